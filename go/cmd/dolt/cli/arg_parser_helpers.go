@@ -139,6 +139,7 @@ func CreateCloneArgParser() *argparser.ArgParser {
 	ap.SupportsString(dbfactory.OSSCredsProfile, "", "profile", "OSS profile to use.")
 	ap.SupportsString(UserFlag, "u", "user", "User name to use when authenticating with the remote. Gets password from the environment variable {{.EmphasisLeft}}DOLT_REMOTE_PASSWORD{{.EmphasisRight}}.")
 	ap.SupportsFlag(SingleBranchFlag, "", "Clone only the history leading to the tip of a single branch, either specified by --branch or the remote's HEAD (default).")
+	ap.SupportsString(FilterParam, "", "filter", "Make a partial clone, which leaves out data and reads it from the remote when it is first needed. {{.EmphasisLeft}}blob:none{{.EmphasisRight}} leaves out large values stored outside their rows, and {{.EmphasisLeft}}tables:{{.LessThan}}pattern{{.GreaterThan}}[,{{.LessThan}}pattern{{.GreaterThan}}...]{{.EmphasisRight}} leaves out the data of tables not matching any pattern.")
 	return ap
 }
 
@@ -183,6 +184,7 @@ func CreateFetchArgParser() *argparser.ArgParser {
 	ap.SupportsString(UserFlag, "", "user", "User name to use when authenticating with the remote. Gets password from the environment variable {{.EmphasisLeft}}DOLT_REMOTE_PASSWORD{{.EmphasisRight}}.")
 	ap.SupportsFlag(PruneFlag, "p", "After fetching, remove any remote-tracking references that don't exist on the remote.")
 	ap.SupportsFlag(SilentFlag, "", "Suppress progress information.")
+	ap.SupportsFlag(BackfillFlag, "", "In a partial clone, also fetch all the data that the clone left out, after which it is a complete clone.")
 	return ap
 }
 
@@ -297,6 +299,38 @@ func CreateCountCommitsArgParser() *argparser.ArgParser {
 	return ap
 }
 
+func CreateMaterializedViewArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("dolt_create_materialized_view", 2)
+	ap.SupportsFlag(AutoRefreshFlag, "", "Refresh the materialized view each time a commit is created with dolt_commit.")
+	return ap
+}
+
+func DropPolicyArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("dolt_drop_policy", 1)
+	ap.SupportsFlag(IfExistsFlag, "", "Do not return an error if the policy does not exist.")
+	ap.SupportsString(TableFlag, "", "table", "The table of the policy.")
+	return ap
+}
+
+func CreateMaskArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("dolt_create_mask", 4)
+	ap.SupportsString(RolesFlag, "", "roles", "A comma-separated list of the users and roles the mask applies to. Defaults to every user.")
+	return ap
+}
+
+func DropMaskArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("dolt_drop_mask", 1)
+	ap.SupportsFlag(IfExistsFlag, "", "Do not return an error if the mask does not exist.")
+	return ap
+}
+
+func RefreshMaterializedViewArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs("dolt_refresh_materialized_view", 1)
+	ap.SupportsFlag(AllFlag, "a", "Refresh all materialized views.")
+	ap.SupportsFlag(FullFlag, "", "Recompute the materialized view from scratch, instead of from the rows changed since its last refresh.")
+	return ap
+}
+
 func CreateReflogArgParser() *argparser.ArgParser {
 	ap := argparser.NewArgParserWithMaxArgs("reflog", 1)
 	ap.SupportsFlag(AllFlag, "", "Show all refs, including hidden refs, such as DoltHub workspace refs")
@@ -22,6 +22,8 @@ const (
 	AllowEmptyFlag       = "allow-empty"
 	AmendFlag            = "amend"
 	AuthorParam          = "author"
+	AutoRefreshFlag      = "auto-refresh"
+	BackfillFlag         = "backfill"
 	BranchParam          = "branch"
 	CachedFlag           = "cached"
 	CheckoutCreateBranch = "b"
@@ -35,9 +37,12 @@ const (
 	DeleteForceFlag      = "D"
 	DepthFlag            = "depth"
 	DryRunFlag           = "dry-run"
+	FilterParam          = "filter"
 	ForceFlag            = "force"
+	FullFlag             = "full"
 	HardResetParam       = "hard"
 	HostFlag             = "host"
+	IfExistsFlag         = "if-exists"
 	InteractiveFlag      = "interactive"
 	ListFlag             = "list"
 	MergesFlag           = "merges"
@@ -60,6 +65,7 @@ const (
 	PortFlag             = "port"
 	PruneFlag            = "prune"
 	RemoteParam          = "remote"
+	RolesFlag            = "roles"
 	SetUpstreamFlag      = "set-upstream"
 	ShallowFlag          = "shallow"
 	ShowIgnoredFlag      = "ignored"
@@ -70,6 +76,7 @@ const (
 	SquashParam          = "squash"
 	StatFlag             = "stat"
 	SystemFlag           = "system"
+	TableFlag            = "table"
 	TablesFlag           = "tables"
 	TheirsFlag           = "theirs"
 	TrackFlag            = "track"
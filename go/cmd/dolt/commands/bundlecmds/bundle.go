@@ -0,0 +1,23 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundlecmds
+
+import (
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+)
+
+var Commands = cli.NewSubCommandHandler("bundle", "Commands for moving history between databases as single files.", []cli.Command{
+	CreateCmd{},
+})
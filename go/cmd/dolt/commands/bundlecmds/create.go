@@ -0,0 +1,167 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundlecmds
+
+import (
+	"context"
+	"os"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const sinceParam = "since"
+
+var createDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Write branches and tags to a bundle file.",
+	LongDesc: `Writes the branches and tags named by {{.LessThan}}ref{{.GreaterThan}} to a single bundle file, along with all the data needed to complete their histories. A bundle can be moved to a database with no network access to the original and used in place of a remote url: {{.EmphasisLeft}}dolt clone changes.bundle{{.EmphasisRight}} creates a new database from it, and {{.EmphasisLeft}}dolt fetch changes.bundle{{.EmphasisRight}} fetches its branches into {{.LessThan}}remotes/changes{{.GreaterThan}}.
+
+With {{.EmphasisLeft}}--since{{.EmphasisRight}}, the bundle is incremental: it leaves out everything reachable from {{.LessThan}}commit{{.GreaterThan}}, so it is usually much smaller, but it can only be fetched into a database that already has {{.LessThan}}commit{{.GreaterThan}}, and it can't be cloned.
+`,
+	Synopsis: []string{
+		`[--since {{.LessThan}}commit{{.GreaterThan}}] {{.LessThan}}file{{.GreaterThan}} {{.LessThan}}ref{{.GreaterThan}}...`,
+	},
+}
+
+type CreateCmd struct{}
+
+// Name returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd CreateCmd) Name() string {
+	return "create"
+}
+
+// Description returns a description of the command
+func (cmd CreateCmd) Description() string {
+	return "Write branches and tags to a bundle file."
+}
+
+func (cmd CreateCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(createDocs, ap)
+}
+
+func (cmd CreateCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithVariableArgs(cmd.Name())
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"file", "The bundle file to write."})
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"ref", "A branch or tag to include in the bundle."})
+	ap.SupportsString(sinceParam, "", "commit", "Leave out everything reachable from this commit, which the receiving database must already have.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd CreateCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	apr, usage, terminate, status := commands.ParseArgsOrPrintHelp(ap, commandStr, args, createDocs)
+	if terminate {
+		return status
+	}
+
+	if apr.NArg() < 2 {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: a bundle file and at least one ref are required").SetPrintUsage().Build(), usage)
+	}
+
+	refs, verr := resolveBundleRefs(ctx, dEnv.DoltDB, apr.Args[1:])
+	if verr != nil {
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	var basis []hash.Hash
+	if since, ok := apr.GetValue(sinceParam); ok {
+		cm, verr := commands.MaybeGetCommitWithVErr(dEnv, since)
+		if verr != nil {
+			return commands.HandleVErrAndExitCode(verr, usage)
+		}
+		if cm == nil {
+			return commands.HandleVErrAndExitCode(errhand.BuildDError("error: '%s' is not a valid commit", since).Build(), usage)
+		}
+		h, err := cm.HashOf()
+		if err != nil {
+			return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+		}
+		basis = append(basis, h)
+	}
+
+	verr = createBundle(ctx, dEnv, apr.Arg(0), refs, basis)
+	if verr != nil {
+		return commands.HandleVErrAndExitCode(verr, usage)
+	}
+
+	cli.Printf("Wrote %d refs to %s\n", len(refs), apr.Arg(0))
+	return 0
+}
+
+// resolveBundleRefs returns the branches and tags named by |names|, which may be short names or full ref paths
+func resolveBundleRefs(ctx context.Context, ddb *doltdb.DoltDB, names []string) ([]doltdb.RefWithHash, errhand.VerboseError) {
+	all, err := ddb.GetRefsWithHashes(ctx)
+	if err != nil {
+		return nil, errhand.BuildDError("error: unable to read refs").AddCause(err).Build()
+	}
+
+	var refs []doltdb.RefWithHash
+	seen := make(map[string]bool)
+	for _, name := range names {
+		found := false
+		for _, r := range all {
+			if r.Ref.GetType() != ref.BranchRefType && r.Ref.GetType() != ref.TagRefType {
+				continue
+			}
+			if r.Ref.GetPath() != name && r.Ref.String() != name {
+				continue
+			}
+
+			found = true
+			if !seen[r.Ref.String()] {
+				seen[r.Ref.String()] = true
+				refs = append(refs, r)
+			}
+		}
+
+		if !found {
+			return nil, errhand.BuildDError("error: '%s' is not a branch or tag", name).Build()
+		}
+	}
+
+	return refs, nil
+}
+
+func createBundle(ctx context.Context, dEnv *env.DoltEnv, path string, refs []doltdb.RefWithHash, basis []hash.Hash) errhand.VerboseError {
+	tempDir, err := dEnv.TempTableFilesDir()
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errhand.BuildDError("error: unable to create bundle file %s", path).AddCause(err).Build()
+	}
+
+	err = actions.CreateBundle(ctx, dEnv.DoltDB, tempDir, f, refs, basis, nil)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return errhand.BuildDError("error: unable to create bundle").AddCause(err).Build()
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdccmds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/json"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+const (
+	fromParam      = "from"
+	toParam        = "to"
+	stateFileParam = "state-file"
+)
+
+var exportDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Export committed changes as Debezium change events.",
+	LongDesc: `Writes every row change made by the commits after {{.LessThan}}from{{.GreaterThan}} up to and including {{.LessThan}}to{{.GreaterThan}} to stdout as newline delimited JSON change events in the format used by Debezium connectors. Commits are exported oldest first, following the first parent of each commit, and each commit is diffed against its first parent. The {{.EmphasisLeft}}source{{.EmphasisRight}} block of every event records the commit hash, branch, committer and commit time that produced it.
+
+If {{.LessThan}}table{{.GreaterThan}} arguments are given, only changes to those tables are exported.
+
+{{.EmphasisLeft}}--to{{.EmphasisRight}} defaults to {{.EmphasisLeft}}HEAD{{.EmphasisRight}}.
+
+When {{.EmphasisLeft}}--state-file{{.EmphasisRight}} is given, the hash of the last exported commit is written to that file after each commit is exported. On later runs the export resumes from the commit recorded in the file and {{.EmphasisLeft}}--from{{.EmphasisRight}} is only used when the file doesn't exist yet. This makes it safe to run the export on a schedule and append its output to a log consumed by a downstream system.
+`,
+	Synopsis: []string{
+		`--from {{.LessThan}}commit{{.GreaterThan}} [--to {{.LessThan}}commit{{.GreaterThan}}] [{{.LessThan}}table{{.GreaterThan}}...]`,
+		`--state-file {{.LessThan}}file{{.GreaterThan}} [--from {{.LessThan}}commit{{.GreaterThan}}] [--to {{.LessThan}}commit{{.GreaterThan}}] [{{.LessThan}}table{{.GreaterThan}}...]`,
+	},
+}
+
+type ExportCmd struct{}
+
+// Name returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd ExportCmd) Name() string {
+	return "export"
+}
+
+// Description returns a description of the command
+func (cmd ExportCmd) Description() string {
+	return "Export committed changes as Debezium change events."
+}
+
+func (cmd ExportCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(exportDocs, ap)
+}
+
+func (cmd ExportCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithVariableArgs(cmd.Name())
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"table", "The tables to export changes for. Defaults to all tables."})
+	ap.SupportsString(fromParam, "", "commit", "Export the changes made after this commit.")
+	ap.SupportsString(toParam, "", "commit", "Export the changes made up to and including this commit. Defaults to HEAD.")
+	ap.SupportsString(stateFileParam, "", "file", "File recording the last exported commit, used to resume the export on later runs.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd ExportCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	apr, usage, terminate, status := commands.ParseArgsOrPrintHelp(ap, commandStr, args, exportDocs)
+	if terminate {
+		return status
+	}
+
+	stateFile := apr.GetValueOrDefault(stateFileParam, "")
+	fromRef, err := exportStartingPoint(apr.GetValueOrDefault(fromParam, ""), stateFile)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if fromRef == "" {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: --from is required unless --state-file names an existing state file").SetPrintUsage().Build(), usage)
+	}
+
+	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if closeFunc != nil {
+		defer closeFunc()
+	}
+
+	verr := exportChanges(queryist, sqlCtx, fromRef, apr.GetValueOrDefault(toParam, "HEAD"), apr.Args, stateFile)
+	return commands.HandleVErrAndExitCode(verr, usage)
+}
+
+// exportStartingPoint returns the commit to start exporting after: the commit recorded in |stateFile| if it exists,
+// otherwise |fromRef|.
+func exportStartingPoint(fromRef, stateFile string) (string, error) {
+	if stateFile == "" {
+		return fromRef, nil
+	}
+
+	data, err := os.ReadFile(stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return fromRef, nil
+	} else if err != nil {
+		return "", fmt.Errorf("error: unable to read state file %s: %w", stateFile, err)
+	}
+
+	lastExported := strings.TrimSpace(string(data))
+	if lastExported == "" {
+		return fromRef, nil
+	}
+	return lastExported, nil
+}
+
+// cdcCommit is a commit to be exported, along with the first parent it is diffed against.
+type cdcCommit struct {
+	hash      string
+	parent    string
+	committer string
+	email     string
+	tsMs      int64
+}
+
+func exportChanges(queryist cli.Queryist, sqlCtx *sql.Context, fromRef, toRef string, tableNames []string, stateFile string) errhand.VerboseError {
+	fromHash, err := resolveCommit(queryist, sqlCtx, fromRef)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	toHash, err := resolveCommit(queryist, sqlCtx, toRef)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	commits, err := firstParentCommits(queryist, sqlCtx, fromHash, toHash)
+	if err != nil {
+		return errhand.BuildDError("error: unable to list commits between %s and %s", fromRef, toRef).AddCause(err).Build()
+	}
+
+	branch, err := commands.BranchForRef(queryist, sqlCtx, toRef)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+
+	dbName := sqlCtx.GetCurrentDatabase()
+	for _, cm := range commits {
+		source := json.DebeziumSource{
+			Name:      dbName,
+			Db:        dbName,
+			TsMs:      cm.tsMs,
+			Commit:    cm.hash,
+			Branch:    branch,
+			Committer: cm.committer,
+			Email:     cm.email,
+		}
+
+		verr := commands.WriteDebeziumChanges(queryist, sqlCtx, cm.parent, cm.hash, tableNames, source)
+		if verr != nil {
+			return verr
+		}
+
+		if stateFile != "" {
+			err = writeStateFile(stateFile, cm.hash)
+			if err != nil {
+				return errhand.BuildDError("error: unable to record exported commit %s in %s", cm.hash, stateFile).AddCause(err).Build()
+			}
+		}
+	}
+
+	return nil
+}
+
+func resolveCommit(queryist cli.Queryist, sqlCtx *sql.Context, ref string) (string, error) {
+	rows, err := commands.InterpolateAndRunQuery(queryist, sqlCtx, "select hashof(?)", ref)
+	if err != nil {
+		return "", fmt.Errorf("error: unable to resolve commit '%s': %w", ref, err)
+	}
+	if len(rows) == 0 {
+		return "", fmt.Errorf("error: unable to resolve commit '%s'", ref)
+	}
+	return fmt.Sprint(rows[0][0]), nil
+}
+
+// firstParentCommits returns the commits on the first parent path from |toHash| back to |fromHash|, oldest first.
+// |fromHash| itself is not included. It is an error for |fromHash| not to be on that path.
+func firstParentCommits(queryist cli.Queryist, sqlCtx *sql.Context, fromHash, toHash string) ([]cdcCommit, error) {
+	if fromHash == toHash {
+		return nil, nil
+	}
+
+	rows, err := commands.InterpolateAndRunQuery(queryist, sqlCtx,
+		"select commit_hash, committer, email, date, message, parents from dolt_log(?, '--parents')", fromHash+".."+toHash)
+	if err != nil {
+		return nil, err
+	}
+
+	byHash := make(map[string]cdcCommit, len(rows))
+	for _, row := range rows {
+		tsMs, err := commitTimeMillis(row[3])
+		if err != nil {
+			return nil, err
+		}
+
+		cm := cdcCommit{
+			hash:      fmt.Sprint(row[0]),
+			committer: fmt.Sprint(row[1]),
+			email:     fmt.Sprint(row[2]),
+			tsMs:      tsMs,
+		}
+		if parents, ok := row[5].(string); ok && parents != "" {
+			cm.parent = strings.Split(parents, ", ")[0]
+		}
+		byHash[cm.hash] = cm
+	}
+
+	var commits []cdcCommit
+	for h := toHash; h != fromHash; {
+		cm, ok := byHash[h]
+		if !ok || cm.parent == "" {
+			return nil, fmt.Errorf("%s is not an ancestor of %s on its first parent history", fromHash, toHash)
+		}
+		commits = append(commits, cm)
+		h = cm.parent
+	}
+
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+func commitTimeMillis(col interface{}) (int64, error) {
+	switch v := col.(type) {
+	case time.Time:
+		return v.UnixMilli(), nil
+	case string:
+		t, err := time.Parse("2006-01-02 15:04:05.999", v)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing commit date %s: %w", v, err)
+		}
+		return t.UnixMilli(), nil
+	default:
+		return 0, fmt.Errorf("unexpected type %T for commit date", v)
+	}
+}
+
+// writeStateFile atomically replaces the contents of |stateFile| with |commitHash|.
+func writeStateFile(stateFile, commitHash string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(stateFile), filepath.Base(stateFile)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.WriteString(commitHash + "\n")
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), stateFile)
+}
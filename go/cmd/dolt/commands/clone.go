@@ -43,9 +43,13 @@ var cloneDocs = cli.CommandDocumentationContent{
 After the clone, a plain {{.EmphasisLeft}}dolt fetch{{.EmphasisRight}} without arguments will update all the remote-tracking branches, and a {{.EmphasisLeft}}dolt pull{{.EmphasisRight}} without arguments will in addition merge the remote branch into the current branch.
 
 This default configuration is achieved by creating references to the remote branch heads under {{.LessThan}}refs/remotes/origin{{.GreaterThan}}  and by creating a remote named 'origin'.
+
+{{.LessThan}}remote-url{{.GreaterThan}} may also be the path to a bundle file written by {{.EmphasisLeft}}dolt bundle create{{.EmphasisRight}} without {{.EmphasisLeft}}--since{{.EmphasisRight}}.
+
+With {{.EmphasisLeft}}--filter{{.EmphasisRight}}, the clone is partial: it leaves out the data selected by the filter, and reads it from the remote the first time a query needs it. Later fetches leave out the same data. {{.EmphasisLeft}}dolt fetch --backfill{{.EmphasisRight}} fetches everything that was left out and makes the clone complete.
 `,
 	Synopsis: []string{
-		"[-remote {{.LessThan}}remote{{.GreaterThan}}] [-branch {{.LessThan}}branch{{.GreaterThan}}]  [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] {{.LessThan}}remote-url{{.GreaterThan}} {{.LessThan}}new-dir{{.GreaterThan}}",
+		"[-remote {{.LessThan}}remote{{.GreaterThan}}] [-branch {{.LessThan}}branch{{.GreaterThan}}] [--filter {{.LessThan}}filter{{.GreaterThan}}] [--aws-region {{.LessThan}}region{{.GreaterThan}}] [--aws-creds-type {{.LessThan}}creds-type{{.GreaterThan}}] [--aws-creds-file {{.LessThan}}file{{.GreaterThan}}] [--aws-creds-profile {{.LessThan}}profile{{.GreaterThan}}] {{.LessThan}}remote-url{{.GreaterThan}} {{.LessThan}}new-dir{{.GreaterThan}}",
 	},
 }
 
@@ -104,6 +108,15 @@ func clone(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.DoltEn
 		return verr
 	}
 
+	var filter *doltdb.PartialCloneFilter
+	if filterStr, ok := apr.GetValue(cli.FilterParam); ok {
+		f, err := doltdb.ParsePartialCloneFilter(filterStr)
+		if err != nil {
+			return errhand.BuildDError("error: %s", err.Error()).Build()
+		}
+		filter = &f
+	}
+
 	dEnv.UserPassConfig, verr = getRemoteUserAndPassConfig(apr)
 	if verr != nil {
 		return verr
@@ -149,7 +162,13 @@ func clone(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env.DoltEn
 	// Nil out the old Dolt env so we don't accidentally operate on the wrong database
 	dEnv = nil
 
-	err = actions.CloneRemote(ctx, srcDB, remoteName, branch, singleBranch, depth, clonedEnv)
+	if filter != nil {
+		dialParams := map[string]interface{}{dbfactory.GRPCDialProviderParam: clonedEnv}
+		err = clonedEnv.DoltDB.InitPartialClone(ctx, remoteUrl, params, *filter, dialParams)
+	}
+	if err == nil {
+		err = actions.CloneRemote(ctx, srcDB, remoteName, branch, singleBranch, depth, clonedEnv)
+	}
 	if err != nil {
 		// If we're cloning into a directory that already exists do not erase it. Otherwise
 		// make best effort to delete the directory we created.
@@ -196,7 +215,7 @@ func parseArgs(apr *argparser.ArgParseResults) (string, string, errhand.VerboseE
 	if apr.NArg() == 2 {
 		dir = apr.Arg(1)
 	} else {
-		dir = path.Base(urlStr)
+		dir = strings.TrimSuffix(path.Base(urlStr), dbfactory.BundleExt)
 		if dir == "." {
 			dir = path.Dir(urlStr)
 		} else if dir == "/" {
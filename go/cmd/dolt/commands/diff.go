@@ -21,6 +21,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/types"
@@ -38,6 +39,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/typed/json"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/tabular"
 	"github.com/dolthub/dolt/go/libraries/utils/argparser"
 	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
@@ -56,9 +58,10 @@ const (
 
 	SchemaAndDataDiff = SchemaOnlyDiff | DataOnlyDiff
 
-	TabularDiffOutput diffOutput = 1
-	SQLDiffOutput     diffOutput = 2
-	JsonDiffOutput    diffOutput = 3
+	TabularDiffOutput  diffOutput = 1
+	SQLDiffOutput      diffOutput = 2
+	JsonDiffOutput     diffOutput = 3
+	DebeziumDiffOutput diffOutput = 4
 
 	DataFlag     = "data"
 	SchemaFlag   = "schema"
@@ -97,6 +100,8 @@ The diffs displayed can be limited to show the first N by providing the paramete
 
 To filter which data rows are displayed, use {{.EmphasisLeft}}--where <SQL expression>{{.EmphasisRight}}. Table column names in the filter expression must be prefixed with {{.EmphasisLeft}}from_{{.EmphasisRight}} or {{.EmphasisLeft}}to_{{.EmphasisRight}}, e.g. {{.EmphasisLeft}}to_COLUMN_NAME > 100{{.EmphasisRight}} or {{.EmphasisLeft}}from_COLUMN_NAME + to_COLUMN_NAME = 0{{.EmphasisRight}}.
 
+The {{.EmphasisLeft}}--format debezium{{.EmphasisRight}} output writes each changed row as a newline delimited JSON change event in the format used by Debezium connectors, with {{.EmphasisLeft}}before{{.EmphasisRight}} and {{.EmphasisLeft}}after{{.EmphasisRight}} row images and an {{.EmphasisLeft}}op{{.EmphasisRight}} of {{.EmphasisLeft}}c{{.EmphasisRight}}, {{.EmphasisLeft}}u{{.EmphasisRight}} or {{.EmphasisLeft}}d{{.EmphasisRight}}. Only data changes are written. The {{.EmphasisLeft}}source{{.EmphasisRight}} block of each event records the commit hash, branch, committer and commit time of the revision the changes lead to. Working and staged changes aren't commits, so their events record only the current branch. To export a stream of changes commit by commit, see {{.EmphasisLeft}}dolt cdc export{{.EmphasisRight}}.
+
 The {{.EmphasisLeft}}--diff-mode{{.EmphasisRight}} argument controls how modified rows are presented when the format output is set to {{.EmphasisLeft}}tabular{{.EmphasisRight}}. When set to {{.EmphasisLeft}}row{{.EmphasisRight}}, modified rows are presented as old and new rows. When set to {{.EmphasisLeft}}line{{.EmphasisRight}}, modified rows are presented as a single row, and changes are presented using "+" and "-" within the column. When set to {{.EmphasisLeft}}in-place{{.EmphasisRight}}, modified rows are presented as a single row, and changes are presented side-by-side with a color distinction (requires a color-enabled terminal). When set to {{.EmphasisLeft}}context{{.EmphasisRight}}, rows that contain at least one column that spans multiple lines uses {{.EmphasisLeft}}line{{.EmphasisRight}}, while all other rows use {{.EmphasisLeft}}row{{.EmphasisRight}}. The default value is {{.EmphasisLeft}}context{{.EmphasisRight}}.
 `,
 	Synopsis: []string{
@@ -123,6 +128,8 @@ type diffArgs struct {
 	*diffDisplaySettings
 	*diffDatasets
 	tableSet *set.StrSet
+	// changeSource describes the origin of the changes for debezium output. When nil, it is derived from the diff refs.
+	changeSource *json.DebeziumSource
 }
 
 type diffStatistics struct {
@@ -168,7 +175,7 @@ func (cmd DiffCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsFlag(SchemaFlag, "s", "Show only the schema changes, do not show the data changes (Both shown by default).")
 	ap.SupportsFlag(StatFlag, "", "Show stats of data changes")
 	ap.SupportsFlag(SummaryFlag, "", "Show summary of data and schema changes")
-	ap.SupportsString(FormatFlag, "r", "result output format", "How to format diff output. Valid values are tabular, sql, json. Defaults to tabular.")
+	ap.SupportsString(FormatFlag, "r", "result output format", "How to format diff output. Valid values are tabular, sql, json, debezium. Defaults to tabular.")
 	ap.SupportsString(whereParam, "", "column", "filters columns based on values in the diff.  See {{.EmphasisLeft}}dolt diff --help{{.EmphasisRight}} for details.")
 	ap.SupportsInt(limitParam, "", "record_count", "limits to the first N diffs.")
 	ap.SupportsFlag(cli.CachedFlag, "c", "Show only the staged data changes.")
@@ -230,6 +237,10 @@ func (cmd DiffCmd) validateArgs(apr *argparser.ArgParseResults) errhand.VerboseE
 	f, _ := apr.GetValue(FormatFlag)
 	switch strings.ToLower(f) {
 	case "tabular", "sql", "json", "":
+	case "debezium":
+		if apr.ContainsAny(StatFlag, SummaryFlag, SkinnyFlag) {
+			return errhand.BuildDError("invalid Arguments: --format debezium cannot be combined with --stat, --summary, or --skinny").Build()
+		}
 	default:
 		return errhand.BuildDError("invalid output format: %s", f).Build()
 	}
@@ -273,6 +284,8 @@ func parseDiffDisplaySettings(apr *argparser.ArgParseResults) *diffDisplaySettin
 		displaySettings.diffOutput = SQLDiffOutput
 	case "json":
 		displaySettings.diffOutput = JsonDiffOutput
+	case "debezium":
+		displaySettings.diffOutput = DebeziumDiffOutput
 	}
 
 	displaySettings.limit, _ = apr.GetInt(limitParam)
@@ -751,6 +764,81 @@ func getDiffSummariesBetweenRefs(queryist cli.Queryist, sqlCtx *sql.Context, fro
 	return summaries, nil
 }
 
+// WriteDebeziumChanges writes the data changes between |fromRef| and |toRef| to stdout as Debezium change events, with
+// |source| describing where the changes came from. An empty |tableNames| includes every table. Unlike dolt diff, named
+// tables that don't exist in either revision are not an error, since they may only exist in other parts of history.
+func WriteDebeziumChanges(queryist cli.Queryist, sqlCtx *sql.Context, fromRef, toRef string, tableNames []string, source json.DebeziumSource) errhand.VerboseError {
+	datasets := &diffDatasets{
+		fromRef: fromRef,
+		toRef:   toRef,
+	}
+
+	tableSet, err := parseDiffTableSetSql(queryist, sqlCtx, datasets, nil)
+	if err != nil {
+		return errhand.VerboseErrorFromError(err)
+	}
+	if len(tableNames) > 0 {
+		_, tableSet, _ = tableSet.LeftIntersectionRight(set.NewStrSet(tableNames))
+	}
+
+	dArgs := &diffArgs{
+		diffDisplaySettings: &diffDisplaySettings{
+			diffParts:  DataOnlyDiff,
+			diffOutput: DebeziumDiffOutput,
+			diffMode:   diff.ModeRow,
+			limit:      -1,
+		},
+		diffDatasets: datasets,
+		tableSet:     tableSet,
+		changeSource: &source,
+	}
+
+	return diffUserTables(queryist, sqlCtx, dArgs)
+}
+
+// debeziumSourceForRef returns the source of the Debezium change events of a diff to |toRef|. When |toRef| is a commit,
+// the source records its hash, branch, committer and commit time. The working set and the staged changes aren't
+// commits, so their events record the current branch and the time of the diff instead.
+func debeziumSourceForRef(queryist cli.Queryist, sqlCtx *sql.Context, toRef string) (*json.DebeziumSource, error) {
+	dbName := sqlCtx.GetCurrentDatabase()
+	source := &json.DebeziumSource{
+		Name: dbName,
+		Db:   dbName,
+		TsMs: time.Now().UnixMilli(),
+	}
+
+	if strings.EqualFold(toRef, doltdb.Working) || strings.EqualFold(toRef, doltdb.Staged) {
+		branch, err := getActiveBranchName(sqlCtx, queryist)
+		if err != nil {
+			return nil, err
+		}
+		source.Branch = branch
+		return source, nil
+	}
+
+	rows, err := InterpolateAndRunQuery(queryist, sqlCtx, "select commit_hash, committer, email, date from dolt_log(?, '-n', '1')", toRef)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to resolve commit '%s': %w", toRef, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("error: unable to resolve commit '%s'", toRef)
+	}
+	tsMs, err := getTimestampColAsUint64(rows[0][3])
+	if err != nil {
+		return nil, err
+	}
+	source.Commit = fmt.Sprint(rows[0][0])
+	source.Committer = fmt.Sprint(rows[0][1])
+	source.Email = fmt.Sprint(rows[0][2])
+	source.TsMs = int64(tsMs)
+
+	source.Branch, err = BranchForRef(queryist, sqlCtx, toRef)
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
 func diffUserTables(queryist cli.Queryist, sqlCtx *sql.Context, dArgs *diffArgs) errhand.VerboseError {
 	var err error
 
@@ -763,7 +851,14 @@ func diffUserTables(queryist cli.Queryist, sqlCtx *sql.Context, dArgs *diffArgs)
 		return printDiffSummary(sqlCtx, deltas, dArgs)
 	}
 
-	dw, err := newDiffWriter(dArgs.diffOutput)
+	if dArgs.diffOutput == DebeziumDiffOutput && dArgs.changeSource == nil {
+		dArgs.changeSource, err = debeziumSourceForRef(queryist, sqlCtx, dArgs.toRef)
+		if err != nil {
+			return errhand.VerboseErrorFromError(err)
+		}
+	}
+
+	dw, err := newDiffWriter(sqlCtx, dArgs)
 	if err != nil {
 		return errhand.VerboseErrorFromError(err)
 	}
@@ -1265,6 +1360,9 @@ func diffDoltSchemasTable(
 			if err != nil {
 				return nil
 			}
+		case "materialized_view":
+			// The rows of a materialized view are stored in a table, which is diffed with the other tables.
+			continue
 		default:
 			cli.PrintErrf("Unrecognized schema element type: %s", fragmentType)
 			continue
@@ -27,6 +27,7 @@ import (
 	"github.com/fatih/color"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/doltversion"
 	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
@@ -60,16 +61,18 @@ type diffWriter interface {
 }
 
 // newDiffWriter returns a diffWriter for the output format given
-func newDiffWriter(diffOutput diffOutput) (diffWriter, error) {
-	switch diffOutput {
+func newDiffWriter(sqlCtx *sql.Context, dArgs *diffArgs) (diffWriter, error) {
+	switch dArgs.diffOutput {
 	case TabularDiffOutput:
 		return tabularDiffWriter{}, nil
 	case SQLDiffOutput:
 		return sqlDiffWriter{}, nil
 	case JsonDiffOutput:
 		return newJsonDiffWriter(iohelp.NopWrCloser(cli.CliOut))
+	case DebeziumDiffOutput:
+		return debeziumDiffWriter{source: *dArgs.changeSource}, nil
 	default:
-		panic(fmt.Sprintf("unexpected diff output: %v", diffOutput))
+		panic(fmt.Sprintf("unexpected diff output: %v", dArgs.diffOutput))
 	}
 }
 
@@ -650,3 +653,51 @@ func (j *jsonDiffWriter) Close(ctx context.Context) error {
 	// Writer has already been closed here during row iteration, no need to close it here
 	return nil
 }
+
+// debeziumDiffWriter writes row changes as newline delimited Debezium change events. Schema, view, trigger and event
+// changes have no Debezium representation and are skipped.
+type debeziumDiffWriter struct {
+	source json.DebeziumSource
+}
+
+var _ diffWriter = debeziumDiffWriter{}
+
+func (d debeziumDiffWriter) BeginTable(fromTableName, toTableName string, isAdd, isDrop bool) error {
+	return nil
+}
+
+func (d debeziumDiffWriter) WriteTableSchemaDiff(fromTableInfo, toTableInfo *diff.TableInfo, tds diff.TableDeltaSummary) error {
+	return nil
+}
+
+func (d debeziumDiffWriter) WriteEventDiff(ctx context.Context, eventName, oldDefn, newDefn string) error {
+	return nil
+}
+
+func (d debeziumDiffWriter) WriteTriggerDiff(ctx context.Context, triggerName, oldDefn, newDefn string) error {
+	return nil
+}
+
+func (d debeziumDiffWriter) WriteViewDiff(ctx context.Context, viewName, oldDefn, newDefn string) error {
+	return nil
+}
+
+func (d debeziumDiffWriter) WriteTableDiffStats(diffStats []diffStatistics, oldColLen, newColLen int, areTablesKeyless bool) error {
+	return errors.New("diff stats are not supported for debezium output")
+}
+
+func (d debeziumDiffWriter) RowWriter(fromTableInfo, toTableInfo *diff.TableInfo, tds diff.TableDeltaSummary, unionSch sql.Schema) (diff.SqlRowDiffWriter, error) {
+	// TODO: schema names
+	tableName := tds.ToTableName.Name
+	if len(tableName) == 0 {
+		tableName = tds.FromTableName.Name
+	}
+
+	source := d.source
+	source.Version = doltversion.Version
+	return json.NewDebeziumRowDiffWriter(iohelp.NopWrCloser(cli.CliOut), unionSch, tableName, source), nil
+}
+
+func (d debeziumDiffWriter) Close(ctx context.Context) error {
+	return nil
+}
@@ -142,6 +142,14 @@ func (cmd DumpCmd) Exec(ctx context.Context, commandStr string, args []string, d
 		return HandleVErrAndExitCode(vErr, usage)
 	}
 
+	rd, closeFunc, err := newDumpReader(ctx, cliCtx)
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if closeFunc != nil {
+		defer closeFunc()
+	}
+
 	switch resFormat {
 	case emptyFileExt, sqlFileExt:
 		var defaultName string
@@ -183,7 +191,7 @@ func (cmd DumpCmd) Exec(ctx context.Context, commandStr string, args []string, d
 
 		for _, tbl := range tblNames {
 			tblOpts := newTableArgs(tbl, dumpOpts.dest, !apr.Contains(noBatchFlag), apr.Contains(noAutocommitFlag), schemaOnly)
-			err = dumpTable(ctx, dEnv, tblOpts, fPath)
+			err = dumpTable(ctx, dEnv, rd, tblOpts, fPath)
 			if err != nil {
 				return HandleVErrAndExitCode(err, usage)
 			}
@@ -194,7 +202,7 @@ func (cmd DumpCmd) Exec(ctx context.Context, commandStr string, args []string, d
 			return HandleVErrAndExitCode(err, usage)
 		}
 	case csvFileExt, jsonFileExt, parquetFileExt:
-		err = dumpNonSqlTables(ctx, root, dEnv, force, tblNames, resFormat, outputFileOrDirName, false)
+		err = dumpNonSqlTables(ctx, root, dEnv, rd, force, tblNames, resFormat, outputFileOrDirName, false)
 		if err != nil {
 			return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
 		}
@@ -559,9 +567,66 @@ func (m dumpOptions) DumpDestName() string {
 	return m.dest.String()
 }
 
+// dumpReader reads the rows of the tables which are dumped. The rows are read as the user of the command, so that the
+// rows the user cannot read are filtered, and the columns masked for the user are masked, as they are in the queries
+// of the user. When the command is connected to a running server, the rows are read with queries of the server.
+type dumpReader struct {
+	se       *engine.SqlEngine
+	queryist cli.Queryist
+	sqlCtx   *sql.Context
+}
+
+// newDumpReader returns the dumpReader of the command with the context |cliCtx|, and a function which closes it.
+func newDumpReader(ctx context.Context, cliCtx cli.CliContext) (*dumpReader, func(), error) {
+	if cliCtx == nil {
+		return &dumpReader{}, nil, nil
+	}
+	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	se, ok := queryist.(*engine.SqlEngine)
+	if !ok {
+		return &dumpReader{queryist: queryist, sqlCtx: sqlCtx}, closeFunc, nil
+	}
+	return &dumpReader{se: se, sqlCtx: sqlCtx}, closeFunc, nil
+}
+
+// tableReader returns the reader of the rows of the table |tableName|.
+func (r *dumpReader) tableReader(ctx context.Context, dEnv *env.DoltEnv, tableName string) (table.SqlRowReader, error) {
+	if r.se == nil && r.queryist == nil {
+		return mvdata.NewSqlEngineReader(ctx, dEnv, tableName)
+	}
+	root, err := dEnv.WorkingRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.se != nil {
+		return mvdata.NewSqlEngineReaderForUser(r.sqlCtx, r.se, root, tableName)
+	}
+
+	// The server's databases are the local databases, so the schema of the table is read from them
+	tbl, ok, err := root.GetTable(ctx, doltdb.TableName{Name: tableName})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, doltdb.ErrTableNotFound
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, iter, err := r.queryist.Query(r.sqlCtx, fmt.Sprintf("SELECT * FROM %s", sql.QuoteIdentifier(tableName)))
+	if err != nil {
+		return nil, err
+	}
+	return mvdata.NewSqlQueryReader(r.sqlCtx, sch, iter), nil
+}
+
 // dumpTable dumps table in file given specific table and file location info
-func dumpTable(ctx context.Context, dEnv *env.DoltEnv, tblOpts *tableOptions, filePath string) errhand.VerboseError {
-	rd, err := mvdata.NewSqlEngineReader(ctx, dEnv, tblOpts.tableName)
+func dumpTable(ctx context.Context, dEnv *env.DoltEnv, dr *dumpReader, tblOpts *tableOptions, filePath string) errhand.VerboseError {
+	rd, err := dr.tableReader(ctx, dEnv, tblOpts.tableName)
 	if err != nil {
 		return errhand.BuildDError("Error creating reader for %s.", tblOpts.SrcName()).AddCause(err).Build()
 	}
@@ -738,7 +803,7 @@ func newTableArgs(tblName string, destination mvdata.DataLocation, batched, auto
 
 // dumpNonSqlTables returns nil if all tables is dumped successfully, and it returns err if there is one.
 // It handles only csv and json file types(rf).
-func dumpNonSqlTables(ctx context.Context, root doltdb.RootValue, dEnv *env.DoltEnv, force bool, tblNames []string, rf string, dirName string, batched bool) errhand.VerboseError {
+func dumpNonSqlTables(ctx context.Context, root doltdb.RootValue, dEnv *env.DoltEnv, rd *dumpReader, force bool, tblNames []string, rf string, dirName string, batched bool) errhand.VerboseError {
 	var fName string
 	if dirName == emptyStr {
 		dirName = "doltdump/"
@@ -759,7 +824,7 @@ func dumpNonSqlTables(ctx context.Context, root doltdb.RootValue, dEnv *env.Dolt
 
 		tblOpts := newTableArgs(tbl, dumpOpts.dest, batched, false, false)
 
-		err = dumpTable(ctx, dEnv, tblOpts, fPath)
+		err = dumpTable(ctx, dEnv, rd, tblOpts, fPath)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,107 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+)
+
+// grantedRolesTTL is how long the roles granted to a user are cached by maskingGrants. Column masks look up the roles
+// of the user for every read of a masked table, so the lookup must be cheap, but changes to the grants of a user
+// should apply soon after they are made.
+const grantedRolesTTL = time.Second
+
+// GrantedRoles returns the roles which have been granted to |user|.
+func (se *SqlEngine) GrantedRoles(ctx context.Context, user string) ([]string, error) {
+	sqlCtx, err := se.NewLocalContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var quoted bytes.Buffer
+	sqltypes.MakeTrusted(sqltypes.VarChar, []byte(user)).EncodeSQL(&quoted)
+	_, iter, err := se.Query(sqlCtx, "SELECT DISTINCT FROM_USER FROM mysql.role_edges WHERE TO_USER = "+quoted.String())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sql.RowIterToRows(sqlCtx, iter)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if role, ok := row[0].(string); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+// maskingGrants is the dsqle.MaskingGrants of a SqlEngine, which reads the grants of users from the privilege system
+// of the engine.
+type maskingGrants struct {
+	se    *SqlEngine
+	mu    sync.Mutex
+	roles map[string]cachedRoles
+}
+
+type cachedRoles struct {
+	roles   []string
+	expires time.Time
+}
+
+var _ dsqle.MaskingGrants = (*maskingGrants)(nil)
+
+func newMaskingGrants(se *SqlEngine) *maskingGrants {
+	return &maskingGrants{se: se, roles: make(map[string]cachedRoles)}
+}
+
+// HasUnmask implements dsqle.MaskingGrants.
+func (g *maskingGrants) HasUnmask(ctx *sql.Context) bool {
+	return g.se.engine.Analyzer.Catalog.MySQLDb.UserHasPrivileges(ctx, sql.NewDynamicPrivilegedOperation(dsqle.DynamicPrivilege_DoltUnmask))
+}
+
+// GrantedRoles implements dsqle.MaskingGrants.
+func (g *maskingGrants) GrantedRoles(ctx *sql.Context) ([]string, error) {
+	user := ctx.Session.Client().User
+	now := time.Now()
+	g.mu.Lock()
+	cached, ok := g.roles[user]
+	g.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.roles, nil
+	}
+
+	roles, err := g.se.GrantedRoles(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for name, c := range g.roles {
+		if now.After(c.expires) {
+			delete(g.roles, name)
+		}
+	}
+	g.roles[user] = cachedRoles{roles: roles, expires: now.Add(grantedRolesTTL)}
+	return roles, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// queryPlanBuilder is the override builder of the plans of the engine which records the plan of each statement in the
+// session running it, when the session is recording plans. See dsess.DoltSession.TrackQueryPlan. The plans are built
+// by |override|, or by the default builder.
+type queryPlanBuilder struct {
+	override sql.NodeExecBuilder
+}
+
+var _ sql.NodeExecBuilder = queryPlanBuilder{}
+
+func (b queryPlanBuilder) Build(ctx *sql.Context, n sql.Node, r sql.Row) (sql.RowIter, error) {
+	if sess, ok := ctx.Session.(*dsess.DoltSession); ok {
+		sess.RecordQueryPlan(n)
+	}
+	return b.override.Build(ctx, n, r)
+}
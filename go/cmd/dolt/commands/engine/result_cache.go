@@ -0,0 +1,521 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/rowexec"
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	dsqle "github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// The result cache keeps the rows of read-only queries, and returns them to
+// later queries which would compute the same rows. A result is keyed by the
+// normalized text of its query, its analyzed plan, which has the values of
+// bound parameters, its user and the host of its client, the session
+// variables which change how rows are computed, and the hashes of the tables
+// it reads. Every write to a table
+// changes its hash, so results are never invalidated: the queries of a new
+// root just miss, and the results of old roots are evicted in LRU order.
+// Cached rows are copied whenever they are returned, so that the iterators of
+// a query may change its rows without changing the cached result.
+//
+// Only queries whose plans read dolt tables, and only call deterministic
+// functions, are cached. Queries which read system tables, call table
+// functions, or read tables with row-level security policies or column masks
+// for their user, are always run.
+
+const (
+	// maxTrackedQueries is the number of queries the result cache remembers having seen, to tell the plan of a query
+	// from the plans of its subqueries, which are built while the query runs.
+	maxTrackedQueries = 4096
+
+	// entryOverhead, rowOverhead and valueOverhead approximate the memory of an entry, a row and a value, apart from
+	// the bytes of strings and blobs.
+	entryOverhead = 128
+	rowOverhead   = 24
+	valueOverhead = 16
+)
+
+// resultCacheSessionVars are the session variables which change the rows a query returns.
+var resultCacheSessionVars = []string{
+	"time_zone",
+	"sql_mode",
+	"sql_select_limit",
+	"collation_connection",
+	"character_set_results",
+	"div_precision_increment",
+	"group_concat_max_len",
+	"dolt_override_schema",
+}
+
+// nondeterministicFunctions are the functions whose results do not only depend on their arguments.
+var nondeterministicFunctions = map[string]struct{}{
+	"benchmark":         {},
+	"connection_id":     {},
+	"curdate":           {},
+	"current_date":      {},
+	"current_role":      {},
+	"current_time":      {},
+	"current_timestamp": {},
+	"current_user":      {},
+	"curtime":           {},
+	"database":          {},
+	"found_rows":        {},
+	"get_lock":          {},
+	"is_free_lock":      {},
+	"is_used_lock":      {},
+	"last_insert_id":    {},
+	"load_file":         {},
+	"localtime":         {},
+	"localtimestamp":    {},
+	"now":               {},
+	"rand":              {},
+	"random_bytes":      {},
+	"release_all_locks": {},
+	"release_lock":      {},
+	"row_count":         {},
+	"schema":            {},
+	"session_user":      {},
+	"sleep":             {},
+	"sysdate":           {},
+	"system_user":       {},
+	"unix_timestamp":    {},
+	"user":              {},
+	"utc_date":          {},
+	"utc_time":          {},
+	"utc_timestamp":     {},
+	"uuid":              {},
+	"uuid_short":        {},
+	// dolt functions read the commit graph and the session, which are not part of the key of a result
+	"active_branch": {},
+	"has_ancestor":  {},
+	"hashof":        {},
+	"hashof_db":     {},
+	"hashof_table":  {},
+}
+
+// ResultCacheStats are the counters of a ResultCache.
+type ResultCacheStats struct {
+	// Hits is the number of queries whose rows were returned from the cache.
+	Hits uint64
+	// Misses is the number of cacheable queries whose rows were not in the cache.
+	Misses uint64
+	// Evictions is the number of results evicted to stay within the memory of the cache.
+	Evictions uint64
+	// Entries is the number of cached results.
+	Entries uint64
+	// Bytes is the approximate memory of the cached results.
+	Bytes uint64
+}
+
+// ResultCache is a memory bounded LRU cache of the rows of read-only queries.
+type ResultCache struct {
+	maxBytes       uint64
+	maxResultBytes uint64
+
+	mu      sync.Mutex
+	entries map[hash.Hash]*list.Element
+	order   *list.List
+	bytes   uint64
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+
+	// queries are the queries whose plans have been built
+	queries *lru.Cache[queryID, struct{}]
+}
+
+type resultCacheEntry struct {
+	key  hash.Hash
+	rows []sql.Row
+	size uint64
+}
+
+// queryID identifies a query run by a session.
+type queryID struct {
+	session   uint32
+	pid       uint64
+	queryTime int64
+}
+
+// NewResultCache returns a ResultCache configured by |config|.
+func NewResultCache(config servercfg.ResultCacheConfig) *ResultCache {
+	return newResultCache(config.MaxMemoryMb()*1024*1024, config.MaxResultKb()*1024)
+}
+
+func newResultCache(maxBytes, maxResultBytes uint64) *ResultCache {
+	// lru.New only fails for non-positive sizes
+	queries, _ := lru.New[queryID, struct{}](maxTrackedQueries)
+	return &ResultCache{
+		maxBytes:       maxBytes,
+		maxResultBytes: maxResultBytes,
+		entries:        make(map[hash.Hash]*list.Element),
+		order:          list.New(),
+		queries:        queries,
+	}
+}
+
+// Stats returns the counters of the cache.
+func (c *ResultCache) Stats() ResultCacheStats {
+	c.mu.Lock()
+	entries, bytes := uint64(len(c.entries)), c.bytes
+	c.mu.Unlock()
+	return ResultCacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   entries,
+		Bytes:     bytes,
+	}
+}
+
+func (c *ResultCache) get(key hash.Hash) ([]sql.Row, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return copyRows(e.Value.(*resultCacheEntry).rows), true
+}
+
+func (c *ResultCache) put(key hash.Hash, rows []sql.Row, size uint64) {
+	size += entryOverhead
+	if size > c.maxResultBytes || size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		// a concurrent query with the same key cached the same rows
+		return
+	}
+	for c.bytes+size > c.maxBytes {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.remove(oldest)
+		c.evictions.Add(1)
+	}
+	c.entries[key] = c.order.PushFront(&resultCacheEntry{key: key, rows: rows, size: size})
+	c.bytes += size
+}
+
+func (c *ResultCache) remove(e *list.Element) {
+	entry := c.order.Remove(e).(*resultCacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
+
+// ExecBuilder returns a sql.NodeExecBuilder which builds the plans of queries with |override|, and the default
+// builder of the engine, and returns the cached rows of the queries which have been run before.
+func (c *ResultCache) ExecBuilder(override sql.NodeExecBuilder) sql.NodeExecBuilder {
+	b := &resultCacheBuilder{cache: c, override: override}
+	b.exec = rowexec.NewOverrideBuilder(b)
+	return b.exec
+}
+
+// resultCacheBuilder is the override builder of the plans of a ResultCache. The first plan built for a query is the
+// plan of the query. All other plans, including the plan of the query when the cache builds it, are built by
+// |override|, or by the default builder.
+type resultCacheBuilder struct {
+	cache    *ResultCache
+	override sql.NodeExecBuilder
+	// exec is the builder of the engine, whose override is this builder
+	exec sql.NodeExecBuilder
+}
+
+var _ sql.NodeExecBuilder = (*resultCacheBuilder)(nil)
+
+func (b *resultCacheBuilder) Build(ctx *sql.Context, n sql.Node, r sql.Row) (sql.RowIter, error) {
+	if len(r) > 0 || ctx.Session == nil {
+		return b.override.Build(ctx, n, r)
+	}
+	id := queryID{session: ctx.Session.ID(), pid: ctx.Pid(), queryTime: ctx.QueryTime().UnixNano()}
+	if seen, _ := b.cache.queries.ContainsOrAdd(id, struct{}{}); seen {
+		return b.override.Build(ctx, n, r)
+	}
+
+	key, ok, err := resultCacheKey(ctx, n)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return b.override.Build(ctx, n, r)
+	}
+
+	if rows, ok := b.cache.get(key); ok {
+		b.cache.hits.Add(1)
+		return sql.RowsToRowIter(rows...), nil
+	}
+	b.cache.misses.Add(1)
+
+	iter, err := b.exec.Build(ctx, n, r)
+	if err != nil {
+		return nil, err
+	}
+	return &resultCacheIter{RowIter: iter, cache: b.cache, key: key}, nil
+}
+
+// resultCacheKey returns the key of the rows of the plan |n| of a query, or false if its rows cannot be cached.
+func resultCacheKey(ctx *sql.Context, n sql.Node) (hash.Hash, bool, error) {
+	if !n.IsReadOnly() {
+		return hash.Hash{}, false, nil
+	}
+	var tables []string
+	if ok, err := cacheableNode(ctx, n, &tables); err != nil || !ok {
+		return hash.Hash{}, false, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(normalizeQuery(ctx.Query()))
+	sb.WriteByte(0)
+	sb.WriteString(n.String())
+	sb.WriteByte(0)
+	sb.WriteString(resultCacheAccount(ctx.Session.Client()))
+	sb.WriteByte(0)
+	sb.WriteString(ctx.GetCurrentDatabase())
+	for _, name := range resultCacheSessionVars {
+		val, err := ctx.GetSessionVariable(ctx, name)
+		if err != nil {
+			// not a variable of this server
+			continue
+		}
+		fmt.Fprintf(&sb, "\x00%s=%v", name, val)
+	}
+	for _, t := range tables {
+		sb.WriteByte(0)
+		sb.WriteString(t)
+	}
+	return hash.Of([]byte(sb.String())), true, nil
+}
+
+// resultCacheAccount returns the user@host of |client|. The privileges of a user depend on the host it connects from,
+// so results are only shared by the queries of the same user from the same host.
+func resultCacheAccount(client sql.Client) string {
+	host, _, err := net.SplitHostPort(client.Address)
+	if err != nil {
+		host = client.Address
+	}
+	return client.User + "@" + host
+}
+
+// normalizeQuery returns |query| without its trailing semicolons, and with every run of whitespace replaced by a
+// single space. The whitespace of string literals is changed too, but their values are in the plan of the query.
+func normalizeQuery(query string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(query), " "), "; ")
+}
+
+// cacheableNode returns whether the rows of |n| only depend on the tables it reads, and appends the names and
+// hashes of those tables to |tables|, in the order |n| reads them.
+func cacheableNode(ctx *sql.Context, n sql.Node, tables *[]string) (bool, error) {
+	switch n := n.(type) {
+	case *plan.ResolvedTable:
+		return cacheableTable(ctx, n.UnderlyingTable(), tables)
+	case *plan.IndexedTableAccess:
+		if ok, err := cacheableTable(ctx, n.UnderlyingTable(), tables); err != nil || !ok {
+			return ok, err
+		}
+		return cacheableExpressions(ctx, n, tables)
+	case *plan.EmptyTable:
+		return true, nil
+	case sql.TableFunction:
+		return false, nil
+	}
+
+	children := n.Children()
+	if len(children) == 0 {
+		// any other leaf reads the state of the server or of the session
+		return false, nil
+	}
+	if ok, err := cacheableExpressions(ctx, n, tables); err != nil || !ok {
+		return ok, err
+	}
+	for _, child := range children {
+		if ok, err := cacheableNode(ctx, child, tables); err != nil || !ok {
+			return ok, err
+		}
+	}
+	return true, nil
+}
+
+// cacheableExpressions returns whether the expressions of |n| are deterministic, and appends the tables of their
+// subqueries to |tables|.
+func cacheableExpressions(ctx *sql.Context, n sql.Node, tables *[]string) (bool, error) {
+	ex, ok := n.(sql.Expressioner)
+	if !ok {
+		return true, nil
+	}
+	for _, e := range ex.Expressions() {
+		if ok, err := cacheableExpression(ctx, e, tables); err != nil || !ok {
+			return ok, err
+		}
+	}
+	return true, nil
+}
+
+// cacheableExpression returns whether |e| is deterministic, and appends the tables of its subqueries to |tables|.
+func cacheableExpression(ctx *sql.Context, e sql.Expression, tables *[]string) (bool, error) {
+	switch e := e.(type) {
+	case *expression.UserVar, *expression.SystemVar:
+		return false, nil
+	case *plan.Subquery:
+		if ok, err := cacheableNode(ctx, e.Query, tables); err != nil || !ok {
+			return ok, err
+		}
+	case sql.FunctionExpression:
+		if _, ok := nondeterministicFunctions[strings.ToLower(e.FunctionName())]; ok {
+			return false, nil
+		}
+	}
+	for _, child := range e.Children() {
+		if ok, err := cacheableExpression(ctx, child, tables); err != nil || !ok {
+			return ok, err
+		}
+	}
+	return true, nil
+}
+
+// cacheableTable returns whether |t| is a dolt table whose rows are the same for every user, and appends its name
+// and the hash of the table it reads to |tables|.
+func cacheableTable(ctx *sql.Context, t sql.Table, tables *[]string) (bool, error) {
+	for {
+		w, ok := t.(sql.TableWrapper)
+		if !ok {
+			break
+		}
+		t = w.Underlying()
+	}
+
+	var dt *dsqle.DoltTable
+	switch t := t.(type) {
+	case *dsqle.DoltTable:
+		dt = t
+	case *dsqle.WritableDoltTable:
+		dt = t.DoltTable
+	case *dsqle.AlterableDoltTable:
+		dt = t.DoltTable
+	case *dsqle.IndexedDoltTable:
+		dt = t.DoltTable
+	case *dsqle.WritableIndexedDoltTable:
+		dt = t.DoltTable
+	default:
+		return false, nil
+	}
+
+	if restricted, err := dt.ReadRestricted(ctx); err != nil || restricted {
+		return false, err
+	}
+	tbl, err := dt.DoltTable(ctx)
+	if err != nil {
+		return false, err
+	}
+	h, err := tbl.HashOf()
+	if err != nil {
+		return false, err
+	}
+	*tables = append(*tables, dt.Name()+"@"+h.String())
+	return true, nil
+}
+
+// resultCacheIter returns the rows of a query, and caches them when the query has returned all of them, unless they
+// do not fit in a cached result.
+type resultCacheIter struct {
+	sql.RowIter
+	cache *ResultCache
+	key   hash.Hash
+
+	rows     []sql.Row
+	size     uint64
+	overflow bool
+}
+
+func (i *resultCacheIter) Next(ctx *sql.Context) (sql.Row, error) {
+	row, err := i.RowIter.Next(ctx)
+	if err == io.EOF {
+		if !i.overflow {
+			i.cache.put(i.key, i.rows, i.size)
+			i.overflow = true
+		}
+		return nil, err
+	} else if err != nil {
+		i.overflow = true
+		i.rows = nil
+		return nil, err
+	}
+
+	if !i.overflow {
+		i.size += rowSize(row)
+		if i.size+entryOverhead > i.cache.maxResultBytes {
+			i.overflow = true
+			i.rows = nil
+		} else {
+			i.rows = append(i.rows, copyRow(row))
+		}
+	}
+	return row, nil
+}
+
+// copyRows returns a copy of |rows|, made with copyRow.
+func copyRows(rows []sql.Row) []sql.Row {
+	ret := make([]sql.Row, len(rows))
+	for i, row := range rows {
+		ret[i] = copyRow(row)
+	}
+	return ret
+}
+
+// copyRow returns a copy of |row| which shares no memory with it, apart from immutable values.
+func copyRow(row sql.Row) sql.Row {
+	ret := row.Copy()
+	for i, v := range ret {
+		if b, ok := v.([]byte); ok {
+			ret[i] = append([]byte(nil), b...)
+		}
+	}
+	return ret
+}
+
+// rowSize approximates the memory of |row|.
+func rowSize(row sql.Row) uint64 {
+	size := uint64(rowOverhead)
+	for _, v := range row {
+		size += valueOverhead
+		switch v := v.(type) {
+		case string:
+			size += uint64(len(v))
+		case []byte:
+			size += uint64(len(v))
+		}
+	}
+	return size
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"io"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	assert.Equal(t, "select * from t where a = 1", normalizeQuery("  select *\n\tfrom t   where a = 1 ;"))
+	assert.Equal(t, "select 'a b'", normalizeQuery("select 'a  b';;"))
+}
+
+func TestResultCache(t *testing.T) {
+	row := sql.Row{int64(1), "abcd"}
+	size := rowSize(row)
+	assert.Equal(t, uint64(rowOverhead+2*valueOverhead+4), size)
+
+	entrySize := 2*size + entryOverhead
+	c := newResultCache(2*entrySize, entrySize)
+	k1, k2, k3 := hash.Of([]byte("1")), hash.Of([]byte("2")), hash.Of([]byte("3"))
+
+	c.put(k1, []sql.Row{row, row}, 2*size)
+	c.put(k2, []sql.Row{row, row}, 2*size)
+	rows, ok := c.get(k1)
+	require.True(t, ok)
+	assert.Len(t, rows, 2)
+
+	// k2 is the least recently used
+	c.put(k3, []sql.Row{row, row}, 2*size)
+	_, ok = c.get(k2)
+	assert.False(t, ok)
+	_, ok = c.get(k1)
+	assert.True(t, ok)
+
+	// results larger than the largest cached result are not cached
+	c.put(k2, []sql.Row{row, row, row}, 3*size)
+	_, ok = c.get(k2)
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Equal(t, uint64(2), stats.Entries)
+	assert.Equal(t, 2*entrySize, stats.Bytes)
+}
+
+func TestResultCacheIter(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	row := sql.Row{int64(1), "abcd"}
+	c := newResultCache(1<<20, 2*rowSize(row)+entryOverhead)
+
+	drain := func(key hash.Hash, rows ...sql.Row) {
+		iter := &resultCacheIter{RowIter: sql.RowsToRowIter(rows...), cache: c, key: key}
+		for {
+			_, err := iter.Next(ctx)
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+		}
+		require.NoError(t, iter.Close(ctx))
+	}
+
+	small, large := hash.Of([]byte("small")), hash.Of([]byte("large"))
+	drain(small, row, row)
+	drain(large, row, row, row)
+
+	rows, ok := c.get(small)
+	require.True(t, ok)
+	assert.Equal(t, []sql.Row{row, row}, rows)
+	_, ok = c.get(large)
+	assert.False(t, ok)
+}
+
+func TestResultCacheCopiesRows(t *testing.T) {
+	row := sql.Row{int64(1), []byte("abcd")}
+	c := newResultCache(1<<20, 1<<20)
+	key := hash.Of([]byte("key"))
+	c.put(key, []sql.Row{row}, rowSize(row))
+
+	rows, ok := c.get(key)
+	require.True(t, ok)
+	rows[0][0] = int64(2)
+	rows[0][1].([]byte)[0] = 'x'
+
+	rows, ok = c.get(key)
+	require.True(t, ok)
+	assert.Equal(t, []sql.Row{{int64(1), []byte("abcd")}}, rows)
+}
+
+func TestResultCacheAccount(t *testing.T) {
+	assert.Equal(t, "alice@10.0.0.1", resultCacheAccount(sql.Client{User: "alice", Address: "10.0.0.1:3306"}))
+	assert.Equal(t, "alice@::1", resultCacheAccount(sql.Client{User: "alice", Address: "[::1]:3306"}))
+	assert.Equal(t, "alice@localhost", resultCacheAccount(sql.Client{User: "alice", Address: "localhost"}))
+	assert.NotEqual(t, resultCacheAccount(sql.Client{User: "alice", Address: "10.0.0.1:3306"}),
+		resultCacheAccount(sql.Client{User: "alice", Address: "10.0.0.2:3306"}))
+}
@@ -42,12 +42,14 @@ import (
 	dblr "github.com/dolthub/dolt/go/libraries/doltcore/sqle/binlogreplication"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/cluster"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/mysql_file_handler"
 	drowexec "github.com/dolthub/dolt/go/libraries/doltcore/sqle/rowexec"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/statsnoms"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/statspro"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/writer"
 	"github.com/dolthub/dolt/go/libraries/utils/config"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
 	"github.com/dolthub/dolt/go/store/types"
 )
 
@@ -57,6 +59,7 @@ type SqlEngine struct {
 	contextFactory contextFactory
 	dsessFactory   sessionFactory
 	engine         *gms.Engine
+	resultCache    *ResultCache
 }
 
 type sessionFactory func(mysqlSess *sql.BaseSession, pro sql.DatabaseProvider) (*dsess.DoltSession, error)
@@ -81,6 +84,8 @@ type SqlEngineConfig struct {
 	ClusterController       *cluster.Controller
 	BinlogReplicaController binlogreplication.BinlogReplicaController
 	EventSchedulerStatus    eventscheduler.SchedulerStatus
+	// ResultCache configures the cache of the results of read-only queries, which is disabled if it is nil.
+	ResultCache servercfg.ResultCacheConfig
 }
 
 // NewSqlEngine returns a SqlEngine
@@ -141,6 +146,7 @@ func NewSqlEngine(
 		pro.InitDatabaseHooks = append(pro.InitDatabaseHooks, cluster.NewInitDatabaseHook(config.ClusterController, bThreads))
 		pro.DropDatabaseHooks = append(pro.DropDatabaseHooks, config.ClusterController.DropDatabaseHook())
 		config.ClusterController.SetDropDatabase(pro.DropDatabase)
+		pro.SetStandbyReadCheck(config.ClusterController.CheckStandbyReadLag)
 	}
 
 	sqlEngine := &SqlEngine{}
@@ -150,8 +156,9 @@ func NewSqlEngine(
 		IsReadOnly:     config.IsReadOnly,
 		IsServerLocked: config.IsServerLocked,
 	}).WithBackgroundThreads(bThreads)
+	engine.Parser = dsqle.NewParser(engine.Parser)
 
-	if err := configureBinlogPrimaryController(engine); err != nil {
+	if err := configureBinlogPrimaryController(engine, mrEnv.FileSystem(), pro); err != nil {
 		return nil, err
 	}
 	pro.AddInitDatabaseHook(dblr.NewBinlogInitDatabaseHook(ctx, doltdb.DatabaseUpdateListeners))
@@ -195,12 +202,18 @@ func NewSqlEngine(
 	statsPro := statspro.NewProvider(pro, statsnoms.NewNomsStatsFactory(mrEnv.RemoteDialProvider()))
 	engine.Analyzer.Catalog.StatsProvider = statsPro
 
-	engine.Analyzer.ExecBuilder = rowexec.NewOverrideBuilder(drowexec.Builder{})
+	execBuilder := queryPlanBuilder{override: drowexec.Builder{}}
+	engine.Analyzer.ExecBuilder = rowexec.NewOverrideBuilder(execBuilder)
+	if config.ResultCache != nil {
+		sqlEngine.resultCache = NewResultCache(config.ResultCache)
+		engine.Analyzer.ExecBuilder = sqlEngine.resultCache.ExecBuilder(execBuilder)
+	}
 	sessFactory := doltSessionFactory(pro, statsPro, mrEnv.Config(), bcController, config.Autocommit)
 	sqlEngine.provider = pro
 	sqlEngine.contextFactory = sqlContextFactory()
 	sqlEngine.dsessFactory = sessFactory
 	sqlEngine.engine = engine
+	pro.SetMaskingGrants(newMaskingGrants(sqlEngine))
 
 	// configuring stats depends on sessionBuilder
 	// sessionBuilder needs ref to statsProv
@@ -322,6 +335,11 @@ func (se *SqlEngine) GetUnderlyingEngine() *gms.Engine {
 	return se.engine
 }
 
+// ResultCache returns the cache of the results of read-only queries, or nil if it is disabled.
+func (se *SqlEngine) ResultCache() *ResultCache {
+	return se.resultCache
+}
+
 func (se *SqlEngine) Close() error {
 	if se.engine != nil {
 		return se.engine.Close()
@@ -343,14 +361,45 @@ func configureBinlogReplicaController(config *SqlEngineConfig, engine *gms.Engin
 }
 
 // configureBinlogPrimaryController configures the |engine| to use the default Dolt binlog primary controller, as well
-// as enabling the binlog producer if @@log_bin has been set to 1.
+// as enabling the binlog producer if @@log_bin has been set to 1. Binary log files are stored in |fs|, and the
+// stored procedures for managing them are registered with |pro|.
 //
 // NOTE: By default, binary logging for Dolt is not enabled, which differs from MySQL's @@log_bin default. Dolt's
 // binary logging is initially an opt-in feature, but we may change that after measuring and tuning the
 // performance hit that binary logging adds.
-func configureBinlogPrimaryController(engine *gms.Engine) error {
+func configureBinlogPrimaryController(engine *gms.Engine, fs filesys.Filesys, pro *dsqle.DoltDatabaseProvider) error {
 	primaryController := dblr.NewDoltBinlogPrimaryController()
 	engine.Analyzer.Catalog.BinlogPrimaryController = primaryController
+	primaryController.RegisterStoredProcedures(pro)
+	dtables.RegisterBinlogReplicaStatusProvider(primaryController)
+
+	// The binlog branch must be set before the binary log files, which are named for it, are opened
+	_, logBinBranchValue, ok := sql.SystemVariables.GetGlobal("log_bin_branch")
+	if !ok {
+		return fmt.Errorf("unable to load @@log_bin_branch system variable")
+	}
+	logBinBranch, ok := logBinBranchValue.(string)
+	if !ok {
+		return fmt.Errorf("unexpected type for @@log_bin_branch system variable: %T", logBinBranchValue)
+	}
+	if logBinBranch != "" {
+		logrus.Debugf("Setting binary logging branch to %s", logBinBranch)
+		dblr.BinlogBranch = logBinBranch
+	}
+
+	_, logBinBranchMappingsValue, ok := sql.SystemVariables.GetGlobal("log_bin_branch_mappings")
+	if !ok {
+		return fmt.Errorf("unable to load @@log_bin_branch_mappings system variable")
+	}
+	logBinBranchMappings, ok := logBinBranchMappingsValue.(string)
+	if !ok {
+		return fmt.Errorf("unexpected type for @@log_bin_branch_mappings system variable: %T", logBinBranchMappingsValue)
+	}
+	branchMappings, err := dblr.ParseBinlogBranchMappings(logBinBranchMappings)
+	if err != nil {
+		return err
+	}
+	dblr.BinlogBranchMappings = branchMappings
 
 	_, logBinValue, ok := sql.SystemVariables.GetGlobal("log_bin")
 	if !ok {
@@ -362,25 +411,18 @@ func configureBinlogPrimaryController(engine *gms.Engine) error {
 	}
 	if logBin == 1 {
 		logrus.Debug("Enabling binary logging")
+		logManager, err := dblr.NewLogManager(fs)
+		if err != nil {
+			return err
+		}
 		binlogProducer, err := dblr.NewBinlogProducer(primaryController.StreamerManager())
 		if err != nil {
 			return err
 		}
+		binlogProducer.LogManager(logManager)
 		doltdb.RegisterDatabaseUpdateListener(binlogProducer)
 		primaryController.BinlogProducer = binlogProducer
-	}
-
-	_, logBinBranchValue, ok := sql.SystemVariables.GetGlobal("log_bin_branch")
-	if !ok {
-		return fmt.Errorf("unable to load @@log_bin_branch system variable")
-	}
-	logBinBranch, ok := logBinBranchValue.(string)
-	if !ok {
-		return fmt.Errorf("unexpected type for @@log_bin_branch system variable: %T", logBinBranchValue)
-	}
-	if logBinBranch != "" {
-		logrus.Debugf("Setting binary logging branch to %s", logBinBranch)
-		dblr.BinlogBranch = logBinBranch
+		primaryController.LogManager(logManager)
 	}
 
 	return nil
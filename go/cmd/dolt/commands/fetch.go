@@ -37,10 +37,14 @@ var fetchDocs = cli.CommandDocumentationContent{
 By default dolt will attempt to fetch from a remote named {{.EmphasisLeft}}origin{{.EmphasisRight}}.  The {{.LessThan}}remote{{.GreaterThan}} parameter allows you to specify the name of a different remote you wish to pull from by the remote's name.
 
 When no refspec(s) are specified on the command line, the fetch_specs for the default remote are used.
+
+{{.LessThan}}remote{{.GreaterThan}} may also be the path to a bundle file written by {{.EmphasisLeft}}dolt bundle create{{.EmphasisRight}}. Its branches are fetched into remote-tracking branches named after the file, e.g. {{.LessThan}}remotes/changes/main{{.GreaterThan}} for {{.LessThan}}changes.bundle{{.GreaterThan}}.
+
+In a partial clone made with {{.EmphasisLeft}}dolt clone --filter{{.EmphasisRight}}, fetches leave out the same data as the clone did. With {{.EmphasisLeft}}--backfill{{.EmphasisRight}}, everything that was left out is fetched as well, and the database becomes a complete clone.
 `,
 
 	Synopsis: []string{
-		"[{{.LessThan}}remote{{.GreaterThan}}] [{{.LessThan}}refspec{{.GreaterThan}} ...]",
+		"[--backfill] [{{.LessThan}}remote{{.GreaterThan}}] [{{.LessThan}}refspec{{.GreaterThan}} ...]",
 	},
 }
 
@@ -145,6 +149,9 @@ func constructInterpolatedDoltFetchQuery(apr *argparser.ArgParseResults) (string
 	if apr.Contains(cli.PruneFlag) {
 		args = append(args, "'--prune'")
 	}
+	if apr.Contains(cli.BackfillFlag) {
+		args = append(args, "'--backfill'")
+	}
 	if user, hasUser := apr.GetValue(cli.UserFlag); hasUser {
 		args = append(args, "'--user'")
 		args = append(args, "?")
@@ -0,0 +1,553 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package patchcmds
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/gocraft/dbr/v2"
+	"github.com/gocraft/dbr/v2/dialect"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+const threeWayFlag = "3way"
+
+var applyDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Apply a patch file to the working set.",
+	LongDesc: `Applies a patch file written by {{.EmphasisLeft}}dolt patch create{{.EmphasisRight}} to the working set of the current branch.
+
+Before anything is changed, every table and row touched by the patch is checked against the patch's base: tables must have the schema they had at the base commit, rows being updated or deleted must still have their old values, and rows being inserted must not exist yet. If every check passes the patch is applied and the changes are left in the working set to be reviewed and committed. The row changes of the patch are applied in a single transaction, so if any of them fails, for example because it violates a foreign key, no rows are changed. Schema changes can't be rolled back, and are applied first: if the row changes then fail, the schema changes are left in the working set.
+
+If any check fails, nothing is changed and the failures are reported. With {{.EmphasisLeft}}--3way{{.EmphasisRight}}, the patch is instead applied to its base commit, which must exist in this database, and the result is merged into the current branch in the same way as {{.EmphasisLeft}}dolt cherry-pick{{.EmphasisRight}}: if the merge is clean it is committed, otherwise conflicts are recorded in {{.EmphasisLeft}}dolt_conflicts{{.EmphasisRight}} for resolution. A three-way apply requires a clean working set.
+`,
+	Synopsis: []string{
+		`[--3way] {{.LessThan}}file{{.GreaterThan}}`,
+	},
+}
+
+type ApplyCmd struct{}
+
+// Name returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd ApplyCmd) Name() string {
+	return "apply"
+}
+
+// Description returns a description of the command
+func (cmd ApplyCmd) Description() string {
+	return "Apply a patch file to the working set."
+}
+
+func (cmd ApplyCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(applyDocs, ap)
+}
+
+func (cmd ApplyCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs(cmd.Name(), 1)
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"file", "The patch file to apply."})
+	ap.SupportsFlag(threeWayFlag, "", "If the patch doesn't apply cleanly, merge it using its base commit and record any conflicts.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd ApplyCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	apr, usage, terminate, status := commands.ParseArgsOrPrintHelp(ap, commandStr, args, applyDocs)
+	if terminate {
+		return status
+	}
+
+	if apr.NArg() == 0 {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: a patch file is required").SetPrintUsage().Build(), usage)
+	}
+
+	f, err := os.Open(apr.Arg(0))
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: unable to open %s", apr.Arg(0)).AddCause(err).Build(), usage)
+	}
+	patch, err := diff.ReadPatchFile(f)
+	f.Close()
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: unable to read patch %s", apr.Arg(0)).AddCause(err).Build(), usage)
+	}
+
+	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if closeFunc != nil {
+		defer closeFunc()
+	}
+
+	a := patchApplier{queryist: queryist, sqlCtx: sqlCtx}
+	problems, err := a.check(patch)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: unable to check patch").AddCause(err).Build(), usage)
+	}
+
+	if len(problems) == 0 {
+		schemaChanged, err := a.applyInTransaction(patch)
+		if err != nil {
+			if schemaChanged {
+				cli.PrintErrln("The schema changes of the patch were left in the working set, but none of its row changes were applied.")
+			}
+			return commands.HandleVErrAndExitCode(errhand.BuildDError("error: failed to apply patch").AddCause(err).Build(), usage)
+		}
+		cli.Printf("Applied patch to %d tables, %d rows changed\n", len(patch.Tables), patch.RowCount())
+		return 0
+	}
+
+	for _, p := range problems {
+		cli.PrintErrln(p)
+	}
+
+	if !apr.Contains(threeWayFlag) {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: patch does not apply cleanly; use --3way to merge it").Build(), usage)
+	}
+
+	cli.Println("Patch does not apply cleanly, falling back to a three-way merge")
+	err = a.applyThreeWay(patch)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: three-way apply failed").AddCause(err).Build(), usage)
+	}
+	return 0
+}
+
+type patchApplier struct {
+	queryist cli.Queryist
+	sqlCtx   *sql.Context
+}
+
+func (a patchApplier) query(q string, params ...interface{}) ([]sql.Row, error) {
+	return commands.InterpolateAndRunQuery(a.queryist, a.sqlCtx, q, params...)
+}
+
+// patchTableInfo describes the current state of a table being patched.
+type patchTableInfo struct {
+	exists     bool
+	createStmt string
+	pkCols     []string
+	jsonCols   map[string]bool
+}
+
+func (a patchApplier) tableInfo(tableName string) (patchTableInfo, error) {
+	rows, err := a.query("show full tables where table_type = 'BASE TABLE'")
+	if err != nil {
+		return patchTableInfo{}, err
+	}
+
+	var info patchTableInfo
+	for _, row := range rows {
+		if strings.EqualFold(colString(row[0]), tableName) {
+			info.exists = true
+		}
+	}
+	if !info.exists {
+		return info, nil
+	}
+
+	rows, err = a.query("show create table ?", dbr.I(tableName))
+	if err != nil {
+		return patchTableInfo{}, err
+	}
+	info.createStmt = colString(rows[0][1])
+
+	q, err := dbr.InterpolateForDialect("select * from ? limit 0", []interface{}{dbr.I(tableName)}, dialect.MySQL)
+	if err != nil {
+		return patchTableInfo{}, err
+	}
+	sch, rowIter, err := a.queryist.Query(a.sqlCtx, q)
+	if err != nil {
+		return patchTableInfo{}, err
+	}
+	if err = rowIter.Close(a.sqlCtx); err != nil {
+		return patchTableInfo{}, err
+	}
+
+	info.jsonCols = make(map[string]bool)
+	for _, col := range sch {
+		if col.PrimaryKey {
+			info.pkCols = append(info.pkCols, col.Name)
+		}
+		if types.IsJSON(col.Type) {
+			info.jsonCols[col.Name] = true
+		}
+	}
+	return info, nil
+}
+
+// check verifies that |patch| applies cleanly to the working set and returns a description of every problem found.
+func (a patchApplier) check(patch *diff.PatchFile) ([]string, error) {
+	var problems []string
+	for _, tp := range patch.Tables {
+		info, err := a.tableInfo(tp.TableName())
+		if err != nil {
+			return nil, err
+		}
+
+		if tp.IsAdd() {
+			if info.exists {
+				problems = append(problems, fmt.Sprintf("table %s: already exists", tp.ToName))
+			}
+			continue
+		}
+		if !info.exists {
+			problems = append(problems, fmt.Sprintf("table %s: does not exist", tp.FromName))
+			continue
+		}
+		if normalizeCreateStmt(info.createStmt) != normalizeCreateStmt(tp.FromCreateStmt) {
+			problems = append(problems, fmt.Sprintf("table %s: schema does not match the patch base", tp.FromName))
+			continue
+		}
+
+		for _, rp := range tp.Rows {
+			problem, err := a.checkRow(tp.FromName, info, rp)
+			if err != nil {
+				return nil, err
+			}
+			if problem != "" {
+				problems = append(problems, problem)
+			}
+		}
+	}
+	return problems, nil
+}
+
+func (a patchApplier) checkRow(tableName string, info patchTableInfo, rp diff.RowPatch) (string, error) {
+	switch rp.Op {
+	case diff.PatchOpInsert:
+		if len(info.pkCols) == 0 {
+			// keyless tables allow duplicate rows, so an insert always applies
+			return "", nil
+		}
+		n, err := a.countMatching(tableName, info, rp.New, info.pkCols)
+		if err != nil {
+			return "", err
+		}
+		if n > 0 {
+			return fmt.Sprintf("table %s: row %s already exists", tableName, describeKey(info.pkCols, rp.New)), nil
+		}
+	case diff.PatchOpUpdate, diff.PatchOpDelete:
+		n, err := a.countMatching(tableName, info, rp.Old, mapKeys(rp.Old))
+		if err != nil {
+			return "", err
+		}
+		if n == 0 {
+			key := describeKey(info.pkCols, rp.Old)
+			return fmt.Sprintf("table %s: row %s does not match the patch base", tableName, key), nil
+		}
+	}
+	return "", nil
+}
+
+func (a patchApplier) countMatching(tableName string, info patchTableInfo, vals map[string]*string, cols []string) (int64, error) {
+	where, params := matchClause(info, vals, cols)
+	rows, err := a.query("select count(*) from ? where "+where, append([]interface{}{dbr.I(tableName)}, params...)...)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	_, err = fmt.Sscan(colString(rows[0][0]), &n)
+	return n, err
+}
+
+// apply applies |patch| to the working set without checking it first.
+func (a patchApplier) apply(patch *diff.PatchFile) error {
+	if _, err := a.applySchema(patch); err != nil {
+		return err
+	}
+	return a.applyRows(patch)
+}
+
+// applySchema runs the schema statements of |patch|, and returns whether it ran any of them.
+func (a patchApplier) applySchema(patch *diff.PatchFile) (bool, error) {
+	ran := false
+	for _, tp := range patch.Tables {
+		for _, stmt := range tp.SchemaStmts {
+			ran = true
+			if _, err := a.query(stmt); err != nil {
+				return ran, fmt.Errorf("table %s: %w", tp.TableName(), err)
+			}
+		}
+	}
+	return ran, nil
+}
+
+// applyRows applies the row changes of |patch|, whose schema statements have been run.
+func (a patchApplier) applyRows(patch *diff.PatchFile) error {
+	for _, tp := range patch.Tables {
+		if tp.IsDrop() || len(tp.Rows) == 0 {
+			continue
+		}
+
+		info, err := a.tableInfo(tp.ToName)
+		if err != nil {
+			return err
+		}
+		for _, rp := range tp.Rows {
+			if err = a.applyRow(tp.ToName, info, rp); err != nil {
+				return fmt.Errorf("table %s: %w", tp.ToName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applyInTransaction applies |patch| to the working set. Its row changes are applied in a transaction, so that a patch
+// which fails part way changes no rows. Schema statements implicitly commit the transaction they are run in, so they
+// are run before the transaction, and are left in the working set if the row changes fail. It returns whether any
+// schema statements were run.
+func (a patchApplier) applyInTransaction(patch *diff.PatchFile) (schemaChanged bool, err error) {
+	if schemaChanged, err = a.applySchema(patch); err != nil {
+		return schemaChanged, err
+	}
+	if _, err = a.query("start transaction"); err != nil {
+		return schemaChanged, err
+	}
+	defer func() {
+		if err != nil {
+			_, _ = a.query("rollback")
+		}
+	}()
+	if err = a.applyRows(patch); err != nil {
+		return schemaChanged, err
+	}
+	_, err = a.query("commit")
+	return schemaChanged, err
+}
+
+func (a patchApplier) applyRow(tableName string, info patchTableInfo, rp diff.RowPatch) error {
+	// keyless tables match every column of the old row, and only change one of any duplicates
+	keyCols := info.pkCols
+	limit := ""
+	if len(keyCols) == 0 {
+		keyCols = mapKeys(rp.Old)
+		limit = " limit 1"
+	}
+
+	switch rp.Op {
+	case diff.PatchOpInsert:
+		cols := mapKeys(rp.New)
+		params := []interface{}{dbr.I(tableName)}
+		var colPlaceholders, valPlaceholders []string
+		for _, c := range cols {
+			colPlaceholders = append(colPlaceholders, "?")
+			params = append(params, dbr.I(c))
+		}
+		for _, c := range cols {
+			valPlaceholders = append(valPlaceholders, valuePlaceholder(info, c))
+			params = append(params, sqlValue(rp.New[c]))
+		}
+		_, err := a.query(fmt.Sprintf("insert into ? (%s) values (%s)", strings.Join(colPlaceholders, ", "), strings.Join(valPlaceholders, ", ")), params...)
+		return err
+
+	case diff.PatchOpUpdate:
+		params := []interface{}{dbr.I(tableName)}
+		var sets []string
+		for _, c := range mapKeys(rp.New) {
+			sets = append(sets, "? = "+valuePlaceholder(info, c))
+			params = append(params, dbr.I(c), sqlValue(rp.New[c]))
+		}
+		where, whereParams := matchClause(info, keyValues(keyCols, rp), keyCols)
+		_, err := a.query(fmt.Sprintf("update ? set %s where %s%s", strings.Join(sets, ", "), where, limit), append(params, whereParams...)...)
+		return err
+
+	case diff.PatchOpDelete:
+		where, params := matchClause(info, rp.Old, keyCols)
+		_, err := a.query("delete from ? where "+where+limit, append([]interface{}{dbr.I(tableName)}, params...)...)
+		return err
+	}
+	return nil
+}
+
+// applyThreeWay applies |patch| to its base commit on a temporary branch and cherry-picks the result onto the current
+// branch, leaving any conflicts in the working set.
+func (a patchApplier) applyThreeWay(patch *diff.PatchFile) (err error) {
+	rows, err := a.query("select count(*) from dolt_status")
+	if err != nil {
+		return err
+	}
+	if colString(rows[0][0]) != "0" {
+		return fmt.Errorf("a three-way apply requires a clean working set; commit or reset your changes first")
+	}
+
+	if _, err = resolveCommit(a.queryist, a.sqlCtx, patch.BaseCommit); err != nil {
+		return fmt.Errorf("the patch base commit %s is not in this database: %w", patch.BaseCommit, err)
+	}
+
+	dbName := a.sqlCtx.GetCurrentDatabase()
+	tmpBranch := fmt.Sprintf("dolt-patch-apply-%d", time.Now().UnixNano())
+	if _, err = a.query("call dolt_branch(?, ?)", tmpBranch, patch.BaseCommit); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = a.query("use ?", dbr.I(dbName))
+		_, _ = a.query("call dolt_branch('-D', ?)", tmpBranch)
+	}()
+
+	if _, err = a.query("use ?", dbr.I(dbName+"/"+tmpBranch)); err != nil {
+		return err
+	}
+	problems, err := a.check(patch)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("patch does not apply to its own base commit %s: %s", patch.BaseCommit, problems[0])
+	}
+	if err = a.apply(patch); err != nil {
+		return err
+	}
+	rows, err = a.query("call dolt_commit('-A', '-m', ?)", fmt.Sprintf("Apply patch %s..%s", patch.BaseCommit, patch.TargetCommit))
+	if err != nil {
+		return err
+	}
+	patchCommit := colString(rows[0][0])
+
+	if _, err = a.query("use ?", dbr.I(dbName)); err != nil {
+		return err
+	}
+	// conflicts are left in the working set for the user to resolve
+	restore, err := a.setSessionFlags("dolt_allow_commit_conflicts", "dolt_force_transaction_commit")
+	if err != nil {
+		return err
+	}
+	defer restore()
+	rows, err = a.query("call dolt_cherry_pick(?)", patchCommit)
+	if err != nil {
+		return err
+	}
+
+	row := rows[0]
+	dataConflicts, schemaConflicts, violations := colString(row[1]), colString(row[2]), colString(row[3])
+	if dataConflicts == "0" && schemaConflicts == "0" && violations == "0" {
+		cli.Printf("Merged patch as commit %s\n", colString(row[0]))
+		return nil
+	}
+
+	cli.Printf("Patch merged with conflicts: %s data conflicts, %s schema conflicts, %s constraint violations.\n", dataConflicts, schemaConflicts, violations)
+	cli.Println("Resolve them using dolt_conflicts, then commit the result.")
+	return nil
+}
+
+// setSessionFlags sets the boolean session variables |names| and returns a function which restores their values.
+func (a patchApplier) setSessionFlags(names ...string) (func(), error) {
+	var prev []string
+	restore := func() {
+		for i, v := range prev {
+			_, _ = a.query(fmt.Sprintf("set @@session.%s = %s", names[i], v))
+		}
+	}
+	for _, name := range names {
+		rows, err := a.query(fmt.Sprintf("select @@session.%s", name))
+		if err != nil {
+			restore()
+			return nil, err
+		}
+		v := "0"
+		switch strings.ToLower(colString(rows[0][0])) {
+		case "1", "on", "true":
+			v = "1"
+		}
+		if _, err = a.query(fmt.Sprintf("set @@session.%s = 1", name)); err != nil {
+			restore()
+			return nil, err
+		}
+		prev = append(prev, v)
+	}
+	return restore, nil
+}
+
+// matchClause returns a where clause, and its parameters, matching |cols| of a row against |vals| with null safe
+// equality.
+func matchClause(info patchTableInfo, vals map[string]*string, cols []string) (string, []interface{}) {
+	var conds []string
+	var params []interface{}
+	for _, c := range cols {
+		conds = append(conds, "? <=> "+valuePlaceholder(info, c))
+		params = append(params, dbr.I(c), sqlValue(vals[c]))
+	}
+	return strings.Join(conds, " and "), params
+}
+
+func valuePlaceholder(info patchTableInfo, col string) string {
+	if info.jsonCols[col] {
+		return "cast(? as json)"
+	}
+	return "?"
+}
+
+func sqlValue(v *string) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// keyValues returns the values used to find the existing row for an update. Key values come from the old row, falling
+// back to the new row for key columns that didn't exist at the base.
+func keyValues(keyCols []string, rp diff.RowPatch) map[string]*string {
+	vals := make(map[string]*string, len(keyCols))
+	for _, c := range keyCols {
+		if v, ok := rp.Old[c]; ok {
+			vals[c] = v
+		} else {
+			vals[c] = rp.New[c]
+		}
+	}
+	return vals
+}
+
+func describeKey(pkCols []string, vals map[string]*string) string {
+	if len(pkCols) == 0 {
+		pkCols = mapKeys(vals)
+	}
+	parts := make([]string, len(pkCols))
+	for i, c := range pkCols {
+		if v := vals[c]; v != nil {
+			parts[i] = *v
+		} else {
+			parts[i] = "NULL"
+		}
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func normalizeCreateStmt(stmt string) string {
+	return strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+}
+
+// mapKeys returns the keys of |m| in sorted order.
+func mapKeys(m map[string]*string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
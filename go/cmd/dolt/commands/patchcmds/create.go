@@ -0,0 +1,353 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package patchcmds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/gocraft/dbr/v2"
+	"github.com/gocraft/dbr/v2/dialect"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands"
+	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/utils/argparser"
+)
+
+const outputParam = "output"
+
+var createDocs = cli.CommandDocumentationContent{
+	ShortDesc: "Write the changes between two commits to a patch file.",
+	LongDesc: `Writes the schema and data changes between {{.LessThan}}from_commit{{.GreaterThan}} and {{.LessThan}}to_commit{{.GreaterThan}} to a patch file that can be applied to another database with {{.EmphasisLeft}}dolt patch apply{{.EmphasisRight}}. {{.LessThan}}to_commit{{.GreaterThan}} defaults to {{.EmphasisLeft}}HEAD{{.EmphasisRight}}.
+
+Unlike the SQL produced by {{.EmphasisLeft}}dolt_patch(){{.EmphasisRight}} or {{.EmphasisLeft}}dolt diff -r sql{{.EmphasisRight}}, a patch file records the base commit and the old value of every changed row, so {{.EmphasisLeft}}dolt patch apply{{.EmphasisRight}} can verify that the patch applies cleanly, and fall back to a three-way merge when it doesn't.
+
+The patch is written to stdout unless {{.EmphasisLeft}}--output{{.EmphasisRight}} is given. Changes to Dolt system tables, including views and triggers, are not included.
+`,
+	Synopsis: []string{
+		`[-o {{.LessThan}}file{{.GreaterThan}}] {{.LessThan}}from_commit{{.GreaterThan}} [{{.LessThan}}to_commit{{.GreaterThan}}]`,
+	},
+}
+
+type CreateCmd struct{}
+
+// Name returns the name of the Dolt cli command. This is what is used on the command line to invoke the command
+func (cmd CreateCmd) Name() string {
+	return "create"
+}
+
+// Description returns a description of the command
+func (cmd CreateCmd) Description() string {
+	return "Write the changes between two commits to a patch file."
+}
+
+func (cmd CreateCmd) Docs() *cli.CommandDocumentation {
+	ap := cmd.ArgParser()
+	return cli.NewCommandDocumentation(createDocs, ap)
+}
+
+func (cmd CreateCmd) ArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParserWithMaxArgs(cmd.Name(), 2)
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"from_commit", "The base commit of the patch."})
+	ap.ArgListHelp = append(ap.ArgListHelp, [2]string{"to_commit", "The commit whose changes are written to the patch. Defaults to HEAD."})
+	ap.SupportsString(outputParam, "o", "file", "Write the patch to this file instead of stdout.")
+	return ap
+}
+
+// Exec executes the command
+func (cmd CreateCmd) Exec(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv, cliCtx cli.CliContext) int {
+	ap := cmd.ArgParser()
+	apr, usage, terminate, status := commands.ParseArgsOrPrintHelp(ap, commandStr, args, createDocs)
+	if terminate {
+		return status
+	}
+
+	if apr.NArg() == 0 {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: a base commit is required").SetPrintUsage().Build(), usage)
+	}
+	toRef := "HEAD"
+	if apr.NArg() == 2 {
+		toRef = apr.Arg(1)
+	}
+
+	queryist, sqlCtx, closeFunc, err := cliCtx.QueryEngine(ctx)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
+	}
+	if closeFunc != nil {
+		defer closeFunc()
+	}
+
+	patch, err := createPatch(queryist, sqlCtx, apr.Arg(0), toRef)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: unable to create patch").AddCause(err).Build(), usage)
+	}
+
+	var wr io.Writer = cli.CliOut
+	if outFile, ok := apr.GetValue(outputParam); ok {
+		f, err := os.Create(outFile)
+		if err != nil {
+			return commands.HandleVErrAndExitCode(errhand.BuildDError("error: unable to create %s", outFile).AddCause(err).Build(), usage)
+		}
+		defer f.Close()
+		wr = f
+	}
+
+	err = patch.Write(wr)
+	if err != nil {
+		return commands.HandleVErrAndExitCode(errhand.BuildDError("error: unable to write patch").AddCause(err).Build(), usage)
+	}
+
+	return 0
+}
+
+func createPatch(queryist cli.Queryist, sqlCtx *sql.Context, fromRef, toRef string) (*diff.PatchFile, error) {
+	fromHash, err := resolveCommit(queryist, sqlCtx, fromRef)
+	if err != nil {
+		return nil, err
+	}
+	toHash, err := resolveCommit(queryist, sqlCtx, toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := patchTables(queryist, sqlCtx, fromHash, toHash)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := diff.NewPatchFile(fromHash, toHash)
+	for _, tp := range tables {
+		if !tp.IsAdd() {
+			tp.FromCreateStmt, err = createStmtAsOf(queryist, sqlCtx, tp.FromName, fromHash)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !tp.IsDrop() {
+			tp.Rows, err = patchRows(queryist, sqlCtx, tp, fromHash, toHash)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(tp.SchemaStmts) > 0 || len(tp.Rows) > 0 {
+			patch.Tables = append(patch.Tables, *tp)
+		}
+	}
+
+	return patch, nil
+}
+
+func resolveCommit(queryist cli.Queryist, sqlCtx *sql.Context, ref string) (string, error) {
+	rows, err := commands.InterpolateAndRunQuery(queryist, sqlCtx, "select hashof(?)", ref)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve commit '%s': %w", ref, err)
+	}
+	if len(rows) == 0 {
+		return "", fmt.Errorf("unable to resolve commit '%s'", ref)
+	}
+	return fmt.Sprint(rows[0][0]), nil
+}
+
+// patchTables returns an entry for every user table changed between the two commits, with its schema statements
+// filled in. Tables with schema changes come first, in the order dolt_patch() emits them, so that dependencies between
+// tables are respected.
+func patchTables(queryist cli.Queryist, sqlCtx *sql.Context, fromHash, toHash string) ([]*diff.TablePatch, error) {
+	summaries, err := commands.InterpolateAndRunQuery(queryist, sqlCtx,
+		"select from_table_name, to_table_name from dolt_diff_summary(?, ?)", fromHash, toHash)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*diff.TablePatch)
+	var unordered []*diff.TablePatch
+	for _, row := range summaries {
+		tp := &diff.TablePatch{FromName: colString(row[0]), ToName: colString(row[1])}
+		if doltdb.HasDoltPrefix(tp.TableName()) {
+			continue
+		}
+		if tp.FromName != "" {
+			byName[tp.FromName] = tp
+		}
+		if tp.ToName != "" {
+			byName[tp.ToName] = tp
+		}
+		unordered = append(unordered, tp)
+	}
+
+	stmts, err := commands.InterpolateAndRunQuery(queryist, sqlCtx,
+		"select table_name, statement from dolt_patch(?, ?) where diff_type = 'schema' order by statement_order", fromHash, toHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []*diff.TablePatch
+	seen := make(map[*diff.TablePatch]bool)
+	for _, row := range stmts {
+		tp, ok := byName[colString(row[0])]
+		if !ok {
+			continue
+		}
+		tp.SchemaStmts = append(tp.SchemaStmts, colString(row[1]))
+		if !seen[tp] {
+			seen[tp] = true
+			tables = append(tables, tp)
+		}
+	}
+	for _, tp := range unordered {
+		if !seen[tp] {
+			tables = append(tables, tp)
+		}
+	}
+
+	return tables, nil
+}
+
+func createStmtAsOf(queryist cli.Queryist, sqlCtx *sql.Context, tableName, ref string) (string, error) {
+	rows, err := commands.InterpolateAndRunQuery(queryist, sqlCtx, "show create table ? as of ?", dbr.I(tableName), ref)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) != 1 {
+		return "", fmt.Errorf("expected 1 row for create statement of %s, got %d", tableName, len(rows))
+	}
+	return colString(rows[0][1]), nil
+}
+
+// tableColumnsAsOf returns the schema of |tableName| at |ref|.
+func tableColumnsAsOf(queryist cli.Queryist, sqlCtx *sql.Context, tableName, ref string) (sql.Schema, error) {
+	q, err := dbr.InterpolateForDialect("select * from ? as of ? limit 0", []interface{}{dbr.I(tableName), ref}, dialect.MySQL)
+	if err != nil {
+		return nil, err
+	}
+	sch, rowIter, err := queryist.Query(sqlCtx, q)
+	if err != nil {
+		return nil, err
+	}
+	return sch, rowIter.Close(sqlCtx)
+}
+
+// patchRows returns the row changes to |tp| between the two commits.
+func patchRows(queryist cli.Queryist, sqlCtx *sql.Context, tp *diff.TablePatch, fromHash, toHash string) ([]diff.RowPatch, error) {
+	var fromSch, toSch sql.Schema
+	var err error
+	if !tp.IsAdd() {
+		fromSch, err = tableColumnsAsOf(queryist, sqlCtx, tp.FromName, fromHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	toSch, err = tableColumnsAsOf(queryist, sqlCtx, tp.ToName, toHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var cols []string
+	var params []interface{}
+	for _, col := range fromSch {
+		cols = append(cols, "?")
+		params = append(params, dbr.I("from_"+col.Name))
+	}
+	for _, col := range toSch {
+		cols = append(cols, "?")
+		params = append(params, dbr.I("to_"+col.Name))
+	}
+	params = append(params, fromHash, toHash, tp.ToName)
+
+	q, err := dbr.InterpolateForDialect(fmt.Sprintf("select %s, diff_type from dolt_diff(?, ?, ?)", strings.Join(cols, ", ")), params, dialect.MySQL)
+	if err != nil {
+		return nil, err
+	}
+	sch, rowIter, err := queryist.Query(sqlCtx, q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sql.RowIterToRows(sqlCtx, rowIter)
+	if err != nil {
+		return nil, err
+	}
+
+	split := len(fromSch)
+	var patchRows []diff.RowPatch
+	for _, row := range rows {
+		var rp diff.RowPatch
+		switch colString(row[len(row)-1]) {
+		case "added":
+			rp.Op = diff.PatchOpInsert
+		case "removed":
+			rp.Op = diff.PatchOpDelete
+		case "modified":
+			rp.Op = diff.PatchOpUpdate
+		default:
+			return nil, fmt.Errorf("unexpected diff type %v", row[len(row)-1])
+		}
+
+		if rp.Op != diff.PatchOpInsert {
+			rp.Old, err = rowImage(tp.TableName(), fromSch, sch[:split], row[:split])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if rp.Op != diff.PatchOpDelete {
+			rp.New, err = rowImage(tp.TableName(), toSch, sch[split:len(sch)-1], row[split:len(row)-1])
+			if err != nil {
+				return nil, err
+			}
+		}
+		patchRows = append(patchRows, rp)
+	}
+
+	return patchRows, nil
+}
+
+// rowImage converts |row|, read with the diff query schema |querySch|, to the column values of a RowPatch.
+func rowImage(tableName string, tableSch, querySch sql.Schema, row sql.Row) (map[string]*string, error) {
+	image := make(map[string]*string, len(tableSch))
+	for i, col := range tableSch {
+		if row[i] == nil {
+			image[col.Name] = nil
+			continue
+		}
+
+		str, err := sqlutil.SqlColToStr(querySch[i].Type, row[i])
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.ValidString(str) {
+			return nil, fmt.Errorf("table %s: column %s contains binary data, which patch files do not support", tableName, col.Name)
+		}
+		image[col.Name] = &str
+	}
+	return image, nil
+}
+
+func colString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -30,7 +30,7 @@ import (
 func TestGetAbsRemoteUrl(t *testing.T) {
 	cwd := osutil.PathToNative("/User/name/datasets")
 	testRepoDir := filepath.Join(cwd, "test-repo")
-	fs := filesys.NewInMemFS([]string{cwd, testRepoDir}, nil, cwd)
+	fs := filesys.NewInMemFS([]string{cwd, testRepoDir}, map[string][]byte{filepath.Join(cwd, "data.bundle"): []byte("bundle")}, cwd)
 	if osutil.IsWindows {
 		cwd = filepath.ToSlash(cwd)
 	}
@@ -111,6 +111,27 @@ func TestGetAbsRemoteUrl(t *testing.T) {
 			"file",
 			false,
 		},
+		{
+			"data.bundle",
+			config.NewMapConfig(map[string]string{}),
+			fmt.Sprintf("bundle://%s/data.bundle", cwd),
+			"bundle",
+			false,
+		},
+		{
+			"file://./data.bundle",
+			config.NewMapConfig(map[string]string{}),
+			fmt.Sprintf("bundle://%s/data.bundle", cwd),
+			"bundle",
+			false,
+		},
+		{
+			"bundle://./missing.bundle",
+			config.NewMapConfig(map[string]string{}),
+			"",
+			"",
+			true,
+		},
 		{
 			":/:/:/", // intended to fail earl.Parse
 			config.NewMapConfig(map[string]string{}),
@@ -55,6 +55,8 @@ const (
 	floatThresholdParam = "float-threshold"
 	keepTypesParam      = "keep-types"
 	delimParam          = "delim"
+	inferParam          = "infer"
+	sampleRowsParam     = "sample-rows"
 )
 
 var MappingFileHelp = "A mapping file is json in the format:" + `
@@ -69,7 +71,7 @@ where source_field_name is the name of a field in the file being imported and de
 
 var schImportDocs = cli.CommandDocumentationContent{
 	ShortDesc: "Creates or updates a table by inferring a schema from a file containing sample data.",
-	LongDesc: `If {{.EmphasisLeft}}--create | -c{{.EmphasisRight}} is given the operation will create {{.LessThan}}table{{.GreaterThan}} with a schema that it infers from the supplied file. One or more primary key columns must be specified using the {{.EmphasisLeft}}--pks{{.EmphasisRight}} parameter.
+	LongDesc: `If {{.EmphasisLeft}}--create | -c{{.EmphasisRight}} is given the operation will create {{.LessThan}}table{{.GreaterThan}} with a schema that it infers from the supplied file. One or more primary key columns must be specified using the {{.EmphasisLeft}}--pks{{.EmphasisRight}} parameter, unless {{.EmphasisLeft}}--infer{{.EmphasisRight}} is given.
 
 If {{.EmphasisLeft}}--update | -u{{.EmphasisRight}} is given the operation will update {{.LessThan}}table{{.GreaterThan}} any additional columns, or change the types of columns based on the file supplied.  If the {{.EmphasisLeft}}--keep-types{{.EmphasisRight}} parameter is supplied then the types for existing columns will not be modified, even if they differ from what is in the supplied file.
 
@@ -83,11 +85,14 @@ In create, update, and replace scenarios the file's extension is used to infer t
 
 If the parameter {{.EmphasisLeft}}--dry-run{{.EmphasisRight}} is supplied a sql statement will be generated showing what would be executed if this were run without the --dry-run flag
 
+If {{.EmphasisLeft}}--infer{{.EmphasisRight}} is given along with {{.EmphasisLeft}}--create{{.EmphasisRight}}, the file is profiled to propose VARCHAR lengths with headroom, ENUMs for low-cardinality columns, NOT NULL constraints and, when {{.EmphasisLeft}}--pks{{.EmphasisRight}} is not given, a single or composite primary key made of columns whose values are unique and never empty, and UNIQUE keys for the other unique columns. Up to {{.EmphasisLeft}}--sample-rows{{.EmphasisRight}} rows are profiled, and NOT NULL constraints and UNIQUE keys are only inferred when the whole file fits in the sample. The reason for each choice is printed as a comment before the generated statement, so {{.EmphasisLeft}}--infer --dry-run{{.EmphasisRight}} previews the table without creating it.
+
 {{.EmphasisLeft}}--float-threshold{{.EmphasisRight}} is the threshold at which a string representing a floating point number should be interpreted as a float versus an int.  If FloatThreshold is 0.0 then any number with a decimal point will be interpreted as a float (such as 0.0, 1.0, etc).  If FloatThreshold is 1.0 then any number with a decimal point will be converted to an int (0.5 will be the int 0, 1.99 will be the int 1, etc.  If the FloatThreshold is 0.001 then numbers with a fractional component greater than or equal to 0.001 will be treated as a float (1.0 would be an int, 1.0009 would be an int, 1.001 would be a float, 1.1 would be a float, etc)
 `,
 
 	Synopsis: []string{
 		`[--create|--replace] [--force] [--dry-run] [--lower|--upper] [--keep-types] [--file-type <type>] [--float-threshold] [--map {{.LessThan}}mapping-file{{.GreaterThan}}] [--delim {{.LessThan}}delimiter{{.GreaterThan}}]--pks {{.LessThan}}field{{.GreaterThan}},... {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}`,
+		`--create --infer [--sample-rows {{.LessThan}}n{{.GreaterThan}}] [--dry-run] [--file-type <type>] [--float-threshold] [--map {{.LessThan}}mapping-file{{.GreaterThan}}] [--delim {{.LessThan}}delimiter{{.GreaterThan}}] [--pks {{.LessThan}}field{{.GreaterThan}},...] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}`,
 	},
 }
 
@@ -110,6 +115,8 @@ type importOptions struct {
 	keepTypes      bool
 	colMapper      rowconv.NameMapper
 	floatThreshold float64
+	infer          bool
+	sampleRows     int
 }
 
 func (im *importOptions) ColNameMapper() rowconv.NameMapper {
@@ -155,6 +162,8 @@ func (cmd ImportCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsString(mappingParam, "m", "mapping-file", "A file that can map a column name in {{.LessThan}}file{{.GreaterThan}} to a new value.")
 	ap.SupportsString(floatThresholdParam, "", "float", "Minimum value at which the fractional component of a value must exceed in order to be considered a float.")
 	ap.SupportsString(delimParam, "", "delimiter", "Specify a delimiter for a csv style file with a non-comma delimiter.")
+	ap.SupportsFlag(inferParam, "", "Profile the {{.LessThan}}file{{.GreaterThan}} to infer a primary key, unique keys, string lengths, enums and NOT NULL constraints. Can only be used with --create.")
+	ap.SupportsInt(sampleRowsParam, "", "n", fmt.Sprintf("The number of rows profiled by --%s. Defaults to %d.", inferParam, actions.DefaultInferenceSampleRows))
 	return ap
 }
 
@@ -208,6 +217,18 @@ func getSchemaImportArgs(ctx context.Context, apr *argparser.ArgParseResults, dE
 		return nil, errhand.BuildDError("error: parameter keep-types not supported for create operations").AddDetails("keep-types parameter is used to keep the existing column types as is without modification.").Build()
 	}
 
+	infer := apr.Contains(inferParam)
+	if infer && op != CreateOp {
+		return nil, errhand.BuildDError("error: parameter %s is only supported for create operations", inferParam).Build()
+	}
+
+	sampleRows, hasSampleRows := apr.GetInt(sampleRowsParam)
+	if hasSampleRows && !infer {
+		return nil, errhand.BuildDError("error: parameter %s requires %s", sampleRowsParam, inferParam).Build()
+	} else if hasSampleRows && sampleRows <= 0 {
+		return nil, errhand.BuildDError("error: %s must be a positive number of rows", sampleRowsParam).Build()
+	}
+
 	tbl, tblExists, err := root.GetTable(ctx, doltdb.TableName{Name: tblName})
 
 	if err != nil {
@@ -246,10 +267,10 @@ func getSchemaImportArgs(ctx context.Context, apr *argparser.ArgParseResults, dE
 	pks := funcitr.MapStrings(strings.Split(val, ","), strings.TrimSpace)
 	pks = funcitr.FilterStrings(pks, func(s string) bool { return s != "" })
 
-	if !pksOK {
+	if !pksOK && !infer {
 		return nil, errhand.BuildDError("error: missing required parameter pks").SetPrintUsage().Build()
 	}
-	if len(pks) == 0 {
+	if pksOK && len(pks) == 0 {
 		return nil, errhand.BuildDError("error: no valid columns provided in --pks argument").Build()
 	}
 
@@ -278,6 +299,8 @@ func getSchemaImportArgs(ctx context.Context, apr *argparser.ArgParseResults, dE
 		keepTypes:      apr.Contains(keepTypesParam),
 		colMapper:      colMapper,
 		floatThreshold: floatThreshold,
+		infer:          infer,
+		sampleRows:     sampleRows,
 	}, nil
 }
 
@@ -292,7 +315,7 @@ func importSchema(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPars
 		return verr
 	}
 
-	sch, verr := inferSchemaFromFile(ctx, dEnv.DoltDB.ValueReadWriter().Format(), impArgs, root)
+	sch, inferred, verr := inferSchemaFromFile(ctx, dEnv.DoltDB.ValueReadWriter().Format(), impArgs, root)
 	if verr != nil {
 		return verr
 	}
@@ -310,6 +333,11 @@ func importSchema(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPars
 	if err != nil {
 		return errhand.VerboseErrorFromError(err)
 	}
+	if inferred != nil {
+		for _, line := range inferred.Explain() {
+			cli.Println("-- " + line)
+		}
+	}
 	cli.Println(stmt)
 
 	if !apr.Contains(dryRunFlag) {
@@ -361,7 +389,9 @@ func putEmptyTableWithSchema(ctx context.Context, tblName string, root doltdb.Ro
 	return root, nil
 }
 
-func inferSchemaFromFile(ctx context.Context, nbf *types.NomsBinFormat, impOpts *importOptions, root doltdb.RootValue) (schema.Schema, errhand.VerboseError) {
+// inferSchemaFromFile infers a schema from the file in |impOpts|. When profiling inference was requested, the returned
+// *actions.InferredSchema describes the choices that were made; otherwise it is nil.
+func inferSchemaFromFile(ctx context.Context, nbf *types.NomsBinFormat, impOpts *importOptions, root doltdb.RootValue) (schema.Schema, *actions.InferredSchema, errhand.VerboseError) {
 	if impOpts.fileType[0] == '.' {
 		impOpts.fileType = impOpts.fileType[1:]
 	}
@@ -377,13 +407,13 @@ func inferSchemaFromFile(ctx context.Context, nbf *types.NomsBinFormat, impOpts
 	case "psv":
 		csvInfo.SetDelim("|")
 	default:
-		return nil, errhand.BuildDError("error: unsupported file type '%s'", impOpts.fileType).Build()
+		return nil, nil, errhand.BuildDError("error: unsupported file type '%s'", impOpts.fileType).Build()
 	}
 
 	f, err := os.Open(impOpts.fileName)
 
 	if err != nil {
-		return nil, errhand.BuildDError("error: failed to open '%s'", impOpts.fileName).Build()
+		return nil, nil, errhand.BuildDError("error: failed to open '%s'", impOpts.fileName).Build()
 	}
 
 	defer f.Close()
@@ -391,18 +421,39 @@ func inferSchemaFromFile(ctx context.Context, nbf *types.NomsBinFormat, impOpts
 	rd, err = csv.NewCSVReader(nbf, f, csvInfo)
 
 	if err != nil {
-		return nil, errhand.BuildDError("error: failed to create a CSVReader.").AddCause(err).Build()
+		return nil, nil, errhand.BuildDError("error: failed to create a CSVReader.").AddCause(err).Build()
 	}
 
 	defer rd.Close(ctx)
 
+	if impOpts.infer {
+		inferred, err := actions.InferSchemaFromTableReader(ctx, rd, impOpts, impOpts.sampleRows)
+		if err != nil {
+			return nil, nil, errhand.BuildDError("error: failed to infer schema").AddCause(err).Build()
+		}
+
+		if len(impOpts.PkCols) == 0 {
+			if len(inferred.PrimaryKey) == 0 {
+				return nil, nil, errhand.BuildDError("error: failed to infer a primary key").AddDetails(inferred.PrimaryKeyReason).Build()
+			}
+			impOpts.PkCols = inferred.PrimaryKey
+		}
+
+		sch, verr := CombineColCollections(ctx, root, inferred.ColCollection(), impOpts)
+		if verr != nil {
+			return nil, nil, verr
+		}
+		return sch, inferred, nil
+	}
+
 	infCols, err := actions.InferColumnTypesFromTableReader(ctx, rd, impOpts)
 
 	if err != nil {
-		return nil, errhand.BuildDError("error: failed to infer schema").AddCause(err).Build()
+		return nil, nil, errhand.BuildDError("error: failed to infer schema").AddCause(err).Build()
 	}
 
-	return CombineColCollections(ctx, root, infCols, impOpts)
+	sch, verr := CombineColCollections(ctx, root, infCols, impOpts)
+	return sch, nil, verr
 }
 
 func CombineColCollections(ctx context.Context, root doltdb.RootValue, inferredCols *schema.ColCollection, impOpts *importOptions) (schema.Schema, errhand.VerboseError) {
@@ -0,0 +1,513 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+)
+
+// The kinds of events in the audit log.
+const (
+	auditEventConnect           = "connect"
+	auditEventConnectionAborted = "connection_aborted"
+	auditEventAuthSuccess       = "auth_success"
+	auditEventAuthFailure       = "auth_failure"
+	auditEventDisconnect        = "disconnect"
+	auditEventStatement         = "statement"
+)
+
+// auditEvent is a single entry in the audit log. It is written to the audit
+// log file as one line of JSON.
+type auditEvent struct {
+	Time           time.Time `json:"time"`
+	Event          string    `json:"event"`
+	ConnectionID   uint32    `json:"connection_id"`
+	User           string    `json:"user,omitempty"`
+	Host           string    `json:"host,omitempty"`
+	Database       string    `json:"database,omitempty"`
+	Branch         string    `json:"branch,omitempty"`
+	StatementType  string    `json:"statement_type,omitempty"`
+	StatementClass string    `json:"statement_class,omitempty"`
+	Query          string    `json:"query,omitempty"`
+	Tables         []string  `json:"tables,omitempty"`
+	RowsAffected   *uint64   `json:"rows_affected,omitempty"`
+	RowsReturned   *uint64   `json:"rows_returned,omitempty"`
+	DurationMs     *float64  `json:"duration_ms,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// auditSink is a destination for audit log events.
+type auditSink interface {
+	write(events []auditEvent) error
+	Close() error
+}
+
+// sessionInfoFunc returns the current database and active branch of the
+// session of the connection |connID|.
+type sessionInfoFunc func(connID uint32) (database, branch string)
+
+// auditLog records connection events, authentication attempts and
+// statements of the clients of sql-server. It wraps the mysql.Handler of the
+// server to see them.
+type auditLog struct {
+	lgr          *logrus.Entry
+	users        map[string]struct{}
+	excludeUsers map[string]struct{}
+	classes      map[string]struct{}
+	sinks        []auditSink
+	sessionInfo  sessionInfoFunc
+
+	mu    sync.Mutex
+	conns map[uint32]*auditConn
+}
+
+// auditConn is the state the audit log keeps for each connection.
+type auditConn struct {
+	user          string
+	host          string
+	authenticated bool
+}
+
+func newAuditLog(lgr *logrus.Entry, cfg servercfg.AuditLogConfig, sinks []auditSink, sessionInfo sessionInfoFunc) *auditLog {
+	toSet := func(vals []string) map[string]struct{} {
+		if len(vals) == 0 {
+			return nil
+		}
+		ret := make(map[string]struct{}, len(vals))
+		for _, v := range vals {
+			ret[v] = struct{}{}
+		}
+		return ret
+	}
+	classes := make([]string, len(cfg.StatementClasses()))
+	for i, c := range cfg.StatementClasses() {
+		classes[i] = strings.ToLower(c)
+	}
+	return &auditLog{
+		lgr:          lgr,
+		users:        toSet(cfg.Users()),
+		excludeUsers: toSet(cfg.ExcludeUsers()),
+		classes:      toSet(classes),
+		sinks:        sinks,
+		sessionInfo:  sessionInfo,
+		conns:        make(map[uint32]*auditConn),
+	}
+}
+
+// WrapHandler returns a mysql.Handler which records the events of |h| in
+// the audit log.
+func (l *auditLog) WrapHandler(h mysql.Handler) (mysql.Handler, error) {
+	return &auditHandler{Handler: h, log: l}, nil
+}
+
+// Close closes the sinks of the audit log, flushing any pending events.
+func (l *auditLog) Close() error {
+	var errs []error
+	for _, s := range l.sinks {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// userIsAudited returns true if the events of |user| should be logged. The
+// user of a connection is not known until it authenticates, so events without
+// a user are only logged if the audit log is not restricted to some users.
+func (l *auditLog) userIsAudited(user string) bool {
+	if _, ok := l.excludeUsers[user]; ok && user != "" {
+		return false
+	}
+	if l.users == nil {
+		return true
+	}
+	_, ok := l.users[user]
+	return ok
+}
+
+func (l *auditLog) classIsAudited(class string) bool {
+	if l.classes == nil {
+		return true
+	}
+	_, ok := l.classes[class]
+	return ok
+}
+
+func (l *auditLog) emit(ev auditEvent) {
+	if !l.userIsAudited(ev.User) {
+		return
+	}
+	for _, s := range l.sinks {
+		if err := s.write([]auditEvent{ev}); err != nil {
+			l.lgr.Warnf("error writing to audit log: %v", err)
+		}
+	}
+}
+
+func (l *auditLog) conn(c *mysql.Conn) *auditConn {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ac, ok := l.conns[c.ConnectionID]
+	if !ok {
+		ac = &auditConn{host: connHost(c)}
+		l.conns[c.ConnectionID] = ac
+	}
+	return ac
+}
+
+func (l *auditLog) newConnection(c *mysql.Conn) {
+	ac := l.conn(c)
+	l.emit(auditEvent{
+		Time:         time.Now(),
+		Event:        auditEventConnect,
+		ConnectionID: c.ConnectionID,
+		Host:         ac.host,
+	})
+}
+
+func (l *auditLog) connectionClosed(c *mysql.Conn) {
+	ac := l.conn(c)
+	l.mu.Lock()
+	delete(l.conns, c.ConnectionID)
+	l.mu.Unlock()
+	l.emit(auditEvent{
+		Time:         time.Now(),
+		Event:        auditEventDisconnect,
+		ConnectionID: c.ConnectionID,
+		User:         ac.user,
+		Host:         ac.host,
+	})
+}
+
+var accessDeniedUserRegex = regexp.MustCompile(`user '([^']*)'`)
+
+// connectionAborted is called when a connection fails before it is
+// established, which is usually because the client failed to authenticate.
+func (l *auditLog) connectionAborted(c *mysql.Conn, reason string) {
+	ac := l.conn(c)
+	ev := auditEvent{
+		Time:         time.Now(),
+		Event:        auditEventConnectionAborted,
+		ConnectionID: c.ConnectionID,
+		User:         c.User,
+		Host:         ac.host,
+		Error:        reason,
+	}
+	lower := strings.ToLower(reason)
+	if strings.Contains(lower, "authenticat") || strings.Contains(lower, "access denied") {
+		ev.Event = auditEventAuthFailure
+		if ev.User == "" {
+			if m := accessDeniedUserRegex.FindStringSubmatch(reason); m != nil {
+				ev.User = m[1]
+			}
+		}
+	}
+	l.emit(ev)
+}
+
+// initDB is called when a connection sets its database, which it first does
+// right after it authenticates.
+func (l *auditLog) initDB(c *mysql.Conn, schemaName string) {
+	ac := l.conn(c)
+	if ac.authenticated {
+		return
+	}
+	ac.authenticated = true
+	ac.user = c.User
+	l.emit(auditEvent{
+		Time:         time.Now(),
+		Event:        auditEventAuthSuccess,
+		ConnectionID: c.ConnectionID,
+		User:         ac.user,
+		Host:         ac.host,
+		Database:     schemaName,
+	})
+}
+
+// statement records the execution of |query| on |c|, which started at |start|.
+func (l *auditLog) statement(c *mysql.Conn, query string, start time.Time, rows *auditRows, err error) {
+	ac := l.conn(c)
+	user := ac.user
+	if user == "" {
+		user = c.User
+	}
+	if !l.userIsAudited(user) {
+		return
+	}
+	ev := auditEvent{
+		Time:           start,
+		Event:          auditEventStatement,
+		ConnectionID:   c.ConnectionID,
+		User:           user,
+		Host:           ac.host,
+		StatementClass: servercfg.AuditLogClassOther,
+		Query:          strings.TrimSpace(query),
+	}
+	if stmt, perr := sqlparser.Parse(query); perr == nil {
+		ev.StatementType, ev.StatementClass = classifyStatement(stmt)
+		ev.Tables = statementTables(stmt)
+	}
+	if !l.classIsAudited(ev.StatementClass) {
+		return
+	}
+	if ev.StatementClass == servercfg.AuditLogClassDCL {
+		// Account management statements can contain passwords.
+		ev.Query = ""
+	}
+	duration := float64(time.Since(start)) / float64(time.Millisecond)
+	ev.DurationMs = &duration
+	ev.RowsAffected = &rows.affected
+	ev.RowsReturned = &rows.returned
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	if l.sessionInfo != nil {
+		ev.Database, ev.Branch = l.sessionInfo(c.ConnectionID)
+	}
+	l.emit(ev)
+}
+
+// connHost returns the host the client of |c| connected from.
+func connHost(c *mysql.Conn) string {
+	if c.Conn == nil {
+		return ""
+	}
+	addr := c.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// auditRows counts the rows affected and returned by a statement as its
+// results are sent to the client.
+type auditRows struct {
+	affected uint64
+	returned uint64
+}
+
+func (r *auditRows) add(res *sqltypes.Result) {
+	if res == nil {
+		return
+	}
+	r.affected += res.RowsAffected
+	r.returned += uint64(len(res.Rows))
+}
+
+// classifyStatement returns the type of |stmt|, such as "insert" or
+// "create database", and its class, which is one of
+// servercfg.AuditLogStatementClasses.
+func classifyStatement(stmt sqlparser.Statement) (string, string) {
+	switch s := stmt.(type) {
+	case *sqlparser.Select, *sqlparser.SetOp, *sqlparser.ParenSelect:
+		return "select", servercfg.AuditLogClassSelect
+	case *sqlparser.Insert:
+		return strings.ToLower(s.Action), servercfg.AuditLogClassDML
+	case *sqlparser.Update:
+		return "update", servercfg.AuditLogClassDML
+	case *sqlparser.Delete:
+		return "delete", servercfg.AuditLogClassDML
+	case *sqlparser.Load:
+		return "load", servercfg.AuditLogClassDML
+	case *sqlparser.DDL:
+		return strings.ToLower(s.Action), servercfg.AuditLogClassDDL
+	case *sqlparser.AlterTable:
+		return "alter", servercfg.AuditLogClassDDL
+	case *sqlparser.DBDDL:
+		return strings.ToLower(s.Action + " " + s.SchemaOrDatabase), servercfg.AuditLogClassDDL
+	case *sqlparser.CreateUser:
+		return "create user", servercfg.AuditLogClassDCL
+	case *sqlparser.RenameUser:
+		return "rename user", servercfg.AuditLogClassDCL
+	case *sqlparser.DropUser:
+		return "drop user", servercfg.AuditLogClassDCL
+	case *sqlparser.CreateRole:
+		return "create role", servercfg.AuditLogClassDCL
+	case *sqlparser.DropRole:
+		return "drop role", servercfg.AuditLogClassDCL
+	case *sqlparser.GrantPrivilege, *sqlparser.GrantRole, *sqlparser.GrantProxy:
+		return "grant", servercfg.AuditLogClassDCL
+	case *sqlparser.RevokePrivilege, *sqlparser.RevokeAllPrivileges, *sqlparser.RevokeRole, *sqlparser.RevokeProxy:
+		return "revoke", servercfg.AuditLogClassDCL
+	case *sqlparser.Begin:
+		return "begin", servercfg.AuditLogClassTransaction
+	case *sqlparser.Commit:
+		return "commit", servercfg.AuditLogClassTransaction
+	case *sqlparser.Rollback:
+		return "rollback", servercfg.AuditLogClassTransaction
+	case *sqlparser.Savepoint:
+		return "savepoint", servercfg.AuditLogClassTransaction
+	case *sqlparser.RollbackSavepoint:
+		return "rollback to savepoint", servercfg.AuditLogClassTransaction
+	case *sqlparser.ReleaseSavepoint:
+		return "release savepoint", servercfg.AuditLogClassTransaction
+	case *sqlparser.LockTables:
+		return "lock tables", servercfg.AuditLogClassTransaction
+	case *sqlparser.UnlockTables:
+		return "unlock tables", servercfg.AuditLogClassTransaction
+	case *sqlparser.Show:
+		return "show", servercfg.AuditLogClassOther
+	case *sqlparser.Explain:
+		return "explain", servercfg.AuditLogClassOther
+	case *sqlparser.Set:
+		return "set", servercfg.AuditLogClassOther
+	case *sqlparser.Use:
+		return "use", servercfg.AuditLogClassOther
+	case *sqlparser.Call:
+		return "call", servercfg.AuditLogClassOther
+	default:
+		return "", servercfg.AuditLogClassOther
+	}
+}
+
+// statementTables returns the names of the tables |stmt| references, in the
+// order they first appear.
+func statementTables(stmt sqlparser.Statement) []string {
+	var tables []string
+	seen := make(map[string]struct{})
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.ColName, *sqlparser.StarExpr:
+			// The qualifiers of columns are usually table aliases.
+			return false, nil
+		case sqlparser.TableName:
+			if n.Name.IsEmpty() {
+				return false, nil
+			}
+			name := n.Name.String()
+			if !n.DbQualifier.IsEmpty() {
+				name = n.DbQualifier.String() + "." + name
+			}
+			if _, ok := seen[strings.ToLower(name)]; !ok {
+				seen[strings.ToLower(name)] = struct{}{}
+				tables = append(tables, name)
+			}
+			return false, nil
+		}
+		return true, nil
+	}, stmt)
+	return tables
+}
+
+// auditHandler is a mysql.Handler which records the events of the handler it
+// wraps in an auditLog.
+type auditHandler struct {
+	mysql.Handler
+	log *auditLog
+}
+
+var _ mysql.Handler = (*auditHandler)(nil)
+var _ mysql.BinlogReplicaHandler = (*auditHandler)(nil)
+
+func (h *auditHandler) NewConnection(c *mysql.Conn) {
+	h.log.newConnection(c)
+	h.Handler.NewConnection(c)
+}
+
+func (h *auditHandler) ConnectionClosed(c *mysql.Conn) {
+	h.Handler.ConnectionClosed(c)
+	h.log.connectionClosed(c)
+}
+
+func (h *auditHandler) ConnectionAborted(c *mysql.Conn, reason string) error {
+	h.log.connectionAborted(c, reason)
+	return h.Handler.ConnectionAborted(c, reason)
+}
+
+func (h *auditHandler) ComInitDB(c *mysql.Conn, schemaName string) error {
+	h.log.initDB(c, schemaName)
+	return h.Handler.ComInitDB(c, schemaName)
+}
+
+func (h *auditHandler) ComQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) error {
+	start := time.Now()
+	rows := &auditRows{}
+	err := h.Handler.ComQuery(ctx, c, query, func(res *sqltypes.Result, more bool) error {
+		rows.add(res)
+		return callback(res, more)
+	})
+	h.log.statement(c, query, start, rows, err)
+	return err
+}
+
+func (h *auditHandler) ComMultiQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) (string, error) {
+	start := time.Now()
+	rows := &auditRows{}
+	remainder, err := h.Handler.ComMultiQuery(ctx, c, query, func(res *sqltypes.Result, more bool) error {
+		rows.add(res)
+		return callback(res, more)
+	})
+	h.log.statement(c, executedQuery(query, remainder), start, rows, err)
+	return remainder, err
+}
+
+func (h *auditHandler) ComStmtExecute(ctx context.Context, c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	start := time.Now()
+	rows := &auditRows{}
+	err := h.Handler.ComStmtExecute(ctx, c, prepare, func(res *sqltypes.Result) error {
+		rows.add(res)
+		return callback(res)
+	})
+	h.log.statement(c, prepare.PrepareStmt, start, rows, err)
+	return err
+}
+
+// ComRegisterReplica implements mysql.BinlogReplicaHandler, so that binlog
+// replicas can still connect to a server with an audit log.
+func (h *auditHandler) ComRegisterReplica(c *mysql.Conn, replicaHost string, replicaPort uint16, replicaUser string, replicaPassword string) error {
+	if bh, ok := h.Handler.(mysql.BinlogReplicaHandler); ok {
+		return bh.ComRegisterReplica(c, replicaHost, replicaPort, replicaUser, replicaPassword)
+	}
+	return errors.New("binlog replication is not supported by this server")
+}
+
+// ComBinlogDumpGTID implements mysql.BinlogReplicaHandler.
+func (h *auditHandler) ComBinlogDumpGTID(c *mysql.Conn, logFile string, logPos uint64, gtidSet mysql.GTIDSet) error {
+	if bh, ok := h.Handler.(mysql.BinlogReplicaHandler); ok {
+		return bh.ComBinlogDumpGTID(c, logFile, logPos, gtidSet)
+	}
+	return errors.New("binlog replication is not supported by this server")
+}
+
+// executedQuery returns the statement of a ComMultiQuery of |query| which was
+// executed, given the |remainder| which was not. Only the first statement of
+// |query| is executed.
+func executedQuery(query, remainder string) string {
+	if len(remainder) <= len(query) && strings.HasSuffix(query, remainder) {
+		return strings.TrimSuffix(strings.TrimSpace(query[:len(query)-len(remainder)]), ";")
+	}
+	return query
+}
+
+// marshalAuditEvent returns |ev| as a line of JSON.
+func marshalAuditEvent(ev auditEvent) ([]byte, error) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// auditFileSink writes the audit log to a file as JSON lines. Once the file
+// reaches |maxSize| bytes, it is rotated: it is renamed to |path|.1, any
+// previously rotated files are renamed from |path|.N to |path|.N+1, and the
+// oldest ones are removed so that at most |maxFiles| rotated files remain.
+type auditFileSink struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+var _ auditSink = (*auditFileSink)(nil)
+
+// newAuditFileSink opens the audit log file at |path|, appending to it if it
+// already exists. |maxSize| of 0 disables rotation, and |maxFiles| of 0 keeps
+// all rotated files.
+func newAuditFileSink(path string, maxSize int64, maxFiles int) (*auditFileSink, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	s := &auditFileSink{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *auditFileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f = f
+	s.size = st.Size()
+	return nil
+}
+
+func (s *auditFileSink) write(events []auditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return errors.New("audit log file is closed")
+	}
+	for _, ev := range events {
+		line, err := marshalAuditEvent(ev)
+		if err != nil {
+			return err
+		}
+		if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := s.f.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *auditFileSink) rotatedPath(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
+
+func (s *auditFileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return err
+	}
+	s.f = nil
+
+	last := s.maxFiles
+	if last == 0 {
+		// Keep all of the rotated files, so find the oldest one.
+		for last = 1; ; last++ {
+			if _, err := os.Stat(s.rotatedPath(last)); errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+		}
+	}
+	if err := os.Remove(s.rotatedPath(last)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for i := last - 1; i >= 1; i-- {
+		if err := os.Rename(s.rotatedPath(i), s.rotatedPath(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.rotatedPath(1)); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *auditFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
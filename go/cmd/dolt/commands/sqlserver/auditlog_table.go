@@ -0,0 +1,239 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
+)
+
+const (
+	// The number of audit log events which can be waiting to be written to
+	// the audit log table. Events are dropped when it is full.
+	auditTableQueueSize = 4096
+	// The maximum number of audit log events written in a single INSERT.
+	auditTableBatchSize = 256
+	// How often pending audit log events are written to the table.
+	auditTableFlushInterval = time.Second
+)
+
+// auditTableSink inserts audit log events into a Dolt table. The events are
+// queued and written in batches by a background goroutine, so that clients
+// do not wait on the writes. The table is created if it does not exist.
+type auditTableSink struct {
+	lgr      *logrus.Entry
+	se       *engine.SqlEngine
+	database string
+	table    string
+
+	events  chan auditEvent
+	done    chan struct{}
+	created bool
+
+	closeOnce sync.Once
+	mu        sync.Mutex
+	closed    bool
+	dropped   int
+}
+
+var _ auditSink = (*auditTableSink)(nil)
+
+func newAuditTableSink(lgr *logrus.Entry, se *engine.SqlEngine, database, table string) *auditTableSink {
+	s := &auditTableSink{
+		lgr:      lgr,
+		se:       se,
+		database: database,
+		table:    table,
+		events:   make(chan auditEvent, auditTableQueueSize),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *auditTableSink) write(events []auditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	for _, ev := range events {
+		select {
+		case s.events <- ev:
+		default:
+			s.dropped += 1
+		}
+	}
+	return nil
+}
+
+func (s *auditTableSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(auditTableFlushInterval)
+	defer ticker.Stop()
+	var batch []auditEvent
+	for {
+		select {
+		case ev, ok := <-s.events:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, ev)
+			if len(batch) >= auditTableBatchSize {
+				s.flush(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			s.flush(batch)
+			batch = nil
+		}
+	}
+}
+
+func (s *auditTableSink) flush(batch []auditEvent) {
+	s.mu.Lock()
+	dropped := s.dropped
+	s.dropped = 0
+	s.mu.Unlock()
+	if dropped > 0 {
+		s.lgr.Warnf("audit log table %s.%s could not keep up; dropped %d events", s.database, s.table, dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	if err := s.insert(batch); err != nil {
+		s.lgr.Warnf("error writing %d events to audit log table %s.%s: %v", len(batch), s.database, s.table, err)
+	}
+}
+
+func (s *auditTableSink) qualifiedTableName() string {
+	return sqlfmt.QuoteIdentifier(s.database) + "." + sqlfmt.QuoteIdentifier(s.table)
+}
+
+func (s *auditTableSink) insert(batch []auditEvent) error {
+	ctx, err := s.se.NewLocalContext(context.Background())
+	if err != nil {
+		return err
+	}
+	if !s.created {
+		err = s.exec(ctx, "CREATE TABLE IF NOT EXISTS "+s.qualifiedTableName()+` (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  event_time DATETIME(6) NOT NULL,
+  event VARCHAR(32) NOT NULL,
+  connection_id INT UNSIGNED NOT NULL,
+  user VARCHAR(255),
+  host VARCHAR(255),
+  database_name VARCHAR(255),
+  branch VARCHAR(255),
+  statement_type VARCHAR(64),
+  statement_class VARCHAR(16),
+  query LONGTEXT,
+  tables_touched TEXT,
+  rows_affected BIGINT UNSIGNED,
+  rows_returned BIGINT UNSIGNED,
+  duration_ms DOUBLE,
+  error TEXT
+)`)
+		if err != nil {
+			return err
+		}
+		s.created = true
+	}
+
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(s.qualifiedTableName())
+	b.WriteString(" (event_time, event, connection_id, user, host, database_name, branch, statement_type, statement_class, query, tables_touched, rows_affected, rows_returned, duration_ms, error) VALUES ")
+	for i, ev := range batch {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		b.WriteString(sqlString(ev.Time.UTC().Format("2006-01-02 15:04:05.999999")))
+		b.WriteString(", ")
+		b.WriteString(sqlString(ev.Event))
+		b.WriteString(", ")
+		b.WriteString(strconv.FormatUint(uint64(ev.ConnectionID), 10))
+		for _, str := range []string{ev.User, ev.Host, ev.Database, ev.Branch, ev.StatementType, ev.StatementClass, ev.Query, strings.Join(ev.Tables, ",")} {
+			b.WriteString(", ")
+			b.WriteString(sqlNullableString(str))
+		}
+		for _, n := range []*uint64{ev.RowsAffected, ev.RowsReturned} {
+			b.WriteString(", ")
+			if n == nil {
+				b.WriteString("NULL")
+			} else {
+				b.WriteString(strconv.FormatUint(*n, 10))
+			}
+		}
+		b.WriteString(", ")
+		if ev.DurationMs == nil {
+			b.WriteString("NULL")
+		} else {
+			b.WriteString(strconv.FormatFloat(*ev.DurationMs, 'f', -1, 64))
+		}
+		b.WriteString(", ")
+		b.WriteString(sqlNullableString(ev.Error))
+		b.WriteString(")")
+	}
+	return s.exec(ctx, b.String())
+}
+
+func (s *auditTableSink) exec(ctx *sql.Context, query string) error {
+	_, iter, err := s.se.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	_, err = sql.RowIterToRows(ctx, iter)
+	return err
+}
+
+// Close writes any pending events to the table and stops the sink.
+func (s *auditTableSink) Close() error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.events)
+		s.mu.Unlock()
+		<-s.done
+	})
+	return nil
+}
+
+// sqlString returns |s| as a quoted and escaped SQL string literal.
+func sqlString(s string) string {
+	var buf bytes.Buffer
+	sqltypes.MakeTrusted(sqltypes.VarChar, []byte(s)).EncodeSQL(&buf)
+	return buf.String()
+}
+
+func sqlNullableString(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return sqlString(s)
+}
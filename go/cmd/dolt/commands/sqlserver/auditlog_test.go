@@ -0,0 +1,275 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+)
+
+func TestClassifyStatement(t *testing.T) {
+	tests := []struct {
+		query  string
+		typ    string
+		class  string
+		tables []string
+	}{
+		{"select * from t1 join db2.t2 on t1.a = t2.a where t1.b > 1", "select", servercfg.AuditLogClassSelect, []string{"t1", "db2.t2"}},
+		{"select x.* from t1 as x union select * from t3", "select", servercfg.AuditLogClassSelect, []string{"t1", "t3"}},
+		{"insert into t1 select * from t2", "insert", servercfg.AuditLogClassDML, []string{"t1", "t2"}},
+		{"replace into t1 values (1)", "replace", servercfg.AuditLogClassDML, []string{"t1"}},
+		{"update t1 set a = 1 where b = 2", "update", servercfg.AuditLogClassDML, []string{"t1"}},
+		{"delete from T1 where a in (select a from t1)", "delete", servercfg.AuditLogClassDML, []string{"T1"}},
+		{"create table t1 (a int primary key)", "create", servercfg.AuditLogClassDDL, []string{"t1"}},
+		{"drop table t1, t2", "drop", servercfg.AuditLogClassDDL, []string{"t1", "t2"}},
+		{"create database db2", "create database", servercfg.AuditLogClassDDL, nil},
+		{"create user 'u'@'%' identified by 'secret'", "create user", servercfg.AuditLogClassDCL, nil},
+		{"grant select on db.* to 'u'@'%'", "grant", servercfg.AuditLogClassDCL, nil},
+		{"start transaction", "begin", servercfg.AuditLogClassTransaction, nil},
+		{"commit", "commit", servercfg.AuditLogClassTransaction, nil},
+		{"call dolt_commit('-am', 'msg')", "call", servercfg.AuditLogClassOther, nil},
+		{"show tables", "show", servercfg.AuditLogClassOther, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(test.query)
+			require.NoError(t, err)
+			typ, class := classifyStatement(stmt)
+			assert.Equal(t, test.typ, typ)
+			assert.Equal(t, test.class, class)
+			assert.Equal(t, test.tables, statementTables(stmt))
+		})
+	}
+}
+
+func TestAuditFileSink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "audit.log")
+	ev := auditEvent{Event: auditEventConnect, Host: strings.Repeat("h", 100)}
+	line, err := marshalAuditEvent(ev)
+	require.NoError(t, err)
+
+	// Room for two events per file, keeping two rotated files.
+	sink, err := newAuditFileSink(path, int64(2*len(line)), 2)
+	require.NoError(t, err)
+	for i := 0; i < 7; i++ {
+		require.NoError(t, sink.write([]auditEvent{ev}))
+	}
+	require.NoError(t, sink.Close())
+
+	lines := func(path string) int {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return strings.Count(string(data), "\n")
+	}
+	assert.Equal(t, 1, lines(path))
+	assert.Equal(t, 2, lines(path+".1"))
+	assert.Equal(t, 2, lines(path+".2"))
+	_, err = os.Stat(path + ".3")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	// Reopening appends to the existing file.
+	sink, err = newAuditFileSink(path, 0, 2)
+	require.NoError(t, err)
+	require.NoError(t, sink.write([]auditEvent{ev, ev}))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, 3, lines(path))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var read auditEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &read))
+		assert.Equal(t, auditEventConnect, read.Event)
+	}
+}
+
+type memoryAuditSink struct {
+	events []auditEvent
+}
+
+func (s *memoryAuditSink) write(events []auditEvent) error {
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *memoryAuditSink) Close() error {
+	return nil
+}
+
+// fakeHandler is a mysql.Handler which returns a single result for every
+// query.
+type fakeHandler struct {
+	mysql.Handler
+	result *sqltypes.Result
+	err    error
+}
+
+func (h fakeHandler) NewConnection(*mysql.Conn)    {}
+func (h fakeHandler) ConnectionClosed(*mysql.Conn) {}
+
+func (h fakeHandler) ConnectionAborted(*mysql.Conn, string) error {
+	return nil
+}
+
+func (h fakeHandler) ComInitDB(*mysql.Conn, string) error {
+	return nil
+}
+
+func (h fakeHandler) ComQuery(_ context.Context, _ *mysql.Conn, _ string, callback mysql.ResultSpoolFn) error {
+	if h.err != nil {
+		return h.err
+	}
+	return callback(h.result, false)
+}
+
+func (h fakeHandler) ComMultiQuery(_ context.Context, _ *mysql.Conn, query string, callback mysql.ResultSpoolFn) (string, error) {
+	_, remainder, _ := strings.Cut(query, ";")
+	return remainder, callback(h.result, false)
+}
+
+func newTestConn(t *testing.T, id uint32) *mysql.Conn {
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return &mysql.Conn{Conn: server, ConnectionID: id}
+}
+
+func TestAuditHandler(t *testing.T) {
+	ctx := context.Background()
+	lgr := logrus.NewEntry(logrus.StandardLogger())
+	sessionInfo := func(uint32) (string, string) {
+		return "mydb", "main"
+	}
+	spool := func(*sqltypes.Result, bool) error {
+		return nil
+	}
+
+	t.Run("connection lifecycle", func(t *testing.T) {
+		sink := &memoryAuditSink{}
+		log := newAuditLog(lgr, &servercfg.AuditLogYAMLConfig{}, []auditSink{sink}, sessionInfo)
+		h, err := log.WrapHandler(fakeHandler{result: &sqltypes.Result{RowsAffected: 3}})
+		require.NoError(t, err)
+
+		failed := newTestConn(t, 1)
+		h.NewConnection(failed)
+		require.NoError(t, h.ConnectionAborted(failed, "Error authenticating user using MySQL native password: Access denied for user 'mallory'"))
+		h.ConnectionClosed(failed)
+
+		c := newTestConn(t, 2)
+		h.NewConnection(c)
+		c.User = "alice"
+		require.NoError(t, h.ComInitDB(c, "mydb"))
+		require.NoError(t, h.ComQuery(ctx, c, "insert into t values (1), (2), (3)", spool))
+		remainder, err := h.ComMultiQuery(ctx, c, "delete from t; select 1", spool)
+		require.NoError(t, err)
+		assert.Equal(t, " select 1", remainder)
+		h.ConnectionClosed(c)
+
+		events := sink.events
+		require.Len(t, events, 8)
+		assert.Equal(t, auditEventConnect, events[0].Event)
+		assert.Equal(t, auditEventAuthFailure, events[1].Event)
+		assert.Equal(t, "mallory", events[1].User)
+		assert.Equal(t, auditEventDisconnect, events[2].Event)
+
+		assert.Equal(t, auditEventConnect, events[3].Event)
+		assert.Equal(t, auditEventAuthSuccess, events[4].Event)
+		assert.Equal(t, "alice", events[4].User)
+		assert.Equal(t, "mydb", events[4].Database)
+
+		stmt := events[5]
+		assert.Equal(t, auditEventStatement, stmt.Event)
+		assert.Equal(t, "alice", stmt.User)
+		assert.Equal(t, "pipe", stmt.Host)
+		assert.Equal(t, "mydb", stmt.Database)
+		assert.Equal(t, "main", stmt.Branch)
+		assert.Equal(t, "insert", stmt.StatementType)
+		assert.Equal(t, servercfg.AuditLogClassDML, stmt.StatementClass)
+		assert.Equal(t, []string{"t"}, stmt.Tables)
+		require.NotNil(t, stmt.RowsAffected)
+		assert.Equal(t, uint64(3), *stmt.RowsAffected)
+		require.NotNil(t, stmt.DurationMs)
+
+		assert.Equal(t, "delete from t", events[6].Query)
+		assert.Equal(t, "delete", events[6].StatementType)
+		assert.Equal(t, auditEventDisconnect, events[7].Event)
+		assert.Equal(t, "alice", events[7].User)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		sink := &memoryAuditSink{}
+		cfg := &servercfg.AuditLogYAMLConfig{
+			Users_:            []string{"alice", "bob"},
+			ExcludeUsers_:     []string{"bob"},
+			StatementClasses_: []string{"DDL", "dcl"},
+		}
+		log := newAuditLog(lgr, cfg, []auditSink{sink}, sessionInfo)
+		h, err := log.WrapHandler(fakeHandler{result: &sqltypes.Result{}})
+		require.NoError(t, err)
+
+		for i, user := range []string{"alice", "bob", "carol"} {
+			c := newTestConn(t, uint32(i+1))
+			h.NewConnection(c)
+			c.User = user
+			require.NoError(t, h.ComInitDB(c, ""))
+			require.NoError(t, h.ComQuery(ctx, c, "select * from t", spool))
+			require.NoError(t, h.ComQuery(ctx, c, "create table t2 (a int primary key)", spool))
+			require.NoError(t, h.ComQuery(ctx, c, "create user 'u' identified by 'secret'", spool))
+		}
+
+		// Connect events have no user, so they are filtered out.
+		require.Len(t, sink.events, 3)
+		assert.Equal(t, auditEventAuthSuccess, sink.events[0].Event)
+		assert.Equal(t, "create", sink.events[1].StatementType)
+		assert.Equal(t, "create user", sink.events[2].StatementType)
+		assert.Empty(t, sink.events[2].Query)
+		for _, ev := range sink.events {
+			assert.Equal(t, "alice", ev.User)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		sink := &memoryAuditSink{}
+		log := newAuditLog(lgr, &servercfg.AuditLogYAMLConfig{}, []auditSink{sink}, sessionInfo)
+		h, err := log.WrapHandler(fakeHandler{err: errors.New("table not found: t")})
+		require.NoError(t, err)
+		c := newTestConn(t, 1)
+		c.User = "alice"
+		require.Error(t, h.ComQuery(ctx, c, "select * from t", spool))
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, "table not found: t", sink.events[0].Error)
+	})
+}
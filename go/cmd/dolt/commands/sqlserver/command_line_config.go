@@ -307,6 +307,22 @@ func (cfg *commandLineServerConfig) ClusterConfig() servercfg.ClusterConfig {
 	return nil
 }
 
+func (cfg *commandLineServerConfig) AuditLogConfig() servercfg.AuditLogConfig {
+	return nil
+}
+
+func (cfg *commandLineServerConfig) ResourceLimits() []servercfg.ResourceLimitsConfig {
+	return nil
+}
+
+func (cfg *commandLineServerConfig) TTLPurgeConfig() servercfg.TTLPurgeConfig {
+	return nil
+}
+
+func (cfg *commandLineServerConfig) ResultCacheConfig() servercfg.ResultCacheConfig {
+	return nil
+}
+
 // PrivilegeFilePath returns the path to the file which contains all needed privilege information in the form of a
 // JSON string.
 func (cfg *commandLineServerConfig) PrivilegeFilePath() string {
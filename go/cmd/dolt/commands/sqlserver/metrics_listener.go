@@ -16,23 +16,28 @@ package sqlserver
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/dolthub/go-mysql-server/server"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/cluster"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/clusterdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 	"github.com/dolthub/dolt/go/libraries/utils/version"
 )
 
 const (
 	clusterUpdateInterval = time.Second * 5
 
-	dbLabel     = "database"
-	roleLabel   = "role"
-	remoteLabel = "remote"
+	dbLabel      = "database"
+	roleLabel    = "role"
+	remoteLabel  = "remote"
+	replicaLabel = "replica"
 )
 
 var _ server.ServerEventListener = (*metricsListener)(nil)
@@ -51,11 +56,22 @@ type metricsListener struct {
 	isReplicaGauges      *prometheus.GaugeVec
 	replicationLagGauges *prometheus.GaugeVec
 
+	// binlog replication metrics
+	gaugeBinlogReplicas          prometheus.Gauge
+	binlogReplicaLagGauges       *prometheus.GaugeVec
+	binlogReplicaBytesSentGauges *prometheus.GaugeVec
+
 	// used in updating cluster metrics
 	clusterStatus  clusterdb.ClusterStatusProvider
 	mu             *sync.Mutex
 	done           bool
 	clusterSeenDbs map[string]struct{}
+
+	// used in updating binlog replication metrics
+	binlogSeenReplicas map[string]struct{}
+
+	// query result cache metrics, if the cache is enabled
+	resultCacheCollectors []prometheus.Collector
 }
 
 func newMetricsListener(labels prometheus.Labels, versionStr string, clusterStatus clusterdb.ClusterStatusProvider) (*metricsListener, error) {
@@ -102,9 +118,25 @@ func newMetricsListener(labels prometheus.Labels, versionStr string, clusterStat
 			Help:        "one if the server is currently in this role, zero otherwise",
 			ConstLabels: labels,
 		}, []string{dbLabel}),
-		clusterStatus:  clusterStatus,
-		mu:             &sync.Mutex{},
-		clusterSeenDbs: make(map[string]struct{}),
+		gaugeBinlogReplicas: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "dss_binlog_replicas",
+			Help:        "Number of replicas currently streaming binlog events from this server",
+			ConstLabels: labels,
+		}),
+		binlogReplicaLagGauges: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "dss_binlog_replica_lag",
+			Help:        "The estimated lag, in milliseconds, of the binlog events sent to the given replica.",
+			ConstLabels: labels,
+		}, []string{replicaLabel}),
+		binlogReplicaBytesSentGauges: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "dss_binlog_replica_bytes_sent",
+			Help:        "The number of bytes of binlog events sent to the given replica over its current connection.",
+			ConstLabels: labels,
+		}, []string{replicaLabel}),
+		clusterStatus:      clusterStatus,
+		mu:                 &sync.Mutex{},
+		clusterSeenDbs:     make(map[string]struct{}),
+		binlogSeenReplicas: make(map[string]struct{}),
 	}
 
 	u32Version, err := version.Encode(versionStr)
@@ -126,6 +158,9 @@ func newMetricsListener(labels prometheus.Labels, versionStr string, clusterStat
 	prometheus.MustRegister(ml.histQueryDur)
 	prometheus.MustRegister(ml.replicationLagGauges)
 	prometheus.MustRegister(ml.isReplicaGauges)
+	prometheus.MustRegister(ml.gaugeBinlogReplicas)
+	prometheus.MustRegister(ml.binlogReplicaLagGauges)
+	prometheus.MustRegister(ml.binlogReplicaBytesSentGauges)
 
 	go func() {
 		for ml.updateReplMetrics() {
@@ -145,6 +180,8 @@ func (ml *metricsListener) updateReplMetrics() bool {
 		return false
 	}
 
+	ml.updateBinlogReplicaMetrics()
+
 	perDbStatus := ml.clusterStatus.GetClusterStatus()
 	if perDbStatus == nil {
 		return true
@@ -181,6 +218,30 @@ func (ml *metricsListener) updateReplMetrics() bool {
 	return true
 }
 
+// updateBinlogReplicaMetrics updates the metrics for the replicas streaming binlog events from this server. The
+// caller must hold |ml.mu|.
+func (ml *metricsListener) updateBinlogReplicaMetrics() {
+	statuses := dtables.BinlogReplicaStatuses()
+	ml.gaugeBinlogReplicas.Set(float64(len(statuses)))
+
+	replicas := make(map[string]struct{})
+	for _, status := range statuses {
+		replica := net.JoinHostPort(status.Host, strconv.Itoa(int(status.Port)))
+		replicas[replica] = struct{}{}
+		ml.binlogReplicaLagGauges.WithLabelValues(replica).Set(float64(status.Lag.Milliseconds()))
+		ml.binlogReplicaBytesSentGauges.WithLabelValues(replica).Set(float64(status.BytesSent))
+	}
+
+	// deregister metrics for disconnected replicas
+	for replica := range ml.binlogSeenReplicas {
+		if _, ok := replicas[replica]; !ok {
+			ml.binlogReplicaLagGauges.DeleteLabelValues(replica)
+			ml.binlogReplicaBytesSentGauges.DeleteLabelValues(replica)
+		}
+	}
+	ml.binlogSeenReplicas = replicas
+}
+
 func (ml *metricsListener) ClientConnected() {
 	ml.gaugeConcurrentConn.Add(1.0)
 	ml.cntConnections.Add(1.0)
@@ -200,6 +261,35 @@ func (ml *metricsListener) QueryCompleted(success bool, duration time.Duration)
 	ml.histQueryDur.Observe(duration.Seconds())
 }
 
+// registerResultCache registers the metrics of the query result cache |cache|.
+func (ml *metricsListener) registerResultCache(cache *engine.ResultCache) {
+	counter := func(name, help string, val func(engine.ResultCacheStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help, ConstLabels: ml.labels}, func() float64 {
+			return float64(val(cache.Stats()))
+		})
+	}
+	gauge := func(name, help string, val func(engine.ResultCacheStats) uint64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help, ConstLabels: ml.labels}, func() float64 {
+			return float64(val(cache.Stats()))
+		})
+	}
+	ml.resultCacheCollectors = []prometheus.Collector{
+		counter("dss_result_cache_hits", "Count of queries whose results were returned from the query result cache",
+			func(s engine.ResultCacheStats) uint64 { return s.Hits }),
+		counter("dss_result_cache_misses", "Count of cacheable queries whose results were not in the query result cache",
+			func(s engine.ResultCacheStats) uint64 { return s.Misses }),
+		counter("dss_result_cache_evictions", "Count of results evicted from the query result cache",
+			func(s engine.ResultCacheStats) uint64 { return s.Evictions }),
+		gauge("dss_result_cache_entries", "Number of results in the query result cache",
+			func(s engine.ResultCacheStats) uint64 { return s.Entries }),
+		gauge("dss_result_cache_bytes", "Approximate memory of the results in the query result cache",
+			func(s engine.ResultCacheStats) uint64 { return s.Bytes }),
+	}
+	for _, c := range ml.resultCacheCollectors {
+		prometheus.MustRegister(c)
+	}
+}
+
 func (ml *metricsListener) Close() {
 	prometheus.Unregister(ml.gaugeVersion)
 	prometheus.Unregister(ml.cntConnections)
@@ -207,6 +297,9 @@ func (ml *metricsListener) Close() {
 	prometheus.Unregister(ml.gaugeConcurrentConn)
 	prometheus.Unregister(ml.gaugeConcurrentQueries)
 	prometheus.Unregister(ml.histQueryDur)
+	for _, c := range ml.resultCacheCollectors {
+		prometheus.Unregister(c)
+	}
 
 	ml.closeReplicationMetrics()
 }
@@ -217,6 +310,9 @@ func (ml *metricsListener) closeReplicationMetrics() {
 
 	prometheus.Unregister(ml.replicationLagGauges)
 	prometheus.Unregister(ml.isReplicaGauges)
+	prometheus.Unregister(ml.gaugeBinlogReplicas)
+	prometheus.Unregister(ml.binlogReplicaLagGauges)
+	prometheus.Unregister(ml.binlogReplicaBytesSentGauges)
 
 	ml.done = true
 }
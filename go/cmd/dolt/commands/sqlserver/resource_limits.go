@@ -0,0 +1,383 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+)
+
+// roleLookupFunc returns the roles which have been granted to |user|.
+type roleLookupFunc func(ctx context.Context, user string) ([]string, error)
+
+// roleLimitsTTL is how long the limits a user gets from their roles are
+// cached. The limits of a session are resolved again before each of its
+// statements, so changes to the roles granted to a user apply to their open
+// sessions once the cached limits expire.
+const roleLimitsTTL = time.Second
+
+// resourceLimits enforces the resource_limits of the server config on the
+// sessions of sql-server. The limits of a session are those of its user or,
+// if its user has none, the strictest limits of the roles granted to its
+// user, and are resolved when it is created and before each of its
+// statements. The limits on rows written are enforced by the session
+// itself, and the others are enforced by wrapping the mysql.Handler of the
+// server. The limits of the sessions are shown in the dolt_resource_limits
+// system table, and with their running statements in SHOW PROCESSLIST.
+type resourceLimits struct {
+	lgr        *logrus.Entry
+	users      map[string]dsess.ResourceLimits
+	roles      map[string]dsess.ResourceLimits
+	roleLookup roleLookupFunc
+	governor   *dsess.ResourceGovernor
+
+	// The limits users get from their roles, by user, and how long they
+	// are cached for.
+	mu         sync.Mutex
+	roleLimits map[string]cachedLimits
+	ttl        time.Duration
+
+	// The sessions of the connections to the server, by connection id.
+	sessions sync.Map
+}
+
+type cachedLimits struct {
+	limits  dsess.ResourceLimits
+	expires time.Time
+}
+
+var _ dtables.ResourceLimitsProvider = (*resourceLimits)(nil)
+
+// newResourceLimits returns the resourceLimits for |cfg|, or nil if there
+// are no limits.
+func newResourceLimits(lgr *logrus.Entry, cfg []servercfg.ResourceLimitsConfig, roleLookup roleLookupFunc) *resourceLimits {
+	if len(cfg) == 0 {
+		return nil
+	}
+	r := &resourceLimits{
+		lgr:        lgr,
+		users:      make(map[string]dsess.ResourceLimits),
+		roles:      make(map[string]dsess.ResourceLimits),
+		roleLookup: roleLookup,
+		governor:   dsess.NewResourceGovernor(),
+		roleLimits: make(map[string]cachedLimits),
+		ttl:        roleLimitsTTL,
+	}
+	for _, c := range cfg {
+		limits := dsess.ResourceLimits{
+			MaxConcurrentQueries:         c.MaxConcurrentQueries(),
+			MaxExecutionTime:             time.Duration(c.MaxExecutionTimeMillis()) * time.Millisecond,
+			MaxRowsReturned:              c.MaxRowsReturned(),
+			MaxRowsWrittenPerTransaction: c.MaxRowsWrittenPerTransaction(),
+			KillOnExceed:                 c.OnExceed() == servercfg.ResourceLimitsOnExceedKill,
+		}
+		if c.User() != "" {
+			r.users[c.User()] = limits
+		} else {
+			r.roles[c.Role()] = limits
+		}
+	}
+	return r
+}
+
+// limitsFor returns the resource limits of |user|.
+func (r *resourceLimits) limitsFor(ctx context.Context, user string) (dsess.ResourceLimits, error) {
+	if limits, ok := r.users[user]; ok {
+		return limits, nil
+	}
+	if len(r.roles) == 0 || r.roleLookup == nil {
+		return dsess.ResourceLimits{}, nil
+	}
+	now := time.Now()
+	r.mu.Lock()
+	cached, ok := r.roleLimits[user]
+	r.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.limits, nil
+	}
+
+	roles, err := r.roleLookup(ctx, user)
+	if err != nil {
+		return dsess.ResourceLimits{}, err
+	}
+	var limits dsess.ResourceLimits
+	for _, role := range roles {
+		if roleLimits, ok := r.roles[role]; ok {
+			limits = limits.Strictest(roleLimits)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, c := range r.roleLimits {
+		if now.After(c.expires) {
+			delete(r.roleLimits, name)
+		}
+	}
+	r.roleLimits[user] = cachedLimits{limits: limits, expires: now.Add(r.ttl)}
+	return limits, nil
+}
+
+// newSession sets the resource limits of |sess|, the new session of |conn|.
+func (r *resourceLimits) newSession(ctx context.Context, conn *mysql.Conn, sess *dsess.DoltSession) error {
+	limits, err := r.limitsFor(ctx, conn.User)
+	if err != nil {
+		return err
+	}
+	sess.SetResourceLimits(limits)
+	r.sessions.Store(conn.ConnectionID, sess)
+	return nil
+}
+
+// WrapHandler returns a mysql.Handler which enforces the resource limits of
+// the sessions of |h|.
+func (r *resourceLimits) WrapHandler(h mysql.Handler) (mysql.Handler, error) {
+	return &resourceLimitsHandler{Handler: h, limits: r}, nil
+}
+
+// WrapProcessList returns a sql.ProcessList which shows the resource limits
+// of the sessions of |pl| in SHOW PROCESSLIST. The limits of a session are
+// added to the Info column of its running statement, as a comment before
+// the statement.
+func (r *resourceLimits) WrapProcessList(pl sql.ProcessList) sql.ProcessList {
+	return &resourceLimitsProcessList{ProcessList: pl, limits: r}
+}
+
+type resourceLimitsProcessList struct {
+	sql.ProcessList
+	limits *resourceLimits
+}
+
+// Processes implements sql.ProcessList.
+func (pl *resourceLimitsProcessList) Processes() []sql.Process {
+	processes := pl.ProcessList.Processes()
+	for i := range processes {
+		if processes[i].Command != sql.ProcessCommandQuery {
+			continue
+		}
+		v, ok := pl.limits.sessions.Load(processes[i].Connection)
+		if !ok {
+			continue
+		}
+		if l := v.(*dsess.DoltSession).ResourceLimits(); !l.IsZero() {
+			processes[i].Query = "/* " + l.String() + " */ " + processes[i].Query
+		}
+	}
+	return processes
+}
+
+// session resolves the resource limits of the session of |c| again, and
+// returns the session if it has any, or nil. If the limits can't be
+// resolved, the session keeps its current limits.
+func (r *resourceLimits) session(ctx context.Context, c *mysql.Conn) *dsess.DoltSession {
+	v, ok := r.sessions.Load(c.ConnectionID)
+	if !ok {
+		return nil
+	}
+	sess := v.(*dsess.DoltSession)
+	if limits, err := r.limitsFor(ctx, c.User); err != nil {
+		r.lgr.Warnf("error resolving the resource limits of user '%s': %v", c.User, err)
+	} else {
+		sess.SetResourceLimits(limits)
+	}
+	if sess.ResourceLimits().IsZero() {
+		return nil
+	}
+	return sess
+}
+
+// SessionResourceLimits implements dtables.ResourceLimitsProvider.
+func (r *resourceLimits) SessionResourceLimits() []dtables.SessionResourceLimits {
+	var limits []dtables.SessionResourceLimits
+	r.sessions.Range(func(_, v interface{}) bool {
+		sess := v.(*dsess.DoltSession)
+		if l := sess.ResourceLimits(); !l.IsZero() {
+			limits = append(limits, dtables.SessionResourceLimits{
+				ConnectionId: sess.ID(),
+				User:         sess.Client().User,
+				Limits:       l,
+			})
+		}
+		return true
+	})
+	sort.Slice(limits, func(i, j int) bool {
+		return limits[i].ConnectionId < limits[j].ConnectionId
+	})
+	return limits
+}
+
+// limitedStatement tracks a statement of a session with resource limits
+// while it runs.
+type limitedStatement struct {
+	sess     *dsess.DoltSession
+	limits   dsess.ResourceLimits
+	user     string
+	returned uint64
+}
+
+// begin returns the context a statement on |c| should run with, and the
+// function which must be called when the statement is done. It returns an
+// error if the statement cannot run because the user of |c| is running as
+// many statements as they are allowed to.
+func (r *resourceLimits) begin(ctx context.Context, c *mysql.Conn) (context.Context, *limitedStatement, func(error) error, error) {
+	sess := r.session(ctx, c)
+	if sess == nil {
+		return ctx, nil, func(err error) error {
+			return err
+		}, nil
+	}
+	s := &limitedStatement{sess: sess, limits: sess.ResourceLimits(), user: c.User}
+	endQuery, err := r.governor.BeginQuery(sess)
+	if err != nil {
+		sqlErr := mysql.NewSQLError(mysql.ERUserLimitReached, mysql.SSUnknownSQLState, "User '%s' has exceeded the 'max_concurrent_queries' resource (current value: %d)", c.User, s.limits.MaxConcurrentQueries)
+		return ctx, nil, nil, r.exceeded(c, s, sqlErr)
+	}
+	cancel := func() {}
+	if s.limits.MaxExecutionTime > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.limits.MaxExecutionTime)
+	}
+	return ctx, s, func(err error) error {
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+		endQuery()
+		if err != nil && timedOut {
+			sess.ResourceLimitExceeded()
+			err = mysql.NewSQLError(mysql.ERQueryTimeout, mysql.SSUnknownSQLState, "Query execution was interrupted, maximum statement execution time exceeded")
+		}
+		return r.exceeded(c, s, err)
+	}, nil
+}
+
+// exceeded kills |c| if the statement |s| exceeded one of its resource
+// limits and the limits of its session say to do so. It returns the error of
+// the statement, |err|.
+func (r *resourceLimits) exceeded(c *mysql.Conn, s *limitedStatement, err error) error {
+	if !s.sess.TakeResourceLimitExceeded() {
+		return err
+	}
+	if s.limits.KillOnExceed {
+		r.lgr.Warnf("killing connection %d of user '%s', which exceeded its resource limits: %v", c.ConnectionID, c.User, err)
+		c.Close()
+	}
+	return err
+}
+
+// addRows counts the rows of |res| towards the MaxRowsReturned limit of
+// the statement.
+func (s *limitedStatement) addRows(res *sqltypes.Result) error {
+	if res == nil || s.limits.MaxRowsReturned == 0 {
+		return nil
+	}
+	s.returned += uint64(len(res.Rows))
+	if s.returned > s.limits.MaxRowsReturned {
+		s.sess.ResourceLimitExceeded()
+		return mysql.NewSQLError(mysql.ERUserLimitReached, mysql.SSUnknownSQLState, "User '%s' has exceeded the 'max_rows_returned' resource (current value: %d)", s.user, s.limits.MaxRowsReturned)
+	}
+	return nil
+}
+
+// resourceLimitsHandler is a mysql.Handler which enforces the resource
+// limits of the sessions of the handler it wraps.
+type resourceLimitsHandler struct {
+	mysql.Handler
+	limits *resourceLimits
+}
+
+var _ mysql.Handler = (*resourceLimitsHandler)(nil)
+var _ mysql.BinlogReplicaHandler = (*resourceLimitsHandler)(nil)
+
+func (h *resourceLimitsHandler) ConnectionClosed(c *mysql.Conn) {
+	h.Handler.ConnectionClosed(c)
+	h.limits.sessions.Delete(c.ConnectionID)
+}
+
+func (h *resourceLimitsHandler) ComQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) error {
+	ctx, s, end, err := h.limits.begin(ctx, c)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return h.Handler.ComQuery(ctx, c, query, callback)
+	}
+	err = h.Handler.ComQuery(ctx, c, query, func(res *sqltypes.Result, more bool) error {
+		if err := s.addRows(res); err != nil {
+			return err
+		}
+		return callback(res, more)
+	})
+	return end(err)
+}
+
+func (h *resourceLimitsHandler) ComMultiQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) (string, error) {
+	ctx, s, end, err := h.limits.begin(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	if s == nil {
+		return h.Handler.ComMultiQuery(ctx, c, query, callback)
+	}
+	remainder, err := h.Handler.ComMultiQuery(ctx, c, query, func(res *sqltypes.Result, more bool) error {
+		if err := s.addRows(res); err != nil {
+			return err
+		}
+		return callback(res, more)
+	})
+	return remainder, end(err)
+}
+
+func (h *resourceLimitsHandler) ComStmtExecute(ctx context.Context, c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	ctx, s, end, err := h.limits.begin(ctx, c)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return h.Handler.ComStmtExecute(ctx, c, prepare, callback)
+	}
+	err = h.Handler.ComStmtExecute(ctx, c, prepare, func(res *sqltypes.Result) error {
+		if err := s.addRows(res); err != nil {
+			return err
+		}
+		return callback(res)
+	})
+	return end(err)
+}
+
+// ComRegisterReplica implements mysql.BinlogReplicaHandler, so that binlog
+// replicas can still connect to a server with resource limits.
+func (h *resourceLimitsHandler) ComRegisterReplica(c *mysql.Conn, replicaHost string, replicaPort uint16, replicaUser string, replicaPassword string) error {
+	if bh, ok := h.Handler.(mysql.BinlogReplicaHandler); ok {
+		return bh.ComRegisterReplica(c, replicaHost, replicaPort, replicaUser, replicaPassword)
+	}
+	return errors.New("binlog replication is not supported by this server")
+}
+
+// ComBinlogDumpGTID implements mysql.BinlogReplicaHandler.
+func (h *resourceLimitsHandler) ComBinlogDumpGTID(c *mysql.Conn, logFile string, logPos uint64, gtidSet mysql.GTIDSet) error {
+	if bh, ok := h.Handler.(mysql.BinlogReplicaHandler); ok {
+		return bh.ComBinlogDumpGTID(c, logFile, logPos, gtidSet)
+	}
+	return errors.New("binlog replication is not supported by this server")
+}
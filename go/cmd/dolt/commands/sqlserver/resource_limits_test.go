@@ -0,0 +1,235 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+)
+
+func newLimitedSession(user string, id uint32) *dsess.DoltSession {
+	return &dsess.DoltSession{Session: sql.NewBaseSessionWithClientServer("", sql.Client{User: user}, id)}
+}
+
+// fakeProcessList is a sql.ProcessList which returns copies of |processes|.
+type fakeProcessList struct {
+	sql.ProcessList
+	processes []sql.Process
+}
+
+func (pl fakeProcessList) Processes() []sql.Process {
+	return append([]sql.Process(nil), pl.processes...)
+}
+
+// blockingHandler is a fakeHandler which runs each query until |release| is
+// closed or the query is canceled.
+type blockingHandler struct {
+	fakeHandler
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h blockingHandler) ComQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) error {
+	h.started <- struct{}{}
+	select {
+	case <-h.release:
+		return h.fakeHandler.ComQuery(ctx, c, query, callback)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	ctx := context.Background()
+	lgr := logrus.NewEntry(logrus.StandardLogger())
+	spool := func(*sqltypes.Result, bool) error {
+		return nil
+	}
+	ptr := func(v uint64) *uint64 {
+		return &v
+	}
+	str := func(s string) *string {
+		return &s
+	}
+	two := 2
+	cfg := []servercfg.ResourceLimitsConfig{
+		servercfg.ResourceLimitsYAMLConfig{User_: str("alice"), MaxConcurrentQueries_: &two, MaxRowsReturned_: ptr(2)},
+		servercfg.ResourceLimitsYAMLConfig{User_: str("bob"), MaxExecutionTimeMillis_: ptr(20), OnExceed_: str("kill")},
+		servercfg.ResourceLimitsYAMLConfig{Role_: str("analysts"), MaxRowsReturned_: ptr(100), MaxRowsWrittenPerTransaction_: ptr(10)},
+		servercfg.ResourceLimitsYAMLConfig{Role_: str("readers"), MaxRowsReturned_: ptr(50), OnExceed_: str("kill")},
+	}
+	roles := func(_ context.Context, user string) ([]string, error) {
+		if user == "carol" {
+			return []string{"analysts", "readers", "writers"}, nil
+		}
+		return nil, nil
+	}
+	limits := newResourceLimits(lgr, cfg, roles)
+	require.NotNil(t, limits)
+	require.Nil(t, newResourceLimits(lgr, nil, roles))
+
+	t.Run("limits for users and roles", func(t *testing.T) {
+		l, err := limits.limitsFor(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, dsess.ResourceLimits{MaxConcurrentQueries: 2, MaxRowsReturned: 2}, l)
+
+		l, err = limits.limitsFor(ctx, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, dsess.ResourceLimits{MaxExecutionTime: 20 * time.Millisecond, KillOnExceed: true}, l)
+
+		l, err = limits.limitsFor(ctx, "carol")
+		require.NoError(t, err)
+		assert.Equal(t, dsess.ResourceLimits{MaxRowsReturned: 50, MaxRowsWrittenPerTransaction: 10, KillOnExceed: true}, l)
+
+		l, err = limits.limitsFor(ctx, "dave")
+		require.NoError(t, err)
+		assert.True(t, l.IsZero())
+	})
+
+	t.Run("max rows returned", func(t *testing.T) {
+		result := &sqltypes.Result{Rows: [][]sqltypes.Value{{}, {}}}
+		h, err := limits.WrapHandler(fakeHandler{result: result})
+		require.NoError(t, err)
+		c := newTestConn(t, 1)
+		c.User = "alice"
+		require.NoError(t, limits.newSession(ctx, c, newLimitedSession("alice", 1)))
+		require.NoError(t, h.ComQuery(ctx, c, "select * from t", spool))
+
+		h, err = limits.WrapHandler(fakeHandler{result: &sqltypes.Result{Rows: [][]sqltypes.Value{{}, {}, {}}}})
+		require.NoError(t, err)
+		err = h.ComQuery(ctx, c, "select * from t", spool)
+		require.Error(t, err)
+		assert.Equal(t, mysql.ERUserLimitReached, err.(*mysql.SQLError).Number())
+		assert.False(t, c.IsClosed())
+
+		// Connections without limits are not affected.
+		other := newTestConn(t, 2)
+		other.User = "dave"
+		require.NoError(t, limits.newSession(ctx, other, newLimitedSession("dave", 2)))
+		require.NoError(t, h.ComQuery(ctx, other, "select * from t", spool))
+		h.ConnectionClosed(c)
+		h.ConnectionClosed(other)
+	})
+
+	t.Run("process list shows limits", func(t *testing.T) {
+		c := newTestConn(t, 7)
+		c.User = "alice"
+		sess := newLimitedSession("alice", 7)
+		require.NoError(t, limits.newSession(ctx, c, sess))
+		pl := limits.WrapProcessList(fakeProcessList{processes: []sql.Process{
+			{Connection: 7, Command: sql.ProcessCommandQuery, Query: "select * from t"},
+			{Connection: 7, Command: sql.ProcessCommandSleep},
+			{Connection: 8, Command: sql.ProcessCommandQuery, Query: "select 1"},
+		}})
+		processes := pl.Processes()
+		assert.Equal(t, "/* resource limits: max_concurrent_queries=2, max_rows_returned=2, on_exceed=error */ select * from t", processes[0].Query)
+		assert.Equal(t, "", processes[1].Query)
+		assert.Equal(t, "select 1", processes[2].Query)
+		limits.sessions.Delete(uint32(7))
+	})
+
+	t.Run("role changes apply to open sessions", func(t *testing.T) {
+		var granted []string
+		limits := newResourceLimits(lgr, cfg, func(_ context.Context, user string) ([]string, error) {
+			return granted, nil
+		})
+		limits.ttl = 0
+		h, err := limits.WrapHandler(fakeHandler{result: &sqltypes.Result{Rows: [][]sqltypes.Value{{}, {}, {}}}})
+		require.NoError(t, err)
+		c := newTestConn(t, 6)
+		c.User = "erin"
+		sess := newLimitedSession("erin", 6)
+		require.NoError(t, limits.newSession(ctx, c, sess))
+		require.NoError(t, h.ComQuery(ctx, c, "select * from t", spool))
+		assert.Empty(t, limits.SessionResourceLimits())
+
+		granted = []string{"readers"}
+		require.NoError(t, h.ComQuery(ctx, c, "select * from t", spool))
+		assert.Equal(t, []dtables.SessionResourceLimits{{
+			ConnectionId: 6,
+			User:         "erin",
+			Limits:       dsess.ResourceLimits{MaxRowsReturned: 50, KillOnExceed: true},
+		}}, limits.SessionResourceLimits())
+
+		granted = nil
+		require.NoError(t, h.ComQuery(ctx, c, "select * from t", spool))
+		assert.True(t, sess.ResourceLimits().IsZero())
+		h.ConnectionClosed(c)
+	})
+
+	t.Run("max concurrent queries", func(t *testing.T) {
+		bh := blockingHandler{
+			fakeHandler: fakeHandler{result: &sqltypes.Result{}},
+			started:     make(chan struct{}, 3),
+			release:     make(chan struct{}),
+		}
+		h, err := limits.WrapHandler(bh)
+		require.NoError(t, err)
+		errs := make(chan error, 2)
+		for i := uint32(1); i <= 3; i++ {
+			c := newTestConn(t, i)
+			c.User = "alice"
+			require.NoError(t, limits.newSession(ctx, c, newLimitedSession("alice", i)))
+			if i < 3 {
+				go func() {
+					errs <- h.ComQuery(ctx, c, "select sleep(10)", spool)
+				}()
+				<-bh.started
+				continue
+			}
+			err = h.ComQuery(ctx, c, "select 1", spool)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "max_concurrent_queries")
+		}
+		close(bh.release)
+		require.NoError(t, <-errs)
+		require.NoError(t, <-errs)
+
+		// Once the queries are done, the user can run more.
+		c := newTestConn(t, 4)
+		c.User = "alice"
+		require.NoError(t, limits.newSession(ctx, c, newLimitedSession("alice", 4)))
+		require.NoError(t, h.ComQuery(ctx, c, "select 1", spool))
+	})
+
+	t.Run("max execution time kills connection", func(t *testing.T) {
+		bh := blockingHandler{
+			fakeHandler: fakeHandler{result: &sqltypes.Result{}},
+			started:     make(chan struct{}, 1),
+			release:     make(chan struct{}),
+		}
+		h, err := limits.WrapHandler(bh)
+		require.NoError(t, err)
+		c := newTestConn(t, 5)
+		c.User = "bob"
+		require.NoError(t, limits.newSession(ctx, c, newLimitedSession("bob", 5)))
+		err = h.ComQuery(ctx, c, "select sleep(10)", spool)
+		require.Error(t, err)
+		assert.Equal(t, mysql.ERQueryTimeout, err.(*mysql.SQLError).Number())
+		assert.True(t, c.IsClosed())
+	})
+}
@@ -53,6 +53,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/cluster"
 	_ "github.com/dolthub/dolt/go/libraries/doltcore/sqle/dfunctions"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqlserver"
 	"github.com/dolthub/dolt/go/libraries/events"
 	"github.com/dolthub/dolt/go/libraries/utils/config"
@@ -249,6 +250,7 @@ func ConfigureServices(
 				SystemVariables:         serverConfig.SystemVars(),
 				ClusterController:       clusterController,
 				BinlogReplicaController: binlogreplication.DoltBinlogReplicaController,
+				ResultCache:             serverConfig.ResultCacheConfig(),
 			}
 			return nil
 		},
@@ -331,7 +333,13 @@ func ConfigureServices(
 		InitF: func(context.Context) (err error) {
 			labels := serverConfig.MetricsLabels()
 			metListener, err = newMetricsListener(labels, version, clusterController)
-			return err
+			if err != nil {
+				return err
+			}
+			if resultCache := sqlEngine.ResultCache(); resultCache != nil {
+				metListener.registerResultCache(resultCache)
+			}
+			return nil
 		},
 		StopF: func() error {
 			metListener.Close()
@@ -540,24 +548,70 @@ func ConfigureServices(
 
 	var sqlServerClosed bool
 	var mySQLServer *server.Server
+	var sqlAuditLog *auditLog
+	var sqlSlowQueryLog *slowQueryLog
+	var sqlTTLPurger *ttlPurger
+	sqlResourceLimits := newResourceLimits(logrus.NewEntry(lgr), serverConfig.ResourceLimits(), func(ctx context.Context, user string) ([]string, error) {
+		return sqlEngine.GrantedRoles(ctx, user)
+	})
 	InitSQLServer := &svcs.AnonService{
 		InitF: func(context.Context) (err error) {
+			var wrappers []func(mysql.Handler) (mysql.Handler, error)
 			v, ok := serverConfig.(servercfg.ValidatingServerConfig)
 			if ok && v.GoldenMysqlConnectionString() != "" {
+				wrappers = append(wrappers, func(h mysql.Handler) (mysql.Handler, error) {
+					return golden.NewValidatingHandler(h, v.GoldenMysqlConnectionString(), logrus.StandardLogger())
+				})
+			}
+			if sqlResourceLimits != nil {
+				dtables.RegisterResourceLimitsProvider(sqlResourceLimits)
+				engine := sqlEngine.GetUnderlyingEngine()
+				engine.ProcessList = sqlResourceLimits.WrapProcessList(engine.ProcessList)
+				wrappers = append(wrappers, sqlResourceLimits.WrapHandler)
+			}
+			if auditLogConfig := serverConfig.AuditLogConfig(); auditLogConfig != nil {
+				sqlAuditLog, err = newServerAuditLog(logrus.NewEntry(lgr), auditLogConfig, sqlEngine, func(connID uint32) (string, string) {
+					return sessionDatabaseAndBranch(mySQLServer, connID)
+				})
+				if err != nil {
+					lgr.Errorf("error opening audit log: %v", err)
+					return err
+				}
+				wrappers = append(wrappers, sqlAuditLog.WrapHandler)
+			}
+			sqlSlowQueryLog = newSlowQueryLog(logrus.NewEntry(lgr), &serverSlowQueryEnv{
+				server: func() *server.Server {
+					return mySQLServer
+				},
+			})
+			dtables.RegisterSlowQueryProvider(sqlSlowQueryLog)
+			wrappers = append(wrappers, sqlSlowQueryLog.WrapHandler)
+			if ttlPurgeConfig := serverConfig.TTLPurgeConfig(); ttlPurgeConfig != nil {
+				sqlTTLPurger = newTTLPurger(logrus.NewEntry(lgr), ttlPurgeConfig, &serverTTLPurgeEnv{se: sqlEngine})
+				dtables.RegisterTTLStatusProvider(sqlTTLPurger)
+				sqlTTLPurger.Start()
+			}
+			if len(wrappers) > 0 {
 				mySQLServer, err = server.NewServerWithHandler(
 					serverConf,
 					sqlEngine.GetUnderlyingEngine(),
-					newSessionBuilder(sqlEngine, serverConfig),
+					newSessionBuilder(sqlEngine, serverConfig, sqlResourceLimits),
 					metListener,
 					func(h mysql.Handler) (mysql.Handler, error) {
-						return golden.NewValidatingHandler(h, v.GoldenMysqlConnectionString(), logrus.StandardLogger())
+						for _, wrap := range wrappers {
+							var err error
+							if h, err = wrap(h); err != nil {
+								return nil, err
+							}
+						}
+						return h, nil
 					},
 				)
 			} else {
 				mySQLServer, err = server.NewServer(
 					serverConf,
 					sqlEngine.GetUnderlyingEngine(),
-					newSessionBuilder(sqlEngine, serverConfig),
+					newSessionBuilder(sqlEngine, serverConfig, sqlResourceLimits),
 					metListener,
 				)
 			}
@@ -570,9 +624,23 @@ func ConfigureServices(
 		StopF: func() (err error) {
 			if !sqlServerClosed {
 				sqlServerClosed = true
-				return mySQLServer.Close()
+				err = mySQLServer.Close()
 			}
-			return nil
+			if sqlAuditLog != nil {
+				err = errors.Join(err, sqlAuditLog.Close())
+			}
+			if sqlSlowQueryLog != nil {
+				dtables.RegisterSlowQueryProvider(nil)
+				err = errors.Join(err, sqlSlowQueryLog.Close())
+			}
+			if sqlResourceLimits != nil {
+				dtables.RegisterResourceLimitsProvider(nil)
+			}
+			if sqlTTLPurger != nil {
+				dtables.RegisterTTLStatusProvider(nil)
+				err = errors.Join(err, sqlTTLPurger.Close())
+			}
+			return err
 		},
 	}
 	controller.Register(InitSQLServer)
@@ -619,6 +687,161 @@ func ConfigureServices(
 	controller.Register(RunSQLServer)
 }
 
+// newServerAuditLog creates the audit log of the server from |cfg|.
+// |sessionInfo| looks up the current database and branch of a connection.
+func newServerAuditLog(lgr *logrus.Entry, cfg servercfg.AuditLogConfig, se *engine.SqlEngine, sessionInfo sessionInfoFunc) (*auditLog, error) {
+	var sinks []auditSink
+	if cfg.Path() != "" {
+		fileSink, err := newAuditFileSink(cfg.Path(), int64(cfg.MaxSizeMB())*1024*1024, cfg.MaxFiles())
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+	if tableCfg := cfg.TableConfig(); tableCfg != nil {
+		sinks = append(sinks, newAuditTableSink(lgr, se, tableCfg.Database(), tableCfg.Table()))
+	}
+	return newAuditLog(lgr, cfg, sinks, sessionInfo), nil
+}
+
+// sessionDatabaseAndBranch returns the current database of the session of
+// the connection |connID| to |s| and the branch it has checked out.
+func sessionDatabaseAndBranch(s *server.Server, connID uint32) (string, string) {
+	sess := findSession(s, connID)
+	if sess == nil {
+		return "", ""
+	}
+	database, _ := dsess.SplitRevisionDbName(sess.GetCurrentDatabase())
+	var branch string
+	if dSess, ok := sess.(*dsess.DoltSession); ok {
+		branch, _ = dSess.GetBranch()
+	}
+	return database, branch
+}
+
+// findSession returns the session of the connection |connID| to |s|, or nil
+// if there is none.
+func findSession(s *server.Server, connID uint32) sql.Session {
+	if s == nil {
+		return nil
+	}
+	var sess sql.Session
+	_ = s.SessionManager().Iter(func(session sql.Session) (bool, error) {
+		if session.ID() == connID {
+			sess = session
+			return true, nil
+		}
+		return false, nil
+	})
+	return sess
+}
+
+// serverSlowQueryEnv is the slowQueryEnv of a running sql-server.
+type serverSlowQueryEnv struct {
+	server func() *server.Server
+}
+
+var _ slowQueryEnv = (*serverSlowQueryEnv)(nil)
+
+func (e *serverSlowQueryEnv) settings(connID uint32) slowQuerySettings {
+	_, enabled, _ := sql.SystemVariables.GetGlobal("slow_query_log")
+	if !sysVarIsOn(enabled) {
+		return slowQuerySettings{}
+	}
+	ret := slowQuerySettings{enabled: true}
+	if _, path, ok := sql.SystemVariables.GetGlobal("slow_query_log_file"); ok {
+		ret.path, _ = path.(string)
+	}
+	if _, size, ok := sql.SystemVariables.GetGlobal(dsess.DoltSlowQueryTableSize); ok {
+		ret.tableSize = int(sysVarFloat(size))
+	}
+	longQueryTime := float64(10)
+	if _, global, ok := sql.SystemVariables.GetGlobal("long_query_time"); ok {
+		longQueryTime = sysVarFloat(global)
+	}
+	if sess := findSession(e.server(), connID); sess != nil {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sess))
+		if val, err := sess.GetSessionVariable(ctx, "long_query_time"); err == nil {
+			longQueryTime = sysVarFloat(val)
+		}
+	}
+	ret.longQueryTime = time.Duration(longQueryTime * float64(time.Second))
+	return ret
+}
+
+func (e *serverSlowQueryEnv) trackRowsExamined(connID uint32) func() uint64 {
+	sess, ok := findSession(e.server(), connID).(*dsess.DoltSession)
+	if !ok {
+		return func() uint64 {
+			return 0
+		}
+	}
+	sess.TrackRowsExamined(true)
+	return func() uint64 {
+		n := sess.RowsExamined()
+		sess.TrackRowsExamined(false)
+		return n
+	}
+}
+
+func (e *serverSlowQueryEnv) sessionInfo(connID uint32) (string, string) {
+	return sessionDatabaseAndBranch(e.server(), connID)
+}
+
+func (e *serverSlowQueryEnv) trackQueryPlan(connID uint32) func() fmt.Stringer {
+	sess, ok := findSession(e.server(), connID).(*dsess.DoltSession)
+	if !ok {
+		return func() fmt.Stringer {
+			return nil
+		}
+	}
+	sess.TrackQueryPlan(true)
+	return func() fmt.Stringer {
+		plan := sess.QueryPlan()
+		sess.TrackQueryPlan(false)
+		return plan
+	}
+}
+
+// sysVarIsOn returns whether the value of a boolean system variable is ON.
+func sysVarIsOn(val interface{}) bool {
+	switch v := val.(type) {
+	case bool:
+		return v
+	case int8:
+		return v != 0
+	case int64:
+		return v != 0
+	case string:
+		return strings.EqualFold(v, "on") || v == "1"
+	default:
+		return false
+	}
+}
+
+// sysVarFloat returns the value of a numeric system variable as a float64.
+func sysVarFloat(val interface{}) float64 {
+	switch v := val.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	default:
+		return 0
+	}
+}
+
 // heartbeatService is a service that sends a heartbeat event to the metrics server once a day
 type heartbeatService struct {
 	version      string
@@ -810,7 +1033,7 @@ func portInUse(hostPort string) bool {
 	return false
 }
 
-func newSessionBuilder(se *engine.SqlEngine, config servercfg.ServerConfig) server.SessionBuilder {
+func newSessionBuilder(se *engine.SqlEngine, config servercfg.ServerConfig, limits *resourceLimits) server.SessionBuilder {
 	userToSessionVars := make(map[string]map[string]string)
 	userVars := config.UserVars()
 	for _, curr := range userVars {
@@ -843,6 +1066,12 @@ func newSessionBuilder(se *engine.SqlEngine, config servercfg.ServerConfig) serv
 			}
 		}
 
+		if limits != nil {
+			if err = limits.newSession(ctx, conn, dsess); err != nil {
+				return nil, err
+			}
+		}
+
 		return dsess, nil
 	}
 }
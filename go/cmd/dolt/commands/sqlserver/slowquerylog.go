@@ -0,0 +1,334 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+)
+
+// slowQuerySettings are the system variables which control the slow query
+// log for a statement.
+type slowQuerySettings struct {
+	// enabled is the global value of slow_query_log.
+	enabled bool
+	// path is the global value of slow_query_log_file.
+	path string
+	// longQueryTime is the session value of long_query_time.
+	longQueryTime time.Duration
+	// tableSize is the global value of dolt_slow_query_table_size, the
+	// number of slow queries kept for the dolt_slow_queries table.
+	tableSize int
+}
+
+// slowQueryEnv is how the slow query log sees the sessions of the server.
+type slowQueryEnv interface {
+	// settings returns the slow query log settings for the next statement
+	// of the session of |connID|. Only |enabled| is set if the slow query
+	// log is disabled.
+	settings(connID uint32) slowQuerySettings
+	// trackRowsExamined starts counting the rows examined by the session of
+	// |connID|. The returned function stops counting and returns the count.
+	trackRowsExamined(connID uint32) func() uint64
+	// sessionInfo returns the current database and active branch of the
+	// session of |connID|.
+	sessionInfo(connID uint32) (database, branch string)
+	// trackQueryPlan starts recording the plan of the next statement run
+	// by the session of |connID|. The returned function stops recording and
+	// returns the plan the engine ran the statement with, or nil if it
+	// didn't build one.
+	trackQueryPlan(connID uint32) func() fmt.Stringer
+}
+
+// slowQueryLog records the statements run by the clients of sql-server which
+// take longer than long_query_time while slow_query_log is enabled. They are
+// written to slow_query_log_file in the format of the MySQL slow query log,
+// and the most recent ones are kept for the dolt_slow_queries system table.
+type slowQueryLog struct {
+	lgr *logrus.Entry
+	env slowQueryEnv
+
+	mu      sync.Mutex
+	f       *os.File
+	path    string
+	queries []dtables.SlowQuery
+	next    int
+}
+
+var _ dtables.SlowQueryProvider = (*slowQueryLog)(nil)
+
+func newSlowQueryLog(lgr *logrus.Entry, env slowQueryEnv) *slowQueryLog {
+	return &slowQueryLog{lgr: lgr, env: env}
+}
+
+// WrapHandler returns a mysql.Handler which records the slow statements run
+// through |h|.
+func (l *slowQueryLog) WrapHandler(h mysql.Handler) (mysql.Handler, error) {
+	return &slowQueryHandler{Handler: h, log: l}, nil
+}
+
+// SlowQueries implements dtables.SlowQueryProvider.
+func (l *slowQueryLog) SlowQueries() []dtables.SlowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ret := make([]dtables.SlowQuery, 0, len(l.queries))
+	ret = append(ret, l.queries[l.next:]...)
+	return append(ret, l.queries[:l.next]...)
+}
+
+// Close closes the slow query log file.
+func (l *slowQueryLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// slowQueryStatement tracks a statement while it runs.
+type slowQueryStatement struct {
+	settings     slowQuerySettings
+	start        time.Time
+	rows         auditRows
+	rowsExamined func() uint64
+	queryPlan    func() fmt.Stringer
+}
+
+// begin returns a slowQueryStatement for a statement which is about to run on
+// |c|, or nil if the slow query log is disabled.
+func (l *slowQueryLog) begin(c *mysql.Conn) *slowQueryStatement {
+	settings := l.env.settings(c.ConnectionID)
+	if !settings.enabled {
+		return nil
+	}
+	return &slowQueryStatement{
+		settings:     settings,
+		start:        time.Now(),
+		rowsExamined: l.env.trackRowsExamined(c.ConnectionID),
+		queryPlan:    l.env.trackQueryPlan(c.ConnectionID),
+	}
+}
+
+// end records the statement |query| tracked by |s| if it was slow.
+func (l *slowQueryLog) end(c *mysql.Conn, s *slowQueryStatement, query string, err error) {
+	if s == nil {
+		return
+	}
+	queryTime := time.Since(s.start)
+	rowsExamined := s.rowsExamined()
+	plan := s.queryPlan()
+	if queryTime <= s.settings.longQueryTime {
+		return
+	}
+
+	sq := dtables.SlowQuery{
+		Time:         s.start,
+		ConnectionId: c.ConnectionID,
+		User:         c.User,
+		Host:         connHost(c),
+		Query:        strings.TrimSpace(query),
+		QueryTime:    queryTime,
+		RowsExamined: rowsExamined,
+		RowsSent:     s.rows.returned,
+		RowsAffected: s.rows.affected,
+	}
+	sq.Database, sq.Branch = l.env.sessionInfo(c.ConnectionID)
+	if err != nil {
+		sq.Error = err.Error()
+	}
+	if stmt, perr := sqlparser.Parse(query); perr == nil {
+		_, class := classifyStatement(stmt)
+		if class == servercfg.AuditLogClassDCL {
+			// Account management statements can contain passwords.
+			sq.Query = ""
+		} else if (class == servercfg.AuditLogClassSelect || class == servercfg.AuditLogClassDML) && plan != nil {
+			sq.Plan = plan.String()
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.add(sq, s.settings.tableSize)
+	if werr := l.write(sq, s.settings.path); werr != nil {
+		l.lgr.Warnf("error writing to slow query log %s: %v", s.settings.path, werr)
+	}
+}
+
+// add keeps |sq| for the dolt_slow_queries table, which shows the last
+// |size| slow queries.
+func (l *slowQueryLog) add(sq dtables.SlowQuery, size int) {
+	if size != len(l.queries) {
+		// The table size changed, so keep the most recent queries that fit.
+		queries := make([]dtables.SlowQuery, 0, size)
+		queries = append(queries, l.queries[l.next:]...)
+		queries = append(queries, l.queries[:l.next]...)
+		if len(queries) > size {
+			queries = queries[len(queries)-size:]
+		}
+		l.queries = queries
+		l.next = 0
+	}
+	if size == 0 {
+		return
+	}
+	if len(l.queries) < size {
+		l.queries = append(l.queries, sq)
+		return
+	}
+	l.queries[l.next] = sq
+	l.next = (l.next + 1) % size
+}
+
+// write appends |sq| to the slow query log file at |path|, opening it if
+// slow_query_log_file has changed.
+func (l *slowQueryLog) write(sq dtables.SlowQuery, path string) error {
+	if path == "" {
+		return nil
+	}
+	if l.f == nil || l.path != path {
+		if l.f != nil {
+			l.f.Close()
+			l.f = nil
+		}
+		if dir := filepath.Dir(path); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		l.f = f
+		l.path = path
+	}
+	_, err := l.f.WriteString(formatSlowQuery(sq))
+	return err
+}
+
+// formatSlowQuery returns |sq| as an entry of the MySQL slow query log. The
+// branch, plan and error of the statement are added as comments.
+func formatSlowQuery(sq dtables.SlowQuery) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Time: %s\n", sq.Time.UTC().Format("2006-01-02T15:04:05.000000Z"))
+	fmt.Fprintf(&b, "# User@Host: %s[%s] @ %s [%s]  Id: %d\n", sq.User, sq.User, sq.Host, sq.Host, sq.ConnectionId)
+	fmt.Fprintf(&b, "# Query_time: %.6f  Lock_time: 0.000000 Rows_sent: %d  Rows_examined: %d  Rows_affected: %d\n",
+		sq.QueryTime.Seconds(), sq.RowsSent, sq.RowsExamined, sq.RowsAffected)
+	if sq.Branch != "" {
+		fmt.Fprintf(&b, "# Branch: %s\n", sq.Branch)
+	}
+	if sq.Error != "" {
+		fmt.Fprintf(&b, "# Error: %s\n", strings.ReplaceAll(sq.Error, "\n", " "))
+	}
+	if sq.Plan != "" {
+		b.WriteString("# Plan:\n")
+		for _, line := range strings.Split(strings.TrimRight(sq.Plan, "\n"), "\n") {
+			fmt.Fprintf(&b, "#   %s\n", line)
+		}
+	}
+	if sq.Database != "" {
+		fmt.Fprintf(&b, "use %s;\n", sq.Database)
+	}
+	fmt.Fprintf(&b, "SET timestamp=%d;\n", sq.Time.Unix())
+	query := strings.TrimSuffix(sq.Query, ";")
+	if query == "" {
+		query = "# statement hidden"
+	}
+	fmt.Fprintf(&b, "%s;\n", query)
+	return b.String()
+}
+
+// slowQueryHandler is a mysql.Handler which records the slow statements of
+// the handler it wraps in a slowQueryLog.
+type slowQueryHandler struct {
+	mysql.Handler
+	log *slowQueryLog
+}
+
+var _ mysql.Handler = (*slowQueryHandler)(nil)
+var _ mysql.BinlogReplicaHandler = (*slowQueryHandler)(nil)
+
+func (h *slowQueryHandler) ComQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) error {
+	s := h.log.begin(c)
+	if s == nil {
+		return h.Handler.ComQuery(ctx, c, query, callback)
+	}
+	err := h.Handler.ComQuery(ctx, c, query, func(res *sqltypes.Result, more bool) error {
+		s.rows.add(res)
+		return callback(res, more)
+	})
+	h.log.end(c, s, query, err)
+	return err
+}
+
+func (h *slowQueryHandler) ComMultiQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) (string, error) {
+	s := h.log.begin(c)
+	if s == nil {
+		return h.Handler.ComMultiQuery(ctx, c, query, callback)
+	}
+	remainder, err := h.Handler.ComMultiQuery(ctx, c, query, func(res *sqltypes.Result, more bool) error {
+		s.rows.add(res)
+		return callback(res, more)
+	})
+	h.log.end(c, s, executedQuery(query, remainder), err)
+	return remainder, err
+}
+
+func (h *slowQueryHandler) ComStmtExecute(ctx context.Context, c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
+	s := h.log.begin(c)
+	if s == nil {
+		return h.Handler.ComStmtExecute(ctx, c, prepare, callback)
+	}
+	err := h.Handler.ComStmtExecute(ctx, c, prepare, func(res *sqltypes.Result) error {
+		s.rows.add(res)
+		return callback(res)
+	})
+	h.log.end(c, s, prepare.PrepareStmt, err)
+	return err
+}
+
+// ComRegisterReplica implements mysql.BinlogReplicaHandler, so that binlog
+// replicas can still connect to a server with a slow query log.
+func (h *slowQueryHandler) ComRegisterReplica(c *mysql.Conn, replicaHost string, replicaPort uint16, replicaUser string, replicaPassword string) error {
+	if bh, ok := h.Handler.(mysql.BinlogReplicaHandler); ok {
+		return bh.ComRegisterReplica(c, replicaHost, replicaPort, replicaUser, replicaPassword)
+	}
+	return errors.New("binlog replication is not supported by this server")
+}
+
+// ComBinlogDumpGTID implements mysql.BinlogReplicaHandler.
+func (h *slowQueryHandler) ComBinlogDumpGTID(c *mysql.Conn, logFile string, logPos uint64, gtidSet mysql.GTIDSet) error {
+	if bh, ok := h.Handler.(mysql.BinlogReplicaHandler); ok {
+		return bh.ComBinlogDumpGTID(c, logFile, logPos, gtidSet)
+	}
+	return errors.New("binlog replication is not supported by this server")
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSlowQueryEnv struct {
+	s            slowQuerySettings
+	examined     uint64
+	tracking     bool
+	planTracking bool
+	// plans counts the plans shown by the slow query log
+	plans int
+}
+
+func (e *fakeSlowQueryEnv) settings(uint32) slowQuerySettings {
+	return e.s
+}
+
+func (e *fakeSlowQueryEnv) trackRowsExamined(uint32) func() uint64 {
+	e.tracking = true
+	return func() uint64 {
+		e.tracking = false
+		return e.examined
+	}
+}
+
+func (e *fakeSlowQueryEnv) sessionInfo(uint32) (string, string) {
+	return "mydb", "feature"
+}
+
+func (e *fakeSlowQueryEnv) trackQueryPlan(uint32) func() fmt.Stringer {
+	e.planTracking = true
+	return func() fmt.Stringer {
+		e.planTracking = false
+		return fakePlan{env: e}
+	}
+}
+
+// fakePlan is the plan of every statement run in a fakeSlowQueryEnv.
+type fakePlan struct {
+	env *fakeSlowQueryEnv
+}
+
+func (p fakePlan) String() string {
+	p.env.plans++
+	return "Project\n └─ Table\n"
+}
+
+// slowHandler is a fakeHandler which takes |delay| to run each query.
+type slowHandler struct {
+	fakeHandler
+	delay time.Duration
+}
+
+func (h slowHandler) ComQuery(ctx context.Context, c *mysql.Conn, query string, callback mysql.ResultSpoolFn) error {
+	time.Sleep(h.delay)
+	return h.fakeHandler.ComQuery(ctx, c, query, callback)
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	ctx := context.Background()
+	lgr := logrus.NewEntry(logrus.StandardLogger())
+	spool := func(*sqltypes.Result, bool) error {
+		return nil
+	}
+	result := &sqltypes.Result{Rows: [][]sqltypes.Value{{}, {}}}
+
+	t.Run("disabled", func(t *testing.T) {
+		env := &fakeSlowQueryEnv{s: slowQuerySettings{path: filepath.Join(t.TempDir(), "slow.log"), tableSize: 10}}
+		log := newSlowQueryLog(lgr, env)
+		h, err := log.WrapHandler(fakeHandler{result: result})
+		require.NoError(t, err)
+		c := newTestConn(t, 1)
+		require.NoError(t, h.ComQuery(ctx, c, "select * from t", spool))
+		assert.Empty(t, log.SlowQueries())
+		assert.False(t, env.planTracking)
+		assert.Zero(t, env.plans)
+		_, err = os.Stat(env.s.path)
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logs", "slow.log")
+		env := &fakeSlowQueryEnv{
+			s:        slowQuerySettings{enabled: true, path: path, longQueryTime: 20 * time.Millisecond, tableSize: 2},
+			examined: 7,
+		}
+		log := newSlowQueryLog(lgr, env)
+		defer log.Close()
+		h, err := log.WrapHandler(slowHandler{fakeHandler: fakeHandler{result: result}, delay: 30 * time.Millisecond})
+		require.NoError(t, err)
+		fast, err := log.WrapHandler(fakeHandler{result: result})
+		require.NoError(t, err)
+
+		c := newTestConn(t, 3)
+		c.User = "alice"
+		require.NoError(t, fast.ComQuery(ctx, c, "select 1", spool))
+		assert.False(t, env.tracking)
+		assert.False(t, env.planTracking)
+		assert.Zero(t, env.plans)
+		require.NoError(t, h.ComQuery(ctx, c, "select * from t", spool))
+		assert.False(t, env.tracking)
+		assert.False(t, env.planTracking)
+
+		queries := log.SlowQueries()
+		require.Len(t, queries, 1)
+		sq := queries[0]
+		assert.Equal(t, "select * from t", sq.Query)
+		assert.Equal(t, "alice", sq.User)
+		assert.Equal(t, "pipe", sq.Host)
+		assert.Equal(t, uint32(3), sq.ConnectionId)
+		assert.Equal(t, "mydb", sq.Database)
+		assert.Equal(t, "feature", sq.Branch)
+		assert.Equal(t, uint64(7), sq.RowsExamined)
+		assert.Equal(t, uint64(2), sq.RowsSent)
+		assert.GreaterOrEqual(t, sq.QueryTime, 30*time.Millisecond)
+		assert.Equal(t, "Project\n └─ Table\n", sq.Plan)
+		assert.Equal(t, 1, env.plans)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		entry := string(data)
+		assert.True(t, strings.HasPrefix(entry, "# Time: "))
+		assert.Contains(t, entry, "# User@Host: alice[alice] @ pipe [pipe]  Id: 3\n")
+		assert.Contains(t, entry, "Rows_sent: 2  Rows_examined: 7")
+		assert.Contains(t, entry, "# Branch: feature\n")
+		assert.Contains(t, entry, "# Plan:\n#   Project\n#    └─ Table\n")
+		assert.Contains(t, entry, "use mydb;\n")
+		assert.True(t, strings.HasSuffix(entry, "select * from t;\n"))
+
+		// Only the last |tableSize| slow queries are kept.
+		for _, q := range []string{"select * from t2", "create user 'u' identified by 'secret'", "show tables"} {
+			require.NoError(t, h.ComQuery(ctx, c, q, spool))
+		}
+		queries = log.SlowQueries()
+		require.Len(t, queries, 2)
+		assert.Equal(t, "", queries[0].Query)
+		assert.Equal(t, "show tables", queries[1].Query)
+		assert.Empty(t, queries[1].Plan)
+		// only selects and DML show their plans
+		assert.Equal(t, 2, env.plans)
+
+		data, err = os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, 4, strings.Count(string(data), "# Time: "))
+		assert.NotContains(t, string(data), "secret")
+
+		env.s.tableSize = 1
+		require.NoError(t, h.ComQuery(ctx, c, "select * from t3", spool))
+		queries = log.SlowQueries()
+		require.Len(t, queries, 1)
+		assert.Equal(t, "select * from t3", queries[0].Query)
+	})
+}
@@ -0,0 +1,335 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/ttl"
+)
+
+// ttlQueryFunc runs a statement in a session of the server and returns its
+// rows.
+type ttlQueryFunc func(query string) ([]sql.Row, error)
+
+// ttlPurgeEnv opens the sessions in which the TTL purger runs its statements.
+type ttlPurgeEnv interface {
+	// open returns a new session whose current database is the branch
+	// |branch| of the database |database|.
+	open(database, branch string) (ttlQueryFunc, error)
+}
+
+// ttlPurger periodically deletes the expired rows of the tables with a TTL on
+// the branches of its configuration. Each purge of a branch deletes the
+// expired rows of each table in batches, and commits each batch on its own,
+// so that the batch size bounds both the transaction and the commit. Tables
+// with uncommitted changes, and every table of a branch with staged changes,
+// are skipped.
+type ttlPurger struct {
+	lgr *logrus.Entry
+	cfg servercfg.TTLPurgeConfig
+	env ttlPurgeEnv
+
+	mu     sync.Mutex
+	purges map[ttlPurgeKey]dtables.TTLPurge
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+var (
+	errTTLStagedChanges      = errors.New("the branch has staged changes")
+	errTTLUncommittedChanges = errors.New("the table has uncommitted changes")
+)
+
+type ttlPurgeKey struct {
+	database, branch, table string
+}
+
+var _ dtables.TTLStatusProvider = (*ttlPurger)(nil)
+
+// newTTLPurger returns a ttlPurger for |cfg|. It does not purge any rows
+// until it is started.
+func newTTLPurger(lgr *logrus.Entry, cfg servercfg.TTLPurgeConfig, env ttlPurgeEnv) *ttlPurger {
+	return &ttlPurger{
+		lgr:    lgr,
+		cfg:    cfg,
+		env:    env,
+		purges: make(map[ttlPurgeKey]dtables.TTLPurge),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start purges the expired rows every IntervalMillis until the purger is
+// closed.
+func (p *ttlPurger) Start() {
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(time.Duration(p.cfg.IntervalMillis()) * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.purge()
+			}
+		}
+	}()
+}
+
+// Close stops the purger, waiting for a running purge to finish.
+func (p *ttlPurger) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		select {
+		case <-p.done:
+		case <-time.After(time.Duration(p.cfg.IntervalMillis())*time.Millisecond + time.Minute):
+			p.lgr.Warn("timed out waiting for the TTL purger to stop")
+		}
+	})
+	return nil
+}
+
+// TTLPurges implements dtables.TTLStatusProvider.
+func (p *ttlPurger) TTLPurges() []dtables.TTLPurge {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ret := make([]dtables.TTLPurge, 0, len(p.purges))
+	for _, purge := range p.purges {
+		ret = append(ret, purge)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Database != ret[j].Database {
+			return ret[i].Database < ret[j].Database
+		}
+		if ret[i].Branch != ret[j].Branch {
+			return ret[i].Branch < ret[j].Branch
+		}
+		return ret[i].Table < ret[j].Table
+	})
+	return ret
+}
+
+// purge purges the expired rows of each branch of the configuration.
+func (p *ttlPurger) purge() {
+	for _, b := range p.cfg.Branches() {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+		if err := p.purgeBranch(b.Database(), b.Branch()); err != nil {
+			p.lgr.Warnf("error purging expired rows of %s/%s: %v", b.Database(), b.Branch(), err)
+		}
+	}
+}
+
+// purgeBranch purges the expired rows of the tables with a TTL on the branch
+// |branch| of |database|, and records the purge of each table.
+func (p *ttlPurger) purgeBranch(database, branch string) error {
+	start := time.Now()
+	query, err := p.env.open(database, branch)
+	if err != nil {
+		return err
+	}
+	tables, err := query("SELECT table_name, ttl FROM " + doltdb.TTLStatusTableName + " ORDER BY table_name")
+	if err != nil {
+		return err
+	}
+	for _, row := range tables {
+		table, spec := fmt.Sprint(row[0]), fmt.Sprint(row[1])
+		purged, commit, err := p.purgeTable(query, table, spec)
+		p.record(database, branch, table, start, purged, commit, err)
+	}
+	return nil
+}
+
+// record records the purge of |table| on the branch |branch| of |database|,
+// which started at |start|, committed |purged| rows in commits up to
+// |commit|, and was stopped by |err|.
+func (p *ttlPurger) record(database, branch, table string, start time.Time, purged uint64, commit string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := ttlPurgeKey{database: strings.ToLower(database), branch: branch, table: strings.ToLower(table)}
+	purge := dtables.TTLPurge{
+		Database:        database,
+		Branch:          branch,
+		Table:           table,
+		Time:            start,
+		RowsPurged:      purged,
+		TotalRowsPurged: p.purges[key].TotalRowsPurged + purged,
+		Commit:          commit,
+	}
+	if err != nil {
+		purge.Error = err.Error()
+	}
+	p.purges[key] = purge
+}
+
+// purgeTable deletes the expired rows of |table|, whose TTL is |spec|, in at
+// most MaxBatches batches. It returns how many rows it deleted, and the hash
+// of the commit of its last batch.
+func (p *ttlPurger) purgeTable(query ttlQueryFunc, table, spec string) (uint64, string, error) {
+	t, err := ttl.Parse(spec)
+	if err != nil {
+		return 0, "", err
+	}
+	batchSize := uint64(p.cfg.BatchSize())
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT %d", sqlfmt.QuoteIdentifier(table), t.Expired(), batchSize)
+
+	var purged uint64
+	var commit string
+	for i := 0; i < p.cfg.MaxBatches(); i++ {
+		n, c, err := p.purgeBatch(query, table, stmt)
+		if err != nil {
+			return purged, commit, err
+		}
+		if c != "" {
+			commit = c
+		}
+		purged += n
+		if n < batchSize {
+			break
+		}
+	}
+	return purged, commit, nil
+}
+
+// purgeBatch runs |stmt|, which deletes a batch of the expired rows of
+// |table|, and commits the deleted rows. Each batch runs in its own
+// transaction, whose working set only holds the deletions of the batch, so
+// that the writes of other sessions are never staged by the purge and
+// committed under its author. The transaction is rolled back unless its
+// deletions are committed. purgeBatch returns how many rows it deleted, and
+// the hash of their commit.
+func (p *ttlPurger) purgeBatch(query ttlQueryFunc, table, stmt string) (n uint64, commit string, err error) {
+	if _, err := query("START TRANSACTION"); err != nil {
+		return 0, "", err
+	}
+	end := "ROLLBACK"
+	defer func() {
+		if _, endErr := query(end); endErr != nil && err == nil {
+			n, commit, err = 0, "", endErr
+		}
+	}()
+
+	status, err := query("SELECT table_name, staged FROM " + doltdb.StatusTableName)
+	if err != nil {
+		return 0, "", err
+	}
+	for _, row := range status {
+		if isTrue(row[1]) {
+			return 0, "", errTTLStagedChanges
+		}
+		if strings.EqualFold(fmt.Sprint(row[0]), table) {
+			return 0, "", errTTLUncommittedChanges
+		}
+	}
+
+	rows, err := query(stmt)
+	if err != nil {
+		return 0, "", err
+	}
+	n = rowsAffected(rows)
+	if n == 0 {
+		return 0, "", nil
+	}
+	commit, err = p.commit(query, table)
+	if err != nil {
+		return 0, "", err
+	}
+	end = "COMMIT"
+	return n, commit, nil
+}
+
+// commit commits the deletions from |table| and returns the hash of the commit.
+func (p *ttlPurger) commit(query ttlQueryFunc, table string) (string, error) {
+	if _, err := query("CALL DOLT_ADD(" + sqlString(table) + ")"); err != nil {
+		return "", err
+	}
+	message := "Purge expired rows of " + table
+	rows, err := query(fmt.Sprintf("CALL DOLT_COMMIT('-m', %s, '--author', %s)", sqlString(message), sqlString(p.cfg.CommitAuthor())))
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return "", errors.New("DOLT_COMMIT returned no commit")
+	}
+	return fmt.Sprint(rows[0][0]), nil
+}
+
+// rowsAffected returns the number of rows affected by the statement whose result is |rows|.
+func rowsAffected(rows []sql.Row) uint64 {
+	if len(rows) == 1 && len(rows[0]) == 1 {
+		if res, ok := rows[0][0].(types.OkResult); ok {
+			return res.RowsAffected
+		}
+	}
+	return 0
+}
+
+// isTrue returns whether |v|, the value of a BOOLEAN column, is true.
+func isTrue(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case int8:
+		return v != 0
+	}
+	return false
+}
+
+// serverTTLPurgeEnv is the ttlPurgeEnv of a running sql-server.
+type serverTTLPurgeEnv struct {
+	se *engine.SqlEngine
+}
+
+var _ ttlPurgeEnv = (*serverTTLPurgeEnv)(nil)
+
+func (e *serverTTLPurgeEnv) open(database, branch string) (ttlQueryFunc, error) {
+	ctx, err := e.se.NewLocalContext(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	query := func(q string) ([]sql.Row, error) {
+		_, iter, err := e.se.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(ctx, iter)
+	}
+	if _, err := query("USE " + sqlfmt.QuoteIdentifier(database+"/"+branch)); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+)
+
+// fakeTTLPurgeEnv is a ttlPurgeEnv whose sessions return canned results. The
+// DELETE statements of a table affect the rows in |expired| for the table, at
+// most a batch at a time.
+type fakeTTLPurgeEnv struct {
+	tables  []sql.Row
+	status  []sql.Row
+	expired map[string]uint64
+	queries []string
+	commits int
+}
+
+func (e *fakeTTLPurgeEnv) open(database, branch string) (ttlQueryFunc, error) {
+	return func(query string) ([]sql.Row, error) {
+		e.queries = append(e.queries, query)
+		switch {
+		case strings.HasPrefix(query, "SELECT table_name, ttl"):
+			return e.tables, nil
+		case strings.HasPrefix(query, "SELECT table_name, staged"):
+			return e.status, nil
+		case strings.HasPrefix(query, "DELETE FROM"):
+			for table, n := range e.expired {
+				if strings.HasPrefix(query, "DELETE FROM `"+table+"`") {
+					affected := n
+					if affected > 2 {
+						affected = 2
+					}
+					e.expired[table] -= affected
+					return []sql.Row{{types.NewOkResult(int(affected))}}, nil
+				}
+			}
+			return []sql.Row{{types.NewOkResult(0)}}, nil
+		case strings.HasPrefix(query, "CALL DOLT_COMMIT"):
+			e.commits++
+			return []sql.Row{{fmt.Sprintf("commit%d", e.commits)}}, nil
+		}
+		return nil, nil
+	}, nil
+}
+
+func TestTTLPurger(t *testing.T) {
+	lgr := logrus.NewEntry(logrus.StandardLogger())
+	ptr := func(v int) *int {
+		return &v
+	}
+	str := func(s string) *string {
+		return &s
+	}
+	cfg := &servercfg.TTLPurgeYAMLConfig{
+		BatchSize_:  ptr(2),
+		MaxBatches_: ptr(2),
+		Branches_: []servercfg.TTLPurgeBranchYAMLConfig{
+			{Database_: str("mydb"), Branch_: str("main")},
+		},
+	}
+
+	t.Run("purges and commits expired rows", func(t *testing.T) {
+		env := &fakeTTLPurgeEnv{
+			tables: []sql.Row{
+				{"events", "`created_at` + INTERVAL 1 DAY"},
+				{"sessions", "`seen` + INTERVAL 30 MINUTE"},
+				{"users", "`joined` + INTERVAL 1 YEAR"},
+			},
+			status:  []sql.Row{{"users", false, "modified"}},
+			expired: map[string]uint64{"events": 5, "sessions": 1},
+		}
+		p := newTTLPurger(lgr, cfg, env)
+		p.purge()
+
+		// each batch is deleted and committed in its own transaction
+		assert.Equal(t, []string{
+			"SELECT table_name, ttl FROM dolt_ttl_status ORDER BY table_name",
+			"START TRANSACTION",
+			"SELECT table_name, staged FROM dolt_status",
+			"DELETE FROM `events` WHERE `created_at` <= DATE_SUB(NOW(), INTERVAL 1 DAY) LIMIT 2",
+			"CALL DOLT_ADD('events')",
+			"CALL DOLT_COMMIT('-m', 'Purge expired rows of events', '--author', 'Dolt TTL Purger <doltuser@dolthub.com>')",
+			"COMMIT",
+			"START TRANSACTION",
+			"SELECT table_name, staged FROM dolt_status",
+			"DELETE FROM `events` WHERE `created_at` <= DATE_SUB(NOW(), INTERVAL 1 DAY) LIMIT 2",
+			"CALL DOLT_ADD('events')",
+			"CALL DOLT_COMMIT('-m', 'Purge expired rows of events', '--author', 'Dolt TTL Purger <doltuser@dolthub.com>')",
+			"COMMIT",
+			"START TRANSACTION",
+			"SELECT table_name, staged FROM dolt_status",
+			"DELETE FROM `sessions` WHERE `seen` <= DATE_SUB(NOW(), INTERVAL 30 MINUTE) LIMIT 2",
+			"CALL DOLT_ADD('sessions')",
+			"CALL DOLT_COMMIT('-m', 'Purge expired rows of sessions', '--author', 'Dolt TTL Purger <doltuser@dolthub.com>')",
+			"COMMIT",
+			"START TRANSACTION",
+			"SELECT table_name, staged FROM dolt_status",
+			"ROLLBACK",
+		}, env.queries)
+
+		purges := p.TTLPurges()
+		require.Len(t, purges, 3)
+		assert.Equal(t, "events", purges[0].Table)
+		assert.Equal(t, uint64(4), purges[0].RowsPurged)
+		assert.Equal(t, uint64(4), purges[0].TotalRowsPurged)
+		assert.Equal(t, "commit2", purges[0].Commit)
+		assert.Equal(t, "sessions", purges[1].Table)
+		assert.Equal(t, uint64(1), purges[1].RowsPurged)
+		assert.Equal(t, "commit3", purges[1].Commit)
+		assert.Equal(t, "users", purges[2].Table)
+		assert.Equal(t, uint64(0), purges[2].RowsPurged)
+		assert.Equal(t, "", purges[2].Commit)
+		assert.Equal(t, "the table has uncommitted changes", purges[2].Error)
+
+		env.status = nil
+		p.purge()
+		purges = p.TTLPurges()
+		assert.Equal(t, uint64(1), purges[0].RowsPurged)
+		assert.Equal(t, uint64(5), purges[0].TotalRowsPurged)
+		assert.Equal(t, "commit4", purges[0].Commit)
+		assert.Equal(t, uint64(0), purges[1].RowsPurged)
+		assert.Equal(t, uint64(1), purges[1].TotalRowsPurged)
+		assert.Equal(t, "", purges[1].Commit)
+		assert.Equal(t, "", purges[2].Error)
+	})
+
+	t.Run("records the tables of branches with staged changes", func(t *testing.T) {
+		env := &fakeTTLPurgeEnv{
+			tables: []sql.Row{
+				{"events", "`created_at` + INTERVAL 1 DAY"},
+				{"sessions", "`seen` + INTERVAL 30 MINUTE"},
+			},
+			status:  []sql.Row{{"other", true, "new table"}},
+			expired: map[string]uint64{"events": 5},
+		}
+		p := newTTLPurger(lgr, cfg, env)
+		require.NoError(t, p.purgeBranch("mydb", "main"))
+		assert.Equal(t, uint64(5), env.expired["events"])
+		assert.Zero(t, env.commits)
+		purges := p.TTLPurges()
+		require.Len(t, purges, 2)
+		for _, purge := range purges {
+			assert.Equal(t, uint64(0), purge.RowsPurged)
+			assert.Equal(t, "", purge.Commit)
+			assert.Equal(t, "the branch has staged changes", purge.Error)
+		}
+		for _, q := range env.queries {
+			assert.False(t, strings.HasPrefix(q, "DELETE FROM"), q)
+		}
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
@@ -40,6 +41,7 @@ import (
 	eventsapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/eventsapi/v1alpha1"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
 	"github.com/dolthub/dolt/go/libraries/doltcore/mvdata"
 	"github.com/dolthub/dolt/go/libraries/doltcore/rowconv"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
@@ -71,6 +73,8 @@ const (
 	ignoreSkippedRows = "ignore-skipped-rows" // alias for quiet
 	disableFkChecks   = "disable-fk-checks"
 	allTextParam      = "all-text"
+	inferParam        = "infer"
+	sampleRowsParam   = "sample-rows"
 )
 
 var jsonInputFileHelp = "The expected JSON input file format is:" + `
@@ -93,6 +97,8 @@ var importDocs = cli.CommandDocumentationContent{
 
 The schema for the new table can be specified explicitly by providing a SQL schema definition file, or will be inferred from the imported file.  All schemas, inferred or explicitly defined must define a primary key.  If the file format being imported does not support defining a primary key, then the {{.EmphasisLeft}}--pk{{.EmphasisRight}} parameter must supply the name of the field that should be used as the primary key. If no primary key is explicitly defined, the first column in the import file will be used as the primary key.
 
+If {{.EmphasisLeft}}--infer{{.EmphasisRight}} is given along with {{.EmphasisLeft}}--create-table{{.EmphasisRight}}, the file is profiled to propose VARCHAR lengths, ENUMs for low-cardinality columns, NOT NULL constraints and, unless {{.EmphasisLeft}}--pk{{.EmphasisRight}} is given, a single or composite primary key made of columns whose values are unique and never empty. When every row is profiled, other columns whose values are unique get a UNIQUE key. Up to {{.EmphasisLeft}}--sample-rows{{.EmphasisRight}} rows are profiled. Use {{.EmphasisLeft}}dolt schema import --create --infer --dry-run{{.EmphasisRight}} to preview the inferred schema and the reason for each choice.
+
 If {{.EmphasisLeft}}--update-table | -u{{.EmphasisRight}} is given the operation will update {{.LessThan}}table{{.GreaterThan}} with the contents of file. The table's existing schema will be used, and field names will be used to match file fields with table fields unless a mapping file is specified.
 
 If {{.EmphasisLeft}}--append-table | -a{{.EmphasisRight}} is given the operation will add the contents of the file to {{.LessThan}}table{{.GreaterThan}}, without modifying any of the rows of {{.LessThan}}table{{.GreaterThan}}. If the file contains a row that matches the primary key of a row already in the table, the import will be aborted unless the --continue flag is used (in which case that row will not be imported.) The table's existing schema will be used, and field names will be used to match file fields with table fields unless a mapping file is specified.
@@ -112,7 +118,7 @@ During import, if there is an error importing any row, the import will be aborte
 In create, update, and replace scenarios the file's extension is used to infer the type of the file.  If a file does not have the expected extension then the {{.EmphasisLeft}}--file-type{{.EmphasisRight}} parameter should be used to explicitly define the format of the file in one of the supported formats (csv, psv, json, xlsx).  For files separated by a delimiter other than a ',' (type csv) or a '|' (type psv), the --delim parameter can be used to specify a delimiter`,
 
 	Synopsis: []string{
-		"-c [-f] [--pk {{.LessThan}}field{{.GreaterThan}}] [--all-text] [--schema {{.LessThan}}file{{.GreaterThan}}] [--map {{.LessThan}}file{{.GreaterThan}}] [--continue]  [--quiet] [--disable-fk-checks] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
+		"-c [-f] [--pk {{.LessThan}}field{{.GreaterThan}}] [--all-text] [--infer [--sample-rows {{.LessThan}}n{{.GreaterThan}}]] [--schema {{.LessThan}}file{{.GreaterThan}}] [--map {{.LessThan}}file{{.GreaterThan}}] [--continue]  [--quiet] [--disable-fk-checks] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
 		"-u [--map {{.LessThan}}file{{.GreaterThan}}] [--continue] [--quiet] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
 		"-a [--map {{.LessThan}}file{{.GreaterThan}}] [--continue] [--quiet] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
 		"-r [--map {{.LessThan}}file{{.GreaterThan}}] [--file-type {{.LessThan}}type{{.GreaterThan}}] {{.LessThan}}table{{.GreaterThan}} {{.LessThan}}file{{.GreaterThan}}",
@@ -134,6 +140,8 @@ type importOptions struct {
 	quiet           bool
 	disableFkChecks bool
 	allText         bool
+	infer           bool
+	sampleRows      int
 }
 
 func (m importOptions) IsBatched() bool {
@@ -198,6 +206,8 @@ func getImportMoveOptions(ctx context.Context, apr *argparser.ArgParseResults, d
 	quiet := apr.Contains(quiet)
 	disableFks := apr.Contains(disableFkChecks)
 	allText := apr.Contains(allTextParam)
+	infer := apr.Contains(inferParam)
+	sampleRows, _ := apr.GetInt(sampleRowsParam)
 
 	val, _ := apr.GetValue(primaryKeyParam)
 	pks := funcitr.MapStrings(strings.Split(val, ","), strings.TrimSpace)
@@ -280,6 +290,8 @@ func getImportMoveOptions(ctx context.Context, apr *argparser.ArgParseResults, d
 		quiet:           quiet,
 		disableFkChecks: disableFks,
 		allText:         allText,
+		infer:           infer,
+		sampleRows:      sampleRows,
 	}, nil
 
 }
@@ -313,6 +325,22 @@ func validateImportArgs(apr *argparser.ArgParseResults) errhand.VerboseError {
 		return errhand.BuildDError("parameters %s and %s are mutually exclusive", allTextParam, schemaParam).Build()
 	}
 
+	if apr.Contains(inferParam) && !apr.Contains(createParam) {
+		return errhand.BuildDError("fatal: --%s is only supported for create operations", inferParam).Build()
+	}
+
+	if apr.Contains(inferParam) && apr.ContainsAny(allTextParam, schemaParam) {
+		return errhand.BuildDError("parameter %s cannot be used with %s or %s", inferParam, allTextParam, schemaParam).Build()
+	}
+
+	if apr.Contains(sampleRowsParam) && !apr.Contains(inferParam) {
+		return errhand.BuildDError("fatal: --%s requires --%s", sampleRowsParam, inferParam).Build()
+	}
+
+	if n, ok := apr.GetInt(sampleRowsParam); ok && n <= 0 {
+		return errhand.BuildDError("fatal: --%s must be a positive number of rows", sampleRowsParam).Build()
+	}
+
 	tableName := apr.Arg(0)
 	if err := schcmds.ValidateTableNameForCreate(tableName); err != nil {
 		return err
@@ -390,6 +418,8 @@ func (cmd ImportCmd) ArgParser() *argparser.ArgParser {
 	ap.SupportsString(fileTypeParam, "", "file_type", "Explicitly define the type of the file if it can't be inferred from the file extension.")
 	ap.SupportsString(delimParam, "", "delimiter", "Specify a delimiter for a csv style file with a non-comma delimiter.")
 	ap.SupportsFlag(allTextParam, "", "Treats all fields as text. Can only be used when creating a table.")
+	ap.SupportsFlag(inferParam, "", "Profile the file to infer a primary key, unique keys, string lengths, enums and NOT NULL constraints. Can only be used when creating a table.")
+	ap.SupportsInt(sampleRowsParam, "", "n", fmt.Sprintf("The number of rows profiled by --%s. Defaults to %d.", inferParam, actions.DefaultInferenceSampleRows))
 	return ap
 }
 
@@ -671,7 +701,7 @@ func moveRows(
 				return err
 			}
 		} else {
-			sqlRow, err = NameAndTypeTransform(sqlRow, wr.RowOperationSchema(), rdSqlSch, options.nameMapper)
+			sqlRow, err = NameAndTypeTransform(sqlRow, wr.RowOperationSchema(), rdSqlSch, options.nameMapper, options.infer)
 			if err != nil {
 				return err
 			}
@@ -731,6 +761,20 @@ func getImportSchema(ctx context.Context, dEnv *env.DoltEnv, impOpts *importOpti
 			return nil, &mvdata.DataMoverCreationError{ErrType: mvdata.SchemaErr, Cause: err}
 		}
 
+		if impOpts.infer {
+			outSch, inferred, err := mvdata.InferSchemaWithConstraints(ctx, root, rd, impOpts.destTableName, impOpts.primaryKeys, impOpts, impOpts.sampleRows)
+			if err != nil {
+				return nil, &mvdata.DataMoverCreationError{ErrType: mvdata.SchemaErr, Cause: err}
+			}
+			if len(impOpts.primaryKeys) == 0 {
+				cli.PrintErrln(color.CyanString("Inferred primary key (%s): %s", strings.Join(inferred.PrimaryKey, ", "), inferred.PrimaryKeyReason))
+			}
+			for _, uk := range inferred.UniqueKeys {
+				cli.PrintErrln(color.CyanString("Inferred unique key (%s): %s", strings.Join(uk.Columns, ", "), uk.Reason))
+			}
+			return outSch, nil
+		}
+
 		outSch, err := mvdata.InferSchema(ctx, root, rd, impOpts.destTableName, impOpts.primaryKeys, impOpts)
 		if err != nil {
 			return nil, &mvdata.DataMoverCreationError{ErrType: mvdata.SchemaErr, Cause: err}
@@ -825,8 +869,9 @@ func newDataMoverErrToVerr(mvOpts *importOptions, err *mvdata.DataMoverCreationE
 }
 
 // NameAndTypeTransform does 1) match the read and write schema with subsetting and name matching. 2) Address any
-// type inconsistencies.
-func NameAndTypeTransform(row sql.Row, rowOperationSchema sql.PrimaryKeySchema, rdSchema sql.PrimaryKeySchema, nameMapper rowconv.NameMapper) (sql.Row, error) {
+// type inconsistencies. If |extendedDatetimes| is true, the date formats recognized by schema inference are converted
+// for datetime columns.
+func NameAndTypeTransform(row sql.Row, rowOperationSchema sql.PrimaryKeySchema, rdSchema sql.PrimaryKeySchema, nameMapper rowconv.NameMapper, extendedDatetimes bool) (sql.Row, error) {
 	row = applyMapperToRow(row, rowOperationSchema, rdSchema, nameMapper)
 
 	for i, col := range rowOperationSchema.Schema {
@@ -837,6 +882,15 @@ func NameAndTypeTransform(row sql.Row, rowOperationSchema sql.PrimaryKeySchema,
 			continue
 		}
 
+		// Schema inference recognizes some date formats that the engine can't parse, like 01/02/2006. Those are
+		// converted here so that the values can be written to the inferred column.
+		if extendedDatetimes {
+			if t, ok := detectAndConvertExtendedDatetime(row[i], col.Type); ok {
+				row[i] = t
+				continue
+			}
+		}
+
 		// Bit types need additional verification due to the differing values they can take on. "4", "0x04", b'100' should
 		// be interpreted in the correct manner.
 		if _, ok := col.Type.(gmstypes.BitType); ok {
@@ -902,6 +956,19 @@ func detectAndConvertToBoolean(columnVal interface{}, columnType sql.Type) (bool
 	return false, false
 }
 
+// detectAndConvertExtendedDatetime converts strings in the date formats recognized by schema inference, but not by the
+// engine, into time values for datetime columns.
+func detectAndConvertExtendedDatetime(columnVal interface{}, columnType sql.Type) (time.Time, bool) {
+	if _, ok := columnType.(sql.DatetimeType); !ok {
+		return time.Time{}, false
+	}
+	s, ok := columnVal.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	return actions.ParseExtendedDatetime(s)
+}
+
 func stringToBoolean(s string) (result bool, canConvert bool) {
 	lower := strings.ToLower(s)
 	switch lower {
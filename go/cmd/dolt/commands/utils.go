@@ -330,6 +330,29 @@ func InterpolateAndRunQuery(queryist cli.Queryist, sqlCtx *sql.Context, queryTem
 	return GetRowsForSql(queryist, sqlCtx, query)
 }
 
+// BranchForRef returns the branch name |ref| refers to, or the empty string if it isn't a branch.
+func BranchForRef(queryist cli.Queryist, sqlCtx *sql.Context, ref string) (string, error) {
+	if strings.EqualFold(ref, "HEAD") {
+		rows, err := InterpolateAndRunQuery(queryist, sqlCtx, "select active_branch()")
+		if err != nil {
+			return "", err
+		}
+		if len(rows) == 0 || rows[0][0] == nil {
+			return "", nil
+		}
+		return fmt.Sprint(rows[0][0]), nil
+	}
+
+	rows, err := InterpolateAndRunQuery(queryist, sqlCtx, "select name from dolt_branches where name = ?", ref)
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "", nil
+	}
+	return ref, nil
+}
+
 // GetTinyIntColAsBool returns the value of a tinyint column as a bool
 // This is necessary because Queryist may return a tinyint column as a bool (when using SQLEngine)
 // or as a string (when using ConnectionQueryist).
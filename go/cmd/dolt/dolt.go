@@ -43,11 +43,14 @@ import (
 	"github.com/dolthub/dolt/go/cmd/dolt/cli"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/admin"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/bundlecmds"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/cdccmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/cnfcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/credcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/cvcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/docscmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/indexcmds"
+	"github.com/dolthub/dolt/go/cmd/dolt/commands/patchcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/schcmds"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/sqlserver"
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/stashcmds"
@@ -87,12 +90,15 @@ var doltSubCommands = []cli.Command{
 	commands.CheckoutCmd{},
 	commands.MergeCmd{},
 	cnfcmds.Commands,
+	cdccmds.Commands,
 	commands.CherryPickCmd{},
+	patchcmds.Commands,
 	commands.RevertCmd{},
 	commands.CloneCmd{},
 	commands.FetchCmd{},
 	commands.PullCmd{},
 	commands.PushCmd{},
+	bundlecmds.Commands,
 	commands.ConfigCmd{},
 	commands.RemoteCmd{},
 	commands.BackupCmd{},
@@ -142,7 +148,6 @@ var commandsWithoutCliCtx = []cli.Command{
 	commands.FilterBranchCmd{},
 	commands.RootsCmd{},
 	commands.VersionCmd{VersionStr: doltversion.Version},
-	commands.DumpCmd{},
 	commands.InspectCmd{},
 	dumpDocsCommand,
 	dumpZshCommand,
@@ -46,6 +46,24 @@ type ReplicationServiceClient interface {
 	UpdateUsersAndGrants(ctx context.Context, in *UpdateUsersAndGrantsRequest, opts ...grpc.CallOption) (*UpdateUsersAndGrantsResponse, error)
 	UpdateBranchControl(ctx context.Context, in *UpdateBranchControlRequest, opts ...grpc.CallOption) (*UpdateBranchControlResponse, error)
 	DropDatabase(ctx context.Context, in *DropDatabaseRequest, opts ...grpc.CallOption) (*DropDatabaseResponse, error)
+	// Returns the current cluster role and epoch of the server, along with
+	// the root hashes of the databases it replicates. When automatic failover
+	// is enabled, every server in the cluster calls this method on its peers
+	// periodically, both to monitor the liveness of the primary and to
+	// determine which standby is the most caught up when the primary becomes
+	// unreachable. Unlike the other methods, this method is served regardless
+	// of the role of the server.
+	GetClusterStatus(ctx context.Context, in *GetClusterStatusRequest, opts ...grpc.CallOption) (*GetClusterStatusResponse, error)
+	// Asks the server to vote for a standby which is promoting itself to
+	// primary at a new epoch through automatic failover. The candidate only
+	// promotes itself once a majority of the cluster, counting itself, has
+	// voted for it. The server votes for at most one candidate at each epoch,
+	// and it records its vote durably before it answers. It only votes at an
+	// epoch higher than its own, while it is a standby which has not reached a
+	// primary for half the failover timeout.
+	// Like GetClusterStatus, this method is served regardless of the role of
+	// the server.
+	RequestVote(ctx context.Context, in *RequestVoteRequest, opts ...grpc.CallOption) (*RequestVoteResponse, error)
 }
 
 type replicationServiceClient struct {
@@ -83,6 +101,24 @@ func (c *replicationServiceClient) DropDatabase(ctx context.Context, in *DropDat
 	return out, nil
 }
 
+func (c *replicationServiceClient) GetClusterStatus(ctx context.Context, in *GetClusterStatusRequest, opts ...grpc.CallOption) (*GetClusterStatusResponse, error) {
+	out := new(GetClusterStatusResponse)
+	err := c.cc.Invoke(ctx, "/dolt.services.replicationapi.v1alpha1.ReplicationService/GetClusterStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replicationServiceClient) RequestVote(ctx context.Context, in *RequestVoteRequest, opts ...grpc.CallOption) (*RequestVoteResponse, error) {
+	out := new(RequestVoteResponse)
+	err := c.cc.Invoke(ctx, "/dolt.services.replicationapi.v1alpha1.ReplicationService/RequestVote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ReplicationServiceServer is the server API for ReplicationService service.
 // All implementations must embed UnimplementedReplicationServiceServer
 // for forward compatibility
@@ -96,6 +132,24 @@ type ReplicationServiceServer interface {
 	UpdateUsersAndGrants(context.Context, *UpdateUsersAndGrantsRequest) (*UpdateUsersAndGrantsResponse, error)
 	UpdateBranchControl(context.Context, *UpdateBranchControlRequest) (*UpdateBranchControlResponse, error)
 	DropDatabase(context.Context, *DropDatabaseRequest) (*DropDatabaseResponse, error)
+	// Returns the current cluster role and epoch of the server, along with
+	// the root hashes of the databases it replicates. When automatic failover
+	// is enabled, every server in the cluster calls this method on its peers
+	// periodically, both to monitor the liveness of the primary and to
+	// determine which standby is the most caught up when the primary becomes
+	// unreachable. Unlike the other methods, this method is served regardless
+	// of the role of the server.
+	GetClusterStatus(context.Context, *GetClusterStatusRequest) (*GetClusterStatusResponse, error)
+	// Asks the server to vote for a standby which is promoting itself to
+	// primary at a new epoch through automatic failover. The candidate only
+	// promotes itself once a majority of the cluster, counting itself, has
+	// voted for it. The server votes for at most one candidate at each epoch,
+	// and it records its vote durably before it answers. It only votes at an
+	// epoch higher than its own, while it is a standby which has not reached a
+	// primary for half the failover timeout.
+	// Like GetClusterStatus, this method is served regardless of the role of
+	// the server.
+	RequestVote(context.Context, *RequestVoteRequest) (*RequestVoteResponse, error)
 	mustEmbedUnimplementedReplicationServiceServer()
 }
 
@@ -112,6 +166,12 @@ func (UnimplementedReplicationServiceServer) UpdateBranchControl(context.Context
 func (UnimplementedReplicationServiceServer) DropDatabase(context.Context, *DropDatabaseRequest) (*DropDatabaseResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DropDatabase not implemented")
 }
+func (UnimplementedReplicationServiceServer) GetClusterStatus(context.Context, *GetClusterStatusRequest) (*GetClusterStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClusterStatus not implemented")
+}
+func (UnimplementedReplicationServiceServer) RequestVote(context.Context, *RequestVoteRequest) (*RequestVoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestVote not implemented")
+}
 func (UnimplementedReplicationServiceServer) mustEmbedUnimplementedReplicationServiceServer() {}
 
 // UnsafeReplicationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -179,6 +239,42 @@ func _ReplicationService_DropDatabase_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ReplicationService_GetClusterStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClusterStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicationServiceServer).GetClusterStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dolt.services.replicationapi.v1alpha1.ReplicationService/GetClusterStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicationServiceServer).GetClusterStatus(ctx, req.(*GetClusterStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReplicationService_RequestVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicationServiceServer).RequestVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dolt.services.replicationapi.v1alpha1.ReplicationService/RequestVote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicationServiceServer).RequestVote(ctx, req.(*RequestVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ReplicationService_ServiceDesc is the grpc.ServiceDesc for ReplicationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -198,6 +294,14 @@ var ReplicationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DropDatabase",
 			Handler:    _ReplicationService_DropDatabase_Handler,
 		},
+		{
+			MethodName: "GetClusterStatus",
+			Handler:    _ReplicationService_GetClusterStatus_Handler,
+		},
+		{
+			MethodName: "RequestVote",
+			Handler:    _ReplicationService_RequestVote_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "dolt/services/replicationapi/v1alpha1/replication.proto",
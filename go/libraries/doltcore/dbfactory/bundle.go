@@ -0,0 +1,297 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// BundleScheme is the url scheme for bundle files written by dolt bundle create
+	BundleScheme = "bundle"
+	// BundleExt is the extension of bundle files. A path is only used as a bundle without the bundle scheme if it has
+	// this extension.
+	BundleExt = ".bundle"
+
+	// BundleFormat identifies a bundle file
+	BundleFormat = "dolt-bundle"
+	// BundleVersion is the version of the bundle format written by this version of Dolt
+	BundleVersion = 1
+
+	// bundleHeaderName is the name of the first entry of a bundle, which holds its BundleHeader
+	bundleHeaderName = "BUNDLE"
+)
+
+var ErrNotABundle = errors.New("not a dolt bundle file")
+
+// BundleHeader describes the contents of a bundle file. A bundle is a tar archive whose first entry is the header,
+// encoded as JSON, followed by the files of a chunk store holding the bundled refs and every chunk reachable from them
+// that is not also reachable from the Basis commits.
+type BundleHeader struct {
+	Format     string      `json:"format"`
+	Version    int         `json:"version"`
+	NbfVersion string      `json:"nbf_version"`
+	Refs       []BundleRef `json:"refs"`
+	// Basis is the list of commits the bundle was built on top of. A database must already have every basis commit to
+	// fetch from the bundle. Empty for a complete bundle.
+	Basis []string `json:"basis,omitempty"`
+}
+
+// BundleRef is a ref stored in a bundle and the address it points to
+type BundleRef struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// IsIncremental returns whether the bundle depends on commits it does not contain
+func (h *BundleHeader) IsIncremental() bool {
+	return len(h.Basis) > 0
+}
+
+// WriteBundle writes a bundle with the header given to |wr|. |files| are paths relative to |storeDir| of the chunk store
+// files to include.
+func WriteBundle(wr io.Writer, header *BundleHeader, storeDir string, files []string) error {
+	header.Format = BundleFormat
+	header.Version = BundleVersion
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(wr)
+	err = tw.WriteHeader(&tar.Header{Name: bundleHeaderName, Mode: 0644, Size: int64(len(headerBytes))})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(headerBytes)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		err = writeBundleFile(tw, storeDir, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+func writeBundleFile(tw *tar.Writer, storeDir, file string) error {
+	f, err := os.Open(filepath.Join(storeDir, file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	err = tw.WriteHeader(&tar.Header{Name: filepath.ToSlash(file), Mode: 0644, Size: info.Size()})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// ReadBundleHeader reads the header of the bundle file at |path|.
+func ReadBundleHeader(path string) (*BundleHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readBundleHeader(tar.NewReader(f))
+}
+
+func readBundleHeader(tr *tar.Reader) (*BundleHeader, error) {
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != bundleHeaderName {
+		return nil, ErrNotABundle
+	}
+
+	var header BundleHeader
+	err = json.NewDecoder(tr).Decode(&header)
+	if err != nil || header.Format != BundleFormat {
+		return nil, ErrNotABundle
+	}
+	if header.Version > BundleVersion {
+		return nil, fmt.Errorf("bundle version %d is newer than the latest supported version %d; upgrade Dolt to read it", header.Version, BundleVersion)
+	}
+
+	return &header, nil
+}
+
+// ExtractBundle reads the bundle from |rd| and writes its chunk store files to |destDir|, returning the bundle's header.
+func ExtractBundle(rd io.Reader, destDir string) (*BundleHeader, error) {
+	tr := tar.NewReader(rd)
+	header, err := readBundleHeader(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("%w: invalid entry '%s'", ErrNotABundle, hdr.Name)
+		}
+
+		err = extractBundleFile(tr, filepath.Join(destDir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return header, nil
+}
+
+func extractBundleFile(rd io.Reader, dest string) error {
+	err := os.MkdirAll(filepath.Dir(dest), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, rd)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// BundleFactory is a DBFactory implementation for reading databases from bundle files. The bundle is extracted to a
+// temporary directory which is then opened as a local database, and removed by CloseAllLocalDatabases.
+type BundleFactory struct {
+}
+
+// bundleDir is the directory a bundle file was extracted to, and the modification time and size of the file when it
+// was extracted.
+type bundleDir struct {
+	dir     string
+	modTime time.Time
+	size    int64
+}
+
+// bundleDirs maps the path of each opened bundle to the directory it was extracted to
+var bundleDirs = make(map[string]bundleDir)
+
+// PrepareDB returns an error, as bundles are read only
+func (fact BundleFactory) PrepareDB(ctx context.Context, nbf *types.NomsBinFormat, u *url.URL, params map[string]interface{}) error {
+	return errors.New("cannot push to a bundle; use dolt bundle create to write one")
+}
+
+// CreateDB extracts the bundle file at the URL given and opens it
+func (fact BundleFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]interface{}) (datas.Database, types.ValueReadWriter, tree.NodeStore, error) {
+	bundlePath, err := url.PathUnescape(urlObj.Path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bundlePath = urlObj.Host + filepath.FromSlash(bundlePath)
+
+	dir, err := extractBundleDir(bundlePath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return FileFactory{}.CreateDB(ctx, nbf, &url.URL{Scheme: FileScheme, Path: filepath.ToSlash(dir)}, params)
+}
+
+// extractBundleDir returns the directory the bundle at |bundlePath| is extracted to. A bundle is extracted again if
+// its file has changed since it was last extracted, and the directory of its previous contents is closed and removed.
+func extractBundleDir(bundlePath string) (string, error) {
+	singletonLock.Lock()
+	defer singletonLock.Unlock()
+
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	if bd, ok := bundleDirs[bundlePath]; ok {
+		if bd.modTime.Equal(info.ModTime()) && bd.size == info.Size() {
+			return bd.dir, nil
+		}
+		if err = removeBundleDir(bd.dir); err != nil {
+			return "", err
+		}
+		delete(bundleDirs, bundlePath)
+	}
+
+	dir, err := os.MkdirTemp("", "dolt-bundle-*")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = ExtractBundle(f, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("error reading bundle '%s': %w", bundlePath, err)
+	}
+
+	bundleDirs[bundlePath] = bundleDir{dir: dir, modTime: info.ModTime(), size: info.Size()}
+	return dir, nil
+}
+
+// removeBundleDir closes the database opened in the extracted bundle directory |dir|, if any, and removes |dir|.
+// singletonLock must be held.
+func removeBundleDir(dir string) error {
+	key := filepath.ToSlash(dir)
+	if s, ok := singletons[key]; ok {
+		delete(singletons, key)
+		if err := s.ddb.Close(); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	storeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(storeDir, "manifest"), []byte("root manifest"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(storeDir, "oldgen"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(storeDir, "oldgen", "abc.darc"), []byte("archive"), 0644))
+
+	header := &BundleHeader{
+		NbfVersion: "__DOLT__",
+		Refs:       []BundleRef{{Name: "refs/heads/main", Hash: "0123"}},
+		Basis:      []string{"4567"},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteBundle(buf, header, storeDir, []string{"manifest", filepath.Join("oldgen", "abc.darc")}))
+
+	destDir := t.TempDir()
+	read, err := ExtractBundle(bytes.NewReader(buf.Bytes()), destDir)
+	require.NoError(t, err)
+	assert.Equal(t, BundleFormat, read.Format)
+	assert.Equal(t, BundleVersion, read.Version)
+	assert.Equal(t, header.Refs, read.Refs)
+	assert.True(t, read.IsIncremental())
+
+	contents, err := os.ReadFile(filepath.Join(destDir, "manifest"))
+	require.NoError(t, err)
+	assert.Equal(t, "root manifest", string(contents))
+	contents, err = os.ReadFile(filepath.Join(destDir, "oldgen", "abc.darc"))
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(contents))
+
+	bundlePath := filepath.Join(t.TempDir(), "test.bundle")
+	require.NoError(t, os.WriteFile(bundlePath, buf.Bytes(), 0644))
+	read, err = ReadBundleHeader(bundlePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"4567"}, read.Basis)
+}
+
+func TestExtractBundleErrors(t *testing.T) {
+	_, err := ExtractBundle(strings.NewReader("not a bundle"), t.TempDir())
+	assert.True(t, errors.Is(err, ErrNotABundle))
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	header := []byte(`{"format":"dolt-bundle","version":99}`)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: bundleHeaderName, Mode: 0644, Size: int64(len(header))}))
+	_, err = tw.Write(header)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	_, err = ExtractBundle(bytes.NewReader(buf.Bytes()), t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "newer than the latest supported version")
+
+	buf = &bytes.Buffer{}
+	tw = tar.NewWriter(buf)
+	header = []byte(`{"format":"dolt-bundle","version":1}`)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: bundleHeaderName, Mode: 0644, Size: int64(len(header))}))
+	_, err = tw.Write(header)
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0644, Size: 0}))
+	require.NoError(t, tw.Close())
+	_, err = ExtractBundle(bytes.NewReader(buf.Bytes()), t.TempDir())
+	assert.True(t, errors.Is(err, ErrNotABundle))
+}
+
+func TestExtractBundleDirChanged(t *testing.T) {
+	writeBundle := func(path, manifest string) {
+		storeDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(storeDir, "manifest"), []byte(manifest), 0644))
+		buf := &bytes.Buffer{}
+		require.NoError(t, WriteBundle(buf, &BundleHeader{NbfVersion: "__DOLT__"}, storeDir, []string{"manifest"}))
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "test.bundle")
+	writeBundle(bundlePath, "first")
+	dir, err := extractBundleDir(bundlePath)
+	require.NoError(t, err)
+	again, err := extractBundleDir(bundlePath)
+	require.NoError(t, err)
+	assert.Equal(t, dir, again)
+
+	// a replaced bundle is extracted again, and the stale extraction is removed
+	writeBundle(bundlePath, "second, which is longer")
+	replaced, err := extractBundleDir(bundlePath)
+	require.NoError(t, err)
+	assert.NotEqual(t, dir, replaced)
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+	contents, err := os.ReadFile(filepath.Join(replaced, "manifest"))
+	require.NoError(t, err)
+	assert.Equal(t, "second, which is longer", string(contents))
+
+	require.NoError(t, CloseAllLocalDatabases())
+}
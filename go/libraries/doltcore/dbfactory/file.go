@@ -78,6 +78,10 @@ func CloseAllLocalDatabases() (err error) {
 			err = fmt.Errorf("error closing DB %s (%s)", name, cerr)
 		}
 	}
+	for bundlePath, bd := range bundleDirs {
+		os.RemoveAll(bd.dir)
+		delete(bundleDirs, bundlePath)
+	}
 	return
 }
 
@@ -179,6 +183,16 @@ func (fact FileFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat,
 	st := nbs.NewGenerationalCS(oldGenSt, newGenSt, ghostGen)
 	// metrics?
 
+	pc, err := ReadPartialClone(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if pc != nil {
+		if err = st.SetLazySource(NewPartialCloneSource(nbf, pc, params)); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
 	vrw := types.NewValueStore(st)
 	ns := tree.NewNodeStore(st)
 	ddb := datas.NewTypesDatabase(vrw, ns)
@@ -0,0 +1,124 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/utils/earl"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// PartialCloneFile is the name of the file in a database's data directory which marks it as a partial clone
+const PartialCloneFile = "partial_clone.json"
+
+// PartialClone records where a partial clone reads the chunks it left out, and which chunks those are. Databases with
+// this file in their data directory open with a lazy chunk source reading from Url.
+type PartialClone struct {
+	Url    string            `json:"url"`
+	Params map[string]string `json:"params,omitempty"`
+	// Filter is the filter given to dolt clone --filter, which also applies to later fetches
+	Filter string `json:"filter"`
+}
+
+// ReadPartialClone returns the partial clone settings of the database whose data is in |nomsDir|, or nil if it is not
+// a partial clone.
+func ReadPartialClone(nomsDir string) (*PartialClone, error) {
+	data, err := os.ReadFile(filepath.Join(nomsDir, PartialCloneFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var pc PartialClone
+	err = json.Unmarshal(data, &pc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pc, nil
+}
+
+// WritePartialClone marks the database whose data is in |nomsDir| as a partial clone
+func WritePartialClone(nomsDir string, pc *PartialClone) error {
+	data, err := json.MarshalIndent(pc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(nomsDir, PartialCloneFile), data, 0644)
+}
+
+// RemovePartialClone marks the database whose data is in |nomsDir| as complete
+func RemovePartialClone(nomsDir string) error {
+	err := os.Remove(filepath.Join(nomsDir, PartialCloneFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// NewPartialCloneSource returns the lazy chunk source of a partial clone. |params| are the params the clone was opened
+// with, which supply the GRPCDialProvider for remotes that need one.
+func NewPartialCloneSource(nbf *types.NomsBinFormat, pc *PartialClone, params map[string]interface{}) *nbs.LazyChunkSource {
+	originParams := make(map[string]interface{})
+	for k, v := range pc.Params {
+		originParams[k] = v
+	}
+	if dp, ok := params[GRPCDialProviderParam]; ok {
+		originParams[GRPCDialProviderParam] = dp
+	}
+
+	return nbs.NewLazyChunkSource(func(ctx context.Context) (chunks.ChunkStore, error) {
+		db, _, _, err := CreateDB(ctx, nbf, pc.Url, originParams)
+		if err != nil {
+			return nil, err
+		}
+		cs := datas.ChunkStoreFromDatabase(db)
+		if isLocalDatabaseUrl(pc.Url) {
+			return sharedChunkStore{cs}, nil
+		}
+		return cs, nil
+	})
+}
+
+// sharedChunkStore is the store of a local origin. Local databases are cached and closed by CloseAllLocalDatabases, so
+// the lazy source mustn't close them itself.
+type sharedChunkStore struct {
+	chunks.ChunkStore
+}
+
+func (sharedChunkStore) Close() error {
+	return nil
+}
+
+func isLocalDatabaseUrl(urlStr string) bool {
+	urlObj, err := earl.Parse(urlStr)
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(urlObj.Scheme)
+	return scheme == FileScheme || scheme == BundleScheme
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartialCloneRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	pc, err := ReadPartialClone(dir)
+	require.NoError(t, err)
+	assert.Nil(t, pc)
+
+	written := &PartialClone{
+		Url:    "https://doltremoteapi.dolthub.com/org/repo",
+		Params: map[string]string{AWSRegionParam: "us-west-2"},
+		Filter: "tables:people",
+	}
+	require.NoError(t, WritePartialClone(dir, written))
+
+	pc, err = ReadPartialClone(dir)
+	require.NoError(t, err)
+	assert.Equal(t, written, pc)
+
+	require.NoError(t, RemovePartialClone(dir))
+	pc, err = ReadPartialClone(dir)
+	require.NoError(t, err)
+	assert.Nil(t, pc)
+
+	// removing a config that doesn't exist is not an error
+	require.NoError(t, RemovePartialClone(dir))
+}
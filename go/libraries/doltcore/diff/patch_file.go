@@ -0,0 +1,163 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// PatchFileFormat identifies a patch file written by dolt patch create
+	PatchFileFormat = "dolt-patch"
+	// PatchFileVersion is the version of the patch file format written by this version of Dolt
+	PatchFileVersion = 1
+)
+
+// Row patch operations
+const (
+	PatchOpInsert = "insert"
+	PatchOpUpdate = "update"
+	PatchOpDelete = "delete"
+)
+
+var ErrNotAPatchFile = errors.New("not a dolt patch file")
+
+// PatchFile is a portable description of the changes between two commits. It records the schema statements and row
+// changes needed to get from the base commit to the target commit, along with the old values of every changed row so
+// that the patch can verify it applies cleanly to another database.
+type PatchFile struct {
+	Format       string       `json:"format"`
+	Version      int          `json:"version"`
+	BaseCommit   string       `json:"base_commit"`
+	TargetCommit string       `json:"target_commit"`
+	Tables       []TablePatch `json:"tables"`
+}
+
+// TablePatch is the set of changes to a single table. FromName is empty for tables created by the patch and ToName is
+// empty for tables dropped by it.
+type TablePatch struct {
+	FromName string `json:"from_name,omitempty"`
+	ToName   string `json:"to_name,omitempty"`
+	// FromCreateStmt is the CREATE TABLE statement of the table at the base commit
+	FromCreateStmt string `json:"from_create_statement,omitempty"`
+	// SchemaStmts are the statements that migrate the table's schema from the base to the target commit
+	SchemaStmts []string   `json:"schema_statements,omitempty"`
+	Rows        []RowPatch `json:"rows,omitempty"`
+}
+
+// RowPatch is a single row change. Column values are the string form of the SQL value, with nil meaning NULL. Old is
+// only set for updates and deletes, New only for inserts and updates.
+type RowPatch struct {
+	Op  string             `json:"op"`
+	Old map[string]*string `json:"old,omitempty"`
+	New map[string]*string `json:"new,omitempty"`
+}
+
+// NewPatchFile returns an empty PatchFile for the changes between |baseCommit| and |targetCommit|.
+func NewPatchFile(baseCommit, targetCommit string) *PatchFile {
+	return &PatchFile{
+		Format:       PatchFileFormat,
+		Version:      PatchFileVersion,
+		BaseCommit:   baseCommit,
+		TargetCommit: targetCommit,
+	}
+}
+
+// TableName returns the name of the table the patch applies to before any rename
+func (tp TablePatch) TableName() string {
+	if tp.FromName != "" {
+		return tp.FromName
+	}
+	return tp.ToName
+}
+
+// IsAdd returns whether the patch creates this table
+func (tp TablePatch) IsAdd() bool {
+	return tp.FromName == ""
+}
+
+// IsDrop returns whether the patch drops this table
+func (tp TablePatch) IsDrop() bool {
+	return tp.ToName == ""
+}
+
+// RowCount returns the number of row changes in the patch
+func (p *PatchFile) RowCount() int {
+	n := 0
+	for _, t := range p.Tables {
+		n += len(t.Rows)
+	}
+	return n
+}
+
+// Write writes the patch to |wr| as JSON.
+func (p *PatchFile) Write(wr io.Writer) error {
+	enc := json.NewEncoder(wr)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// ReadPatchFile reads and validates a patch written by PatchFile.Write.
+func ReadPatchFile(rd io.Reader) (*PatchFile, error) {
+	var p PatchFile
+	err := json.NewDecoder(rd).Decode(&p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotAPatchFile, err.Error())
+	}
+
+	if p.Format != PatchFileFormat {
+		return nil, ErrNotAPatchFile
+	}
+	if p.Version > PatchFileVersion {
+		return nil, fmt.Errorf("patch file version %d is newer than the latest supported version %d; upgrade Dolt to apply it", p.Version, PatchFileVersion)
+	}
+
+	for _, t := range p.Tables {
+		if t.FromName == "" && t.ToName == "" {
+			return nil, errors.New("invalid patch file: table entry without a name")
+		}
+		for _, r := range t.Rows {
+			if err = r.validate(); err != nil {
+				return nil, fmt.Errorf("invalid patch file: table %s: %w", t.TableName(), err)
+			}
+		}
+	}
+
+	return &p, nil
+}
+
+func (r RowPatch) validate() error {
+	switch r.Op {
+	case PatchOpInsert:
+		if r.New == nil || r.Old != nil {
+			return errors.New("insert must have only new values")
+		}
+	case PatchOpUpdate:
+		if r.New == nil || r.Old == nil {
+			return errors.New("update must have old and new values")
+		}
+	case PatchOpDelete:
+		if r.Old == nil || r.New != nil {
+			return errors.New("delete must have only old values")
+		}
+	default:
+		return fmt.Errorf("unknown row operation '%s'", r.Op)
+	}
+	return nil
+}
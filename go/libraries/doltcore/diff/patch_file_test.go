@@ -0,0 +1,90 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestPatchFileRoundTrip(t *testing.T) {
+	p := NewPatchFile("base", "target")
+	p.Tables = []TablePatch{
+		{
+			FromName:       "t",
+			ToName:         "t",
+			FromCreateStmt: "CREATE TABLE `t` (`pk` int NOT NULL, `c` varchar(10), PRIMARY KEY (`pk`));",
+			SchemaStmts:    []string{"ALTER TABLE `t` ADD `d` int;"},
+			Rows: []RowPatch{
+				{Op: PatchOpInsert, New: map[string]*string{"pk": strPtr("1"), "c": nil}},
+				{Op: PatchOpUpdate, Old: map[string]*string{"pk": strPtr("2"), "c": strPtr("<a>")}, New: map[string]*string{"pk": strPtr("2"), "c": strPtr("b")}},
+				{Op: PatchOpDelete, Old: map[string]*string{"pk": strPtr("3"), "c": strPtr("c")}},
+			},
+		},
+		{ToName: "added", SchemaStmts: []string{"CREATE TABLE `added` (`pk` int PRIMARY KEY);"}},
+		{FromName: "dropped", SchemaStmts: []string{"DROP TABLE `dropped`;"}},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, p.Write(buf))
+	assert.Contains(t, buf.String(), `"<a>"`)
+
+	read, err := ReadPatchFile(buf)
+	require.NoError(t, err)
+	assert.Equal(t, p, read)
+	assert.Equal(t, 3, read.RowCount())
+
+	assert.False(t, read.Tables[0].IsAdd())
+	assert.True(t, read.Tables[1].IsAdd())
+	assert.Equal(t, "added", read.Tables[1].TableName())
+	assert.True(t, read.Tables[2].IsDrop())
+	assert.Nil(t, read.Tables[0].Rows[0].New["c"])
+}
+
+func TestReadPatchFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		errStr   string
+	}{
+		{"not json", "INSERT INTO t VALUES (1);", "not a dolt patch file"},
+		{"wrong format", `{"format":"something","version":1}`, "not a dolt patch file"},
+		{"newer version", `{"format":"dolt-patch","version":99}`, "newer than the latest supported version"},
+		{"unnamed table", `{"format":"dolt-patch","version":1,"tables":[{}]}`, "without a name"},
+		{"unknown op", `{"format":"dolt-patch","version":1,"tables":[{"to_name":"t","rows":[{"op":"merge"}]}]}`, "unknown row operation"},
+		{"insert with old", `{"format":"dolt-patch","version":1,"tables":[{"to_name":"t","rows":[{"op":"insert","old":{"a":"1"},"new":{"a":"1"}}]}]}`, "only new values"},
+		{"update without old", `{"format":"dolt-patch","version":1,"tables":[{"to_name":"t","rows":[{"op":"update","new":{"a":"1"}}]}]}`, "old and new values"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadPatchFile(strings.NewReader(test.contents))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errStr)
+		})
+	}
+
+	_, err := ReadPatchFile(strings.NewReader("{"))
+	assert.True(t, errors.Is(err, ErrNotAPatchFile))
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -1241,6 +1242,7 @@ func (ddb *DoltDB) NewBranchAtCommit(ctx context.Context, branchRef ref.DoltRef,
 	if err != nil {
 		return err
 	}
+	isNewBranch := !ds.HasHead()
 
 	addr, err := commit.HashOf()
 	if err != nil {
@@ -1252,6 +1254,10 @@ func (ddb *DoltDB) NewBranchAtCommit(ctx context.Context, branchRef ref.DoltRef,
 		return err
 	}
 
+	if isNewBranch {
+		ddb.notifyBranchListeners(ctx, branchRef, DatabaseUpdateListener.BranchCreated)
+	}
+
 	// Update the corresponding working set at the same time, either by updating it or creating a new one
 	// TODO: find all the places HEAD can change, update working set too. This is only necessary when we don't already
 	//  update the working set when the head changes.
@@ -1342,7 +1348,30 @@ func (ddb *DoltDB) deleteRef(ctx context.Context, dref ref.DoltRef, replicationS
 	}
 
 	_, err = ddb.db.withReplicationStatusController(replicationStatus).Delete(ctx, ds, wsPath)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if dref.GetType() == ref.BranchRefType {
+		ddb.notifyBranchListeners(ctx, dref, DatabaseUpdateListener.BranchDeleted)
+	}
+	return nil
+}
+
+// notifyBranchListeners calls |notify| on each registered DatabaseUpdateListener for the branch |branchRef|.
+// Errors from listeners are logged, but not returned.
+func (ddb *DoltDB) notifyBranchListeners(ctx context.Context, branchRef ref.DoltRef, notify func(DatabaseUpdateListener, *sql.Context, string, string) error) {
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		return
+	}
+
+	for _, listener := range DatabaseUpdateListeners {
+		err := notify(listener, sqlCtx, ddb.databaseName, branchRef.GetPath())
+		if err != nil {
+			logrus.Errorf("error notifying database update listener of branch update: %s", err.Error())
+		}
+	}
 }
 
 // DeleteAllRefs Very destructive, use with caution. Not only does this drop all data, Dolt assume there is always
@@ -1429,6 +1458,14 @@ type DatabaseUpdateListener interface {
 
 	// DatabaseDropped is called with the database named |databaseName| has been dropped.
 	DatabaseDropped(ctx *sql.Context, databaseName string) error
+
+	// BranchCreated is called when a new branch, named |branchName|, has been created in the database named
+	// |databaseName|. It is called before WorkingRootUpdated is called for the new branch's working root.
+	BranchCreated(ctx *sql.Context, databaseName string, branchName string) error
+
+	// BranchDeleted is called when the branch named |branchName| has been deleted from the database named
+	// |databaseName|.
+	BranchDeleted(ctx *sql.Context, databaseName string, branchName string) error
 }
 
 var DatabaseUpdateListeners = make([]DatabaseUpdateListener, 0)
@@ -1635,6 +1672,9 @@ func (ddb *DoltDB) GC(ctx context.Context, safepointF func() error) error {
 	if !ok {
 		return fmt.Errorf("this database does not support garbage collection")
 	}
+	if ddb.IsPartialClone() {
+		return fmt.Errorf("cannot garbage collect a partial clone; run dolt fetch --backfill first")
+	}
 
 	err := ddb.pruneUnreferencedDatasets(ctx)
 	if err != nil {
@@ -1726,7 +1766,13 @@ func (ddb *DoltDB) PullChunks(
 	statsCh chan pull.Stats,
 	skipHashes hash.HashSet,
 ) error {
-	return pullHash(ctx, ddb.db, srcDB.db, targetHashes, tempDir, statsCh, skipHashes)
+	// a partial clone only pulls the data its filter selects
+	filter, err := ddb.PartialCloneFilter()
+	if err != nil {
+		return err
+	}
+
+	return pullHash(ctx, ddb.db, srcDB.db, targetHashes, tempDir, statsCh, skipHashes, filter)
 }
 
 func pullHash(
@@ -1736,10 +1782,18 @@ func pullHash(
 	tempDir string,
 	statsCh chan pull.Stats,
 	skipHashes hash.HashSet,
+	filter *PartialCloneFilter,
 ) error {
 	srcCS := datas.ChunkStoreFromDatabase(srcDB)
 	destCS := datas.ChunkStoreFromDatabase(destDB)
-	waf := types.WalkAddrsForNBF(srcDB.Format(), skipHashes)
+	var waf pull.WalkAddrs = types.WalkAddrsForNBF(srcDB.Format(), skipHashes)
+	if filter != nil {
+		w, err := filter.newWalk(waf, skipHashes)
+		if err != nil {
+			return err
+		}
+		waf = w.walk
+	}
 
 	if datas.CanUsePuller(srcDB) && datas.CanUsePuller(destDB) {
 		puller, err := pull.NewPuller(ctx, tempDir, defaultChunksPerTF, srcCS, destCS, waf, targetHashes, statsCh)
@@ -1755,6 +1809,148 @@ func pullHash(
 	}
 }
 
+// PullChunksExcluding pulls the chunks reachable from |targetHashes| in |srcDB| into this database, like PullChunks,
+// except for the chunks |srcDB| reaches from |basisHashes|. The targets themselves are always pulled.
+func (ddb *DoltDB) PullChunksExcluding(
+	ctx context.Context,
+	tempDir string,
+	srcDB *DoltDB,
+	targetHashes, basisHashes []hash.Hash,
+	statsCh chan pull.Stats,
+) error {
+	if !datas.CanUsePuller(srcDB.db) || !datas.CanUsePuller(ddb.db) {
+		return errors.New("Puller not supported")
+	}
+	destCS, ok := datas.ChunkStoreFromDatabase(ddb.db).(tableFileChunkStore)
+	if !ok {
+		return errors.New("Puller not supported")
+	}
+
+	srcCS := datas.ChunkStoreFromDatabase(srcDB.db)
+	waf := types.WalkAddrsForNBF(srcDB.Format(), nil)
+	exclude, err := basisChunks(ctx, srcCS, waf, targetHashes, basisHashes)
+	if err != nil {
+		return err
+	}
+	sink := excludingChunkStore{
+		tableFileChunkStore: destCS,
+		exclude:             exclude,
+		keep:                hash.NewHashSet(targetHashes...),
+	}
+
+	puller, err := pull.NewPuller(ctx, tempDir, defaultChunksPerTF, srcCS, sink, waf, targetHashes, statsCh)
+	if err == pull.ErrDBUpToDate {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return puller.Pull(ctx)
+}
+
+// basisChunks walks the chunks |cs| reaches from |targets| and from |basis| side by side, a level at a time, and
+// returns the chunks it reached from |basis|. It stops once the walk from |targets| does, and neither walk goes past a
+// chunk the other has reached, so it only reads the part of the basis the targets can share with it. A chunk the
+// targets share with a deeper part of the basis, as when a change is reverted, is not found, and is pulled again.
+func basisChunks(ctx context.Context, cs chunks.ChunkStore, waf pull.WalkAddrs, targets, basis []hash.Hash) (hash.HashSet, error) {
+	fromBasis := hash.NewHashSet(basis...)
+	fromTargets := hash.NewHashSet()
+	for _, h := range targets {
+		if !fromBasis.Has(h) {
+			fromTargets.Insert(h)
+		}
+	}
+
+	basisBatch, targetBatch := fromBasis.Copy(), fromTargets.Copy()
+	for targetBatch.Size() > 0 {
+		// The basis goes first, so that the walk from the targets stops at the chunks both reach at the same depth
+		var err error
+		basisBatch, err = walkChunkLevel(ctx, cs, waf, basisBatch, func(h hash.Hash) bool {
+			if fromBasis.Has(h) || fromTargets.Has(h) {
+				return false
+			}
+			fromBasis.Insert(h)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		targetBatch, err = walkChunkLevel(ctx, cs, waf, targetBatch, func(h hash.Hash) bool {
+			if fromBasis.Has(h) || fromTargets.Has(h) {
+				return false
+			}
+			fromTargets.Insert(h)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fromBasis, nil
+}
+
+// walkChunkLevel reads the chunks of |batch| from |cs| and returns the addresses they reference for which |visit|
+// returns true.
+func walkChunkLevel(ctx context.Context, cs chunks.ChunkStore, waf pull.WalkAddrs, batch hash.HashSet, visit func(hash.Hash) bool) (hash.HashSet, error) {
+	var mu sync.Mutex
+	var walkErr error
+	next := hash.NewHashSet()
+	err := cs.GetMany(ctx, batch, func(ctx context.Context, c *chunks.Chunk) {
+		mu.Lock()
+		defer mu.Unlock()
+		if walkErr != nil || c.IsGhost() {
+			return
+		}
+
+		walkErr = waf(*c, func(h hash.Hash, _ bool) error {
+			if visit(h) {
+				next.Insert(h)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, walkErr
+}
+
+type tableFileChunkStore interface {
+	chunks.ChunkStore
+	chunks.TableFileStore
+}
+
+// excludingChunkStore is the sink of a pull which reports the chunks in |exclude| as present, other than those in
+// |keep|. The puller neither pulls those chunks, nor walks the chunks they reach.
+type excludingChunkStore struct {
+	tableFileChunkStore
+	exclude hash.HashSet
+	keep    hash.HashSet
+}
+
+func (s excludingChunkStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
+	absent, err := s.HasMany(ctx, hash.NewHashSet(h))
+	if err != nil {
+		return false, err
+	}
+	return absent.Size() == 0, nil
+}
+
+func (s excludingChunkStore) HasMany(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	absent, err := s.tableFileChunkStore.HasMany(ctx, hashes)
+	if err != nil || absent.Size() == 0 {
+		return absent, err
+	}
+	ret := hash.NewHashSet()
+	for h := range absent {
+		if !s.exclude.Has(h) || s.keep.Has(h) {
+			ret.Insert(h)
+		}
+	}
+	return ret, nil
+}
+
 func (ddb *DoltDB) Clone(ctx context.Context, destDB *DoltDB, eventCh chan<- pull.TableFileEvent) error {
 	return pull.Clone(ctx, datas.ChunkStoreFromDatabase(ddb.db), datas.ChunkStoreFromDatabase(destDB.db), eventCh)
 }
@@ -1948,6 +2144,62 @@ func (ddb *DoltDB) GetStatistics(ctx context.Context, branch string) (prolly.Map
 
 }
 
+// SetInternalRoot records |root| under the internal ref |name|, as the root value of a commit with the metadata |meta|
+// which has no parents, replacing the previous commit of the ref. Internal refs are not versioned, and are neither
+// pushed nor fetched, but they keep the values they reach from being garbage collected.
+func (ddb *DoltDB) SetInternalRoot(ctx context.Context, name string, root RootValue, meta *datas.CommitMeta) error {
+	_, h, err := ddb.WriteRootValue(ctx, root)
+	if err != nil {
+		return err
+	}
+	cm, err := ddb.CommitDanglingWithParentCommits(ctx, h, nil, meta)
+	if err != nil {
+		return err
+	}
+	return ddb.SetHeadToCommit(ctx, ref.NewInternalRef(name), cm)
+}
+
+// GetInternalRoot returns the root value recorded under the internal ref |name| by SetInternalRoot, and the metadata
+// of its commit. It returns false if the ref doesn't exist.
+func (ddb *DoltDB) GetInternalRoot(ctx context.Context, name string) (RootValue, *datas.CommitMeta, bool, error) {
+	cm, err := ddb.ResolveCommitRef(ctx, ref.NewInternalRef(name))
+	if errors.Is(err, ErrBranchNotFound) {
+		return nil, nil, false, nil
+	} else if err != nil {
+		return nil, nil, false, err
+	}
+	meta, err := cm.GetCommitMeta(ctx)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	root, err := cm.GetRootValue(ctx)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return root, meta, true, nil
+}
+
+// GetInternalRefNames returns the names of the internal refs which start with |prefix|.
+func (ddb *DoltDB) GetInternalRefNames(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	err := ddb.VisitRefsOfType(ctx, map[ref.RefType]struct{}{ref.InternalRefType: {}}, func(r ref.DoltRef, _ hash.Hash) error {
+		if strings.HasPrefix(r.GetPath(), prefix) {
+			names = append(names, r.GetPath())
+		}
+		return nil
+	})
+	return names, err
+}
+
+// DeleteInternalRef deletes the internal ref |name|, if it exists.
+func (ddb *DoltDB) DeleteInternalRef(ctx context.Context, name string) error {
+	err := ddb.deleteRef(ctx, ref.NewInternalRef(name), nil, "")
+	if err == ErrBranchNotFound {
+		return nil
+	}
+	return err
+}
+
 // RemoveStashAtIdx takes and index of a stash to remove from the stash list map.
 // It removes a Stash message from stash list Dataset, which cannot be performed
 // by database Delete function. This function removes a single stash only and stash
@@ -0,0 +1,373 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dolthub/dolt/go/gen/fb/serial"
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/datas/pull"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	blobNoneFilter     = "blob:none"
+	tablesFilterPrefix = "tables:"
+)
+
+var ErrNotPartialClone = errors.New("database is not a partial clone")
+var ErrPartialCloneUnsupported = errors.New("partial clones are only supported for local databases")
+
+// PartialCloneFilter selects the chunks that a partial clone leaves out of its local store. Chunks that are left out
+// are read from the clone's origin when they are first needed.
+type PartialCloneFilter struct {
+	// Tables are the patterns of the tables whose data is cloned, in the same syntax as dolt_ignore. Other tables are
+	// cloned with their schemas only. Dolt system tables are always cloned. Empty if tables aren't filtered.
+	Tables []string
+	// NoBlobs leaves out the values stored outside of their rows, such as large TEXT, BLOB and JSON values.
+	NoBlobs bool
+}
+
+// ParsePartialCloneFilter parses the argument to dolt clone --filter, which is either blob:none or
+// tables:<pattern>[,<pattern>...]
+func ParsePartialCloneFilter(s string) (PartialCloneFilter, error) {
+	if s == blobNoneFilter {
+		return PartialCloneFilter{NoBlobs: true}, nil
+	}
+
+	if !strings.HasPrefix(s, tablesFilterPrefix) {
+		return PartialCloneFilter{}, fmt.Errorf("invalid filter '%s'; expected %s or %s<pattern>[,<pattern>...]", s, blobNoneFilter, tablesFilterPrefix)
+	}
+
+	var filter PartialCloneFilter
+	for _, pattern := range strings.Split(strings.TrimPrefix(s, tablesFilterPrefix), ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return PartialCloneFilter{}, fmt.Errorf("invalid filter '%s'; empty table pattern", s)
+		}
+		filter.Tables = append(filter.Tables, pattern)
+	}
+
+	return filter, nil
+}
+
+// String returns the filter in the syntax accepted by ParsePartialCloneFilter
+func (f PartialCloneFilter) String() string {
+	if f.NoBlobs {
+		return blobNoneFilter
+	}
+	return tablesFilterPrefix + strings.Join(f.Tables, ",")
+}
+
+// newWalk returns the walk used to find the chunks to pull into a partial clone with this filter. It walks chunks
+// the same way as |waf|, except that it doesn't descend into the data left out by the filter.
+func (f PartialCloneFilter) newWalk(waf pull.WalkAddrs, skip hash.HashSet) (*partialCloneWalk, error) {
+	w := &partialCloneWalk{
+		waf:           waf,
+		skip:          skip,
+		noBlobs:       f.NoBlobs,
+		tableMapNodes: hash.NewHashSet(),
+		schemaOnly:    hash.NewHashSet(),
+	}
+
+	for _, pattern := range f.Tables {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		w.tables = append(w.tables, re)
+	}
+
+	return w, nil
+}
+
+// partialCloneWalk tracks which chunks it has seen belong to the table maps of root values, so that it can tell
+// which tables are left out by the filter when it reaches them.
+type partialCloneWalk struct {
+	waf     pull.WalkAddrs
+	skip    hash.HashSet
+	tables  []*regexp.Regexp
+	noBlobs bool
+
+	mu            sync.Mutex
+	tableMapNodes hash.HashSet
+	schemaOnly    hash.HashSet
+}
+
+func (w *partialCloneWalk) walk(c chunks.Chunk, cb func(h hash.Hash, isleaf bool) error) error {
+	emit := func(h hash.Hash) error {
+		if h.IsEmpty() || (w.skip != nil && w.skip.Has(h)) {
+			return nil
+		}
+		return cb(h, false)
+	}
+
+	data := c.Data()
+	switch serial.GetFileID(data) {
+	case serial.RootValueFileID:
+		if len(w.tables) == 0 {
+			break
+		}
+
+		var msg serial.RootValue
+		err := serial.InitRootValueRoot(&msg, data, serial.MessagePrefixSz)
+		if err != nil {
+			return err
+		}
+		err = w.walkTableMap(msg.TablesBytes(), emit)
+		if err != nil {
+			return err
+		}
+		return emit(hash.New(msg.ForeignKeyAddrBytes()))
+
+	case serial.AddressMapFileID:
+		if w.isTableMapNode(c.Hash()) {
+			return w.walkTableMap(data, emit)
+		}
+
+	case serial.TableFileID:
+		if w.isSchemaOnly(c.Hash()) {
+			var msg serial.Table
+			err := serial.InitTableRoot(&msg, data, serial.MessagePrefixSz)
+			if err != nil {
+				return err
+			}
+			return emit(hash.New(msg.SchemaBytes()))
+		}
+
+	case serial.ProllyTreeNodeFileID:
+		// the only addresses in the leaves of row data are those of values stored outside their rows
+		if w.noBlobs {
+			var msg serial.ProllyTreeNode
+			err := serial.InitProllyTreeNodeRoot(&msg, data, serial.MessagePrefixSz)
+			if err != nil {
+				return err
+			}
+			if msg.TreeLevel() == 0 {
+				return nil
+			}
+		}
+	}
+
+	return w.waf(c, cb)
+}
+
+// walkTableMap emits the addresses in a node of a root value's table map, noting which of them are further table
+// map nodes and which are tables left out by the filter
+func (w *partialCloneWalk) walkTableMap(msg []byte, emit func(hash.Hash) error) error {
+	if len(msg) == 0 {
+		return nil
+	}
+
+	nd, err := tree.NodeFromBytes(msg)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < nd.Count(); i++ {
+		addr := hash.New(nd.GetValue(i))
+		if nd.IsLeaf() {
+			if !w.keepTable(string(nd.GetKey(i))) {
+				w.mu.Lock()
+				w.schemaOnly.Insert(addr)
+				w.mu.Unlock()
+			}
+		} else {
+			w.mu.Lock()
+			w.tableMapNodes.Insert(addr)
+			w.mu.Unlock()
+		}
+
+		err = emit(addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keepTable returns whether the data of the table with the encoded name given is cloned
+func (w *partialCloneWalk) keepTable(encodedName string) bool {
+	// schema-qualified names are encoded as \0schema\0name
+	name := encodedName
+	if i := strings.LastIndexByte(name, 0); i >= 0 {
+		name = name[i+1:]
+	}
+
+	if HasDoltPrefix(name) {
+		return true
+	}
+	for _, re := range w.tables {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *partialCloneWalk) isTableMapNode(h hash.Hash) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tableMapNodes.Has(h)
+}
+
+func (w *partialCloneWalk) isSchemaOnly(h hash.Hash) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.schemaOnly.Has(h)
+}
+
+// generationalStore returns the chunk store of this database if it is a local, generational store
+func (ddb *DoltDB) generationalStore() (*nbs.GenerationalNBS, bool) {
+	gcs, ok := datas.ChunkStoreFromDatabase(ddb.db).(*nbs.GenerationalNBS)
+	return gcs, ok
+}
+
+// IsPartialClone returns whether this database was cloned with a filter, and may be missing data that it reads from
+// its origin on demand.
+func (ddb *DoltDB) IsPartialClone() bool {
+	gcs, ok := ddb.generationalStore()
+	return ok && gcs.HasLazySource()
+}
+
+// PartialCloneFilter returns the filter this database was cloned with, or nil if it is not a partial clone
+func (ddb *DoltDB) PartialCloneFilter() (*PartialCloneFilter, error) {
+	if !ddb.IsPartialClone() {
+		return nil, nil
+	}
+
+	pc, err := ddb.readPartialClone()
+	if err != nil || pc == nil {
+		return nil, err
+	}
+
+	filter, err := ParsePartialCloneFilter(pc.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+func (ddb *DoltDB) readPartialClone() (*dbfactory.PartialClone, error) {
+	gcs, ok := ddb.generationalStore()
+	if !ok {
+		return nil, nil
+	}
+	path, ok := gcs.Path()
+	if !ok {
+		return nil, nil
+	}
+	return dbfactory.ReadPartialClone(path)
+}
+
+// InitPartialClone makes this database a partial clone of the database at |originUrl|, which it reads the data left
+// out by |filter| from. |originParams| are the remote's params, and |params| supply the GRPCDialProvider to reach it.
+func (ddb *DoltDB) InitPartialClone(ctx context.Context, originUrl string, originParams map[string]string, filter PartialCloneFilter, params map[string]interface{}) error {
+	gcs, ok := ddb.generationalStore()
+	if !ok {
+		return ErrPartialCloneUnsupported
+	}
+	path, ok := gcs.Path()
+	if !ok {
+		return ErrPartialCloneUnsupported
+	}
+
+	pc := &dbfactory.PartialClone{Url: originUrl, Params: originParams, Filter: filter.String()}
+	err := dbfactory.WritePartialClone(path, pc)
+	if err != nil {
+		return err
+	}
+
+	return gcs.SetLazySource(dbfactory.NewPartialCloneSource(ddb.Format(), pc, params))
+}
+
+// CompletePartialClone fetches every chunk this partial clone left out from its origin, after which it is an
+// ordinary, complete database.
+func (ddb *DoltDB) CompletePartialClone(ctx context.Context) error {
+	gcs, ok := ddb.generationalStore()
+	if !ok || !gcs.HasLazySource() {
+		return ErrNotPartialClone
+	}
+
+	root, err := gcs.Root(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Reading a chunk this store doesn't have fetches it, so reading everything reachable from the root fills it in
+	walkAddrs := types.WalkAddrsForNBF(ddb.Format(), nil)
+	seen := hash.NewHashSet(root)
+	batch := hash.NewHashSet(root)
+	for batch.Size() > 0 {
+		var mu sync.Mutex
+		var walkErr error
+		next := hash.NewHashSet()
+
+		err = gcs.GetMany(ctx, batch, func(ctx context.Context, c *chunks.Chunk) {
+			mu.Lock()
+			defer mu.Unlock()
+			if walkErr != nil || c.IsGhost() {
+				return
+			}
+
+			walkErr = walkAddrs(*c, func(h hash.Hash, _ bool) error {
+				if !seen.Has(h) {
+					seen.Insert(h)
+					next.Insert(h)
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if walkErr != nil {
+			return walkErr
+		}
+
+		batch = next
+	}
+
+	// persist what we fetched before the store forgets its origin
+	ok, err = gcs.Commit(ctx, root, root)
+	if err != nil {
+		return err
+	} else if !ok {
+		return errors.New("the database was updated while its data was being fetched; try again")
+	}
+
+	path, ok := gcs.Path()
+	if !ok {
+		return ErrPartialCloneUnsupported
+	}
+	err = dbfactory.RemovePartialClone(path)
+	if err != nil {
+		return err
+	}
+
+	return gcs.SetLazySource(nil)
+}
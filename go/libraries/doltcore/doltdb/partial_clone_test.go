@@ -0,0 +1,69 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePartialCloneFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected PartialCloneFilter
+		err      bool
+	}{
+		{filter: "blob:none", expected: PartialCloneFilter{NoBlobs: true}},
+		{filter: "tables:people", expected: PartialCloneFilter{Tables: []string{"people"}}},
+		{filter: "tables:people, orders_*", expected: PartialCloneFilter{Tables: []string{"people", "orders_*"}}},
+		{filter: "tables:", err: true},
+		{filter: "tables:people,,orders", err: true},
+		{filter: "blob:limit=1k", err: true},
+		{filter: "", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			filter, err := ParsePartialCloneFilter(test.filter)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, filter)
+
+			roundTripped, err := ParsePartialCloneFilter(filter.String())
+			require.NoError(t, err)
+			assert.Equal(t, filter, roundTripped)
+		})
+	}
+}
+
+func TestPartialCloneWalkKeepTable(t *testing.T) {
+	filter, err := ParsePartialCloneFilter("tables:people,orders_*")
+	require.NoError(t, err)
+	w, err := filter.newWalk(nil, nil)
+	require.NoError(t, err)
+
+	assert.True(t, w.keepTable("people"))
+	assert.True(t, w.keepTable("orders_2024"))
+	assert.True(t, w.keepTable("\000public\000people"))
+	assert.True(t, w.keepTable("dolt_schemas"))
+	assert.False(t, w.keepTable("peoples"))
+	assert.False(t, w.keepTable("events"))
+	assert.False(t, w.keepTable("\000public\000events"))
+}
@@ -256,6 +256,9 @@ const (
 	DoltBlameViewPrefix = "dolt_blame_"
 	// DoltHistoryTablePrefix is the prefix assigned to all the generated history tables
 	DoltHistoryTablePrefix = "dolt_history_"
+	// DoltSystemTimeTablePrefix is the prefix assigned to all the generated system time tables, which back the
+	// FOR SYSTEM_TIME clauses of queries
+	DoltSystemTimeTablePrefix = "dolt_system_time_"
 	// DoltDiffTablePrefix is the prefix assigned to all the generated diff tables
 	DoltDiffTablePrefix = "dolt_diff_"
 	// DoltCommitDiffTablePrefix is the prefix assigned to all the generated commit diff tables
@@ -316,6 +319,18 @@ const (
 
 	// StatisticsTableName is the statistics system table name
 	StatisticsTableName = "dolt_statistics"
+
+	// BinlogReplicasTableName is the binlog replicas system table name
+	BinlogReplicasTableName = "dolt_binlog_replicas"
+
+	// SlowQueriesTableName is the slow queries system table name
+	SlowQueriesTableName = "dolt_slow_queries"
+
+	// TTLStatusTableName is the TTL status system table name
+	TTLStatusTableName = "dolt_ttl_status"
+
+	// ResourceLimitsTableName is the resource limits system table name
+	ResourceLimitsTableName = "dolt_resource_limits"
 )
 
 const (
@@ -0,0 +1,186 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/utils/earl"
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/datas/pull"
+	"github.com/dolthub/dolt/go/store/hash"
+	"github.com/dolthub/dolt/go/store/nbs"
+)
+
+var ErrBundleMissingBasis = errors.New("the database lacks the commits this bundle was built on")
+
+// CreateBundle writes a bundle of the refs given to |wr|. The bundle contains every chunk reachable from the refs
+// except for those also reachable from the |basis| commits, which a database must already have to fetch from it.
+// Chunks are copied with the same puller used for pushes into a temporary store under |tempDir|, whose table files
+// are converted into archives and written out as the bundle's contents. The chunks of the basis are only read from
+// |srcDB|, as far as the refs could share them, and are never copied.
+func CreateBundle(ctx context.Context, srcDB *doltdb.DoltDB, tempDir string, wr io.Writer, refs []doltdb.RefWithHash, basis []hash.Hash, statsCh chan pull.Stats) error {
+	if len(refs) == 0 {
+		return errors.New("a bundle must contain at least one ref")
+	}
+
+	storeDir, err := os.MkdirTemp(tempDir, "bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(storeDir)
+
+	bundleDB, err := loadBundleStore(ctx, srcDB, storeDir)
+	if err != nil {
+		return err
+	}
+	defer dbfactory.DeleteFromSingletonCache(filepath.ToSlash(storeDir))
+
+	err = fillBundleDB(ctx, srcDB, bundleDB, tempDir, refs, basis, statsCh)
+	if err == nil {
+		err = nbs.ArchiveNewGen(ctx, datas.ChunkStoreFromDatabase(doltdb.HackDatasDatabaseFromDoltDB(bundleDB)), nil)
+	}
+	if cerr := bundleDB.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	header := &dbfactory.BundleHeader{NbfVersion: srcDB.Format().VersionString()}
+	for _, r := range refs {
+		header.Refs = append(header.Refs, dbfactory.BundleRef{Name: r.Ref.String(), Hash: r.Hash.String()})
+	}
+	for _, h := range basis {
+		header.Basis = append(header.Basis, h.String())
+	}
+
+	files, err := bundleStoreFiles(storeDir)
+	if err != nil {
+		return err
+	}
+
+	return dbfactory.WriteBundle(wr, header, storeDir, files)
+}
+
+// loadBundleStore returns a new database, of the format of |srcDB|, in the empty directory |dir|.
+func loadBundleStore(ctx context.Context, srcDB *doltdb.DoltDB, dir string) (*doltdb.DoltDB, error) {
+	storeUrl := earl.FileUrlFromPath(filepath.ToSlash(dir), os.PathSeparator)
+	return doltdb.LoadDoltDB(ctx, srcDB.Format(), storeUrl, filesys.LocalFS)
+}
+
+// fillBundleDB pulls the chunks of |refs| from |srcDB| into |bundleDB|, other than the chunks reachable from the
+// |basis| commits, and sets the refs. |basis| is empty for a complete bundle.
+func fillBundleDB(ctx context.Context, srcDB, bundleDB *doltdb.DoltDB, tempDir string, refs []doltdb.RefWithHash, basis []hash.Hash, statsCh chan pull.Stats) error {
+	targets := make([]hash.Hash, len(refs))
+	for i, r := range refs {
+		targets[i] = r.Hash
+	}
+
+	var err error
+	if len(basis) > 0 {
+		err = bundleDB.PullChunksExcluding(ctx, tempDir, srcDB, targets, basis, statsCh)
+	} else {
+		err = bundleDB.PullChunks(ctx, tempDir, srcDB, targets, statsCh, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, r := range refs {
+		err = bundleDB.SetHead(ctx, r.Ref, r.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", r.Ref.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// bundleStoreFiles returns the paths of the files in the chunk store at |storeDir|, relative to it, skipping lock files
+func bundleStoreFiles(storeDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(storeDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() == "LOCK" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(storeDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
+// IsBundleUrl returns whether |remoteUrl| is the url of a bundle file.
+func IsBundleUrl(remoteUrl string) bool {
+	u, err := earl.Parse(remoteUrl)
+	return err == nil && u.Scheme == dbfactory.BundleScheme
+}
+
+// CheckBundleBasis returns an error if |remoteUrl| is an incremental bundle built on commits that |ddb| doesn't have.
+// A nil |ddb|, as when cloning, has none of them. Urls of other schemes are always accepted.
+func CheckBundleBasis(ctx context.Context, ddb *doltdb.DoltDB, remoteUrl string) error {
+	u, err := earl.Parse(remoteUrl)
+	if err != nil || u.Scheme != dbfactory.BundleScheme {
+		return nil
+	}
+
+	header, err := dbfactory.ReadBundleHeader(u.Host + filepath.FromSlash(u.Path))
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, basis := range header.Basis {
+		h, ok := hash.MaybeParse(basis)
+		if !ok {
+			return fmt.Errorf("%w: invalid basis commit '%s'", dbfactory.ErrNotABundle, basis)
+		}
+
+		has := false
+		if ddb != nil {
+			has, err = ddb.Has(ctx, h)
+			if err != nil {
+				return err
+			}
+		}
+		if !has {
+			missing = append(missing, basis)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrBundleMissingBasis, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
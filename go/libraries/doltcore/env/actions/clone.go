@@ -36,6 +36,7 @@ import (
 	"github.com/dolthub/dolt/go/store/chunks"
 	"github.com/dolthub/dolt/go/store/datas"
 	"github.com/dolthub/dolt/go/store/datas/pull"
+	"github.com/dolthub/dolt/go/store/hash"
 	"github.com/dolthub/dolt/go/store/types"
 )
 
@@ -165,19 +166,33 @@ func CloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch s
 	// We support two forms of cloning: full and shallow. These two approaches have little in common, with the exception
 	// of the first and last steps. Determining the branch to check out and setting the working set to the checked out commit.
 
-	srcRefHashes, branch, err := getSrcRefs(ctx, branch, srcDB, dEnv)
+	if remoteName == "" {
+		remoteName = "origin"
+	}
+
+	remotes, err := dEnv.GetRemotes()
 	if err != nil {
 		return fmt.Errorf("%w; %s", ErrCloneFailed, err.Error())
 	}
-	if remoteName == "" {
-		remoteName = "origin"
+	isBundle := false
+	if r, ok := remotes.Get(remoteName); ok {
+		err = CheckBundleBasis(ctx, nil, r.Url)
+		if err != nil {
+			return fmt.Errorf("%w; cannot clone from an incremental bundle; %s", ErrCloneFailed, err.Error())
+		}
+		isBundle = IsBundleUrl(r.Url)
+	}
+
+	srcRefHashes, branch, err := getSrcRefs(ctx, branch, srcDB, dEnv)
+	if err != nil {
+		return fmt.Errorf("%w; %s", ErrCloneFailed, err.Error())
 	}
 
 	var checkedOutCommit *doltdb.Commit
 
 	// Step 1) Pull the remote information we care about to a local disk.
 	if depth <= 0 {
-		checkedOutCommit, err = fullClone(ctx, srcDB, dEnv, srcRefHashes, branch, remoteName, singleBranch)
+		checkedOutCommit, err = fullClone(ctx, srcDB, dEnv, srcRefHashes, branch, remoteName, singleBranch, isBundle)
 	} else {
 		checkedOutCommit, err = shallowCloneDataPull(ctx, dEnv.DbData(), srcDB, remoteName, branch, depth)
 	}
@@ -247,19 +262,28 @@ func getSrcRefs(ctx context.Context, branch string, srcDB *doltdb.DoltDB, dEnv *
 	return srcRefHashes, branch, nil
 }
 
-func fullClone(ctx context.Context, srcDB *doltdb.DoltDB, dEnv *env.DoltEnv, srcRefHashes []doltdb.RefWithHash, branch, remoteName string, singleBranch bool) (*doltdb.Commit, error) {
-	eventCh := make(chan pull.TableFileEvent, 128)
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		clonePrint(eventCh)
-	}()
+// fullClone clones every branch and tag of |srcDB|. If |pullChunks| is set, or the clone is a partial clone, the chunks
+// of the refs are pulled rather than copying the table files of |srcDB|.
+func fullClone(ctx context.Context, srcDB *doltdb.DoltDB, dEnv *env.DoltEnv, srcRefHashes []doltdb.RefWithHash, branch, remoteName string, singleBranch, pullChunks bool) (*doltdb.Commit, error) {
+	if pullChunks || dEnv.DoltDB.IsPartialClone() {
+		err := pullCloneData(ctx, srcDB, dEnv, srcRefHashes)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		eventCh := make(chan pull.TableFileEvent, 128)
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clonePrint(eventCh)
+		}()
 
-	err := srcDB.Clone(ctx, dEnv.DoltDB, eventCh)
+		_ = srcDB.Clone(ctx, dEnv.DoltDB, eventCh)
 
-	close(eventCh)
-	wg.Wait()
+		close(eventCh)
+		wg.Wait()
+	}
 
 	cs, _ := doltdb.NewCommitSpec(branch)
 	optCmt, err := dEnv.DoltDB.Resolve(ctx, cs, nil)
@@ -307,6 +331,40 @@ func fullClone(ctx context.Context, srcDB *doltdb.DoltDB, dEnv *env.DoltEnv, src
 	return cm, nil
 }
 
+// pullCloneData pulls the branches and tags of |srcDB| into a clone. Unlike a full clone, which copies the remote's
+// table files as they are, this walks the refs' histories, so that it can leave out the data that the filter of a
+// partial clone selects, and read the archives of bundles, which can't be copied as table files.
+func pullCloneData(ctx context.Context, srcDB *doltdb.DoltDB, dEnv *env.DoltEnv, srcRefHashes []doltdb.RefWithHash) error {
+	tmpDir, err := dEnv.TempTableFilesDir()
+	if err != nil {
+		return err
+	}
+
+	var refs []doltdb.RefWithHash
+	var targets []hash.Hash
+	for _, refHash := range srcRefHashes {
+		if refHash.Ref.GetType() == ref.BranchRefType || refHash.Ref.GetType() == ref.TagRefType {
+			refs = append(refs, refHash)
+			targets = append(targets, refHash.Hash)
+		}
+	}
+
+	err = dEnv.DoltDB.PullChunks(ctx, tmpDir, srcDB, targets, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// leave the refs as a full clone would, for the caller to translate into remote branches
+	for _, refHash := range refs {
+		err = dEnv.DoltDB.SetHead(ctx, refHash.Ref, refHash.Hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // shallowCloneDataPull is a shallow clone specific helper function to pull only the data required to show the given branch
 // at the depth given.
 func shallowCloneDataPull(ctx context.Context, destData env.DbData, srcDB *doltdb.DoltDB, remoteName, branch string, depth int) (*doltdb.Commit, error) {
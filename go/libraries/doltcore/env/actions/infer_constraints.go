@@ -0,0 +1,558 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/row"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table"
+	"github.com/dolthub/dolt/go/libraries/utils/set"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+const (
+	// DefaultInferenceSampleRows is the number of rows profiled by InferSchemaFromTableReader when no limit is given.
+	DefaultInferenceSampleRows = 100_000
+
+	// enumMaxValues is the largest number of distinct values a column may have and still be inferred as an ENUM.
+	enumMaxValues = 16
+	// enumMinRows is the minimum number of non-null values a column must have before it is considered for an ENUM.
+	// Small files don't give enough evidence that the observed values are the complete set.
+	enumMinRows = 20
+	// maxCompositeKeyCols is the widest composite primary key that inference will propose.
+	maxCompositeKeyCols = 3
+	// maxCompositeKeyCandidates bounds the number of columns considered when searching for a composite key.
+	maxCompositeKeyCandidates = 12
+	// uniqueKeyMinRows is the minimum number of rows a file must have before unique keys other than the primary key
+	// are proposed. In small files most columns are unique by chance.
+	uniqueKeyMinRows = 20
+)
+
+// varcharLengths are the lengths proposed for VARCHAR columns, smallest first.
+var varcharLengths = []int64{16, 32, 64, 128, 255, 512, 1024, 2048, 4096, 8192, typeinfo.MaxVarcharLength}
+
+// extendedChronoLayouts are date and datetime layouts recognized by schema inference and table import in addition
+// to the formats the SQL engine accepts natively. Month-first layouts are preferred for ambiguous dates.
+var extendedChronoLayouts = []struct {
+	layout  string
+	hasTime bool
+}{
+	{"01/02/2006", false},
+	{"1/2/2006", false},
+	{"01/02/2006 15:04:05", true},
+	{"1/2/2006 15:04:05", true},
+	{"01/02/2006 15:04", true},
+	{"1/2/2006 15:04", true},
+	{"2006/01/02", false},
+	{"2006/1/2", false},
+	{"2006/01/02 15:04:05", true},
+	{"02-Jan-2006", false},
+	{"2-Jan-2006", false},
+	{"02 Jan 2006", false},
+	{"2 Jan 2006", false},
+	{"Jan 2, 2006", false},
+	{"January 2, 2006", false},
+	{"Mon, 02 Jan 2006 15:04:05 MST", true},
+	{"Mon Jan _2 15:04:05 2006", true},
+}
+
+// ParseExtendedDatetime parses |s| using the date and datetime layouts that schema inference recognizes beyond those
+// supported by the SQL engine. It returns false if |s| does not match any of them.
+func ParseExtendedDatetime(s string) (time.Time, bool) {
+	t, _, ok := parseExtendedDatetime(s)
+	return t, ok
+}
+
+func parseExtendedDatetime(s string) (t time.Time, hasTime bool, ok bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false, false
+	}
+	for _, l := range extendedChronoLayouts {
+		t, err := time.Parse(l.layout, s)
+		if err == nil {
+			return t.UTC(), l.hasTime, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// InferredColumn is a column proposed by schema inference along with the reasons for each choice made about it.
+type InferredColumn struct {
+	Column  schema.Column
+	Reasons []string
+}
+
+// InferredUniqueKey is a UNIQUE key proposed by schema inference along with the reason it was proposed.
+type InferredUniqueKey struct {
+	Columns []string
+	Reason  string
+}
+
+// InferredSchema is the result of profiling a table reader. It proposes column types, lengths, nullability, a
+// primary key and unique keys, and records why each of those choices was made so that they can be previewed before a
+// table is created.
+type InferredSchema struct {
+	Columns []InferredColumn
+	// PrimaryKey holds the names of the proposed primary key columns, in key order. It's empty when no candidate key
+	// was found.
+	PrimaryKey []string
+	// PrimaryKeyReason explains why PrimaryKey was chosen, or why no key could be proposed.
+	PrimaryKeyReason string
+	// UniqueKeys holds the proposed UNIQUE keys on columns that are not the primary key.
+	UniqueKeys []InferredUniqueKey
+	// RowsProfiled is the number of rows that were read while profiling.
+	RowsProfiled int
+	// Complete is true if every row of the source was profiled.
+	Complete bool
+}
+
+// ColCollection returns the inferred columns. Columns are not marked as part of the primary key.
+func (is *InferredSchema) ColCollection() *schema.ColCollection {
+	cols := make([]schema.Column, len(is.Columns))
+	for i, c := range is.Columns {
+		cols[i] = c.Column
+	}
+	return schema.NewColCollection(cols...)
+}
+
+// Explain returns a human-readable description of every choice made during inference, one line per choice.
+func (is *InferredSchema) Explain() []string {
+	var lines []string
+	if is.Complete {
+		lines = append(lines, fmt.Sprintf("profiled all %d rows", is.RowsProfiled))
+	} else {
+		lines = append(lines, fmt.Sprintf("profiled the first %d rows; constraints that require a full scan were not inferred", is.RowsProfiled))
+	}
+	for _, c := range is.Columns {
+		for _, r := range c.Reasons {
+			lines = append(lines, fmt.Sprintf("%s: %s", c.Column.Name, r))
+		}
+	}
+	if len(is.PrimaryKey) > 0 {
+		lines = append(lines, fmt.Sprintf("primary key (%s): %s", strings.Join(is.PrimaryKey, ", "), is.PrimaryKeyReason))
+	} else {
+		lines = append(lines, fmt.Sprintf("primary key: %s", is.PrimaryKeyReason))
+	}
+	for _, uk := range is.UniqueKeys {
+		lines = append(lines, fmt.Sprintf("unique key (%s): %s", strings.Join(uk.Columns, ", "), uk.Reason))
+	}
+	return lines
+}
+
+// InferSchemaFromTableReader profiles up to |sampleRows| rows from |rd| and proposes a schema for them. In addition to
+// the least permissive column types found by InferColumnTypesFromTableReader, it proposes VARCHAR lengths with
+// headroom, ENUMs for low-cardinality columns, NOT NULL constraints, a single or composite primary key made of
+// unique, non-null columns and UNIQUE keys on the other unique columns. If |sampleRows| is not positive, DefaultInferenceSampleRows is used.
+func InferSchemaFromTableReader(ctx context.Context, rd table.ReadCloser, args InferenceArgs, sampleRows int) (*InferredSchema, error) {
+	if sampleRows <= 0 {
+		sampleRows = DefaultInferenceSampleRows
+	}
+
+	p := newProfiler(rd.GetSchema(), args)
+	complete := false
+	for {
+		r, err := rd.ReadRow(ctx)
+		if err == io.EOF {
+			complete = true
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if err = p.processRow(r); err != nil {
+			return nil, err
+		}
+		if p.rows >= sampleRows {
+			// the sample is complete if the reader is exhausted as well
+			_, err = rd.ReadRow(ctx)
+			complete = err == io.EOF
+			break
+		}
+	}
+
+	return p.inferSchema(complete)
+}
+
+// columnProfile accumulates what was seen for a single column. Its memory is bounded by the number of profiled rows:
+// values are kept as fixed size hashes, which are only needed to find unique columns, and the distinct values of a
+// column are only kept until there are too many of them for an ENUM.
+type columnProfile struct {
+	col      schema.Column
+	types    typeInfoSet
+	hashes   []uint64
+	distinct map[string]struct{}
+	hasEmpty bool
+	nullCnt  int
+	maxLen   int
+	// lastRow is the number of rows profiled when the last value of the column was seen
+	lastRow int
+}
+
+// hasNulls returns whether the column has null values once it is imported as |ti|. Non-string types treat empty
+// strings as NULL on import, so they count as nulls too.
+func (cp *columnProfile) hasNulls(ti typeinfo.TypeInfo) bool {
+	if cp.nullCnt > 0 {
+		return true
+	}
+	_, isString := ti.ToSqlType().(sql.StringType)
+	return !isString && cp.hasEmpty
+}
+
+type profiler struct {
+	cols           []*columnProfile
+	byTag          map[uint64]*columnProfile
+	rows           int
+	floatThreshold float64
+	seed           maphash.Seed
+}
+
+func newProfiler(readerSch schema.Schema, args InferenceArgs) *profiler {
+	p := &profiler{
+		byTag:          make(map[uint64]*columnProfile),
+		floatThreshold: args.FloatThreshold(),
+		seed:           maphash.MakeSeed(),
+	}
+	mapper := args.ColNameMapper()
+	_ = readerSch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		col.Name = mapper.Map(col.Name)
+		col.Tag = schema.ReservedTagMin + tag
+		cp := &columnProfile{col: col, types: make(typeInfoSet), distinct: make(map[string]struct{})}
+		p.cols = append(p.cols, cp)
+		p.byTag[tag] = cp
+		return false, nil
+	})
+	return p
+}
+
+func (p *profiler) processRow(r row.Row) error {
+	p.rows++
+	_, err := r.IterCols(func(tag uint64, val types.Value) (stop bool, err error) {
+		cp, ok := p.byTag[tag]
+		if !ok || types.IsNull(val) {
+			return false, nil
+		}
+		cp.lastRow = p.rows
+		strVal := string(val.(types.String))
+		cp.types[leastPermissiveType(strVal, p.floatThreshold, true)] = struct{}{}
+		if l := utf8.RuneCountInString(strVal); l > cp.maxLen {
+			cp.maxLen = l
+		}
+		if strVal == "" {
+			cp.hasEmpty = true
+		}
+		if cp.distinct != nil {
+			cp.distinct[strVal] = struct{}{}
+			if len(cp.distinct) > enumMaxValues {
+				// too many values for an ENUM
+				cp.distinct = nil
+			}
+		}
+		if cp.nullCnt == 0 {
+			cp.hashes = append(cp.hashes, maphash.String(p.seed, strVal))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, cp := range p.cols {
+		if cp.lastRow != p.rows {
+			cp.nullCnt++
+			// columns with nulls are not part of a primary key
+			cp.hashes = nil
+		}
+	}
+	return nil
+}
+
+func (p *profiler) inferSchema(complete bool) (*InferredSchema, error) {
+	is := &InferredSchema{
+		RowsProfiled: p.rows,
+		Complete:     complete,
+	}
+
+	for _, cp := range p.cols {
+		ic, err := p.inferColumn(cp, complete)
+		if err != nil {
+			return nil, err
+		}
+		is.Columns = append(is.Columns, ic)
+	}
+
+	is.PrimaryKey, is.PrimaryKeyReason = p.inferPrimaryKey(is.Columns, complete)
+	pks := set.NewStrSet(is.PrimaryKey)
+	for i := range is.Columns {
+		col := &is.Columns[i].Column
+		if pks.Contains(col.Name) && col.IsNullable() {
+			// primary key columns are always NOT NULL
+			col.Constraints = append(col.Constraints, schema.NotNullConstraint{})
+		}
+	}
+	is.UniqueKeys = p.inferUniqueKeys(is.Columns, is.PrimaryKey, complete)
+
+	return is, nil
+}
+
+func (p *profiler) inferColumn(cp *columnProfile, complete bool) (InferredColumn, error) {
+	ts := make(typeInfoSet, len(cp.types))
+	for t := range cp.types {
+		ts[t] = struct{}{}
+	}
+	ti := findCommonType(ts)
+	nonNull := p.rows - cp.nullCnt
+
+	var reasons []string
+	switch {
+	case nonNull == 0:
+		reasons = append(reasons, fmt.Sprintf("%s: no values were present", ti.ToSqlType().String()))
+	case ti == typeinfo.StringDefaultType:
+		var err error
+		var reason string
+		ti, reason, err = p.inferStringType(cp, nonNull)
+		if err != nil {
+			return InferredColumn{}, err
+		}
+		reasons = append(reasons, reason)
+	default:
+		reasons = append(reasons, fmt.Sprintf("%s: %s", ti.ToSqlType().String(), describeType(ti)))
+	}
+
+	col := cp.col
+	col.Kind = ti.NomsKind()
+	col.TypeInfo = ti
+	col.Constraints = nil
+
+	switch {
+	case cp.hasNulls(ti):
+		reasons = append(reasons, "nullable: empty values are present")
+	case !complete:
+		reasons = append(reasons, fmt.Sprintf("nullable: no empty values in %d sampled rows, but the file was not fully scanned", p.rows))
+	case p.rows == 0:
+		reasons = append(reasons, "nullable: the file has no rows")
+	default:
+		col.Constraints = append(col.Constraints, schema.NotNullConstraint{})
+		reasons = append(reasons, fmt.Sprintf("NOT NULL: no empty values in %d rows", p.rows))
+	}
+
+	return InferredColumn{Column: col, Reasons: reasons}, nil
+}
+
+// inferStringType chooses between ENUM and a sized VARCHAR for a column whose values are strings.
+func (p *profiler) inferStringType(cp *columnProfile, nonNull int) (typeinfo.TypeInfo, string, error) {
+	distinct := cp.distinct
+	if !cp.hasEmpty && distinct != nil && nonNull >= enumMinRows && len(distinct)*4 <= nonNull {
+		vals := make([]string, 0, len(distinct))
+		for v := range distinct {
+			vals = append(vals, v)
+		}
+		sort.Strings(vals)
+		enumType, err := gmstypes.CreateEnumType(vals, sql.Collation_Default)
+		if err != nil {
+			return nil, "", err
+		}
+		ti := typeinfo.CreateEnumTypeFromSqlEnumType(enumType)
+		return ti, fmt.Sprintf("%s: only %d distinct values across %d values", ti.ToSqlType().String(), len(vals), nonNull), nil
+	}
+
+	// leave 50% headroom above the longest value seen
+	want := int64(cp.maxLen) + int64(cp.maxLen)/2
+	for _, l := range varcharLengths {
+		if l >= want {
+			strType, err := gmstypes.CreateString(sqltypes.VarChar, l, sql.Collation_Default)
+			if err != nil {
+				return nil, "", err
+			}
+			ti := typeinfo.CreateVarStringTypeFromSqlType(strType)
+			return ti, fmt.Sprintf("%s: longest value is %d characters", ti.ToSqlType().String(), cp.maxLen), nil
+		}
+	}
+
+	return typeinfo.TextType, fmt.Sprintf("TEXT: longest value is %d characters, too long for a VARCHAR", cp.maxLen), nil
+}
+
+// inferPrimaryKey proposes the columns to use as a primary key. Single columns are preferred over composite keys, and
+// among single columns those named like an id and those with integer or uuid types are preferred.
+func (p *profiler) inferPrimaryKey(cols []InferredColumn, complete bool) ([]string, string) {
+	if p.rows == 0 {
+		return nil, "the file has no rows"
+	}
+
+	var candidates []int
+	for i, c := range cols {
+		if p.cols[i].hasNulls(c.Column.TypeInfo) || !isKeyableType(c.Column.TypeInfo) {
+			continue
+		}
+		candidates = append(candidates, i)
+	}
+
+	scope := fmt.Sprintf("all %d rows", p.rows)
+	if !complete {
+		scope = fmt.Sprintf("the first %d rows", p.rows)
+	}
+
+	var unique []int
+	for _, i := range candidates {
+		if p.uniqueOver(i) {
+			unique = append(unique, i)
+		}
+	}
+	if len(unique) > 0 {
+		sort.SliceStable(unique, func(a, b int) bool {
+			return keyPreference(cols[unique[a]].Column) > keyPreference(cols[unique[b]].Column)
+		})
+		return []string{cols[unique[0]].Column.Name}, fmt.Sprintf("values are unique and non-null in %s", scope)
+	}
+
+	if len(candidates) > maxCompositeKeyCandidates {
+		candidates = candidates[:maxCompositeKeyCandidates]
+	}
+	for width := 2; width <= maxCompositeKeyCols && width <= len(candidates); width++ {
+		var found []int
+		forEachCombination(candidates, width, func(combo []int) bool {
+			if p.uniqueOver(combo...) {
+				found = append([]int(nil), combo...)
+				return true
+			}
+			return false
+		})
+		if found != nil {
+			names := make([]string, len(found))
+			for i, idx := range found {
+				names[i] = cols[idx].Column.Name
+			}
+			return names, fmt.Sprintf("no single column is unique, but these values are unique together and non-null in %s", scope)
+		}
+	}
+
+	return nil, fmt.Sprintf("no combination of up to %d non-null columns is unique in %s; use --pk to choose one", maxCompositeKeyCols, scope)
+}
+
+// inferUniqueKeys proposes a UNIQUE key for each column outside of |pk| whose values are unique. Unique keys are only
+// proposed when every row was profiled, since a key that only holds for a sample would fail the import.
+func (p *profiler) inferUniqueKeys(cols []InferredColumn, pk []string, complete bool) []InferredUniqueKey {
+	if !complete || p.rows < uniqueKeyMinRows {
+		return nil
+	}
+	pks := set.NewStrSet(pk)
+	var keys []InferredUniqueKey
+	for i, c := range cols {
+		if pks.Contains(c.Column.Name) || p.cols[i].hasNulls(c.Column.TypeInfo) || !isKeyableType(c.Column.TypeInfo) {
+			continue
+		}
+		if p.uniqueOver(i) {
+			keys = append(keys, InferredUniqueKey{
+				Columns: []string{c.Column.Name},
+				Reason:  fmt.Sprintf("values are unique in all %d rows", p.rows),
+			})
+		}
+	}
+	return keys
+}
+
+// uniqueOver returns whether the combined values of the columns at |idxs|, which have no nulls, are unique across all
+// profiled rows. Values are compared by their hashes, so a collision makes unique columns look like they are not,
+// but never the other way around.
+func (p *profiler) uniqueOver(idxs ...int) bool {
+	seen := make(map[[maxCompositeKeyCols]uint64]struct{}, p.rows)
+	for r := 0; r < p.rows; r++ {
+		var k [maxCompositeKeyCols]uint64
+		for j, i := range idxs {
+			k[j] = p.cols[i].hashes[r]
+		}
+		if _, ok := seen[k]; ok {
+			return false
+		}
+		seen[k] = struct{}{}
+	}
+	return true
+}
+
+func isKeyableType(ti typeinfo.TypeInfo) bool {
+	switch ti.GetTypeIdentifier() {
+	case typeinfo.BlobStringTypeIdentifier, typeinfo.JSONTypeIdentifier, typeinfo.FloatTypeIdentifier, typeinfo.BoolTypeIdentifier:
+		return false
+	}
+	return true
+}
+
+func keyPreference(col schema.Column) int {
+	score := 0
+	name := strings.ToLower(col.Name)
+	if name == "id" {
+		score += 4
+	} else if strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "id") {
+		score += 2
+	}
+	switch col.TypeInfo.GetTypeIdentifier() {
+	case typeinfo.IntTypeIdentifier, typeinfo.UuidTypeIdentifier:
+		score += 1
+	}
+	return score
+}
+
+// forEachCombination calls |cb| with each combination of |k| elements of |vals|, in lexicographic order, until |cb|
+// returns true.
+func forEachCombination(vals []int, k int, cb func([]int) bool) {
+	combo := make([]int, k)
+	var rec func(start, depth int) bool
+	rec = func(start, depth int) bool {
+		if depth == k {
+			return cb(combo)
+		}
+		for i := start; i <= len(vals)-(k-depth); i++ {
+			combo[depth] = vals[i]
+			if rec(i+1, depth+1) {
+				return true
+			}
+		}
+		return false
+	}
+	rec(0, 0)
+}
+
+func describeType(ti typeinfo.TypeInfo) string {
+	switch ti.GetTypeIdentifier() {
+	case typeinfo.IntTypeIdentifier:
+		return "every value is an integer"
+	case typeinfo.FloatTypeIdentifier:
+		return "values include fractional numbers"
+	case typeinfo.UuidTypeIdentifier:
+		return "every value is a UUID"
+	case typeinfo.BoolTypeIdentifier:
+		return "every value is true or false"
+	case typeinfo.JSONTypeIdentifier:
+		return "every value is a JSON object or array"
+	case typeinfo.DatetimeTypeIdentifier, typeinfo.TimeTypeIdentifier, typeinfo.YearTypeIdentifier:
+		return "every value is a date or time"
+	case typeinfo.BlobStringTypeIdentifier:
+		return "values are too long for a VARCHAR"
+	}
+	return "inferred from values"
+}
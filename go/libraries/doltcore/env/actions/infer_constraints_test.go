@@ -0,0 +1,215 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/untyped/csv"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+func inferFromCSV(t *testing.T, contents string, sampleRows int) *InferredSchema {
+	rd, err := csv.NewCSVReader(types.Format_Default, io.NopCloser(strings.NewReader(contents)), csv.NewCSVInfo())
+	require.NoError(t, err)
+	defer rd.Close(context.Background())
+
+	args := testInferenceArgs{ColMapper: identityMapper}
+	inferred, err := InferSchemaFromTableReader(context.Background(), rd, args, sampleRows)
+	require.NoError(t, err)
+	return inferred
+}
+
+func inferredCol(t *testing.T, is *InferredSchema, name string) schema.Column {
+	for _, c := range is.Columns {
+		if c.Column.Name == name {
+			return c.Column
+		}
+	}
+	require.Failf(t, "column not found", "%s", name)
+	return schema.Column{}
+}
+
+func ordersCSV(n int) string {
+	statuses := []string{"open", "shipped", "cancelled"}
+	sb := strings.Builder{}
+	sb.WriteString("name,order_id,status,note\n")
+	for i := 0; i < n; i++ {
+		note := ""
+		if i%5 != 0 {
+			note = fmt.Sprintf("note %d", i)
+		}
+		sb.WriteString(fmt.Sprintf("customer %d,%d,%s,%s\n", i, i+1, statuses[i%len(statuses)], note))
+	}
+	return sb.String()
+}
+
+func TestInferSchemaFromTableReader(t *testing.T) {
+	t.Run("single column primary key", func(t *testing.T) {
+		is := inferFromCSV(t, ordersCSV(40), 0)
+		assert.True(t, is.Complete)
+		assert.Equal(t, 40, is.RowsProfiled)
+		assert.Equal(t, []string{"order_id"}, is.PrimaryKey)
+
+		orderID := inferredCol(t, is, "order_id")
+		assert.Equal(t, typeinfo.Int32Type, orderID.TypeInfo)
+		assert.False(t, orderID.IsNullable())
+
+		status := inferredCol(t, is, "status")
+		assert.Equal(t, typeinfo.EnumTypeIdentifier, status.TypeInfo.GetTypeIdentifier())
+		assert.Contains(t, strings.ToLower(status.TypeInfo.ToSqlType().String()), "enum('cancelled','open','shipped')")
+		assert.False(t, status.IsNullable())
+
+		name := inferredCol(t, is, "name")
+		assert.Equal(t, "varchar(16)", name.TypeInfo.ToSqlType().String())
+
+		note := inferredCol(t, is, "note")
+		assert.True(t, note.IsNullable())
+	})
+
+	t.Run("composite primary key", func(t *testing.T) {
+		is := inferFromCSV(t, "region,day,total\nus,1,10\nus,2,10\neu,1,12\neu,2,12\n", 0)
+		assert.Equal(t, []string{"region", "day"}, is.PrimaryKey)
+		for _, name := range is.PrimaryKey {
+			assert.False(t, inferredCol(t, is, name).IsNullable())
+		}
+	})
+
+	t.Run("id columns are preferred", func(t *testing.T) {
+		is := inferFromCSV(t, "email,id\na@x.com,1\nb@x.com,2\n", 0)
+		assert.Equal(t, []string{"id"}, is.PrimaryKey)
+	})
+
+	t.Run("no primary key", func(t *testing.T) {
+		is := inferFromCSV(t, "a,b\n1,2\n1,2\n", 0)
+		assert.Empty(t, is.PrimaryKey)
+		assert.Contains(t, is.PrimaryKeyReason, "--pk")
+	})
+
+	t.Run("nullable columns are not keys", func(t *testing.T) {
+		is := inferFromCSV(t, "a,b\n,x\n2,y\n", 0)
+		assert.Equal(t, []string{"b"}, is.PrimaryKey)
+		assert.True(t, inferredCol(t, is, "a").IsNullable())
+	})
+
+	t.Run("columns with empty values are not keys", func(t *testing.T) {
+		is := inferFromCSV(t, "a,b\n\"\",x\n2,y\n", 0)
+		assert.Equal(t, typeinfo.Int32Type, inferredCol(t, is, "a").TypeInfo)
+		assert.Equal(t, []string{"b"}, is.PrimaryKey)
+		assert.True(t, inferredCol(t, is, "a").IsNullable())
+	})
+
+	t.Run("sampled files don't infer not null", func(t *testing.T) {
+		is := inferFromCSV(t, ordersCSV(40), 10)
+		assert.False(t, is.Complete)
+		assert.Equal(t, 10, is.RowsProfiled)
+		assert.True(t, inferredCol(t, is, "status").IsNullable())
+		// primary key columns are always not null
+		assert.Equal(t, []string{"order_id"}, is.PrimaryKey)
+		assert.False(t, inferredCol(t, is, "order_id").IsNullable())
+	})
+
+	t.Run("sample exactly the size of the file", func(t *testing.T) {
+		is := inferFromCSV(t, ordersCSV(10), 10)
+		assert.True(t, is.Complete)
+	})
+
+	t.Run("unique keys", func(t *testing.T) {
+		is := inferFromCSV(t, ordersCSV(40), 0)
+		require.Len(t, is.UniqueKeys, 1)
+		assert.Equal(t, []string{"name"}, is.UniqueKeys[0].Columns)
+		assert.Contains(t, is.UniqueKeys[0].Reason, "all 40 rows")
+
+		// small files and sampled files don't infer unique keys
+		is = inferFromCSV(t, ordersCSV(10), 0)
+		assert.Empty(t, is.UniqueKeys)
+		is = inferFromCSV(t, ordersCSV(40), 30)
+		assert.Empty(t, is.UniqueKeys)
+	})
+
+	t.Run("json and long strings", func(t *testing.T) {
+		long := strings.Repeat("x", 200)
+		is := inferFromCSV(t, fmt.Sprintf("id,doc,body\n1,\"{\"\"a\"\":1}\",%s\n2,\"[1,2]\",y\n", long), 0)
+		assert.Equal(t, typeinfo.JSONType, inferredCol(t, is, "doc").TypeInfo)
+		assert.Equal(t, "varchar(512)", inferredCol(t, is, "body").TypeInfo.ToSqlType().String())
+	})
+
+	t.Run("explanations", func(t *testing.T) {
+		is := inferFromCSV(t, ordersCSV(40), 0)
+		explanation := strings.ToLower(strings.Join(is.Explain(), "\n"))
+		assert.Contains(t, explanation, "profiled all 40 rows")
+		assert.Contains(t, explanation, "status: enum")
+		assert.Contains(t, explanation, "order_id: not null")
+		assert.Contains(t, explanation, "primary key (order_id)")
+		assert.Contains(t, explanation, "unique key (name): values are unique in all 40 rows")
+	})
+}
+
+func TestParseExtendedDatetime(t *testing.T) {
+	tests := []struct {
+		valStr string
+		exp    time.Time
+		ok     bool
+	}{
+		{"01/02/2020", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), true},
+		{"1/2/2020 13:04", time.Date(2020, 1, 2, 13, 4, 0, 0, time.UTC), true},
+		{"2020/01/02 13:04:05", time.Date(2020, 1, 2, 13, 4, 5, 0, time.UTC), true},
+		{"02-Jan-2020", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), true},
+		{"January 2, 2020", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), true},
+		{"13/02/2020", time.Time{}, false},
+		{"asdf", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.valStr, func(t *testing.T) {
+			actual, ok := ParseExtendedDatetime(test.valStr)
+			assert.Equal(t, test.ok, ok)
+			assert.True(t, test.exp.Equal(actual), "expected %v, got %v", test.exp, actual)
+		})
+	}
+
+	assert.Equal(t, typeinfo.DateType, leastPermissiveChronoType("01/02/2020", true))
+	assert.Equal(t, typeinfo.DatetimeType, leastPermissiveChronoType("01/02/2020 10:30", true))
+	// the extended layouts are only recognized when inferring constraints
+	assert.Equal(t, typeinfo.UnknownType, leastPermissiveChronoType("01/02/2020", false))
+	assert.Equal(t, typeinfo.StringDefaultType, leastPermissiveType("01/02/2020", 0, false))
+}
+
+func TestForEachCombination(t *testing.T) {
+	var combos [][]int
+	forEachCombination([]int{1, 2, 3, 4}, 2, func(c []int) bool {
+		combos = append(combos, append([]int(nil), c...))
+		return false
+	})
+	assert.Equal(t, [][]int{{1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}, combos)
+
+	var calls int
+	forEachCombination([]int{1, 2, 3}, 2, func(c []int) bool {
+		calls++
+		return true
+	})
+	assert.Equal(t, 1, calls)
+}
@@ -148,7 +148,7 @@ func (inf *inferrer) processRow(r row.Row) error {
 			return false, nil
 		}
 		strVal := string(val.(types.String))
-		typeInfo := leastPermissiveType(strVal, inf.floatThreshold)
+		typeInfo := leastPermissiveType(strVal, inf.floatThreshold, false)
 		inf.inferSets[tag][typeInfo] = struct{}{}
 		return false, nil
 	})
@@ -156,7 +156,9 @@ func (inf *inferrer) processRow(r row.Row) error {
 	return err
 }
 
-func leastPermissiveType(strVal string, floatThreshold float64) typeinfo.TypeInfo {
+// leastPermissiveType returns the least permissive type of |strVal|. If |extendedChrono| is true, the dates and
+// datetimes in extendedChronoLayouts are recognized as well as those the engine parses.
+func leastPermissiveType(strVal string, floatThreshold float64, extendedChrono bool) typeinfo.TypeInfo {
 	if len(strVal) == 0 {
 		return typeinfo.UnknownType
 	}
@@ -172,7 +174,7 @@ func leastPermissiveType(strVal string, floatThreshold float64) typeinfo.TypeInf
 		return typeinfo.UuidType
 	}
 
-	chronoType := leastPermissiveChronoType(strVal)
+	chronoType := leastPermissiveChronoType(strVal, extendedChrono)
 	if chronoType != typeinfo.UnknownType {
 		return chronoType
 	}
@@ -253,7 +255,7 @@ func leastPermissiveNumericType(strVal string, floatThreshold float64) (ti typei
 	}
 }
 
-func leastPermissiveChronoType(strVal string) typeinfo.TypeInfo {
+func leastPermissiveChronoType(strVal string, extendedChrono bool) typeinfo.TypeInfo {
 	if strVal == "" {
 		return typeinfo.UnknownType
 	}
@@ -273,6 +275,16 @@ func leastPermissiveChronoType(strVal string) typeinfo.TypeInfo {
 		return typeinfo.TimeType
 	}
 
+	if !extendedChrono {
+		return typeinfo.UnknownType
+	}
+	if t, hasTime, ok := parseExtendedDatetime(strVal); ok {
+		if !hasTime || (t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0) {
+			return typeinfo.DateType
+		}
+		return typeinfo.DatetimeType
+	}
+
 	return typeinfo.UnknownType
 }
 
@@ -62,7 +62,7 @@ func TestLeastPermissiveType(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actualType := leastPermissiveType(test.valStr, test.floatThreshold)
+			actualType := leastPermissiveType(test.valStr, test.floatThreshold, false)
 			assert.Equal(t, test.expType, actualType, "val: %s, expected: %v, actual: %v", test.valStr, test.expType, actualType)
 		})
 	}
@@ -114,7 +114,7 @@ func TestLeasPermissiveChronoType(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			actualType := leastPermissiveChronoType(test.valStr)
+			actualType := leastPermissiveChronoType(test.valStr, false)
 			assert.Equal(t, test.expType, actualType, "val: %s, expected: %v, actual: %v", test.valStr, test.expType, actualType)
 		})
 	}
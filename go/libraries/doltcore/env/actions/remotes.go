@@ -493,6 +493,11 @@ func FetchRefSpecs(
 	progStarter ProgStarter,
 	progStopper ProgStopper,
 ) error {
+	err := CheckBundleBasis(ctx, dbData.Ddb, remote.Url)
+	if err != nil {
+		return err
+	}
+
 	return fetchRefSpecsWithDepth(ctx, dbData, srcDB, refSpecs, defaultRefSpec, remote, mode, -1, progStarter, progStopper)
 }
 
@@ -151,7 +151,9 @@ func LoadWithoutDB(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys,
 func Load(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys, urlStr string, version string) *DoltEnv {
 	dEnv := LoadWithoutDB(ctx, hdp, fs, version)
 
-	ddb, dbLoadErr := doltdb.LoadDoltDB(ctx, types.Format_Default, urlStr, fs)
+	// a partial clone reads the data it doesn't have from its origin, which may need our credentials
+	params := map[string]interface{}{dbfactory.GRPCDialProviderParam: dEnv}
+	ddb, dbLoadErr := doltdb.LoadDoltDBWithParams(ctx, types.Format_Default, urlStr, fs, params)
 
 	dEnv.DoltDB = ddb
 	dEnv.DBLoadError = dbLoadErr
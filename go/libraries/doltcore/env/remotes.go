@@ -643,12 +643,28 @@ func NewPullSpec(
 }
 
 func GetAbsRemoteUrl(fs filesys2.Filesys, cfg config.ReadableConfig, urlArg string) (string, string, error) {
+	if fs != nil && IsBundlePath(fs, urlArg) {
+		absUrl, err := getAbsBundleUrl(fs, urlArg)
+		if err != nil {
+			return "", "", err
+		}
+		return dbfactory.BundleScheme, absUrl, nil
+	}
+
 	u, err := earl.Parse(urlArg)
 	if err != nil {
 		return "", "", err
 	}
 
 	if u.Scheme != "" && fs != nil {
+		if u.Scheme == dbfactory.BundleScheme || (u.Scheme == dbfactory.FileScheme && IsBundlePath(fs, u.Host+u.Path)) {
+			absUrl, err := getAbsBundleUrl(fs, u.Host+u.Path)
+			if err != nil {
+				return "", "", err
+			}
+			return dbfactory.BundleScheme, absUrl, nil
+		}
+
 		if u.Scheme == dbfactory.FileScheme || u.Scheme == dbfactory.LocalBSScheme {
 			absUrl, err := getAbsFileRemoteUrl(u, fs)
 
@@ -708,6 +724,45 @@ func getAbsFileRemoteUrl(u *url.URL, fs filesys2.Filesys) (string, error) {
 	return scheme + "://" + urlStr, nil
 }
 
+// IsBundlePath returns whether |path| names a regular file with the extension of bundle files. Other files are only
+// used as bundles with the bundle url scheme.
+func IsBundlePath(fs filesys2.Filesys, path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), dbfactory.BundleExt) {
+		return false
+	}
+	exists, isDir := fs.Exists(filepath.Clean(path))
+	return exists && !isDir
+}
+
+func getAbsBundleUrl(fs filesys2.Filesys, bundlePath string) (string, error) {
+	absPath, err := fs.Abs(filepath.Clean(bundlePath))
+	if err != nil {
+		return "", err
+	}
+
+	exists, isDir := fs.Exists(absPath)
+	if !exists {
+		return "", fmt.Errorf("bundle file '%s' does not exist", bundlePath)
+	} else if isDir {
+		return "", fmt.Errorf("'%s' is a directory, not a bundle file", bundlePath)
+	}
+
+	return dbfactory.BundleScheme + "://" + filepath.ToSlash(absPath), nil
+}
+
+// RemoteForBundle returns an unconfigured remote for fetching from the bundle file at |bundlePath|. The remote is
+// named after the file, so its branches are fetched into remotes/<name>/*.
+func RemoteForBundle(fs filesys2.Filesys, bundlePath string) (Remote, error) {
+	absUrl, err := getAbsBundleUrl(fs, bundlePath)
+	if err != nil {
+		return NoRemote, err
+	}
+
+	name := filepath.Base(bundlePath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return NewRemote(name, absUrl, nil), nil
+}
+
 // GetDefaultBranch returns the default branch from among the branches given, returning
 // the configs default config branch first, then init branch main, then the old init branch master,
 // and finally the first lexicographical branch if none of the others are found
@@ -738,6 +738,10 @@ func newUniqIndex(ctx *sql.Context, sch schema.Schema, tableName string, def sch
 type collisionFn func(key, value val.Tuple) error
 
 func (idx uniqIndex) insertRow(ctx context.Context, key, value val.Tuple) error {
+	if ok, err := idx.secondaryBld.IncludesRow(ctx, key, value); err != nil || !ok {
+		return err
+	}
+
 	secondaryIndexKey, err := idx.secondaryBld.SecondaryKeyFromRow(ctx, key, value)
 	if err != nil {
 		return err
@@ -753,10 +757,16 @@ func (idx uniqIndex) removeRow(ctx context.Context, key, value val.Tuple) error
 		return err
 	}
 
-	err = idx.secondary.Delete(ctx, secondaryIndexKey)
+	included, err := idx.secondaryBld.IncludesRow(ctx, key, value)
 	if err != nil {
 		return err
 	}
+	if included {
+		err = idx.secondary.Delete(ctx, secondaryIndexKey)
+		if err != nil {
+			return err
+		}
+	}
 
 	clusteredIndexKey := idx.clusteredBld.ClusteredKeyFromIndexKey(secondaryIndexKey)
 	return idx.clustered.Delete(ctx, clusteredIndexKey)
@@ -766,6 +776,10 @@ func (idx uniqIndex) removeRow(ctx context.Context, key, value val.Tuple) error
 // included in the unique constraint. For any matching row, the specified callback, |cb|, is invoked with the key
 // and value for the primary index, representing the conflicting row identified from the unique index.
 func (idx uniqIndex) findCollisions(ctx context.Context, key, value val.Tuple, cb collisionFn) error {
+	if ok, err := idx.secondaryBld.IncludesRow(ctx, key, value); err != nil || !ok {
+		return err // rows outside of a partial index cannot cause unique violations
+	}
+
 	indexKey, err := idx.secondaryBld.SecondaryKeyFromRow(ctx, key, value)
 	if err != nil {
 		return err
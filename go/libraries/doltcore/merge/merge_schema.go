@@ -47,6 +47,8 @@ var ErrUnmergeableNewColumn = errorkinds.NewKind("Unable to merge new column `%s
 
 var ErrDefaultCollationConflict = errorkinds.NewKind("Unable to merge table '%s', because its default collation setting has changed on both sides of the merge. Manually change the table's default collation setting on one of the sides of the merge and retry this merge.")
 
+var ErrTTLConflict = errorkinds.NewKind("Unable to merge table '%s', because its TTL has changed on both sides of the merge. Manually change the table's TTL on one of the sides of the merge and retry this merge.")
+
 type SchemaConflict struct {
 	TableName            string
 	ColConflicts         []ColConflict
@@ -204,6 +206,11 @@ func SchemaMerge(ctx context.Context, format *storetypes.NomsBinFormat, ourSch,
 		return nil, sc, mergeInfo, diffInfo, err
 	}
 
+	sch, err = mergeTableTTL(tblName, ancSch, ourSch, theirSch, sch)
+	if err != nil {
+		return nil, sc, mergeInfo, diffInfo, err
+	}
+
 	// TODO: Merge conflict should have blocked any primary key ordinal changes
 	err = sch.SetPkOrdinals(ourSch.GetPkOrdinals())
 	if err != nil {
@@ -777,7 +784,7 @@ func indexesInCommon(mergedCC *schema.ColCollection, ours, theirs, anc schema.In
 			// if column doesn't exist anymore, drop index
 			// however, it shouldn't be possible for an index
 			// over a dropped column to exist in the intersection
-			if _, ok := mergedCC.GetByTag(t); !ok {
+			if _, ok := mergedCC.GetByTag(t); !ok && !schema.IsExpressionTag(t) {
 				return false, nil
 			}
 		}
@@ -891,7 +898,7 @@ func indexCollSetDifference(left, right schema.IndexCollection, cc *schema.ColCo
 		idxTags := idx.IndexedColumnTags()
 		for _, t := range idxTags {
 			// if column doesn't exist anymore, drop index
-			if _, ok := cc.GetByTag(t); !ok {
+			if _, ok := cc.GetByTag(t); !ok && !schema.IsExpressionTag(t) {
 				return false, nil
 			}
 		}
@@ -1185,6 +1192,28 @@ func mergeTableCollation(_ context.Context, tblName string, ancSch, ourSch, thei
 	return mergedSch, nil
 }
 
+// mergeTableTTL sets the TTL of |mergedSch| to the TTL of |ourSch|, or to the TTL of |theirSch| if only their side
+// changed it from |ancSch|. If the TTL was changed on both sides of the merge (to different TTLs), then an error is
+// returned.
+func mergeTableTTL(tblName string, ancSch, ourSch, theirSch, mergedSch schema.Schema) (schema.Schema, error) {
+	ancTTL := ""
+	if ancSch != nil {
+		ancTTL = ancSch.GetTTL()
+	}
+	ourTTLChanged := ancTTL != ourSch.GetTTL()
+	theirTTLChanged := ancTTL != theirSch.GetTTL()
+
+	if ourTTLChanged && theirTTLChanged && ourSch.GetTTL() != theirSch.GetTTL() {
+		return nil, ErrTTLConflict.New(tblName)
+	}
+	mergedSch.SetTTL(ourSch.GetTTL())
+	if theirTTLChanged {
+		mergedSch.SetTTL(theirSch.GetTTL())
+	}
+
+	return mergedSch, nil
+}
+
 // mergeChecks attempts to combine ourChks, theirChks, and ancChks into a single collection, or gathers the conflicts
 func mergeChecks(ctx context.Context, ourChks, theirChks, ancChks schema.CheckCollection) ([]schema.Check, []ChkConflict, error) {
 	// Handles modifications
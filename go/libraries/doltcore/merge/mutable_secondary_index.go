@@ -124,6 +124,10 @@ func NewMutableSecondaryIdx(ctx *sql.Context, idx prolly.Map, sch schema.Schema,
 // InsertEntry inserts a secondary index entry given the key and new value
 // of the primary row.
 func (m MutableSecondaryIdx) InsertEntry(ctx context.Context, key, newValue val.Tuple) error {
+	if ok, err := m.builder.IncludesRow(ctx, key, newValue); err != nil || !ok {
+		return err
+	}
+
 	newKey, err := m.builder.SecondaryKeyFromRow(ctx, key, newValue)
 	if err != nil {
 		return err
@@ -140,25 +144,19 @@ func (m MutableSecondaryIdx) InsertEntry(ctx context.Context, key, newValue val.
 // UpdateEntry modifies the corresponding secondary index entry given the key
 // and curr/new values of the primary row.
 func (m MutableSecondaryIdx) UpdateEntry(ctx context.Context, key, currValue, newValue val.Tuple) error {
-	currKey, err := m.builder.SecondaryKeyFromRow(ctx, key, currValue)
-	if err != nil {
-		return err
-	}
-
-	newKey, err := m.builder.SecondaryKeyFromRow(ctx, key, newValue)
-	if err != nil {
-		return err
-	}
-
-	err = m.mut.Delete(ctx, currKey)
-	if err != nil {
+	// a row of a partial index can move in or out of the index
+	if err := m.DeleteEntry(ctx, key, currValue); err != nil {
 		return err
 	}
-	return m.mut.Put(ctx, newKey, val.EmptyTuple)
+	return m.InsertEntry(ctx, key, newValue)
 }
 
 // DeleteEntry deletes a secondary index entry given they key and value of the primary row.
 func (m MutableSecondaryIdx) DeleteEntry(ctx context.Context, key val.Tuple, value val.Tuple) error {
+	if ok, err := m.builder.IncludesRow(ctx, key, value); err != nil || !ok {
+		return err
+	}
+
 	currKey, err := m.builder.SecondaryKeyFromRow(ctx, key, value)
 	if err != nil {
 		return err
@@ -35,11 +35,13 @@ func makeUniqViolMeta(sch schema.Schema, idx schema.Index) (UniqCVMeta, error) {
 	idxTags := idx.IndexedColumnTags()
 	colNames := make([]string, len(idxTags))
 	for i, tag := range idxTags {
-		if col, ok := schCols.TagToCol[tag]; !ok {
+		if col, ok := schCols.TagToCol[tag]; ok {
+			colNames[i] = col.Name
+		} else if col, ok := idx.GetColumn(tag); ok && schema.IsExpressionTag(tag) {
+			colNames[i] = col.Name
+		} else {
 			return UniqCVMeta{}, fmt.Errorf("unique key '%s' references tag '%d' on table but it cannot be found",
 				idx.Name(), tag)
-		} else {
-			colNames[i] = col.Name
 		}
 	}
 
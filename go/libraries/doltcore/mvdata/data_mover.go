@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/dolthub/dolt/go/cmd/dolt/commands/engine"
 	"github.com/dolthub/dolt/go/cmd/dolt/errhand"
@@ -78,6 +79,7 @@ const (
 )
 
 var ErrProvidedPkNotFound = errors.New("provided primary key not found")
+var ErrNoPkInferred = errors.New("no primary key could be inferred")
 
 type DataMoverCreationError struct {
 	ErrType DataMoverCreationErrType
@@ -132,6 +134,51 @@ func InferSchema(ctx context.Context, root doltdb.RootValue, rd table.ReadCloser
 		return nil, err
 	}
 
+	return schemaFromInferredCols(ctx, root, tableName, infCols, pks)
+}
+
+// InferSchemaWithConstraints profiles up to |sampleRows| rows of |rd| using actions.InferSchemaFromTableReader and
+// returns the resulting schema, including a unique index for each inferred unique key, along with the inference
+// details. If |pks| is empty the inferred primary key is used, and an error is returned if no primary key could be
+// inferred.
+func InferSchemaWithConstraints(ctx context.Context, root doltdb.RootValue, rd table.ReadCloser, tableName string, pks []string, args actions.InferenceArgs, sampleRows int) (schema.Schema, *actions.InferredSchema, error) {
+	inferred, err := actions.InferSchemaFromTableReader(ctx, rd, args, sampleRows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(pks) == 0 {
+		if len(inferred.PrimaryKey) == 0 {
+			return nil, nil, fmt.Errorf("%w: %s", ErrNoPkInferred, inferred.PrimaryKeyReason)
+		}
+		pks = inferred.PrimaryKey
+	}
+
+	sch, err := schemaFromInferredCols(ctx, root, tableName, inferred.ColCollection(), pks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkSet := set.NewStrSet(pks)
+	for _, uk := range inferred.UniqueKeys {
+		if len(uk.Columns) == 1 && pkSet.Contains(uk.Columns[0]) {
+			// a provided primary key is already unique
+			continue
+		}
+		_, err = sch.Indexes().AddIndexByColNames(strings.Join(uk.Columns, "_"), uk.Columns, nil, schema.IndexProperties{
+			IsUnique:      true,
+			IsUserDefined: true,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return sch, inferred, nil
+}
+
+func schemaFromInferredCols(ctx context.Context, root doltdb.RootValue, tableName string, infCols *schema.ColCollection, pks []string) (schema.Schema, error) {
+	var err error
 	pkSet := set.NewStrSet(pks)
 	newCols := schema.MapColCollection(infCols, func(col schema.Column) schema.Column {
 		col.IsPartOfPK = pkSet.Contains(col.Name)
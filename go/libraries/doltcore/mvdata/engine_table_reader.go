@@ -66,6 +66,18 @@ func NewSqlEngineReader(ctx context.Context, dEnv *env.DoltEnv, tableName string
 	}
 	sqlCtx.SetCurrentDatabase(mrEnv.GetFirstDatabase())
 
+	root, err := dEnv.WorkingRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSqlEngineReaderForUser(sqlCtx, se, root, tableName)
+}
+
+// NewSqlEngineReaderForUser returns a reader of the rows of the table |tableName| of the current database of |sqlCtx|,
+// which reads them with |se| as the user of |sqlCtx|. The rows are those the user reads with a query of the table, so
+// they are filtered by the row-level security policies and masked by the column masks which apply to the user.
+func NewSqlEngineReaderForUser(sqlCtx *sql.Context, se *engine.SqlEngine, root doltdb.RootValue, tableName string) (*sqlEngineTableReader, error) {
 	sqlEngine := se.GetUnderlyingEngine()
 	binder := planbuilder.New(sqlCtx, sqlEngine.Analyzer.Catalog, sqlEngine.Parser)
 	ret, _, _, err := binder.Parse(fmt.Sprintf("show create table `%s`", tableName), false)
@@ -83,12 +95,7 @@ func NewSqlEngineReader(ctx context.Context, dEnv *env.DoltEnv, tableName string
 		return nil, err
 	}
 
-	root, err := dEnv.WorkingRoot(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	doltSchema, err := sqlutil.ToDoltSchema(ctx, root, tableName, create.PrimaryKeySchema, nil, sql.Collation_Default)
+	doltSchema, err := sqlutil.ToDoltSchema(sqlCtx, root, tableName, create.PrimaryKeySchema, nil, sql.Collation_Default)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +130,21 @@ func NewSqlEngineTableReaderWithEngine(sqlCtx *sql.Context, se *sqle.Engine, db
 	}, nil
 }
 
+// NewSqlQueryReader returns a reader of the rows of |iter|, the rows of a query of all the columns of a table with the
+// schema |sch|, whose values are strings, as they are in the results of a query of a running server. The values are
+// converted to the types of the columns of |sch|, except the values of string columns, which are kept as they are: a
+// masked value need not fit the type of its column.
+func NewSqlQueryReader(sqlCtx *sql.Context, sch schema.Schema, iter sql.RowIter) *sqlQueryTableReader {
+	cols := sch.GetAllCols().GetColumns()
+	types := make([]sql.Type, len(cols))
+	for i, col := range cols {
+		if _, ok := col.TypeInfo.ToSqlType().(sql.StringType); !ok {
+			types[i] = col.TypeInfo.ToSqlType()
+		}
+	}
+	return &sqlQueryTableReader{sqlCtx: sqlCtx, sch: sch, types: types, iter: iter}
+}
+
 func (s *sqlEngineTableReader) GetSchema() schema.Schema {
 	return s.sch
 }
@@ -143,3 +165,42 @@ func (s *sqlEngineTableReader) ReadSqlRow(ctx context.Context) (sql.Row, error)
 func (s *sqlEngineTableReader) Close(ctx context.Context) error {
 	return s.iter.Close(s.sqlCtx)
 }
+
+type sqlQueryTableReader struct {
+	sqlCtx *sql.Context
+
+	sch   schema.Schema
+	types []sql.Type
+	iter  sql.RowIter
+}
+
+func (s *sqlQueryTableReader) GetSchema() schema.Schema {
+	return s.sch
+}
+
+func (s *sqlQueryTableReader) ReadRow(ctx context.Context) (row.Row, error) {
+	panic("deprecated")
+}
+
+func (s *sqlQueryTableReader) ReadSqlRow(ctx context.Context) (sql.Row, error) {
+	next, err := s.iter.Next(s.sqlCtx)
+	if err != nil {
+		return nil, err
+	}
+	if len(next) != len(s.types) {
+		return nil, fmt.Errorf("expected rows of %d columns, found %d", len(s.types), len(next))
+	}
+	for i, v := range next {
+		if v == nil || s.types[i] == nil {
+			continue
+		}
+		if next[i], _, err = s.types[i].Convert(v); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+func (s *sqlQueryTableReader) Close(ctx context.Context) error {
+	return s.iter.Close(s.sqlCtx)
+}
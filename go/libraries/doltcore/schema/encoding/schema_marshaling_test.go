@@ -279,6 +279,60 @@ func TestSchemaMarshalling(t *testing.T) {
 	}
 }
 
+func TestPartialIndexMarshalling(t *testing.T) {
+	if !types.Format_Default.UsesFlatbuffers() {
+		t.Skip("partial indexes are only supported by the flatbuffers schema encoding")
+	}
+	ctx := context.Background()
+	nbf := types.Format_Default
+	vrw := getTestVRW(nbf)
+
+	sch := createTestSchema()
+	_, err := sch.Indexes().AddIndexByColTags("idx_last", []uint64{2}, nil, schema.IndexProperties{
+		IsUserDefined: true,
+		Predicate:     "age >= 18",
+	})
+	require.NoError(t, err)
+
+	v, err := MarshalSchema(ctx, vrw, sch)
+	require.NoError(t, err)
+	s, err := UnmarshalSchema(ctx, nbf, v)
+	require.NoError(t, err)
+	assert.Equal(t, sch, s)
+	assert.Equal(t, "age >= 18", s.Indexes().GetByName("idx_last").Predicate())
+	assert.Equal(t, "", s.Indexes().GetByName("idx_age").Predicate())
+}
+
+func TestExpressionIndexMarshalling(t *testing.T) {
+	if !types.Format_Default.UsesFlatbuffers() {
+		t.Skip("expression indexes are only supported by the flatbuffers schema encoding")
+	}
+	ctx := context.Background()
+	nbf := types.Format_Default
+	vrw := getTestVRW(nbf)
+
+	sch := createTestSchema()
+	lower := schema.NewExpressionColumn("lower(last)", typeinfo.StringDefaultType)
+	_, err := sch.Indexes().AddIndexByColTags("idx_lower_last", []uint64{lower.Tag, 3}, nil, schema.IndexProperties{
+		IsUserDefined: true,
+		Expressions:   []schema.Column{lower},
+	})
+	require.NoError(t, err)
+
+	v, err := MarshalSchema(ctx, vrw, sch)
+	require.NoError(t, err)
+	s, err := UnmarshalSchema(ctx, nbf, v)
+	require.NoError(t, err)
+	assert.Equal(t, sch, s)
+	idx := s.Indexes().GetByName("idx_lower_last")
+	assert.Equal(t, []string{"lower(last)", "age"}, idx.ColumnNames())
+	assert.Equal(t, []uint64{lower.Tag, 3, 4}, idx.AllTags())
+	col, ok := idx.GetColumn(lower.Tag)
+	require.True(t, ok)
+	assert.Equal(t, lower, col)
+	assert.Empty(t, s.Indexes().GetByName("idx_age").Expressions())
+}
+
 func getTypeinfo(t *testing.T) (ti []typeinfo.TypeInfo) {
 	st := getSqlTypes()
 	ti = make([]typeinfo.TypeInfo, len(st))
@@ -57,6 +57,7 @@ func serializeSchemaAsFlatbuffer(sch schema.Schema) ([]byte, error) {
 	indexes := serializeSecondaryIndexes(b, sch, sch.Indexes().AllIndexes())
 	checks := serializeChecks(b, sch.Checks().AllChecks())
 	comment := b.CreateString(sch.GetComment())
+	ttl := b.CreateString(sch.GetTTL())
 
 	var hasFeaturesAfterTryAccessors bool
 	for _, col := range sch.GetAllCols().GetColumns() {
@@ -65,6 +66,12 @@ func serializeSchemaAsFlatbuffer(sch schema.Schema) ([]byte, error) {
 			break
 		}
 	}
+	for _, idx := range sch.Indexes().AllIndexes() {
+		if len(idx.Expressions()) > 0 {
+			hasFeaturesAfterTryAccessors = true
+			break
+		}
+	}
 
 	serial.TableSchemaStart(b)
 	serial.TableSchemaAddClusteredIndex(b, rows)
@@ -76,6 +83,10 @@ func serializeSchemaAsFlatbuffer(sch schema.Schema) ([]byte, error) {
 		serial.TableSchemaAddComment(b, comment)
 		hasFeaturesAfterTryAccessors = true
 	}
+	if sch.GetTTL() != "" {
+		serial.TableSchemaAddTtl(b, ttl)
+		hasFeaturesAfterTryAccessors = true
+	}
 	if hasFeaturesAfterTryAccessors {
 		serial.TableSchemaAddHasFeaturesAfterTryAccessors(b, hasFeaturesAfterTryAccessors)
 	}
@@ -129,6 +140,7 @@ func deserializeSchemaFromFlatbuffer(ctx context.Context, buf []byte) (schema.Sc
 
 	sch.SetCollation(schema.Collation(s.Collation()))
 	sch.SetComment(string(s.Comment()))
+	sch.SetTTL(string(s.Ttl()))
 
 	return sch, nil
 }
@@ -231,45 +243,8 @@ func serializeSchemaColumns(b *fb.Builder, sch schema.Schema) fb.UOffsetT {
 
 	// serialize columns in |cols|
 	for i := len(cols) - 1; i >= 0; i-- {
-		col := cols[i]
-		var defVal, onUpdateVal string
-		if col.Default != "" {
-			defVal = col.Default
-		} else {
-			defVal = col.Generated
-		}
-
-		if col.OnUpdate != "" {
-			onUpdateVal = col.OnUpdate
-		}
-
-		co := b.CreateString(col.Comment)
-		do := b.CreateString(defVal)
-		ou := b.CreateString(onUpdateVal)
-
-		typeString := sqlTypeString(col.TypeInfo)
-		to := b.CreateString(typeString)
-		no := b.CreateString(col.Name)
-
-		serial.ColumnStart(b)
-		serial.ColumnAddName(b, no)
-		serial.ColumnAddSqlType(b, to)
-		serial.ColumnAddDefaultValue(b, do)
-		serial.ColumnAddComment(b, co)
 		// schema.Schema determines display order
-		serial.ColumnAddDisplayOrder(b, int16(i))
-		serial.ColumnAddTag(b, col.Tag)
-		serial.ColumnAddEncoding(b, encodingFromTypeinfo(col.TypeInfo))
-		serial.ColumnAddPrimaryKey(b, col.IsPartOfPK)
-		serial.ColumnAddAutoIncrement(b, col.AutoIncrement)
-		serial.ColumnAddNullable(b, col.IsNullable())
-		serial.ColumnAddGenerated(b, col.Generated != "")
-		serial.ColumnAddVirtual(b, col.Virtual)
-		if onUpdateVal != "" {
-			serial.ColumnAddOnUpdateValue(b, ou)
-		}
-		serial.ColumnAddHidden(b, false)
-		offs[i] = serial.ColumnEnd(b)
+		offs[i] = serializeColumn(b, cols[i], int16(i))
 	}
 
 	// create the columns array with all columns
@@ -280,6 +255,46 @@ func serializeSchemaColumns(b *fb.Builder, sch schema.Schema) fb.UOffsetT {
 	return b.EndVector(len(offs))
 }
 
+func serializeColumn(b *fb.Builder, col schema.Column, displayOrder int16) fb.UOffsetT {
+	var defVal, onUpdateVal string
+	if col.Default != "" {
+		defVal = col.Default
+	} else {
+		defVal = col.Generated
+	}
+
+	if col.OnUpdate != "" {
+		onUpdateVal = col.OnUpdate
+	}
+
+	co := b.CreateString(col.Comment)
+	do := b.CreateString(defVal)
+	ou := b.CreateString(onUpdateVal)
+
+	typeString := sqlTypeString(col.TypeInfo)
+	to := b.CreateString(typeString)
+	no := b.CreateString(col.Name)
+
+	serial.ColumnStart(b)
+	serial.ColumnAddName(b, no)
+	serial.ColumnAddSqlType(b, to)
+	serial.ColumnAddDefaultValue(b, do)
+	serial.ColumnAddComment(b, co)
+	serial.ColumnAddDisplayOrder(b, displayOrder)
+	serial.ColumnAddTag(b, col.Tag)
+	serial.ColumnAddEncoding(b, encodingFromTypeinfo(col.TypeInfo))
+	serial.ColumnAddPrimaryKey(b, col.IsPartOfPK)
+	serial.ColumnAddAutoIncrement(b, col.AutoIncrement)
+	serial.ColumnAddNullable(b, col.IsNullable())
+	serial.ColumnAddGenerated(b, col.Generated != "")
+	serial.ColumnAddVirtual(b, col.Virtual)
+	if onUpdateVal != "" {
+		serial.ColumnAddOnUpdateValue(b, ou)
+	}
+	serial.ColumnAddHidden(b, false)
+	return serial.ColumnEnd(b)
+}
+
 func serializeHiddenKeylessColumns(b *fb.Builder) (id, card fb.UOffsetT) {
 	// cardinality column
 	no := b.CreateString(keylessCardCol)
@@ -340,56 +355,78 @@ func deserializeColumns(ctx context.Context, s *serial.TableSchema) ([]schema.Co
 		if err != nil {
 			return nil, err
 		}
-		sqlType, err := typeinfoFromSqlType(string(c.SqlType()))
+		cols[i], err = deserializeColumn(&c)
 		if err != nil {
 			return nil, err
 		}
+	}
+	return cols, nil
+}
 
-		var defVal, generatedVal, onUpdateVal string
-		if c.DefaultValue() != nil {
-			if c.Generated() {
-				generatedVal = string(c.DefaultValue())
-			} else {
-				defVal = string(c.DefaultValue())
-			}
-		}
+func deserializeColumn(c *serial.Column) (schema.Column, error) {
+	sqlType, err := typeinfoFromSqlType(string(c.SqlType()))
+	if err != nil {
+		return schema.Column{}, err
+	}
 
-		if c.OnUpdateValue() != nil {
-			onUpdateVal = string(c.OnUpdateValue())
+	var defVal, generatedVal, onUpdateVal string
+	if c.DefaultValue() != nil {
+		if c.Generated() {
+			generatedVal = string(c.DefaultValue())
+		} else {
+			defVal = string(c.DefaultValue())
 		}
+	}
 
-		cols[i] = schema.Column{
-			Name:          string(c.Name()),
-			Tag:           c.Tag(),
-			Kind:          sqlType.NomsKind(),
-			IsPartOfPK:    c.PrimaryKey(),
-			TypeInfo:      sqlType,
-			Default:       defVal,
-			Generated:     generatedVal,
-			OnUpdate:      onUpdateVal,
-			Virtual:       c.Virtual(),
-			AutoIncrement: c.AutoIncrement(),
-			Comment:       string(c.Comment()),
-			Constraints:   constraintsFromSerialColumn(&c),
-		}
+	if c.OnUpdateValue() != nil {
+		onUpdateVal = string(c.OnUpdateValue())
 	}
-	return cols, nil
+
+	return schema.Column{
+		Name:          string(c.Name()),
+		Tag:           c.Tag(),
+		Kind:          sqlType.NomsKind(),
+		IsPartOfPK:    c.PrimaryKey(),
+		TypeInfo:      sqlType,
+		Default:       defVal,
+		Generated:     generatedVal,
+		OnUpdate:      onUpdateVal,
+		Virtual:       c.Virtual(),
+		AutoIncrement: c.AutoIncrement(),
+		Comment:       string(c.Comment()),
+		Constraints:   constraintsFromSerialColumn(c),
+	}, nil
 }
 
 func serializeSecondaryIndexes(b *fb.Builder, sch schema.Schema, indexes []schema.Index) fb.UOffsetT {
 	ordinalMap := sch.GetAllCols().TagToIdx
+	// expression columns are referred to by their position
+	// past the end of the serialized columns vector
+	numCols := sch.GetAllCols().Size()
+	if schema.IsKeyless(sch) {
+		numCols += 2
+	}
 	offs := make([]fb.UOffsetT, len(indexes))
 	for i := len(offs) - 1; i >= 0; i-- {
 		idx := indexes[i]
 		no := b.CreateString(idx.Name())
 		co := b.CreateString(idx.Comment())
 
+		exprs := idx.Expressions()
+		position := func(tag uint64) uint16 {
+			for j, expr := range exprs {
+				if expr.Tag == tag {
+					return uint16(numCols + j)
+				}
+			}
+			return uint16(ordinalMap[tag])
+		}
+
 		// serialize indexed columns
 		tags := idx.IndexedColumnTags()
 		serial.IndexStartIndexColumnsVector(b, len(tags))
 		for j := len(tags) - 1; j >= 0; j-- {
-			pos := ordinalMap[tags[j]]
-			b.PrependUint16(uint16(pos))
+			b.PrependUint16(position(tags[j]))
 		}
 		ico := b.EndVector(len(tags))
 
@@ -397,8 +434,7 @@ func serializeSecondaryIndexes(b *fb.Builder, sch schema.Schema, indexes []schem
 		tags = idx.AllTags()
 		serial.IndexStartKeyColumnsVector(b, len(tags))
 		for j := len(tags) - 1; j >= 0; j-- {
-			pos := ordinalMap[tags[j]]
-			b.PrependUint16(uint16(pos))
+			b.PrependUint16(position(tags[j]))
 		}
 		ko := b.EndVector(len(tags))
 
@@ -415,6 +451,24 @@ func serializeSecondaryIndexes(b *fb.Builder, sch schema.Schema, indexes []schem
 			ftInfo = serializeFullTextInfo(b, idx)
 		}
 
+		var pro fb.UOffsetT
+		if idx.Predicate() != "" {
+			pro = b.CreateString(idx.Predicate())
+		}
+
+		var eco fb.UOffsetT
+		if len(exprs) > 0 {
+			exprOffs := make([]fb.UOffsetT, len(exprs))
+			for j := len(exprs) - 1; j >= 0; j-- {
+				exprOffs[j] = serializeColumn(b, exprs[j], int16(-1))
+			}
+			serial.IndexStartExpressionColumnsVector(b, len(exprs))
+			for j := len(exprOffs) - 1; j >= 0; j-- {
+				b.PrependUOffsetT(exprOffs[j])
+			}
+			eco = b.EndVector(len(exprs))
+		}
+
 		serial.IndexStart(b)
 		serial.IndexAddName(b, no)
 		serial.IndexAddComment(b, co)
@@ -429,6 +483,12 @@ func serializeSecondaryIndexes(b *fb.Builder, sch schema.Schema, indexes []schem
 		if idx.IsFullText() {
 			serial.IndexAddFulltextInfo(b, ftInfo)
 		}
+		if idx.Predicate() != "" {
+			serial.IndexAddPredicate(b, pro)
+		}
+		if len(exprs) > 0 {
+			serial.IndexAddExpressionColumns(b, eco)
+		}
 		offs[i] = serial.IndexEnd(b)
 	}
 
@@ -461,13 +521,31 @@ func deserializeSecondaryIndexes(sch schema.Schema, s *serial.TableSchema) error
 			IsFullText:         idx.FulltextKey(),
 			IsUserDefined:      !idx.SystemDefined(),
 			Comment:            string(idx.Comment()),
+			Predicate:          string(idx.Predicate()),
 			FullTextProperties: fti,
 		}
 
+		if idx.ExpressionColumnsLength() > 0 {
+			props.Expressions = make([]schema.Column, idx.ExpressionColumnsLength())
+			for j := range props.Expressions {
+				if _, err := idx.TryExpressionColumns(&col, j); err != nil {
+					return err
+				}
+				if props.Expressions[j], err = deserializeColumn(&col); err != nil {
+					return err
+				}
+			}
+		}
+
 		tags := make([]uint64, idx.IndexColumnsLength())
 		for j := range tags {
-			pos := idx.IndexColumns(j)
-			_, err := s.TryColumns(&col, int(pos))
+			pos := int(idx.IndexColumns(j))
+			if pos >= s.ColumnsLength() {
+				// the key part is an expression
+				tags[j] = props.Expressions[pos-s.ColumnsLength()].Tag
+				continue
+			}
+			_, err := s.TryColumns(&col, pos)
 			if err != nil {
 				return err
 			}
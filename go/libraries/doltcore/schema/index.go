@@ -62,6 +62,12 @@ type Index interface {
 	PrefixLengths() []uint16
 	// FullTextProperties returns all properties belonging to a Full-Text index.
 	FullTextProperties() FullTextProperties
+	// Predicate returns the SQL expression which rows must satisfy to be included in a partial index. It is empty for
+	// indexes which include every row of the table.
+	Predicate() string
+	// Expressions returns the key parts of the index which are expressions over the columns of the table rather than
+	// columns, see NewExpressionColumn.
+	Expressions() []Column
 }
 
 var _ Index = (*indexImpl)(nil)
@@ -78,6 +84,8 @@ type indexImpl struct {
 	comment       string
 	prefixLengths []uint16
 	fullTextProps FullTextProperties
+	predicate     string
+	expressions   []Column
 }
 
 func NewIndex(name string, tags, allTags []uint64, indexColl IndexCollection, props IndexProperties) Index {
@@ -97,6 +105,8 @@ func NewIndex(name string, tags, allTags []uint64, indexColl IndexCollection, pr
 		isUserDefined: props.IsUserDefined,
 		comment:       props.Comment,
 		fullTextProps: props.FullTextProperties,
+		predicate:     props.Predicate,
+		expressions:   props.Expressions,
 	}
 }
 
@@ -109,7 +119,8 @@ func (ix *indexImpl) AllTags() []uint64 {
 func (ix *indexImpl) ColumnNames() []string {
 	colNames := make([]string, len(ix.tags))
 	for i, tag := range ix.tags {
-		colNames[i] = ix.indexColl.colColl.TagToCol[tag].Name
+		col, _ := ix.GetColumn(tag)
+		colNames[i] = col.Name
 	}
 	return colNames
 }
@@ -143,6 +154,7 @@ func (ix *indexImpl) Equals(other Index) bool {
 		ix.IsSpatial() == other.IsSpatial() &&
 		compareUint16Slices(ix.PrefixLengths(), other.PrefixLengths()) &&
 		ix.Comment() == other.Comment() &&
+		ix.Predicate() == other.Predicate() &&
 		ix.Name() == other.Name()
 }
 
@@ -165,6 +177,7 @@ func (ix *indexImpl) DeepEquals(other Index) bool {
 		ix.IsSpatial() == other.IsSpatial() &&
 		compareUint16Slices(ix.PrefixLengths(), other.PrefixLengths()) &&
 		ix.Comment() == other.Comment() &&
+		ix.Predicate() == other.Predicate() &&
 		ix.Name() == other.Name()
 }
 
@@ -184,8 +197,13 @@ func compareUint16Slices(a, b []uint16) bool {
 	return true
 }
 
-// GetColumn implements Index.
+// GetColumn implements Index. The columns of key parts which are expressions are not columns of the table.
 func (ix *indexImpl) GetColumn(tag uint64) (Column, bool) {
+	for _, col := range ix.expressions {
+		if col.Tag == tag {
+			return col, true
+		}
+	}
 	return ix.indexColl.colColl.GetByTag(tag)
 }
 
@@ -229,7 +247,7 @@ func (ix *indexImpl) Schema() Schema {
 	contentHashedFields := make([]uint64, 0)
 	cols := make([]Column, len(ix.allTags))
 	for i, tag := range ix.allTags {
-		col := ix.indexColl.colColl.TagToCol[tag]
+		col, _ := ix.GetColumn(tag)
 		cols[i] = Column{
 			Name:        col.Name,
 			Tag:         tag,
@@ -309,6 +327,16 @@ func (ix *indexImpl) FullTextProperties() FullTextProperties {
 	return ix.fullTextProps
 }
 
+// Predicate implements Index.
+func (ix *indexImpl) Predicate() string {
+	return ix.predicate
+}
+
+// Expressions implements Index.
+func (ix *indexImpl) Expressions() []Column {
+	return ix.expressions
+}
+
 // copy returns an exact copy of the calling index.
 func (ix *indexImpl) copy() *indexImpl {
 	newIx := *ix
@@ -320,6 +348,10 @@ func (ix *indexImpl) copy() *indexImpl {
 		newIx.prefixLengths = make([]uint16, len(ix.prefixLengths))
 		_ = copy(newIx.prefixLengths, ix.prefixLengths)
 	}
+	if len(ix.expressions) > 0 {
+		newIx.expressions = make([]Column, len(ix.expressions))
+		_ = copy(newIx.expressions, ix.expressions)
+	}
 	if len(newIx.fullTextProps.KeyPositions) > 0 {
 		newIx.fullTextProps.KeyPositions = make([]uint16, len(ix.fullTextProps.KeyPositions))
 		_ = copy(newIx.fullTextProps.KeyPositions, ix.fullTextProps.KeyPositions)
@@ -24,7 +24,8 @@ type IndexCollection interface {
 	// AddIndex adds the given index, overwriting any current indexes with the same name or columns.
 	// It does not perform any kind of checking, and is intended for schema modifications.
 	AddIndex(indexes ...Index)
-	// AddIndexByColNames adds an index with the given name and columns (in index order).
+	// AddIndexByColNames adds an index with the given name and columns (in index order). The names of the key parts
+	// which are expressions are the names of their columns in |props|.
 	AddIndexByColNames(indexName string, cols []string, prefixLengths []uint16, props IndexProperties) (Index, error)
 	// AddIndexByColTags adds an index with the given name and column tags (in index order).
 	AddIndexByColTags(indexName string, tags []uint64, prefixLengths []uint16, props IndexProperties) (Index, error)
@@ -82,6 +83,11 @@ type IndexProperties struct {
 	IsFullText    bool
 	IsUserDefined bool
 	Comment       string
+	// Predicate is the SQL expression which rows must satisfy to be included in the index, or empty if the index
+	// includes every row.
+	Predicate string
+	// Expressions are the columns of the key parts of the index which are expressions, see NewExpressionColumn.
+	Expressions []Column
 	FullTextProperties
 }
 
@@ -185,7 +191,7 @@ func (ixc *indexCollectionImpl) AddIndex(indexes ...Index) {
 }
 
 func (ixc *indexCollectionImpl) AddIndexByColNames(indexName string, cols []string, prefixLengths []uint16, props IndexProperties) (Index, error) {
-	tags, ok := ixc.columnNamesToTags(cols)
+	tags, ok := ixc.keyPartNamesToTags(cols, props.Expressions)
 	if !ok {
 		return nil, fmt.Errorf("the table does not contain at least one of the following columns: `%v`", cols)
 	}
@@ -200,13 +206,16 @@ func (ixc *indexCollectionImpl) AddIndexByColTags(indexName string, tags []uint6
 	if ixc.Contains(lowerName) {
 		return nil, fmt.Errorf("`%s` already exists as an index for this table", lowerName)
 	}
-	if !ixc.tagsExist(tags...) {
+	if !ixc.tagsExist(props.Expressions, tags...) {
 		return nil, fmt.Errorf("tags %v do not exist on this table", tags)
 	}
 
 	for _, tag := range tags {
 		// we already validated the tag exists
-		c, _ := ixc.colColl.GetByTag(tag)
+		c, ok := ixc.colColl.GetByTag(tag)
+		if !ok {
+			continue
+		}
 		err := validateColumnIndexable(c)
 		if err != nil {
 			return nil, err
@@ -225,6 +234,8 @@ func (ixc *indexCollectionImpl) AddIndexByColTags(indexName string, tags []uint6
 		comment:       props.Comment,
 		prefixLengths: prefixLengths,
 		fullTextProps: props.FullTextProperties,
+		predicate:     props.Predicate,
+		expressions:   props.Expressions,
 	}
 	ixc.indexes[lowerName] = index
 	for _, tag := range tags {
@@ -251,6 +262,8 @@ func (ixc *indexCollectionImpl) UnsafeAddIndexByColTags(indexName string, tags [
 		comment:       props.Comment,
 		prefixLengths: prefixLengths,
 		fullTextProps: props.FullTextProperties,
+		predicate:     props.Predicate,
+		expressions:   props.Expressions,
 	}
 	ixc.indexes[strings.ToLower(indexName)] = index
 	for _, tag := range tags {
@@ -406,7 +419,7 @@ func (ixc *indexCollectionImpl) Iter(cb func(index Index) (stop bool, err error)
 
 func (ixc *indexCollectionImpl) Merge(indexes ...Index) {
 	for _, index := range indexes {
-		if tags, ok := ixc.columnNamesToTags(index.ColumnNames()); ok && !ixc.Contains(index.Name()) {
+		if tags, ok := ixc.keyPartNamesToTags(index.ColumnNames(), index.Expressions()); ok && !ixc.Contains(index.Name()) {
 			newIndex := &indexImpl{
 				name:          index.Name(),
 				tags:          tags,
@@ -418,6 +431,8 @@ func (ixc *indexCollectionImpl) Merge(indexes ...Index) {
 				comment:       index.Comment(),
 				prefixLengths: index.PrefixLengths(),
 				fullTextProps: index.FullTextProperties(),
+				predicate:     index.Predicate(),
+				expressions:   index.Expressions(),
 			}
 			ixc.AddIndex(newIndex)
 		}
@@ -460,14 +475,25 @@ func (ixc *indexCollectionImpl) RenameIndex(oldName, newName string) (Index, err
 	return index, nil
 }
 
-func (ixc *indexCollectionImpl) columnNamesToTags(cols []string) ([]uint64, bool) {
-	tags := make([]uint64, len(cols))
-	for i, colName := range cols {
-		col, ok := ixc.colColl.NameToCol[colName]
-		if !ok {
+// keyPartNamesToTags returns the tags of the key parts named |names|, which are the columns of the table and the
+// columns of the expressions of an index, |expressions|.
+func (ixc *indexCollectionImpl) keyPartNamesToTags(names []string, expressions []Column) ([]uint64, bool) {
+	tags := make([]uint64, len(names))
+	for i, name := range names {
+		if col, ok := ixc.colColl.NameToCol[name]; ok {
+			tags[i] = col.Tag
+			continue
+		}
+		found := false
+		for _, expr := range expressions {
+			if expr.Name == name {
+				tags[i], found = expr.Tag, true
+				break
+			}
+		}
+		if !found {
 			return nil, false
 		}
-		tags[i] = col.Tag
 	}
 	return tags, true
 }
@@ -504,13 +530,24 @@ func (ixc *indexCollectionImpl) removeIndex(index *indexImpl) {
 	}
 }
 
-func (ixc *indexCollectionImpl) tagsExist(tags ...uint64) bool {
+// tagsExist returns whether |tags| are the tags of columns of the table, or of |expressions|.
+func (ixc *indexCollectionImpl) tagsExist(expressions []Column, tags ...uint64) bool {
 	if len(tags) == 0 {
 		return false
 	}
 	tagToCol := ixc.colColl.TagToCol
 	for _, tag := range tags {
-		if _, ok := tagToCol[tag]; !ok {
+		if _, ok := tagToCol[tag]; ok {
+			continue
+		}
+		found := false
+		for _, expr := range expressions {
+			if expr.Tag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
 			return false
 		}
 	}
@@ -0,0 +1,51 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"hash/fnv"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+)
+
+// NewExpressionColumn returns the column of a key part of an index which is the SQL expression |expr| over the columns
+// of the table, such as LOWER(email), whose values have the type |typeInfo|. The column is not a column of the table:
+// it is a virtual column of the index, whose name and generated expression are |expr|, and whose values are computed
+// from the rows of the table when they are written to the index.
+func NewExpressionColumn(expr string, typeInfo typeinfo.TypeInfo) Column {
+	return Column{
+		Name:      expr,
+		Tag:       ExpressionTag(expr),
+		Kind:      typeInfo.NomsKind(),
+		TypeInfo:  typeInfo,
+		Generated: expr,
+		Virtual:   true,
+	}
+}
+
+// ExpressionTag returns the tag of the key parts of indexes which are the expression |expr|. The tags of expressions
+// are in the range of reserved tags below the tags of system tables, which the columns of tables never use. They only
+// depend on the expression, so that the indexes of the same expression have the same tags on every branch, and are
+// matched by merges like the indexes of columns.
+func ExpressionTag(expr string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(expr))
+	return ReservedTagMin + h.Sum64()%(SystemTableReservedMin-ReservedTagMin)
+}
+
+// IsExpressionTag returns whether |tag| is the tag of a key part of an index which is an expression, see ExpressionTag.
+func IsExpressionTag(tag uint64) bool {
+	return tag >= ReservedTagMin && tag < SystemTableReservedMin
+}
@@ -545,6 +545,40 @@ func TestIndexCollectionDuplicateIndexes(t *testing.T) {
 	indexColl.clear(t)
 }
 
+func TestIndexCollectionExpressionIndexes(t *testing.T) {
+	colColl := NewColCollection(
+		NewColumn("pk", 1, types.IntKind, true, NotNullConstraint{}),
+		NewColumn("email", 2, types.StringKind, false),
+	)
+	indexColl := NewIndexCollection(colColl, nil)
+	lower := NewExpressionColumn("lower(email)", colColl.TagToCol[2].TypeInfo)
+	assert.True(t, IsExpressionTag(lower.Tag))
+	assert.False(t, IsExpressionTag(2))
+	assert.Equal(t, lower.Tag, ExpressionTag("lower(email)"))
+
+	props := IndexProperties{IsUnique: true, Expressions: []Column{lower}}
+	idx, err := indexColl.AddIndexByColNames("lower_email", []string{"lower(email)", "email"}, nil, props)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{lower.Tag, 2}, idx.IndexedColumnTags())
+	assert.Equal(t, []uint64{lower.Tag, 2, 1}, idx.AllTags())
+	assert.Equal(t, []string{"lower(email)", "email"}, idx.ColumnNames())
+	assert.Equal(t, []string{"lower(email)", "email", "pk"}, idx.Schema().GetAllCols().GetColumnNames())
+	assert.Equal(t, []Index{idx}, indexColl.IndexesWithTag(lower.Tag))
+
+	// an index of the same expression matches across collections, like the indexes of the same columns
+	other := NewIndexCollection(colColl, nil)
+	other.Merge(idx)
+	merged, ok := other.GetIndexByTags(lower.Tag, 2)
+	require.True(t, ok)
+	assert.True(t, idx.Equals(merged))
+	assert.Equal(t, []Column{lower}, merged.Expressions())
+
+	_, err = indexColl.AddIndexByColNames("upper_email", []string{"upper(email)"}, nil, props)
+	assert.Error(t, err)
+	_, err = indexColl.AddIndexByColTags("upper_email", []uint64{ExpressionTag("upper(email)")}, nil, props)
+	assert.Error(t, err)
+}
+
 func (ixc *indexCollectionImpl) clear(_ *testing.T) {
 	ixc.indexes = make(map[string]*indexImpl)
 	for key := range ixc.colTagToIndex {
@@ -101,6 +101,12 @@ type Schema interface {
 	// SetComment sets the table's comment.
 	SetComment(comment string)
 
+	// GetTTL returns the time to live of the table's rows, as `column` + INTERVAL n unit, or "" if they don't expire.
+	GetTTL() string
+
+	// SetTTL sets the time to live of the table's rows.
+	SetTTL(ttl string)
+
 	// Copy returns a copy of this Schema that can be safely modified independently.
 	Copy() Schema
 }
@@ -47,6 +47,7 @@ type schemaImpl struct {
 	collation                  Collation
 	contentHashedFields        []uint64
 	comment                    string
+	ttl                        string
 }
 
 var _ Schema = (*schemaImpl)(nil)
@@ -324,6 +325,14 @@ func (si *schemaImpl) SetComment(comment string) {
 	si.comment = comment
 }
 
+func (si *schemaImpl) GetTTL() string {
+	return si.ttl
+}
+
+func (si *schemaImpl) SetTTL(ttl string) {
+	si.ttl = ttl
+}
+
 // GetAllCols gets the collection of all columns (pk and non-pk)
 func (si *schemaImpl) GetAllCols() *ColCollection {
 	return si.allCols
@@ -95,6 +95,157 @@ type ClusterConfig interface {
 	BootstrapRole() string
 	BootstrapEpoch() int
 	RemotesAPIConfig() ClusterRemotesAPIConfig
+	AutomaticFailoverConfig() ClusterAutomaticFailoverConfig
+}
+
+// ClusterAutomaticFailoverConfig configures automatic failover, in which the
+// standbys of a cluster monitor the liveness of the primary and promote the
+// most caught up standby when it becomes unreachable. A primary which cannot
+// reach a quorum of the cluster demotes itself to a standby.
+type ClusterAutomaticFailoverConfig interface {
+	// Enabled returns true if automatic failover is enabled.
+	Enabled() bool
+	// HeartbeatIntervalMillis returns how often, in milliseconds, each
+	// server in the cluster checks the status of its peers. Standbys
+	// check the status of the primary to track their replication lag
+	// even when automatic failover is disabled.
+	HeartbeatIntervalMillis() int
+	// FailoverTimeoutMillis returns how long, in milliseconds, a standby
+	// must be unable to reach the primary before it attempts a failover.
+	// A primary demotes itself after being unable to reach a quorum for
+	// half this time.
+	FailoverTimeoutMillis() int
+}
+
+// The classes of statements which the audit log can be filtered by.
+const (
+	AuditLogClassSelect      = "select"
+	AuditLogClassDML         = "dml"
+	AuditLogClassDDL         = "ddl"
+	AuditLogClassDCL         = "dcl"
+	AuditLogClassTransaction = "transaction"
+	AuditLogClassOther       = "other"
+)
+
+// AuditLogStatementClasses are all of the valid statement classes of the audit log.
+var AuditLogStatementClasses = []string{
+	AuditLogClassSelect,
+	AuditLogClassDML,
+	AuditLogClassDDL,
+	AuditLogClassDCL,
+	AuditLogClassTransaction,
+	AuditLogClassOther,
+}
+
+// AuditLogConfig configures the audit log of sql-server, which records
+// connection events, authentication attempts and statements as JSON lines.
+type AuditLogConfig interface {
+	// Path returns the path of the file the audit log is written to. It
+	// is empty if the audit log is only written to a table.
+	Path() string
+	// MaxSizeMB returns the size, in megabytes, at which the audit log
+	// file is rotated. Rotation is disabled if it is 0.
+	MaxSizeMB() int
+	// MaxFiles returns how many rotated audit log files are kept. All of
+	// them are kept if it is 0.
+	MaxFiles() int
+	// Users returns the users whose events are logged. The events of all
+	// users are logged if it is empty.
+	Users() []string
+	// ExcludeUsers returns the users whose events are not logged.
+	ExcludeUsers() []string
+	// StatementClasses returns the classes of statements which are
+	// logged, from AuditLogStatementClasses. All statements are logged if
+	// it is empty.
+	StatementClasses() []string
+	// TableConfig returns the configuration of the Dolt table the audit
+	// log is written to, or nil if it is not written to a table.
+	TableConfig() AuditLogTableConfig
+}
+
+// AuditLogTableConfig configures a Dolt table which audit log events are
+// inserted into.
+type AuditLogTableConfig interface {
+	// Database returns the database which contains the table.
+	Database() string
+	// Table returns the name of the table. It is created if it does not
+	// exist.
+	Table() string
+}
+
+// The policies for a statement which exceeds a resource limit.
+const (
+	// ResourceLimitsOnExceedError fails the statement.
+	ResourceLimitsOnExceedError = "error"
+	// ResourceLimitsOnExceedKill fails the statement and kills the
+	// connection which ran it.
+	ResourceLimitsOnExceedKill = "kill"
+)
+
+// ResourceLimitsConfig limits the resources used by the statements of a
+// user, or of the users who have been granted a role. A limit of 0 means
+// the resource is not limited.
+type ResourceLimitsConfig interface {
+	// User returns the user the limits apply to, or "" if they apply to
+	// a role.
+	User() string
+	// Role returns the role the limits apply to, or "" if they apply to
+	// a user.
+	Role() string
+	// MaxConcurrentQueries returns how many statements the user can run
+	// at the same time, across all of their connections.
+	MaxConcurrentQueries() int
+	// MaxExecutionTimeMillis returns how long, in milliseconds, a
+	// statement can run for.
+	MaxExecutionTimeMillis() uint64
+	// MaxRowsReturned returns how many rows a statement can return.
+	MaxRowsReturned() uint64
+	// MaxRowsWrittenPerTransaction returns how many rows can be inserted,
+	// updated or deleted in a transaction.
+	MaxRowsWrittenPerTransaction() uint64
+	// OnExceed returns what happens to a statement which exceeds a limit,
+	// either ResourceLimitsOnExceedError or ResourceLimitsOnExceedKill.
+	OnExceed() string
+}
+
+// TTLPurgeConfig configures the TTL purger of sql-server, which deletes the
+// expired rows of the tables with a TTL on a set of branches, and commits
+// the deletions.
+type TTLPurgeConfig interface {
+	// IntervalMillis returns how often, in milliseconds, the expired rows
+	// are purged.
+	IntervalMillis() int
+	// BatchSize returns how many rows are deleted by each statement of a
+	// purge. Each statement runs in its own transaction and commit.
+	BatchSize() int
+	// MaxBatches returns how many statements a purge runs for each table.
+	// The remaining expired rows are purged by the next purge.
+	MaxBatches() int
+	// CommitAuthor returns the author of the commits of the purges, as
+	// "name <email>".
+	CommitAuthor() string
+	// Branches returns the branches whose expired rows are purged.
+	Branches() []TTLPurgeBranchConfig
+}
+
+// TTLPurgeBranchConfig is a branch whose expired rows are purged by the TTL
+// purger.
+type TTLPurgeBranchConfig interface {
+	// Database returns the database of the branch.
+	Database() string
+	// Branch returns the name of the branch.
+	Branch() string
+}
+
+// ResultCacheConfig configures the cache of the results of read-only
+// queries. A result is reused by the queries with the same text, parameters
+// and user which read the same roots of the same tables.
+type ResultCacheConfig interface {
+	// MaxMemoryMb returns how many megabytes of results are cached.
+	MaxMemoryMb() uint64
+	// MaxResultKb returns how many kilobytes the largest cached result can
+	// have. Larger results are not cached.
+	MaxResultKb() uint64
 }
 
 type ClusterRemotesAPIConfig interface {
@@ -200,6 +351,14 @@ type ServerConfig interface {
 	ClusterConfig() ClusterConfig
 	// EventSchedulerStatus is the configuration for enabling or disabling the event scheduler in this server.
 	EventSchedulerStatus() string
+	// AuditLogConfig is the configuration for the audit log of this server, or nil if it is disabled.
+	AuditLogConfig() AuditLogConfig
+	// ResourceLimits returns the limits on the resources used by the statements of users and roles.
+	ResourceLimits() []ResourceLimitsConfig
+	// TTLPurgeConfig is the configuration for the TTL purger of this server, or nil if it is disabled.
+	TTLPurgeConfig() TTLPurgeConfig
+	// ResultCacheConfig is the configuration for the query result cache of this server, or nil if it is disabled.
+	ResultCacheConfig() ResultCacheConfig
 	// ValueSet returns whether the value string provided was explicitly set in the config
 	ValueSet(value string) bool
 }
@@ -269,6 +428,18 @@ func ValidateConfig(config ServerConfig) error {
 	if config.RequireSecureTransport() && config.TLSCert() == "" && config.TLSKey() == "" {
 		return fmt.Errorf("require_secure_transport can only be `true` when a tls_key and tls_cert are provided.")
 	}
+	if err := ValidateAuditLogConfig(config.AuditLogConfig()); err != nil {
+		return err
+	}
+	if err := ValidateResourceLimits(config.ResourceLimits()); err != nil {
+		return err
+	}
+	if err := ValidateTTLPurgeConfig(config.TTLPurgeConfig()); err != nil {
+		return err
+	}
+	if err := ValidateResultCacheConfig(config.ResultCacheConfig()); err != nil {
+		return err
+	}
 	return ValidateClusterConfig(config.ClusterConfig())
 }
 
@@ -347,6 +518,130 @@ func ValidateClusterConfig(config ClusterConfig) error {
 	if config.RemotesAPIConfig().TLSKey() != "" && config.RemotesAPIConfig().TLSCert() == "" {
 		return fmt.Errorf("cluster: remotesapi: tls_cert: must supply a tls_cert if you supply a tls_key")
 	}
+	failover := config.AutomaticFailoverConfig()
+	if failover.HeartbeatIntervalMillis() <= 0 {
+		return fmt.Errorf("cluster: automatic_failover: heartbeat_interval_millis: is %d but must be > 0", failover.HeartbeatIntervalMillis())
+	}
+	if failover.Enabled() {
+		if failover.FailoverTimeoutMillis() <= failover.HeartbeatIntervalMillis() {
+			return fmt.Errorf("cluster: automatic_failover: failover_timeout_millis: is %d but must be greater than heartbeat_interval_millis, %d", failover.FailoverTimeoutMillis(), failover.HeartbeatIntervalMillis())
+		}
+	}
+	return nil
+}
+
+func ValidateAuditLogConfig(config AuditLogConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.Path() == "" && config.TableConfig() == nil {
+		return errors.New("audit_log: must supply a path or a table")
+	}
+	if config.MaxSizeMB() < 0 {
+		return fmt.Errorf("audit_log: max_size_mb: is %d but must be >= 0", config.MaxSizeMB())
+	}
+	if config.MaxFiles() < 0 {
+		return fmt.Errorf("audit_log: max_files: is %d but must be >= 0", config.MaxFiles())
+	}
+	for i, class := range config.StatementClasses() {
+		valid := false
+		for _, c := range AuditLogStatementClasses {
+			if strings.EqualFold(class, c) {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("audit_log: statement_classes[%d]: is \"%s\" but must be one of %s", i, class, strings.Join(AuditLogStatementClasses, ", "))
+		}
+	}
+	if table := config.TableConfig(); table != nil {
+		if table.Database() == "" {
+			return errors.New("audit_log: table: database: Cannot be empty")
+		}
+		if table.Table() == "" {
+			return errors.New("audit_log: table: name: Cannot be empty")
+		}
+	}
+	return nil
+}
+
+// ValidateResourceLimits returns an error if any of the resource limits are
+// not valid, or if there is more than one set of limits for a user or role.
+func ValidateResourceLimits(limits []ResourceLimitsConfig) error {
+	users := make(map[string]struct{})
+	roles := make(map[string]struct{})
+	for i, l := range limits {
+		if (l.User() == "") == (l.Role() == "") {
+			return fmt.Errorf("resource_limits[%d]: must supply exactly one of user or role", i)
+		}
+		if l.User() != "" {
+			if _, ok := users[l.User()]; ok {
+				return fmt.Errorf("resource_limits[%d]: user: %s has more than one set of limits", i, l.User())
+			}
+			users[l.User()] = struct{}{}
+		} else {
+			if _, ok := roles[l.Role()]; ok {
+				return fmt.Errorf("resource_limits[%d]: role: %s has more than one set of limits", i, l.Role())
+			}
+			roles[l.Role()] = struct{}{}
+		}
+		if l.MaxConcurrentQueries() < 0 {
+			return fmt.Errorf("resource_limits[%d]: max_concurrent_queries: is %d but must be >= 0", i, l.MaxConcurrentQueries())
+		}
+		if l.OnExceed() != ResourceLimitsOnExceedError && l.OnExceed() != ResourceLimitsOnExceedKill {
+			return fmt.Errorf("resource_limits[%d]: on_exceed: is \"%s\" but must be %s or %s", i, l.OnExceed(), ResourceLimitsOnExceedError, ResourceLimitsOnExceedKill)
+		}
+	}
+	return nil
+}
+
+// ValidateTTLPurgeConfig returns an error if the configuration of the TTL
+// purger is not valid.
+func ValidateTTLPurgeConfig(config TTLPurgeConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.IntervalMillis() <= 0 {
+		return fmt.Errorf("ttl_purge: interval_millis: is %d but must be > 0", config.IntervalMillis())
+	}
+	if config.BatchSize() <= 0 {
+		return fmt.Errorf("ttl_purge: batch_size: is %d but must be > 0", config.BatchSize())
+	}
+	if config.MaxBatches() <= 0 {
+		return fmt.Errorf("ttl_purge: max_batches: is %d but must be > 0", config.MaxBatches())
+	}
+	if config.CommitAuthor() == "" {
+		return errors.New("ttl_purge: commit_author: Cannot be empty")
+	}
+	if len(config.Branches()) == 0 {
+		return errors.New("ttl_purge: branches: must supply at least one branch")
+	}
+	for i, b := range config.Branches() {
+		if b.Database() == "" {
+			return fmt.Errorf("ttl_purge: branches[%d]: database: Cannot be empty", i)
+		}
+		if b.Branch() == "" {
+			return fmt.Errorf("ttl_purge: branches[%d]: branch: Cannot be empty", i)
+		}
+	}
+	return nil
+}
+
+// ValidateResultCacheConfig returns an error if the configuration of the
+// query result cache is not valid.
+func ValidateResultCacheConfig(config ResultCacheConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.MaxMemoryMb() == 0 {
+		return errors.New("result_cache: max_memory_mb: must be > 0")
+	}
+	if config.MaxResultKb() == 0 {
+		return errors.New("result_cache: max_result_kb: must be > 0")
+	}
+	if config.MaxResultKb() > config.MaxMemoryMb()*1024 {
+		return fmt.Errorf("result_cache: max_result_kb: is %d but must be <= max_memory_mb * 1024", config.MaxResultKb())
+	}
 	return nil
 }
 
@@ -138,10 +138,14 @@ type YAMLConfig struct {
 	PrivilegeFile     *string               `yaml:"privilege_file,omitempty"`
 	BranchControlFile *string               `yaml:"branch_control_file,omitempty"`
 	// TODO: Rename to UserVars_
-	Vars            []UserSessionVars      `yaml:"user_session_vars"`
-	SystemVars_     map[string]interface{} `yaml:"system_variables,omitempty" minver:"1.11.1"`
-	Jwks            []JwksConfig           `yaml:"jwks"`
-	GoldenMysqlConn *string                `yaml:"golden_mysql_conn,omitempty"`
+	Vars            []UserSessionVars          `yaml:"user_session_vars"`
+	SystemVars_     map[string]interface{}     `yaml:"system_variables,omitempty" minver:"1.11.1"`
+	Jwks            []JwksConfig               `yaml:"jwks"`
+	GoldenMysqlConn *string                    `yaml:"golden_mysql_conn,omitempty"`
+	AuditLog        *AuditLogYAMLConfig        `yaml:"audit_log,omitempty" minver:"TBD"`
+	ResourceLimits_ []ResourceLimitsYAMLConfig `yaml:"resource_limits,omitempty" minver:"TBD"`
+	TTLPurge        *TTLPurgeYAMLConfig        `yaml:"ttl_purge,omitempty" minver:"TBD"`
+	ResultCache     *ResultCacheYAMLConfig     `yaml:"result_cache,omitempty" minver:"TBD"`
 }
 
 var _ ServerConfig = YAMLConfig{}
@@ -223,6 +227,84 @@ func ServerConfigAsYAMLConfig(cfg ServerConfig) *YAMLConfig {
 		SystemVars_:       systemVars,
 		Vars:              cfg.UserVars(),
 		Jwks:              cfg.JwksConfig(),
+		AuditLog:          auditLogConfigAsYAMLConfig(cfg.AuditLogConfig()),
+		ResourceLimits_:   resourceLimitsAsYAMLConfig(cfg.ResourceLimits()),
+		TTLPurge:          ttlPurgeConfigAsYAMLConfig(cfg.TTLPurgeConfig()),
+		ResultCache:       resultCacheConfigAsYAMLConfig(cfg.ResultCacheConfig()),
+	}
+}
+
+func resultCacheConfigAsYAMLConfig(config ResultCacheConfig) *ResultCacheYAMLConfig {
+	if config == nil {
+		return nil
+	}
+	return &ResultCacheYAMLConfig{
+		MaxMemoryMb_: ptr(config.MaxMemoryMb()),
+		MaxResultKb_: ptr(config.MaxResultKb()),
+	}
+}
+
+func ttlPurgeConfigAsYAMLConfig(config TTLPurgeConfig) *TTLPurgeYAMLConfig {
+	if config == nil {
+		return nil
+	}
+
+	branches := make([]TTLPurgeBranchYAMLConfig, len(config.Branches()))
+	for i, b := range config.Branches() {
+		branches[i] = TTLPurgeBranchYAMLConfig{
+			Database_: ptr(b.Database()),
+			Branch_:   ptr(b.Branch()),
+		}
+	}
+	return &TTLPurgeYAMLConfig{
+		IntervalMillis_: ptr(config.IntervalMillis()),
+		BatchSize_:      ptr(config.BatchSize()),
+		MaxBatches_:     ptr(config.MaxBatches()),
+		CommitAuthor_:   ptr(config.CommitAuthor()),
+		Branches_:       branches,
+	}
+}
+
+func resourceLimitsAsYAMLConfig(limits []ResourceLimitsConfig) []ResourceLimitsYAMLConfig {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	ret := make([]ResourceLimitsYAMLConfig, len(limits))
+	for i, l := range limits {
+		ret[i] = ResourceLimitsYAMLConfig{
+			User_:                         nillableStrPtr(l.User()),
+			Role_:                         nillableStrPtr(l.Role()),
+			MaxConcurrentQueries_:         ptr(l.MaxConcurrentQueries()),
+			MaxExecutionTimeMillis_:       ptr(l.MaxExecutionTimeMillis()),
+			MaxRowsReturned_:              ptr(l.MaxRowsReturned()),
+			MaxRowsWrittenPerTransaction_: ptr(l.MaxRowsWrittenPerTransaction()),
+			OnExceed_:                     ptr(l.OnExceed()),
+		}
+	}
+	return ret
+}
+
+func auditLogConfigAsYAMLConfig(config AuditLogConfig) *AuditLogYAMLConfig {
+	if config == nil {
+		return nil
+	}
+
+	var table *AuditLogTableYAMLConfig
+	if config.TableConfig() != nil {
+		table = &AuditLogTableYAMLConfig{
+			Database_: ptr(config.TableConfig().Database()),
+			Table_:    ptr(config.TableConfig().Table()),
+		}
+	}
+	return &AuditLogYAMLConfig{
+		Path_:             nillableStrPtr(config.Path()),
+		MaxSizeMB_:        ptr(config.MaxSizeMB()),
+		MaxFiles_:         ptr(config.MaxFiles()),
+		Users_:            config.Users(),
+		ExcludeUsers_:     config.ExcludeUsers(),
+		StatementClasses_: config.StatementClasses(),
+		Table:             table,
 	}
 }
 
@@ -244,6 +326,19 @@ func clusterConfigAsYAMLConfig(config ClusterConfig) *ClusterYAMLConfig {
 			URLMatches: config.RemotesAPIConfig().ServerNameURLMatches(),
 			DNSMatches: config.RemotesAPIConfig().ServerNameDNSMatches(),
 		},
+		AutomaticFailover: clusterAutomaticFailoverAsYAMLConfig(config.AutomaticFailoverConfig()),
+	}
+}
+
+func clusterAutomaticFailoverAsYAMLConfig(config ClusterAutomaticFailoverConfig) *ClusterAutomaticFailoverYAMLConfig {
+	if !config.Enabled() {
+		return nil
+	}
+
+	return &ClusterAutomaticFailoverYAMLConfig{
+		Enabled_:                 ptr(config.Enabled()),
+		HeartbeatIntervalMillis_: ptr(config.HeartbeatIntervalMillis()),
+		FailoverTimeoutMillis_:   ptr(config.FailoverTimeoutMillis()),
 	}
 }
 
@@ -592,11 +687,270 @@ func (cfg YAMLConfig) EventSchedulerStatus() string {
 	}
 }
 
+func (cfg YAMLConfig) AuditLogConfig() AuditLogConfig {
+	if cfg.AuditLog == nil {
+		return nil
+	}
+	return cfg.AuditLog
+}
+
+const (
+	defaultAuditLogMaxSizeMB = 100
+	defaultAuditLogMaxFiles  = 10
+	defaultAuditLogTable     = "audit_log"
+)
+
+type AuditLogYAMLConfig struct {
+	Path_             *string                  `yaml:"path,omitempty" minver:"TBD"`
+	MaxSizeMB_        *int                     `yaml:"max_size_mb,omitempty" minver:"TBD"`
+	MaxFiles_         *int                     `yaml:"max_files,omitempty" minver:"TBD"`
+	Users_            []string                 `yaml:"users,omitempty" minver:"TBD"`
+	ExcludeUsers_     []string                 `yaml:"exclude_users,omitempty" minver:"TBD"`
+	StatementClasses_ []string                 `yaml:"statement_classes,omitempty" minver:"TBD"`
+	Table             *AuditLogTableYAMLConfig `yaml:"table,omitempty" minver:"TBD"`
+}
+
+func (c *AuditLogYAMLConfig) Path() string {
+	if c.Path_ == nil {
+		return ""
+	}
+	return *c.Path_
+}
+
+func (c *AuditLogYAMLConfig) MaxSizeMB() int {
+	if c.MaxSizeMB_ == nil {
+		return defaultAuditLogMaxSizeMB
+	}
+	return *c.MaxSizeMB_
+}
+
+func (c *AuditLogYAMLConfig) MaxFiles() int {
+	if c.MaxFiles_ == nil {
+		return defaultAuditLogMaxFiles
+	}
+	return *c.MaxFiles_
+}
+
+func (c *AuditLogYAMLConfig) Users() []string {
+	return c.Users_
+}
+
+func (c *AuditLogYAMLConfig) ExcludeUsers() []string {
+	return c.ExcludeUsers_
+}
+
+func (c *AuditLogYAMLConfig) StatementClasses() []string {
+	return c.StatementClasses_
+}
+
+func (c *AuditLogYAMLConfig) TableConfig() AuditLogTableConfig {
+	if c.Table == nil {
+		return nil
+	}
+	return c.Table
+}
+
+type AuditLogTableYAMLConfig struct {
+	Database_ *string `yaml:"database,omitempty" minver:"TBD"`
+	Table_    *string `yaml:"name,omitempty" minver:"TBD"`
+}
+
+func (c *AuditLogTableYAMLConfig) Database() string {
+	if c.Database_ == nil {
+		return ""
+	}
+	return *c.Database_
+}
+
+func (c *AuditLogTableYAMLConfig) Table() string {
+	if c.Table_ == nil {
+		return defaultAuditLogTable
+	}
+	return *c.Table_
+}
+
+func (cfg YAMLConfig) ResourceLimits() []ResourceLimitsConfig {
+	ret := make([]ResourceLimitsConfig, len(cfg.ResourceLimits_))
+	for i := range cfg.ResourceLimits_ {
+		ret[i] = cfg.ResourceLimits_[i]
+	}
+	return ret
+}
+
+type ResourceLimitsYAMLConfig struct {
+	User_                         *string `yaml:"user,omitempty" minver:"TBD"`
+	Role_                         *string `yaml:"role,omitempty" minver:"TBD"`
+	MaxConcurrentQueries_         *int    `yaml:"max_concurrent_queries,omitempty" minver:"TBD"`
+	MaxExecutionTimeMillis_       *uint64 `yaml:"max_execution_time_millis,omitempty" minver:"TBD"`
+	MaxRowsReturned_              *uint64 `yaml:"max_rows_returned,omitempty" minver:"TBD"`
+	MaxRowsWrittenPerTransaction_ *uint64 `yaml:"max_rows_written_per_transaction,omitempty" minver:"TBD"`
+	OnExceed_                     *string `yaml:"on_exceed,omitempty" minver:"TBD"`
+}
+
+func (c ResourceLimitsYAMLConfig) User() string {
+	if c.User_ == nil {
+		return ""
+	}
+	return *c.User_
+}
+
+func (c ResourceLimitsYAMLConfig) Role() string {
+	if c.Role_ == nil {
+		return ""
+	}
+	return *c.Role_
+}
+
+func (c ResourceLimitsYAMLConfig) MaxConcurrentQueries() int {
+	if c.MaxConcurrentQueries_ == nil {
+		return 0
+	}
+	return *c.MaxConcurrentQueries_
+}
+
+func (c ResourceLimitsYAMLConfig) MaxExecutionTimeMillis() uint64 {
+	if c.MaxExecutionTimeMillis_ == nil {
+		return 0
+	}
+	return *c.MaxExecutionTimeMillis_
+}
+
+func (c ResourceLimitsYAMLConfig) MaxRowsReturned() uint64 {
+	if c.MaxRowsReturned_ == nil {
+		return 0
+	}
+	return *c.MaxRowsReturned_
+}
+
+func (c ResourceLimitsYAMLConfig) MaxRowsWrittenPerTransaction() uint64 {
+	if c.MaxRowsWrittenPerTransaction_ == nil {
+		return 0
+	}
+	return *c.MaxRowsWrittenPerTransaction_
+}
+
+func (c ResourceLimitsYAMLConfig) OnExceed() string {
+	if c.OnExceed_ == nil {
+		return ResourceLimitsOnExceedError
+	}
+	return strings.ToLower(*c.OnExceed_)
+}
+
+func (cfg YAMLConfig) TTLPurgeConfig() TTLPurgeConfig {
+	if cfg.TTLPurge == nil {
+		return nil
+	}
+	return cfg.TTLPurge
+}
+
+const (
+	defaultTTLPurgeIntervalMillis = 60 * 1000
+	defaultTTLPurgeBatchSize      = 1000
+	defaultTTLPurgeMaxBatches     = 100
+	defaultTTLPurgeCommitAuthor   = "Dolt TTL Purger <doltuser@dolthub.com>"
+)
+
+type TTLPurgeYAMLConfig struct {
+	IntervalMillis_ *int                       `yaml:"interval_millis,omitempty" minver:"TBD"`
+	BatchSize_      *int                       `yaml:"batch_size,omitempty" minver:"TBD"`
+	MaxBatches_     *int                       `yaml:"max_batches,omitempty" minver:"TBD"`
+	CommitAuthor_   *string                    `yaml:"commit_author,omitempty" minver:"TBD"`
+	Branches_       []TTLPurgeBranchYAMLConfig `yaml:"branches,omitempty" minver:"TBD"`
+}
+
+func (c *TTLPurgeYAMLConfig) IntervalMillis() int {
+	if c.IntervalMillis_ == nil {
+		return defaultTTLPurgeIntervalMillis
+	}
+	return *c.IntervalMillis_
+}
+
+func (c *TTLPurgeYAMLConfig) BatchSize() int {
+	if c.BatchSize_ == nil {
+		return defaultTTLPurgeBatchSize
+	}
+	return *c.BatchSize_
+}
+
+func (c *TTLPurgeYAMLConfig) MaxBatches() int {
+	if c.MaxBatches_ == nil {
+		return defaultTTLPurgeMaxBatches
+	}
+	return *c.MaxBatches_
+}
+
+func (c *TTLPurgeYAMLConfig) CommitAuthor() string {
+	if c.CommitAuthor_ == nil {
+		return defaultTTLPurgeCommitAuthor
+	}
+	return *c.CommitAuthor_
+}
+
+func (c *TTLPurgeYAMLConfig) Branches() []TTLPurgeBranchConfig {
+	ret := make([]TTLPurgeBranchConfig, len(c.Branches_))
+	for i := range c.Branches_ {
+		ret[i] = c.Branches_[i]
+	}
+	return ret
+}
+
+type TTLPurgeBranchYAMLConfig struct {
+	Database_ *string `yaml:"database,omitempty" minver:"TBD"`
+	Branch_   *string `yaml:"branch,omitempty" minver:"TBD"`
+}
+
+func (c TTLPurgeBranchYAMLConfig) Database() string {
+	if c.Database_ == nil {
+		return ""
+	}
+	return *c.Database_
+}
+
+func (c TTLPurgeBranchYAMLConfig) Branch() string {
+	if c.Branch_ == nil {
+		return ""
+	}
+	return *c.Branch_
+}
+
+func (cfg YAMLConfig) ResultCacheConfig() ResultCacheConfig {
+	if cfg.ResultCache == nil {
+		return nil
+	}
+	return cfg.ResultCache
+}
+
+const (
+	defaultResultCacheMaxMemoryMb = 64
+	defaultResultCacheMaxResultKb = 1024
+)
+
+type ResultCacheYAMLConfig struct {
+	MaxMemoryMb_ *uint64 `yaml:"max_memory_mb,omitempty" minver:"TBD"`
+	MaxResultKb_ *uint64 `yaml:"max_result_kb,omitempty" minver:"TBD"`
+}
+
+func (c *ResultCacheYAMLConfig) MaxMemoryMb() uint64 {
+	if c.MaxMemoryMb_ == nil {
+		return defaultResultCacheMaxMemoryMb
+	}
+	return *c.MaxMemoryMb_
+}
+
+func (c *ResultCacheYAMLConfig) MaxResultKb() uint64 {
+	if c.MaxResultKb_ == nil {
+		return defaultResultCacheMaxResultKb
+	}
+	return *c.MaxResultKb_
+}
+
 type ClusterYAMLConfig struct {
 	StandbyRemotes_ []StandbyRemoteYAMLConfig   `yaml:"standby_remotes"`
 	BootstrapRole_  string                      `yaml:"bootstrap_role"`
 	BootstrapEpoch_ int                         `yaml:"bootstrap_epoch"`
 	RemotesAPI      ClusterRemotesAPIYAMLConfig `yaml:"remotesapi"`
+
+	AutomaticFailover *ClusterAutomaticFailoverYAMLConfig `yaml:"automatic_failover,omitempty" minver:"TBD"`
 }
 
 type StandbyRemoteYAMLConfig struct {
@@ -632,6 +986,42 @@ func (c *ClusterYAMLConfig) RemotesAPIConfig() ClusterRemotesAPIConfig {
 	return c.RemotesAPI
 }
 
+func (c *ClusterYAMLConfig) AutomaticFailoverConfig() ClusterAutomaticFailoverConfig {
+	return c.AutomaticFailover
+}
+
+const (
+	defaultClusterHeartbeatIntervalMillis = 1000
+	defaultClusterFailoverTimeoutMillis   = 5000
+)
+
+type ClusterAutomaticFailoverYAMLConfig struct {
+	Enabled_                 *bool `yaml:"enabled,omitempty" minver:"TBD"`
+	HeartbeatIntervalMillis_ *int  `yaml:"heartbeat_interval_millis,omitempty" minver:"TBD"`
+	FailoverTimeoutMillis_   *int  `yaml:"failover_timeout_millis,omitempty" minver:"TBD"`
+}
+
+func (c *ClusterAutomaticFailoverYAMLConfig) Enabled() bool {
+	if c == nil || c.Enabled_ == nil {
+		return false
+	}
+	return *c.Enabled_
+}
+
+func (c *ClusterAutomaticFailoverYAMLConfig) HeartbeatIntervalMillis() int {
+	if c == nil || c.HeartbeatIntervalMillis_ == nil {
+		return defaultClusterHeartbeatIntervalMillis
+	}
+	return *c.HeartbeatIntervalMillis_
+}
+
+func (c *ClusterAutomaticFailoverYAMLConfig) FailoverTimeoutMillis() int {
+	if c == nil || c.FailoverTimeoutMillis_ == nil {
+		return defaultClusterFailoverTimeoutMillis
+	}
+	return *c.FailoverTimeoutMillis_
+}
+
 type ClusterRemotesAPIYAMLConfig struct {
 	Addr_      string   `yaml:"address"`
 	Port_      int      `yaml:"port"`
@@ -179,6 +179,29 @@ cluster:
 	require.Equal(t, 0, config.ClusterConfig().BootstrapEpoch())
 	require.Equal(t, "standby", config.ClusterConfig().StandbyRemotes()[0].Name())
 	require.Equal(t, "http://doltdb-1.doltdb:50051/{database}", config.ClusterConfig().StandbyRemotes()[0].RemoteURLTemplate())
+	require.False(t, config.ClusterConfig().AutomaticFailoverConfig().Enabled())
+}
+
+func TestUnmarshallClusterAutomaticFailover(t *testing.T) {
+	testStr := `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://doltdb-1.doltdb:50051/{database}
+  bootstrap_role: primary
+  bootstrap_epoch: 0
+  remotesapi:
+    port: 50051
+  automatic_failover:
+    enabled: true
+    failover_timeout_millis: 10000
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	failover := config.ClusterConfig().AutomaticFailoverConfig()
+	require.True(t, failover.Enabled())
+	require.Equal(t, 1000, failover.HeartbeatIntervalMillis())
+	require.Equal(t, 10000, failover.FailoverTimeoutMillis())
 }
 
 func TestValidateClusterConfig(t *testing.T) {
@@ -259,6 +282,38 @@ cluster:
   bootstrap_epoch: 0
   remotesapi:
     port: 50051
+`,
+			Error: true,
+		},
+		{
+			Name: "automatic failover valid",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  automatic_failover:
+    enabled: true
+    heartbeat_interval_millis: 500
+    failover_timeout_millis: 2000
+`,
+			Error: false,
+		},
+		{
+			Name: "automatic failover timeout shorter than heartbeat interval",
+			Config: `
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:50051/{database}
+  remotesapi:
+    port: 50051
+  automatic_failover:
+    enabled: true
+    heartbeat_interval_millis: 2000
+    failover_timeout_millis: 500
 `,
 			Error: true,
 		},
@@ -288,6 +343,120 @@ cluster:
 	}
 }
 
+func TestUnmarshallAuditLog(t *testing.T) {
+	testStr := `
+audit_log:
+  path: /var/log/dolt/audit.log
+  max_files: 3
+  exclude_users:
+  - replicator
+  statement_classes:
+  - ddl
+  - dcl
+  table:
+    database: audit
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	auditLog := config.AuditLogConfig()
+	require.NotNil(t, auditLog)
+	require.Equal(t, "/var/log/dolt/audit.log", auditLog.Path())
+	require.Equal(t, 100, auditLog.MaxSizeMB())
+	require.Equal(t, 3, auditLog.MaxFiles())
+	require.Empty(t, auditLog.Users())
+	require.Equal(t, []string{"replicator"}, auditLog.ExcludeUsers())
+	require.Equal(t, []string{"ddl", "dcl"}, auditLog.StatementClasses())
+	require.NotNil(t, auditLog.TableConfig())
+	require.Equal(t, "audit", auditLog.TableConfig().Database())
+	require.Equal(t, "audit_log", auditLog.TableConfig().Table())
+	require.NoError(t, ValidateAuditLogConfig(auditLog))
+
+	config, err = NewYamlConfig([]byte(""))
+	require.NoError(t, err)
+	require.Nil(t, config.AuditLogConfig())
+}
+
+func TestValidateAuditLogConfig(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Config string
+		Error  bool
+	}{
+		{
+			Name:   "no audit_log: config",
+			Config: "",
+			Error:  false,
+		},
+		{
+			Name: "file only",
+			Config: `
+audit_log:
+  path: audit.log
+`,
+			Error: false,
+		},
+		{
+			Name: "table only",
+			Config: `
+audit_log:
+  table:
+    database: audit
+    name: events
+`,
+			Error: false,
+		},
+		{
+			Name: "no path or table",
+			Config: `
+audit_log:
+  users:
+  - root
+`,
+			Error: true,
+		},
+		{
+			Name: "table without database",
+			Config: `
+audit_log:
+  table:
+    name: events
+`,
+			Error: true,
+		},
+		{
+			Name: "negative max_size_mb",
+			Config: `
+audit_log:
+  path: audit.log
+  max_size_mb: -1
+`,
+			Error: true,
+		},
+		{
+			Name: "bad statement class",
+			Config: `
+audit_log:
+  path: audit.log
+  statement_classes:
+  - dml
+  - writes
+`,
+			Error: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg, err := NewYamlConfig([]byte(c.Config))
+			require.NoError(t, err)
+			if c.Error {
+				require.Error(t, ValidateAuditLogConfig(cfg.AuditLogConfig()))
+			} else {
+				require.NoError(t, ValidateAuditLogConfig(cfg.AuditLogConfig()))
+			}
+		})
+	}
+}
+
 // Tests that a common YAML error (incorrect indentation) throws an error
 func TestUnmarshallError(t *testing.T) {
 	testStr := `
@@ -415,3 +584,313 @@ listener:
 	err = ValidateConfig(cfg)
 	assert.Error(t, err)
 }
+
+func TestUnmarshallResourceLimits(t *testing.T) {
+	testStr := `
+resource_limits:
+- user: analyst
+  max_concurrent_queries: 2
+  max_execution_time_millis: 30000
+  max_rows_returned: 1000
+  on_exceed: KILL
+- role: loaders
+  max_rows_written_per_transaction: 50000
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	limits := config.ResourceLimits()
+	require.Len(t, limits, 2)
+	require.Equal(t, "analyst", limits[0].User())
+	require.Equal(t, "", limits[0].Role())
+	require.Equal(t, 2, limits[0].MaxConcurrentQueries())
+	require.Equal(t, uint64(30000), limits[0].MaxExecutionTimeMillis())
+	require.Equal(t, uint64(1000), limits[0].MaxRowsReturned())
+	require.Equal(t, uint64(0), limits[0].MaxRowsWrittenPerTransaction())
+	require.Equal(t, ResourceLimitsOnExceedKill, limits[0].OnExceed())
+	require.Equal(t, "", limits[1].User())
+	require.Equal(t, "loaders", limits[1].Role())
+	require.Equal(t, uint64(50000), limits[1].MaxRowsWrittenPerTransaction())
+	require.Equal(t, ResourceLimitsOnExceedError, limits[1].OnExceed())
+	require.NoError(t, ValidateResourceLimits(limits))
+
+	roundTripped, err := NewYamlConfig([]byte(ServerConfigAsYAMLConfig(config).String()))
+	require.NoError(t, err)
+	require.Len(t, roundTripped.ResourceLimits(), 2)
+	for i, l := range roundTripped.ResourceLimits() {
+		require.Equal(t, limits[i].User(), l.User())
+		require.Equal(t, limits[i].Role(), l.Role())
+		require.Equal(t, limits[i].MaxConcurrentQueries(), l.MaxConcurrentQueries())
+		require.Equal(t, limits[i].MaxExecutionTimeMillis(), l.MaxExecutionTimeMillis())
+		require.Equal(t, limits[i].MaxRowsReturned(), l.MaxRowsReturned())
+		require.Equal(t, limits[i].MaxRowsWrittenPerTransaction(), l.MaxRowsWrittenPerTransaction())
+		require.Equal(t, limits[i].OnExceed(), l.OnExceed())
+	}
+}
+
+func TestValidateResourceLimits(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Config string
+		Error  bool
+	}{
+		{
+			Name:   "no resource_limits: config",
+			Config: "",
+			Error:  false,
+		},
+		{
+			Name: "user and role",
+			Config: `
+resource_limits:
+- user: analyst
+  role: analysts
+  max_rows_returned: 10
+`,
+			Error: true,
+		},
+		{
+			Name: "neither user nor role",
+			Config: `
+resource_limits:
+- max_rows_returned: 10
+`,
+			Error: true,
+		},
+		{
+			Name: "duplicate user",
+			Config: `
+resource_limits:
+- user: analyst
+  max_rows_returned: 10
+- user: analyst
+  max_concurrent_queries: 1
+`,
+			Error: true,
+		},
+		{
+			Name: "user and role with the same name",
+			Config: `
+resource_limits:
+- user: analyst
+  max_rows_returned: 10
+- role: analyst
+  max_concurrent_queries: 1
+`,
+			Error: false,
+		},
+		{
+			Name: "negative max_concurrent_queries",
+			Config: `
+resource_limits:
+- user: analyst
+  max_concurrent_queries: -1
+`,
+			Error: true,
+		},
+		{
+			Name: "bad on_exceed",
+			Config: `
+resource_limits:
+- user: analyst
+  on_exceed: warn
+`,
+			Error: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg, err := NewYamlConfig([]byte(c.Config))
+			require.NoError(t, err)
+			if c.Error {
+				require.Error(t, ValidateResourceLimits(cfg.ResourceLimits()))
+			} else {
+				require.NoError(t, ValidateResourceLimits(cfg.ResourceLimits()))
+			}
+		})
+	}
+}
+
+func TestUnmarshallTTLPurge(t *testing.T) {
+	testStr := `
+ttl_purge:
+  interval_millis: 5000
+  batch_size: 50
+  branches:
+  - database: events
+    branch: main
+  - database: sessions
+    branch: prod
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	purge := config.TTLPurgeConfig()
+	require.NotNil(t, purge)
+	require.Equal(t, 5000, purge.IntervalMillis())
+	require.Equal(t, 50, purge.BatchSize())
+	require.Equal(t, defaultTTLPurgeMaxBatches, purge.MaxBatches())
+	require.Equal(t, defaultTTLPurgeCommitAuthor, purge.CommitAuthor())
+	require.Len(t, purge.Branches(), 2)
+	require.Equal(t, "events", purge.Branches()[0].Database())
+	require.Equal(t, "main", purge.Branches()[0].Branch())
+	require.Equal(t, "sessions", purge.Branches()[1].Database())
+	require.Equal(t, "prod", purge.Branches()[1].Branch())
+	require.NoError(t, ValidateTTLPurgeConfig(purge))
+
+	roundTripped, err := NewYamlConfig([]byte(ServerConfigAsYAMLConfig(config).String()))
+	require.NoError(t, err)
+	require.NotNil(t, roundTripped.TTLPurgeConfig())
+	require.Equal(t, purge.IntervalMillis(), roundTripped.TTLPurgeConfig().IntervalMillis())
+	require.Equal(t, purge.BatchSize(), roundTripped.TTLPurgeConfig().BatchSize())
+	require.Equal(t, purge.MaxBatches(), roundTripped.TTLPurgeConfig().MaxBatches())
+	require.Equal(t, purge.CommitAuthor(), roundTripped.TTLPurgeConfig().CommitAuthor())
+	require.Len(t, roundTripped.TTLPurgeConfig().Branches(), 2)
+
+	config, err = NewYamlConfig([]byte(""))
+	require.NoError(t, err)
+	require.Nil(t, config.TTLPurgeConfig())
+}
+
+func TestValidateTTLPurge(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Config string
+		Error  bool
+	}{
+		{
+			Name:   "no ttl_purge: config",
+			Config: "",
+			Error:  false,
+		},
+		{
+			Name: "no branches",
+			Config: `
+ttl_purge:
+  interval_millis: 1000
+`,
+			Error: true,
+		},
+		{
+			Name: "branch without database",
+			Config: `
+ttl_purge:
+  branches:
+  - branch: main
+`,
+			Error: true,
+		},
+		{
+			Name: "zero batch_size",
+			Config: `
+ttl_purge:
+  batch_size: 0
+  branches:
+  - database: events
+    branch: main
+`,
+			Error: true,
+		},
+		{
+			Name: "negative interval_millis",
+			Config: `
+ttl_purge:
+  interval_millis: -1
+  branches:
+  - database: events
+    branch: main
+`,
+			Error: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg, err := NewYamlConfig([]byte(c.Config))
+			require.NoError(t, err)
+			if c.Error {
+				require.Error(t, ValidateTTLPurgeConfig(cfg.TTLPurgeConfig()))
+			} else {
+				require.NoError(t, ValidateTTLPurgeConfig(cfg.TTLPurgeConfig()))
+			}
+		})
+	}
+}
+
+func TestUnmarshallResultCache(t *testing.T) {
+	testStr := `
+result_cache:
+  max_memory_mb: 16
+`
+	config, err := NewYamlConfig([]byte(testStr))
+	require.NoError(t, err)
+	cache := config.ResultCacheConfig()
+	require.NotNil(t, cache)
+	require.Equal(t, uint64(16), cache.MaxMemoryMb())
+	require.Equal(t, uint64(defaultResultCacheMaxResultKb), cache.MaxResultKb())
+	require.NoError(t, ValidateResultCacheConfig(cache))
+
+	roundTripped, err := NewYamlConfig([]byte(ServerConfigAsYAMLConfig(config).String()))
+	require.NoError(t, err)
+	require.NotNil(t, roundTripped.ResultCacheConfig())
+	require.Equal(t, cache.MaxMemoryMb(), roundTripped.ResultCacheConfig().MaxMemoryMb())
+	require.Equal(t, cache.MaxResultKb(), roundTripped.ResultCacheConfig().MaxResultKb())
+
+	config, err = NewYamlConfig([]byte(""))
+	require.NoError(t, err)
+	require.Nil(t, config.ResultCacheConfig())
+}
+
+func TestValidateResultCache(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Config string
+		Error  bool
+	}{
+		{
+			Name:   "no result_cache: config",
+			Config: "",
+			Error:  false,
+		},
+		{
+			Name: "defaults",
+			Config: `
+result_cache: {}
+`,
+			Error: false,
+		},
+		{
+			Name: "zero max_memory_mb",
+			Config: `
+result_cache:
+  max_memory_mb: 0
+`,
+			Error: true,
+		},
+		{
+			Name: "zero max_result_kb",
+			Config: `
+result_cache:
+  max_result_kb: 0
+`,
+			Error: true,
+		},
+		{
+			Name: "max_result_kb larger than max_memory_mb",
+			Config: `
+result_cache:
+  max_memory_mb: 1
+  max_result_kb: 2048
+`,
+			Error: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg, err := NewYamlConfig([]byte(c.Config))
+			require.NoError(t, err)
+			if c.Error {
+				require.Error(t, ValidateResultCacheConfig(cfg.ResultCacheConfig()))
+			} else {
+				require.NoError(t, ValidateResultCacheConfig(cfg.ResultCacheConfig()))
+			}
+		})
+	}
+}
@@ -198,6 +198,11 @@ func modifyColumn(
 	if err != nil {
 		return nil, err
 	}
+	ttlSpec, err := ttlForColumnChange(sch, newSchema, existingCol.Name, newCol.Name)
+	if err != nil {
+		return nil, err
+	}
+	newSchema.SetTTL(ttlSpec)
 
 	return tbl.UpdateSchema(ctx, newSchema)
 }
@@ -271,7 +276,9 @@ func replaceColumnInSchema(sch schema.Schema, oldCol schema.Column, newCol schem
 				IsFullText:         index.IsFullText(),
 				IsUserDefined:      index.IsUserDefined(),
 				Comment:            index.Comment(),
+				Predicate:          index.Predicate(),
 				FullTextProperties: index.FullTextProperties(),
+				Expressions:        index.Expressions(),
 			})
 		if err != nil {
 			return nil, err
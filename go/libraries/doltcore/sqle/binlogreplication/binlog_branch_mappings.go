@@ -0,0 +1,130 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binlogreplication
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BinlogBranchMappings maps the branches of Dolt databases to the names of the databases (i.e. schemas) that their
+// changes are replicated to in the binlog, in addition to the default mapping of each database's @@log_bin_branch
+// branch to a database of the same name.
+var BinlogBranchMappings = &branchMappings{}
+
+// databasePlaceholder is replaced with the name of the Dolt database in the schema names of branch mappings.
+const databasePlaceholder = "{database}"
+
+// branchMapping maps the branch |branch| of the Dolt database |database| to the replicated database |schema|. A
+// |database| of "*" matches every database, and "{database}" in |schema| is replaced with the database name.
+type branchMapping struct {
+	database string
+	branch   string
+	schema   string
+}
+
+// branchMappings holds the branch mappings for binlog replication, configured with @@log_bin_branch_mappings.
+type branchMappings struct {
+	mappings []branchMapping
+}
+
+// ParseBinlogBranchMappings parses the value of @@log_bin_branch_mappings, which is a comma separated list of
+// <database>/<branch>=<schema> mappings, e.g. "db01/release=db01_release,*/dev={database}_dev".
+func ParseBinlogBranchMappings(s string) (*branchMappings, error) {
+	bm := &branchMappings{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		source, schema, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid binlog branch mapping '%s'; expected <database>/<branch>=<schema>", entry)
+		}
+		database, branch, ok := strings.Cut(strings.TrimSpace(source), "/")
+		if !ok || database == "" || branch == "" || strings.TrimSpace(schema) == "" {
+			return nil, fmt.Errorf("invalid binlog branch mapping '%s'; expected <database>/<branch>=<schema>", entry)
+		}
+
+		bm.mappings = append(bm.mappings, branchMapping{
+			database: database,
+			branch:   branch,
+			schema:   strings.TrimSpace(schema),
+		})
+	}
+
+	return bm, nil
+}
+
+// schemaFor returns the name of the database that changes to the branch |branch| of the Dolt database |database|
+// are replicated to, or false if changes to that branch aren't replicated. Mappings for a specific database take
+// precedence over mappings for every database. If no mapping matches, the @@log_bin_branch branch is replicated to a
+// database of the same name, unless another branch of the database is mapped to that name.
+func (bm *branchMappings) schemaFor(database, branch string) (string, bool) {
+	if schema, ok := bm.mappedSchemaFor(database, branch); ok {
+		return schema, true
+	}
+
+	if branch != BinlogBranch {
+		return "", false
+	}
+	for _, m := range bm.mappings {
+		if m.matchesDatabase(database) && m.schemaName(database) == database {
+			return "", false
+		}
+	}
+	return database, true
+}
+
+// mappedSchemaFor returns the name of the database that a mapping replicates the branch |branch| of the Dolt
+// database |database| to, or false if no mapping matches.
+func (bm *branchMappings) mappedSchemaFor(database, branch string) (string, bool) {
+	for _, wildcard := range []bool{false, true} {
+		for _, m := range bm.mappings {
+			if (m.database == "*") == wildcard && m.matchesDatabase(database) && m.branch == branch {
+				return m.schemaName(database), true
+			}
+		}
+	}
+	return "", false
+}
+
+// schemasFor returns the names of all databases that branches of the Dolt database |database| may be replicated
+// to. The first name is the database that the @@log_bin_branch branch is replicated to, or "" if it isn't.
+func (bm *branchMappings) schemasFor(database string) []string {
+	defaultSchema, _ := bm.schemaFor(database, BinlogBranch)
+	schemas := []string{defaultSchema}
+	seen := map[string]bool{defaultSchema: true}
+	for _, m := range bm.mappings {
+		if !m.matchesDatabase(database) {
+			continue
+		}
+		schema := m.schemaName(database)
+		if !seen[schema] {
+			seen[schema] = true
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+func (m branchMapping) matchesDatabase(database string) bool {
+	return m.database == "*" || strings.EqualFold(m.database, database)
+}
+
+func (m branchMapping) schemaName(database string) string {
+	return strings.ReplaceAll(m.schema, databasePlaceholder, database)
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binlogreplication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBinlogBranchMappings(t *testing.T) {
+	for _, invalid := range []string{"db01", "db01=db01_dev", "db01/=db01_dev", "/dev=db01_dev", "db01/dev="} {
+		_, err := ParseBinlogBranchMappings(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	bm, err := ParseBinlogBranchMappings("")
+	require.NoError(t, err)
+	require.Empty(t, bm.mappings)
+
+	bm, err = ParseBinlogBranchMappings(" db01/release = db01_release, */dev={database}_dev,")
+	require.NoError(t, err)
+	require.Equal(t, []branchMapping{
+		{database: "db01", branch: "release", schema: "db01_release"},
+		{database: "*", branch: "dev", schema: "{database}_dev"},
+	}, bm.mappings)
+}
+
+func TestBranchMappingsSchemaFor(t *testing.T) {
+	bm, err := ParseBinlogBranchMappings("db01/release=db01_release,*/dev={database}_dev,db02/dev=db02_staging,db03/prod=db03")
+	require.NoError(t, err)
+
+	tests := []struct {
+		database string
+		branch   string
+		schema   string
+		ok       bool
+	}{
+		{database: "db01", branch: BinlogBranch, schema: "db01", ok: true},
+		{database: "db01", branch: "release", schema: "db01_release", ok: true},
+		{database: "db01", branch: "dev", schema: "db01_dev", ok: true},
+		{database: "db01", branch: "feature", ok: false},
+		{database: "db02", branch: "dev", schema: "db02_staging", ok: true},
+		{database: "db03", branch: "prod", schema: "db03", ok: true},
+		{database: "db03", branch: BinlogBranch, ok: false},
+	}
+	for _, tt := range tests {
+		schema, ok := bm.schemaFor(tt.database, tt.branch)
+		require.Equal(t, tt.ok, ok, "%s/%s", tt.database, tt.branch)
+		require.Equal(t, tt.schema, schema, "%s/%s", tt.database, tt.branch)
+	}
+
+	_, ok := bm.mappedSchemaFor("db01", BinlogBranch)
+	require.False(t, ok)
+
+	require.Equal(t, []string{"db01", "db01_release", "db01_dev"}, bm.schemasFor("db01"))
+	require.Equal(t, []string{"db02", "db02_dev", "db02_staging"}, bm.schemasFor("db02"))
+	require.Equal(t, []string{"", "db03_dev", "db03"}, bm.schemasFor("db03"))
+}
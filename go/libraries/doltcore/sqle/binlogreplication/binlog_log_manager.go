@@ -0,0 +1,643 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binlogreplication
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+// binlogDirectory is the directory, relative to the root of the sql-server's filesystem, where binary log
+// files and their index are stored.
+var binlogDirectory = filepath.Join(binlogPositionDirectory, "binlog")
+
+// binlogIndexFilename is the name of the file listing the binary log files, oldest first, in the same
+// format as MySQL's binlog index file.
+const binlogIndexFilename = "binlog.index"
+
+// binlogFileMagicNumber is the header at the start of every binary log file.
+var binlogFileMagicNumber = []byte{0xfe, 0x62, 0x69, 0x6e}
+
+// defaultMaxBinlogSize and defaultBinlogExpireLogsSeconds are MySQL's defaults for @@max_binlog_size and
+// @@binlog_expire_logs_seconds, used if those system variables aren't defined.
+const defaultMaxBinlogSize = 1024 * 1024 * 1024
+const defaultBinlogExpireLogsSeconds = 30 * 24 * 60 * 60
+
+// ErrBinlogPurged is returned when a replica requests to start streaming from a GTID set that doesn't include
+// transactions that are no longer available in the binary log files.
+var ErrBinlogPurged = errors.New("cannot replicate because the source purged required binary logs; " +
+	"replicate the missing transactions from elsewhere, or provision a new replica from backup")
+
+// logManager manages the binary log files for a binlog primary. Events are appended to the current log file,
+// which is rotated once it grows past @@max_binlog_size, and log files are purged either explicitly or once
+// they are older than @@binlog_expire_logs_seconds. Each log file uses MySQL's binary log file format: a magic
+// number, then a FormatDescription event, a PreviousGtids event recording the GTIDs written to earlier log
+// files, the logged events, and, for all but the current log file, a Rotate event naming the next log file.
+type logManager struct {
+	mu *sync.Mutex
+
+	binlogDirectory string
+	binlogFormat    *mysql.BinlogFormat
+	binlogEventMeta mysql.BinlogEventMetadata
+
+	logFiles              []string
+	previousGtids         map[string]mysql.Mysql56GTIDSet
+	currentBinlogFile     *os.File
+	currentBinlogFileName string
+	currentPosition       uint32
+
+	// openReaders counts the readers of each log file, so that log files that replicas are still streaming
+	// from aren't purged
+	openReaders map[string]int
+
+	// executedGtids holds all GTIDs written to the binary log files, including purged log files
+	executedGtids mysql.Mysql56GTIDSet
+}
+
+// NewLogManager creates a new logManager that stores binary log files under the specified |fs|. Any existing
+// log files are loaded, expired log files are purged, and a new log file is started, just as MySQL starts a
+// new binary log file each time the server starts.
+func NewLogManager(fs filesys.Filesys) (*logManager, error) {
+	binlogEventMeta, err := createBinlogEventMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = fs.MkDirs(binlogDirectory); err != nil {
+		return nil, fmt.Errorf("unable to create binlog directory: %s", err.Error())
+	}
+	dir, err := fs.Abs(binlogDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	lm := &logManager{
+		mu:              &sync.Mutex{},
+		binlogDirectory: dir,
+		binlogFormat:    createBinlogFormat(),
+		binlogEventMeta: *binlogEventMeta,
+		executedGtids:   mysql.Mysql56GTIDSet{},
+		previousGtids:   make(map[string]mysql.Mysql56GTIDSet),
+		openReaders:     make(map[string]int),
+	}
+
+	if err = lm.loadIndex(); err != nil {
+		return nil, err
+	}
+	if err = lm.reopenNewestLogFile(); err != nil {
+		return nil, err
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	if err = lm.rotate(); err != nil {
+		return nil, err
+	}
+	if err = lm.purgeExpiredLogs(); err != nil {
+		return nil, err
+	}
+
+	return lm, nil
+}
+
+// WriteEvents appends |binlogEvents| to the current binary log file and syncs it to disk. If the log file has
+// grown past @@max_binlog_size, it is rotated, and any expired log files are purged.
+func (lm *logManager) WriteEvents(binlogEvents []mysql.BinlogEvent) error {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	for _, binlogEvent := range binlogEvents {
+		if err := lm.writeEvent(binlogEvent); err != nil {
+			return err
+		}
+
+		if binlogEvent.IsGTID() {
+			gtid, _, err := binlogEvent.GTID(*lm.binlogFormat)
+			if err != nil {
+				return err
+			}
+			lm.executedGtids = lm.executedGtids.AddGTID(gtid).(mysql.Mysql56GTIDSet)
+		}
+	}
+
+	if err := lm.currentBinlogFile.Sync(); err != nil {
+		return err
+	}
+
+	maxBinlogSize, err := lookupIntegerSystemVariable("max_binlog_size", defaultMaxBinlogSize)
+	if err != nil {
+		return err
+	}
+	if int64(lm.currentPosition) < maxBinlogSize {
+		return nil
+	}
+
+	if err = lm.rotate(); err != nil {
+		return err
+	}
+	return lm.purgeExpiredLogs()
+}
+
+// currentFile returns the name of the binary log file currently being written, and the position in that file
+// where the next event will be written.
+func (lm *logManager) currentFile() (string, uint32) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.currentBinlogFileName, lm.currentPosition
+}
+
+// ListLogFiles returns the name and size of each binary log file, oldest first.
+func (lm *logManager) ListLogFiles() ([]string, []int64, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	names := make([]string, len(lm.logFiles))
+	sizes := make([]int64, len(lm.logFiles))
+	for i, name := range lm.logFiles {
+		info, err := os.Stat(lm.logFilePath(name))
+		if err != nil {
+			return nil, nil, err
+		}
+		names[i] = name
+		sizes[i] = info.Size()
+	}
+	return names, sizes, nil
+}
+
+// PurgeLogsTo deletes all binary log files before the log file named |filename|. If |filename| isn't one of the
+// binary log files, an error is returned. Log files that a replica is still reading, and the log files after
+// them, are not deleted; the name of the first such log file is returned, or the empty string if every log
+// file before |filename| was deleted.
+func (lm *logManager) PurgeLogsTo(filename string) (string, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	for i, name := range lm.logFiles {
+		if name == filename {
+			return lm.purgeLogs(i)
+		}
+	}
+	return "", fmt.Errorf("target log %s not found in binlog index", filename)
+}
+
+// PurgeLogsBefore deletes the binary log files that were last written to before |t|. The current log file is
+// never deleted, and neither are log files that a replica is still reading, nor the log files after them. The
+// name of the first log file that was kept because it is being read is returned, or the empty string.
+func (lm *logManager) PurgeLogsBefore(t time.Time) (string, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.purgeLogsBefore(t)
+}
+
+// findLogFileForGtids returns the name of the binary log file a replica that has already executed
+// |executedGtids| should start streaming from: the newest log file whose earlier log files only contain GTIDs
+// in |executedGtids|. If the replica is missing GTIDs that were in purged log files, ErrBinlogPurged is returned.
+func (lm *logManager) findLogFileForGtids(executedGtids mysql.GTIDSet) (string, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	for i := len(lm.logFiles) - 1; i >= 0; i-- {
+		previousGtids := lm.previousGtids[lm.logFiles[i]]
+		if len(previousGtids) == 0 || (executedGtids != nil && executedGtids.Contains(previousGtids)) {
+			return lm.logFiles[i], nil
+		}
+	}
+
+	return "", ErrBinlogPurged
+}
+
+// openLogFile returns a reader for the binary log file named |filename|, positioned at the first event. The log
+// file isn't purged until the reader is closed.
+func (lm *logManager) openLogFile(filename string) (*binlogFileReader, error) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	file, err := os.Open(lm.logFilePath(filename))
+	if err != nil {
+		return nil, err
+	}
+	lm.openReaders[filename]++
+
+	return &binlogFileReader{
+		logManager:   lm,
+		filename:     filename,
+		file:         file,
+		position:     uint32(len(binlogFileMagicNumber)),
+		binlogFormat: *lm.binlogFormat,
+	}, nil
+}
+
+// readLimit returns the position in the log file named |filename| up to which complete events have been
+// written, or false if the log file is no longer being written and can be read to its end.
+func (lm *logManager) readLimit(filename string) (uint32, bool) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if filename != lm.currentBinlogFileName {
+		return 0, false
+	}
+	return lm.currentPosition, true
+}
+
+// rotate ends the current binary log file with a Rotate event and starts writing to a new log file. Callers
+// must hold |lm.mu|.
+func (lm *logManager) rotate() error {
+	nextLogFile, err := lm.nextLogFilename()
+	if err != nil {
+		return err
+	}
+
+	if lm.currentBinlogFile != nil {
+		binlogEventMeta := lm.binlogEventMeta
+		binlogEventMeta.NextLogPosition = lm.currentPosition
+		rotateEvent := mysql.NewRotateEvent(*lm.binlogFormat, binlogEventMeta, uint64(len(binlogFileMagicNumber)), nextLogFile)
+		if err = lm.writeEvent(rotateEvent); err != nil {
+			return err
+		}
+		if err = lm.currentBinlogFile.Sync(); err != nil {
+			return err
+		}
+		if err = lm.currentBinlogFile.Close(); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(lm.logFilePath(nextLogFile), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(binlogFileMagicNumber); err != nil {
+		return err
+	}
+
+	logrus.Debugf("rotating to binary log file %s", nextLogFile)
+	lm.currentBinlogFile = file
+	lm.currentBinlogFileName = nextLogFile
+	lm.currentPosition = uint32(len(binlogFileMagicNumber))
+
+	binlogEventMeta := lm.binlogEventMeta
+	binlogEventMeta.Timestamp = uint32(time.Now().Unix())
+	binlogEventMeta.NextLogPosition = lm.currentPosition
+	formatDescriptionEvent := mysql.NewFormatDescriptionEvent(*lm.binlogFormat, binlogEventMeta)
+	if err = lm.writeEvent(formatDescriptionEvent); err != nil {
+		return err
+	}
+
+	binlogEventMeta.NextLogPosition = lm.currentPosition
+	previousGtidsEvent := mysql.NewPreviousGtidsEvent(*lm.binlogFormat, binlogEventMeta, lm.executedGtids)
+	if err = lm.writeEvent(previousGtidsEvent); err != nil {
+		return err
+	}
+	if err = lm.currentBinlogFile.Sync(); err != nil {
+		return err
+	}
+
+	lm.logFiles = append(lm.logFiles, nextLogFile)
+	lm.previousGtids[nextLogFile] = lm.executedGtids
+	return lm.saveIndex()
+}
+
+// writeEvent writes |binlogEvent| to the current binary log file. Callers must hold |lm.mu|.
+func (lm *logManager) writeEvent(binlogEvent mysql.BinlogEvent) error {
+	if _, err := lm.currentBinlogFile.Write(binlogEvent.Bytes()); err != nil {
+		return fmt.Errorf("unable to write to binary log file %s: %s", lm.currentBinlogFileName, err.Error())
+	}
+	lm.currentPosition += binlogEvent.Length()
+	return nil
+}
+
+// nextLogFilename returns the name of the next binary log file, which is named for the replicated branch and
+// numbered one higher than the newest existing log file.
+func (lm *logManager) nextLogFilename() (string, error) {
+	number := 1
+	if len(lm.logFiles) > 0 {
+		newest := lm.logFiles[len(lm.logFiles)-1]
+		i, err := strconv.Atoi(newest[strings.LastIndex(newest, ".")+1:])
+		if err != nil {
+			return "", fmt.Errorf("unexpected binary log file name: %s", newest)
+		}
+		number = i + 1
+	}
+
+	return fmt.Sprintf("binlog-%s.%06d", BinlogBranch, number), nil
+}
+
+// purgeExpiredLogs deletes the binary log files that are older than @@binlog_expire_logs_seconds, unless it is
+// zero. Callers must hold |lm.mu|.
+func (lm *logManager) purgeExpiredLogs() error {
+	expireLogsSeconds, err := lookupIntegerSystemVariable("binlog_expire_logs_seconds", defaultBinlogExpireLogsSeconds)
+	if err != nil {
+		return err
+	}
+	if expireLogsSeconds <= 0 {
+		return nil
+	}
+
+	inUse, err := lm.purgeLogsBefore(time.Now().Add(-time.Duration(expireLogsSeconds) * time.Second))
+	if inUse != "" {
+		logrus.Warnf("expired binary log file %s was not purged because a replica is still reading it", inUse)
+	}
+	return err
+}
+
+// purgeLogsBefore deletes the binary log files last written to before |t|. Callers must hold |lm.mu|.
+func (lm *logManager) purgeLogsBefore(t time.Time) (string, error) {
+	count := 0
+	for _, name := range lm.logFiles {
+		if name == lm.currentBinlogFileName {
+			break
+		}
+		info, err := os.Stat(lm.logFilePath(name))
+		if err != nil {
+			return "", err
+		}
+		if !info.ModTime().Before(t) {
+			break
+		}
+		count++
+	}
+
+	return lm.purgeLogs(count)
+}
+
+// purgeLogs deletes the oldest |count| binary log files and updates @@gtid_purged with the GTIDs that are no
+// longer available. Like MySQL, it stops at the first log file that a replica is still reading, and returns
+// its name. Callers must hold |lm.mu|.
+func (lm *logManager) purgeLogs(count int) (string, error) {
+	inUse := ""
+	for i, name := range lm.logFiles[:count] {
+		if lm.openReaders[name] > 0 {
+			inUse, count = name, i
+			break
+		}
+	}
+	if count == 0 {
+		return inUse, nil
+	}
+
+	for _, name := range lm.logFiles[:count] {
+		logrus.Debugf("purging binary log file %s", name)
+		err := os.Remove(lm.logFilePath(name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		delete(lm.previousGtids, name)
+	}
+	lm.logFiles = lm.logFiles[count:]
+	if err := lm.saveIndex(); err != nil {
+		return "", err
+	}
+
+	purgedGtids := lm.previousGtids[lm.logFiles[0]]
+	return inUse, sql.SystemVariables.AssignValues(map[string]any{"gtid_purged": purgedGtids.String()})
+}
+
+// closeReader records that a reader of the log file named |filename| was closed.
+func (lm *logManager) closeReader(filename string) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if lm.openReaders[filename] <= 1 {
+		delete(lm.openReaders, filename)
+	} else {
+		lm.openReaders[filename]--
+	}
+}
+
+// loadIndex loads the names of the existing binary log files from the binlog index file, along with the GTIDs
+// written before each of them.
+func (lm *logManager) loadIndex() error {
+	data, err := os.ReadFile(filepath.Join(lm.binlogDirectory, binlogIndexFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		if _, err = os.Stat(lm.logFilePath(name)); errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("binary log file %s listed in binlog index does not exist", name)
+			continue
+		} else if err != nil {
+			return err
+		}
+		previousGtids, err := lm.readPreviousGtids(name)
+		if err != nil {
+			return err
+		}
+		lm.logFiles = append(lm.logFiles, name)
+		lm.previousGtids[name] = previousGtids
+	}
+
+	return nil
+}
+
+// saveIndex writes the names of the binary log files to the binlog index file. Callers must hold |lm.mu|.
+func (lm *logManager) saveIndex() error {
+	var sb strings.Builder
+	for _, name := range lm.logFiles {
+		sb.WriteString(name)
+		sb.WriteString("\n")
+	}
+
+	indexPath := filepath.Join(lm.binlogDirectory, binlogIndexFilename)
+	tempPath := indexPath + ".tmp"
+	if err := os.WriteFile(tempPath, []byte(sb.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, indexPath)
+}
+
+// reopenNewestLogFile opens the newest existing log file as the current log file, so that rotating to a new log
+// file ends it with a Rotate event. Any partially written event at the end of the log file is truncated. The set
+// of GTIDs written to the binary logs is initialized by adding the GTIDs in the newest log file to those written
+// before it.
+func (lm *logManager) reopenNewestLogFile() error {
+	if len(lm.logFiles) == 0 {
+		return nil
+	}
+
+	newest := lm.logFiles[len(lm.logFiles)-1]
+	lm.executedGtids = lm.previousGtids[newest]
+	reader, err := lm.openLogFile(newest)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for {
+		binlogEvent, err := reader.nextEvent()
+		if err != nil {
+			return err
+		} else if binlogEvent == nil {
+			break
+		}
+
+		if binlogEvent.IsGTID() {
+			gtid, _, err := binlogEvent.GTID(reader.binlogFormat)
+			if err != nil {
+				return err
+			}
+			lm.executedGtids = lm.executedGtids.AddGTID(gtid).(mysql.Mysql56GTIDSet)
+		}
+	}
+
+	path := lm.logFilePath(newest)
+	if err = os.Truncate(path, int64(reader.position)); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	lm.currentBinlogFile = file
+	lm.currentBinlogFileName = newest
+	lm.currentPosition = reader.position
+	return nil
+}
+
+// readPreviousGtids returns the GTIDs recorded in the PreviousGtids event of the log file named |filename|,
+// which are all the GTIDs written to earlier log files.
+func (lm *logManager) readPreviousGtids(filename string) (mysql.Mysql56GTIDSet, error) {
+	reader, err := lm.openLogFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	for {
+		binlogEvent, err := reader.nextEvent()
+		if err != nil {
+			return nil, err
+		} else if binlogEvent == nil {
+			return nil, fmt.Errorf("no PreviousGtids event found in binary log file %s", filename)
+		}
+
+		if binlogEvent.IsPreviousGTIDs() {
+			return parsePreviousGtids(reader.binlogFormat, binlogEvent)
+		}
+	}
+}
+
+func (lm *logManager) logFilePath(filename string) string {
+	return filepath.Join(lm.binlogDirectory, filename)
+}
+
+// binlogFileReader reads the events in a binary log file, including a log file that is still being written.
+type binlogFileReader struct {
+	logManager   *logManager
+	filename     string
+	file         *os.File
+	position     uint32
+	binlogFormat mysql.BinlogFormat
+	closed       bool
+}
+
+// nextEvent returns the next event in the log file, or nil if there are no more complete events in the log file
+// yet.
+func (r *binlogFileReader) nextEvent() (mysql.BinlogEvent, error) {
+	limit, isCurrent := r.logManager.readLimit(r.filename)
+
+	header := make([]byte, r.binlogFormat.HeaderLength)
+	if isCurrent && r.position+uint32(len(header)) > limit {
+		return nil, nil
+	}
+	if _, err := r.file.ReadAt(header, int64(r.position)); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	length := binary.LittleEndian.Uint32(header[9:13])
+	if isCurrent && r.position+length > limit {
+		return nil, nil
+	}
+	buf := make([]byte, length)
+	if _, err := r.file.ReadAt(buf, int64(r.position)); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	r.position += length
+	binlogEvent := mysql.NewMysql56BinlogEvent(buf)
+
+	// Log files written before a restart may use a different format, e.g. if @@binlog_checksum changed
+	if binlogEvent.IsFormatDescription() {
+		binlogFormat, err := binlogEvent.Format()
+		if err != nil {
+			return nil, err
+		}
+		r.binlogFormat = binlogFormat
+	}
+
+	return binlogEvent, nil
+}
+
+// Close closes the log file being read, after which it may be purged. Closing a reader more than once has no
+// effect.
+func (r *binlogFileReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.logManager.closeReader(r.filename)
+	return r.file.Close()
+}
+
+// parsePreviousGtids returns the GTID set recorded in the PreviousGtids event |binlogEvent|.
+func parsePreviousGtids(binlogFormat mysql.BinlogFormat, binlogEvent mysql.BinlogEvent) (mysql.Mysql56GTIDSet, error) {
+	binlogEvent, _, err := binlogEvent.StripChecksum(binlogFormat)
+	if err != nil {
+		return nil, err
+	}
+	position, err := binlogEvent.PreviousGTIDs(binlogFormat)
+	if err != nil {
+		return nil, err
+	}
+	return position.GTIDSet.(mysql.Mysql56GTIDSet), nil
+}
+
+// parseRotateEvent returns the name of the next log file from the Rotate event |binlogEvent|.
+func parseRotateEvent(binlogFormat mysql.BinlogFormat, binlogEvent mysql.BinlogEvent) (string, error) {
+	binlogEvent, _, err := binlogEvent.StripChecksum(binlogFormat)
+	if err != nil {
+		return "", err
+	}
+	data := binlogEvent.Bytes()[binlogFormat.HeaderLength:]
+	if len(data) < 8 {
+		return "", fmt.Errorf("invalid Rotate event")
+	}
+	return string(data[8:]), nil
+}
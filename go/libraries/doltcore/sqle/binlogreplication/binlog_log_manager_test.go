@@ -0,0 +1,185 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binlogreplication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/filesys"
+)
+
+const testServerUuid = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+
+func newTestLogManager(t *testing.T, dir string) *logManager {
+	require.NoError(t, sql.SystemVariables.AssignValues(map[string]any{"server_id": uint32(42)}))
+	fs, err := filesys.LocalFilesysWithWorkingDir(dir)
+	require.NoError(t, err)
+	lm, err := NewLogManager(fs)
+	require.NoError(t, err)
+	return lm
+}
+
+// writeTestTransaction writes a transaction with the GTID sequence number |sequence| to the binary log.
+func writeTestTransaction(t *testing.T, lm *logManager, sequence int64) mysql.GTID {
+	sid, err := mysql.ParseSID(testServerUuid)
+	require.NoError(t, err)
+	gtid := mysql.Mysql56GTID{Server: sid, Sequence: sequence}
+
+	_, position := lm.currentFile()
+	meta := lm.binlogEventMeta
+	meta.NextLogPosition = position
+	gtidEvent := mysql.NewMySQLGTIDEvent(*lm.binlogFormat, meta, gtid, false)
+	meta.NextLogPosition += gtidEvent.Length()
+	queryEvent := mysql.NewQueryEvent(*lm.binlogFormat, meta, mysql.Query{Database: "db01", SQL: "create table t (pk int primary key);"})
+
+	require.NoError(t, lm.WriteEvents([]mysql.BinlogEvent{gtidEvent, queryEvent}))
+	return gtid
+}
+
+func readAllEvents(t *testing.T, lm *logManager, filename string) []mysql.BinlogEvent {
+	reader, err := lm.openLogFile(filename)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var events []mysql.BinlogEvent
+	for {
+		event, err := reader.nextEvent()
+		require.NoError(t, err)
+		if event == nil {
+			return events
+		}
+		events = append(events, event)
+	}
+}
+
+func TestLogManager(t *testing.T) {
+	dir := t.TempDir()
+
+	lm := newTestLogManager(t, dir)
+	filename, position := lm.currentFile()
+	require.Equal(t, "binlog-"+BinlogBranch+".000001", filename)
+	gtid := writeTestTransaction(t, lm, 1)
+	_, newPosition := lm.currentFile()
+	require.Greater(t, newPosition, position)
+
+	events := readAllEvents(t, lm, filename)
+	require.Len(t, events, 4)
+	require.True(t, events[0].IsFormatDescription())
+	require.True(t, events[1].IsPreviousGTIDs())
+	require.True(t, events[2].IsGTID())
+	require.True(t, events[3].IsQuery())
+
+	// Restarting starts a new log file, and ends the previous one with a Rotate event
+	lm = newTestLogManager(t, dir)
+	names, sizes, err := lm.ListLogFiles()
+	require.NoError(t, err)
+	require.Equal(t, []string{"binlog-" + BinlogBranch + ".000001", "binlog-" + BinlogBranch + ".000002"}, names)
+	require.Len(t, sizes, 2)
+
+	events = readAllEvents(t, lm, names[0])
+	require.Len(t, events, 5)
+	require.True(t, events[4].IsRotate())
+	nextFile, err := parseRotateEvent(*lm.binlogFormat, events[4])
+	require.NoError(t, err)
+	require.Equal(t, names[1], nextFile)
+
+	events = readAllEvents(t, lm, names[1])
+	require.Len(t, events, 2)
+	previousGtids, err := parsePreviousGtids(*lm.binlogFormat, events[1])
+	require.NoError(t, err)
+	require.True(t, previousGtids.ContainsGTID(gtid))
+
+	// Replicas start streaming from the first log file with GTIDs they haven't executed
+	filename, err = lm.findLogFileForGtids(mysql.Mysql56GTIDSet{})
+	require.NoError(t, err)
+	require.Equal(t, names[0], filename)
+	filename, err = lm.findLogFileForGtids(mysql.Mysql56GTIDSet{}.AddGTID(gtid))
+	require.NoError(t, err)
+	require.Equal(t, names[1], filename)
+
+	// Once the first log file is purged, a replica without its GTIDs can't replicate
+	inUse, err := lm.PurgeLogsTo(names[1])
+	require.NoError(t, err)
+	require.Empty(t, inUse)
+	names, _, err = lm.ListLogFiles()
+	require.NoError(t, err)
+	require.Equal(t, []string{"binlog-" + BinlogBranch + ".000002"}, names)
+	_, err = lm.findLogFileForGtids(mysql.Mysql56GTIDSet{})
+	require.ErrorIs(t, err, ErrBinlogPurged)
+	filename, err = lm.findLogFileForGtids(mysql.Mysql56GTIDSet{}.AddGTID(gtid))
+	require.NoError(t, err)
+	require.Equal(t, names[0], filename)
+
+	_, err = lm.PurgeLogsTo("binlog-" + BinlogBranch + ".000001")
+	require.Error(t, err)
+
+	// The current log file is never purged
+	_, err = lm.PurgeLogsBefore(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	names, _, err = lm.ListLogFiles()
+	require.NoError(t, err)
+	require.Len(t, names, 1)
+
+	// The GTIDs written to the newest log file are loaded on restart
+	writeTestTransaction(t, lm, 2)
+	lm = newTestLogManager(t, dir)
+	names, _, err = lm.ListLogFiles()
+	require.NoError(t, err)
+	require.Len(t, names, 2)
+	require.Equal(t, "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-2", lm.executedGtids.String())
+}
+
+// TestLogManagerKeepsLogFilesBeingRead tests that log files with open readers, and the log files after them, are
+// not purged until the readers are closed.
+func TestLogManagerKeepsLogFilesBeingRead(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTransaction(t, newTestLogManager(t, dir), 1)
+	writeTestTransaction(t, newTestLogManager(t, dir), 2)
+	lm := newTestLogManager(t, dir)
+	names, _, err := lm.ListLogFiles()
+	require.NoError(t, err)
+	require.Len(t, names, 3)
+
+	reader, err := lm.openLogFile(names[1])
+	require.NoError(t, err)
+	inUse, err := lm.PurgeLogsTo(names[2])
+	require.NoError(t, err)
+	require.Equal(t, names[1], inUse)
+	remaining, _, err := lm.ListLogFiles()
+	require.NoError(t, err)
+	require.Equal(t, names[1:], remaining)
+
+	// Closing a reader twice doesn't release a log file that another reader still has open
+	other, err := lm.openLogFile(names[1])
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	require.NoError(t, reader.Close())
+	inUse, err = lm.PurgeLogsBefore(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, names[1], inUse)
+
+	require.NoError(t, other.Close())
+	inUse, err = lm.PurgeLogsTo(names[2])
+	require.NoError(t, err)
+	require.Empty(t, inUse)
+	remaining, _, err = lm.ListLogFiles()
+	require.NoError(t, err)
+	require.Equal(t, names[2:], remaining)
+}
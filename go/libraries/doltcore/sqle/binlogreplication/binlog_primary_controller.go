@@ -16,11 +16,18 @@ package binlogreplication
 
 import (
 	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/binlogreplication"
+	"github.com/dolthub/go-mysql-server/sql/types"
 	"github.com/dolthub/vitess/go/mysql"
 	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 )
 
 // doltBinlogPrimaryController implements the binlogreplication.BinlogPrimaryController
@@ -29,14 +36,32 @@ import (
 type doltBinlogPrimaryController struct {
 	streamerManager *binlogStreamerManager
 	BinlogProducer  *binlogProducer
+	logManager      *logManager
+
+	// registeredReplicas holds the replicas that have registered over a connection, keyed by connection ID, until
+	// they request to start streaming binlog events over that connection.
+	registeredReplicas   map[uint32]registeredReplica
+	registeredReplicasMu *sync.Mutex
 }
 
 var _ binlogreplication.BinlogPrimaryController = (*doltBinlogPrimaryController)(nil)
+var _ dtables.BinlogReplicaStatusProvider = (*doltBinlogPrimaryController)(nil)
+
+// registeredReplica describes a replica, as reported by the replica when it registers with this server. The server ID
+// a replica sends in COM_REGISTER_SLAVE and COM_BINLOG_DUMP_GTID is dropped when the packets are parsed, so it isn't
+// recorded.
+type registeredReplica struct {
+	replicaUuid string
+	host        string
+	port        uint16
+}
 
 // NewDoltBinlogPrimaryController creates a new doltBinlogPrimaryController instance.
 func NewDoltBinlogPrimaryController() *doltBinlogPrimaryController {
 	controller := doltBinlogPrimaryController{
-		streamerManager: newBinlogStreamerManager(),
+		streamerManager:      newBinlogStreamerManager(),
+		registeredReplicas:   make(map[uint32]registeredReplica),
+		registeredReplicasMu: &sync.Mutex{},
 	}
 	return &controller
 }
@@ -45,28 +70,78 @@ func (d *doltBinlogPrimaryController) StreamerManager() *binlogStreamerManager {
 	return d.streamerManager
 }
 
+// LogManager sets the logManager that manages the binary log files that binlog events are streamed from.
+func (d *doltBinlogPrimaryController) LogManager(logManager *logManager) {
+	d.logManager = logManager
+	d.streamerManager.LogManager(logManager)
+}
+
 // RegisterReplica implements the BinlogPrimaryController interface.
 //
 // NOTE: This method is invoked from a replica sending a command before the replica requests to start streaming the
-// binlog events. We don't currently record the information on registered replicas, but we will eventually need it
-// to implement the ListReplicas method below. For now, this method is still useful to throw errors back to the
-// replica if bin logging isn't enabled, since errors returned from the BinlogDumpGtid method seem to be dropped
-// by the replica, instead of being displayed as an error.
+// binlog events. The replica's information is recorded until it starts streaming over the same connection, so that
+// it can be reported in the replica's status. This method is also useful to throw errors back to the replica if bin
+// logging isn't enabled, since errors returned from the BinlogDumpGtid method seem to be dropped by the replica,
+// instead of being displayed as an error.
 func (d *doltBinlogPrimaryController) RegisterReplica(ctx *sql.Context, c *mysql.Conn, replicaHost string, replicaPort uint16) error {
 	if d.BinlogProducer == nil {
 		return fmt.Errorf("no binlog currently being recorded; make sure the server is started with @@log_bin enabled")
 	}
 
+	d.registeredReplicasMu.Lock()
+	defer d.registeredReplicasMu.Unlock()
+	d.registeredReplicas[c.ConnectionID] = registeredReplica{
+		replicaUuid: replicaUuid(ctx),
+		host:        replicaHost,
+		port:        replicaPort,
+	}
+
 	return nil
 }
 
+// takeRegisteredReplica returns the replica that registered over the connection |c| and forgets its registration.
+// If no replica registered over |c|, the replica is described by the connection's remote address instead.
+func (d *doltBinlogPrimaryController) takeRegisteredReplica(ctx *sql.Context, c *mysql.Conn) registeredReplica {
+	d.registeredReplicasMu.Lock()
+	defer d.registeredReplicasMu.Unlock()
+
+	replica, ok := d.registeredReplicas[c.ConnectionID]
+	if ok {
+		delete(d.registeredReplicas, c.ConnectionID)
+		return replica
+	}
+
+	replica = registeredReplica{replicaUuid: replicaUuid(ctx)}
+	if addr, ok := c.RemoteAddr().(*net.TCPAddr); ok {
+		replica.host = addr.IP.String()
+		replica.port = uint16(addr.Port)
+	}
+	return replica
+}
+
+// replicaUuid returns the server UUID that a MySQL replica reports by setting the @replica_uuid (or, on older
+// versions, @slave_uuid) user variable on its connection, or "" if it hasn't been set.
+func replicaUuid(ctx *sql.Context) string {
+	for _, name := range []string{"replica_uuid", "slave_uuid"} {
+		_, value, err := ctx.Session.GetUserVariable(ctx, name)
+		if err != nil || value == nil {
+			continue
+		}
+		if s, ok := value.(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
 // BinlogDumpGtid implements the BinlogPrimaryController interface.
 func (d *doltBinlogPrimaryController) BinlogDumpGtid(ctx *sql.Context, conn *mysql.Conn, gtidSet mysql.GTIDSet) error {
 	if d.BinlogProducer == nil {
 		return fmt.Errorf("no binlog currently being recorded; make sure the server is started with @@log_bin enabled")
 	}
 
-	err := d.streamerManager.StartStream(ctx, conn, d.BinlogProducer.binlogFormat, d.BinlogProducer.binlogEventMeta)
+	replica := d.takeRegisteredReplica(ctx, conn)
+	err := d.streamerManager.StartStream(ctx, conn, replica, gtidSet, d.BinlogProducer.binlogFormat, d.BinlogProducer.binlogEventMeta)
 	if err != nil {
 		logrus.Warnf("exiting binlog streamer due to error: %s", err.Error())
 	} else {
@@ -77,21 +152,159 @@ func (d *doltBinlogPrimaryController) BinlogDumpGtid(ctx *sql.Context, conn *mys
 }
 
 // ListReplicas implements the BinlogPrimaryController interface.
-func (d *doltBinlogPrimaryController) ListReplicas(ctx *sql.Context) error {
-	return fmt.Errorf("ListReplicas not implemented in Dolt yet")
+//
+// NOTE: The BinlogPrimaryController interface can't return the replicas for GMS to display, so Dolt's parser rewrites
+// SHOW REPLICAS into a call of the dolt_show_replicas stored procedure, which lists BinlogReplicaStatuses, and there
+// is nothing for this method to do.
+func (d *doltBinlogPrimaryController) ListReplicas(_ *sql.Context) error {
+	return nil
+}
+
+// BinlogReplicaStatuses implements the dtables.BinlogReplicaStatusProvider interface and returns the status of each
+// replica that is currently streaming binlog events from this server.
+func (d *doltBinlogPrimaryController) BinlogReplicaStatuses() []dtables.BinlogReplicaStatus {
+	return d.streamerManager.BinlogReplicaStatuses()
 }
 
 // ListBinaryLogs implements the BinlogPrimaryController interface.
 func (d *doltBinlogPrimaryController) ListBinaryLogs(_ *sql.Context) ([]binlogreplication.BinaryLogFileMetadata, error) {
-	// TODO: No log file support yet, so just return an empty list
-	return nil, nil
+	if d.logManager == nil {
+		return nil, nil
+	}
+
+	names, sizes, err := d.logManager.ListLogFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	logFiles := make([]binlogreplication.BinaryLogFileMetadata, len(names))
+	for i := range names {
+		logFiles[i] = binlogreplication.BinaryLogFileMetadata{
+			Name: names[i],
+			Size: uint64(sizes[i]),
+		}
+	}
+	return logFiles, nil
 }
 
 // GetBinaryLogStatus implements the BinlogPrimaryController interface.
 func (d *doltBinlogPrimaryController) GetBinaryLogStatus(ctx *sql.Context) ([]binlogreplication.BinaryLogStatus, error) {
+	if d.BinlogProducer == nil || d.logManager == nil {
+		return nil, nil
+	}
+
+	filename, position := d.logManager.currentFile()
 	return []binlogreplication.BinaryLogStatus{{
-		File:          binlogFilename,
-		Position:      uint(d.BinlogProducer.binlogEventMeta.NextLogPosition),
+		File:          filename,
+		Position:      uint(position),
 		ExecutedGtids: d.BinlogProducer.currentGtidPosition(),
 	}}, nil
 }
+
+// PurgeBinaryLogsTo deletes the binary log files before the log file named |filename|, like MySQL's
+// PURGE BINARY LOGS TO statement. As in MySQL, a log file that a connected replica is still reading isn't
+// deleted, nor are the log files after it, and a warning is added to |ctx|.
+func (d *doltBinlogPrimaryController) PurgeBinaryLogsTo(ctx *sql.Context, filename string) error {
+	if d.logManager == nil {
+		return fmt.Errorf("no binlog currently being recorded; make sure the server is started with @@log_bin enabled")
+	}
+	inUse, err := d.logManager.PurgeLogsTo(filename)
+	warnLogFileInUse(ctx, inUse)
+	return err
+}
+
+// PurgeBinaryLogsBefore deletes the binary log files last written to before |t|, like MySQL's
+// PURGE BINARY LOGS BEFORE statement. Log files that connected replicas are still reading are kept, the same
+// as for PurgeBinaryLogsTo.
+func (d *doltBinlogPrimaryController) PurgeBinaryLogsBefore(ctx *sql.Context, t time.Time) error {
+	if d.logManager == nil {
+		return fmt.Errorf("no binlog currently being recorded; make sure the server is started with @@log_bin enabled")
+	}
+	inUse, err := d.logManager.PurgeLogsBefore(t)
+	warnLogFileInUse(ctx, inUse)
+	return err
+}
+
+// warnLogFileInUse adds a warning to |ctx| that the binary log file named |filename| and the log files after it
+// were not purged because a replica is still reading it, unless |filename| is empty.
+func warnLogFileInUse(ctx *sql.Context, filename string) {
+	if filename == "" {
+		return
+	}
+	ctx.Warn(1105, "binary log file %s was not purged because a replica is still reading it; "+
+		"only the log files before it were purged", filename)
+}
+
+// checkPurgeBinaryLogsPrivs returns an error if the current user doesn't have the SUPER privilege. Purging
+// binary log files permanently removes the history that replicas need to catch up, so like
+// dolt_purge_dropped_databases, it is restricted to admins.
+func checkPurgeBinaryLogsPrivs(ctx *sql.Context) error {
+	privs, counter := ctx.GetPrivilegeSet()
+	if counter == 0 {
+		return fmt.Errorf("unable to check user privileges for dolt_purge_binary_logs procedure")
+	}
+	if privs.Has(sql.PrivilegeType_Super) == false {
+		return sql.ErrPrivilegeCheckFailed.New(ctx.Session.Client().User)
+	}
+
+	return nil
+}
+
+type procedurestore interface {
+	Register(sql.ExternalStoredProcedureDetails)
+}
+
+// RegisterStoredProcedures registers the stored procedures for managing the binary log files with |store|.
+func (d *doltBinlogPrimaryController) RegisterStoredProcedures(store procedurestore) {
+	store.Register(newPurgeBinaryLogsProcedure(d))
+}
+
+// newPurgeBinaryLogsProcedure returns the dolt_purge_binary_logs stored procedure, which purges binary log files
+// the same way as MySQL's PURGE BINARY LOGS statement: dolt_purge_binary_logs('to', <log file name>) or
+// dolt_purge_binary_logs('before', <datetime>). It requires the SUPER privilege.
+func newPurgeBinaryLogsProcedure(controller *doltBinlogPrimaryController) sql.ExternalStoredProcedureDetails {
+	return sql.ExternalStoredProcedureDetails{
+		Name: "dolt_purge_binary_logs",
+		Schema: sql.Schema{
+			&sql.Column{
+				Name:     "status",
+				Type:     types.Int64,
+				Nullable: false,
+			},
+		},
+		Function: func(ctx *sql.Context, purgeType string, value string) (sql.RowIter, error) {
+			if err := checkPurgeBinaryLogsPrivs(ctx); err != nil {
+				return nil, err
+			}
+
+			var err error
+			switch strings.ToLower(purgeType) {
+			case "to":
+				err = controller.PurgeBinaryLogsTo(ctx, value)
+			case "before":
+				var t time.Time
+				t, err = parsePurgeDatetime(value)
+				if err != nil {
+					return nil, err
+				}
+				err = controller.PurgeBinaryLogsBefore(ctx, t)
+			default:
+				return nil, fmt.Errorf("invalid purge type '%s'; expected 'to' or 'before'", purgeType)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+		},
+	}
+}
+
+// parsePurgeDatetime parses the datetime argument for purging binary logs, in the server's local time zone.
+func parsePurgeDatetime(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid datetime '%s'", value)
+}
@@ -50,6 +50,32 @@ func NewBinlogInitDatabaseHook(_ context.Context, listeners []doltdb.DatabaseUpd
 				logrus.Errorf("error replicating data from newly created database: %s", err.Error())
 				return err
 			}
+
+			// Other branches of the database that are mapped to their own databases are replicated as well
+			branches, err := denv.DoltDB.GetBranches(ctx)
+			if err != nil {
+				return err
+			}
+			for _, branch := range branches {
+				branchName := branch.GetPath()
+				if branchName == BinlogBranch {
+					continue
+				}
+				if _, ok := BinlogBranchMappings.mappedSchemaFor(name, branchName); !ok {
+					continue
+				}
+
+				err = listener.BranchCreated(ctx, name, branchName)
+				if err != nil {
+					logrus.Errorf("error notifying working root listener of created branch: %s", err.Error())
+					return err
+				}
+				err = replicateExistingData(ctx, denv.DoltDB, branchName, listener, name)
+				if err != nil {
+					logrus.Errorf("error replicating data from newly created database: %s", err.Error())
+					return err
+				}
+			}
 		}
 		return nil
 	}
@@ -22,39 +22,181 @@ import (
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/vitess/go/mysql"
 	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
 )
 
-// binlogStreamer is responsible for receiving binlog events over its eventChan
-// channel, and streaming those out to a connected replica over a MySQL connection.
-// It also sends heartbeat events to the replica over the same connection at
-// regular intervals. There is one streamer per connected replica.
+// binlogStreamer is responsible for reading binlog events from the binary log files and streaming them out to
+// a connected replica over a MySQL connection. It is notified over its notifyChan channel when new events are
+// written to the binary log. It also sends heartbeat events to the replica over the same connection at regular
+// intervals. There is one streamer per connected replica, which tracks the replica's status.
 type binlogStreamer struct {
-	quitChan  chan struct{}
-	eventChan chan []mysql.BinlogEvent
-	ticker    *time.Ticker
+	quitChan   chan struct{}
+	notifyChan chan struct{}
+	ticker     *time.Ticker
+
+	mu     *sync.Mutex
+	status dtables.BinlogReplicaStatus
+	// behindSince is the time the oldest binlog event that hasn't been sent to the replica was written, or the
+	// zero time if every event has been sent.
+	behindSince time.Time
 }
 
-// NewBinlogStreamer creates a new binlogStreamer instance.
-func newBinlogStreamer() *binlogStreamer {
+// NewBinlogStreamer creates a new binlogStreamer instance for the replica described by |replica|.
+func newBinlogStreamer(replica registeredReplica) *binlogStreamer {
+	now := time.Now()
 	return &binlogStreamer{
-		quitChan:  make(chan struct{}),
-		eventChan: make(chan []mysql.BinlogEvent, 5),
-		ticker:    time.NewTicker(30 * time.Second),
+		quitChan:   make(chan struct{}),
+		notifyChan: make(chan struct{}, 1),
+		ticker:     time.NewTicker(30 * time.Second),
+		mu:         &sync.Mutex{},
+		status: dtables.BinlogReplicaStatus{
+			ReplicaUuid: replica.replicaUuid,
+			Host:        replica.host,
+			Port:        replica.port,
+			ConnectedAt: now,
+		},
+		// A new replica may need to catch up on the events already written to the binary log
+		behindSince: now,
+	}
+}
+
+// replicaStatus returns the status of the replica this streamer is sending events to.
+func (streamer *binlogStreamer) replicaStatus() dtables.BinlogReplicaStatus {
+	streamer.mu.Lock()
+	defer streamer.mu.Unlock()
+
+	status := streamer.status
+	if !streamer.behindSince.IsZero() {
+		status.Lag = time.Since(streamer.behindSince)
+	}
+	return status
+}
+
+// markBehind records that binlog events have been written that this streamer hasn't sent yet.
+func (streamer *binlogStreamer) markBehind(t time.Time) {
+	streamer.mu.Lock()
+	defer streamer.mu.Unlock()
+
+	if streamer.behindSince.IsZero() {
+		streamer.behindSince = t
+	}
+}
+
+// markCaughtUp records that this streamer has sent every binlog event written before |t|.
+func (streamer *binlogStreamer) markCaughtUp(t time.Time) {
+	streamer.mu.Lock()
+	defer streamer.mu.Unlock()
+
+	if !streamer.behindSince.After(t) {
+		streamer.behindSince = time.Time{}
+	}
+}
+
+// recordSentEvent updates the replica's status after |event| has been sent to it.
+func (streamer *binlogStreamer) recordSentEvent(event mysql.BinlogEvent, gtid mysql.GTID) {
+	streamer.mu.Lock()
+	defer streamer.mu.Unlock()
+
+	streamer.status.BytesSent += uint64(len(event.Bytes()))
+	if gtid != nil {
+		streamer.status.LastSentGtid = gtid.String()
 	}
 }
 
-// startStream listens for new binlog events sent to this streamer over its binlog event
-// channel and sends them over |conn|. It also listens for ticker ticks to send hearbeats
-// over |conn|. The specified |binlogFormat| is used to define the format of binlog events
-// and |binlogEventMeta| records the position of the stream. This method blocks until an error
-// is received over the stream (e.g. the connection closing) or the streamer is closed,
-// through it's quit channel.
-func (streamer *binlogStreamer) startStream(ctx *sql.Context, conn *mysql.Conn, binlogFormat *mysql.BinlogFormat, binlogEventMeta mysql.BinlogEventMetadata) error {
-	if err := sendInitialEvents(ctx, conn, binlogFormat, &binlogEventMeta); err != nil {
+// recordHeartbeat records that a heartbeat was sent to the replica.
+func (streamer *binlogStreamer) recordHeartbeat() {
+	streamer.mu.Lock()
+	defer streamer.mu.Unlock()
+
+	streamer.status.LastHeartbeat = time.Now()
+}
+
+// writeEvent sends |event| over |conn| and records it in the replica's status.
+func (streamer *binlogStreamer) writeEvent(conn *mysql.Conn, event mysql.BinlogEvent, gtid mysql.GTID) error {
+	if err := conn.WriteBinlogEvent(event, false); err != nil {
+		return err
+	}
+	streamer.recordSentEvent(event, gtid)
+	return nil
+}
+
+// startStream streams the binlog events from the binary log files of |logManager| over |conn|, starting from
+// the log file that contains the first transaction not in |executedGtids|. Transactions in |executedGtids| are
+// skipped. Once all events written so far have been sent, it waits to be notified of new events, and listens
+// for ticker ticks to send hearbeats over |conn|. The specified |binlogEventMeta| records the position of the
+// stream. This method blocks until an error is received over the stream (e.g. the connection closing) or the
+// streamer is closed, through it's quit channel.
+func (streamer *binlogStreamer) startStream(ctx *sql.Context, conn *mysql.Conn, executedGtids mysql.GTIDSet, binlogFormat *mysql.BinlogFormat, binlogEventMeta mysql.BinlogEventMetadata, logManager *logManager) error {
+	filename, err := logManager.findLogFileForGtids(executedGtids)
+	if err != nil {
+		return err
+	}
+	reader, err := logManager.openLogFile(filename)
+	if err != nil {
 		return err
 	}
+	defer func() {
+		reader.Close()
+	}()
 
+	if err := streamer.sendInitialEvents(ctx, conn, filename, binlogFormat, &binlogEventMeta); err != nil {
+		return err
+	}
+
+	skippingTransaction := false
 	for {
+		// Send all the events that have been written to the binary log so far
+		drainStart := time.Now()
+		for {
+			event, err := reader.nextEvent()
+			if err != nil {
+				return err
+			} else if event == nil {
+				break
+			}
+
+			var gtid mysql.GTID
+			switch {
+			case event.IsGTID():
+				gtid, _, err = event.GTID(reader.binlogFormat)
+				if err != nil {
+					return err
+				}
+				skippingTransaction = executedGtids != nil && executedGtids.ContainsGTID(gtid)
+			case event.IsFormatDescription() || event.IsPreviousGTIDs() || event.IsRotate():
+				skippingTransaction = false
+			}
+			binlogEventMeta.NextLogPosition = reader.position
+			if skippingTransaction {
+				continue
+			}
+
+			if err := streamer.writeEvent(conn, event, gtid); err != nil {
+				return err
+			}
+
+			// A Rotate event ends a binary log file and names the next log file to read
+			if event.IsRotate() {
+				filename, err = parseRotateEvent(reader.binlogFormat, event)
+				if err != nil {
+					return err
+				}
+				// Open the next log file before closing this one, so that it can't be purged in between
+				nextReader, err := logManager.openLogFile(filename)
+				if err != nil {
+					return err
+				}
+				reader.Close()
+				reader = nextReader
+				binlogEventMeta.NextLogPosition = reader.position
+			}
+		}
+		if err := conn.FlushBuffer(); err != nil {
+			return fmt.Errorf("unable to flush binlog connection: %s", err.Error())
+		}
+		streamer.markCaughtUp(drainStart)
+
 		logrus.StandardLogger().Trace("binlog streamer is listening for messages")
 
 		select {
@@ -65,28 +207,15 @@ func (streamer *binlogStreamer) startStream(ctx *sql.Context, conn *mysql.Conn,
 
 		case <-streamer.ticker.C:
 			logrus.StandardLogger().Trace("sending binlog heartbeat")
-			if err := sendHeartbeat(conn, binlogFormat, binlogEventMeta); err != nil {
+			if err := streamer.sendHeartbeat(conn, binlogFormat, binlogEventMeta, filename); err != nil {
 				return err
 			}
 			if err := conn.FlushBuffer(); err != nil {
 				return fmt.Errorf("unable to flush binlog connection: %s", err.Error())
 			}
 
-		case events := <-streamer.eventChan:
-			// TODO: If an error occurs while sending an event, it would be nice to have a retry at this
-			//       level. Technically the replica should be abel to automatically reconnect and restart
-			//       the stream from the last GTID it executed successfully, but it would be better to
-			//       avoid the extra work for the reconnection and restart if possible.
-			logrus.StandardLogger().Tracef("streaming %d binlog events", len(events))
-			for _, event := range events {
-				if err := conn.WriteBinlogEvent(event, false); err != nil {
-					return err
-				}
-				binlogEventMeta.NextLogPosition += event.Length()
-			}
-			if err := conn.FlushBuffer(); err != nil {
-				return fmt.Errorf("unable to flush binlog connection: %s", err.Error())
-			}
+		case <-streamer.notifyChan:
+			logrus.StandardLogger().Trace("streaming new binlog events")
 		}
 	}
 }
@@ -98,6 +227,7 @@ type binlogStreamerManager struct {
 	streamers      []*binlogStreamer
 	streamersMutex sync.Mutex
 	quitChan       chan struct{}
+	logManager     *logManager
 }
 
 // NewBinlogStreamerManager creates a new binlogStreamerManager instance.
@@ -134,26 +264,48 @@ func (m *binlogStreamerManager) copyStreamers() []*binlogStreamer {
 	return results
 }
 
-// StartStream starts a new binlogStreamer and streams events over |conn| until the connection
-// is closed, the streamer is sent a quit signal over its quit channel, or the streamer receives
-// errors while sending events over the connection. Note that this method blocks until the
-// streamer exits.
-func (m *binlogStreamerManager) StartStream(ctx *sql.Context, conn *mysql.Conn, binlogFormat *mysql.BinlogFormat, binlogEventMeta mysql.BinlogEventMetadata) error {
-	streamer := newBinlogStreamer()
+// LogManager sets the logManager whose binary log files this streamer manager streams events from.
+func (m *binlogStreamerManager) LogManager(logManager *logManager) {
+	m.logManager = logManager
+}
+
+// StartStream starts a new binlogStreamer and streams events over |conn| to the replica described by |replica|,
+// starting after the GTIDs in |executedGtids|, until the connection is closed, the streamer is sent a quit signal
+// over its quit channel, or the streamer receives errors while sending events over the connection. Note that this
+// method blocks until the streamer exits.
+func (m *binlogStreamerManager) StartStream(ctx *sql.Context, conn *mysql.Conn, replica registeredReplica, executedGtids mysql.GTIDSet, binlogFormat *mysql.BinlogFormat, binlogEventMeta mysql.BinlogEventMetadata) error {
+	streamer := newBinlogStreamer(replica)
 	m.addStreamer(streamer)
 	defer m.removeStreamer(streamer)
 
-	return streamer.startStream(ctx, conn, binlogFormat, binlogEventMeta)
+	return streamer.startStream(ctx, conn, executedGtids, binlogFormat, binlogEventMeta, m.logManager)
 }
 
-// sendEvents sends |binlogEvents| to all the streams managed by this instance.
-func (m *binlogStreamerManager) sendEvents(binlogEvents []mysql.BinlogEvent) {
+// notifyStreamers notifies all the streamers managed by this instance that new events have been written to
+// the binary log.
+func (m *binlogStreamerManager) notifyStreamers() {
+	now := time.Now()
 	for _, streamer := range m.copyStreamers() {
-		logrus.StandardLogger().Tracef("queuing %d binlog events\n", len(binlogEvents))
-		streamer.eventChan <- binlogEvents
+		streamer.markBehind(now)
+		select {
+		case streamer.notifyChan <- struct{}{}:
+		default:
+			// the streamer already has a notification it hasn't handled yet
+		}
 	}
 }
 
+// BinlogReplicaStatuses implements the dtables.BinlogReplicaStatusProvider interface and returns the status of
+// each replica that is currently streaming binlog events.
+func (m *binlogStreamerManager) BinlogReplicaStatuses() []dtables.BinlogReplicaStatus {
+	streamers := m.copyStreamers()
+	statuses := make([]dtables.BinlogReplicaStatus, len(streamers))
+	for i, streamer := range streamers {
+		statuses[i] = streamer.replicaStatus()
+	}
+	return statuses
+}
+
 // addStreamer adds |streamer| to the slice of streamers managed by this binlogStreamerManager.
 func (m *binlogStreamerManager) addStreamer(streamer *binlogStreamer) {
 	m.streamersMutex.Lock()
@@ -167,31 +319,32 @@ func (m *binlogStreamerManager) removeStreamer(streamer *binlogStreamer) {
 	m.streamersMutex.Lock()
 	defer m.streamersMutex.Unlock()
 
-	m.streamers = make([]*binlogStreamer, len(m.streamers)-1, 0)
-	for _, element := range m.streamers {
+	streamers := m.streamers
+	m.streamers = make([]*binlogStreamer, 0, len(streamers))
+	for _, element := range streamers {
 		if element != streamer {
 			m.streamers = append(m.streamers, element)
 		}
 	}
 }
 
-func sendHeartbeat(conn *mysql.Conn, binlogFormat *mysql.BinlogFormat, binlogEventMeta mysql.BinlogEventMetadata) error {
+func (streamer *binlogStreamer) sendHeartbeat(conn *mysql.Conn, binlogFormat *mysql.BinlogFormat, binlogEventMeta mysql.BinlogEventMetadata, binlogFilename string) error {
 	binlogEventMeta.Timestamp = uint32(0) // Timestamp is zero for a heartbeat event
 	logrus.WithField("log_position", binlogEventMeta.NextLogPosition).Tracef("sending heartbeat")
 
 	binlogEvent := mysql.NewHeartbeatEventWithLogFile(*binlogFormat, binlogEventMeta, binlogFilename)
-	return conn.WriteBinlogEvent(binlogEvent, false)
-}
-
-// sendInitialEvents sends the initial binlog events (i.e. Rotate, FormatDescription) over a newly established binlog
-// streaming connection.
-func sendInitialEvents(_ *sql.Context, conn *mysql.Conn, binlogFormat *mysql.BinlogFormat, binlogEventMeta *mysql.BinlogEventMetadata) error {
-	err := sendRotateEvent(conn, binlogFormat, binlogEventMeta)
-	if err != nil {
+	if err := streamer.writeEvent(conn, binlogEvent, nil); err != nil {
 		return err
 	}
+	streamer.recordHeartbeat()
+	return nil
+}
 
-	err = sendFormatDescription(conn, binlogFormat, binlogEventMeta)
+// sendInitialEvents sends the initial binlog event (i.e. Rotate) over a newly established binlog streaming
+// connection, naming the binary log file |binlogFilename| that streaming starts from. The FormatDescription event
+// that follows is read from the log file itself.
+func (streamer *binlogStreamer) sendInitialEvents(_ *sql.Context, conn *mysql.Conn, binlogFilename string, binlogFormat *mysql.BinlogFormat, binlogEventMeta *mysql.BinlogEventMetadata) error {
+	err := streamer.sendRotateEvent(conn, binlogFilename, binlogFormat, binlogEventMeta)
 	if err != nil {
 		return err
 	}
@@ -199,8 +352,8 @@ func sendInitialEvents(_ *sql.Context, conn *mysql.Conn, binlogFormat *mysql.Bin
 	return conn.FlushBuffer()
 }
 
-func sendRotateEvent(conn *mysql.Conn, binlogFormat *mysql.BinlogFormat, binlogEventMeta *mysql.BinlogEventMetadata) error {
-	binlogFilePosition := uint64(0)
+func (streamer *binlogStreamer) sendRotateEvent(conn *mysql.Conn, binlogFilename string, binlogFormat *mysql.BinlogFormat, binlogEventMeta *mysql.BinlogEventMetadata) error {
+	binlogFilePosition := uint64(len(binlogFileMagicNumber))
 	binlogEventMeta.NextLogPosition = uint32(binlogFilePosition)
 
 	// The Rotate event sent at the start of a stream is a "virtual" event that isn't actually
@@ -208,11 +361,5 @@ func sendRotateEvent(conn *mysql.Conn, binlogFormat *mysql.BinlogFormat, binlogE
 	// read from. Because it is virtual, we do NOT update the nextLogPosition field of
 	// BinlogEventMetadata.
 	binlogEvent := mysql.NewRotateEvent(*binlogFormat, *binlogEventMeta, binlogFilePosition, binlogFilename)
-	return conn.WriteBinlogEvent(binlogEvent, false)
-}
-
-func sendFormatDescription(conn *mysql.Conn, binlogFormat *mysql.BinlogFormat, binlogEventMeta *mysql.BinlogEventMetadata) error {
-	binlogEvent := mysql.NewFormatDescriptionEvent(*binlogFormat, *binlogEventMeta)
-	binlogEventMeta.NextLogPosition += binlogEvent.Length()
-	return conn.WriteBinlogEvent(binlogEvent, false)
+	return streamer.writeEvent(conn, binlogEvent, nil)
 }
@@ -16,9 +16,11 @@ package binlogreplication
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/require"
 )
 
@@ -124,7 +126,7 @@ func TestBinlogPrimary(t *testing.T) {
 	})
 
 	requirePrimaryResults(t, "SHOW BINARY LOG STATUS", [][]any{
-		{"binlog-main.000001", "2226", "", "", uuid + ":1-3"}})
+		{"binlog-main.000001", "2377", "", "", uuid + ":1-3"}})
 }
 
 // TestBinlogPrimary_Heartbeats tests that heartbeats sent from the primary to the replica are well-formed and
@@ -161,6 +163,66 @@ func TestBinlogPrimary_Heartbeats(t *testing.T) {
 	require.Equal(t, "", status["Last_IO_Error"])
 	require.Equal(t, "0", status["Last_SQL_Errno"])
 	require.Equal(t, "0", status["Last_IO_Errno"])
+
+	// The primary records the heartbeats it sends to the replica
+	requirePrimaryResults(t, "select last_heartbeat is not null from db01.dolt_binlog_replicas;", [][]any{{"1"}})
+}
+
+// TestBinlogPrimary_ReplicaStatus tests that the primary tracks the status of connected replicas in the
+// dolt_binlog_replicas system table.
+func TestBinlogPrimary_ReplicaStatus(t *testing.T) {
+	defer teardown(t)
+	startSqlServersWithDoltSystemVars(t, doltReplicationPrimarySystemVars)
+	setupForDoltToMySqlReplication()
+	requirePrimaryResults(t, "select * from db01.dolt_binlog_replicas;", [][]any{})
+
+	startReplication(t, doltPort)
+	primaryDatabase.MustExec("create table db01.t (pk int primary key);")
+	primaryDatabase.MustExec("insert into db01.t values (1);")
+	waitForReplicaToCatchUp(t)
+
+	requirePrimaryResults(t, "select count(*), max(bytes_sent) > 0, max(lag_ms) >= 0 from db01.dolt_binlog_replicas;",
+		[][]any{{"1", "1", "1"}})
+
+	// MySQL replicas report their server UUID when they connect
+	var replicaUuid string
+	require.NoError(t, replicaDatabase.QueryRowx("select @@server_uuid;").Scan(&replicaUuid))
+	requirePrimaryResults(t, "select replica_uuid from db01.dolt_binlog_replicas;", [][]any{{replicaUuid}})
+
+	// The last GTID sent is the last GTID the replica executed
+	status := queryReplicaStatus(t)
+	executedGtids := strings.Split(status["Executed_Gtid_Set"].(string), "-")
+	lastSequence := executedGtids[len(executedGtids)-1]
+	rows, err := primaryDatabase.Queryx("select last_sent_gtid from db01.dolt_binlog_replicas;")
+	require.NoError(t, err)
+	allRows := readAllRowsIntoMaps(t, rows)
+	require.Len(t, allRows, 1)
+	require.True(t, strings.HasSuffix(allRows[0]["last_sent_gtid"].(string), ":"+lastSequence))
+
+	// SHOW REPLICAS lists the same replicas, with MySQL's columns, and doesn't need a current database
+	rows, err = primaryDatabase.Queryx("show replicas;")
+	require.NoError(t, err)
+	allRows = readAllRowsIntoMaps(t, rows)
+	require.NoError(t, rows.Close())
+	require.Len(t, allRows, 1)
+	require.Equal(t, replicaUuid, allRows[0]["Replica_UUID"])
+	require.Nil(t, allRows[0]["Server_Id"])
+
+	// SHOW REPLICAS requires the REPLICATION SLAVE privilege
+	primaryDatabase.MustExec("create user 'watcher'@'%' identified by 'watcher';")
+	primaryDatabase.MustExec("grant select on *.* to 'watcher'@'%';")
+	watcherDatabase, err := sqlx.Open("mysql", fmt.Sprintf("watcher:watcher@tcp(127.0.0.1:%v)/", doltPort))
+	require.NoError(t, err)
+	defer watcherDatabase.Close()
+	_, err = watcherDatabase.Exec("show replicas;")
+	require.ErrorContains(t, err, "command denied to user")
+
+	primaryDatabase.MustExec("grant replication slave on *.* to 'watcher'@'%';")
+	rows, err = watcherDatabase.Queryx("show replicas;")
+	require.NoError(t, err)
+	allRows = readAllRowsIntoMaps(t, rows)
+	require.NoError(t, rows.Close())
+	require.Len(t, allRows, 1)
 }
 
 // TestBinlogPrimary_ReplicaRestart tests that the Dolt primary server behaves correctly when the
@@ -186,8 +248,8 @@ func TestBinlogPrimary_ReplicaRestart(t *testing.T) {
 	time.Sleep(4_000 * time.Millisecond)
 
 	// Make a change while the replica is stopped to test that the server
-	// doesn't error out when a registered replica is not available.
-	// NOTE: This won't be replicated until we start persisting the binlog to disk
+	// doesn't error out when a registered replica is not available, and
+	// that the replica catches up from the binary log files when it reconnects.
 	primaryDatabase.MustExec("insert into db01.t1 values (1, 'one');")
 
 	// Restart the MySQL replica and reconnect to the Dolt primary
@@ -201,17 +263,14 @@ func TestBinlogPrimary_ReplicaRestart(t *testing.T) {
 
 	// Create another table and assert that it gets replicated
 	primaryDatabase.MustExec("create table db01.t2 (pk int primary key, c1 varchar(255));")
-	// We can't use waitForReplicaToCatchUp here, because it won't get the statement
-	// that was executed while the replica was stopped. Once we support replaying binlog
-	// events from a log file, we can switch this to waitForReplicaToCatchUp(t)
-	waitForReplicaToHaveLatestGtid(t)
+	waitForReplicaToCatchUp(t)
 	requireReplicaResults(t, "show tables;", [][]any{{"t1"}, {"t2"}})
+	requireReplicaResults(t, "select * from db01.t1;", [][]any{{"1", "one"}})
 
-	// Assert the executed GTID position now contains GTID #2 and GTID #4
-	// (#1 isn't present, because it was executed before we turned on replication,
-	// and #3 isn't present, because it was executed while the replica was stopped)
+	// Assert the executed GTID position now contains all GTIDs, including #3,
+	// which was executed while the replica was stopped
 	status = queryReplicaStatus(t)
-	require.Equal(t, serverUuid+":1-2:4", status["Executed_Gtid_Set"])
+	require.Equal(t, serverUuid+":1-4", status["Executed_Gtid_Set"])
 }
 
 // TestBinlogPrimary_PrimaryRestart tests that a Dolt primary server can be restarted and that a replica
@@ -255,6 +314,76 @@ func TestBinlogPrimary_PrimaryRestart(t *testing.T) {
 	require.Equal(t, serverUuid+":1-3", status["Executed_Gtid_Set"])
 }
 
+// TestBinlogPrimary_BinaryLogFiles tests that binlog events are written to binary log files that are rotated when
+// the primary restarts, listed by SHOW BINARY LOGS, and can be purged, and that a replica that reconnects after
+// missing transactions catches up from the log files.
+func TestBinlogPrimary_BinaryLogFiles(t *testing.T) {
+	defer teardown(t)
+	startSqlServersWithDoltSystemVars(t, doltReplicationPrimarySystemVars)
+	setupForDoltToMySqlReplication()
+	startReplication(t, doltPort)
+
+	primaryDatabase.MustExec("create table db01.t1 (pk int primary key, c1 varchar(255));")
+	waitForReplicaToCatchUp(t)
+	requirePrimaryBinaryLogs(t, []string{"binlog-main.000001"})
+
+	// Stop the replica, then make changes and restart the primary, so that the changes
+	// the replica needs are spread over two log files
+	replicaDatabase.MustExec("stop replica;")
+	primaryDatabase.MustExec("insert into db01.t1 values (1, 'one');")
+	stopDoltSqlServer(t)
+	time.Sleep(2_000 * time.Millisecond)
+	prevReplicaDatabase := replicaDatabase
+	var err error
+	doltPort, doltProcess, err = startDoltSqlServer(testDir, nil)
+	require.NoError(t, err)
+	primaryDatabase = replicaDatabase
+	replicaDatabase = prevReplicaDatabase
+	primaryDatabase.MustExec("insert into db01.t1 values (2, 'two');")
+	requirePrimaryBinaryLogs(t, []string{"binlog-main.000001", "binlog-main.000002"})
+
+	replicaDatabase.MustExec("start replica;")
+	waitForReplicaToReconnect(t)
+	waitForReplicaToCatchUp(t)
+	requireReplicaResults(t, "select * from db01.t1;", [][]any{{"1", "one"}, {"2", "two"}})
+
+	// Purging requires the SUPER privilege
+	primaryDatabase.MustExec("create user 'purger'@'%' identified by 'purger';")
+	primaryDatabase.MustExec("grant all on db01.* to 'purger'@'%';")
+	purgerDatabase, err := sqlx.Open("mysql", fmt.Sprintf("purger:purger@tcp(127.0.0.1:%v)/", doltPort))
+	require.NoError(t, err)
+	defer purgerDatabase.Close()
+	_, err = purgerDatabase.Exec("call dolt_purge_binary_logs('to', 'binlog-main.000002');")
+	require.ErrorContains(t, err, "command denied to user")
+	requirePrimaryBinaryLogs(t, []string{"binlog-main.000001", "binlog-main.000002"})
+
+	// Purging requires a log file from the binlog index
+	_, err = primaryDatabase.Exec("call dolt_purge_binary_logs('to', 'binlog-main.000999');")
+	require.ErrorContains(t, err, "not found in binlog index")
+
+	primaryDatabase.MustExec("call dolt_purge_binary_logs('to', 'binlog-main.000002');")
+	requirePrimaryBinaryLogs(t, []string{"binlog-main.000002"})
+	requirePrimaryResults(t, "select @@gtid_purged;", [][]any{{queryPrimaryServerUuid(t) + ":1-3"}})
+
+	// The current log file is never purged
+	primaryDatabase.MustExec("call dolt_purge_binary_logs('before', '2099-01-01 00:00:00');")
+	requirePrimaryBinaryLogs(t, []string{"binlog-main.000002"})
+}
+
+// requirePrimaryBinaryLogs asserts that SHOW BINARY LOGS on the primary lists the log files named |expected|.
+func requirePrimaryBinaryLogs(t *testing.T, expected []string) {
+	rows, err := primaryDatabase.Queryx("show binary logs;")
+	require.NoError(t, err)
+	allRows := readAllRowsIntoMaps(t, rows)
+	require.NoError(t, rows.Close())
+
+	var names []string
+	for _, row := range allRows {
+		names = append(names, row["Log_name"].(string))
+	}
+	require.Equal(t, expected, names)
+}
+
 // TestBinlogPrimary_OptIn asserts that binary logging does not work when the log_bin system variable is not set.
 func TestBinlogPrimary_OptIn(t *testing.T) {
 	defer teardown(t)
@@ -577,6 +706,57 @@ func TestBinlogPrimary_OnlyReplicateMainBranch(t *testing.T) {
 	requireReplicaResults(t, "select * from db01.t;", [][]any{{"42", "42", "2042"}})
 }
 
+// TestBinlogPrimary_BranchMappings asserts that the log_bin_branch_mappings system variable can be used to replicate
+// other branches to their own databases, and that creating and deleting mapped branches creates and drops them.
+func TestBinlogPrimary_BranchMappings(t *testing.T) {
+	defer teardown(t)
+	mapCopy := copyMap(doltReplicationPrimarySystemVars)
+	mapCopy["log_bin_branch_mappings"] = "db01/branch1=db01_branch1"
+	startSqlServersWithDoltSystemVars(t, mapCopy)
+	setupForDoltToMySqlReplication()
+	startReplication(t, doltPort)
+
+	primaryDatabase.MustExec("create table db01.t (pk varchar(100) primary key, c1 int, c2 year);")
+	primaryDatabase.MustExec("call dolt_commit('-Am', 'creating table t');")
+	waitForReplicaToCatchUp(t)
+	requireReplicaResults(t, "select * from db01.t;", [][]any{})
+
+	// Creating the mapped branch creates its database, along with its existing schema
+	primaryDatabase.MustExec("call dolt_branch('branch1');")
+	waitForReplicaToCatchUp(t)
+	requireReplicaResults(t, "show databases like 'db01_branch1';", [][]any{{"db01_branch1"}})
+	requireReplicaResults(t, "select * from db01_branch1.t;", [][]any{})
+
+	// Changes to the mapped branch are replicated to its database
+	primaryDatabase.MustExec("call dolt_checkout('branch1');")
+	primaryDatabase.MustExec("insert into db01.t values('hundred', 100, 2000);")
+	waitForReplicaToCatchUp(t)
+	requireReplicaResults(t, "select * from db01_branch1.t;", [][]any{{"hundred", "100", "2000"}})
+	requireReplicaResults(t, "select * from db01.t;", [][]any{})
+
+	// Branches without a mapping are not replicated
+	primaryDatabase.MustExec("call dolt_checkout('-b', 'branch2');")
+	primaryDatabase.MustExec("insert into db01.t values('two hundred', 200, 2000);")
+	waitForReplicaToCatchUp(t)
+	requireReplicaResults(t, "show databases like 'db01_branch%';", [][]any{{"db01_branch1"}})
+
+	// Main is still replicated to a database of the same name
+	primaryDatabase.MustExec("call dolt_checkout('main');")
+	primaryDatabase.MustExec("insert into db01.t values('42', 42, 2042);")
+	waitForReplicaToCatchUp(t)
+	requireReplicaResults(t, "select * from db01.t;", [][]any{{"42", "42", "2042"}})
+	requireReplicaResults(t, "select * from db01_branch1.t;", [][]any{{"hundred", "100", "2000"}})
+
+	// Deleting the mapped branch drops its database
+	primaryDatabase.MustExec("call dolt_branch('-D', 'branch1');")
+	waitForReplicaToCatchUp(t)
+	requireReplicaResults(t, "show databases like 'db01_branch1';", [][]any{})
+
+	// The mappings are read when the server starts, so they can't be changed while it runs
+	_, err := primaryDatabase.Exec("set @@global.log_bin_branch_mappings = 'db01/branch2=db01_branch2';")
+	require.ErrorContains(t, err, "read only")
+}
+
 // TestBinlogPrimary_KeylessTables tests that Dolt can replicate changes to keyless tables.
 func TestBinlogPrimary_KeylessTables(t *testing.T) {
 	defer teardown(t)
@@ -36,20 +36,13 @@ import (
 	"github.com/dolthub/dolt/go/store/val"
 )
 
-// BinlogBranch specifies the branch used for generating binlog events.
+// BinlogBranch specifies the branch of each database that is replicated to a database of the same name, unless
+// BinlogBranchMappings maps a different branch to that name.
 var BinlogBranch = "main"
 
-// binlogFilename is the name of the filename used in binlog events. Note that
-// currently, this doesn't map to a real file on disk yet, but the filename is
-// still needed for binlog messages.
-var binlogFilename = "binlog-" + BinlogBranch + ".000001"
-
 // binlogProducer implements the doltdb.DatabaseUpdateListener interface so that it can listen for updates to Dolt
-// databases and generate binlog events describing them. Those binlog events are sent to the binlogStreamerManager,
-// which is responsible for delivering them to each connected replica.
-//
-// Note that the initial version of binlogProducer currently delivers the generated binlog events directly to the
-// connected replicas, without actually writing them to a real binlog file on disk.
+// databases and generate binlog events describing them. Those binlog events are written to the binary log files
+// by the logManager, and the binlogStreamerManager is notified so that it can deliver them to each connected replica.
 type binlogProducer struct {
 	binlogEventMeta mysql.BinlogEventMetadata
 	binlogFormat    *mysql.BinlogFormat
@@ -60,6 +53,7 @@ type binlogProducer struct {
 	gtidSequence int64
 
 	streamerManager *binlogStreamerManager
+	logManager      *logManager
 }
 
 var _ doltdb.DatabaseUpdateListener = (*binlogProducer)(nil)
@@ -82,15 +76,23 @@ func NewBinlogProducer(streamerManager *binlogStreamerManager) (*binlogProducer,
 	}, nil
 }
 
+// LogManager sets the logManager that writes the binlog events produced to the binary log files. The
+// position of the binlog events produced starts at the current position in the current log file.
+func (b *binlogProducer) LogManager(logManager *logManager) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.logManager = logManager
+	_, b.binlogEventMeta.NextLogPosition = logManager.currentFile()
+}
+
 // WorkingRootUpdated implements the doltdb.DatabaseUpdateListener interface. When a working root changes,
-// this function generates events for the binary log and sends them to all connected replicas.
-//
-// This function currently sends the events to all connected replicas as the events are produced. Eventually we
-// need to change this so that it writes to a binary log file as the intermediate, and the readers watch that
-// log to stream events back to the connected replicas.
+// this function generates events for the binary log and writes them to the binary log files. The events are
+// generated for the database that BinlogBranchMappings maps the updated branch to, and updates to branches that
+// aren't mapped are ignored.
 func (b *binlogProducer) WorkingRootUpdated(ctx *sql.Context, databaseName string, branchName string, before doltdb.RootValue, after doltdb.RootValue) error {
-	// We only support updates to a single branch for binlog events, so ignore all other updates
-	if branchName != BinlogBranch {
+	databaseName, ok := BinlogBranchMappings.schemaFor(databaseName, branchName)
+	if !ok {
 		return nil
 	}
 
@@ -136,43 +138,103 @@ func (b *binlogProducer) WorkingRootUpdated(ctx *sql.Context, databaseName strin
 		binlogEvents = append(binlogEvents, b.newXIDEvent())
 	}
 
-	b.streamerManager.sendEvents(binlogEvents)
-	return nil
+	return b.writeEvents(binlogEvents)
 }
 
-// DatabaseCreated implements the doltdb.DatabaseUpdateListener interface.
+// DatabaseCreated implements the doltdb.DatabaseUpdateListener interface. A database is created in the binlog for
+// the @@log_bin_branch branch of the new database, if it is replicated. Databases for other mapped branches are
+// created when BranchCreated is called for them.
 func (b *binlogProducer) DatabaseCreated(ctx *sql.Context, databaseName string) error {
 	// TODO: All of these need to be sequentially processed by a single goroutine, so that we can ensure the GTID
 	//       assignment happens sequentially and safely. Also... if a database is created, we need to process that
 	//       update before any data updates to the database itself. Seems like that race could happen otherwise?
+	schemaName, ok := BinlogBranchMappings.schemaFor(databaseName, BinlogBranch)
+	if !ok {
+		return nil
+	}
 
+	createDatabaseStatement := fmt.Sprintf("create database `%s`;", schemaName)
+	return b.writeDatabaseStatement(ctx, schemaName, createDatabaseStatement)
+}
+
+// DatabaseDropped implements the doltdb.DatabaseUpdateListener interface. The databases that any branches of the
+// dropped database are replicated to are dropped in the binlog.
+func (b *binlogProducer) DatabaseDropped(ctx *sql.Context, databaseName string) error {
 	var binlogEvents []mysql.BinlogEvent
-	binlogEvent, err := b.createGtidEvent(ctx)
-	if err != nil {
-		return err
+	for i, schemaName := range BinlogBranchMappings.schemasFor(databaseName) {
+		if schemaName == "" {
+			continue
+		}
+
+		// Databases for branches other than @@log_bin_branch may never have been created
+		dropDatabaseStatement := fmt.Sprintf("drop database if exists `%s`;", schemaName)
+		if i == 0 {
+			dropDatabaseStatement = fmt.Sprintf("drop database `%s`;", schemaName)
+		}
+
+		binlogEvent, err := b.createGtidEvent(ctx)
+		if err != nil {
+			return err
+		}
+		binlogEvents = append(binlogEvents, binlogEvent)
+		binlogEvents = append(binlogEvents, b.newQueryEvent(schemaName, dropDatabaseStatement))
 	}
-	binlogEvents = append(binlogEvents, binlogEvent)
 
-	createDatabaseStatement := fmt.Sprintf("create database `%s`;", databaseName)
-	binlogEvents = append(binlogEvents, b.newQueryEvent(databaseName, createDatabaseStatement))
+	return b.writeEvents(binlogEvents)
+}
 
-	b.streamerManager.sendEvents(binlogEvents)
-	return nil
+// BranchCreated implements the doltdb.DatabaseUpdateListener interface. If the new branch is mapped to a database
+// in the binlog, that database is created. The new branch's data is replicated when its working root is updated.
+func (b *binlogProducer) BranchCreated(ctx *sql.Context, databaseName string, branchName string) error {
+	schemaName, ok := BinlogBranchMappings.mappedSchemaFor(databaseName, branchName)
+	if !ok {
+		return nil
+	}
+
+	createDatabaseStatement := fmt.Sprintf("create database if not exists `%s`;", schemaName)
+	return b.writeDatabaseStatement(ctx, schemaName, createDatabaseStatement)
 }
 
-// DatabaseDropped implements the doltdb.DatabaseUpdateListener interface.
-func (b *binlogProducer) DatabaseDropped(ctx *sql.Context, databaseName string) error {
-	var binlogEvents []mysql.BinlogEvent
+// BranchDeleted implements the doltdb.DatabaseUpdateListener interface. If the deleted branch is mapped to a
+// database in the binlog, that database is dropped.
+func (b *binlogProducer) BranchDeleted(ctx *sql.Context, databaseName string, branchName string) error {
+	schemaName, ok := BinlogBranchMappings.mappedSchemaFor(databaseName, branchName)
+	if !ok {
+		return nil
+	}
+
+	dropDatabaseStatement := fmt.Sprintf("drop database if exists `%s`;", schemaName)
+	return b.writeDatabaseStatement(ctx, schemaName, dropDatabaseStatement)
+}
+
+// writeDatabaseStatement writes the statement |statement|, which creates or drops the database |schemaName|, to
+// the binlog in its own transaction.
+func (b *binlogProducer) writeDatabaseStatement(ctx *sql.Context, schemaName string, statement string) error {
 	binlogEvent, err := b.createGtidEvent(ctx)
 	if err != nil {
 		return err
 	}
-	binlogEvents = append(binlogEvents, binlogEvent)
 
-	dropDatabaseStatement := fmt.Sprintf("drop database `%s`;", databaseName)
-	binlogEvents = append(binlogEvents, b.newQueryEvent(databaseName, dropDatabaseStatement))
+	return b.writeEvents([]mysql.BinlogEvent{binlogEvent, b.newQueryEvent(schemaName, statement)})
+}
+
+// writeEvents writes |binlogEvents| to the binary log and notifies the binlog streamers that new events are
+// available. If writing the events rotated the binary log file, the position of the next binlog events produced
+// is moved to the new log file.
+func (b *binlogProducer) writeEvents(binlogEvents []mysql.BinlogEvent) error {
+	if len(binlogEvents) == 0 {
+		return nil
+	}
+
+	if err := b.logManager.WriteEvents(binlogEvents); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	_, b.binlogEventMeta.NextLogPosition = b.logManager.currentFile()
+	b.mu.Unlock()
 
-	b.streamerManager.sendEvents(binlogEvents)
+	b.streamerManager.notifyStreamers()
 	return nil
 }
 
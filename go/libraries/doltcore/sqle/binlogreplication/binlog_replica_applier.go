@@ -15,6 +15,8 @@
 package binlogreplication
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"strconv"
@@ -63,13 +65,72 @@ type binlogReplicaApplier struct {
 	currentPosition       *mysql.Position // successfully executed GTIDs
 	filters               *filterConfiguration
 	running               atomic.Bool
+
+	// sourceLogFile and sourceLogPosition are the source's binary log file and the position in it after the last
+	// event received. pendingRotate holds a Rotate event received before the FormatDescription event needed to
+	// parse it.
+	sourceLogFile     string
+	sourceLogPosition uint32
+	pendingRotate     mysql.BinlogEvent
+
+	// commitOptions control when applied source transactions are committed to Dolt. uncommittedGtids holds the GTIDs
+	// of the source transactions applied since the last Dolt commit, and uncommittedTransactions their count, to the
+	// databases in uncommittedDatabases.
+	commitOptions           replicaCommitOptions
+	uncommittedGtids        mysql.GTIDSet
+	uncommittedTransactions int64
+	uncommittedDatabases    map[string]struct{}
+	lastDoltCommit          time.Time
+}
+
+// replicaCommitOptions control when the applier creates Dolt commits from the source transactions it applies.
+type replicaCommitOptions struct {
+	// transactions is the number of source transactions that are applied before a Dolt commit is created, or 0 to
+	// not create Dolt commits based on the number of transactions.
+	transactions int64
+	// interval is how long after the last Dolt commit applied source transactions are committed, or 0 to not
+	// create Dolt commits based on time.
+	interval time.Duration
+	// author is the author of the Dolt commits, e.g. "Jane Doe <jane@example.com>", or "" to use the replication user.
+	author string
+}
+
+// loadReplicaCommitOptions loads the options for creating Dolt commits from applied source transactions from the
+// @@dolt_replica_commit_transactions, @@dolt_replica_commit_seconds, and @@dolt_replica_commit_author system
+// variables. By default, every source transaction is committed to Dolt.
+func loadReplicaCommitOptions() (replicaCommitOptions, error) {
+	transactions, err := lookupIntegerSystemVariable("dolt_replica_commit_transactions", 1)
+	if err != nil {
+		return replicaCommitOptions{}, err
+	}
+	seconds, err := lookupIntegerSystemVariable("dolt_replica_commit_seconds", 0)
+	if err != nil {
+		return replicaCommitOptions{}, err
+	}
+	author, err := lookupStringSystemVariable("dolt_replica_commit_author")
+	if err != nil {
+		return replicaCommitOptions{}, err
+	}
+
+	author = strings.TrimSpace(author)
+	if author != "" && (!strings.Contains(author, "<") || !strings.HasSuffix(author, ">")) {
+		return replicaCommitOptions{}, fmt.Errorf("invalid @@dolt_replica_commit_author '%s'; expected 'Name <email>'", author)
+	}
+
+	return replicaCommitOptions{
+		transactions: transactions,
+		interval:     time.Duration(seconds) * time.Second,
+		author:       author,
+	}, nil
 }
 
 func newBinlogReplicaApplier(filters *filterConfiguration) *binlogReplicaApplier {
 	return &binlogReplicaApplier{
-		tableMapsById:       make(map[uint64]*mysql.TableMap),
-		stopReplicationChan: make(chan struct{}),
-		filters:             filters,
+		tableMapsById:        make(map[uint64]*mysql.TableMap),
+		stopReplicationChan:  make(chan struct{}),
+		filters:              filters,
+		commitOptions:        replicaCommitOptions{transactions: 1},
+		uncommittedDatabases: make(map[string]struct{}),
 	}
 }
 
@@ -264,6 +325,7 @@ func (a *binlogReplicaApplier) replicaBinlogEventHandler(ctx *sql.Context) error
 
 	var conn *mysql.Conn
 	var eventProducer *binlogEventProducer
+	a.lastDoltCommit = time.Now()
 
 	// Process binlog events
 	for {
@@ -275,6 +337,7 @@ func (a *binlogReplicaApplier) replicaBinlogEventHandler(ctx *sql.Context) error
 
 			var err error
 			if conn, err = a.connectAndStartReplicationEventStream(ctx); err == ErrReplicationStopped {
+				a.createDoltCommits(ctx, engine)
 				return nil
 			} else if err != nil {
 				return err
@@ -291,6 +354,12 @@ func (a *binlogReplicaApplier) replicaBinlogEventHandler(ctx *sql.Context) error
 				DoltBinlogReplicaController.setSqlError(mysql.ERUnknownError, err.Error())
 			}
 
+			// Time-based Dolt commits are checked after every event, including the heartbeats
+			// the source sends while it's idle, so they're created even if no new transactions arrive.
+			if a.commitOptions.interval > 0 && time.Since(a.lastDoltCommit) >= a.commitOptions.interval {
+				a.createDoltCommits(ctx, engine)
+			}
+
 		case err := <-eventProducer.ErrorChan():
 			if sqlError, isSqlError := err.(*mysql.SQLError); isSqlError {
 				badConnection := sqlError.Message == io.EOF.Error() ||
@@ -316,6 +385,7 @@ func (a *binlogReplicaApplier) replicaBinlogEventHandler(ctx *sql.Context) error
 		case <-a.stopReplicationChan:
 			ctx.GetLogger().Trace("received stop replication signal")
 			eventProducer.Stop()
+			a.createDoltCommits(ctx, engine)
 			return nil
 		}
 	}
@@ -334,6 +404,8 @@ func (a *binlogReplicaApplier) processBinlogEvent(ctx *sql.Context, engine *gms.
 	// tells us if checksums are enabled and what algorithm they use. We can NOT strip the checksum off of
 	// FormatDescription events, because FormatDescription always includes a CRC32 checksum, and Vitess depends on
 	// those bytes always being present when we parse the event into a FormatDescription type.
+	a.trackSourceLogPosition(event)
+	rawEvent := event
 	if a.format != nil && event.IsFormatDescription() == false {
 		var err error
 		event, _, err = event.StripChecksum(*a.format)
@@ -415,8 +487,25 @@ func (a *binlogReplicaApplier) processBinlogEvent(ctx *sql.Context, engine *gms.
 			ctx.SetSessionVariable(ctx, "unique_checks", 1)
 		}
 
-		ctx.SetCurrentDatabase(query.Database)
-		executeQueryWithEngine(ctx, engine, query.SQL)
+		// Statements are filtered by their default database, the same as MySQL's statement-based replication
+		database := a.filters.rewriteDatabase(query.Database)
+		switch strings.ToLower(query.SQL) {
+		case "begin", "commit":
+		default:
+			if a.filters.isDatabaseFilteredOut(ctx, database) {
+				ctx.GetLogger().WithFields(logrus.Fields{
+					"database": database,
+					"query":    query.SQL,
+				}).Debug("Skipping filtered out query")
+				break
+			}
+			targetDatabase, err := a.targetDatabaseName(ctx, engine, database)
+			if err != nil {
+				return err
+			}
+			ctx.SetCurrentDatabase(targetDatabase)
+			executeQueryWithEngine(ctx, engine, query.SQL)
+		}
 		createCommit = strings.ToLower(query.SQL) != "begin"
 
 	case event.IsRotate():
@@ -425,6 +514,12 @@ func (a *binlogReplicaApplier) processBinlogEvent(ctx *sql.Context, engine *gms.
 		// on the source server and it's also written when a FLUSH LOGS statement occurs on the source server.
 		// For more details, see: https://mariadb.com/kb/en/rotate_event/
 		ctx.GetLogger().Debug("Received binlog event: Rotate")
+		if a.format == nil {
+			// The first Rotate event of a stream is sent before the FormatDescription event
+			a.pendingRotate = rawEvent
+		} else if err := a.processRotateEvent(rawEvent); err != nil {
+			return err
+		}
 
 	case event.IsFormatDescription():
 		// This is a descriptor event that is written to the beginning of a binary log file, at position 4 (after
@@ -440,6 +535,13 @@ func (a *binlogReplicaApplier) processBinlogEvent(ctx *sql.Context, engine *gms.
 			"serverVersion": a.format.ServerVersion,
 			"checksum":      a.format.ChecksumAlgorithm,
 		}).Debug("Received binlog event: FormatDescription")
+		if a.pendingRotate != nil {
+			err = a.processRotateEvent(a.pendingRotate)
+			a.pendingRotate = nil
+			if err != nil {
+				return err
+			}
+		}
 
 	case event.IsPreviousGTIDs():
 		// Logged in every binlog to record the current replication state. Consists of the last GTID seen for each
@@ -487,6 +589,7 @@ func (a *binlogReplicaApplier) processBinlogEvent(ctx *sql.Context, engine *gms.
 		if err != nil {
 			return err
 		}
+		tableMap.Database = a.filters.rewriteDatabase(tableMap.Database)
 		ctx.GetLogger().WithFields(logrus.Fields{
 			"id":        tableId,
 			"tableName": tableMap.Name,
@@ -543,7 +646,15 @@ func (a *binlogReplicaApplier) processBinlogEvent(ctx *sql.Context, engine *gms.
 	if createCommit {
 		var databasesToCommit []string
 		if commitToAllDatabases {
-			databasesToCommit = getAllUserDatabaseNames(ctx, engine)
+			for _, database := range getAllUserDatabaseNames(ctx, engine) {
+				if !a.filters.isDatabaseFilteredOut(ctx, database) {
+					targetDatabase, err := a.targetDatabaseName(ctx, engine, database)
+					if err != nil {
+						return err
+					}
+					databasesToCommit = append(databasesToCommit, targetDatabase)
+				}
+			}
 			for _, database := range databasesToCommit {
 				executeQueryWithEngine(ctx, engine, "use `"+database+"`;")
 				executeQueryWithEngine(ctx, engine, "commit;")
@@ -561,18 +672,81 @@ func (a *binlogReplicaApplier) processBinlogEvent(ctx *sql.Context, engine *gms.
 			return fmt.Errorf("unable to store GTID executed metadata to disk: %s", err.Error())
 		}
 
-		// For now, create a Dolt commit from every data update. Eventually, we'll want to make this configurable.
-		ctx.GetLogger().Trace("Creating Dolt commit(s)")
+		// Source transactions are committed to Dolt once enough of them have been applied
+		if a.uncommittedGtids == nil {
+			a.uncommittedGtids = mysql.Mysql56GTIDSet{}
+		}
+		a.uncommittedGtids = a.uncommittedGtids.AddGTID(a.currentGtid)
+		a.uncommittedTransactions++
 		for _, database := range databasesToCommit {
-			executeQueryWithEngine(ctx, engine, "use `"+database+"`;")
-			executeQueryWithEngine(ctx, engine,
-				fmt.Sprintf("call dolt_commit('-Am', 'Dolt binlog replica commit: GTID %s');", a.currentGtid))
+			a.uncommittedDatabases[database] = struct{}{}
+		}
+		transactions := a.commitOptions.transactions
+		if transactions > 0 && a.uncommittedTransactions >= transactions {
+			a.createDoltCommits(ctx, engine)
 		}
 	}
 
 	return nil
 }
 
+// createDoltCommits creates a Dolt commit in each database that source transactions have been applied to since
+// the last Dolt commit. The commit message records the GTIDs of the source transactions, as well as the source's
+// binary log file and position after the last of them.
+func (a *binlogReplicaApplier) createDoltCommits(ctx *sql.Context, engine *gms.Engine) {
+	a.lastDoltCommit = time.Now()
+	if a.uncommittedGtids == nil || len(a.uncommittedDatabases) == 0 {
+		return
+	}
+
+	message := fmt.Sprintf("Dolt binlog replica commit: GTID %s", a.uncommittedGtids)
+	if a.uncommittedTransactions > 1 {
+		message = fmt.Sprintf("Dolt binlog replica commit: GTIDs %s", a.uncommittedGtids)
+	}
+	if a.sourceLogFile != "" {
+		message += fmt.Sprintf("\n\nSource binary log file: %s, position: %d", a.sourceLogFile, a.sourceLogPosition)
+	}
+
+	args := []string{"'-Am'", "'" + strings.ReplaceAll(message, "'", "''") + "'"}
+	if a.commitOptions.author != "" {
+		args = append(args, "'--author'", "'"+strings.ReplaceAll(a.commitOptions.author, "'", "''")+"'")
+	}
+
+	ctx.GetLogger().Trace("Creating Dolt commit(s)")
+	for _, database := range keys(a.uncommittedDatabases) {
+		executeQueryWithEngine(ctx, engine, "use `"+database+"`;")
+		executeQueryWithEngine(ctx, engine, fmt.Sprintf("call dolt_commit(%s);", strings.Join(args, ", ")))
+	}
+
+	a.uncommittedGtids = nil
+	a.uncommittedTransactions = 0
+	a.uncommittedDatabases = make(map[string]struct{})
+}
+
+// trackSourceLogPosition records the position in the source's binary log file after |event|, from the event's
+// header. Events that aren't written to the binary log file, such as the Rotate event that starts a stream, have
+// a position of zero and are ignored.
+func (a *binlogReplicaApplier) trackSourceLogPosition(event mysql.BinlogEvent) {
+	data := event.Bytes()
+	if len(data) < 17 {
+		return
+	}
+	if position := binary.LittleEndian.Uint32(data[13:17]); position > 0 {
+		a.sourceLogPosition = position
+	}
+}
+
+// processRotateEvent records the name of the source's binary log file that the Rotate event |event| names as the
+// next file events are received from. |event| must still include its checksum, if any.
+func (a *binlogReplicaApplier) processRotateEvent(event mysql.BinlogEvent) error {
+	filename, err := parseRotateEvent(*a.format, event)
+	if err != nil {
+		return err
+	}
+	a.sourceLogFile = filename
+	return nil
+}
+
 // processRowEvent processes a WriteRows, DeleteRows, or UpdateRows binlog event and returns an error if any problems
 // were encountered.
 func (a *binlogReplicaApplier) processRowEvent(ctx *sql.Context, event mysql.BinlogEvent, engine *gms.Engine) error {
@@ -623,7 +797,11 @@ func (a *binlogReplicaApplier) processRowEvent(ctx *sql.Context, event mysql.Bin
 		ctx.GetLogger().Errorf(msg)
 		DoltBinlogReplicaController.setSqlError(mysql.ERUnknownError, msg)
 	}
-	schema, err := getTableSchema(ctx, engine, tableMap.Name, tableMap.Database)
+	database, err := a.targetDatabaseName(ctx, engine, tableMap.Database)
+	if err != nil {
+		return err
+	}
+	schema, err := getTableSchema(ctx, engine, tableMap.Name, database)
 	if err != nil {
 		return err
 	}
@@ -637,7 +815,7 @@ func (a *binlogReplicaApplier) processRowEvent(ctx *sql.Context, event mysql.Bin
 		ctx.GetLogger().Debugf(" - Inserted Rows (table: %s)", tableMap.Name)
 	}
 
-	writeSession, tableWriter, err := getTableWriter(ctx, engine, tableMap.Name, tableMap.Database, foreignKeyChecksDisabled)
+	writeSession, tableWriter, err := getTableWriter(ctx, engine, tableMap.Name, database, foreignKeyChecksDisabled)
 	if err != nil {
 		return err
 	}
@@ -674,7 +852,7 @@ func (a *binlogReplicaApplier) processRowEvent(ctx *sql.Context, event mysql.Bin
 
 	}
 
-	err = closeWriteSession(ctx, engine, tableMap.Database, writeSession)
+	err = closeWriteSession(ctx, engine, database, writeSession)
 	if err != nil {
 		return err
 	}
@@ -682,6 +860,40 @@ func (a *binlogReplicaApplier) processRowEvent(ctx *sql.Context, event mysql.Bin
 	return nil
 }
 
+// targetDatabaseName returns the name of the database that changes replicated to |database| are applied to. If
+// @@dolt_replica_branch is set, that's the revision database for that branch of |database|. The branch is created
+// from the database's default branch if it doesn't exist yet, and checked out in the applier's session, so that
+// statements that qualify table names with |database| are applied to the branch as well.
+func (a *binlogReplicaApplier) targetDatabaseName(ctx *sql.Context, engine *gms.Engine, database string) (string, error) {
+	branch := a.filters.getBranch()
+	if branch == "" || database == "" {
+		return database, nil
+	}
+	if _, err := engine.Analyzer.Catalog.Database(ctx, database); err != nil {
+		// The database doesn't exist yet (e.g. it's about to be created), so there's no branch to apply changes to
+		return database, nil
+	}
+
+	sess := dsess.DSessFromSess(ctx.Session)
+	if headRef, err := sess.CWBHeadRef(ctx, database); err != nil || headRef.GetPath() != branch {
+		currentDatabase := ctx.GetCurrentDatabase()
+		defer ctx.SetCurrentDatabase(currentDatabase)
+
+		if err := queryWithEngine(ctx, engine, "use "+quoteIdentifier(database)+";"); err != nil {
+			return "", err
+		}
+		if _, err := engine.Analyzer.Catalog.Database(ctx, database+dsess.DbRevisionDelimiter+branch); err != nil {
+			if err := queryWithEngine(ctx, engine, "call dolt_branch("+quoteString(branch)+");"); err != nil {
+				return "", fmt.Errorf("unable to create replica branch %s: %w", branch, err)
+			}
+		}
+		if err := queryWithEngine(ctx, engine, "call dolt_checkout("+quoteString(branch)+");"); err != nil {
+			return "", fmt.Errorf("unable to check out replica branch %s: %w", branch, err)
+		}
+	}
+	return database + dsess.DbRevisionDelimiter + branch, nil
+}
+
 //
 // Helper functions
 //
@@ -945,6 +1157,23 @@ func loadReplicaServerId() (uint32, error) {
 	return serverId, nil
 }
 
+// queryWithEngine executes |query| against |engine| and returns any error from running it or reading its results.
+func queryWithEngine(ctx *sql.Context, engine *gms.Engine, query string) error {
+	queryCtx := sql.NewContext(ctx, sql.WithSession(ctx.Session))
+	_, iter, err := engine.Query(queryCtx, query)
+	if err != nil {
+		return err
+	}
+	for {
+		if _, err := iter.Next(queryCtx); err == io.EOF {
+			return iter.Close(queryCtx)
+		} else if err != nil {
+			iter.Close(queryCtx)
+			return err
+		}
+	}
+}
+
 func executeQueryWithEngine(ctx *sql.Context, engine *gms.Engine, query string) {
 	// Create a sub-context when running queries against the engine, so that we get an accurate query start time.
 	queryCtx := sql.NewContext(ctx, sql.WithSession(ctx.Session))
@@ -983,6 +1212,18 @@ func executeQueryWithEngine(ctx *sql.Context, engine *gms.Engine, query string)
 // Generic util functions...
 //
 
+// quoteIdentifier returns |s| quoted with backticks, with any backticks it contains escaped.
+func quoteIdentifier(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+// quoteString returns |s| as a quoted SQL string literal, with any special characters it contains escaped.
+func quoteString(s string) string {
+	buf := &bytes.Buffer{}
+	sqltypes.NewVarChar(s).EncodeSQL(buf)
+	return buf.String()
+}
+
 // convertToHexString returns a lower-case hex string representation of the specified uint16 value |v|.
 func convertToHexString(v uint16) string {
 	return fmt.Sprintf("%x", v)
@@ -135,6 +135,20 @@ func (d *doltBinlogReplicaController) StartReplica(ctx *sql.Context) error {
 		return err
 	}
 
+	// Filter options configured with system variables take effect when replication starts
+	err = d.filters.loadSystemVariables()
+	if err != nil {
+		return err
+	}
+	d.applier.filters = d.filters
+
+	// As are the options for when applied source transactions are committed to Dolt
+	commitOptions, err := loadReplicaCommitOptions()
+	if err != nil {
+		return err
+	}
+	d.applier.commitOptions = commitOptions
+
 	// Set execution context's user to the binlog replication user
 	d.ctx.SetClient(sql.Client{
 		User:    binlogApplierUser,
@@ -272,6 +286,7 @@ func (d *doltBinlogReplicaController) SetReplicationSourceOptions(ctx *sql.Conte
 
 // SetReplicationFilterOptions implements the BinlogReplicaController interface.
 func (d *doltBinlogReplicaController) SetReplicationFilterOptions(_ *sql.Context, options []binlogreplication.ReplicationOption) error {
+	sysVars := make(map[string]interface{})
 	for _, option := range options {
 		switch strings.ToUpper(option.Name) {
 		case "REPLICATE_DO_TABLE":
@@ -292,11 +307,31 @@ func (d *doltBinlogReplicaController) SetReplicationFilterOptions(_ *sql.Context
 			if err != nil {
 				return err
 			}
+		case "REPLICATE_DO_DB", "REPLICATE_IGNORE_DB", "REPLICATE_WILD_DO_TABLE", "REPLICATE_WILD_IGNORE_TABLE",
+			"REPLICATE_REWRITE_DB":
+			// These options are the same as the system variables of the same names, which the filters are loaded from
+			value, err := getOptionValueAsString(option)
+			if err != nil {
+				return err
+			}
+			sysVars[strings.ToLower(option.Name)] = value
 		default:
 			return fmt.Errorf("unsupported replication filter option: %s", option.Name)
 		}
 	}
 
+	if len(sysVars) > 0 {
+		if err := validateFilterSystemVariables(sysVars); err != nil {
+			return err
+		}
+		if err := sql.SystemVariables.AssignValues(sysVars); err != nil {
+			return err
+		}
+		if err := d.filters.loadSystemVariables(); err != nil {
+			return err
+		}
+	}
+
 	// TODO: Consider persisting filter settings. MySQL doesn't actually do this... unlike CHANGE REPLICATION SOURCE,
 	//       CHANGE REPLICATION FILTER requires users to re-apply the filter options every time a server is restarted,
 	//       or to pass them to mysqld on the command line or in configuration. Since we don't want to force users
@@ -16,6 +16,7 @@ package binlogreplication
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -23,25 +24,263 @@ import (
 	"github.com/dolthub/vitess/go/mysql"
 )
 
+const (
+	replicateDoDbSysVar            = "replicate_do_db"
+	replicateIgnoreDbSysVar        = "replicate_ignore_db"
+	replicateWildDoTableSysVar     = "replicate_wild_do_table"
+	replicateWildIgnoreTableSysVar = "replicate_wild_ignore_table"
+	replicateRewriteDbSysVar       = "replicate_rewrite_db"
+	replicaBranchSysVar            = "dolt_replica_branch"
+)
+
 // filterConfiguration defines the binlog filtering rules applied on the replica.
 type filterConfiguration struct {
 	// doTables holds a map of database name to map of table names, indicating tables that SHOULD be replicated.
 	doTables map[string]map[string]struct{}
 	// ignoreTables holds a map of database name to map of table names, indicating tables that should NOT be replicated.
 	ignoreTables map[string]map[string]struct{}
+	// wildDoTables holds the patterns of tables that SHOULD be replicated.
+	wildDoTables []wildTablePattern
+	// wildIgnoreTables holds the patterns of tables that should NOT be replicated.
+	wildIgnoreTables []wildTablePattern
+	// doDbs holds the names of databases that SHOULD be replicated.
+	doDbs map[string]struct{}
+	// ignoreDbs holds the names of databases that should NOT be replicated.
+	ignoreDbs map[string]struct{}
+	// rewriteDbs maps the names of databases on the source to the names of the databases they are replicated to.
+	rewriteDbs map[string]string
+	// branch is the branch that changes are replicated to in each database, or "" to replicate to each database's
+	// default branch. This is a Dolt-specific option.
+	branch string
 	// mu guards against concurrent access to the filter configuration data.
 	mu *sync.Mutex
 }
 
+// wildTablePattern is a database and table name pattern from a REPLICATE_WILD_DO_TABLE or
+// REPLICATE_WILD_IGNORE_TABLE option, e.g. "db%.t_1". The patterns use the same wildcards as the LIKE operator.
+type wildTablePattern struct {
+	pattern  string
+	database *regexp.Regexp
+	table    *regexp.Regexp
+}
+
 // newFilterConfiguration creates a new filterConfiguration instance and initializes members.
 func newFilterConfiguration() *filterConfiguration {
 	return &filterConfiguration{
 		doTables:     make(map[string]map[string]struct{}),
 		ignoreTables: make(map[string]map[string]struct{}),
+		doDbs:        make(map[string]struct{}),
+		ignoreDbs:    make(map[string]struct{}),
+		rewriteDbs:   make(map[string]string),
 		mu:           &sync.Mutex{},
 	}
 }
 
+// loadSystemVariables sets the filter options that are configured with system variables, i.e. @@replicate_do_db,
+// @@replicate_ignore_db, @@replicate_wild_do_table, @@replicate_wild_ignore_table, @@replicate_rewrite_db, and
+// @@dolt_replica_branch, and returns an error if any of them are invalid. Unlike MySQL, where these options can only
+// be set on the command line or with CHANGE REPLICATION FILTER, they can be set (and persisted) like any other system
+// variable, and take effect the next time replication is started. CHANGE REPLICATION FILTER sets the system variables
+// and reloads them, so its options take effect immediately.
+func (fc *filterConfiguration) loadSystemVariables() error {
+	values := make(map[string]string)
+	for _, name := range []string{replicateDoDbSysVar, replicateIgnoreDbSysVar, replicateWildDoTableSysVar,
+		replicateWildIgnoreTableSysVar, replicateRewriteDbSysVar, replicaBranchSysVar} {
+		value, err := lookupStringSystemVariable(name)
+		if err != nil {
+			return err
+		}
+		values[name] = value
+	}
+
+	wildDoTables, err := parseWildTablePatterns(values[replicateWildDoTableSysVar])
+	if err != nil {
+		return err
+	}
+	wildIgnoreTables, err := parseWildTablePatterns(values[replicateWildIgnoreTableSysVar])
+	if err != nil {
+		return err
+	}
+	rewriteDbs, err := parseRewriteDbs(values[replicateRewriteDbSysVar])
+	if err != nil {
+		return err
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.doDbs = parseDatabaseNames(values[replicateDoDbSysVar])
+	fc.ignoreDbs = parseDatabaseNames(values[replicateIgnoreDbSysVar])
+	fc.wildDoTables = wildDoTables
+	fc.wildIgnoreTables = wildIgnoreTables
+	fc.rewriteDbs = rewriteDbs
+	fc.branch = strings.TrimSpace(values[replicaBranchSysVar])
+	return nil
+}
+
+// validateFilterSystemVariables returns an error if any of the values of the filter system variables in |values| are
+// invalid.
+func validateFilterSystemVariables(values map[string]interface{}) error {
+	for name, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("@@%s is not a string: %v", name, value)
+		}
+
+		var err error
+		switch name {
+		case replicateWildDoTableSysVar, replicateWildIgnoreTableSysVar:
+			_, err = parseWildTablePatterns(s)
+		case replicateRewriteDbSysVar:
+			_, err = parseRewriteDbs(s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseDatabaseNames parses a comma separated list of database names, e.g. "db01,db02".
+func parseDatabaseNames(s string) map[string]struct{} {
+	databases := make(map[string]struct{})
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			databases[name] = struct{}{}
+		}
+	}
+	return databases
+}
+
+// parseWildTablePatterns parses a comma separated list of <database pattern>.<table pattern> patterns, e.g.
+// "db01.t%,db%.audit_log".
+func parseWildTablePatterns(s string) ([]wildTablePattern, error) {
+	var patterns []wildTablePattern
+	for _, pattern := range strings.Split(s, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+
+		database, table, ok := strings.Cut(pattern, ".")
+		if !ok || database == "" || table == "" {
+			return nil, fmt.Errorf("invalid wildcard table pattern '%s'; expected <database pattern>.<table pattern>", pattern)
+		}
+		patterns = append(patterns, wildTablePattern{
+			pattern:  pattern,
+			database: likePatternToRegexp(database),
+			table:    likePatternToRegexp(table),
+		})
+	}
+	return patterns, nil
+}
+
+// likePatternToRegexp converts |pattern|, which uses the same wildcards as the LIKE operator (i.e. '%' matches any
+// number of characters, '_' matches exactly one character, and '\' escapes the next character), into a regexp.
+func likePatternToRegexp(pattern string) *regexp.Regexp {
+	sb := strings.Builder{}
+	sb.WriteString("^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteString(".*")
+		case r == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// parseRewriteDbs parses a comma separated list of <from database>-><to database> rewrites, e.g. "db01->db02".
+func parseRewriteDbs(s string) (map[string]string, error) {
+	rewrites := make(map[string]string)
+	for _, rewrite := range strings.Split(s, ",") {
+		rewrite = strings.TrimSpace(rewrite)
+		if rewrite == "" {
+			continue
+		}
+
+		from, to, ok := strings.Cut(rewrite, "->")
+		from, to = strings.ToLower(strings.TrimSpace(from)), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid database rewrite '%s'; expected <from database>-><to database>", rewrite)
+		}
+		rewrites[from] = to
+	}
+	return rewrites, nil
+}
+
+// rewriteDatabase returns the name of the database that changes to |database| on the source are replicated to.
+func (fc *filterConfiguration) rewriteDatabase(database string) string {
+	if fc == nil {
+		return database
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if rewritten, ok := fc.rewriteDbs[strings.ToLower(database)]; ok {
+		return rewritten
+	}
+	return database
+}
+
+// getBranch returns the branch that changes are replicated to in each database, or "" if changes are replicated
+// to each database's default branch.
+func (fc *filterConfiguration) getBranch() string {
+	if fc == nil {
+		return ""
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.branch
+}
+
+// isDatabaseFilteredOut returns true if the database named |database|, after any rewrites have been applied, has
+// been filtered out on this replica and should not have any updates applied from binlog messages.
+func (fc *filterConfiguration) isDatabaseFilteredOut(ctx *sql.Context, database string) bool {
+	if fc == nil {
+		return false
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.isDatabaseFilteredOutLocked(ctx, database)
+}
+
+// isDatabaseFilteredOutLocked implements isDatabaseFilteredOut. The caller must hold |fc.mu|.
+//
+// If any doDbs options are specified, then a database MUST be listed in the set for it to be replicated, and
+// ignoreDbs options are not checked.
+// https://dev.mysql.com/doc/refman/8.0/en/replication-rules-db-options.html
+func (fc *filterConfiguration) isDatabaseFilteredOutLocked(ctx *sql.Context, database string) bool {
+	db := strings.ToLower(database)
+	if len(fc.doDbs) > 0 {
+		if _, ok := fc.doDbs[db]; !ok {
+			ctx.GetLogger().Tracef("skipping database %s (not in doDbs)", database)
+			return true
+		}
+		return false
+	}
+
+	if _, ok := fc.ignoreDbs[db]; ok {
+		ctx.GetLogger().Tracef("skipping database %s (in ignoreDbs)", database)
+		return true
+	}
+	return false
+}
+
 // setDoTables sets the tables that are allowed to replicate and returns an error if any problems were
 // encountered, such as unqualified tables being specified in |urts|. If any DoTables were previously configured,
 // they are cleared out before the new tables are set as the value of DoTables.
@@ -96,8 +335,8 @@ func (fc *filterConfiguration) setIgnoreTables(urts []sql.UnresolvedTable) error
 	return nil
 }
 
-// isTableFilteredOut returns true if the table identified by |tableMap| has been filtered out on this replica and
-// should not have any updates applied from binlog messages.
+// isTableFilteredOut returns true if the table identified by |tableMap|, whose database name has already been
+// rewritten, has been filtered out on this replica and should not have any updates applied from binlog messages.
 func (fc *filterConfiguration) isTableFilteredOut(ctx *sql.Context, tableMap *mysql.TableMap) bool {
 	if fc == nil {
 		return false
@@ -109,6 +348,11 @@ func (fc *filterConfiguration) isTableFilteredOut(ctx *sql.Context, tableMap *my
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
 
+	// Database options are checked before table options
+	if fc.isDatabaseFilteredOutLocked(ctx, tableMap.Database) {
+		return true
+	}
+
 	// If any filter doTable options are specified, then a table MUST be listed in the set
 	// for it to be replicated. doTables options are processed BEFORE ignoreTables options.
 	// If a table appears in both doTable and ignoreTables, it is ignored.
@@ -132,9 +376,38 @@ func (fc *filterConfiguration) isTableFilteredOut(ctx *sql.Context, tableMap *my
 		}
 	}
 
+	// Wildcard options are checked after the exact table options. A table matching a wildIgnoreTables
+	// pattern is ignored, and if any wildDoTables patterns are specified, a table MUST match one of them.
+	for _, pattern := range fc.wildIgnoreTables {
+		if pattern.matches(db, table) {
+			ctx.GetLogger().Tracef("skipping table %s.%s (matches wildIgnoreTables pattern %s)", tableMap.Database, tableMap.Name, pattern.pattern)
+			return true
+		}
+	}
+	if len(fc.wildDoTables) > 0 {
+		if _, ok := fc.doTables[db][table]; !ok {
+			matched := false
+			for _, pattern := range fc.wildDoTables {
+				if pattern.matches(db, table) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				ctx.GetLogger().Tracef("skipping table %s.%s (doesn't match any wildDoTables patterns)", tableMap.Database, tableMap.Name)
+				return true
+			}
+		}
+	}
+
 	return false
 }
 
+// matches returns true if the table |table| in the database |database| matches this pattern.
+func (p wildTablePattern) matches(database, table string) bool {
+	return p.database.MatchString(database) && p.table.MatchString(table)
+}
+
 // getDoTables returns a slice of qualified table names that are configured to be replicated.
 func (fc *filterConfiguration) getDoTables() []string {
 	fc.mu.Lock()
@@ -0,0 +1,100 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binlogreplication
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/vitess/go/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWildTablePatterns(t *testing.T) {
+	_, err := parseWildTablePatterns("db01")
+	require.ErrorContains(t, err, "invalid wildcard table pattern")
+	_, err = parseWildTablePatterns("db01.")
+	require.ErrorContains(t, err, "invalid wildcard table pattern")
+
+	patterns, err := parseWildTablePatterns("db%.t_, DB01.audit\\_%,")
+	require.NoError(t, err)
+	require.Len(t, patterns, 2)
+
+	require.True(t, patterns[0].matches("db01", "t1"))
+	require.True(t, patterns[0].matches("db", "t2"))
+	require.False(t, patterns[0].matches("db01", "t10"))
+	require.False(t, patterns[0].matches("xdb01", "t1"))
+
+	require.True(t, patterns[1].matches("db01", "audit_log"))
+	require.False(t, patterns[1].matches("db01", "auditlog"))
+	require.False(t, patterns[1].matches("db02", "audit_log"))
+}
+
+func TestParseRewriteDbs(t *testing.T) {
+	_, err := parseRewriteDbs("db01")
+	require.ErrorContains(t, err, "invalid database rewrite")
+	_, err = parseRewriteDbs("db01->")
+	require.ErrorContains(t, err, "invalid database rewrite")
+
+	rewrites, err := parseRewriteDbs("DB01->db02, db03 -> Db04")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"db01": "db02", "db03": "Db04"}, rewrites)
+}
+
+func TestFilterConfiguration(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	tableMap := func(database, table string) *mysql.TableMap {
+		return &mysql.TableMap{Database: database, Name: table}
+	}
+
+	var err error
+	fc := newFilterConfiguration()
+	fc.rewriteDbs, err = parseRewriteDbs("src->db01")
+	require.NoError(t, err)
+	require.Equal(t, "db01", fc.rewriteDatabase("SRC"))
+	require.Equal(t, "db02", fc.rewriteDatabase("db02"))
+
+	// Without any filters, everything is replicated
+	require.False(t, fc.isDatabaseFilteredOut(ctx, "db01"))
+	require.False(t, fc.isTableFilteredOut(ctx, tableMap("db01", "t1")))
+
+	// Ignored databases aren't replicated
+	fc.ignoreDbs = parseDatabaseNames("db02")
+	require.True(t, fc.isDatabaseFilteredOut(ctx, "DB02"))
+	require.True(t, fc.isTableFilteredOut(ctx, tableMap("db02", "t1")))
+	require.False(t, fc.isTableFilteredOut(ctx, tableMap("db01", "t1")))
+
+	// If any databases are listed to be replicated, no others are, and ignored databases aren't checked
+	fc.doDbs = parseDatabaseNames("db01, db02")
+	require.False(t, fc.isDatabaseFilteredOut(ctx, "db02"))
+	require.True(t, fc.isDatabaseFilteredOut(ctx, "db03"))
+	require.True(t, fc.isDatabaseFilteredOut(ctx, ""))
+
+	// Tables matching a wildcard ignore pattern aren't replicated
+	fc.wildIgnoreTables, err = parseWildTablePatterns("db01.tmp%")
+	require.NoError(t, err)
+	require.True(t, fc.isTableFilteredOut(ctx, tableMap("db01", "tmp_1")))
+	require.False(t, fc.isTableFilteredOut(ctx, tableMap("db01", "t1")))
+
+	// If any wildcard do patterns are specified, a table must match one of them, or be listed in the do tables
+	fc.wildDoTables, err = parseWildTablePatterns("db01.t%")
+	require.NoError(t, err)
+	fc.doTables = map[string]map[string]struct{}{"db02": {"users": {}}}
+	require.False(t, fc.isTableFilteredOut(ctx, tableMap("db01", "t1")))
+	require.True(t, fc.isTableFilteredOut(ctx, tableMap("db01", "tmp_1")))
+	require.True(t, fc.isTableFilteredOut(ctx, tableMap("db01", "users")))
+	require.False(t, fc.isTableFilteredOut(ctx, tableMap("db02", "users")))
+	require.True(t, fc.isTableFilteredOut(ctx, tableMap("db02", "t1")))
+}
@@ -175,6 +175,111 @@ func TestBinlogReplicationFilters_doTablesAndIgnoreTables(t *testing.T) {
 	require.NoError(t, rows.Close())
 }
 
+// TestBinlogReplicationFilters_wildTables tests that the replicate_wild_do_table and replicate_wild_ignore_table
+// replication filtering options are correctly applied and honored.
+func TestBinlogReplicationFilters_wildTables(t *testing.T) {
+	defer teardown(t)
+	replicaSystemVars := copyMap(doltReplicaSystemVars)
+	replicaSystemVars["replicate_wild_do_table"] = "db01.t%"
+	replicaSystemVars["replicate_wild_ignore_table"] = "db01.tmp%"
+	startSqlServersWithDoltSystemVars(t, replicaSystemVars)
+	startReplication(t, mySqlPort)
+
+	// Make changes on the primary
+	primaryDatabase.MustExec("CREATE TABLE db01.t1 (pk INT PRIMARY KEY);")
+	primaryDatabase.MustExec("CREATE TABLE db01.tmp1 (pk INT PRIMARY KEY);")
+	primaryDatabase.MustExec("CREATE TABLE db01.other (pk INT PRIMARY KEY);")
+	for i := 1; i < 12; i++ {
+		primaryDatabase.MustExec(fmt.Sprintf("INSERT INTO db01.t1 VALUES (%d);", i))
+		primaryDatabase.MustExec(fmt.Sprintf("INSERT INTO db01.tmp1 VALUES (%d);", i))
+		primaryDatabase.MustExec(fmt.Sprintf("INSERT INTO db01.other VALUES (%d);", i))
+	}
+	primaryDatabase.MustExec("DELETE FROM db01.t1 WHERE pk = 11;")
+	primaryDatabase.MustExec("DELETE FROM db01.tmp1 WHERE pk = 11;")
+	primaryDatabase.MustExec("DELETE FROM db01.other WHERE pk = 11;")
+
+	// Pause to let the replica catch up
+	waitForReplicaToCatchUp(t)
+
+	// Only changes to tables matching a do pattern, but not an ignore pattern, are applied on the replica
+	requireReplicaResults(t, "SELECT COUNT(pk) FROM db01.t1;", [][]any{{"10"}})
+	requireReplicaResults(t, "SELECT COUNT(pk) FROM db01.tmp1;", [][]any{{"0"}})
+	requireReplicaResults(t, "SELECT COUNT(pk) FROM db01.other;", [][]any{{"0"}})
+}
+
+// TestBinlogReplicationFilters_changeReplicationFilter tests that the wildcard table and database options can be set
+// with CHANGE REPLICATION FILTER, as well as with system variables.
+func TestBinlogReplicationFilters_changeReplicationFilter(t *testing.T) {
+	defer teardown(t)
+	startSqlServersWithDoltSystemVars(t, doltReplicaSystemVars)
+	startReplication(t, mySqlPort)
+
+	replicaDatabase.MustExec("CHANGE REPLICATION FILTER REPLICATE_WILD_IGNORE_TABLE=('db01.tmp%'), REPLICATE_IGNORE_DB=(db04);")
+	requireReplicaResults(t, "SELECT @@replicate_wild_ignore_table, @@replicate_ignore_db;", [][]any{{"db01.tmp%", "db04"}})
+
+	primaryDatabase.MustExec("CREATE TABLE db01.t1 (pk INT PRIMARY KEY);")
+	primaryDatabase.MustExec("CREATE TABLE db01.tmp1 (pk INT PRIMARY KEY);")
+	primaryDatabase.MustExec("INSERT INTO db01.t1 VALUES (1), (2);")
+	primaryDatabase.MustExec("INSERT INTO db01.tmp1 VALUES (1), (2);")
+	primaryDatabase.MustExec("CREATE DATABASE db04;")
+	waitForReplicaToCatchUp(t)
+
+	requireReplicaResults(t, "SELECT COUNT(pk) FROM db01.t1;", [][]any{{"2"}})
+	requireReplicaResults(t, "SELECT COUNT(pk) FROM db01.tmp1;", [][]any{{"0"}})
+	requireReplicaResults(t, "SHOW DATABASES LIKE 'db04';", [][]any{})
+}
+
+// TestBinlogReplicationFilters_databases tests that the replicate_ignore_db and replicate_rewrite_db
+// replication filtering options are correctly applied and honored.
+func TestBinlogReplicationFilters_databases(t *testing.T) {
+	defer teardown(t)
+	replicaSystemVars := copyMap(doltReplicaSystemVars)
+	replicaSystemVars["replicate_ignore_db"] = "db04"
+	replicaSystemVars["replicate_rewrite_db"] = "db02->db03"
+	startSqlServersWithDoltSystemVars(t, replicaSystemVars)
+	startReplication(t, mySqlPort)
+	replicaDatabase.MustExec("CREATE DATABASE db03;")
+
+	// Changes to db02 are applied to db03 on the replica
+	primaryDatabase.MustExec("CREATE DATABASE db02;")
+	primaryDatabase.MustExec("USE db02;")
+	primaryDatabase.MustExec("CREATE TABLE t (pk INT PRIMARY KEY);")
+	primaryDatabase.MustExec("INSERT INTO t VALUES (1), (2), (3);")
+
+	// Changes to db04 are not applied on the replica
+	primaryDatabase.MustExec("CREATE DATABASE db04;")
+	primaryDatabase.MustExec("USE db04;")
+	primaryDatabase.MustExec("CREATE TABLE t (pk INT PRIMARY KEY);")
+	primaryDatabase.MustExec("INSERT INTO t VALUES (1), (2), (3);")
+	primaryDatabase.MustExec("USE db01;")
+
+	// Pause to let the replica catch up
+	waitForReplicaToCatchUp(t)
+
+	requireReplicaResults(t, "SELECT COUNT(pk) FROM db03.t;", [][]any{{"3"}})
+	requireReplicaResults(t, "SHOW TABLES FROM db02;", [][]any{})
+	requireReplicaResults(t, "SHOW TABLES FROM db04;", [][]any{})
+}
+
+// TestBinlogReplicationFilters_replicaBranch tests that the dolt_replica_branch option replicates changes
+// to a branch other than each database's default branch.
+func TestBinlogReplicationFilters_replicaBranch(t *testing.T) {
+	defer teardown(t)
+	replicaSystemVars := copyMap(doltReplicaSystemVars)
+	replicaSystemVars["dolt_replica_branch"] = "replica"
+	startSqlServersWithDoltSystemVars(t, replicaSystemVars)
+	startReplication(t, mySqlPort)
+
+	primaryDatabase.MustExec("CREATE TABLE db01.t (pk INT PRIMARY KEY);")
+	primaryDatabase.MustExec("INSERT INTO db01.t VALUES (1), (2), (3);")
+	waitForReplicaToCatchUp(t)
+
+	// Changes are applied to the replica branch, which is created from the default branch
+	requireReplicaResults(t, "SELECT COUNT(pk) FROM `db01/replica`.t;", [][]any{{"3"}})
+	requireReplicaResults(t, "SELECT name FROM db01.dolt_branches ORDER BY name;", [][]any{{"main"}, {"replica"}})
+	requireReplicaResults(t, "SHOW TABLES FROM `db01/main`;", [][]any{})
+}
+
 // TestBinlogReplicationFilters_errorCases test returned errors for various error cases.
 func TestBinlogReplicationFilters_errorCases(t *testing.T) {
 	defer teardown(t)
@@ -188,4 +293,9 @@ func TestBinlogReplicationFilters_errorCases(t *testing.T) {
 	_, err = replicaDatabase.Queryx("CHANGE REPLICATION FILTER REPLICATE_IGNORE_TABLE=(t1);")
 	require.Error(t, err)
 	require.ErrorContains(t, err, "no database specified for table")
+
+	// Wildcard patterns must match a database and a table
+	_, err = replicaDatabase.Queryx("CHANGE REPLICATION FILTER REPLICATE_WILD_DO_TABLE=('t%');")
+	require.Error(t, err)
+	require.ErrorContains(t, err, "invalid wildcard table pattern")
 }
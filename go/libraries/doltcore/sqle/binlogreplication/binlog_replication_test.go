@@ -371,6 +371,51 @@ func TestDoltCommits(t *testing.T) {
 	require.Equal(t, 5, len(allRows)) // 4 transactions + 1 initial commit
 }
 
+// TestDoltCommits_batched tests that the dolt_replica_commit_transactions and dolt_replica_commit_author options
+// control how many source transactions are included in each Dolt commit and who authors them, and that applied
+// source transactions that haven't been committed yet are committed when replication stops.
+func TestDoltCommits_batched(t *testing.T) {
+	defer teardown(t)
+	replicaSystemVars := copyMap(doltReplicaSystemVars)
+	replicaSystemVars["dolt_replica_commit_transactions"] = "2"
+	replicaSystemVars["dolt_replica_commit_author"] = "Replica Bot <replica@example.com>"
+	startSqlServersWithDoltSystemVars(t, replicaSystemVars)
+	startReplication(t, mySqlPort)
+
+	primaryDatabase.MustExec("create table t1 (pk int primary key);")
+	primaryDatabase.MustExec("insert into t1 values (1);")
+	primaryDatabase.MustExec("insert into t1 values (2);")
+	waitForReplicaToCatchUp(t)
+
+	// The first two transactions are committed together; the third hasn't been committed yet
+	requireReplicaResults(t, "select count(*) from db01.dolt_log;", [][]any{{"2"}})
+	requireReplicaResults(t, "select count(*) from db01.t1;", [][]any{{"2"}})
+	rows, err := replicaDatabase.Queryx("select committer, email, message from db01.dolt_log limit 1;")
+	require.NoError(t, err)
+	row := convertMapScanResultToStrings(readNextRow(t, rows))
+	require.NoError(t, rows.Close())
+	require.Equal(t, "Replica Bot", row["committer"])
+	require.Equal(t, "replica@example.com", row["email"])
+	require.Contains(t, row["message"], "Dolt binlog replica commit: GTIDs ")
+	require.Contains(t, row["message"], "Source binary log file: ")
+
+	// Stopping replication commits the remaining transaction
+	replicaDatabase.MustExec("stop replica;")
+	require.Eventually(t, func() bool {
+		rows, err := replicaDatabase.Queryx("select count(*) as count from db01.dolt_log;")
+		require.NoError(t, err)
+		row := convertMapScanResultToStrings(readNextRow(t, rows))
+		require.NoError(t, rows.Close())
+		return row["count"] == "3"
+	}, 5*time.Second, 100*time.Millisecond)
+	rows, err = replicaDatabase.Queryx("select message from db01.dolt_log limit 1;")
+	require.NoError(t, err)
+	row = convertMapScanResultToStrings(readNextRow(t, rows))
+	require.NoError(t, rows.Close())
+	require.Contains(t, row["message"], "Dolt binlog replica commit: GTID ")
+	require.NotContains(t, row["message"], "GTIDs")
+}
+
 // TestForeignKeyChecks tests that foreign key constraints replicate correctly when foreign key checks are
 // enabled and disabled.
 func TestForeignKeyChecks(t *testing.T) {
@@ -56,3 +56,52 @@ func getServerUuid(_ context.Context) (string, error) {
 
 	return "", fmt.Errorf("@@server_uuid is not a string – must be set to a valid UUID")
 }
+
+// lookupIntegerSystemVariable returns the global value of the integer system variable named |name|, or
+// |defaultValue| if the system variable isn't defined.
+func lookupIntegerSystemVariable(name string, defaultValue int64) (int64, error) {
+	_, value, ok := sql.SystemVariables.GetGlobal(name)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	switch v := value.(type) {
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case uint:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("@@%s is not an integer: %v", name, value)
+	}
+}
+
+// lookupStringSystemVariable returns the global value of the string system variable named |name|, or "" if the
+// system variable isn't defined.
+func lookupStringSystemVariable(name string) (string, error) {
+	_, value, ok := sql.SystemVariables.GetGlobal(name)
+	if !ok || value == nil {
+		return "", nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("@@%s is not a string: %v", name, value)
+	}
+	return s, nil
+}
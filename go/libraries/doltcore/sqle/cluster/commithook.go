@@ -262,6 +262,31 @@ func (h *commithook) attemptHeartbeat(ctx context.Context) {
 	h.mu.Lock()
 }
 
+// destHasChunk returns true if the chunk store of the standby this hook
+// replicates to has the chunk |addr|. It fetches the destDB if it hasn't been
+// fetched yet.
+func (h *commithook) destHasChunk(ctx context.Context, addr hash.Hash) (bool, error) {
+	h.mu.Lock()
+	destDB := h.destDB
+	h.mu.Unlock()
+	if destDB == nil {
+		var err error
+		destDB, err = h.destDBF(ctx)
+		if err != nil {
+			return false, err
+		}
+		h.mu.Lock()
+		if h.destDB == nil {
+			h.destDB = destDB
+		} else {
+			destDB = h.destDB
+		}
+		h.mu.Unlock()
+	}
+	cs := datas.ChunkStoreFromDatabase(doltdb.HackDatasDatabaseFromDoltDB(destDB))
+	return cs.Has(ctx, addr)
+}
+
 // Called by the replicate thread to push the nextHead to the destDB and set
 // its root to the new value.
 //
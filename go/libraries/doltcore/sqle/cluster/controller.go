@@ -99,6 +99,18 @@ type Controller struct {
 
 	replicationClients []*replicationServiceClient
 
+	// A unique identifier for this run of the server, which is reported to
+	// peers in GetClusterStatus.
+	serverID  string
+	failover  *failoverMonitor
+	freshness *standbyFreshness
+
+	// The highest epoch at which this server has voted for a candidate
+	// in an automatic failover, and the server id of that candidate.
+	// Persisted in |persistentCfg| before a vote is granted.
+	voteEpoch    int
+	voteServerID string
+
 	mysqlDb          *mysql_db.MySQLDb
 	mysqlDbPersister *replicatingMySQLDbPersister
 	mysqlDbReplicas  []*mysqlDbReplica
@@ -140,11 +152,17 @@ func NewController(lgr *logrus.Logger, cfg servercfg.ClusterConfig, pCfg config.
 	if err != nil {
 		return nil, err
 	}
+	voteEpoch, voteServerID, err := loadPersistedVote(pCfg)
+	if err != nil {
+		return nil, err
+	}
 	ret := &Controller{
 		cfg:           cfg,
 		persistentCfg: pCfg,
 		role:          role,
 		epoch:         epoch,
+		voteEpoch:     voteEpoch,
+		voteServerID:  voteServerID,
 		commithooks:   make([]*commithook, 0),
 		lgr:           lgr,
 	}
@@ -204,6 +222,12 @@ func NewController(lgr *logrus.Logger, cfg servercfg.ClusterConfig, pCfg config.
 
 	ret.outstandingDropDatabases = make(map[string]*databaseDropReplication)
 
+	ret.serverID = keyIDStr
+	ret.freshness = newStandbyFreshness()
+	if len(ret.replicationClients) > 0 {
+		ret.failover = ret.newFailoverMonitor(cfg.AutomaticFailoverConfig())
+	}
+
 	return ret, nil
 }
 
@@ -224,6 +248,13 @@ func (c *Controller) Run() {
 		defer wg.Done()
 		c.bcReplication.Run()
 	}()
+	if c.failover != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.failover.Run()
+		}()
+	}
 	wg.Wait()
 	for _, client := range c.replicationClients {
 		client.closer()
@@ -234,6 +265,9 @@ func (c *Controller) GracefulStop() error {
 	c.jwks.GracefulStop()
 	c.mysqlDbPersister.GracefulStop()
 	c.bcReplication.GracefulStop()
+	if c.failover != nil {
+		c.failover.GracefulStop()
+	}
 	return nil
 }
 
@@ -761,6 +795,8 @@ func (c *Controller) RegisterGrpcServices(ctxFactory func(context.Context) (*sql
 		branchControl:        c.branchControlController,
 		branchControlFilesys: c.branchControlFilesys,
 		dropDatabase:         c.dropDatabase,
+		clusterStatus:        c.getClusterStatusResponse,
+		requestVote:          c.requestVote,
 		lgr:                  c.lgr.WithFields(logrus.Fields{}),
 	})
 }
@@ -0,0 +1,650 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	replicationapi "github.com/dolthub/dolt/go/gen/proto/dolt/services/replicationapi/v1alpha1"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/servercfg"
+	"github.com/dolthub/dolt/go/libraries/utils/config"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// The keys under PersistentConfigPrefix at which a server persists the
+// highest epoch at which it has voted in an automatic failover, and the server
+// id of the candidate it voted for.
+const (
+	persistedVoteEpochKey    = "failover_vote_epoch"
+	persistedVoteServerIDKey = "failover_vote_server_id"
+)
+
+// clusterMemberStatus is the status of a server in the cluster, as it is
+// returned from GetClusterStatus.
+type clusterMemberStatus struct {
+	role     Role
+	epoch    int
+	serverID string
+	// The highest epoch at which the server has voted in an automatic
+	// failover.
+	voteEpoch int
+	// The root hash of each database the server replicates.
+	roots map[string]hash.Hash
+	// The name of the standby remote through which the status was
+	// fetched. Empty for the status of this server.
+	remote string
+}
+
+func (s clusterMemberStatus) toProto() *replicationapi.GetClusterStatusResponse {
+	ret := &replicationapi.GetClusterStatusResponse{
+		Role:      string(s.role),
+		Epoch:     int64(s.epoch),
+		ServerId:  s.serverID,
+		VoteEpoch: int64(s.voteEpoch),
+	}
+	for name, root := range s.roots {
+		ret.Databases = append(ret.Databases, &replicationapi.DatabaseRootHash{
+			Name:     name,
+			RootHash: root[:],
+		})
+	}
+	return ret
+}
+
+func clusterMemberStatusFromProto(resp *replicationapi.GetClusterStatusResponse) (clusterMemberStatus, error) {
+	ret := clusterMemberStatus{
+		role:      Role(resp.Role),
+		epoch:     int(resp.Epoch),
+		serverID:  resp.ServerId,
+		voteEpoch: int(resp.VoteEpoch),
+		roots:     make(map[string]hash.Hash, len(resp.Databases)),
+	}
+	for _, db := range resp.Databases {
+		if len(db.RootHash) != hash.ByteLen {
+			return clusterMemberStatus{}, fmt.Errorf("invalid root hash for database %s: expected %d bytes, got %d", db.Name, hash.ByteLen, len(db.RootHash))
+		}
+		ret.roots[db.Name] = hash.New(db.RootHash)
+	}
+	return ret, nil
+}
+
+// failoverMonitor implements automatic failover for a cluster. Every
+// heartbeat interval, it fetches the status of every peer in the cluster
+// through GetClusterStatus. While this server is a standby, if it has not
+// been able to reach a primary at its epoch or higher for the failover
+// timeout, it becomes a candidate to be promoted to primary at a new epoch
+// if:
+//
+// * it can reach a quorum of the cluster, counting itself, and
+// * none of the peers it can reach is a primary or is in
+// detected_broken_config, and
+// * it is the most caught up of the standbys it can reach. A standby is at
+// least as caught up as a peer if, for every database, it has the peer's
+// root hash in its chunk store. This is checked in both directions, asking
+// the peer's chunk stores whether they have this server's root hashes, so
+// that two standbys always reach opposite conclusions. Ties are broken by
+// the server ids.
+//
+// A candidate only promotes itself once a majority of the cluster, counting
+// itself, has voted for it at the new epoch through RequestVote. Every
+// server durably records its vote and votes for at most one candidate at
+// each epoch, so two standbys which can each reach a different quorum of
+// the cluster can not both be promoted at the same epoch. A standby does not
+// vote while it can still reach a primary, so that a standby which is only
+// cut off from the primary does not replace it. A candidate which loses
+// campaigns again at the next epoch once the failover timeout has passed
+// again.
+//
+// The peers learn of the new epoch through the role and epoch headers on
+// their next request to or from the new primary, and they transition to
+// standby through the interceptors exactly as they would for a manual
+// failover. In particular, an old primary which becomes reachable again is
+// demoted to a standby at the new epoch.
+//
+// While this server is a primary, it demotes itself to a standby at its
+// epoch if it has not been able to reach a quorum of the cluster for half the
+// failover timeout. A primary cut off from the majority of the cluster may be
+// replaced by a standby on the other side of the partition, so it stops
+// accepting writes before any standby can have promoted itself.
+//
+// The monitor runs even when automatic failover is disabled, since a standby
+// also uses the statuses of the primary to track how up to date it is. See
+// standbyFreshness.
+type failoverMonitor struct {
+	lgr      *logrus.Entry
+	interval time.Duration
+	timeout  time.Duration
+
+	automaticFailover bool
+	freshness         *standbyFreshness
+
+	clients []*replicationServiceClient
+
+	// Returns the status of this server.
+	localStatus func(context.Context) (clusterMemberStatus, error)
+	// Returns true if the chunk store of the local database |db| has the
+	// chunk |h|.
+	hasChunk func(ctx context.Context, db string, h hash.Hash) (bool, error)
+	// Returns true if the chunk store of the database |db| on the peer
+	// reached through the standby remote |remote| has the chunk |h|.
+	peerHasChunk func(ctx context.Context, remote, db string, h hash.Hash) (bool, error)
+	// Records the vote of this server for the candidate |serverID| at
+	// |epoch|, and returns true if it was granted. See
+	// Controller.requestVote.
+	vote func(epoch int, serverID string) (bool, error)
+	// Requests the vote of the peer reached through the standby remote
+	// |remote| for the candidate |serverID| at |epoch|.
+	peerVote func(ctx context.Context, remote string, epoch int, serverID string) (bool, error)
+	// Transitions this server to primary at |epoch|.
+	promote func(epoch int) error
+	// Transitions this server to standby at |epoch|.
+	demote func(epoch int) error
+
+	// The last time this server was not a standby or could reach a
+	// primary at its epoch or higher. Only accessed from the run loop.
+	lastPrimaryContact time.Time
+	// The last time this server was not a primary or could reach a quorum
+	// of the cluster. Only accessed from the run loop.
+	lastQuorumContact time.Time
+
+	mu     sync.Mutex
+	cancel func()
+	done   chan struct{}
+
+	// The last time this server was a standby and could reach a primary
+	// at its epoch or higher. Guarded by |mu|, since it is read when peers
+	// request the vote of this server.
+	lastPrimarySeen time.Time
+}
+
+func (m *failoverMonitor) Run() {
+	m.mu.Lock()
+	if m.done != nil {
+		// GracefulStop was called before we started running.
+		m.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	m.mu.Unlock()
+	defer close(m.done)
+
+	m.lgr.Tracef("cluster/failover: running with heartbeat interval %v and failover timeout %v", m.interval, m.timeout)
+	m.lastPrimaryContact = time.Now()
+	m.lastQuorumContact = m.lastPrimaryContact
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.heartbeat(ctx)
+		}
+	}
+}
+
+func (m *failoverMonitor) GracefulStop() {
+	m.mu.Lock()
+	if m.done == nil {
+		m.done = make(chan struct{})
+		close(m.done)
+	}
+	cancel, done := m.cancel, m.done
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	<-done
+}
+
+func (m *failoverMonitor) heartbeat(ctx context.Context) {
+	self, err := m.localStatus(ctx)
+	if err != nil {
+		m.lgr.Warnf("cluster/failover: could not load the status of this server: %v", err)
+		return
+	}
+	start := time.Now()
+	peers := m.peerStatuses(ctx)
+	if self.role == RoleStandby {
+		if primary := currentPrimary(self, peers); primary != nil {
+			m.mu.Lock()
+			m.lastPrimarySeen = start
+			m.mu.Unlock()
+			if err := m.freshness.observe(ctx, self, *primary, start, m.hasChunk); err != nil {
+				m.lgr.Warnf("cluster/failover: could not compare replication progress with the primary: %v", err)
+			}
+		}
+	}
+	if !m.automaticFailover {
+		return
+	}
+	if m.shouldDemote(self, peers, time.Now()) {
+		if err := m.demote(self.epoch); err != nil {
+			m.lgr.Errorf("cluster/failover: failed to transition to standby at epoch %d: %v", self.epoch, err)
+		}
+		return
+	}
+	epoch, promote := m.evaluate(ctx, self, peers, time.Now())
+	if promote && m.campaign(ctx, self, epoch, peers) {
+		if err := m.promote(epoch); err != nil {
+			m.lgr.Errorf("cluster/failover: failed to transition to primary at epoch %d: %v", epoch, err)
+		}
+	}
+}
+
+// peerStatuses fetches the status of every peer in the cluster. The returned
+// slice has an entry for each peer, which is nil if the peer could not be
+// reached.
+func (m *failoverMonitor) peerStatuses(ctx context.Context) []*clusterMemberStatus {
+	ret := make([]*clusterMemberStatus, len(m.clients))
+	var wg sync.WaitGroup
+	wg.Add(len(m.clients))
+	for i, client := range m.clients {
+		i, client := i, client
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, m.interval)
+			defer cancel()
+			resp, err := client.client.GetClusterStatus(ctx, &replicationapi.GetClusterStatusRequest{})
+			if err != nil {
+				m.lgr.Tracef("cluster/failover: could not fetch the status of %s: %v", client.remote, err)
+				return
+			}
+			status, err := clusterMemberStatusFromProto(resp)
+			if err != nil {
+				m.lgr.Warnf("cluster/failover: received an invalid status from %s: %v", client.remote, err)
+				return
+			}
+			status.remote = client.remote
+			ret[i] = &status
+		}()
+	}
+	wg.Wait()
+	return ret
+}
+
+// currentPrimary returns the status of the peer which is the primary at the
+// highest epoch, if it is at least the epoch of |self|.
+func currentPrimary(self clusterMemberStatus, peers []*clusterMemberStatus) *clusterMemberStatus {
+	var ret *clusterMemberStatus
+	for _, peer := range peers {
+		if peer != nil && peer.role == RolePrimary && peer.epoch >= self.epoch {
+			if ret == nil || peer.epoch > ret.epoch {
+				ret = peer
+			}
+		}
+	}
+	return ret
+}
+
+// evaluate decides whether this server, whose status is |self|, should
+// become a candidate to be promoted to primary given the statuses of its
+// |peers|. If it should, it returns the epoch to campaign at and true.
+func (m *failoverMonitor) evaluate(ctx context.Context, self clusterMemberStatus, peers []*clusterMemberStatus, now time.Time) (int, bool) {
+	if self.role != RoleStandby {
+		m.lastPrimaryContact = now
+		return 0, false
+	}
+
+	var reachable []clusterMemberStatus
+	for _, peer := range peers {
+		if peer != nil {
+			reachable = append(reachable, *peer)
+		}
+	}
+	for _, peer := range reachable {
+		if peer.role == RolePrimary && peer.epoch >= self.epoch {
+			m.lastPrimaryContact = now
+			return 0, false
+		}
+		if peer.role == RoleDetectedBrokenConfig {
+			m.lgr.Warnf("cluster/failover: a peer is in detected_broken_config; not attempting automatic failover.")
+			return 0, false
+		}
+	}
+
+	unreachableFor := now.Sub(m.lastPrimaryContact)
+	if unreachableFor < m.timeout {
+		return 0, false
+	}
+
+	clusterSize := len(peers) + 1
+	if len(reachable)+1 <= clusterSize/2 {
+		m.lgr.Warnf("cluster/failover: no primary has been reachable for %v, but only %d of %d servers are reachable; not attempting automatic failover without a quorum.", unreachableFor, len(reachable)+1, clusterSize)
+		return 0, false
+	}
+
+	// Every vote this server and its peers have cast was at a lower
+	// epoch than the one it campaigns at.
+	epoch := max(self.epoch, self.voteEpoch)
+	for _, peer := range reachable {
+		wins, err := m.isMoreCaughtUp(ctx, self, peer)
+		if err != nil {
+			m.lgr.Warnf("cluster/failover: could not compare replication progress with a peer: %v", err)
+			return 0, false
+		}
+		if !wins {
+			m.lgr.Tracef("cluster/failover: a more caught up standby will be promoted instead of this server.")
+			return 0, false
+		}
+		epoch = max(epoch, peer.epoch, peer.voteEpoch)
+	}
+
+	m.lgr.Warnf("cluster/failover: no primary has been reachable for %v; requesting votes to transition this server to primary at epoch %d.", unreachableFor, epoch+1)
+	m.lastPrimaryContact = now
+	return epoch + 1, true
+}
+
+// campaign requests the votes of the cluster for this server, whose status is
+// |self|, to be promoted to primary at |epoch|. This server votes for itself
+// before it requests the votes of its reachable |peers|. It returns true if a
+// majority of the cluster, counting this server, voted for it.
+func (m *failoverMonitor) campaign(ctx context.Context, self clusterMemberStatus, epoch int, peers []*clusterMemberStatus) bool {
+	granted, err := m.vote(epoch, self.serverID)
+	if err != nil {
+		m.lgr.Errorf("cluster/failover: failed to record the vote of this server at epoch %d: %v", epoch, err)
+		return false
+	}
+	if !granted {
+		m.lgr.Tracef("cluster/failover: this server can not vote for itself at epoch %d.", epoch)
+		return false
+	}
+
+	votes := 1
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, peer := range peers {
+		if peer == nil {
+			continue
+		}
+		remote := peer.remote
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, m.interval)
+			defer cancel()
+			granted, err := m.peerVote(ctx, remote, epoch, self.serverID)
+			if err != nil {
+				m.lgr.Tracef("cluster/failover: could not request the vote of %s: %v", remote, err)
+				return
+			}
+			if granted {
+				mu.Lock()
+				votes += 1
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	clusterSize := len(peers) + 1
+	if votes <= clusterSize/2 {
+		m.lgr.Warnf("cluster/failover: only %d of %d servers voted for this server at epoch %d; not transitioning to primary.", votes, clusterSize, epoch)
+		return false
+	}
+	m.lgr.Warnf("cluster/failover: %d of %d servers voted for this server; transitioning this server to primary at epoch %d.", votes, clusterSize, epoch)
+	return true
+}
+
+// reachedPrimarySince returns true if this server was a standby and could
+// reach a primary at its epoch or higher at |t| or later.
+func (m *failoverMonitor) reachedPrimarySince(t time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.lastPrimarySeen.Before(t)
+}
+
+// shouldDemote decides whether this server, whose status is |self|, should
+// demote itself to standby given the statuses of its |peers|. It returns true
+// if this server is a primary and has not been able to reach a quorum of the
+// cluster, counting itself, for half the failover timeout.
+func (m *failoverMonitor) shouldDemote(self clusterMemberStatus, peers []*clusterMemberStatus, now time.Time) bool {
+	reachable := 1
+	for _, peer := range peers {
+		if peer != nil {
+			reachable++
+		}
+	}
+	clusterSize := len(peers) + 1
+	if self.role != RolePrimary || reachable > clusterSize/2 {
+		m.lastQuorumContact = now
+		return false
+	}
+
+	unreachableFor := now.Sub(m.lastQuorumContact)
+	if unreachableFor < m.timeout/2 {
+		return false
+	}
+
+	m.lgr.Warnf("cluster/failover: only %d of %d servers have been reachable for %v; transitioning this server to standby at epoch %d.", reachable, clusterSize, unreachableFor, self.epoch)
+	m.lastQuorumContact = now
+	return true
+}
+
+// isMoreCaughtUp returns true if |self| should be promoted over |peer|. That
+// is, if |self| has replicated everything |peer| has and |peer| has not
+// replicated everything |self| has, or if each has replicated everything the
+// other has and |self| has the lower server id. A chunk store can still have
+// a root hash after it has moved past it, so two standbys can each have the
+// root hashes of the other; checking the peer's chunk stores too means that
+// |self| and |peer| never both win when they evaluate each other.
+func (m *failoverMonitor) isMoreCaughtUp(ctx context.Context, self, peer clusterMemberStatus) (bool, error) {
+	mineCovers, err := covers(ctx, self, peer, m.hasChunk)
+	if err != nil || !mineCovers {
+		return false, err
+	}
+	theirsCovers, err := covers(ctx, peer, self, func(ctx context.Context, db string, h hash.Hash) (bool, error) {
+		return m.peerHasChunk(ctx, peer.remote, db, h)
+	})
+	if err != nil {
+		return false, err
+	}
+	if !theirsCovers {
+		return true, nil
+	}
+	return self.serverID < peer.serverID, nil
+}
+
+// covers returns true if |a| has replicated everything |b| has: if |a| has
+// every database of |b|, and for each of them, |a| has the root hash of |b|.
+// |hasChunk| reports whether the chunk store of a database of |a| has a chunk.
+func covers(ctx context.Context, a, b clusterMemberStatus, hasChunk func(context.Context, string, hash.Hash) (bool, error)) (bool, error) {
+	for db, theirs := range b.roots {
+		mine, ok := a.roots[db]
+		if !ok {
+			return false, nil
+		}
+		if mine == theirs || theirs.IsEmpty() {
+			continue
+		}
+		has, err := hasChunk(ctx, db, theirs)
+		if err != nil || !has {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (c *Controller) newFailoverMonitor(cfg servercfg.ClusterAutomaticFailoverConfig) *failoverMonitor {
+	return &failoverMonitor{
+		lgr:               c.lgr.WithFields(logrus.Fields{}),
+		interval:          time.Duration(cfg.HeartbeatIntervalMillis()) * time.Millisecond,
+		timeout:           time.Duration(cfg.FailoverTimeoutMillis()) * time.Millisecond,
+		automaticFailover: cfg.Enabled(),
+		freshness:         c.freshness,
+		clients:           c.replicationClients,
+		localStatus:       c.localClusterStatus,
+		hasChunk:          c.hasChunk,
+		peerHasChunk:      c.peerHasChunk,
+		vote:              c.requestVote,
+		peerVote:          c.peerVote,
+		promote: func(epoch int) error {
+			_, err := c.setRoleAndEpoch(string(RolePrimary), epoch, roleTransitionOptions{
+				graceful: false,
+			})
+			return err
+		},
+		demote: func(epoch int) error {
+			_, err := c.setRoleAndEpoch(string(RoleStandby), epoch, roleTransitionOptions{
+				graceful: false,
+			})
+			return err
+		},
+	}
+}
+
+// replicatedDatabases returns the source database of every database which
+// is currently configured for standby replication, keyed by name.
+func (c *Controller) replicatedDatabases() map[string]*doltdb.DoltDB {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ret := make(map[string]*doltdb.DoltDB)
+	for _, h := range c.commithooks {
+		ret[h.dbname] = h.srcDB
+	}
+	return ret
+}
+
+func (c *Controller) localClusterStatus(ctx context.Context) (clusterMemberStatus, error) {
+	c.mu.Lock()
+	ret := clusterMemberStatus{
+		role:      c.role,
+		epoch:     c.epoch,
+		serverID:  c.serverID,
+		voteEpoch: c.voteEpoch,
+		roots:     make(map[string]hash.Hash),
+	}
+	c.mu.Unlock()
+	for name, db := range c.replicatedDatabases() {
+		cs := datas.ChunkStoreFromDatabase(doltdb.HackDatasDatabaseFromDoltDB(db))
+		root, err := cs.Root(ctx)
+		if err != nil {
+			return clusterMemberStatus{}, fmt.Errorf("could not load root of database %s: %w", name, err)
+		}
+		ret.roots[name] = root
+	}
+	return ret, nil
+}
+
+func (c *Controller) getClusterStatusResponse(ctx context.Context) (*replicationapi.GetClusterStatusResponse, error) {
+	status, err := c.localClusterStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return status.toProto(), nil
+}
+
+// peerHasChunk returns true if the database |name| on the standby reached
+// through the standby remote |remote| has the chunk |h|.
+func (c *Controller) peerHasChunk(ctx context.Context, remote, name string, h hash.Hash) (bool, error) {
+	c.mu.Lock()
+	var hook *commithook
+	for _, ch := range c.commithooks {
+		if ch.remotename == remote && ch.dbname == name {
+			hook = ch
+			break
+		}
+	}
+	c.mu.Unlock()
+	if hook == nil {
+		return false, fmt.Errorf("no standby replication of database %s to remote %s", name, remote)
+	}
+	return hook.destHasChunk(ctx, h)
+}
+
+func (c *Controller) hasChunk(ctx context.Context, name string, h hash.Hash) (bool, error) {
+	db, ok := c.replicatedDatabases()[name]
+	if !ok {
+		return false, nil
+	}
+	cs := datas.ChunkStoreFromDatabase(doltdb.HackDatasDatabaseFromDoltDB(db))
+	return cs.Has(ctx, h)
+}
+
+// requestVote records the vote of this server for the candidate |serverID|
+// at |epoch| in an automatic failover and returns true. It returns false if
+// this server is not a standby, is already at |epoch| or a higher epoch, has
+// reached a primary within half the failover timeout, or has voted for a
+// different candidate at |epoch| or a higher epoch. The vote is persisted
+// before it is granted, so that this server can not vote for a second
+// candidate at the same epoch after it restarts.
+func (c *Controller) requestVote(epoch int, serverID string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.role != RoleStandby || epoch <= c.epoch {
+		return false, nil
+	}
+	if epoch < c.voteEpoch || (epoch == c.voteEpoch && serverID != c.voteServerID) {
+		return false, nil
+	}
+	if epoch == c.voteEpoch {
+		return true, nil
+	}
+	if c.failover != nil && c.failover.reachedPrimarySince(time.Now().Add(-c.failover.timeout/2)) {
+		return false, nil
+	}
+	err := c.persistentCfg.SetStrings(map[string]string{
+		persistedVoteEpochKey:    strconv.Itoa(epoch),
+		persistedVoteServerIDKey: serverID,
+	})
+	if err != nil {
+		return false, err
+	}
+	c.voteEpoch = epoch
+	c.voteServerID = serverID
+	return true, nil
+}
+
+// peerVote requests the vote of the peer reached through the standby remote
+// |remote| for the candidate |serverID| at |epoch|.
+func (c *Controller) peerVote(ctx context.Context, remote string, epoch int, serverID string) (bool, error) {
+	for _, client := range c.replicationClients {
+		if client.remote == remote {
+			resp, err := client.client.RequestVote(ctx, &replicationapi.RequestVoteRequest{
+				Epoch:    int64(epoch),
+				ServerId: serverID,
+			})
+			if err != nil {
+				return false, err
+			}
+			return resp.Granted, nil
+		}
+	}
+	return false, fmt.Errorf("no standby remote named %s", remote)
+}
+
+// loadPersistedVote returns the highest epoch at which this server has voted
+// in an automatic failover, and the server id of the candidate it voted for.
+func loadPersistedVote(pCfg config.ReadWriteConfig) (int, string, error) {
+	epoch := pCfg.GetStringOrDefault(persistedVoteEpochKey, "")
+	if epoch == "" {
+		return 0, "", nil
+	}
+	epochi, err := strconv.Atoi(epoch)
+	if err != nil {
+		return 0, "", fmt.Errorf("persisted failover vote epoch %s.%s = %s must be an integer", PersistentConfigPrefix, persistedVoteEpochKey, epoch)
+	}
+	return epochi, pCfg.GetStringOrDefault(persistedVoteServerIDKey, ""), nil
+}
@@ -0,0 +1,344 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/utils/config"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestClusterMemberStatusProtoRoundTrip(t *testing.T) {
+	status := clusterMemberStatus{
+		role:      RoleStandby,
+		epoch:     7,
+		serverID:  "server_a",
+		voteEpoch: 8,
+		roots: map[string]hash.Hash{
+			"db1": hash.Of([]byte("db1")),
+			"db2": hash.Of([]byte("db2")),
+		},
+	}
+	roundTripped, err := clusterMemberStatusFromProto(status.toProto())
+	require.NoError(t, err)
+	assert.Equal(t, status, roundTripped)
+
+	invalid := status.toProto()
+	invalid.Databases[0].RootHash = []byte("short")
+	_, err = clusterMemberStatusFromProto(invalid)
+	assert.Error(t, err)
+}
+
+func TestFailoverMonitorEvaluate(t *testing.T) {
+	older := hash.Of([]byte("older"))
+	newer := hash.Of([]byte("newer"))
+	// The local chunk stores of the monitor under test have |older| and
+	// |newer| for db1, and the chunk stores of its peers have |older|.
+	newMonitor := func(start time.Time) *failoverMonitor {
+		return &failoverMonitor{
+			lgr:      logrus.NewEntry(logrus.New()),
+			interval: time.Second,
+			timeout:  5 * time.Second,
+			hasChunk: func(_ context.Context, db string, h hash.Hash) (bool, error) {
+				return db == "db1" && (h == older || h == newer), nil
+			},
+			peerHasChunk: func(_ context.Context, _, db string, h hash.Hash) (bool, error) {
+				return db == "db1" && h == older, nil
+			},
+			lastPrimaryContact: start,
+		}
+	}
+	status := func(role Role, epoch int, id string, root hash.Hash) *clusterMemberStatus {
+		return &clusterMemberStatus{
+			role:     role,
+			epoch:    epoch,
+			serverID: id,
+			roots:    map[string]hash.Hash{"db1": root},
+		}
+	}
+	start := time.Now()
+	afterTimeout := start.Add(10 * time.Second)
+
+	t.Run("BeforeTimeout", func(t *testing.T) {
+		m := newMonitor(start)
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "b", newer), []*clusterMemberStatus{nil, status(RoleStandby, 2, "c", older)}, start.Add(time.Second))
+		assert.False(t, promote)
+	})
+	t.Run("PromotesMostCaughtUp", func(t *testing.T) {
+		m := newMonitor(start)
+		epoch, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "z", newer), []*clusterMemberStatus{nil, status(RoleStandby, 2, "a", older)}, afterTimeout)
+		assert.True(t, promote)
+		assert.Equal(t, 3, epoch)
+	})
+	t.Run("PromotesAtHighestEpoch", func(t *testing.T) {
+		m := newMonitor(start)
+		epoch, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil, status(RoleStandby, 4, "b", newer)}, afterTimeout)
+		assert.True(t, promote)
+		assert.Equal(t, 5, epoch)
+	})
+	t.Run("CampaignsPastOwnVote", func(t *testing.T) {
+		// A lost campaign leaves the votes of this server and its peers
+		// at the epoch it campaigned at.
+		m := newMonitor(start)
+		self := status(RoleStandby, 2, "a", newer)
+		self.voteEpoch = 6
+		epoch, promote := m.evaluate(context.Background(), *self, []*clusterMemberStatus{nil, status(RoleStandby, 4, "b", newer)}, afterTimeout)
+		assert.True(t, promote)
+		assert.Equal(t, 7, epoch)
+		m = newMonitor(start)
+		peer := status(RoleStandby, 2, "b", newer)
+		peer.voteEpoch = 8
+		epoch, promote = m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil, peer}, afterTimeout)
+		assert.True(t, promote)
+		assert.Equal(t, 9, epoch)
+	})
+	t.Run("DoesNotPromoteWhenBehind", func(t *testing.T) {
+		m := newMonitor(start)
+		other := hash.Of([]byte("other"))
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "a", older), []*clusterMemberStatus{nil, status(RoleStandby, 2, "b", other)}, afterTimeout)
+		assert.False(t, promote)
+	})
+	t.Run("TieBreaksOnServerID", func(t *testing.T) {
+		m := newMonitor(start)
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "b", newer), []*clusterMemberStatus{nil, status(RoleStandby, 2, "a", newer)}, afterTimeout)
+		assert.False(t, promote)
+		m = newMonitor(start)
+		_, promote = m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil, status(RoleStandby, 2, "b", newer)}, afterTimeout)
+		assert.True(t, promote)
+	})
+	t.Run("MutuallyAheadTieBreaksOnServerID", func(t *testing.T) {
+		// Each standby has the root hash of the other in its chunk store
+		peerHasBoth := func(_ context.Context, _, db string, h hash.Hash) (bool, error) {
+			return db == "db1" && (h == older || h == newer), nil
+		}
+		m := newMonitor(start)
+		m.peerHasChunk = peerHasBoth
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "b", newer), []*clusterMemberStatus{nil, status(RoleStandby, 2, "a", older)}, afterTimeout)
+		assert.False(t, promote)
+		m = newMonitor(start)
+		m.peerHasChunk = peerHasBoth
+		_, promote = m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil, status(RoleStandby, 2, "b", older)}, afterTimeout)
+		assert.True(t, promote)
+	})
+	t.Run("UnreachablePeerChunkStorePreventsFailover", func(t *testing.T) {
+		m := newMonitor(start)
+		m.peerHasChunk = func(context.Context, string, string, hash.Hash) (bool, error) {
+			return false, errors.New("unavailable")
+		}
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil, status(RoleStandby, 2, "b", older)}, afterTimeout)
+		assert.False(t, promote)
+	})
+	t.Run("RequiresQuorum", func(t *testing.T) {
+		m := newMonitor(start)
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil, nil}, afterTimeout)
+		assert.False(t, promote)
+		m = newMonitor(start)
+		_, promote = m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil}, afterTimeout)
+		assert.False(t, promote)
+	})
+	t.Run("PrimaryContactResetsTimeout", func(t *testing.T) {
+		m := newMonitor(start)
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{status(RolePrimary, 2, "p", newer), status(RoleStandby, 2, "b", older)}, afterTimeout)
+		assert.False(t, promote)
+		assert.Equal(t, afterTimeout, m.lastPrimaryContact)
+		_, promote = m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil, status(RoleStandby, 2, "b", older)}, afterTimeout.Add(time.Second))
+		assert.False(t, promote)
+	})
+	t.Run("StalePrimaryDoesNotResetTimeout", func(t *testing.T) {
+		m := newMonitor(start)
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 3, "a", newer), []*clusterMemberStatus{status(RolePrimary, 2, "p", older), status(RoleStandby, 3, "b", older)}, afterTimeout)
+		assert.True(t, promote)
+	})
+	t.Run("BrokenConfigPreventsFailover", func(t *testing.T) {
+		m := newMonitor(start)
+		_, promote := m.evaluate(context.Background(), *status(RoleStandby, 2, "a", newer), []*clusterMemberStatus{nil, status(RoleDetectedBrokenConfig, 2, "b", older)}, afterTimeout)
+		assert.False(t, promote)
+	})
+	t.Run("PrimaryNeverPromotes", func(t *testing.T) {
+		m := newMonitor(start)
+		_, promote := m.evaluate(context.Background(), *status(RolePrimary, 2, "a", newer), []*clusterMemberStatus{nil, nil}, afterTimeout)
+		assert.False(t, promote)
+		assert.Equal(t, afterTimeout, m.lastPrimaryContact)
+	})
+}
+
+func TestFailoverMonitorCampaign(t *testing.T) {
+	self := clusterMemberStatus{role: RoleStandby, epoch: 2, serverID: "a"}
+	peer := func(remote string) *clusterMemberStatus {
+		return &clusterMemberStatus{role: RoleStandby, epoch: 2, serverID: remote, remote: remote}
+	}
+	// |votes| are the servers which vote for the candidate, by remote.
+	// The candidate itself is "self".
+	newMonitor := func(votes ...string) *failoverMonitor {
+		granted := make(map[string]bool)
+		for _, v := range votes {
+			granted[v] = true
+		}
+		return &failoverMonitor{
+			lgr:      logrus.NewEntry(logrus.New()),
+			interval: time.Second,
+			timeout:  5 * time.Second,
+			vote: func(epoch int, serverID string) (bool, error) {
+				assert.Equal(t, 3, epoch)
+				assert.Equal(t, "a", serverID)
+				return granted["self"], nil
+			},
+			peerVote: func(_ context.Context, remote string, epoch int, serverID string) (bool, error) {
+				assert.Equal(t, 3, epoch)
+				assert.Equal(t, "a", serverID)
+				if remote == "down" {
+					return false, errors.New("unavailable")
+				}
+				return granted[remote], nil
+			},
+		}
+	}
+	peers := []*clusterMemberStatus{peer("b"), peer("c"), nil, peer("down")}
+
+	t.Run("Majority", func(t *testing.T) {
+		assert.True(t, newMonitor("self", "b", "c").campaign(context.Background(), self, 3, peers))
+	})
+	t.Run("SplitVote", func(t *testing.T) {
+		// Two candidates which each reach a different quorum can not both win.
+		assert.False(t, newMonitor("self", "b").campaign(context.Background(), self, 3, peers))
+	})
+	t.Run("OwnVoteRefused", func(t *testing.T) {
+		m := newMonitor("b", "c")
+		m.peerVote = func(context.Context, string, int, string) (bool, error) {
+			t.Fatal("requested the votes of peers without voting for itself")
+			return false, nil
+		}
+		assert.False(t, m.campaign(context.Background(), self, 3, peers))
+	})
+	t.Run("UnreachablePeersDoNotVote", func(t *testing.T) {
+		assert.False(t, newMonitor("self", "b", "down").campaign(context.Background(), self, 3, []*clusterMemberStatus{peer("b"), nil, nil, peer("down")}))
+	})
+}
+
+func TestControllerRequestVote(t *testing.T) {
+	newController := func(pCfg config.ReadWriteConfig) *Controller {
+		voteEpoch, voteServerID, err := loadPersistedVote(pCfg)
+		require.NoError(t, err)
+		return &Controller{
+			persistentCfg: pCfg,
+			role:          RoleStandby,
+			epoch:         2,
+			voteEpoch:     voteEpoch,
+			voteServerID:  voteServerID,
+		}
+	}
+
+	t.Run("OneCandidatePerEpoch", func(t *testing.T) {
+		pCfg := config.NewEmptyMapConfig()
+		c := newController(pCfg)
+		granted, err := c.requestVote(3, "a")
+		require.NoError(t, err)
+		assert.True(t, granted)
+		granted, err = c.requestVote(3, "a")
+		require.NoError(t, err)
+		assert.True(t, granted)
+		granted, err = c.requestVote(3, "b")
+		require.NoError(t, err)
+		assert.False(t, granted)
+		granted, err = c.requestVote(4, "b")
+		require.NoError(t, err)
+		assert.True(t, granted)
+		granted, err = c.requestVote(3, "a")
+		require.NoError(t, err)
+		assert.False(t, granted)
+		assert.Equal(t, 4, c.voteEpoch)
+	})
+	t.Run("VotePersists", func(t *testing.T) {
+		pCfg := config.NewEmptyMapConfig()
+		granted, err := newController(pCfg).requestVote(3, "a")
+		require.NoError(t, err)
+		require.True(t, granted)
+		// After a restart, the server still can not vote for a second
+		// candidate at the same epoch.
+		c := newController(pCfg)
+		granted, err = c.requestVote(3, "b")
+		require.NoError(t, err)
+		assert.False(t, granted)
+	})
+	t.Run("NotAboveOwnEpoch", func(t *testing.T) {
+		c := newController(config.NewEmptyMapConfig())
+		granted, err := c.requestVote(2, "a")
+		require.NoError(t, err)
+		assert.False(t, granted)
+	})
+	t.Run("Primary", func(t *testing.T) {
+		c := newController(config.NewEmptyMapConfig())
+		c.role = RolePrimary
+		granted, err := c.requestVote(3, "a")
+		require.NoError(t, err)
+		assert.False(t, granted)
+	})
+	t.Run("PrimaryReachable", func(t *testing.T) {
+		c := newController(config.NewEmptyMapConfig())
+		c.failover = &failoverMonitor{
+			timeout:         4 * time.Second,
+			lastPrimarySeen: time.Now().Add(-time.Second),
+		}
+		granted, err := c.requestVote(3, "a")
+		require.NoError(t, err)
+		assert.False(t, granted)
+		c.failover.lastPrimarySeen = time.Now().Add(-3 * time.Second)
+		granted, err = c.requestVote(3, "a")
+		require.NoError(t, err)
+		assert.True(t, granted)
+	})
+}
+
+func TestFailoverMonitorShouldDemote(t *testing.T) {
+	start := time.Now()
+	newMonitor := func() *failoverMonitor {
+		return &failoverMonitor{
+			lgr:               logrus.NewEntry(logrus.New()),
+			interval:          time.Second,
+			timeout:           6 * time.Second,
+			lastQuorumContact: start,
+		}
+	}
+	primary := clusterMemberStatus{role: RolePrimary, epoch: 2, serverID: "a"}
+	standby := &clusterMemberStatus{role: RoleStandby, epoch: 2, serverID: "b"}
+
+	t.Run("QuorumReachable", func(t *testing.T) {
+		m := newMonitor()
+		assert.False(t, m.shouldDemote(primary, []*clusterMemberStatus{nil, standby}, start.Add(10*time.Second)))
+		assert.Equal(t, start.Add(10*time.Second), m.lastQuorumContact)
+	})
+	t.Run("BeforeTimeout", func(t *testing.T) {
+		m := newMonitor()
+		assert.False(t, m.shouldDemote(primary, []*clusterMemberStatus{nil, nil}, start.Add(2*time.Second)))
+	})
+	t.Run("QuorumUnreachable", func(t *testing.T) {
+		// A primary demotes itself before a standby on the other side of a partition can promote itself
+		m := newMonitor()
+		assert.True(t, m.shouldDemote(primary, []*clusterMemberStatus{nil, nil}, start.Add(3*time.Second)))
+		assert.True(t, m.shouldDemote(primary, []*clusterMemberStatus{nil}, start.Add(6*time.Second)))
+	})
+	t.Run("StandbyNeverDemotes", func(t *testing.T) {
+		m := newMonitor()
+		assert.False(t, m.shouldDemote(*standby, []*clusterMemberStatus{nil, nil}, start.Add(10*time.Second)))
+	})
+}
@@ -36,11 +36,21 @@ const clusterRoleEpochHeader = "x-dolt-cluster-role-epoch"
 
 var writeEndpoints map[string]bool
 
+// Endpoints which cluster members call on each other regardless of their
+// roles. Requests to these endpoints are not failed because of the role of
+// the client or the server, but they still carry and process the role and
+// epoch headers, so they can still cause role transitions.
+var anyRoleEndpoints map[string]bool
+
 func init() {
 	writeEndpoints = make(map[string]bool)
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/Commit"] = true
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/AddTableFiles"] = true
 	writeEndpoints["/dolt.services.remotesapi.v1alpha1.ChunkStoreService/GetUploadLocations"] = true
+
+	anyRoleEndpoints = make(map[string]bool)
+	anyRoleEndpoints["/dolt.services.replicationapi.v1alpha1.ReplicationService/GetClusterStatus"] = true
+	anyRoleEndpoints["/dolt.services.replicationapi.v1alpha1.ReplicationService/RequestVote"] = true
 }
 
 func isLikelyServerResponse(err error) bool {
@@ -96,11 +106,13 @@ func (ci *clientinterceptor) Stream() grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 		role, epoch := ci.getRole()
 		ci.lgr.Tracef("cluster: clientinterceptor: processing request to %s, role %s", method, string(role))
-		if role == RoleStandby {
-			return nil, status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is a standby and is not currently replicating to its standby")
-		}
-		if role == RoleDetectedBrokenConfig {
-			return nil, status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is in detected_broken_config and is not currently replicating to its standby")
+		if !anyRoleEndpoints[method] {
+			if role == RoleStandby {
+				return nil, status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is a standby and is not currently replicating to its standby")
+			}
+			if role == RoleDetectedBrokenConfig {
+				return nil, status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is in detected_broken_config and is not currently replicating to its standby")
+			}
 		}
 		ctx = metadata.AppendToOutgoingContext(ctx, clusterRoleHeader, string(role), clusterRoleEpochHeader, strconv.Itoa(epoch))
 		var header metadata.MD
@@ -114,11 +126,13 @@ func (ci *clientinterceptor) Unary() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		role, epoch := ci.getRole()
 		ci.lgr.Tracef("cluster: clientinterceptor: processing request to %s, role %s", method, string(role))
-		if role == RoleStandby {
-			return status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is a standby and is not currently replicating to its standby")
-		}
-		if role == RoleDetectedBrokenConfig {
-			return status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is in detected_broken_config and is not currently replicating to its standby")
+		if !anyRoleEndpoints[method] {
+			if role == RoleStandby {
+				return status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is a standby and is not currently replicating to its standby")
+			}
+			if role == RoleDetectedBrokenConfig {
+				return status.Error(codes.FailedPrecondition, "cluster: clientinterceptor: this server is in detected_broken_config and is not currently replicating to its standby")
+			}
 		}
 		ctx = metadata.AppendToOutgoingContext(ctx, clusterRoleHeader, string(role), clusterRoleEpochHeader, strconv.Itoa(epoch))
 		var header metadata.MD
@@ -217,6 +231,9 @@ func (si *serverinterceptor) Stream() grpc.StreamServerInterceptor {
 			if err := grpc.SetHeader(ss.Context(), metadata.Pairs(clusterRoleHeader, string(role), clusterRoleEpochHeader, strconv.Itoa(epoch))); err != nil {
 				return err
 			}
+			if anyRoleEndpoints[info.FullMethod] {
+				return handler(srv, ss)
+			}
 			if role == RolePrimary {
 				// As a primary, we do not accept replication requests.
 				return status.Error(codes.FailedPrecondition, "this server is a primary and is not currently accepting replication")
@@ -249,6 +266,9 @@ func (si *serverinterceptor) Unary() grpc.UnaryServerInterceptor {
 			if err := grpc.SetHeader(ctx, metadata.Pairs(clusterRoleHeader, string(role), clusterRoleEpochHeader, strconv.Itoa(epoch))); err != nil {
 				return nil, err
 			}
+			if anyRoleEndpoints[info.FullMethod] {
+				return handler(ctx, req)
+			}
 			if role == RolePrimary {
 				// As a primary, we do not accept replication requests.
 				return nil, status.Error(codes.FailedPrecondition, "this server is a primary and is not currently accepting replication")
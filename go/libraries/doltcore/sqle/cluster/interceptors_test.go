@@ -303,3 +303,56 @@ func TestClientInterceptorAsStandbyDoesNotSendRequest(t *testing.T) {
 		assert.Equal(t, "10", srv.md.Get(clusterRoleEpochHeader)[0])
 	}
 }
+
+// Used by tests which need a gRPC health method to be treated as an
+// endpoint that is served regardless of role.
+func withAnyRoleHealthCheck(t *testing.T) {
+	const method = "/grpc.health.v1.Health/Check"
+	anyRoleEndpoints[method] = true
+	t.Cleanup(func() {
+		delete(anyRoleEndpoints, method)
+	})
+}
+
+func TestServerInterceptorAsPrimaryServesAnyRoleEndpoints(t *testing.T) {
+	withAnyRoleHealthCheck(t)
+	var si serverinterceptor
+	si.setRole(RolePrimary, 10)
+	si.roleSetter = noopSetRole
+	si.lgr = lgr
+	si.keyProvider = kp
+	srv := withClient(t, func(t *testing.T, client grpc_health_v1.HealthClient) {
+		var md metadata.MD
+		_, err := client.Check(outboundCtx(RoleStandby, 10), &grpc_health_v1.HealthCheckRequest{}, grpc.Header(&md))
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		if assert.Len(t, md.Get(clusterRoleHeader), 1) {
+			assert.Equal(t, "primary", md.Get(clusterRoleHeader)[0])
+		}
+		if assert.Len(t, md.Get(clusterRoleEpochHeader), 1) {
+			assert.Equal(t, "10", md.Get(clusterRoleEpochHeader)[0])
+		}
+	}, si.Options(), nil)
+	if assert.Len(t, srv.md.Get(clusterRoleHeader), 1) {
+		assert.Equal(t, "standby", srv.md.Get(clusterRoleHeader)[0])
+	}
+}
+
+func TestClientInterceptorAsStandbySendsAnyRoleEndpoints(t *testing.T) {
+	withAnyRoleHealthCheck(t)
+	var ci clientinterceptor
+	ci.setRole(RoleStandby, 10)
+	ci.roleSetter = noopSetRole
+	ci.lgr = lgr
+	srv := withClient(t, func(t *testing.T, client grpc_health_v1.HealthClient) {
+		_, err := client.Check(outboundCtx(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		_, err = client.Watch(outboundCtx(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	}, nil, ci.Options())
+	if assert.Len(t, srv.md.Get(clusterRoleHeader), 1) {
+		assert.Equal(t, "standby", srv.md.Get(clusterRoleHeader)[0])
+	}
+	if assert.Len(t, srv.md.Get(clusterRoleEpochHeader), 1) {
+		assert.Equal(t, "10", srv.md.Get(clusterRoleEpochHeader)[0])
+	}
+}
@@ -44,6 +44,9 @@ type replicationServiceServer struct {
 	branchControlFilesys filesys.Filesys
 
 	dropDatabase func(*sql.Context, string) error
+
+	clusterStatus func(context.Context) (*replicationapi.GetClusterStatusResponse, error)
+	requestVote   func(epoch int, serverID string) (bool, error)
 }
 
 func (s *replicationServiceServer) UpdateUsersAndGrants(ctx context.Context, req *replicationapi.UpdateUsersAndGrantsRequest) (*replicationapi.UpdateUsersAndGrantsResponse, error) {
@@ -99,3 +102,21 @@ func (s *replicationServiceServer) DropDatabase(ctx context.Context, req *replic
 	}
 	return &replicationapi.DropDatabaseResponse{}, nil
 }
+
+func (s *replicationServiceServer) GetClusterStatus(ctx context.Context, req *replicationapi.GetClusterStatusRequest) (*replicationapi.GetClusterStatusResponse, error) {
+	if s.clusterStatus == nil {
+		return nil, status.Error(codes.Unimplemented, "unimplemented")
+	}
+	return s.clusterStatus(ctx)
+}
+
+func (s *replicationServiceServer) RequestVote(ctx context.Context, req *replicationapi.RequestVoteRequest) (*replicationapi.RequestVoteResponse, error) {
+	if s.requestVote == nil {
+		return nil, status.Error(codes.Unimplemented, "unimplemented")
+	}
+	granted, err := s.requestVote(int(req.Epoch), req.ServerId)
+	if err != nil {
+		return nil, err
+	}
+	return &replicationapi.RequestVoteResponse{Granted: granted}, nil
+}
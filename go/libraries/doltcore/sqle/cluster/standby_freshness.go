@@ -0,0 +1,172 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// The maximum number of root hashes of the primary we remember for each
+// database while we wait for them to be replicated to us.
+const maxPendingPrimaryRoots = 64
+
+// standbyFreshness tracks how up to date the databases on a standby are.
+//
+// The failoverMonitor periodically fetches the root hashes of the primary's
+// databases. Each time it does, it calls |observe|, which records those
+// roots along with the time at which they were fetched. Once a standby has
+// a root of the primary, it has everything the primary had at the time that
+// root was fetched. The lag of a database is the time since the most recent
+// such fetch. Because it is only updated on every heartbeat, the lag is
+// never lower than the heartbeat interval, and it keeps growing while the
+// primary is unreachable.
+type standbyFreshness struct {
+	mu sync.Mutex
+	// For each database, keyed by its lower-cased name, the time at which
+	// the most recent root of the primary which this server has was
+	// fetched.
+	caughtUpAt map[string]time.Time
+	// For each database, keyed by its lower-cased name, the roots of the
+	// primary which were fetched since the most recent one which this
+	// server has, including that one, oldest first.
+	pending map[string][]observedRoot
+}
+
+type observedRoot struct {
+	root hash.Hash
+	at   time.Time
+}
+
+func newStandbyFreshness() *standbyFreshness {
+	return &standbyFreshness{
+		caughtUpAt: make(map[string]time.Time),
+		pending:    make(map[string][]observedRoot),
+	}
+}
+
+// observe records that, as of |at|, the primary reported the roots in
+// |primary| and this server had the roots in |self|. |hasChunk| reports
+// whether a root of the primary which is not the current root of this
+// server has been replicated to it.
+func (f *standbyFreshness) observe(ctx context.Context, self, primary clusterMemberStatus, at time.Time, hasChunk func(context.Context, string, hash.Hash) (bool, error)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for db, root := range primary.roots {
+		key := strings.ToLower(db)
+		pending := f.pending[key]
+		if len(pending) > 0 && pending[len(pending)-1].root == root {
+			// The primary has not changed since we last saw it, so
+			// having this root is up to date as of now.
+			pending[len(pending)-1].at = at
+		} else {
+			pending = append(pending, observedRoot{root, at})
+		}
+		if len(pending) > maxPendingPrimaryRoots {
+			pending = pending[len(pending)-maxPendingPrimaryRoots:]
+		}
+
+		mine, ok := self.roots[db]
+		if !ok {
+			f.pending[key] = pending
+			continue
+		}
+		// Find the most recent root of the primary which we have.
+		for i := len(pending) - 1; i >= 0; i-- {
+			has := pending[i].root == mine
+			if !has {
+				var err error
+				has, err = hasChunk(ctx, db, pending[i].root)
+				if err != nil {
+					f.pending[key] = pending
+					return err
+				}
+			}
+			if has {
+				if pending[i].at.After(f.caughtUpAt[key]) {
+					f.caughtUpAt[key] = pending[i].at
+				}
+				// Keep the root we have, so that we can keep
+				// advancing its time while the primary is idle.
+				pending = pending[i:]
+				break
+			}
+		}
+		f.pending[key] = pending
+	}
+	return nil
+}
+
+// lag returns how far behind the primary the database |db| is as of |now|.
+// Returns false if it is not known to have replicated anything from the
+// primary.
+func (f *standbyFreshness) lag(db string, now time.Time) (time.Duration, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	at, ok := f.caughtUpAt[strings.ToLower(db)]
+	if !ok {
+		return 0, false
+	}
+	return now.Sub(at), true
+}
+
+// CheckStandbyReadLag is installed as the standby read check of the database
+// provider. If the session has set @@dolt_max_replica_lag_ms, it returns an
+// error when this server is a standby and the replication lag of the
+// database |dbName| is higher than it, or is not known.
+func (c *Controller) CheckStandbyReadLag(ctx *sql.Context, dbName string) error {
+	if c == nil {
+		return nil
+	}
+	maxLag, err := ctx.GetSessionVariable(ctx, dsess.DoltMaxReplicaLagMs)
+	if err != nil {
+		return err
+	}
+	maxLagMs, ok := maxLag.(int64)
+	if !ok || maxLagMs <= 0 {
+		return nil
+	}
+	if role, _ := c.roleAndEpoch(); role == RolePrimary {
+		return nil
+	}
+	if !c.isReplicatedDatabase(dbName) {
+		return nil
+	}
+	lag, ok := c.freshness.lag(dbName, time.Now())
+	if !ok {
+		return fmt.Errorf("cannot read database %s on this standby: the replication lag of the database is not known and @@%s is %d; the standby may not have been able to reach the primary", dbName, dsess.DoltMaxReplicaLagMs, maxLagMs)
+	}
+	if lag > time.Duration(maxLagMs)*time.Millisecond {
+		return fmt.Errorf("cannot read database %s on this standby: the database is %d ms behind the primary, which exceeds @@%s = %d", dbName, lag.Milliseconds(), dsess.DoltMaxReplicaLagMs, maxLagMs)
+	}
+	return nil
+}
+
+func (c *Controller) isReplicatedDatabase(dbName string) bool {
+	for name := range c.replicatedDatabases() {
+		if strings.EqualFold(name, dbName) {
+			return true
+		}
+	}
+	return false
+}
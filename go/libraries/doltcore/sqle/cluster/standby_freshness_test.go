@@ -0,0 +1,80 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestStandbyFreshness(t *testing.T) {
+	ctx := context.Background()
+	roots := func(root hash.Hash) clusterMemberStatus {
+		return clusterMemberStatus{roots: map[string]hash.Hash{"db": root}}
+	}
+	first := hash.Of([]byte("first"))
+	second := hash.Of([]byte("second"))
+	third := hash.Of([]byte("third"))
+	// The chunks this standby has.
+	has := map[hash.Hash]bool{first: true}
+	hasChunk := func(_ context.Context, _ string, h hash.Hash) (bool, error) {
+		return has[h], nil
+	}
+	start := time.Now()
+	at := func(secs int) time.Time {
+		return start.Add(time.Duration(secs) * time.Second)
+	}
+
+	f := newStandbyFreshness()
+	_, ok := f.lag("db", at(0))
+	assert.False(t, ok)
+
+	// The primary is at |second| and we are at |first|.
+	require.NoError(t, f.observe(ctx, roots(first), roots(second), at(0), hasChunk))
+	_, ok = f.lag("db", at(0))
+	assert.False(t, ok)
+
+	// The primary is still at |second| and we have caught up.
+	has[second] = true
+	require.NoError(t, f.observe(ctx, roots(second), roots(second), at(1), hasChunk))
+	lag, ok := f.lag("DB", at(3))
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Second, lag)
+
+	// The primary moves to |third| and we have not replicated it yet.
+	require.NoError(t, f.observe(ctx, roots(second), roots(third), at(2), hasChunk))
+	lag, ok = f.lag("db", at(4))
+	require.True(t, ok)
+	assert.Equal(t, 3*time.Second, lag)
+
+	// The chunks of |third| arrive before the next heartbeat, while the
+	// primary keeps writing. We are up to date as of when we first saw
+	// |third|.
+	has[third] = true
+	require.NoError(t, f.observe(ctx, roots(second), roots(hash.Of([]byte("fourth"))), at(5), hasChunk))
+	lag, ok = f.lag("db", at(6))
+	require.True(t, ok)
+	assert.Equal(t, 4*time.Second, lag)
+
+	// Unknown databases have no lag.
+	_, ok = f.lag("other", at(6))
+	assert.False(t, ok)
+}
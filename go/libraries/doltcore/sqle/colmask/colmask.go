@@ -0,0 +1,233 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package colmask defines the rules which mask the values of columns in the
+// rows read by users, and the masks which they apply to the values.
+//
+// A rule applies to the users and roles it names, or to every user if it
+// names none. When several rules of a column apply to a user, the most
+// restrictive of their masks is applied.
+package colmask
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Function is the kind of a mask.
+type Function string
+
+const (
+	// Full replaces strings with FullMask, and other values with the zero value of their type.
+	Full Function = "full"
+	// Partial keeps the first and last characters of strings, and replaces the others with a padding.
+	Partial Function = "partial"
+	// Hash replaces strings with the hex encoded SHA-256 hash of their value.
+	Hash Function = "hash"
+	// Null replaces values with NULL.
+	Null Function = "null"
+)
+
+// FullMask is the value of the strings masked by Full masks.
+const FullMask = "XXXX"
+
+// DefaultPadding is the padding of Partial masks which do not specify one.
+const DefaultPadding = "XXXX"
+
+// restrictiveness orders the functions of masks from the least to the most restrictive.
+var restrictiveness = map[Function]int{
+	Partial: 0,
+	Hash:    1,
+	Full:    2,
+	Null:    3,
+}
+
+// Mask is a function which masks the values of a column.
+type Mask struct {
+	Function Function `json:"function"`
+	// Prefix, Padding and Suffix are the parameters of Partial masks.
+	Prefix  int    `json:"prefix,omitempty"`
+	Padding string `json:"padding,omitempty"`
+	Suffix  int    `json:"suffix,omitempty"`
+}
+
+// ParseMask parses the mask |spec|, which is one of "full", "null", "hash", "partial(prefix, suffix)" or
+// "partial(prefix, 'padding', suffix)".
+func ParseMask(spec string) (Mask, error) {
+	spec = strings.TrimSpace(spec)
+	name, args, hasArgs := strings.Cut(spec, "(")
+	fn := Function(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := restrictiveness[fn]; !ok {
+		return Mask{}, fmt.Errorf("unknown mask: %s", spec)
+	}
+	if fn != Partial {
+		if hasArgs {
+			return Mask{}, fmt.Errorf("mask %s does not take arguments: %s", fn, spec)
+		}
+		return Mask{Function: fn}, nil
+	}
+
+	if !hasArgs || !strings.HasSuffix(args, ")") {
+		return Mask{}, fmt.Errorf("mask partial requires a prefix and a suffix length: %s", spec)
+	}
+	params := strings.Split(strings.TrimSuffix(args, ")"), ",")
+	for i := range params {
+		params[i] = strings.TrimSpace(params[i])
+	}
+	m := Mask{Function: Partial, Padding: DefaultPadding}
+	switch len(params) {
+	case 2:
+	case 3:
+		// Paddings are quoted strings without quotes, commas or parentheses
+		padding := params[1]
+		if len(padding) < 2 || padding[0] != '\'' || padding[len(padding)-1] != '\'' ||
+			strings.ContainsAny(padding[1:len(padding)-1], "'(),") {
+			return Mask{}, fmt.Errorf("invalid padding of mask partial: %s", params[1])
+		}
+		m.Padding = padding[1 : len(padding)-1]
+		params = []string{params[0], params[2]}
+	default:
+		return Mask{}, fmt.Errorf("mask partial requires a prefix and a suffix length: %s", spec)
+	}
+	var err error
+	if m.Prefix, err = strconv.Atoi(params[0]); err != nil || m.Prefix < 0 {
+		return Mask{}, fmt.Errorf("invalid prefix length of mask partial: %s", params[0])
+	}
+	if m.Suffix, err = strconv.Atoi(params[1]); err != nil || m.Suffix < 0 {
+		return Mask{}, fmt.Errorf("invalid suffix length of mask partial: %s", params[1])
+	}
+	return m, nil
+}
+
+// String returns the spec of |m|, which ParseMask parses.
+func (m Mask) String() string {
+	if m.Function != Partial {
+		return string(m.Function)
+	}
+	return fmt.Sprintf("partial(%d, '%s', %d)", m.Prefix, m.Padding, m.Suffix)
+}
+
+// StringOnly returns whether |m| only applies to strings, which other values cannot be masked with.
+func (m Mask) StringOnly() bool {
+	return m.Function == Partial || m.Function == Hash
+}
+
+// MaskString returns the masked value of the string |s|. Null masks return the empty string.
+func (m Mask) MaskString(s string) string {
+	switch m.Function {
+	case Partial:
+		runes := []rune(s)
+		if m.Prefix+m.Suffix >= len(runes) {
+			// Strings too short to hide any characters are masked entirely
+			return m.Padding
+		}
+		return string(runes[:m.Prefix]) + m.Padding + string(runes[len(runes)-m.Suffix:])
+	case Hash:
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	case Null:
+		return ""
+	default:
+		return FullMask
+	}
+}
+
+// Rule is a masking rule, which masks the values of a column of a table for the users and roles it applies to.
+type Rule struct {
+	Name   string `json:"-"`
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Mask
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Marshal returns the encoding of |r| which is stored in the dolt_schemas table.
+func (r Rule) Marshal() (string, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Unmarshal decodes the rule |name| from |fragment|, which was returned by Marshal.
+func Unmarshal(name, fragment string) (Rule, error) {
+	var r Rule
+	if err := json.Unmarshal([]byte(fragment), &r); err != nil {
+		return Rule{}, fmt.Errorf("invalid mask %s: %w", name, err)
+	}
+	r.Name = name
+	return r, nil
+}
+
+// appliesTo returns whether |r| masks the values of the columns of |table| for the user with the names |roles|.
+func (r Rule) appliesTo(table string, roles []string) bool {
+	if !strings.EqualFold(r.Table, table) {
+		return false
+	}
+	if len(r.Roles) == 0 {
+		return true
+	}
+	for _, role := range r.Roles {
+		for _, name := range roles {
+			if role == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasMasks returns whether any of the |rules| masks the values of the columns of |table|.
+func HasMasks(rules []Rule, table string) bool {
+	for _, r := range rules {
+		if strings.EqualFold(r.Table, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRoles returns whether any of the |rules| of |table| only applies to some users.
+func HasRoles(rules []Rule, table string) bool {
+	for _, r := range rules {
+		if strings.EqualFold(r.Table, table) && len(r.Roles) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Masks returns the masks of the columns of |table|, by lowercase column name, which |rules| apply to the user whose
+// name, and the names of whose roles, are |roles|.
+func Masks(rules []Rule, table string, roles []string) map[string]Mask {
+	var masks map[string]Mask
+	for _, r := range rules {
+		if !r.appliesTo(table, roles) {
+			continue
+		}
+		if masks == nil {
+			masks = make(map[string]Mask)
+		}
+		col := strings.ToLower(r.Column)
+		if m, ok := masks[col]; !ok || restrictiveness[r.Function] > restrictiveness[m.Function] {
+			masks[col] = r.Mask
+		}
+	}
+	return masks
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colmask
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMask(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Mask
+		err      bool
+	}{
+		{spec: "full", expected: Mask{Function: Full}},
+		{spec: " NULL ", expected: Mask{Function: Null}},
+		{spec: "hash", expected: Mask{Function: Hash}},
+		{spec: "partial(2, 4)", expected: Mask{Function: Partial, Prefix: 2, Padding: DefaultPadding, Suffix: 4}},
+		{spec: "Partial(0,'***',3)", expected: Mask{Function: Partial, Prefix: 0, Padding: "***", Suffix: 3}},
+		{spec: "partial(1, '', 1)", expected: Mask{Function: Partial, Prefix: 1, Suffix: 1}},
+		{spec: "hash(1)", err: true},
+		{spec: "partial", err: true},
+		{spec: "partial(1)", err: true},
+		{spec: "partial(-1, 2)", err: true},
+		{spec: "partial(1, ***, 2)", err: true},
+		{spec: "partial(1, 'a,b', 2)", err: true},
+		{spec: "shuffle", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			m, err := ParseMask(test.spec)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, m)
+
+			parsed, err := ParseMask(m.String())
+			require.NoError(t, err)
+			assert.Equal(t, m, parsed)
+		})
+	}
+}
+
+func TestMaskString(t *testing.T) {
+	tests := []struct {
+		mask     Mask
+		s        string
+		expected string
+	}{
+		{mask: Mask{Function: Full}, s: "alice@example.com", expected: FullMask},
+		{mask: Mask{Function: Null}, s: "alice@example.com", expected: ""},
+		{mask: Mask{Function: Hash}, s: "abc", expected: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{mask: Mask{Function: Partial, Prefix: 2, Padding: "***", Suffix: 4}, s: "alice@example.com", expected: "al***.com"},
+		{mask: Mask{Function: Partial, Prefix: 0, Padding: "XXXX", Suffix: 4}, s: "555-123-4567", expected: "XXXX4567"},
+		{mask: Mask{Function: Partial, Prefix: 1, Padding: "-", Suffix: 1}, s: "héllo", expected: "h-o"},
+		{mask: Mask{Function: Partial, Prefix: 2, Padding: "XXXX", Suffix: 2}, s: "abcd", expected: "XXXX"},
+	}
+	for _, test := range tests {
+		t.Run(test.mask.String()+" "+test.s, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.mask.MaskString(test.s))
+		})
+	}
+}
+
+func TestRuleMarshal(t *testing.T) {
+	r := Rule{Name: "email", Table: "customers", Column: "email", Mask: Mask{Function: Partial, Prefix: 1, Padding: "*", Suffix: 2}, Roles: []string{"support"}}
+	fragment, err := r.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, `{"table":"customers","column":"email","function":"partial","prefix":1,"padding":"*","suffix":2,"roles":["support"]}`, fragment)
+
+	decoded, err := Unmarshal("email", fragment)
+	require.NoError(t, err)
+	assert.Equal(t, r, decoded)
+
+	_, err = Unmarshal("email", "not json")
+	assert.Error(t, err)
+}
+
+func TestMasks(t *testing.T) {
+	rules := []Rule{
+		{Name: "email_all", Table: "customers", Column: "email", Mask: Mask{Function: Partial, Prefix: 1, Padding: "*", Suffix: 1}},
+		{Name: "email_support", Table: "customers", Column: "Email", Mask: Mask{Function: Hash}, Roles: []string{"support"}},
+		{Name: "phone_support", Table: "Customers", Column: "phone", Mask: Mask{Function: Null}, Roles: []string{"support", "sales"}},
+		{Name: "orders", Table: "orders", Column: "card", Mask: Mask{Function: Full}},
+	}
+
+	assert.Equal(t, map[string]Mask{
+		"email": {Function: Partial, Prefix: 1, Padding: "*", Suffix: 1},
+	}, Masks(rules, "customers", []string{"bob"}))
+	assert.Equal(t, map[string]Mask{
+		"email": {Function: Hash},
+		"phone": {Function: Null},
+	}, Masks(rules, "CUSTOMERS", []string{"alice", "support"}))
+	assert.Equal(t, map[string]Mask{
+		"email": {Function: Partial, Prefix: 1, Padding: "*", Suffix: 1},
+		"phone": {Function: Null},
+	}, Masks(rules, "customers", []string{"sales"}))
+	assert.Nil(t, Masks(rules, "products", []string{"support"}))
+
+	assert.True(t, HasMasks(rules, "Orders"))
+	assert.False(t, HasMasks(rules, "products"))
+	assert.True(t, HasRoles(rules, "customers"))
+	assert.False(t, HasRoles(rules, "orders"))
+}
@@ -0,0 +1,330 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/colmask"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// DynamicPrivilege_DoltUnmask is the dynamic privilege which allows a user to read the values of masked columns
+// unmasked. It is granted with GRANT DOLT_UNMASK ON *.* TO <user>.
+const DynamicPrivilege_DoltUnmask = "dolt_unmask"
+
+// ErrMaskedWrite is returned when a user whose reads of a table are masked updates or deletes its rows, which would
+// write the masked values of the rows back to the table.
+var ErrMaskedWrite = errors.NewKind("the rows of table %s cannot be updated or deleted: its columns are masked for the current user")
+
+// MaskingGrants answers the questions about the grants of the current user that column masks depend on, which are
+// held by the privilege system of the engine.
+type MaskingGrants interface {
+	// HasUnmask returns whether the current user has been granted DynamicPrivilege_DoltUnmask.
+	HasUnmask(ctx *sql.Context) bool
+	// GrantedRoles returns the roles which have been granted to the current user.
+	GrantedRoles(ctx *sql.Context) ([]string, error)
+}
+
+// ColumnMaskProcedures are the stored procedures that create and drop column masks.
+var ColumnMaskProcedures = []sql.ExternalStoredProcedureDetails{
+	{Name: "dolt_create_mask", Schema: rowPolicyProcedureSchema, Function: doltCreateMask, AdminOnly: true},
+	{Name: "dolt_drop_mask", Schema: rowPolicyProcedureSchema, Function: doltDropMask, AdminOnly: true},
+}
+
+func init() {
+	dtables.ColumnMasks = columnMasks
+}
+
+// doltCreateMask is the stored procedure that creates a column mask:
+//
+//	CALL dolt_create_mask('name', 'table', 'column', 'partial(2, 4)' [, '--roles', 'support,sales'])
+//
+// Masks are stored in the dolt_schemas table, so they are versioned with the tables they apply to. The masks which
+// apply to every branch are those of the default branch.
+func doltCreateMask(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	apr, err := cli.CreateMaskArgParser().Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if apr.NArg() != 4 {
+		return nil, fmt.Errorf("dolt_create_mask requires a mask name, a table, a column and a mask")
+	}
+	r := colmask.Rule{Name: apr.Arg(0), Table: apr.Arg(1), Column: apr.Arg(2)}
+	if r.Name == "" {
+		return nil, fmt.Errorf("dolt_create_mask requires a mask name")
+	}
+	if r.Mask, err = colmask.ParseMask(apr.Arg(3)); err != nil {
+		return nil, err
+	}
+	if roles, ok := apr.GetValue(cli.RolesFlag); ok {
+		for _, role := range strings.Split(roles, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				r.Roles = append(r.Roles, role)
+			}
+		}
+	}
+	db, err := currentColumnMaskDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, tableName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: r.Table})
+	if err != nil {
+		return nil, err
+	}
+	if !ok || doltdb.HasDoltPrefix(tableName) {
+		return nil, sql.ErrTableNotFound.New(r.Table)
+	}
+	r.Table = tableName
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	col, ok := sch.GetAllCols().GetByNameCaseInsensitive(r.Column)
+	if !ok {
+		return nil, sql.ErrColumnNotFound.New(r.Column)
+	}
+	r.Column = col.Name
+	if _, ok := col.TypeInfo.ToSqlType().(sql.StringType); !ok && r.StringOnly() {
+		return nil, fmt.Errorf("mask %s can only be applied to string columns, but column %s is %s", r.Function, col.Name, col.TypeInfo.ToSqlType().String())
+	}
+
+	fragment, err := r.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	err = db.addFragToSchemasTable(ctx, maskFragment, r.Name, fragment, time.Now(), fmt.Errorf("mask %s already exists", r.Name))
+	if err != nil {
+		return nil, err
+	}
+	warnNotDefaultBranch(ctx, db, "mask", r.Name)
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// doltDropMask is the stored procedure that drops a column mask.
+func doltDropMask(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	apr, err := cli.DropMaskArgParser().Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if apr.NArg() != 1 {
+		return nil, fmt.Errorf("dolt_drop_mask requires a mask name")
+	}
+	db, err := currentColumnMaskDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := apr.Arg(0)
+	missingErr := fmt.Errorf("mask not found: %s", name)
+	if apr.Contains(cli.IfExistsFlag) {
+		missingErr = nil
+	}
+	err = db.dropFragFromSchemasTable(ctx, maskFragment, name, missingErr)
+	if err != nil {
+		return nil, err
+	}
+	warnNotDefaultBranch(ctx, db, "mask", name)
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// currentColumnMaskDatabase returns the current database, or an error if it cannot hold column masks.
+func currentColumnMaskDatabase(ctx *sql.Context) (Database, error) {
+	db, ok, err := materializedViewDatabase(ctx)
+	if err != nil {
+		return Database{}, err
+	}
+	if !ok {
+		return Database{}, fmt.Errorf("database %s does not support column masks", ctx.GetCurrentDatabase())
+	}
+	return db, nil
+}
+
+// columnMaskCache holds the masking rules of dolt_schemas tables by the hash of the table.
+var columnMaskCache = struct {
+	sync.Mutex
+	rules map[hash.Hash][]colmask.Rule
+}{
+	rules: make(map[hash.Hash][]colmask.Rule),
+}
+
+// loadColumnMasks returns the masking rules which apply to the database |dbName|, which are those of the working root
+// of its default branch, like its row-level security policies.
+func loadColumnMasks(ctx *sql.Context, dbName string) ([]colmask.Rule, error) {
+	defaultDb, err := rowPolicyDatabaseName(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	h, ok, err := workingSchemasTableHash(ctx, defaultDb)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	columnMaskCache.Lock()
+	rules, ok := columnMaskCache.rules[h]
+	columnMaskCache.Unlock()
+	if ok {
+		return rules, nil
+	}
+
+	frags, err := workingSchemaFragments(ctx, defaultDb, maskFragment)
+	if err != nil {
+		return nil, err
+	}
+	for _, frag := range frags {
+		r, err := colmask.Unmarshal(frag.name, frag.fragment)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	columnMaskCache.Lock()
+	defer columnMaskCache.Unlock()
+	if len(columnMaskCache.rules) >= rowPolicyCacheSize {
+		columnMaskCache.rules = make(map[hash.Hash][]colmask.Rule)
+	}
+	columnMaskCache.rules[h] = rules
+	return rules, nil
+}
+
+// maskingGrants returns the MaskingGrants of the provider of the session of |ctx|, or nil if it has none.
+func maskingGrants(ctx *sql.Context) MaskingGrants {
+	if p, ok := dsess.DSessFromSess(ctx.Session).Provider().(*DoltDatabaseProvider); ok {
+		return p.MaskingGrants()
+	}
+	return nil
+}
+
+// columnMasks returns the masks of the columns of the table |tableName| that apply to the current user, by lowercase
+// column name. The masks are read from the working root of the default branch of the database |dbName|, so that
+// historical versions of the table and other branches are masked by the current masks. The users who administer the database, and those who have been granted
+// DynamicPrivilege_DoltUnmask, read the columns unmasked.
+func columnMasks(ctx *sql.Context, dbName, tableName string) (map[string]colmask.Mask, error) {
+	if doltdb.HasDoltPrefix(tableName) || rowPolicyExempt(ctx, dbName) {
+		return nil, nil
+	}
+	rules, err := loadColumnMasks(ctx, dbName)
+	if err != nil || !colmask.HasMasks(rules, tableName) {
+		return nil, err
+	}
+
+	grants := maskingGrants(ctx)
+	if grants != nil && grants.HasUnmask(ctx) {
+		return nil, nil
+	}
+	roles := []string{ctx.Session.Client().User}
+	if grants != nil && colmask.HasRoles(rules, tableName) {
+		granted, err := grants.GrantedRoles(ctx)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, granted...)
+	}
+	return colmask.Masks(rules, tableName, roles), nil
+}
+
+// columnMasker returns the masker of the columns of |t| for the current user, or nil if they read them unmasked.
+func (t *DoltTable) columnMasker(ctx *sql.Context) (*dtables.ColumnMasker, error) {
+	return dtables.NewColumnMasker(ctx, t.db.Name(), t.tableName)
+}
+
+// unmaskedIndexes returns |indexes|, the indexes of |t|, without those of the columns of |t| which are masked for the
+// current user. Lookups of those indexes compare the values of the columns before they are masked, so their results
+// would reveal the values. Without the indexes, the filters of the columns compare the masked values the user reads.
+// Partial indexes and indexes of expressions are also left out, since their predicates and expressions can read masked
+// columns.
+func (t *DoltTable) unmaskedIndexes(ctx *sql.Context, indexes []sql.Index) ([]sql.Index, error) {
+	masks, err := columnMasks(ctx, t.db.Name(), t.tableName)
+	if err != nil || len(masks) == 0 {
+		return indexes, err
+	}
+	unmasked := make([]sql.Index, 0, len(indexes))
+	for _, idx := range indexes {
+		di, ok := idx.(index.DoltIndex)
+		if !ok {
+			continue
+		}
+		if def := t.sch.Indexes().GetByName(di.ID()); def != nil && (def.Predicate() != "" || len(def.Expressions()) > 0) {
+			continue
+		}
+		masked := false
+		for _, col := range di.IndexSchema().GetAllCols().GetColumns() {
+			if _, ok := masks[strings.ToLower(col.Name)]; ok {
+				masked = true
+				break
+			}
+		}
+		if !masked {
+			unmasked = append(unmasked, idx)
+		}
+	}
+	return unmasked, nil
+}
+
+// ReadRestricted returns whether the rows the current user reads from |t| are filtered by row-level security
+// policies or masked by column masks, in which case the rows must be read with the row iterators of |t|.
+func (t *DoltTable) ReadRestricted(ctx *sql.Context) (bool, error) {
+	if restricted, err := t.RowPolicyRestricted(ctx); err != nil || restricted {
+		return restricted, err
+	}
+	masker, err := t.columnMasker(ctx)
+	return masker != nil, err
+}
+
+// withColumnMasks returns |te|, which writes the rows of |t|, without the updates and deletes of the rows of |t| if
+// its columns are masked for the current user. The rows they update and delete are the masked rows they read, so
+// writing them back would overwrite the values of the masked columns, and leave the indexes of |t| inconsistent.
+func (t *WritableDoltTable) withColumnMasks(ctx *sql.Context, te dsess.TableWriter) (dsess.TableWriter, error) {
+	masker, err := t.columnMasker(ctx)
+	if err != nil || masker == nil {
+		return te, err
+	}
+	return &maskedTableWriter{TableWriter: te, table: t}, nil
+}
+
+// maskedTableWriter is a dsess.TableWriter of a table whose columns are masked for the current user, which inserts
+// rows but does not update or delete them.
+type maskedTableWriter struct {
+	dsess.TableWriter
+	table *WritableDoltTable
+}
+
+var _ dsess.TableWriter = (*maskedTableWriter)(nil)
+
+func (w *maskedTableWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	return ErrMaskedWrite.New(w.table.tableName)
+}
+
+func (w *maskedTableWriter) Delete(ctx *sql.Context, row sql.Row) error {
+	return ErrMaskedWrite.New(w.table.tableName)
+}
@@ -49,6 +49,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/globalstate"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/resolve"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/ttl"
 	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
 	"github.com/dolthub/dolt/go/libraries/utils/concurrentmap"
 	"github.com/dolthub/dolt/go/store/hash"
@@ -376,6 +377,33 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 			return nil, false, fmt.Errorf("expected Alterable or WritableDoltTable, found %T", baseTable)
 		}
 
+	case strings.HasPrefix(lwrName, doltdb.DoltSystemTimeTablePrefix):
+		baseTableName := tblName[len(doltdb.DoltSystemTimeTablePrefix):]
+		baseTable, ok, err := db.getTable(ctx, root, baseTableName)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return nil, false, nil
+		}
+
+		if head == nil {
+			var err error
+			head, err = ds.GetHeadCommit(ctx, db.RevisionQualifiedName())
+			if err != nil {
+				return nil, false, err
+			}
+		}
+
+		switch t := baseTable.(type) {
+		case *AlterableDoltTable:
+			return NewSystemTimeTable(t.DoltTable, db.ddb, head), true, nil
+		case *WritableDoltTable:
+			return NewSystemTimeTable(t.DoltTable, db.ddb, head), true, nil
+		default:
+			return nil, false, fmt.Errorf("expected Alterable or WritableDoltTable, found %T", baseTable)
+		}
+
 	case strings.HasPrefix(lwrName, doltdb.DoltConfTablePrefix):
 		suffix := tblName[len(doltdb.DoltConfTablePrefix):]
 		srcTable, ok, err := db.getTableInsensitive(ctx, head, ds, root, suffix, asOf)
@@ -384,7 +412,7 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 		} else if !ok {
 			return nil, false, nil
 		}
-		dt, err := dtables.NewConflictsTable(ctx, suffix, srcTable, root, dtables.RootSetter(db))
+		dt, err := dtables.NewConflictsTable(ctx, db.Name(), suffix, srcTable, root, dtables.RootSetter(db))
 		if err != nil {
 			return nil, false, err
 		}
@@ -392,7 +420,7 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 
 	case strings.HasPrefix(lwrName, doltdb.DoltConstViolTablePrefix):
 		suffix := tblName[len(doltdb.DoltConstViolTablePrefix):]
-		dt, err := dtables.NewConstraintViolationsTable(ctx, suffix, root, dtables.RootSetter(db))
+		dt, err := dtables.NewConstraintViolationsTable(ctx, db.Name(), suffix, root, dtables.RootSetter(db))
 		if err != nil {
 			return nil, false, err
 		}
@@ -503,6 +531,18 @@ func (db Database) getTableInsensitive(ctx *sql.Context, head *doltdb.Commit, ds
 		}
 	case doltdb.StatisticsTableName:
 		dt, found = dtables.NewStatisticsTable(ctx, db.Name(), db.ddb, asOf), true
+	case doltdb.BinlogReplicasTableName:
+		dt, found = dtables.NewBinlogReplicasTable(ctx), true
+	case doltdb.SlowQueriesTableName:
+		dt, found = dtables.NewSlowQueriesTable(ctx), true
+	case doltdb.ResourceLimitsTableName:
+		dt, found = dtables.NewResourceLimitsTable(ctx), true
+	case doltdb.TTLStatusTableName:
+		var branch string
+		if db.RevisionType() == dsess.RevisionTypeBranch {
+			branch = db.Revision()
+		}
+		dt, found = dtables.NewTTLStatusTable(ctx, db.Name(), branch, root), true
 	case doltdb.ProceduresTableName:
 		found = true
 		backingTable, _, err := db.getTable(ctx, root, doltdb.ProceduresTableName)
@@ -1158,7 +1198,18 @@ func (db Database) createSqlTable(ctx *sql.Context, tableName string, schemaName
 	if err != nil {
 		return err
 	}
+	comment, t, err := ttl.SplitComment(comment)
+	if err != nil {
+		return err
+	}
 	doltSch.SetComment(comment)
+	if t != nil {
+		resolved, err := resolveTTL(doltSch, *t)
+		if err != nil {
+			return err
+		}
+		doltSch.SetTTL(resolved.String())
+	}
 
 	// Prevent any tables that use Spatial Types as Primary Key from being created
 	if schema.IsUsingSpatialColAsKey(doltSch) {
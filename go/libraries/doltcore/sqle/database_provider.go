@@ -55,6 +55,7 @@ type DoltDatabaseProvider struct {
 	externalProcedures sql.ExternalStoredProcedureRegistry
 	InitDatabaseHooks  []InitDatabaseHook
 	DropDatabaseHooks  []DropDatabaseHook
+	preCommitHooks     []dsess.PreCommitHook
 	mu                 *sync.RWMutex
 
 	droppedDatabaseManager *droppedDatabaseManager
@@ -63,8 +64,10 @@ type DoltDatabaseProvider struct {
 	fs            filesys.Filesys
 	remoteDialer  dbfactory.GRPCDialProvider // TODO: why isn't this a method defined on the remote object
 
-	dbFactoryUrl string
-	isStandby    *bool
+	dbFactoryUrl     string
+	isStandby        *bool
+	standbyReadCheck func(*sql.Context, string) error
+	maskingGrants    *MaskingGrants
 }
 
 var _ sql.DatabaseProvider = (*DoltDatabaseProvider)(nil)
@@ -128,6 +131,21 @@ func NewDoltDatabaseProviderWithDatabases(defaultBranch string, fs filesys.Files
 
 	externalProcedures := sql.NewExternalStoredProcedureRegistry()
 	for _, esp := range dprocedures.DoltProcedures {
+		externalProcedures.Register(withRowPolicyRefCheck(esp))
+	}
+	for _, esp := range MaterializedViewProcedures {
+		externalProcedures.Register(esp)
+	}
+	for _, esp := range RowPolicyProcedures {
+		externalProcedures.Register(esp)
+	}
+	for _, esp := range ColumnMaskProcedures {
+		externalProcedures.Register(esp)
+	}
+	for _, esp := range TTLProcedures {
+		externalProcedures.Register(esp)
+	}
+	for _, esp := range PartialIndexProcedures {
 		externalProcedures.Register(esp)
 	}
 
@@ -148,7 +166,9 @@ func NewDoltDatabaseProviderWithDatabases(defaultBranch string, fs filesys.Files
 		defaultBranch:          defaultBranch,
 		dbFactoryUrl:           dbFactoryUrl,
 		InitDatabaseHooks:      []InitDatabaseHook{ConfigureReplicationDatabaseHook},
+		preCommitHooks:         []dsess.PreCommitHook{refreshMaterializedViewsOnCommit},
 		isStandby:              new(bool),
+		maskingGrants:          new(MaskingGrants),
 		droppedDatabaseManager: newDroppedDatabaseManager(fs),
 	}, nil
 }
@@ -192,6 +212,17 @@ func (p *DoltDatabaseProvider) AddDropDatabaseHook(hook DropDatabaseHook) {
 	p.DropDatabaseHooks = append(p.DropDatabaseHooks, hook)
 }
 
+// AddPreCommitHook adds a dsess.PreCommitHook to this provider. The hook will be invoked
+// whenever dolt_commit commits a database of this provider.
+func (p *DoltDatabaseProvider) AddPreCommitHook(hook dsess.PreCommitHook) {
+	p.preCommitHooks = append(p.preCommitHooks, hook)
+}
+
+// PreCommitHooks implements dsess.DoltDatabaseProvider.
+func (p *DoltDatabaseProvider) PreCommitHooks() []dsess.PreCommitHook {
+	return p.preCommitHooks
+}
+
 func (p *DoltDatabaseProvider) FileSystem() filesys.Filesys {
 	return p.fs
 }
@@ -204,6 +235,30 @@ func (p *DoltDatabaseProvider) SetIsStandby(standby bool) {
 	*p.isStandby = standby
 }
 
+// SetStandbyReadCheck sets a check which is run whenever a database is accessed while this provider is a standby. If
+// the check returns an error, the database cannot be accessed and the error is returned to the caller. Cluster
+// replication uses this to bound how stale the data which is read on a standby can be.
+func (p *DoltDatabaseProvider) SetStandbyReadCheck(check func(ctx *sql.Context, dbName string) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.standbyReadCheck = check
+}
+
+// SetMaskingGrants sets the MaskingGrants which column masks consult for the grants of the current user. Without them,
+// masks apply to every user who does not administer the database, and their roles are not considered.
+func (p *DoltDatabaseProvider) SetMaskingGrants(grants MaskingGrants) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	*p.maskingGrants = grants
+}
+
+// MaskingGrants returns the MaskingGrants set by SetMaskingGrants, or nil if none have been set.
+func (p *DoltDatabaseProvider) MaskingGrants() MaskingGrants {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return *p.maskingGrants
+}
+
 // FileSystemForDatabase returns a filesystem, with the working directory set to the root directory
 // of the requested database. If the requested database isn't found, a database not found error
 // is returned.
@@ -1221,8 +1276,15 @@ func (p *DoltDatabaseProvider) SessionDatabase(ctx *sql.Context, name string) (d
 	p.mu.RLock()
 	db, ok := p.databases[strings.ToLower(baseName)]
 	standby := *p.isStandby
+	standbyReadCheck := p.standbyReadCheck
 	p.mu.RUnlock()
 
+	if standby && standbyReadCheck != nil {
+		if err := standbyReadCheck(ctx, baseName); err != nil {
+			return nil, false, err
+		}
+	}
+
 	// If the database doesn't exist and this is a read replica, attempt to clone it from the remote
 	if !ok {
 		var err error
@@ -0,0 +1,233 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfunctions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+const ClusterWaitForHashFuncName = "dolt_cluster_wait_for_hash"
+
+// How often dolt_cluster_wait_for_hash checks whether the commit has arrived.
+const clusterWaitForHashPollInterval = 50 * time.Millisecond
+
+// ClusterWaitForHash implements dolt_cluster_wait_for_hash(database, commit_hash [, timeout_secs]). It blocks until
+// the commit |commit_hash| is on a branch of |database|, which is typically used on a cluster standby to read the
+// writes of a transaction which was committed on the primary. Like MySQL's WAIT_FOR_EXECUTED_GTID_SET, it returns 0
+// once the commit is on a branch and 1 if |timeout_secs| elapses first. Without a timeout, it waits until the query
+// is canceled.
+//
+// The check reads the branches from the database itself, not from the session's transaction, so it should be called
+// outside of an explicit transaction. Queries after it will see the commit once they start a new transaction.
+type ClusterWaitForHash struct {
+	children []sql.Expression
+}
+
+var _ sql.FunctionExpression = (*ClusterWaitForHash)(nil)
+
+// NewClusterWaitForHash creates a new ClusterWaitForHash expression.
+func NewClusterWaitForHash(args ...sql.Expression) (sql.Expression, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, sql.ErrInvalidArgumentNumber.New(ClusterWaitForHashFuncName, "2 or 3", len(args))
+	}
+	return &ClusterWaitForHash{children: args}, nil
+}
+
+// Eval implements the Expression interface.
+func (w *ClusterWaitForHash) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	dbName, err := w.evalString(ctx, row, w.children[0])
+	if err != nil {
+		return nil, err
+	}
+	hashStr, err := w.evalString(ctx, row, w.children[1])
+	if err != nil {
+		return nil, err
+	}
+	h, ok := hash.MaybeParse(strings.TrimSpace(hashStr))
+	if !ok {
+		return nil, fmt.Errorf("%s: invalid commit hash '%s'", ClusterWaitForHashFuncName, hashStr)
+	}
+
+	var timeout <-chan time.Time
+	if len(w.children) == 3 {
+		timeoutVal, err := w.children[2].Eval(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if timeoutVal != nil {
+			secs, _, err := types.Float64.Convert(timeoutVal)
+			if err != nil {
+				return nil, err
+			}
+			timer := time.NewTimer(time.Duration(secs.(float64) * float64(time.Second)))
+			defer timer.Stop()
+			timeout = timer.C
+		}
+	}
+
+	sess := dsess.DSessFromSess(ctx.Session)
+	dbData, ok := sess.GetDbData(ctx, dbName)
+	if !ok {
+		return nil, sql.ErrDatabaseNotFound.New(dbName)
+	}
+	ddb := dbData.Ddb
+
+	ticker := time.NewTicker(clusterWaitForHashPollInterval)
+	defer ticker.Stop()
+	for {
+		onBranch, err := commitIsOnBranch(ctx, ddb, h)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ClusterWaitForHashFuncName, err)
+		}
+		if onBranch {
+			return int8(0), nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return int8(1), nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *ClusterWaitForHash) evalString(ctx *sql.Context, row sql.Row, expr sql.Expression) (string, error) {
+	val, err := expr.Eval(ctx, row)
+	if err != nil {
+		return "", err
+	}
+	if val == nil {
+		return "", sql.ErrInvalidArgumentDetails.New(ClusterWaitForHashFuncName, expr)
+	}
+	str, _, err := types.Text.Convert(val)
+	if err != nil {
+		return "", err
+	}
+	return str.(string), nil
+}
+
+// commitIsOnBranch returns true if the commit |h| is the head of a branch of |ddb| or is an ancestor of one.
+func commitIsOnBranch(ctx context.Context, ddb *doltdb.DoltDB, h hash.Hash) (bool, error) {
+	branches, err := ddb.GetBranchesWithHashes(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, b := range branches {
+		if b.Hash == h {
+			return true, nil
+		}
+	}
+
+	cs, err := doltdb.NewCommitSpec(h.String())
+	if err != nil {
+		return false, err
+	}
+	optCmt, err := ddb.Resolve(ctx, cs, nil)
+	if errors.Is(err, datas.ErrCommitNotFound) {
+		// The commit has not been replicated yet.
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	commit, ok := optCmt.ToCommit()
+	if !ok {
+		return false, nil
+	}
+	height, err := commit.Height()
+	if err != nil {
+		return false, err
+	}
+
+	for _, b := range branches {
+		head, err := ddb.ResolveCommitRef(ctx, b.Ref)
+		if err != nil {
+			return false, err
+		}
+		cc, err := head.GetCommitClosure(ctx)
+		if err != nil {
+			return false, err
+		}
+		isAncestor, err := cc.ContainsKey(ctx, h, height)
+		if err != nil {
+			return false, err
+		}
+		if isAncestor {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Children implements the Expression interface.
+func (w *ClusterWaitForHash) Children() []sql.Expression {
+	return w.children
+}
+
+// String implements the Stringer interface.
+func (w *ClusterWaitForHash) String() string {
+	args := make([]string, len(w.children))
+	for i, child := range w.children {
+		args[i] = child.String()
+	}
+	return fmt.Sprintf("%s(%s)", ClusterWaitForHashFuncName, strings.Join(args, ", "))
+}
+
+// FunctionName implements the FunctionExpression interface.
+func (w *ClusterWaitForHash) FunctionName() string {
+	return ClusterWaitForHashFuncName
+}
+
+// Description implements the FunctionExpression interface.
+func (w *ClusterWaitForHash) Description() string {
+	return "waits until a commit is on a branch of a database, typically to read your own writes on a cluster standby; returns 0 once it is and 1 on timeout"
+}
+
+// IsNullable implements the Expression interface.
+func (w *ClusterWaitForHash) IsNullable() bool {
+	return false
+}
+
+// Resolved implements the Expression interface.
+func (w *ClusterWaitForHash) Resolved() bool {
+	for _, child := range w.children {
+		if !child.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// WithChildren implements the Expression interface.
+func (w *ClusterWaitForHash) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	return NewClusterWaitForHash(children...)
+}
+
+// Type implements the Expression interface.
+func (w *ClusterWaitForHash) Type() sql.Type {
+	return types.Int8
+}
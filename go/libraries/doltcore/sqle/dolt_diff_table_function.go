@@ -168,7 +168,7 @@ func (dtf *DiffTableFunction) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter,
 	// TODO: When we add support for joining on table functions, we'll need to evaluate this against the
 	//       specified row. That row is what has the left_table context in a join query.
 	//       This will expand the test cases we need to cover significantly.
-	fromCommitVal, toCommitVal, dotCommitVal, _, err := dtf.evaluateArguments()
+	fromCommitVal, toCommitVal, dotCommitVal, tableName, err := dtf.evaluateArguments()
 	if err != nil {
 		return nil, err
 	}
@@ -178,6 +178,15 @@ func (dtf *DiffTableFunction) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter,
 		return nil, fmt.Errorf("unable to get dolt database")
 	}
 
+	filter, err := dtables.NewRowPolicyFilter(ctx, sqledb.Name(), tableName)
+	if err != nil {
+		return nil, err
+	}
+	masker, err := dtables.NewColumnMasker(ctx, sqledb.Name(), tableName)
+	if err != nil {
+		return nil, err
+	}
+
 	fromCommitStr, toCommitStr, err := loadCommitStrings(ctx, fromCommitVal, toCommitVal, dotCommitVal, sqledb)
 	if err != nil {
 		return nil, err
@@ -186,7 +195,8 @@ func (dtf *DiffTableFunction) RowIter(ctx *sql.Context, _ sql.Row) (sql.RowIter,
 	ddb := sqledb.DbData().Ddb
 	dp := dtables.NewDiffPartition(dtf.tableDelta.ToTable, dtf.tableDelta.FromTable, toCommitStr, fromCommitStr, dtf.toDate, dtf.fromDate, dtf.tableDelta.ToSch, dtf.tableDelta.FromSch)
 
-	return dtables.NewDiffPartitionRowIter(*dp, ddb, dtf.joiner), nil
+	iter := filter.DiffRowIter(dtf.Schema(), dtables.NewDiffPartitionRowIter(*dp, ddb, dtf.joiner))
+	return masker.DiffRowIter(dtf.Schema(), iter), nil
 }
 
 // findMatchingDelta returns the best matching table delta for the table name
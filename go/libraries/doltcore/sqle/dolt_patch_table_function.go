@@ -164,7 +164,13 @@ func (p *PatchTableFunction) PartitionRows(ctx *sql.Context, partition sql.Parti
 	includeSchemaDiff := bytes.Equal(partition.Key(), schemaAndDataChangePartitionKey) || bytes.Equal(partition.Key(), schemaChangePartitionKey)
 	includeDataDiff := bytes.Equal(partition.Key(), schemaAndDataChangePartitionKey) || bytes.Equal(partition.Key(), dataChangePartitionKey)
 
-	patches, err := getPatchNodes(ctx, sqledb.DbData(), tableDeltas, fromRefDetails, toRefDetails, includeSchemaDiff, includeDataDiff)
+	if includeDataDiff {
+		if err = checkRowPolicyPatch(ctx, sqledb.Name(), tableDeltas); err != nil {
+			return nil, err
+		}
+	}
+
+	patches, err := getPatchNodes(ctx, sqledb.Name(), sqledb.DbData(), tableDeltas, fromRefDetails, toRefDetails, includeSchemaDiff, includeDataDiff)
 	if err != nil {
 		return nil, err
 	}
@@ -480,7 +486,7 @@ type patchNode struct {
 	dataPatchStmts   []string
 }
 
-func getPatchNodes(ctx *sql.Context, dbData env.DbData, tableDeltas []diff.TableDelta, fromRefDetails, toRefDetails *refDetails, includeSchemaDiff, includeDataDiff bool) (patches []*patchNode, err error) {
+func getPatchNodes(ctx *sql.Context, dbName string, dbData env.DbData, tableDeltas []diff.TableDelta, fromRefDetails, toRefDetails *refDetails, includeSchemaDiff, includeDataDiff bool) (patches []*patchNode, err error) {
 	for _, td := range tableDeltas {
 		if td.FromTable == nil && td.ToTable == nil {
 			// no diff
@@ -523,7 +529,7 @@ func getPatchNodes(ctx *sql.Context, dbData env.DbData, tableDeltas []diff.Table
 		// Get DATA DIFF
 		var dataStmts []string
 		if includeDataDiff && canGetDataDiff(ctx, td) {
-			dataStmts, err = getUserTableDataSqlPatch(ctx, dbData, td, fromRefDetails, toRefDetails)
+			dataStmts, err = getUserTableDataSqlPatch(ctx, dbName, dbData, td, fromRefDetails, toRefDetails)
 			if err != nil {
 				return nil, err
 			}
@@ -553,9 +559,9 @@ func canGetDataDiff(ctx *sql.Context, td diff.TableDelta) bool {
 	return true
 }
 
-func getUserTableDataSqlPatch(ctx *sql.Context, dbData env.DbData, td diff.TableDelta, fromRefDetails, toRefDetails *refDetails) ([]string, error) {
+func getUserTableDataSqlPatch(ctx *sql.Context, dbName string, dbData env.DbData, td diff.TableDelta, fromRefDetails, toRefDetails *refDetails) ([]string, error) {
 	// ToTable is used as target table as it cannot be nil at this point
-	diffSch, projections, ri, err := getDiffQuery(ctx, dbData, td, fromRefDetails, toRefDetails)
+	diffSch, projections, ri, err := getDiffQuery(ctx, dbName, dbData, td, fromRefDetails, toRefDetails)
 	if err != nil {
 		return nil, err
 	}
@@ -618,7 +624,7 @@ func getDataSqlPatchResults(ctx *sql.Context, diffQuerySch, targetSch sql.Schema
 // on diff table function row iter. This function attempts to imitate running a query
 // fmt.Sprintf("select %s, %s from dolt_diff('%s', '%s', '%s')", columnsWithDiff, "diff_type", fromRef, toRef, tableName)
 // on sql engine, which returns the schema and rowIter of the final data diff result.
-func getDiffQuery(ctx *sql.Context, dbData env.DbData, td diff.TableDelta, fromRefDetails, toRefDetails *refDetails) (sql.Schema, []sql.Expression, sql.RowIter, error) {
+func getDiffQuery(ctx *sql.Context, dbName string, dbData env.DbData, td diff.TableDelta, fromRefDetails, toRefDetails *refDetails) (sql.Schema, []sql.Expression, sql.RowIter, error) {
 	diffTableSchema, j, err := dtables.GetDiffTableSchemaAndJoiner(td.ToTable.Format(), td.FromSch, td.ToSch)
 	if err != nil {
 		return nil, nil, nil, err
@@ -631,8 +637,17 @@ func getDiffQuery(ctx *sql.Context, dbData env.DbData, td diff.TableDelta, fromR
 	columnsWithDiff := getColumnNamesWithDiff(td.FromSch, td.ToSch)
 	diffQuerySqlSch, projections := getDiffQuerySqlSchemaAndProjections(diffPKSch.Schema, columnsWithDiff)
 
+	tableName := td.ToName.Name
+	if tableName == "" {
+		tableName = td.FromName.Name
+	}
+	masker, err := dtables.NewColumnMasker(ctx, dbName, tableName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	dp := dtables.NewDiffPartition(td.ToTable, td.FromTable, toRefDetails.hashStr, fromRefDetails.hashStr, toRefDetails.commitTime, fromRefDetails.commitTime, td.ToSch, td.FromSch)
-	ri := dtables.NewDiffPartitionRowIter(*dp, dbData.Ddb, j)
+	ri := masker.DiffRowIter(diffPKSch.Schema, dtables.NewDiffPartitionRowIter(*dp, dbData.Ddb, j))
 
 	return diffQuerySqlSch, projections, ri, nil
 }
@@ -15,6 +15,7 @@
 package dprocedures
 
 import (
+	"fmt"
 	"path"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -37,6 +38,10 @@ func doltClone(ctx *sql.Context, args ...string) (sql.RowIter, error) {
 		return nil, err
 	}
 
+	if apr.Contains(cli.FilterParam) {
+		return nil, fmt.Errorf("--filter is not supported by dolt_clone; use dolt clone to make a partial clone")
+	}
+
 	remoteName := apr.GetValueOrDefault(cli.RemoteParam, "origin")
 	branch := apr.GetValueOrDefault(cli.BranchParam, "")
 	dir, urlStr, err := getDirectoryAndUrlString(apr)
@@ -76,6 +76,12 @@ func doDoltCommit(ctx *sql.Context, args []string) (string, bool, error) {
 	}
 
 	dSess := dsess.DSessFromSess(ctx.Session)
+	for _, hook := range dSess.Provider().PreCommitHooks() {
+		if err := hook(ctx, dbName); err != nil {
+			return "", false, err
+		}
+	}
+
 	roots, ok := dSess.GetRoots(ctx, dbName)
 	if !ok {
 		return "", false, fmt.Errorf("Could not load database %s", dbName)
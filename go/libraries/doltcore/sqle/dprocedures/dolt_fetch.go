@@ -60,8 +60,14 @@ func doDoltFetch(ctx *sql.Context, args []string) (int, error) {
 	}
 
 	remote, refSpecArgs, err := env.RemoteForFetchArgs(apr.Args, dbData.Rsr)
+	isBundle := false
 	if err != nil {
-		return cmdFailure, err
+		// A path to a bundle file can be fetched from without adding it as a remote
+		remote, isBundle, err = remoteForBundleArg(ctx, dbName, apr.Args, err)
+		if err != nil {
+			return cmdFailure, err
+		}
+		refSpecArgs = apr.Args[1:]
 	}
 
 	validationErr := validateFetchArgs(apr, refSpecArgs)
@@ -69,7 +75,19 @@ func doDoltFetch(ctx *sql.Context, args []string) (int, error) {
 		return cmdFailure, validationErr
 	}
 
-	refSpecs, defaultRefSpec, err := env.ParseRefSpecs(refSpecArgs, dbData.Rsr, remote)
+	backfill := apr.Contains(cli.BackfillFlag)
+	if backfill && !dbData.Ddb.IsPartialClone() {
+		return cmdFailure, fmt.Errorf("--backfill can only be used in a partial clone")
+	}
+
+	var refSpecs []ref.RemoteRefSpec
+	var defaultRefSpec bool
+	if isBundle && len(refSpecArgs) == 0 {
+		refSpecs, err = env.ParseRSFromArgs(remote.Name, remote.FetchSpecs)
+		defaultRefSpec = true
+	} else {
+		refSpecs, defaultRefSpec, err = env.ParseRefSpecs(refSpecArgs, dbData.Rsr, remote)
+	}
 	if err != nil {
 		return cmdFailure, err
 	}
@@ -91,9 +109,41 @@ func doDoltFetch(ctx *sql.Context, args []string) (int, error) {
 	if err != nil {
 		return cmdFailure, fmt.Errorf("fetch failed: %w", err)
 	}
+
+	if backfill {
+		err = dbData.Ddb.CompletePartialClone(ctx)
+		if err != nil {
+			return cmdFailure, fmt.Errorf("backfill failed: %w", err)
+		}
+	}
+
 	return cmdSuccess, nil
 }
 
+// remoteForBundleArg returns a remote for the bundle file named by the first argument, or |remoteErr| if the
+// argument isn't the path to a file.
+func remoteForBundleArg(ctx *sql.Context, dbName string, args []string, remoteErr error) (env.Remote, bool, error) {
+	if len(args) == 0 {
+		return env.NoRemote, false, remoteErr
+	}
+
+	sess := dsess.DSessFromSess(ctx.Session)
+	dbFs, err := sess.Provider().FileSystemForDatabase(dbName)
+	if err != nil {
+		return env.NoRemote, false, err
+	}
+
+	if !env.IsBundlePath(dbFs, args[0]) {
+		return env.NoRemote, false, remoteErr
+	}
+
+	remote, err := env.RemoteForBundle(dbFs, args[0])
+	if err != nil {
+		return env.NoRemote, false, err
+	}
+	return remote, true, nil
+}
+
 // validateFetchArgs returns an error if the arguments provided aren't valid.
 func validateFetchArgs(apr *argparser.ArgParseResults, refSpecArgs []string) error {
 	if len(refSpecArgs) > 0 && apr.Contains(cli.PruneFlag) {
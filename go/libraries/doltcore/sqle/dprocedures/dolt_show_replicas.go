@@ -0,0 +1,81 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dprocedures
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+)
+
+// ShowReplicasProcedure is the stored procedure which SHOW REPLICAS is rewritten into. It lists the replicas streaming
+// from this server when it is a binlog primary, with the columns of MySQL's SHOW REPLICAS.
+const ShowReplicasProcedure = "dolt_show_replicas"
+
+// showReplicasSchema is the schema of MySQL's SHOW REPLICAS. Server_Id is always NULL: the server ID that replicas
+// send when they register and start streaming is discarded when the packets are parsed, before Dolt sees them.
+var showReplicasSchema = sql.Schema{
+	&sql.Column{Name: "Server_Id", Type: types.Uint32, Nullable: true},
+	&sql.Column{Name: "Host", Type: types.LongText, Nullable: false},
+	&sql.Column{Name: "Port", Type: types.Uint16, Nullable: false},
+	&sql.Column{Name: "Source_Id", Type: types.Uint32, Nullable: false},
+	&sql.Column{Name: "Replica_UUID", Type: types.LongText, Nullable: true},
+}
+
+func doltShowReplicas(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	if len(args) > 0 {
+		return nil, fmt.Errorf("%s does not take any arguments", ShowReplicasProcedure)
+	}
+
+	if err := checkShowReplicasPrivs(ctx); err != nil {
+		return nil, err
+	}
+
+	_, serverId, ok := sql.SystemVariables.GetGlobal("server_id")
+	if !ok {
+		return nil, fmt.Errorf("no server_id global system variable set")
+	}
+	sourceId, _, err := types.Uint32.Convert(serverId)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for _, status := range dtables.BinlogReplicaStatuses() {
+		var uuid interface{}
+		if status.ReplicaUuid != "" {
+			uuid = status.ReplicaUuid
+		}
+		rows = append(rows, sql.Row{nil, status.Host, status.Port, sourceId, uuid})
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// checkShowReplicasPrivs returns an error if the current user doesn't have the REPLICATION SLAVE privilege, which
+// MySQL requires for SHOW REPLICAS.
+func checkShowReplicasPrivs(ctx *sql.Context) error {
+	privs, counter := ctx.GetPrivilegeSet()
+	if counter == 0 {
+		return fmt.Errorf("unable to check user privileges for %s procedure", ShowReplicasProcedure)
+	}
+	if privs.Has(sql.PrivilegeType_ReplicationSlave) == false {
+		return sql.ErrPrivilegeCheckFailed.New(ctx.Session.Client().User)
+	}
+
+	return nil
+}
@@ -17,6 +17,8 @@ package dprocedures
 import (
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/histogram"
 )
 
 var DoltProcedures = []sql.ExternalStoredProcedureDetails{
@@ -45,6 +47,7 @@ var DoltProcedures = []sql.ExternalStoredProcedureDetails{
 	{Name: "dolt_remote", Schema: int64Schema("status"), Function: doltRemote, AdminOnly: true},
 	{Name: "dolt_reset", Schema: int64Schema("status"), Function: doltReset},
 	{Name: "dolt_revert", Schema: int64Schema("status"), Function: doltRevert},
+	{Name: ShowReplicasProcedure, Schema: showReplicasSchema, Function: doltShowReplicas, ReadOnly: true},
 	{Name: "dolt_tag", Schema: int64Schema("status"), Function: doltTag},
 	{Name: "dolt_verify_constraints", Schema: int64Schema("violations"), Function: doltVerifyConstraints},
 
@@ -52,6 +55,8 @@ var DoltProcedures = []sql.ExternalStoredProcedureDetails{
 	{Name: "dolt_stats_restart", Schema: statsFuncSchema, Function: statsFunc(statsRestart)},
 	{Name: "dolt_stats_stop", Schema: statsFuncSchema, Function: statsFunc(statsStop)},
 	{Name: "dolt_stats_status", Schema: statsFuncSchema, Function: statsFunc(statsStatus)},
+	{Name: histogram.UpdateProcedure, Schema: histogramSchema, Function: statsUpdateHistogram},
+	{Name: histogram.DropProcedure, Schema: histogramSchema, Function: statsDropHistogram},
 }
 
 // stringSchema returns a non-nullable schema with all columns as LONGTEXT.
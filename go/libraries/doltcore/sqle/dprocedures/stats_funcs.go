@@ -16,6 +16,7 @@ package dprocedures
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -24,6 +25,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/ref"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/histogram"
 )
 
 var statsFuncSchema = []*sql.Column{
@@ -124,3 +126,122 @@ func statsDrop(ctx *sql.Context) (interface{}, error) {
 	}
 	return fmt.Sprintf("deleted stats ref for %s", dbName), nil
 }
+
+// HistogramStatsProvider is a sql.StatsProvider that builds and drops the
+// histograms of columns which are not necessarily indexed.
+type HistogramStatsProvider interface {
+	sql.StatsProvider
+	UpdateHistogram(ctx *sql.Context, db string, table sql.Table, cols []string, buckets int) error
+	DropHistogram(ctx *sql.Context, db, table string, cols []string) (bool, error)
+}
+
+// histogramSchema is the schema of the results of ANALYZE TABLE ... HISTOGRAM.
+var histogramSchema = stringSchema("Table", "Op", "Msg_type", "Msg_text")
+
+// statsUpdateHistogram builds the histograms of the columns of a table, for ANALYZE TABLE ... UPDATE HISTOGRAM.
+func statsUpdateHistogram(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	if len(args) < 3 {
+		return nil, fmt.Errorf("usage: CALL %s('table', 'buckets', 'column' [, 'column,column' ...])", histogram.UpdateProcedure)
+	}
+	buckets, err := strconv.Atoi(args[1])
+	if err != nil || buckets < 0 || buckets > histogram.MaxBuckets {
+		return nil, fmt.Errorf("the number of buckets must be between 1 and %d, but is %s", histogram.MaxBuckets, args[1])
+	}
+	hsp, dbName, table, err := histogramTable(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for _, arg := range args[2:] {
+		cols := histogram.SplitColumns(arg)
+		if missing, ok := missingColumn(table.Schema(), cols); ok {
+			rows = append(rows, histogramRow(dbName, table.Name(), "Error", fmt.Sprintf("The column '%s' does not exist.", missing)))
+			continue
+		}
+		if err := hsp.UpdateHistogram(ctx, dbName, table, cols, buckets); err != nil {
+			return nil, err
+		}
+		rows = append(rows, histogramRow(dbName, table.Name(), "status", fmt.Sprintf("Histogram statistics created for %s.", describeColumns(cols))))
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// statsDropHistogram drops the histograms of the columns of a table, for ANALYZE TABLE ... DROP HISTOGRAM.
+func statsDropHistogram(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("usage: CALL %s('table', 'column' [, 'column,column' ...])", histogram.DropProcedure)
+	}
+	hsp, dbName, table, err := histogramTable(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for _, arg := range args[1:] {
+		cols := histogram.SplitColumns(arg)
+		ok, err := hsp.DropHistogram(ctx, dbName, table.Name(), cols)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rows = append(rows, histogramRow(dbName, table.Name(), "status", fmt.Sprintf("Histogram statistics removed for %s.", describeColumns(cols))))
+		} else {
+			rows = append(rows, histogramRow(dbName, table.Name(), "Error", fmt.Sprintf("No histogram statistics found for %s.", describeColumns(cols))))
+		}
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// histogramTable returns the stats provider of the session, and the current database and its table |name|.
+func histogramTable(ctx *sql.Context, name string) (HistogramStatsProvider, string, sql.Table, error) {
+	dSess := dsess.DSessFromSess(ctx.Session)
+	hsp, ok := dSess.StatsProvider().(HistogramStatsProvider)
+	if !ok {
+		return nil, "", nil, fmt.Errorf("provider does not implement HistogramStatsProvider")
+	}
+
+	if ctx.GetCurrentDatabase() == "" {
+		return nil, "", nil, sql.ErrNoDatabaseSelected.New()
+	}
+	dbName, _ := dsess.SplitRevisionDbName(ctx.GetCurrentDatabase())
+	db, err := dSess.Provider().Database(ctx, ctx.GetCurrentDatabase())
+	if err != nil {
+		return nil, "", nil, err
+	}
+	table, ok, err := db.GetTableInsensitive(ctx, name)
+	if err != nil {
+		return nil, "", nil, err
+	} else if !ok {
+		return nil, "", nil, sql.ErrTableNotFound.New(name)
+	}
+	return hsp, strings.ToLower(dbName), table, nil
+}
+
+func histogramRow(db, table, msgType, msg string) sql.Row {
+	return sql.Row{db + "." + table, "histogram", msgType, msg}
+}
+
+// missingColumn returns the first of |cols| which is not a column of |sch|, and whether there is one.
+func missingColumn(sch sql.Schema, cols []string) (string, bool) {
+	for _, c := range cols {
+		found := false
+		for _, col := range sch {
+			if strings.EqualFold(col.Name, c) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+func describeColumns(cols []string) string {
+	if len(cols) == 1 {
+		return fmt.Sprintf("column '%s'", cols[0])
+	}
+	return fmt.Sprintf("columns '%s'", strings.Join(cols, "', '"))
+}
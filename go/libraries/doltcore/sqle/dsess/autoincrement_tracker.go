@@ -212,7 +212,7 @@ func (a AutoIncrementTracker) deepSet(ctx *sql.Context, tableName string, table
 
 	var indexData durable.Index
 	aiIndex, ok := sch.Indexes().GetIndexByColumnNames(aiCol.Name)
-	if ok {
+	if ok && aiIndex.Predicate() == "" {
 		indexes, err := table.GetIndexSet(ctx)
 		if err != nil {
 			return nil, err
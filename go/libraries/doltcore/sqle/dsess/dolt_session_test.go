@@ -267,6 +267,10 @@ func (e emptyRevisionDatabaseProvider) PurgeDroppedDatabases(ctx *sql.Context) e
 	return nil
 }
 
+func (e emptyRevisionDatabaseProvider) PreCommitHooks() []PreCommitHook {
+	return nil
+}
+
 func (e emptyRevisionDatabaseProvider) BaseDatabase(ctx *sql.Context, dbName string) (SqlDatabase, bool) {
 	return nil, false
 }
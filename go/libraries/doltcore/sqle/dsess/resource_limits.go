@@ -0,0 +1,182 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dsess
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ResourceLimits are the limits on the resources used by the statements of a session. A limit of 0 means the
+// resource is not limited.
+type ResourceLimits struct {
+	// MaxConcurrentQueries is how many statements the user of the session can run at the same time, across all of
+	// their sessions.
+	MaxConcurrentQueries int
+	// MaxExecutionTime is how long a statement can run for.
+	MaxExecutionTime time.Duration
+	// MaxRowsReturned is how many rows a statement can return.
+	MaxRowsReturned uint64
+	// MaxRowsWrittenPerTransaction is how many rows can be inserted, updated or deleted in a transaction.
+	MaxRowsWrittenPerTransaction uint64
+	// KillOnExceed is true if the connection of a session should be killed when one of its statements exceeds a
+	// limit. Otherwise, only the statement fails.
+	KillOnExceed bool
+}
+
+// IsZero returns true if none of the resources are limited.
+func (l ResourceLimits) IsZero() bool {
+	return l.MaxConcurrentQueries == 0 && l.MaxExecutionTime == 0 && l.MaxRowsReturned == 0 && l.MaxRowsWrittenPerTransaction == 0
+}
+
+// String returns a description of the limits, as shown in the Info column of SHOW PROCESSLIST.
+func (l ResourceLimits) String() string {
+	var limits []string
+	if l.MaxConcurrentQueries > 0 {
+		limits = append(limits, fmt.Sprintf("max_concurrent_queries=%d", l.MaxConcurrentQueries))
+	}
+	if l.MaxExecutionTime > 0 {
+		limits = append(limits, fmt.Sprintf("max_execution_time=%s", l.MaxExecutionTime))
+	}
+	if l.MaxRowsReturned > 0 {
+		limits = append(limits, fmt.Sprintf("max_rows_returned=%d", l.MaxRowsReturned))
+	}
+	if l.MaxRowsWrittenPerTransaction > 0 {
+		limits = append(limits, fmt.Sprintf("max_rows_written_per_transaction=%d", l.MaxRowsWrittenPerTransaction))
+	}
+	onExceed := "error"
+	if l.KillOnExceed {
+		onExceed = "kill"
+	}
+	return fmt.Sprintf("resource limits: %s, on_exceed=%s", strings.Join(limits, ", "), onExceed)
+}
+
+// Strictest returns the limits which are the most restrictive of |l| and |other| for each resource.
+func (l ResourceLimits) Strictest(other ResourceLimits) ResourceLimits {
+	minInt := func(a, b int) int {
+		if a == 0 || (b != 0 && b < a) {
+			return b
+		}
+		return a
+	}
+	minUint := func(a, b uint64) uint64 {
+		if a == 0 || (b != 0 && b < a) {
+			return b
+		}
+		return a
+	}
+	return ResourceLimits{
+		MaxConcurrentQueries:         minInt(l.MaxConcurrentQueries, other.MaxConcurrentQueries),
+		MaxExecutionTime:             time.Duration(minUint(uint64(l.MaxExecutionTime), uint64(other.MaxExecutionTime))),
+		MaxRowsReturned:              minUint(l.MaxRowsReturned, other.MaxRowsReturned),
+		MaxRowsWrittenPerTransaction: minUint(l.MaxRowsWrittenPerTransaction, other.MaxRowsWrittenPerTransaction),
+		KillOnExceed:                 l.KillOnExceed || other.KillOnExceed,
+	}
+}
+
+// ResourceGovernor tracks the statements each user is running, to enforce ResourceLimits.MaxConcurrentQueries
+// across all of the sessions of a user.
+type ResourceGovernor struct {
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// NewResourceGovernor returns a new ResourceGovernor.
+func NewResourceGovernor() *ResourceGovernor {
+	return &ResourceGovernor{running: make(map[string]int)}
+}
+
+// BeginQuery records that a statement of |sess| started running. It returns an error if the user of |sess| is
+// already running as many statements as it is allowed to. Otherwise, the returned function must be called when the
+// statement finishes.
+func (g *ResourceGovernor) BeginQuery(sess *DoltSession) (func(), error) {
+	limits := sess.ResourceLimits()
+	if limits.MaxConcurrentQueries == 0 {
+		return func() {}, nil
+	}
+	user := sess.Client().User
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running[user] >= limits.MaxConcurrentQueries {
+		sess.resourceLimitExceeded.Store(true)
+		return nil, fmt.Errorf("user '%s' has exceeded the resource limit max_concurrent_queries (current value: %d)", user, limits.MaxConcurrentQueries)
+	}
+	g.running[user]++
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.running[user]--
+		if g.running[user] == 0 {
+			delete(g.running, user)
+		}
+	}, nil
+}
+
+// SetResourceLimits sets the limits on the resources used by the statements of this session.
+func (d *DoltSession) SetResourceLimits(limits ResourceLimits) {
+	if limits.IsZero() {
+		d.resourceLimits.Store(nil)
+	} else {
+		d.resourceLimits.Store(&limits)
+	}
+}
+
+// ResourceLimits returns the limits on the resources used by the statements of this session.
+func (d *DoltSession) ResourceLimits() ResourceLimits {
+	if l := d.resourceLimits.Load(); l != nil {
+		return *l
+	}
+	return ResourceLimits{}
+}
+
+// CountRowsWritten adds |n| to the rows written in the current transaction of this session. It returns an error if
+// the transaction has written more rows than the session is allowed to.
+func (d *DoltSession) CountRowsWritten(n uint64) error {
+	l := d.resourceLimits.Load()
+	if l == nil {
+		return nil
+	}
+	written := d.rowsWritten.Add(n)
+	if l.MaxRowsWrittenPerTransaction > 0 && written > l.MaxRowsWrittenPerTransaction {
+		d.resourceLimitExceeded.Store(true)
+		return fmt.Errorf("transaction has exceeded the resource limit max_rows_written_per_transaction (current value: %d)", l.MaxRowsWrittenPerTransaction)
+	}
+	return nil
+}
+
+// CountRowWritten counts a row written by the session of |ctx| towards its MaxRowsWrittenPerTransaction limit. See
+// CountRowsWritten.
+func CountRowWritten(ctx *sql.Context) error {
+	if sess, ok := ctx.Session.(*DoltSession); ok {
+		return sess.CountRowsWritten(1)
+	}
+	return nil
+}
+
+// ResourceLimitExceeded records that a statement of this session exceeded one of its resource limits.
+func (d *DoltSession) ResourceLimitExceeded() {
+	d.resourceLimitExceeded.Store(true)
+}
+
+// TakeResourceLimitExceeded returns whether a statement of this session exceeded one of its resource limits since
+// the last call, and resets it.
+func (d *DoltSession) TakeResourceLimitExceeded() bool {
+	return d.resourceLimitExceeded.Swap(false)
+}
@@ -21,6 +21,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -67,6 +68,19 @@ type DoltSession struct {
 	// If non-nil, this will be returned from ValidateSession.
 	// Used by sqle/cluster to put a session into a terminal err state.
 	validateErr error
+
+	// If non-nil, the rows read from tables by this session are
+	// added to this counter. Used by the slow query log of sql-server.
+	rowsExamined atomic.Pointer[atomic.Uint64]
+	// If non-nil, the plan of the next statement run by this session is
+	// recorded here. Used by the slow query log of sql-server.
+	queryPlan atomic.Pointer[queryPlanRecorder]
+
+	// The resource limits of this session, or nil if it has none, and
+	// the state used to enforce them. See resource_limits.go.
+	resourceLimits        atomic.Pointer[ResourceLimits]
+	resourceLimitExceeded atomic.Bool
+	rowsWritten           atomic.Uint64
 }
 
 var _ sql.Session = (*DoltSession)(nil)
@@ -124,6 +138,67 @@ func NewDoltSession(
 	return sess, nil
 }
 
+// TrackRowsExamined starts counting the rows read from tables by this session from zero if |track| is true, and stops
+// counting them if it is false.
+func (d *DoltSession) TrackRowsExamined(track bool) {
+	if track {
+		d.rowsExamined.Store(new(atomic.Uint64))
+	} else {
+		d.rowsExamined.Store(nil)
+	}
+}
+
+// RowsExaminedCounter returns the counter that table row iterators of this session should add the rows they read to,
+// or nil if rows examined are not being tracked.
+func (d *DoltSession) RowsExaminedCounter() *atomic.Uint64 {
+	return d.rowsExamined.Load()
+}
+
+// RowsExamined returns the number of rows read from tables since the last call to TrackRowsExamined(true).
+func (d *DoltSession) RowsExamined() uint64 {
+	if c := d.rowsExamined.Load(); c != nil {
+		return c.Load()
+	}
+	return 0
+}
+
+// queryPlanRecorder holds the plan of the statement a session is recording the plan of.
+type queryPlanRecorder struct {
+	once sync.Once
+	plan sql.Node
+}
+
+// TrackQueryPlan starts recording the plan of the next statement run by this session if |track| is true, and stops
+// recording it if it is false.
+func (d *DoltSession) TrackQueryPlan(track bool) {
+	if track {
+		d.queryPlan.Store(&queryPlanRecorder{})
+	} else {
+		d.queryPlan.Store(nil)
+	}
+}
+
+// RecordQueryPlan records |n|, the analyzed plan of a statement run by this session, if it is recording the plan of a
+// statement and none has been recorded yet. The plan of a statement is the first node built by the engine to run it.
+func (d *DoltSession) RecordQueryPlan(n sql.Node) {
+	if r := d.queryPlan.Load(); r != nil {
+		r.once.Do(func() {
+			r.plan = n
+		})
+	}
+}
+
+// QueryPlan returns the plan recorded since the last call to TrackQueryPlan(true), or nil if none was recorded. No
+// plan is recorded after it is called.
+func (d *DoltSession) QueryPlan() sql.Node {
+	r := d.queryPlan.Load()
+	if r == nil {
+		return nil
+	}
+	r.once.Do(func() {})
+	return r.plan
+}
+
 // Provider returns the RevisionDatabaseProvider for this session.
 func (d *DoltSession) Provider() DoltDatabaseProvider {
 	return d.provider
@@ -323,6 +398,8 @@ func (d *DoltSession) ValidateSession(ctx *sql.Context) error {
 
 // StartTransaction refreshes the state of this session and starts a new transaction.
 func (d *DoltSession) StartTransaction(ctx *sql.Context, tCharacteristic sql.TransactionCharacteristic) (sql.Transaction, error) {
+	d.rowsWritten.Store(0)
+
 	// TODO: this is only necessary to support filter-branch, which needs to set a root directly and not have the
 	//  session state altered when a transaction begins
 	if TransactionsDisabled(ctx) {
@@ -111,8 +111,14 @@ type DoltDatabaseProvider interface {
 	// PurgeDroppedDatabases permanently deletes any dropped databases that are being held in temporary storage
 	// in case they need to be restored. This operation is not reversible, so use with caution!
 	PurgeDroppedDatabases(ctx *sql.Context) error
+	// PreCommitHooks returns the hooks which dolt_commit calls before it commits a database.
+	PreCommitHooks() []PreCommitHook
 }
 
+// PreCommitHook is called by dolt_commit before it loads the roots of database |dbName|, so that it can make changes
+// to the working set for the commit to include.
+type PreCommitHook func(ctx *sql.Context, dbName string) error
+
 type SessionDatabaseBranchSpec struct {
 	RepoState env.RepoStateReadWriter
 	Branch    string
@@ -88,4 +88,11 @@ type IndexState struct {
 	IsSpatial     bool
 	PrefixLengths []uint16
 	Count         int
+	// Predicate is the expression rows must satisfy to be in a partial index, nil for other indexes.
+	Predicate sql.Expression
+	// Expressions are the expressions of the key fields of the index which are expressions rather than columns, and
+	// nil for the other fields. ExpressionTypes are the types of their values in the index. Both are nil for indexes
+	// without expressions.
+	Expressions     []sql.Expression
+	ExpressionTypes []sql.Type
 }
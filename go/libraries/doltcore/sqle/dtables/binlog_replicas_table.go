@@ -0,0 +1,167 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+// BinlogReplicaStatus describes a replica that is streaming binlog events from this server.
+type BinlogReplicaStatus struct {
+	// ReplicaUuid is the server UUID of the replica, or "" if the replica didn't report it.
+	ReplicaUuid string
+	Host        string
+	Port        uint16
+	ConnectedAt time.Time
+	// LastSentGtid is the GTID of the last transaction sent to the replica, or "" if none has been sent.
+	LastSentGtid string
+	BytesSent    uint64
+	// LastHeartbeat is the time the last heartbeat was sent to the replica, or the zero time if none has been sent.
+	LastHeartbeat time.Time
+	// Lag is how long the oldest binlog event that hasn't been sent to the replica has been waiting to be sent.
+	Lag time.Duration
+}
+
+// BinlogReplicaStatusProvider provides the status of the replicas streaming binlog events from this server.
+type BinlogReplicaStatusProvider interface {
+	BinlogReplicaStatuses() []BinlogReplicaStatus
+}
+
+var binlogReplicaStatusProvider BinlogReplicaStatusProvider
+var binlogReplicaStatusProviderMu = &sync.Mutex{}
+
+// RegisterBinlogReplicaStatusProvider registers |provider| as the source of the rows of the dolt_binlog_replicas
+// system table.
+func RegisterBinlogReplicaStatusProvider(provider BinlogReplicaStatusProvider) {
+	binlogReplicaStatusProviderMu.Lock()
+	defer binlogReplicaStatusProviderMu.Unlock()
+	binlogReplicaStatusProvider = provider
+}
+
+// BinlogReplicaStatuses returns the status of the replicas streaming binlog events from this server, or nil if
+// no BinlogReplicaStatusProvider has been registered.
+func BinlogReplicaStatuses() []BinlogReplicaStatus {
+	binlogReplicaStatusProviderMu.Lock()
+	provider := binlogReplicaStatusProvider
+	binlogReplicaStatusProviderMu.Unlock()
+
+	if provider == nil {
+		return nil
+	}
+	return provider.BinlogReplicaStatuses()
+}
+
+var _ sql.Table = (*BinlogReplicasTable)(nil)
+var _ sql.StatisticsTable = (*BinlogReplicasTable)(nil)
+
+// BinlogReplicasTable is a sql.Table implementation that implements a system table which shows the replicas
+// streaming binlog events from this server. The replicas are the same for every database.
+type BinlogReplicasTable struct{}
+
+// NewBinlogReplicasTable creates a BinlogReplicasTable
+func NewBinlogReplicasTable(_ *sql.Context) sql.Table {
+	return &BinlogReplicasTable{}
+}
+
+func (bt *BinlogReplicasTable) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(bt.Schema())
+	numRows, _, err := bt.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (bt *BinlogReplicasTable) RowCount(_ *sql.Context) (uint64, bool, error) {
+	return uint64(len(BinlogReplicaStatuses())), true, nil
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// BinlogReplicasTableName
+func (bt *BinlogReplicasTable) Name() string {
+	return doltdb.BinlogReplicasTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// BinlogReplicasTableName
+func (bt *BinlogReplicasTable) String() string {
+	return doltdb.BinlogReplicasTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the binlog replicas system table
+func (bt *BinlogReplicasTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "replica_uuid", Type: types.Text, Source: doltdb.BinlogReplicasTableName, PrimaryKey: false, Nullable: true},
+		{Name: "host", Type: types.Text, Source: doltdb.BinlogReplicasTableName, PrimaryKey: false, Nullable: false},
+		{Name: "port", Type: types.Uint16, Source: doltdb.BinlogReplicasTableName, PrimaryKey: false, Nullable: false},
+		{Name: "connected_at", Type: types.Datetime, Source: doltdb.BinlogReplicasTableName, PrimaryKey: false, Nullable: false},
+		{Name: "last_sent_gtid", Type: types.Text, Source: doltdb.BinlogReplicasTableName, PrimaryKey: false, Nullable: true},
+		{Name: "bytes_sent", Type: types.Uint64, Source: doltdb.BinlogReplicasTableName, PrimaryKey: false, Nullable: false},
+		{Name: "last_heartbeat", Type: types.Datetime, Source: doltdb.BinlogReplicasTableName, PrimaryKey: false, Nullable: true},
+		{Name: "lag_ms", Type: types.Int64, Source: doltdb.BinlogReplicasTableName, PrimaryKey: false, Nullable: false},
+	}
+}
+
+// Collation implements the sql.Table interface.
+func (bt *BinlogReplicasTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data. Currently the data is unpartitioned.
+func (bt *BinlogReplicasTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (bt *BinlogReplicasTable) PartitionRows(_ *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	statuses := BinlogReplicaStatuses()
+	rows := make([]sql.Row, len(statuses))
+	for i, status := range statuses {
+		rows[i] = binlogReplicaStatusRow(status)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func binlogReplicaStatusRow(status BinlogReplicaStatus) sql.Row {
+	var replicaUuid, lastSentGtid, lastHeartbeat interface{}
+	if status.ReplicaUuid != "" {
+		replicaUuid = status.ReplicaUuid
+	}
+	if status.LastSentGtid != "" {
+		lastSentGtid = status.LastSentGtid
+	}
+	if !status.LastHeartbeat.IsZero() {
+		lastHeartbeat = status.LastHeartbeat
+	}
+
+	return sql.Row{
+		replicaUuid,
+		status.Host,
+		status.Port,
+		status.ConnectedAt,
+		lastSentGtid,
+		status.BytesSent,
+		lastHeartbeat,
+		status.Lag.Milliseconds(),
+	}
+}
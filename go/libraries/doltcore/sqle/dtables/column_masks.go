@@ -0,0 +1,145 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/colmask"
+)
+
+// ColumnMasks returns the masks of the columns of the table |tableName| in the database |dbName| which apply to the
+// current user, by lowercase column name, or nil if the user reads the values of the columns unmasked. It is set by
+// package sqle, which stores the masking rules.
+var ColumnMasks = func(ctx *sql.Context, dbName, tableName string) (map[string]colmask.Mask, error) {
+	return nil, nil
+}
+
+// ColumnMasker masks the values of the columns of a table, or of the versions of a table in the rows of system tables,
+// for the current user. A nil ColumnMasker masks no values.
+type ColumnMasker struct {
+	masks map[string]colmask.Mask
+}
+
+// NewColumnMasker returns the masker of the columns of |tableName|, or nil if the current user reads them unmasked.
+func NewColumnMasker(ctx *sql.Context, dbName, tableName string) (*ColumnMasker, error) {
+	masks, err := ColumnMasks(ctx, dbName, tableName)
+	if err != nil || len(masks) == 0 {
+		return nil, err
+	}
+	return &ColumnMasker{masks: masks}, nil
+}
+
+// RowIter returns |iter|, which returns rows of |sch|, with the values of the masked columns of |sch| masked. If
+// |prefixes| are given, the columns of |sch| are the columns of the table with one of |prefixes|, as in the tables
+// which show several versions of the rows of the table.
+func (m *ColumnMasker) RowIter(sch sql.Schema, iter sql.RowIter, prefixes ...string) sql.RowIter {
+	if m == nil {
+		return iter
+	}
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	var masked []maskedColumn
+	for i, col := range sch {
+		name := strings.ToLower(col.Name)
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			if mask, ok := m.masks[name[len(prefix):]]; ok {
+				masked = append(masked, maskedColumn{idx: i, typ: col.Type, mask: mask})
+				break
+			}
+		}
+	}
+	if len(masked) == 0 {
+		return iter
+	}
+	return &columnMaskIter{child: iter, masked: masked}
+}
+
+// DiffRowIter returns |iter|, which returns rows of |sch|, the schema of a diff of the table, with the values of the
+// masked columns of both versions of the rows masked.
+func (m *ColumnMasker) DiffRowIter(sch sql.Schema, iter sql.RowIter) sql.RowIter {
+	return m.RowIter(sch, iter, diff.ToColNamer(""), diff.FromColNamer(""))
+}
+
+// ConflictsRowIter returns |iter|, which returns rows of |sch|, the schema of the conflicts of the table, with the
+// values of the masked columns of the base, our and their versions of the rows masked.
+func (m *ColumnMasker) ConflictsRowIter(sch sql.Schema, iter sql.RowIter) sql.RowIter {
+	return m.RowIter(sch, iter, "base_", "our_", "their_")
+}
+
+// MaskValue returns |v|, a value of |typ|, masked with |mask|. Strings are masked with the mask, and truncated to the
+// length of their type, and other values are replaced with the zero value of their type unless the mask is a null mask.
+func MaskValue(mask colmask.Mask, typ sql.Type, v interface{}) interface{} {
+	if v == nil || mask.Function == colmask.Null {
+		return nil
+	}
+	var masked string
+	switch v := v.(type) {
+	case string:
+		masked = mask.MaskString(v)
+	case []byte:
+		masked = mask.MaskString(string(v))
+	default:
+		return typ.Zero()
+	}
+	if st, ok := typ.(sql.StringType); ok && st.MaxCharacterLength() > 0 {
+		if runes := []rune(masked); int64(len(runes)) > st.MaxCharacterLength() {
+			masked = string(runes[:st.MaxCharacterLength()])
+		}
+	}
+	if _, ok := v.([]byte); ok {
+		return []byte(masked)
+	}
+	return masked
+}
+
+type maskedColumn struct {
+	idx  int
+	typ  sql.Type
+	mask colmask.Mask
+}
+
+type columnMaskIter struct {
+	child  sql.RowIter
+	masked []maskedColumn
+}
+
+var _ sql.RowIter = (*columnMaskIter)(nil)
+
+func (itr *columnMaskIter) Next(ctx *sql.Context) (sql.Row, error) {
+	row, err := itr.child.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	masked := make(sql.Row, len(row))
+	copy(masked, row)
+	for _, c := range itr.masked {
+		if c.idx < len(masked) {
+			masked[c.idx] = MaskValue(c.mask, c.typ, masked[c.idx])
+		}
+	}
+	return masked, nil
+}
+
+func (itr *columnMaskIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
@@ -302,5 +302,17 @@ func (dt *CommitDiffTable) rootValForHash(ctx *sql.Context, hashStr string) (dol
 
 func (dt *CommitDiffTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
 	dp := part.(DiffPartition)
-	return dp.GetRowIter(ctx, dt.ddb, dt.joiner, sql.IndexLookup{})
+	filter, err := NewRowPolicyFilter(ctx, dt.dbName, dt.name)
+	if err != nil {
+		return nil, err
+	}
+	masker, err := NewColumnMasker(ctx, dt.dbName, dt.name)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := dp.GetRowIter(ctx, dt.ddb, dt.joiner, sql.IndexLookup{})
+	if err != nil {
+		return nil, err
+	}
+	return masker.DiffRowIter(dt.Schema(), filter.DiffRowIter(dt.Schema(), iter)), nil
 }
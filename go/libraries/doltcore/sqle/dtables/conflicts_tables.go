@@ -28,7 +28,7 @@ import (
 )
 
 // NewConflictsTable returns a new ConflictsTable instance
-func NewConflictsTable(ctx *sql.Context, tblName string, srcTbl sql.Table, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+func NewConflictsTable(ctx *sql.Context, dbName, tblName string, srcTbl sql.Table, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	tbl, tblName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tblName})
 	if err != nil {
 		return nil, err
@@ -41,13 +41,13 @@ func NewConflictsTable(ctx *sql.Context, tblName string, srcTbl sql.Table, root
 		if !ok {
 			return nil, fmt.Errorf("%s can not have conflicts because it is not updateable", tblName)
 		}
-		return newProllyConflictsTable(ctx, tbl, upd, tblName, root, rs)
+		return newProllyConflictsTable(ctx, tbl, upd, dbName, tblName, root, rs)
 	}
 
-	return newNomsConflictsTable(ctx, tbl, tblName, root, rs)
+	return newNomsConflictsTable(ctx, tbl, dbName, tblName, root, rs)
 }
 
-func newNomsConflictsTable(ctx *sql.Context, tbl *doltdb.Table, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+func newNomsConflictsTable(ctx *sql.Context, tbl *doltdb.Table, dbName, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	rd, err := merge.NewConflictReader(ctx, tbl, tblName)
 	if err != nil {
 		return nil, err
@@ -60,6 +60,7 @@ func newNomsConflictsTable(ctx *sql.Context, tbl *doltdb.Table, tblName string,
 	}
 
 	return ConflictsTable{
+		dbName:  dbName,
 		tblName: tblName,
 		sqlSch:  sqlSch,
 		root:    root,
@@ -74,6 +75,7 @@ var _ sql.DeletableTable = ConflictsTable{}
 
 // ConflictsTable is a sql.Table implementation that provides access to the conflicts that exist for a user table
 type ConflictsTable struct {
+	dbName  string
 	tblName string
 	sqlSch  sql.PrimaryKeySchema
 	root    doltdb.RootValue
@@ -118,7 +120,11 @@ func (ct ConflictsTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sq
 	if err != nil {
 		return nil, err
 	}
-	return conflictRowIter{rd}, nil
+	masker, err := NewColumnMasker(ctx, ct.dbName, ct.tblName)
+	if err != nil {
+		return nil, err
+	}
+	return masker.ConflictsRowIter(ct.Schema(), conflictRowIter{rd}), nil
 }
 
 // Deleter returns a RowDeleter for this table. The RowDeleter will get one call to Delete for each row to be deleted,
@@ -36,7 +36,7 @@ import (
 	"github.com/dolthub/dolt/go/store/val"
 )
 
-func newProllyConflictsTable(ctx *sql.Context, tbl *doltdb.Table, sourceUpdatableTbl sql.UpdatableTable, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+func newProllyConflictsTable(ctx *sql.Context, tbl *doltdb.Table, sourceUpdatableTbl sql.UpdatableTable, dbName, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	arts, err := tbl.GetArtifacts(ctx)
 	if err != nil {
 		return nil, err
@@ -57,6 +57,7 @@ func newProllyConflictsTable(ctx *sql.Context, tbl *doltdb.Table, sourceUpdatabl
 	}
 
 	return ProllyConflictsTable{
+		dbName:          dbName,
 		tblName:         tblName,
 		sqlSch:          sqlSch,
 		baseSch:         baseSch,
@@ -74,6 +75,7 @@ func newProllyConflictsTable(ctx *sql.Context, tbl *doltdb.Table, sourceUpdatabl
 // ProllyConflictsTable is a sql.Table implementation that uses the merge
 // artifacts table to persist and read conflicts.
 type ProllyConflictsTable struct {
+	dbName                    string
 	tblName                   string
 	sqlSch                    sql.PrimaryKeySchema
 	baseSch, ourSch, theirSch schema.Schema
@@ -109,7 +111,15 @@ func (ct ProllyConflictsTable) Partitions(ctx *sql.Context) (sql.PartitionIter,
 }
 
 func (ct ProllyConflictsTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
-	return newProllyConflictRowIter(ctx, ct)
+	masker, err := NewColumnMasker(ctx, ct.dbName, ct.tblName)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := newProllyConflictRowIter(ctx, ct)
+	if err != nil {
+		return nil, err
+	}
+	return masker.ConflictsRowIter(ct.Schema(), iter), nil
 }
 
 func (ct ProllyConflictsTable) Updater(ctx *sql.Context) sql.RowUpdater {
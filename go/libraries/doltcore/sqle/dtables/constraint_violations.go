@@ -27,15 +27,15 @@ import (
 )
 
 // NewConstraintViolationsTable returns a sql.Table that lists constraint violations.
-func NewConstraintViolationsTable(ctx *sql.Context, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+func NewConstraintViolationsTable(ctx *sql.Context, dbName, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	if root.VRW().Format() == types.Format_DOLT {
-		return newProllyCVTable(ctx, tblName, root, rs)
+		return newProllyCVTable(ctx, dbName, tblName, root, rs)
 	}
 
-	return newNomsCVTable(ctx, tblName, root, rs)
+	return newNomsCVTable(ctx, dbName, tblName, root, rs)
 }
 
-func newNomsCVTable(ctx *sql.Context, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+func newNomsCVTable(ctx *sql.Context, dbName, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	tbl, tblName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tblName})
 	if err != nil {
 		return nil, err
@@ -52,6 +52,7 @@ func newNomsCVTable(ctx *sql.Context, tblName string, root doltdb.RootValue, rs
 	}
 
 	return &constraintViolationsTable{
+		dbName:  dbName,
 		tblName: tblName,
 		root:    root,
 		cvSch:   cvSch,
@@ -64,6 +65,7 @@ func newNomsCVTable(ctx *sql.Context, tblName string, root doltdb.RootValue, rs
 // constraintViolationsTable is a sql.Table implementation that provides access to the constraint violations that exist
 // for a user table for the old format.
 type constraintViolationsTable struct {
+	dbName  string
 	tblName string
 	root    doltdb.RootValue
 	cvSch   schema.Schema
@@ -110,7 +112,11 @@ func (cvt *constraintViolationsTable) PartitionRows(ctx *sql.Context, part sql.P
 	if err != nil {
 		return nil, err
 	}
-	return &constraintViolationsIter{cvt.cvSch, iter}, nil
+	masker, err := NewColumnMasker(ctx, cvt.dbName, cvt.tblName)
+	if err != nil {
+		return nil, err
+	}
+	return masker.RowIter(cvt.Schema(), &constraintViolationsIter{cvt.cvSch, iter}), nil
 }
 
 // Deleter implements the interface sql.DeletableTable.
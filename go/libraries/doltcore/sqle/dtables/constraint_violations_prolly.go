@@ -32,7 +32,7 @@ import (
 	"github.com/dolthub/dolt/go/store/val"
 )
 
-func newProllyCVTable(ctx *sql.Context, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
+func newProllyCVTable(ctx *sql.Context, dbName, tblName string, root doltdb.RootValue, rs RootSetter) (sql.Table, error) {
 	tbl, tblName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tblName})
 	if err != nil {
 		return nil, err
@@ -54,6 +54,7 @@ func newProllyCVTable(ctx *sql.Context, tblName string, root doltdb.RootValue, r
 	}
 	m := durable.ProllyMapFromArtifactIndex(arts)
 	return &prollyConstraintViolationsTable{
+		dbName:  dbName,
 		tblName: tblName,
 		root:    root,
 		sqlSch:  sqlSch,
@@ -66,6 +67,7 @@ func newProllyCVTable(ctx *sql.Context, tblName string, root doltdb.RootValue, r
 // prollyConstraintViolationsTable is a sql.Table implementation that provides access to the constraint violations that exist
 // for a user table for the v1 format.
 type prollyConstraintViolationsTable struct {
+	dbName  string
 	tblName string
 	root    doltdb.RootValue
 	sqlSch  sql.PrimaryKeySchema
@@ -123,13 +125,17 @@ func (cvt *prollyConstraintViolationsTable) PartitionRows(ctx *sql.Context, part
 	kd = kd.WithoutFixedAccess()
 	vd = vd.WithoutFixedAccess()
 
-	return prollyCVIter{
+	masker, err := NewColumnMasker(ctx, cvt.dbName, cvt.tblName)
+	if err != nil {
+		return nil, err
+	}
+	return masker.RowIter(cvt.Schema(), prollyCVIter{
 		itr: itr,
 		sch: sch,
 		kd:  kd,
 		vd:  vd,
 		ns:  cvt.artM.NodeStore(),
-	}, nil
+	}), nil
 }
 
 func (cvt *prollyConstraintViolationsTable) Deleter(context *sql.Context) sql.RowDeleter {
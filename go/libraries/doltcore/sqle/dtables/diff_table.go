@@ -60,6 +60,7 @@ var _ sql.StatisticsTable = (*DiffTable)(nil)
 
 type DiffTable struct {
 	name        string
+	dbName      string
 	ddb         *doltdb.DoltDB
 	workingRoot doltdb.RootValue
 	head        *doltdb.Commit
@@ -115,6 +116,7 @@ func NewDiffTable(ctx *sql.Context, dbName, tblName string, ddb *doltdb.DoltDB,
 
 	return &DiffTable{
 		name:             resolvedTableName.Name,
+		dbName:           dbName,
 		ddb:              ddb,
 		workingRoot:      root,
 		head:             head,
@@ -243,7 +245,19 @@ func (dt *DiffTable) HeadHash() (hash.Hash, error) {
 
 func (dt *DiffTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
 	dp := part.(DiffPartition)
-	return dp.GetRowIter(ctx, dt.ddb, dt.joiner, dt.lookup)
+	filter, err := NewRowPolicyFilter(ctx, dt.dbName, dt.name)
+	if err != nil {
+		return nil, err
+	}
+	masker, err := NewColumnMasker(ctx, dt.dbName, dt.name)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := dp.GetRowIter(ctx, dt.ddb, dt.joiner, dt.lookup)
+	if err != nil {
+		return nil, err
+	}
+	return masker.DiffRowIter(dt.Schema(), filter.DiffRowIter(dt.Schema(), iter)), nil
 }
 
 func (dt *DiffTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLookup) (sql.PartitionIter, error) {
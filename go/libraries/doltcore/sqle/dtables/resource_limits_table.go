@@ -0,0 +1,165 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+// SessionResourceLimits describes the resource limits of a connection to the server.
+type SessionResourceLimits struct {
+	ConnectionId uint32
+	User         string
+	Limits       dsess.ResourceLimits
+}
+
+// ResourceLimitsProvider provides the resource limits of the connections to the server which have any.
+type ResourceLimitsProvider interface {
+	SessionResourceLimits() []SessionResourceLimits
+}
+
+var resourceLimitsProvider ResourceLimitsProvider
+var resourceLimitsProviderMu = &sync.Mutex{}
+
+// RegisterResourceLimitsProvider registers |provider| as the source of the rows of the dolt_resource_limits system
+// table.
+func RegisterResourceLimitsProvider(provider ResourceLimitsProvider) {
+	resourceLimitsProviderMu.Lock()
+	defer resourceLimitsProviderMu.Unlock()
+	resourceLimitsProvider = provider
+}
+
+// AllSessionResourceLimits returns the resource limits of the connections to the server which have any, or nil if
+// no ResourceLimitsProvider has been registered.
+func AllSessionResourceLimits() []SessionResourceLimits {
+	resourceLimitsProviderMu.Lock()
+	provider := resourceLimitsProvider
+	resourceLimitsProviderMu.Unlock()
+
+	if provider == nil {
+		return nil
+	}
+	return provider.SessionResourceLimits()
+}
+
+var _ sql.Table = (*ResourceLimitsTable)(nil)
+var _ sql.StatisticsTable = (*ResourceLimitsTable)(nil)
+
+// ResourceLimitsTable is a sql.Table implementation that implements a system table which shows the resource limits
+// of the connections to the server. The connections are the same for every database.
+type ResourceLimitsTable struct{}
+
+// NewResourceLimitsTable creates a ResourceLimitsTable
+func NewResourceLimitsTable(_ *sql.Context) sql.Table {
+	return &ResourceLimitsTable{}
+}
+
+func (rt *ResourceLimitsTable) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(rt.Schema())
+	numRows, _, err := rt.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (rt *ResourceLimitsTable) RowCount(_ *sql.Context) (uint64, bool, error) {
+	return uint64(len(AllSessionResourceLimits())), true, nil
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// ResourceLimitsTableName
+func (rt *ResourceLimitsTable) Name() string {
+	return doltdb.ResourceLimitsTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// ResourceLimitsTableName
+func (rt *ResourceLimitsTable) String() string {
+	return doltdb.ResourceLimitsTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the resource limits system table
+func (rt *ResourceLimitsTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "connection_id", Type: types.Uint32, Source: doltdb.ResourceLimitsTableName, PrimaryKey: false, Nullable: false},
+		{Name: "user", Type: types.Text, Source: doltdb.ResourceLimitsTableName, PrimaryKey: false, Nullable: false},
+		{Name: "max_concurrent_queries", Type: types.Int64, Source: doltdb.ResourceLimitsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "max_execution_time_ms", Type: types.Int64, Source: doltdb.ResourceLimitsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "max_rows_returned", Type: types.Uint64, Source: doltdb.ResourceLimitsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "max_rows_written_per_transaction", Type: types.Uint64, Source: doltdb.ResourceLimitsTableName, PrimaryKey: false, Nullable: true},
+		{Name: "on_exceed", Type: types.Text, Source: doltdb.ResourceLimitsTableName, PrimaryKey: false, Nullable: false},
+	}
+}
+
+// Collation implements the sql.Table interface.
+func (rt *ResourceLimitsTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data. Currently the data is unpartitioned.
+func (rt *ResourceLimitsTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (rt *ResourceLimitsTable) PartitionRows(_ *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	sessions := AllSessionResourceLimits()
+	rows := make([]sql.Row, len(sessions))
+	for i, sess := range sessions {
+		rows[i] = sessionResourceLimitsRow(sess)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// sessionResourceLimitsRow returns the row for |sess|, in which the resources which are not limited are NULL.
+func sessionResourceLimitsRow(sess SessionResourceLimits) sql.Row {
+	var maxConcurrentQueries, maxExecutionTime, maxRowsReturned, maxRowsWritten interface{}
+	l := sess.Limits
+	if l.MaxConcurrentQueries > 0 {
+		maxConcurrentQueries = int64(l.MaxConcurrentQueries)
+	}
+	if l.MaxExecutionTime > 0 {
+		maxExecutionTime = l.MaxExecutionTime.Milliseconds()
+	}
+	if l.MaxRowsReturned > 0 {
+		maxRowsReturned = l.MaxRowsReturned
+	}
+	if l.MaxRowsWrittenPerTransaction > 0 {
+		maxRowsWritten = l.MaxRowsWrittenPerTransaction
+	}
+	onExceed := "error"
+	if l.KillOnExceed {
+		onExceed = "kill"
+	}
+
+	return sql.Row{
+		sess.ConnectionId,
+		sess.User,
+		maxConcurrentQueries,
+		maxExecutionTime,
+		maxRowsReturned,
+		maxRowsWritten,
+		onExceed,
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+)
+
+// RowPolicyPredicate returns the predicate of the row-level security policies of the table |tableName| in the database
+// |dbName| which the rows read by the current user must satisfy, along with the current schema of the table, which
+// the predicate is evaluated against. It returns a nil predicate if the user's reads of the table are unrestricted.
+// It is set by package sqle, which stores the policies.
+var RowPolicyPredicate = func(ctx *sql.Context, dbName, tableName string) (sql.Expression, sql.Schema, error) {
+	return nil, nil, nil
+}
+
+// RowPolicyFilter filters the rows of a table, or of a diff of a table, by the row-level security policies of the
+// table. A nil RowPolicyFilter allows every row.
+type RowPolicyFilter struct {
+	pred sql.Expression
+	sch  sql.Schema
+}
+
+// NewRowPolicyFilter returns the filter of the rows of |tableName| that the current user may read, or nil if the user
+// may read every row.
+func NewRowPolicyFilter(ctx *sql.Context, dbName, tableName string) (*RowPolicyFilter, error) {
+	pred, sch, err := RowPolicyPredicate(ctx, dbName, tableName)
+	if err != nil || pred == nil {
+		return nil, err
+	}
+	return &RowPolicyFilter{pred: pred, sch: sch}, nil
+}
+
+// RowIter returns |iter|, which returns rows of |sch|, without the rows that the filter does not allow. The columns
+// of |sch| are matched to the columns of the table by name.
+func (f *RowPolicyFilter) RowIter(sch sql.Schema, iter sql.RowIter) sql.RowIter {
+	if f == nil {
+		return iter
+	}
+	return &rowPolicyIter{
+		child:  iter,
+		filter: f,
+		to:     f.ordinals(sch, ""),
+	}
+}
+
+// DiffRowIter returns |iter|, which returns rows of |sch|, the schema of a diff of the table, without the rows whose
+// from or to version the filter does not allow.
+func (f *RowPolicyFilter) DiffRowIter(sch sql.Schema, iter sql.RowIter) sql.RowIter {
+	if f == nil {
+		return iter
+	}
+	return &rowPolicyIter{
+		child:       iter,
+		filter:      f,
+		to:          f.ordinals(sch, diff.ToColNamer("")),
+		from:        f.ordinals(sch, diff.FromColNamer("")),
+		diffTypeIdx: sch.IndexOfColName(diffTypeColName),
+		diff:        true,
+	}
+}
+
+// ordinals returns the indexes in |sch| of the columns of the table, prefixed with |prefix|, or -1 for the columns
+// that |sch| does not have.
+func (f *RowPolicyFilter) ordinals(sch sql.Schema, prefix string) []int {
+	ordinals := make([]int, len(f.sch))
+	for i, col := range f.sch {
+		ordinals[i] = -1
+		for j, c := range sch {
+			if strings.EqualFold(c.Name, prefix+col.Name) {
+				ordinals[i] = j
+				break
+			}
+		}
+	}
+	return ordinals
+}
+
+// allows returns whether the filter allows the version of the table row in |row| at |ordinals|.
+func (f *RowPolicyFilter) allows(ctx *sql.Context, row sql.Row, ordinals []int) (bool, error) {
+	tableRow := make(sql.Row, len(ordinals))
+	for i, j := range ordinals {
+		if j >= 0 {
+			tableRow[i] = row[j]
+		}
+	}
+	res, err := sql.EvaluateCondition(ctx, f.pred, tableRow)
+	if err != nil {
+		return false, err
+	}
+	return sql.IsTrue(res), nil
+}
+
+type rowPolicyIter struct {
+	child  sql.RowIter
+	filter *RowPolicyFilter
+	to     []int
+	// from, diffTypeIdx and diff are set for the rows of diffs
+	from        []int
+	diffTypeIdx int
+	diff        bool
+}
+
+var _ sql.RowIter = (*rowPolicyIter)(nil)
+
+func (itr *rowPolicyIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for {
+		row, err := itr.child.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := itr.allows(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return row, nil
+		}
+	}
+}
+
+func (itr *rowPolicyIter) allows(ctx *sql.Context, row sql.Row) (bool, error) {
+	if !itr.diff {
+		return itr.filter.allows(ctx, row, itr.to)
+	}
+
+	var diffType interface{}
+	if itr.diffTypeIdx >= 0 {
+		diffType = row[itr.diffTypeIdx]
+	}
+	if diffType != diffTypeRemoved {
+		if ok, err := itr.filter.allows(ctx, row, itr.to); err != nil || !ok {
+			return false, err
+		}
+	}
+	if diffType != diffTypeAdded {
+		if ok, err := itr.filter.allows(ctx, row, itr.from); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (itr *rowPolicyIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
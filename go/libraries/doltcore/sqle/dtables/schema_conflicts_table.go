@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/types"
@@ -227,7 +228,11 @@ func newSchemaConflict(ctx context.Context, table string, baseRoot doltdb.RootVa
 }
 
 func getCreateTableStatement(table string, sch schema.Schema, fks []doltdb.ForeignKey, parents map[string]schema.Schema) (string, error) {
-	return sqlfmt.GenerateCreateTableStatement(table, sch, fks, parents)
+	stmt, err := sqlfmt.GenerateCreateTableStatement(table, sch, fks, parents)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append([]string{stmt}, sqlfmt.CreateIndexStmts(table, sch)...), "\n"), nil
 }
 
 func getSchemaConflictDescription(ctx context.Context, table string, base, ours, theirs schema.Schema) (string, error) {
@@ -0,0 +1,186 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+// SlowQuery describes a statement which took longer than long_query_time to execute.
+type SlowQuery struct {
+	// Time is when the statement started executing.
+	Time         time.Time
+	ConnectionId uint32
+	User         string
+	Host         string
+	// Database is the current database of the session, or "" if there is none.
+	Database string
+	// Branch is the active branch of the session, or "" if there is none.
+	Branch       string
+	Query        string
+	QueryTime    time.Duration
+	RowsExamined uint64
+	RowsSent     uint64
+	RowsAffected uint64
+	// Plan is the plan the engine ran the statement with, or "" if it has none, such as for statements which are not
+	// selects or DML.
+	Plan string
+	// Error is the error returned by the statement, or "" if it succeeded.
+	Error string
+}
+
+// SlowQueryProvider provides the most recent slow queries recorded by the slow query log.
+type SlowQueryProvider interface {
+	SlowQueries() []SlowQuery
+}
+
+var slowQueryProvider SlowQueryProvider
+var slowQueryProviderMu = &sync.Mutex{}
+
+// RegisterSlowQueryProvider registers |provider| as the source of the rows of the dolt_slow_queries system table.
+func RegisterSlowQueryProvider(provider SlowQueryProvider) {
+	slowQueryProviderMu.Lock()
+	defer slowQueryProviderMu.Unlock()
+	slowQueryProvider = provider
+}
+
+// SlowQueries returns the most recent slow queries, oldest first, or nil if no SlowQueryProvider has been
+// registered.
+func SlowQueries() []SlowQuery {
+	slowQueryProviderMu.Lock()
+	provider := slowQueryProvider
+	slowQueryProviderMu.Unlock()
+
+	if provider == nil {
+		return nil
+	}
+	return provider.SlowQueries()
+}
+
+var _ sql.Table = (*SlowQueriesTable)(nil)
+var _ sql.StatisticsTable = (*SlowQueriesTable)(nil)
+
+// SlowQueriesTable is a sql.Table implementation that implements a system table which shows the most recent
+// statements recorded by the slow query log. The slow queries are the same for every database.
+type SlowQueriesTable struct{}
+
+// NewSlowQueriesTable creates a SlowQueriesTable
+func NewSlowQueriesTable(_ *sql.Context) sql.Table {
+	return &SlowQueriesTable{}
+}
+
+func (st *SlowQueriesTable) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(st.Schema())
+	numRows, _, err := st.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (st *SlowQueriesTable) RowCount(_ *sql.Context) (uint64, bool, error) {
+	return uint64(len(SlowQueries())), true, nil
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// SlowQueriesTableName
+func (st *SlowQueriesTable) Name() string {
+	return doltdb.SlowQueriesTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// SlowQueriesTableName
+func (st *SlowQueriesTable) String() string {
+	return doltdb.SlowQueriesTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the slow queries system table
+func (st *SlowQueriesTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "start_time", Type: types.Datetime, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "connection_id", Type: types.Uint32, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "user", Type: types.Text, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "host", Type: types.Text, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "database_name", Type: types.Text, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "branch", Type: types.Text, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "query", Type: types.LongText, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "query_time_ms", Type: types.Float64, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "rows_examined", Type: types.Uint64, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "rows_sent", Type: types.Uint64, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "rows_affected", Type: types.Uint64, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: false},
+		{Name: "plan", Type: types.LongText, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: true},
+		{Name: "error", Type: types.Text, Source: doltdb.SlowQueriesTableName, PrimaryKey: false, Nullable: true},
+	}
+}
+
+// Collation implements the sql.Table interface.
+func (st *SlowQueriesTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data. Currently the data is unpartitioned.
+func (st *SlowQueriesTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (st *SlowQueriesTable) PartitionRows(_ *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	queries := SlowQueries()
+	rows := make([]sql.Row, len(queries))
+	for i, query := range queries {
+		rows[i] = slowQueryRow(query)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func slowQueryRow(query SlowQuery) sql.Row {
+	var database, branch, plan, err interface{}
+	if query.Database != "" {
+		database = query.Database
+	}
+	if query.Branch != "" {
+		branch = query.Branch
+	}
+	if query.Plan != "" {
+		plan = query.Plan
+	}
+	if query.Error != "" {
+		err = query.Error
+	}
+
+	return sql.Row{
+		query.Time,
+		query.ConnectionId,
+		query.User,
+		query.Host,
+		database,
+		branch,
+		query.Query,
+		float64(query.QueryTime.Microseconds()) / 1000,
+		query.RowsExamined,
+		query.RowsSent,
+		query.RowsAffected,
+		plan,
+		err,
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dtables
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+)
+
+// TTLPurge describes the most recent purge of the expired rows of a table on a branch.
+type TTLPurge struct {
+	Database string
+	Branch   string
+	Table    string
+	// Time is when the purge started.
+	Time time.Time
+	// RowsPurged is the number of rows deleted by the purge.
+	RowsPurged uint64
+	// TotalRowsPurged is the number of rows deleted by all the purges of the table since the server started.
+	TotalRowsPurged uint64
+	// Commit is the hash of the last commit of the purge, which commits each batch of rows it deletes, or "" if no
+	// rows were purged.
+	Commit string
+	// Error is the error which stopped the purge, or "" if it succeeded.
+	Error string
+}
+
+// TTLStatusProvider provides the most recent purges of the TTL purger of the server.
+type TTLStatusProvider interface {
+	TTLPurges() []TTLPurge
+}
+
+var ttlStatusProvider TTLStatusProvider
+var ttlStatusProviderMu = &sync.Mutex{}
+
+// RegisterTTLStatusProvider registers |provider| as the source of the purges shown by the dolt_ttl_status system
+// table.
+func RegisterTTLStatusProvider(provider TTLStatusProvider) {
+	ttlStatusProviderMu.Lock()
+	defer ttlStatusProviderMu.Unlock()
+	ttlStatusProvider = provider
+}
+
+// TTLPurges returns the most recent purges of the tables with a TTL, or nil if no TTLStatusProvider has been
+// registered.
+func TTLPurges() []TTLPurge {
+	ttlStatusProviderMu.Lock()
+	provider := ttlStatusProvider
+	ttlStatusProviderMu.Unlock()
+
+	if provider == nil {
+		return nil
+	}
+	return provider.TTLPurges()
+}
+
+var _ sql.Table = (*TTLStatusTable)(nil)
+var _ sql.StatisticsTable = (*TTLStatusTable)(nil)
+
+// TTLStatusTable is a sql.Table implementation that implements a system table which shows the tables of a database
+// whose rows expire, along with the most recent purge of their expired rows on the current branch.
+type TTLStatusTable struct {
+	dbName string
+	branch string
+	root   doltdb.RootValue
+}
+
+// NewTTLStatusTable creates a TTLStatusTable for the tables of |root|, the root of the branch |branch| of the
+// database |dbName|.
+func NewTTLStatusTable(_ *sql.Context, dbName, branch string, root doltdb.RootValue) sql.Table {
+	return &TTLStatusTable{dbName: dbName, branch: branch, root: root}
+}
+
+func (tt *TTLStatusTable) DataLength(ctx *sql.Context) (uint64, error) {
+	numBytesPerRow := schema.SchemaAvgLength(tt.Schema())
+	numRows, _, err := tt.RowCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return numBytesPerRow * numRows, nil
+}
+
+func (tt *TTLStatusTable) RowCount(ctx *sql.Context) (uint64, bool, error) {
+	tables, err := tt.ttlTables(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(len(tables)), true, nil
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// TTLStatusTableName
+func (tt *TTLStatusTable) Name() string {
+	return doltdb.TTLStatusTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// TTLStatusTableName
+func (tt *TTLStatusTable) String() string {
+	return doltdb.TTLStatusTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the TTL status system table
+func (tt *TTLStatusTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "table_name", Type: types.Text, Source: doltdb.TTLStatusTableName, PrimaryKey: true, Nullable: false},
+		{Name: "ttl", Type: types.Text, Source: doltdb.TTLStatusTableName, PrimaryKey: false, Nullable: false},
+		{Name: "last_run", Type: types.Datetime, Source: doltdb.TTLStatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "rows_purged", Type: types.Uint64, Source: doltdb.TTLStatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "total_rows_purged", Type: types.Uint64, Source: doltdb.TTLStatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "last_commit", Type: types.Text, Source: doltdb.TTLStatusTableName, PrimaryKey: false, Nullable: true},
+		{Name: "error", Type: types.Text, Source: doltdb.TTLStatusTableName, PrimaryKey: false, Nullable: true},
+	}
+}
+
+// Collation implements the sql.Table interface.
+func (tt *TTLStatusTable) Collation() sql.CollationID {
+	return sql.Collation_Default
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data. Currently the data is unpartitioned.
+func (tt *TTLStatusTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return index.SinglePartitionIterFromNomsMap(nil), nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (tt *TTLStatusTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	tables, err := tt.ttlTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	purges := make(map[string]TTLPurge)
+	for _, p := range TTLPurges() {
+		if strings.EqualFold(p.Database, tt.dbName) && p.Branch == tt.branch {
+			purges[strings.ToLower(p.Table)] = p
+		}
+	}
+
+	rows := make([]sql.Row, len(tables))
+	for i, t := range tables {
+		rows[i] = sql.Row{t.name, t.ttl, nil, nil, nil, nil, nil}
+		if p, ok := purges[strings.ToLower(t.name)]; ok {
+			var commit, err interface{}
+			if p.Commit != "" {
+				commit = p.Commit
+			}
+			if p.Error != "" {
+				err = p.Error
+			}
+			rows[i] = sql.Row{t.name, t.ttl, p.Time, p.RowsPurged, p.TotalRowsPurged, commit, err}
+		}
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+type ttlTable struct {
+	name string
+	ttl  string
+}
+
+// ttlTables returns the tables of |tt.root| which have a TTL, ordered by name.
+func (tt *TTLStatusTable) ttlTables(ctx *sql.Context) ([]ttlTable, error) {
+	names, err := tt.root.GetTableNames(ctx, doltdb.DefaultSchemaName)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var tables []ttlTable
+	for _, name := range names {
+		tbl, ok, err := tt.root.GetTable(ctx, doltdb.TableName{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		sch, err := tbl.GetSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if sch.GetTTL() != "" {
+			tables = append(tables, ttlTable{name: name, ttl: sch.GetTTL()})
+		}
+	}
+	return tables, nil
+}
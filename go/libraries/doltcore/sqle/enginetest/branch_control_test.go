@@ -1364,7 +1364,12 @@ var BranchControlTests = []BranchControlTest{
 }
 
 func TestBranchControl(t *testing.T) {
-	for _, test := range BranchControlTests {
+	runBranchControlTests(t, BranchControlTests)
+}
+
+// runBranchControlTests runs |tests|, running the set up scripts as the root account and each assertion as its user.
+func runBranchControlTests(t *testing.T, tests []BranchControlTest) {
+	for _, test := range tests {
 		harness := newDoltHarness(t)
 		defer harness.Close()
 		t.Run(test.Name, func(t *testing.T) {
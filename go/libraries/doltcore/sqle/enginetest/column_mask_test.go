@@ -0,0 +1,289 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+)
+
+// ColumnMaskSetUpScript creates a table "customers" with two commits of rows, the masks of its columns, and a user
+// named "testuser@localhost" who may read and write the tables of the database and call its procedures, but does not
+// administer it.
+var ColumnMaskSetUpScript = []string{
+	"CREATE TABLE customers (id INT PRIMARY KEY, email VARCHAR(64), phone VARCHAR(16), balance INT, INDEX (email));",
+	"INSERT INTO customers VALUES (1, 'alice@example.com', '555-123-4567', 100), (2, 'bob@example.com', NULL, 200);",
+	"CALL DOLT_ADD('-A');",
+	"CALL DOLT_COMMIT('-m', 'first commit');",
+	"UPDATE customers SET email = 'alice@example.org' WHERE id = 1;",
+	"CALL DOLT_COMMIT('-am', 'second commit');",
+	"CALL dolt_create_mask('email', 'customers', 'email', \"partial(1, '***', 4)\");",
+	"CALL dolt_create_mask('phone', 'customers', 'PHONE', 'null', '--roles', 'testuser');",
+	"CALL dolt_create_mask('balance', 'customers', 'balance', 'full', '--roles', 'auditor');",
+	"CREATE USER testuser@localhost;",
+	"GRANT SELECT, INSERT, UPDATE, DELETE, EXECUTE ON mydb.* TO testuser@localhost;",
+}
+
+var ColumnMaskTests = []BranchControlTest{
+	{
+		Name:        "masks are stored in dolt_schemas",
+		SetUpScript: ColumnMaskSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				Query: "SELECT name, fragment FROM dolt_schemas WHERE type = 'mask' ORDER BY name;",
+				Expected: []sql.Row{
+					{"balance", `{"table":"customers","column":"balance","function":"full","roles":["auditor"]}`},
+					{"email", `{"table":"customers","column":"email","function":"partial","prefix":1,"padding":"***","suffix":4}`},
+					{"phone", `{"table":"customers","column":"phone","function":"null","roles":["testuser"]}`},
+				},
+			},
+			{
+				Query:          "CALL dolt_create_mask('email', 'customers', 'phone', 'full');",
+				ExpectedErrStr: "mask email already exists",
+			},
+			{
+				Query:       "CALL dolt_create_mask('m', 'missing', 'email', 'full');",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+			{
+				Query:       "CALL dolt_create_mask('m', 'customers', 'missing', 'full');",
+				ExpectedErr: sql.ErrColumnNotFound,
+			},
+			{
+				Query:          "CALL dolt_create_mask('m', 'customers', 'email', 'shuffle');",
+				ExpectedErrStr: "unknown mask: shuffle",
+			},
+			{
+				Query:    "CALL dolt_drop_mask('--if-exists', 'missing');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:          "CALL dolt_drop_mask('missing');",
+				ExpectedErrStr: "mask not found: missing",
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "DELETE FROM dolt_schemas WHERE type = 'mask';",
+				ExpectedErr: sql.ErrPrivilegeCheckFailed,
+			},
+		},
+	},
+	{
+		Name:        "reads are masked",
+		SetUpScript: ColumnMaskSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:  "testuser",
+				Host:  "localhost",
+				Query: "SELECT id, email, phone, balance FROM customers ORDER BY id;",
+				Expected: []sql.Row{
+					{1, "a***.org", nil, 100},
+					{2, "b***.com", nil, 200},
+				},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT email FROM customers WHERE id = 1;",
+				Expected: []sql.Row{{"a***.org"}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT email FROM customers AS OF 'HEAD~1' WHERE id = 1;",
+				Expected: []sql.Row{{"a***.com"}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT email FROM dolt_history_customers WHERE id = 1 ORDER BY email;",
+				Expected: []sql.Row{{"a***.com"}, {"a***.org"}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT from_email, to_email FROM dolt_diff_customers WHERE to_commit = HASHOF('HEAD');",
+				Expected: []sql.Row{{"a***.com", "a***.org"}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT from_email, to_email FROM dolt_diff('HEAD~1', 'HEAD', 'customers');",
+				Expected: []sql.Row{{"a***.com", "a***.org"}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT statement FROM dolt_patch('HEAD~1', 'HEAD', 'customers');",
+				Expected: []sql.Row{{"UPDATE `customers` SET `email`='a***.org' WHERE `id`=1;"}},
+			},
+			{
+				Query:    "SELECT email, phone FROM customers WHERE id = 1;",
+				Expected: []sql.Row{{"alice@example.org", "555-123-4567"}},
+			},
+		},
+	},
+	{
+		Name:        "masked rows are not written back",
+		SetUpScript: ColumnMaskSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "INSERT INTO customers VALUES (3, 'carol@example.com', NULL, 300);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "UPDATE customers SET balance = 0 WHERE id = 1;",
+				ExpectedErr: sqle.ErrMaskedWrite,
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "DELETE FROM customers WHERE id = 2;",
+				ExpectedErr: sqle.ErrMaskedWrite,
+			},
+			{
+				Query:    "SELECT id, email, balance FROM customers ORDER BY id;",
+				Expected: []sql.Row{{1, "alice@example.org", 100}, {2, "bob@example.com", 200}, {3, "carol@example.com", 300}},
+			},
+		},
+	},
+	{
+		Name:        "masked columns are filtered by their masked values",
+		SetUpScript: ColumnMaskSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM customers WHERE email = 'alice@example.org';",
+				Expected: []sql.Row{},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM customers WHERE email = 'a***.org';",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT a.id FROM customers a JOIN customers b ON a.email = b.email ORDER BY a.id;",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "SELECT id FROM customers WHERE email = 'alice@example.org';",
+				Expected: []sql.Row{{1}},
+			},
+		},
+	},
+	{
+		Name:        "masks of the default branch apply to other branches",
+		SetUpScript: ColumnMaskSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				// The masks are not committed, so the branch has none
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "CALL DOLT_BRANCH('old', 'HEAD');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT email FROM `mydb/old`.customers WHERE id = 1;",
+				Expected: []sql.Row{{"a***.org"}},
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_CHECKOUT('.');",
+				ExpectedErr: sqle.ErrRowPolicyRefChange,
+			},
+		},
+	},
+	{
+		Name:        "dropped masks no longer apply",
+		SetUpScript: ColumnMaskSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				Query:    "CALL dolt_drop_mask('email');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT email, phone FROM customers WHERE id = 1;",
+				Expected: []sql.Row{{"alice@example.org", nil}},
+			},
+			{
+				Query:    "CALL dolt_drop_mask('phone');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT email, phone FROM customers WHERE id = 1;",
+				Expected: []sql.Row{{"alice@example.org", "555-123-4567"}},
+			},
+		},
+	},
+	{
+		Name: "materialized views are refreshed from unmasked rows",
+		SetUpScript: append(ColumnMaskSetUpScript,
+			"CALL dolt_create_materialized_view('--auto-refresh', 'customer_emails', 'SELECT id, email FROM customers');",
+			"CALL DOLT_COMMIT('-Am', 'add customer_emails');",
+		),
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "INSERT INTO customers VALUES (3, 'carol@example.com', NULL, 300);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				// the commit refreshes customer_emails, which every user reads
+				User:  "testuser",
+				Host:  "localhost",
+				Query: "CALL DOLT_COMMIT('-am', 'add carol');",
+			},
+			{
+				Query:    "SELECT id, email FROM customer_emails ORDER BY id;",
+				Expected: []sql.Row{{1, "alice@example.org"}, {2, "bob@example.com"}, {3, "carol@example.com"}},
+			},
+			{
+				Query:    "SELECT count(*) FROM dolt_status;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:           "testuser",
+				Host:           "localhost",
+				Query:          "CALL dolt_create_materialized_view('emails', 'SELECT email FROM customers');",
+				ExpectedErrStr: "cannot create a materialized view of table customers, whose rows or columns are restricted for the current user",
+			},
+		},
+	},
+}
+
+func TestColumnMasks(t *testing.T) {
+	runBranchControlTests(t, ColumnMaskTests)
+}
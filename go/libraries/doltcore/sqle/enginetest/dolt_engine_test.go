@@ -1950,6 +1950,55 @@ func TestCreateDatabaseErrorCleansUp(t *testing.T) {
 // refresh is subject to -- namely reading/writing the stats objects in (1) DML statements
 // (2) auto refresh threads, and (3) manual ANALYZE statements.
 // todo: the dolt_stat functions should be concurrency tested
+// TestStatsAsOf checks that the statistics of a table read AS OF a commit are
+// those of the table at the commit, rather than those of the branch head.
+func TestStatsAsOf(t *testing.T) {
+	harness := newDoltHarness(t)
+	harness.Setup(setup.MydbData)
+	engine := mustNewEngine(t, harness)
+	defer engine.Close()
+
+	ctx := enginetest.NewSession(harness)
+	for _, q := range []string{
+		"create table xy (x int primary key, y int, key (y))",
+		"insert into xy values (0,0), (1,0), (2,1)",
+		"call dolt_commit('-Am', 'create xy')",
+		"insert into xy values (3,1), (4,2)",
+		"analyze table xy",
+	} {
+		enginetest.RunQueryWithContext(t, engine, harness, ctx, q)
+	}
+
+	statsProv := engine.EngineAnalyzer().Catalog.StatsProvider.(*statspro.Provider)
+	sqlDb, err := harness.provider.Database(ctx, "mydb")
+	require.NoError(t, err)
+
+	rowCounts := func(table sql.Table) map[string]uint64 {
+		stats, err := statsProv.GetTableStats(ctx, "mydb", table)
+		require.NoError(t, err)
+		ret := make(map[string]uint64)
+		for _, s := range stats {
+			ret[s.Qualifier().Index()] = s.RowCount()
+		}
+		return ret
+	}
+
+	head, ok, err := sqlDb.GetTableInsensitive(ctx, "xy")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]uint64{"primary": 5, "y": 5}, rowCounts(head))
+
+	asOf, ok, err := sqlDb.(sql.VersionedDatabase).GetTableInsensitiveAsOf(ctx, "xy", "HEAD")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]uint64{"primary": 3, "y": 3}, rowCounts(asOf))
+	// the statistics of the root are cached
+	require.Equal(t, map[string]uint64{"primary": 3, "y": 3}, rowCounts(asOf))
+
+	// the statistics of the branch are unchanged
+	require.Equal(t, map[string]uint64{"primary": 5, "y": 5}, rowCounts(head))
+}
+
 func TestStatsAutoRefreshConcurrency(t *testing.T) {
 	// create engine
 	harness := newDoltHarness(t)
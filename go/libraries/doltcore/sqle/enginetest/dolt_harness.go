@@ -253,6 +253,7 @@ func (d *DoltHarness) NewEngine(t *testing.T) (enginetest.QueryEngine, error) {
 			return nil, err
 		}
 		e.Analyzer.ExecBuilder = rowexec.NewOverrideBuilder(drowexec.Builder{})
+		e.Parser = sqle.NewParser(e.Parser)
 		d.engine = e
 
 		ctx := enginetest.NewContext(d)
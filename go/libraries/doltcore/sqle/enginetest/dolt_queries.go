@@ -873,6 +873,56 @@ var DoltScripts = []queries.ScriptTest{
 			},
 		},
 	},
+	{
+		Name: "dolt_cluster_wait_for_hash",
+		SetUpScript: []string{
+			"CREATE TABLE t (pk int primary key);",
+			"call dolt_commit('-Am', 'first commit');",
+			"SET @first = hashof('HEAD');",
+			"INSERT INTO t VALUES (1);",
+			"call dolt_commit('-am', 'second commit');",
+			"SET @second = hashof('HEAD');",
+			"call dolt_checkout('-b', 'other');",
+			"INSERT INTO t VALUES (2);",
+			"call dolt_commit('-am', 'third commit');",
+			"SET @third = hashof('HEAD');",
+			"INSERT INTO t VALUES (3);",
+			"call dolt_commit('-am', 'fourth commit');",
+			"SET @fourth = hashof('HEAD');",
+			"call dolt_reset('--hard', 'HEAD~1');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "SELECT dolt_cluster_wait_for_hash('mydb', @second);",
+				Expected: []sql.Row{{int8(0)}},
+			},
+			{
+				Query:    "SELECT dolt_cluster_wait_for_hash('mydb', @first, 1);",
+				Expected: []sql.Row{{int8(0)}},
+			},
+			{
+				Query:    "SELECT dolt_cluster_wait_for_hash('mydb', @third, 1);",
+				Expected: []sql.Row{{int8(0)}},
+			},
+			{
+				// The fourth commit is not on any branch.
+				Query:    "SELECT dolt_cluster_wait_for_hash('mydb', @fourth, 0.1);",
+				Expected: []sql.Row{{int8(1)}},
+			},
+			{
+				Query:    "SELECT dolt_cluster_wait_for_hash('mydb', '0123456789abcdefghijklmnopqrstuv', 0.1);",
+				Expected: []sql.Row{{int8(1)}},
+			},
+			{
+				Query:          "SELECT dolt_cluster_wait_for_hash('mydb', 'not a hash');",
+				ExpectedErrStr: "dolt_cluster_wait_for_hash: invalid commit hash 'not a hash'",
+			},
+			{
+				Query:       "SELECT dolt_cluster_wait_for_hash('nodb', @second, 1);",
+				ExpectedErr: sql.ErrDatabaseNotFound,
+			},
+		},
+	},
 	{
 		// https://github.com/dolthub/dolt/issues/7384
 		Name: "multiple unresolved foreign keys can be created on the same table",
@@ -964,6 +1014,219 @@ var DoltScripts = []queries.ScriptTest{
 			},
 		},
 	},
+	{
+		Name: "materialized views",
+		SetUpScript: []string{
+			"create table orders (id int primary key, customer varchar(20), total int)",
+			"insert into orders values (1, 'a', 10), (2, 'b', 20), (3, 'a', 30)",
+			"call dolt_create_materialized_view('open_orders', 'select id, customer, total from orders where total > 15')",
+			"call dolt_create_materialized_view('--auto-refresh', 'orders_by_customer', 'select customer, count(*) as n from orders group by customer')",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select * from open_orders order by id",
+				Expected: []sql.Row{{2, "b", 20}, {3, "a", 30}},
+			},
+			{
+				Query:    "select * from orders_by_customer order by customer",
+				Expected: []sql.Row{{"a", 2}, {"b", 1}},
+			},
+			{
+				Query:    "select type, name, fragment from dolt_schemas order by name",
+				Expected: []sql.Row{{"materialized_view", "open_orders", "select id, customer, total from orders where total > 15"}, {"materialized_view", "orders_by_customer", "select customer, count(*) as n from orders group by customer"}},
+			},
+			{
+				Query:    "update orders set total = 5 where id = 2",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "insert into orders values (4, 'c', 40)",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "delete from orders where id = 1",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from open_orders order by id",
+				Expected: []sql.Row{{2, "b", 20}, {3, "a", 30}},
+			},
+			{
+				Query:    "call dolt_refresh_materialized_view('open_orders')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select * from open_orders order by id",
+				Expected: []sql.Row{{3, "a", 30}, {4, "c", 40}},
+			},
+			{
+				Query:    "select * from orders_by_customer order by customer",
+				Expected: []sql.Row{{"a", 2}, {"b", 1}},
+			},
+			{
+				Query:            "call dolt_add('.')",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:            "call dolt_commit('-m', 'update orders')",
+				SkipResultsCheck: true,
+			},
+			{
+				Query:    "select * from orders_by_customer order by customer",
+				Expected: []sql.Row{{"a", 1}, {"b", 1}, {"c", 1}},
+			},
+			{
+				Query:    "select count(*) from dolt_status",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "call dolt_refresh_materialized_view('--all', '--full')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select count(*) from dolt_status where table_name = 'dolt_schemas'",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select * from open_orders order by id",
+				Expected: []sql.Row{{3, "a", 30}, {4, "c", 40}},
+			},
+			{
+				Query:    "call dolt_drop_materialized_view('open_orders')",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:       "select * from open_orders",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+			{
+				Query:          "call dolt_refresh_materialized_view('open_orders')",
+				ExpectedErrStr: "materialized view not found: open_orders",
+			},
+			{
+				Query:       "call dolt_create_materialized_view('orders', 'select * from orders')",
+				ExpectedErr: sql.ErrTableAlreadyExists,
+			},
+		},
+	},
+	{
+		Name: "materialized view statements",
+		SetUpScript: []string{
+			"create table orders (id int primary key, customer varchar(20), total int)",
+			"insert into orders values (1, 'a', 10), (2, 'b', 20), (3, 'a', 30)",
+			"create materialized view large_orders as select id, total from orders where total > 15",
+			"create materialized view orders_by_customer refresh on commit as select customer, count(*) as n from orders group by customer",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select type, name, fragment from dolt_schemas order by name",
+				Expected: []sql.Row{{"materialized_view", "large_orders", "select id, total from orders where total > 15"}, {"materialized_view", "orders_by_customer", "select customer, count(*) as n from orders group by customer"}},
+			},
+			{
+				Query:    "insert into orders values (4, 'c', 40)",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "refresh materialized view large_orders",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select * from large_orders order by id",
+				Expected: []sql.Row{{2, 20}, {3, 30}, {4, 40}},
+			},
+			{
+				Query:    "select * from orders_by_customer order by customer",
+				Expected: []sql.Row{{"a", 2}, {"b", 1}},
+			},
+			{
+				Query:    "refresh all materialized views full",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "select * from orders_by_customer order by customer",
+				Expected: []sql.Row{{"a", 2}, {"b", 1}, {"c", 1}},
+			},
+			{
+				Query:    "drop materialized view large_orders",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:       "select * from large_orders",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+			{
+				Query:          "refresh materialized view large_orders",
+				ExpectedErrStr: "materialized view not found: large_orders",
+			},
+		},
+	},
+	{
+		Name: "for system_time queries",
+		SetUpScript: []string{
+			"create table t (pk int primary key, c int)",
+			"insert into t values (1, 10), (2, 20)",
+			"call dolt_commit('-Am', 'one')",
+			"update t set c = 11 where pk = 1",
+			"call dolt_commit('-am', 'two')",
+			"delete from t where pk = 2",
+			"call dolt_commit('-am', 'three')",
+			"insert into t values (3, 30)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "select pk, c, row_end = '9999-12-31 23:59:59.999999' from t for system_time all order by pk, c",
+				Expected: []sql.Row{{1, 10, false}, {1, 11, true}, {2, 20, false}},
+			},
+			{
+				Query:    "select count(*) from t for system_time all as a join t for system_time all as b on a.pk = b.pk and a.row_end = b.row_start",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select pk, c from t for system_time as of now() order by pk",
+				Expected: []sql.Row{{1, 11}},
+			},
+			{
+				Query:    "select pk, c from t for system_time as of '2000-01-01'",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select pk, c from t for system_time between '2000-01-01' and now() order by pk, c",
+				Expected: []sql.Row{{1, 10}, {1, 11}, {2, 20}},
+			},
+			{
+				Query:    "select pk, c from t for system_time from '2000-01-01' to '2000-01-02'",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "select pk, c from t for system_time contained in ('2000-01-01', now()) order by pk",
+				Expected: []sql.Row{{1, 10}, {2, 20}},
+			},
+			{
+				Query:    "select a.pk, a.c from t for system_time all a join t on a.pk = t.pk where a.row_end < now()",
+				Expected: []sql.Row{{1, 10}},
+			},
+			{
+				Query:    "select * from t as of 'HEAD~1' order by pk",
+				Expected: []sql.Row{{1, 11}, {2, 20}},
+			},
+			{
+				Query:    "select * from t for system_time as of 'HEAD~1' order by pk",
+				Expected: []sql.Row{{1, 11}, {2, 20}},
+			},
+			{
+				Query:    "select * from t for system_time as of 'main' order by pk",
+				Expected: []sql.Row{{1, 11}},
+			},
+			{
+				Query:          "select * from t for system_time all use index (i)",
+				ExpectedErrStr: "FOR SYSTEM_TIME clause of table t cannot be combined with index hints or partitions",
+			},
+			{
+				Query:    "select * from t order by pk",
+				Expected: []sql.Row{{1, 11}, {3, 30}},
+			},
+		},
+	},
 	{
 		Name: "test null filtering in secondary indexes (https://github.com/dolthub/dolt/issues/4199)",
 		SetUpScript: []string{
@@ -1403,6 +1666,215 @@ var DoltScripts = []queries.ScriptTest{
 			},
 		},
 	},
+	{
+		Name: "partial indexes",
+		SetUpScript: []string{
+			"CREATE TABLE t (id INT PRIMARY KEY, a INT, deleted TINYINT);",
+			"INSERT INTO t VALUES (1, 10, 0), (2, 10, 1), (3, 20, 0);",
+			"CREATE INDEX a ON t (a);",
+			"CREATE UNIQUE INDEX live_a ON t (a, deleted) WHERE deleted = 0;",
+			"CREATE TABLE child (id INT PRIMARY KEY, a INT, FOREIGN KEY (a) REFERENCES t (a));",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:    "SELECT id FROM t WHERE a = 10 AND deleted = 0;",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "SELECT id FROM t WHERE a = 10 ORDER BY id;",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "SELECT id FROM t WHERE a = 10 AND deleted = 1;",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "INSERT INTO t VALUES (4, 10, 1);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:       "INSERT INTO t VALUES (5, 10, 0);",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:    "SELECT t1.id, t2.id FROM t t1 JOIN t t2 ON t1.a = t2.a WHERE t1.id = 2 ORDER BY t2.id;",
+				Expected: []sql.Row{{2, 1}, {2, 2}, {2, 4}},
+			},
+			{
+				Query:    "INSERT INTO child VALUES (1, 10);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:       "INSERT INTO child VALUES (2, 30);",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "UPDATE t SET deleted = 1 WHERE id = 1;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "INSERT INTO t VALUES (5, 10, 0);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "SELECT id FROM t WHERE a = 10 AND deleted = 0;",
+				Expected: []sql.Row{{5}},
+			},
+			{
+				Query:          "ALTER TABLE t RENAME COLUMN deleted TO removed;",
+				ExpectedErrStr: "the predicate deleted = 0 of index live_a depends on a column which is not in the table",
+			},
+			{
+				Query:          "ALTER TABLE t CHANGE COLUMN deleted removed TINYINT;",
+				ExpectedErrStr: "the predicate deleted = 0 of index live_a depends on a column which is not in the table",
+			},
+			{
+				Query:          "ALTER TABLE t DROP COLUMN deleted;",
+				ExpectedErrStr: "the predicate deleted = 0 of index live_a depends on a column which is not in the table",
+			},
+			{
+				Query:    "ALTER TABLE t MODIFY COLUMN deleted INT;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query:    "SELECT id FROM t WHERE a = 10 AND deleted = 0;",
+				Expected: []sql.Row{{5}},
+			},
+			{
+				Query:       "INSERT INTO t VALUES (6, 10, 0);",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:    "INSERT INTO t VALUES (6, 10, 1);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+		},
+	},
+	{
+		Name: "partial indexes on columns of their predicates which are not key parts",
+		SetUpScript: []string{
+			"CREATE TABLE users (id INT PRIMARY KEY, email VARCHAR(100), deleted_at DATETIME);",
+			"INSERT INTO users VALUES (1, 'ann@example.com', NULL), (2, 'ann@example.com', '2024-01-01'), (3, 'bob@example.com', NULL);",
+			"CREATE UNIQUE INDEX live_email ON users (email) WHERE deleted_at IS NULL;",
+			"CREATE TABLE logins (id INT PRIMARY KEY, email VARCHAR(100));",
+			"INSERT INTO logins VALUES (10, 'ann@example.com'), (11, 'bob@example.com'), (12, 'cat@example.com');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:           "SELECT id FROM users WHERE email = 'ann@example.com' AND deleted_at IS NULL;",
+				Expected:        []sql.Row{{1}},
+				ExpectedIndexes: []string{"live_email"},
+			},
+			{
+				Query:           "SELECT id FROM users WHERE email = 'ann@example.com';",
+				Expected:        []sql.Row{{1}, {2}},
+				ExpectedIndexes: []string{},
+			},
+			{
+				// the table has no full index on email, so the lookups of the join scan the table
+				Query:    "SELECT l.id, u.id FROM logins l JOIN users u ON l.email = u.email ORDER BY l.id, u.id;",
+				Expected: []sql.Row{{10, 1}, {10, 2}, {11, 3}},
+			},
+			{
+				Query:    "SELECT l.id, u.id FROM logins l JOIN users u ON l.email = u.email AND u.deleted_at IS NULL ORDER BY l.id;",
+				Expected: []sql.Row{{10, 1}, {11, 3}},
+			},
+			{
+				Query:           "SELECT id FROM users WHERE email = 'bob@example.com' AND deleted_at IS NULL;",
+				Expected:        []sql.Row{{3}},
+				ExpectedIndexes: []string{"live_email"},
+			},
+			{
+				Query:       "INSERT INTO users VALUES (4, 'bob@example.com', NULL);",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:    "INSERT INTO users VALUES (4, 'bob@example.com', '2024-02-01');",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:    "SELECT id FROM users WHERE email = 'bob@example.com' ORDER BY id;",
+				Expected: []sql.Row{{3}, {4}},
+			},
+		},
+	},
+	{
+		Name: "expression indexes",
+		SetUpScript: []string{
+			"CREATE TABLE users (id INT PRIMARY KEY, email VARCHAR(100), doc JSON);",
+			`INSERT INTO users VALUES (1, 'Ann@example.com', '{"sku": "a1"}'), (2, 'bob@example.com', '{"sku": "b2"}');`,
+			"CREATE UNIQUE INDEX email_ci ON users ((LOWER(email)));",
+			"CREATE INDEX sku ON users ((JSON_UNQUOTE(JSON_EXTRACT(doc, '$.sku'))));",
+			"CALL DOLT_COMMIT('-Am', 'create users');",
+			"CALL DOLT_CHECKOUT('-b', 'other');",
+			`INSERT INTO users VALUES (3, 'Cat@example.com', '{"sku": "c3"}');`,
+			"CALL DOLT_COMMIT('-am', 'add cat');",
+			"CALL DOLT_CHECKOUT('main');",
+			`INSERT INTO users VALUES (4, 'dan@example.com', '{"sku": "a1"}');`,
+			"CALL DOLT_COMMIT('-am', 'add dan');",
+			"CALL DOLT_MERGE('other');",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query:           "SELECT id FROM users WHERE LOWER(email) = 'ann@example.com';",
+				Expected:        []sql.Row{{1}},
+				ExpectedIndexes: []string{"email_ci"},
+			},
+			{
+				Query:           "SELECT id FROM users WHERE LOWER(email) = 'cat@example.com';",
+				Expected:        []sql.Row{{3}},
+				ExpectedIndexes: []string{"email_ci"},
+			},
+			{
+				Query:           "SELECT id FROM users WHERE LOWER(email) > 'c';",
+				Expected:        []sql.Row{{3}, {4}},
+				ExpectedIndexes: []string{"email_ci"},
+			},
+			{
+				Query:           "SELECT id FROM users WHERE email = 'Ann@example.com';",
+				Expected:        []sql.Row{{1}},
+				ExpectedIndexes: []string{},
+			},
+			{
+				Query:           "SELECT id FROM users WHERE JSON_UNQUOTE(JSON_EXTRACT(doc, '$.sku')) = 'a1';",
+				Expected:        []sql.Row{{1}, {4}},
+				ExpectedIndexes: []string{"sku"},
+			},
+			{
+				Query:    "SELECT u.id, v.id FROM users u JOIN users v ON LOWER(u.email) = LOWER(v.email) WHERE u.id = 3;",
+				Expected: []sql.Row{{3, 3}},
+			},
+			{
+				Query:       "INSERT INTO users VALUES (5, 'ANN@EXAMPLE.COM', NULL);",
+				ExpectedErr: sql.ErrUniqueKeyViolation,
+			},
+			{
+				Query:    "UPDATE users SET email = 'ann@example.org' WHERE id = 1;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				Query:    "INSERT INTO users VALUES (5, 'ANN@EXAMPLE.COM', NULL);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				Query:           "SELECT id FROM users WHERE LOWER(email) = 'ann@example.com';",
+				Expected:        []sql.Row{{5}},
+				ExpectedIndexes: []string{"email_ci"},
+			},
+			{
+				Query:          "CREATE INDEX doc ON users ((JSON_EXTRACT(doc, '$.sku')));",
+				ExpectedErrStr: "invalid expression for index doc: cannot index the json values of expression JSON_EXTRACT(doc, '$.sku')",
+			},
+			{
+				Query:          "ALTER TABLE users DROP COLUMN email;",
+				ExpectedErrStr: "the expression LOWER(email) of index email_ci depends on a column which is not in the table",
+			},
+			{
+				Query:          "ALTER TABLE users RENAME COLUMN email TO mail;",
+				ExpectedErrStr: "the expression LOWER(email) of index email_ci depends on a column which is not in the table",
+			},
+		},
+	},
 }
 
 func makeLargeInsert(sz int) string {
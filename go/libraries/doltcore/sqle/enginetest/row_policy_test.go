@@ -0,0 +1,312 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+)
+
+// RowPolicySetUpScript creates a table "docs" with two commits of rows, the policies of the table, and a user named
+// "testuser@localhost" who may read and write the tables of the database and call its procedures, but does not
+// administer it.
+var RowPolicySetUpScript = []string{
+	"CREATE TABLE docs (id INT PRIMARY KEY, owner VARCHAR(32), public TINYINT);",
+	"INSERT INTO docs VALUES (1, 'testuser', 0), (2, 'root', 0), (3, 'root', 1);",
+	"CALL DOLT_ADD('-A');",
+	"CALL DOLT_COMMIT('-m', 'first commit');",
+	"INSERT INTO docs VALUES (5, 'root', 0), (6, 'testuser', 0);",
+	"CALL DOLT_COMMIT('-am', 'second commit');",
+	"CREATE POLICY own ON docs TO testuser USING (owner = 'testuser');",
+	"CREATE POLICY public_read ON docs FOR SELECT USING (public = 1);",
+	"CREATE USER testuser@localhost;",
+	"GRANT SELECT, INSERT, UPDATE, DELETE, EXECUTE ON mydb.* TO testuser@localhost;",
+}
+
+var RowPolicyTests = []BranchControlTest{
+	{
+		Name:        "policies are stored in dolt_schemas",
+		SetUpScript: RowPolicySetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				Query: "SELECT name, fragment FROM dolt_schemas WHERE type = 'policy' ORDER BY name;",
+				Expected: []sql.Row{
+					{"own", "CREATE POLICY `own` ON `docs` FOR ALL TO `testuser` USING (owner = 'testuser')"},
+					{"public_read", "CREATE POLICY `public_read` ON `docs` FOR SELECT USING (public = 1)"},
+				},
+			},
+			{
+				Query:          "CREATE POLICY own ON docs USING (owner = 'root');",
+				ExpectedErrStr: "policy own already exists",
+			},
+			{
+				Query:       "CREATE POLICY p ON missing USING (a = 1);",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+			{
+				Query:    "DROP POLICY IF EXISTS missing;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:          "DROP POLICY own ON other;",
+				ExpectedErrStr: "policy own is not a policy of table other",
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "DELETE FROM dolt_schemas WHERE type = 'policy';",
+				ExpectedErr: sql.ErrPrivilegeCheckFailed,
+			},
+		},
+	},
+	{
+		Name:        "reads are filtered",
+		SetUpScript: RowPolicySetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM docs ORDER BY id;",
+				Expected: []sql.Row{{1}, {3}, {6}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT COUNT(*) FROM docs;",
+				Expected: []sql.Row{{3}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM docs WHERE id IN (2, 3);",
+				Expected: []sql.Row{{3}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT a.id FROM docs a JOIN docs b ON a.id = b.id ORDER BY a.id;",
+				Expected: []sql.Row{{1}, {3}, {6}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM docs AS OF 'HEAD~1' ORDER BY id;",
+				Expected: []sql.Row{{1}, {3}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM dolt_history_docs ORDER BY id;",
+				Expected: []sql.Row{{1}, {1}, {3}, {3}, {6}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT to_id FROM dolt_diff_docs ORDER BY to_id;",
+				Expected: []sql.Row{{1}, {3}, {6}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT to_id FROM dolt_diff('HEAD~2', 'HEAD', 'docs') ORDER BY to_id;",
+				Expected: []sql.Row{{1}, {3}, {6}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM dolt_blame_docs ORDER BY id;",
+				Expected: []sql.Row{{1}, {3}, {6}},
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "SELECT * FROM dolt_patch('HEAD~1', 'HEAD', 'docs');",
+				ExpectedErr: sqle.ErrRowPolicyPatch,
+			},
+			{
+				Query:    "SELECT id FROM docs ORDER BY id;",
+				Expected: []sql.Row{{1}, {2}, {3}, {5}, {6}},
+			},
+		},
+	},
+	{
+		Name:        "writes are checked",
+		SetUpScript: RowPolicySetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "INSERT INTO docs VALUES (7, 'root', 0);",
+				ExpectedErr: sqle.ErrRowPolicyCheck,
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "INSERT INTO docs VALUES (7, 'testuser', 0);",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "UPDATE docs SET public = 0 WHERE id = 3;",
+				ExpectedErr: sqle.ErrRowPolicyUsing,
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "UPDATE docs SET owner = 'root' WHERE id = 7;",
+				ExpectedErr: sqle.ErrRowPolicyCheck,
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "UPDATE docs SET public = 1 WHERE id = 7;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 1, Info: plan.UpdateInfo{Matched: 1, Updated: 1}}}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "UPDATE docs SET public = 1 WHERE id = 2;",
+				Expected: []sql.Row{{types.OkResult{RowsAffected: 0, Info: plan.UpdateInfo{Matched: 0, Updated: 0}}}},
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "DELETE FROM docs WHERE id = 3;",
+				ExpectedErr: sqle.ErrRowPolicyUsing,
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "DELETE FROM docs WHERE id = 2;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "DELETE FROM docs WHERE id = 7;",
+				Expected: []sql.Row{{types.NewOkResult(1)}},
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "TRUNCATE TABLE docs;",
+				ExpectedErr: sqle.ErrRowPolicyTruncate,
+			},
+			{
+				Query:    "SELECT id FROM docs ORDER BY id;",
+				Expected: []sql.Row{{1}, {2}, {3}, {5}, {6}},
+			},
+		},
+	},
+	{
+		Name:        "policies of the default branch apply to other branches",
+		SetUpScript: RowPolicySetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				// The policies are not committed, so the branch has none
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "CALL DOLT_BRANCH('old', 'HEAD');",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT COUNT(*) FROM `mydb/old`.dolt_schemas WHERE type = 'policy';",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM `mydb/old`.docs ORDER BY id;",
+				Expected: []sql.Row{{1}, {3}, {6}},
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_RESET('--hard');",
+				ExpectedErr: sqle.ErrRowPolicyRefChange,
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_CLEAN();",
+				ExpectedErr: sqle.ErrRowPolicyRefChange,
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_CHECKOUT('dolt_schemas');",
+				ExpectedErr: sqle.ErrRowPolicyRefChange,
+			},
+			{
+				User:        "testuser",
+				Host:        "localhost",
+				Query:       "CALL DOLT_BRANCH('-f', 'main', 'old');",
+				ExpectedErr: sqle.ErrRowPolicyRefChange,
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM docs ORDER BY id;",
+				Expected: []sql.Row{{1}, {3}, {6}},
+			},
+			{
+				Query:    "SELECT COUNT(*) FROM dolt_schemas WHERE type = 'policy';",
+				Expected: []sql.Row{{2}},
+			},
+		},
+	},
+	{
+		Name:        "dropped policies no longer apply",
+		SetUpScript: RowPolicySetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				Query:    "DROP POLICY own ON docs;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM docs ORDER BY id;",
+				Expected: []sql.Row{{3}},
+			},
+			{
+				Query:    "DROP POLICY public_read;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				User:     "testuser",
+				Host:     "localhost",
+				Query:    "SELECT id FROM docs ORDER BY id;",
+				Expected: []sql.Row{{1}, {2}, {3}, {5}, {6}},
+			},
+			{
+				Query:          "DROP POLICY public_read;",
+				ExpectedErrStr: "policy not found: public_read",
+			},
+		},
+	},
+}
+
+func TestRowPolicies(t *testing.T) {
+	runBranchControlTests(t, RowPolicyTests)
+}
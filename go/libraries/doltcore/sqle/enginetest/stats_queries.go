@@ -465,6 +465,100 @@ var DoltStatsIOTests = []queries.ScriptTest{
 			},
 		},
 	},
+	{
+		Name: "histograms of columns",
+		SetUpScript: []string{
+			"CREATE table xy (x bigint primary key, y int, z varchar(500), key(y,z));",
+			"insert into xy values (0,0,'a'), (1,0,'a'), (2,0,'b'), (3,0,'b'), (4,1,'c'), (5,2,'c')",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "analyze table xy update histogram on z, (y, z) with 2 buckets",
+				Expected: []sql.Row{
+					{"mydb.xy", "histogram", "status", "Histogram statistics created for column 'z'."},
+					{"mydb.xy", "histogram", "status", "Histogram statistics created for columns 'y', 'z'."},
+				},
+			},
+			{
+				Query: fmt.Sprintf("select index_name, columns, types, %s, %s, %s, %s from dolt_statistics where index_name = 'histogram(z)' order by %[3]s", schema.StatsRowCountColName, schema.StatsDistinctCountColName, schema.StatsUpperBoundColName, schema.StatsUpperBoundCntColName),
+				Expected: []sql.Row{
+					{"histogram(z)", "z", "varchar(500)", uint64(4), uint64(2), "b", uint64(2)},
+					{"histogram(z)", "z", "varchar(500)", uint64(2), uint64(1), "c", uint64(2)},
+				},
+			},
+			{
+				Query:    "select count(*) from dolt_statistics where index_name = 'histogram(y,z)'",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "analyze table xy update histogram on w",
+				Expected: []sql.Row{{"mydb.xy", "histogram", "Error", "The column 'w' does not exist."}},
+			},
+			{
+				Query:    "analyze table xy drop histogram on z",
+				Expected: []sql.Row{{"mydb.xy", "histogram", "status", "Histogram statistics removed for column 'z'."}},
+			},
+			{
+				Query:    "analyze table xy drop histogram on z",
+				Expected: []sql.Row{{"mydb.xy", "histogram", "Error", "No histogram statistics found for column 'z'."}},
+			},
+			{
+				Query:    "select distinct index_name from dolt_statistics",
+				Expected: []sql.Row{{"histogram(y,z)"}},
+			},
+			{
+				// ANALYZE TABLE rebuilds the histograms of the table
+				Query: "insert into xy values (6,3,'d')",
+			},
+			{
+				Query: "analyze table xy",
+			},
+			{
+				Query:    fmt.Sprintf("select sum(%s) from dolt_statistics where index_name = 'histogram(y,z)'", schema.StatsRowCountColName),
+				Expected: []sql.Row{{float64(7)}},
+			},
+		},
+	},
+	{
+		// the indexes of the table have no statistics of their own, so the
+		// planner costs them with the histograms of their columns
+		Name: "histograms in index estimates",
+		SetUpScript: []string{
+			"CREATE table t (id int primary key, a int, b int, key ia (a), key ib (b));",
+			"insert into t values (1,1,1), (2,0,0), (3,0,1), (4,0,1), (5,0,1), (6,0,1), (7,0,1), (8,0,1), (9,0,1), (10,0,1), (11,0,1), (12,0,1), (13,0,1), (14,0,1), (15,0,1), (16,0,1), (17,0,1), (18,0,1), (19,0,1), (20,0,1)",
+		},
+		Assertions: []queries.ScriptTestAssertion{
+			{
+				Query: "analyze table t update histogram on a, b",
+				Expected: []sql.Row{
+					{"mydb.t", "histogram", "status", "Histogram statistics created for column 'a'."},
+					{"mydb.t", "histogram", "status", "Histogram statistics created for column 'b'."},
+				},
+			},
+			{
+				Query:           "select id from t where a = 0 and b = 0",
+				Expected:        []sql.Row{{2}},
+				ExpectedIndexes: []string{"ib"},
+			},
+			{
+				// a = 0 becomes rare and b = 0 common, and the same filter
+				// uses the other index once the histograms are rebuilt
+				Query: "update t set a = 1 - a, b = 1 - b",
+			},
+			{
+				Query: "analyze table t update histogram on a, b",
+				Expected: []sql.Row{
+					{"mydb.t", "histogram", "status", "Histogram statistics created for column 'a'."},
+					{"mydb.t", "histogram", "status", "Histogram statistics created for column 'b'."},
+				},
+			},
+			{
+				Query:           "select id from t where a = 0 and b = 0",
+				Expected:        []sql.Row{{1}},
+				ExpectedIndexes: []string{"ia"},
+			},
+		},
+	},
 }
 
 var StatBranchTests = []queries.ScriptTest{
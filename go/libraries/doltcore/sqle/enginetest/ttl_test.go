@@ -0,0 +1,137 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+)
+
+// TTLSetUpScript creates a table "sessions" whose rows expire a day after they are created, and a table "events"
+// without a TTL.
+var TTLSetUpScript = []string{
+	"CREATE TABLE sessions (id INT PRIMARY KEY, created_at DATETIME, note TEXT) TTL = created_at + INTERVAL 1 DAY COMMENT 'logins';",
+	"CREATE TABLE events (id INT PRIMARY KEY, happened DATETIME, name TEXT);",
+	"CALL DOLT_ADD('-A');",
+	"CALL DOLT_COMMIT('-m', 'create tables');",
+}
+
+var TTLTests = []BranchControlTest{
+	{
+		Name:        "TTL of a new table",
+		SetUpScript: TTLSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				Query:    "SELECT table_name, ttl, last_run, rows_purged, last_commit FROM dolt_ttl_status;",
+				Expected: []sql.Row{{"sessions", "`created_at` + INTERVAL 1 DAY", nil, nil, nil}},
+			},
+			{
+				Query:    "SELECT table_comment FROM information_schema.tables WHERE table_name = 'sessions';",
+				Expected: []sql.Row{{"logins"}},
+			},
+			{
+				Query:          "CREATE TABLE bad (id INT PRIMARY KEY, name TEXT) TTL = name + INTERVAL 1 DAY;",
+				ExpectedErrStr: "the TTL column name must be a DATE, DATETIME or TIMESTAMP column, but is text",
+			},
+			{
+				Query:       "CREATE TABLE bad (id INT PRIMARY KEY) TTL = missing + INTERVAL 1 DAY;",
+				ExpectedErr: sql.ErrColumnNotFound,
+			},
+			{
+				Query:          "CREATE TABLE bad (id INT PRIMARY KEY, c DATETIME) TTL = c + INTERVAL 1 FORTNIGHT;",
+				ExpectedErrStr: "invalid TTL unit: FORTNIGHT",
+			},
+		},
+	},
+	{
+		Name:        "ALTER TABLE sets and removes TTLs",
+		SetUpScript: TTLSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				Query:    "ALTER TABLE events TTL = happened + INTERVAL 12 HOUR;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "ALTER TABLE sessions REMOVE TTL;",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "SELECT table_name, ttl FROM dolt_ttl_status;",
+				Expected: []sql.Row{{"events", "`happened` + INTERVAL 12 HOUR"}},
+			},
+			{
+				Query:    "SELECT table_name FROM dolt_status ORDER BY table_name;",
+				Expected: []sql.Row{{"events"}, {"sessions"}},
+			},
+			{
+				Query:       "ALTER TABLE missing TTL = c + INTERVAL 1 DAY;",
+				ExpectedErr: sql.ErrTableNotFound,
+			},
+		},
+	},
+	{
+		Name:        "TTL column changes",
+		SetUpScript: TTLSetUpScript,
+		Assertions: []BranchControlTestAssertion{
+			{
+				Query:          "ALTER TABLE sessions DROP COLUMN created_at;",
+				ExpectedErrStr: "the column created_at cannot be dropped, because it is the TTL column of its table",
+			},
+			{
+				Query:          "ALTER TABLE sessions MODIFY COLUMN created_at INT;",
+				ExpectedErrStr: "the TTL column created_at must be a DATE, DATETIME or TIMESTAMP column, but is int",
+			},
+			{
+				Query:    "ALTER TABLE sessions RENAME COLUMN created_at TO started_at;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query:    "ALTER TABLE sessions DROP COLUMN note;",
+				Expected: []sql.Row{{types.NewOkResult(0)}},
+			},
+			{
+				Query:    "SELECT table_name, ttl FROM dolt_ttl_status;",
+				Expected: []sql.Row{{"sessions", "`started_at` + INTERVAL 1 DAY"}},
+			},
+		},
+	},
+	{
+		Name: "TTLs are merged",
+		SetUpScript: append(append([]string{}, TTLSetUpScript...),
+			"CALL DOLT_CHECKOUT('-b', 'other');",
+			"ALTER TABLE events TTL = happened + INTERVAL 1 WEEK;",
+			"CALL DOLT_COMMIT('-am', 'events expire');",
+			"CALL DOLT_CHECKOUT('main');",
+			"ALTER TABLE sessions TTL = created_at + INTERVAL 2 DAY;",
+			"CALL DOLT_COMMIT('-am', 'sessions expire later');",
+			"CALL DOLT_MERGE('other');",
+		),
+		Assertions: []BranchControlTestAssertion{
+			{
+				Query: "SELECT table_name, ttl FROM dolt_ttl_status;",
+				Expected: []sql.Row{
+					{"events", "`happened` + INTERVAL 1 WEEK"},
+					{"sessions", "`created_at` + INTERVAL 2 DAY"},
+				},
+			},
+		},
+	},
+}
+
+func TestTTL(t *testing.T) {
+	runBranchControlTests(t, TTLTests)
+}
@@ -71,6 +71,40 @@ func ResolveCheckExpression(ctx *sql.Context, tableName string, sch schema.Schem
 	return nil, fmt.Errorf("unable to find check expression")
 }
 
+// ResolvePredicateExpression returns a sql.Expression for |predicate|, a boolean expression over the columns of |sch|,
+// such as the predicate of a partial index. The expression is evaluated against rows of the full schema.
+func ResolvePredicateExpression(ctx *sql.Context, tableName string, sch schema.Schema, predicate string) (sql.Expression, error) {
+	return resolveExpression(ctx, tableName, sch, predicate)
+}
+
+// ResolveIndexExpression returns a sql.Expression for |expr|, an expression over the columns of |sch| which is a key
+// part of an index. The expression is evaluated against rows of the full schema.
+func ResolveIndexExpression(ctx *sql.Context, tableName string, sch schema.Schema, expr string) (sql.Expression, error) {
+	return resolveExpression(ctx, tableName, sch, expr)
+}
+
+// resolveExpression resolves |expr| over the columns of |sch| as the expression of a check constraint, which is not
+// added to the indexes or checks of |sch|.
+func resolveExpression(ctx *sql.Context, tableName string, sch schema.Schema, expr string) (sql.Expression, error) {
+	checks := schema.NewCheckCollection()
+	if _, err := checks.AddCheck("expression", expr, true); err != nil {
+		return nil, err
+	}
+	exprSch, err := schema.NewSchema(sch.GetAllCols(), sch.GetPkOrdinals(), sch.GetCollation(), nil, checks)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := parseCreateTable(ctx, tableName, exprSch)
+	if err != nil {
+		return nil, err
+	}
+	if len(ct.Checks()) != 1 {
+		return nil, fmt.Errorf("unable to resolve expression: %s", expr)
+	}
+	return ct.Checks()[0].Expr, nil
+}
+
 func stripTableNamesFromExpression(expr sql.Expression) sql.Expression {
 	e, _, _ := transform.Expr(expr, func(e sql.Expression) (sql.Expression, transform.TreeIdentity, error) {
 		if col, ok := e.(*expression.GetField); ok {
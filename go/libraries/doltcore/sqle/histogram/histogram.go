@@ -0,0 +1,203 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package histogram parses the ANALYZE TABLE statements which build and drop
+// the histograms of the columns of a table:
+//
+//	ANALYZE TABLE t UPDATE HISTOGRAM ON a, b WITH 32 BUCKETS
+//	ANALYZE TABLE t UPDATE HISTOGRAM ON (a, b)
+//	ANALYZE TABLE t DROP HISTOGRAM ON a
+//
+// Each column builds its own histogram, and a parenthesized list of columns
+// builds a single histogram of their combined values, whose distinct count is
+// that of the combinations of the values of the columns. The parser only
+// supports the statements which load a histogram from JSON, so the statements
+// are rewritten into calls of the stored procedures which run them.
+package histogram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlscan"
+)
+
+const (
+	// UpdateProcedure is the stored procedure which builds the histograms of the columns of a table:
+	//
+	//	CALL dolt_stats_update_histogram('table', 'buckets', 'column' [, 'column,column' ...])
+	//
+	// Each argument after the number of buckets is a histogram, of one or more columns separated by commas. A
+	// number of buckets of 0 is the default number.
+	UpdateProcedure = "dolt_stats_update_histogram"
+	// DropProcedure is the stored procedure which drops the histograms of the columns of a table:
+	//
+	//	CALL dolt_stats_drop_histogram('table', 'column' [, 'column,column' ...])
+	DropProcedure = "dolt_stats_drop_histogram"
+)
+
+// MaxBuckets is the largest number of buckets of a histogram.
+const MaxBuckets = 1024
+
+// Statement is an ANALYZE TABLE statement which builds or drops the histograms of the columns of a table.
+type Statement struct {
+	Table string
+	// Columns are the histograms of the statement. Each is a single column, or the columns of a multi-column
+	// histogram.
+	Columns [][]string
+	// Buckets is the number of buckets of the histograms the statement builds, or 0 for the default number.
+	Buckets int
+	// Drop is whether the statement drops the histograms rather than building them.
+	Drop bool
+}
+
+// Parse parses |query| if it is an ANALYZE TABLE statement which builds or drops the histograms of the columns of a
+// table, and returns it along with the index of the end of the statement in |query|, past its terminating semicolon.
+// Parse returns nil for other statements, including the ANALYZE TABLE ... UPDATE HISTOGRAM ... USING DATA statements
+// which load a histogram from JSON.
+func Parse(query string) (*Statement, int, error) {
+	s := &scanner{sqlscan.New(query)}
+	if !s.Keywords("analyze") {
+		return nil, 0, nil
+	}
+	if !s.Keywords("no_write_to_binlog") {
+		s.Keywords("local")
+	}
+	if !s.Keywords("table") {
+		return nil, 0, nil
+	}
+	table, err := s.Ident()
+	if err != nil || s.Peek() == '.' || s.Peek() == ',' {
+		return nil, 0, nil
+	}
+
+	stmt := &Statement{Table: table}
+	switch {
+	case s.Keywords("update", "histogram", "on"):
+	case s.Keywords("drop", "histogram", "on"):
+		stmt.Drop = true
+	default:
+		return nil, 0, nil
+	}
+
+	for {
+		cols, err := s.columns()
+		if err != nil {
+			return nil, 0, err
+		}
+		stmt.Columns = append(stmt.Columns, cols)
+		if s.Peek() != ',' {
+			break
+		}
+		s.Pos++
+	}
+
+	if !stmt.Drop {
+		if s.Keywords("using") {
+			// the parser loads histograms from JSON
+			return nil, 0, nil
+		}
+		if s.Keywords("with") {
+			n := s.Word()
+			if stmt.Buckets, err = strconv.Atoi(n); err != nil || stmt.Buckets < 1 || stmt.Buckets > MaxBuckets {
+				return nil, 0, fmt.Errorf("the number of buckets must be between 1 and %d, but is %s", MaxBuckets, n)
+			}
+			if !s.Keywords("buckets") {
+				return nil, 0, s.Errorf("expected BUCKETS")
+			}
+		}
+	}
+
+	end, err := s.End()
+	if err != nil {
+		return nil, 0, err
+	}
+	return stmt, end, nil
+}
+
+// Call returns the CALL statement of the stored procedure which runs |stmt|.
+func (stmt *Statement) Call() *sqlparser.Call {
+	params := []sqlparser.Expr{sqlparser.NewStrVal([]byte(stmt.Table))}
+	proc := UpdateProcedure
+	if stmt.Drop {
+		proc = DropProcedure
+	} else {
+		params = append(params, sqlparser.NewStrVal([]byte(strconv.Itoa(stmt.Buckets))))
+	}
+	for _, cols := range stmt.Columns {
+		params = append(params, sqlparser.NewStrVal([]byte(strings.Join(cols, ","))))
+	}
+	return &sqlparser.Call{
+		ProcName: sqlparser.ProcedureName{Name: sqlparser.NewColIdent(proc)},
+		Params:   params,
+	}
+}
+
+// SplitColumns returns the columns of a histogram, as they are given to the stored procedures.
+func SplitColumns(arg string) []string {
+	cols := strings.Split(arg, ",")
+	for i := range cols {
+		cols[i] = strings.TrimSpace(cols[i])
+	}
+	return cols
+}
+
+// columns scans a column, or a parenthesized list of columns.
+func (s *scanner) columns() ([]string, error) {
+	if s.Peek() != '(' {
+		col, err := s.column()
+		if err != nil {
+			return nil, err
+		}
+		return []string{col}, nil
+	}
+	s.Pos++
+	var cols []string
+	for {
+		col, err := s.column()
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+		switch s.Peek() {
+		case ',':
+			s.Pos++
+		case ')':
+			s.Pos++
+			return cols, nil
+		default:
+			return nil, s.Errorf("expected , or )")
+		}
+	}
+}
+
+// column scans the name of a column, which cannot contain a comma.
+func (s *scanner) column() (string, error) {
+	col, err := s.Ident()
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsRune(col, ',') {
+		return "", fmt.Errorf("histograms are not supported on the column %s, whose name contains a comma", col)
+	}
+	return col, nil
+}
+
+// scanner scans the tokens of a statement.
+type scanner struct {
+	*sqlscan.Scanner
+}
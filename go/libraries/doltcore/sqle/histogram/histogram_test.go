@@ -0,0 +1,96 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package histogram
+
+import (
+	"testing"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *Statement
+		end      int
+		err      bool
+	}{
+		{
+			query: "analyze table t",
+		},
+		{
+			query: "analyze table t, s",
+		},
+		{
+			query: "analyze table db.t update histogram on c",
+		},
+		{
+			query: "analyze table t update histogram on (c) using data '{}'",
+		},
+		{
+			query:    "ANALYZE TABLE t UPDATE HISTOGRAM ON c",
+			expected: &Statement{Table: "t", Columns: [][]string{{"c"}}},
+			end:      37,
+		},
+		{
+			query:    "analyze local table `t` update histogram on a, (b, `c d`) with 16 buckets; select 1",
+			expected: &Statement{Table: "t", Columns: [][]string{{"a"}, {"b", "c d"}}, Buckets: 16},
+			end:      74,
+		},
+		{
+			query:    "analyze table t drop histogram on (y,z)",
+			expected: &Statement{Table: "t", Columns: [][]string{{"y", "z"}}, Drop: true},
+			end:      39,
+		},
+		{
+			query: "analyze table t update histogram on c with 0 buckets",
+			err:   true,
+		},
+		{
+			query: "analyze table t update histogram on c with 2000 buckets",
+			err:   true,
+		},
+		{
+			query: "analyze table t update histogram on (a, b",
+			err:   true,
+		},
+		{
+			query: "analyze table t update histogram on `a,b`",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			actual, end, err := Parse(tt.query)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	stmt := &Statement{Table: "t", Columns: [][]string{{"a"}, {"b", "c"}}, Buckets: 8}
+	assert.Equal(t, "call dolt_stats_update_histogram('t', '8', 'a', 'b,c')", sqlparser.String(stmt.Call()))
+	stmt.Drop = true
+	assert.Equal(t, "call dolt_stats_drop_histogram('t', 'a', 'b,c')", sqlparser.String(stmt.Call()))
+	assert.Equal(t, []string{"b", "c"}, SplitColumns("b, c"))
+}
@@ -253,6 +253,7 @@ func DoltIndexesFromTable(ctx context.Context, db, tbl string, t *doltdb.Table)
 		}
 		indexes = append(indexes, idx)
 	}
+	setFullIndexes(indexes)
 
 	return indexes, nil
 }
@@ -374,7 +375,7 @@ func getSecondaryIndex(ctx context.Context, db, tbl string, t *doltdb.Table, sch
 	}
 	vrw := t.ValueReadWriter()
 
-	return &doltIndex{
+	di := &doltIndex{
 		id:                            idx.Name(),
 		tblName:                       tbl,
 		dbName:                        db,
@@ -394,7 +395,16 @@ func getSecondaryIndex(ctx context.Context, db, tbl string, t *doltdb.Table, sch
 		doltBinFormat:                 types.IsFormat_DOLT(vrw.Format()),
 		prefixLengths:                 idx.PrefixLengths(),
 		fullTextProps:                 idx.FullTextProperties(),
-	}, nil
+	}
+	if idx.Predicate() != "" {
+		newPartialIndex(ctx, di, idx)
+	}
+	if len(idx.Expressions()) > 0 {
+		if err = setKeyExpressions(ctx, di, idx); err != nil {
+			return nil, err
+		}
+	}
+	return di, nil
 }
 
 // ConvertFullTextToSql converts a given Full-Text schema.Index into a sql.Index. As we do not need to write to a
@@ -561,35 +571,49 @@ type doltIndex struct {
 
 	prefixLengths []uint16
 	fullTextProps schema.FullTextProperties
+
+	// partial is the state of a partial index, nil for other indexes
+	partial *partialIndex
+	// expressions maps the tags of the key parts of the index which are expressions to the expressions, as the
+	// engine prints the expressions of filters on the table
+	expressions map[uint64]string
 }
 
 var _ DoltIndex = (*doltIndex)(nil)
 var _ sql.ExtendedIndex = (*doltIndex)(nil)
 
-// CanSupport implements sql.Index
-func (di *doltIndex) CanSupport(...sql.Range) bool {
+// CanSupport implements sql.Index. A partial index only supports lookups of rows which satisfy its predicate.
+func (di *doltIndex) CanSupport(ranges ...sql.Range) bool {
+	if di.isPartial() {
+		return di.partial.supports(ranges)
+	}
 	return true
 }
 
 // ColumnExpressionTypes implements the interface sql.Index.
 func (di *doltIndex) ColumnExpressionTypes() []sql.ColumnExpressionType {
-	cets := make([]sql.ColumnExpressionType, len(di.columns))
-	for i, col := range di.columns {
+	keyCols := di.keyColumns()
+	cets := make([]sql.ColumnExpressionType, len(keyCols))
+	for i, col := range keyCols {
 		cets[i] = sql.ColumnExpressionType{
-			Expression: di.tblName + "." + col.Name,
+			Expression: di.keyPartExpression(col),
 			Type:       col.TypeInfo.ToSqlType(),
 		}
 	}
 	return cets
 }
 
-// ExtendedColumnExpressionTypes implements the interface sql.ExtendedIndex.
+// ExtendedColumnExpressionTypes implements the interface sql.ExtendedIndex. The key parts of a partial index are not
+// extended by the primary key, as they may end with the columns of its predicate.
 func (di *doltIndex) ExtendedColumnExpressionTypes() []sql.ColumnExpressionType {
+	if di.isPartial() {
+		return di.ColumnExpressionTypes()
+	}
 	pkCols := di.indexSch.GetPKCols()
 	cets := make([]sql.ColumnExpressionType, 0, len(pkCols.Tags))
 	_ = pkCols.Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
 		cets = append(cets, sql.ColumnExpressionType{
-			Expression: di.tblName + "." + col.Name,
+			Expression: di.keyPartExpression(col),
 			Type:       col.TypeInfo.ToSqlType(),
 		})
 		return false, nil
@@ -660,7 +684,7 @@ func (di *doltIndex) prollyRanges(ctx *sql.Context, ns tree.NodeStore, ranges ..
 			return nil, err
 		}
 	}
-	pranges, err := di.prollyRangesFromSqlRanges(ctx, ns, ranges, di.keyBld)
+	pranges, err := di.prollyRangesFromSqlRanges(ctx, ns, di.storedRanges(ranges), di.keyBld)
 	if err != nil {
 		return nil, err
 	}
@@ -672,6 +696,7 @@ func (di *doltIndex) nomsRanges(ctx *sql.Context, iranges ...sql.Range) ([]*noms
 	// cause the lookup to return no rows, which is the desired behavior.
 	var readRanges []*noms.ReadRange
 
+	iranges = di.storedRanges(iranges)
 	ranges := make([]sql.Range, len(iranges))
 
 	for i := range iranges {
@@ -852,7 +877,7 @@ func (di *doltIndex) isMockIndex() bool {
 // use this property to store hashes of TEXT or BLOB fields and still efficiently detect uniqueness.
 func (di *doltIndex) HasContentHashedField() bool {
 	// content-hashed fields can currently only be used in unique indexes
-	if !di.IsUnique() {
+	if !di.unique {
 		return false
 	}
 
@@ -880,7 +905,7 @@ func (di *doltIndex) HasContentHashedField() bool {
 }
 
 func (di *doltIndex) Order() sql.IndexOrder {
-	if di.HasContentHashedField() {
+	if di.HasContentHashedField() || di.isPartial() {
 		return sql.IndexOrderNone
 	}
 
@@ -902,19 +927,24 @@ func (di *doltIndex) Database() string {
 
 // Expressions implements sql.Index
 func (di *doltIndex) Expressions() []string {
-	strs := make([]string, len(di.columns))
-	for i, col := range di.columns {
-		strs[i] = di.tblName + "." + col.Name
+	keyCols := di.keyColumns()
+	strs := make([]string, len(keyCols))
+	for i, col := range keyCols {
+		strs[i] = di.keyPartExpression(col)
 	}
 	return strs
 }
 
-// ExtendedExpressions implements sql.ExtendedIndex
+// ExtendedExpressions implements sql.ExtendedIndex. The key parts of a partial index are not extended by the primary
+// key, see ExtendedColumnExpressionTypes.
 func (di *doltIndex) ExtendedExpressions() []string {
+	if di.isPartial() {
+		return di.Expressions()
+	}
 	pkCols := di.indexSch.GetPKCols()
 	strs := make([]string, 0, len(pkCols.Tags))
 	_ = pkCols.Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
-		strs = append(strs, di.tblName+"."+col.Name)
+		strs = append(strs, di.keyPartExpression(col))
 		return false, nil
 	})
 	return strs
@@ -925,9 +955,10 @@ func (di *doltIndex) ID() string {
 	return di.id
 }
 
-// IsUnique implements sql.Index
+// IsUnique implements sql.Index. Partial indexes are not unique to the engine, as rows which are not in the index may
+// share its keys.
 func (di *doltIndex) IsUnique() bool {
-	return di.unique
+	return di.unique && !di.isPartial()
 }
 
 // IsSpatial implements sql.Index
@@ -0,0 +1,64 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"context"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/transform"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/expranalysis"
+)
+
+// setKeyExpressions records the key parts of |di| which are the expressions of |def| rather than columns. The engine
+// matches the expressions of indexes to the expressions of filters, so an index of LOWER(email) serves lookups of
+// WHERE LOWER(email) = 'x'. The expressions are resolved, and their columns qualified by the name of the table, as in
+// the filters of the engine.
+func setKeyExpressions(ctx context.Context, di *doltIndex, def schema.Index) error {
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+	di.expressions = make(map[uint64]string, len(def.Expressions()))
+	for _, col := range def.Expressions() {
+		expr, err := expranalysis.ResolveIndexExpression(sqlCtx, di.tblName, di.tableSch, col.Generated)
+		if err != nil {
+			return err
+		}
+		expr, _, err = transform.Expr(expr, func(e sql.Expression) (sql.Expression, transform.TreeIdentity, error) {
+			if gf, ok := e.(*expression.GetField); ok {
+				return gf.WithTable(di.tblName), transform.NewTree, nil
+			}
+			return e, transform.SameTree, nil
+		})
+		if err != nil {
+			return err
+		}
+		di.expressions[col.Tag] = expr.String()
+	}
+	return nil
+}
+
+// keyPartExpression returns the expression of the key part of |di| whose column is |col|: the name of a column of the
+// table qualified by the name of the table, or an expression over the columns of the table.
+func (di *doltIndex) keyPartExpression(col schema.Column) string {
+	if expr, ok := di.expressions[col.Tag]; ok {
+		return expr
+	}
+	return di.tblName + "." + col.Name
+}
@@ -118,6 +118,7 @@ func NewRangePartitionIter(ctx *sql.Context, t DoltTableable, lookup sql.IndexLo
 		return nil, err
 	}
 	return &rangePartitionIter{
+		idx:          idx,
 		nomsRanges:   nomsRanges,
 		prollyRanges: prollyRanges,
 		curr:         0,
@@ -132,7 +133,8 @@ func newPointPartitionIter(ctx *sql.Context, lookup sql.IndexLookup, idx *doltIn
 		return nil, err
 	}
 	return &pointPartition{
-		r: prollyRanges[0],
+		idx: idx,
+		r:   prollyRanges[0],
 	}, nil
 }
 
@@ -140,6 +142,7 @@ var _ sql.PartitionIter = (*pointPartition)(nil)
 var _ sql.Partition = (*pointPartition)(nil)
 
 type pointPartition struct {
+	idx  *doltIndex
 	r    prolly.Range
 	used bool
 }
@@ -161,6 +164,7 @@ func (p *pointPartition) Next(c *sql.Context) (sql.Partition, error) {
 }
 
 type rangePartitionIter struct {
+	idx          *doltIndex
 	nomsRanges   []*noms.ReadRange
 	prollyRanges []prolly.Range
 	curr         int
@@ -192,6 +196,7 @@ func (itr *rangePartitionIter) nextProllyPartition() (sql.Partition, error) {
 	itr.curr += 1
 
 	return rangePartition{
+		idx:         itr.idx,
 		prollyRange: pr,
 		key:         bytes[:],
 		isReverse:   itr.isReverse,
@@ -209,6 +214,7 @@ func (itr *rangePartitionIter) nextNomsPartition() (sql.Partition, error) {
 	itr.curr += 1
 
 	return rangePartition{
+		idx:       itr.idx,
 		nomsRange: nr,
 		key:       bytes[:],
 		isReverse: itr.isReverse,
@@ -216,6 +222,7 @@ func (itr *rangePartitionIter) nextNomsPartition() (sql.Partition, error) {
 }
 
 type rangePartition struct {
+	idx         *doltIndex
 	nomsRange   *noms.ReadRange
 	prollyRange prolly.Range
 	key         []byte
@@ -226,6 +233,22 @@ func (rp rangePartition) Key() []byte {
 	return rp.key
 }
 
+// PartitionIndex returns the index read by |part|, a partition of a lookup, which is not the index of the lookup when
+// it is a partial index that cannot serve the lookup, see LookupIndex.
+func PartitionIndex(part sql.Partition) (DoltIndex, bool) {
+	switch p := part.(type) {
+	case rangePartition:
+		if p.idx != nil {
+			return p.idx, true
+		}
+	case pointPartition:
+		if p.idx != nil {
+			return p.idx, true
+		}
+	}
+	return nil, false
+}
+
 // IndexScanBuilder generates secondary lookups for partitions and
 // encapsulates fast path optimizations for certain point lookups.
 type IndexScanBuilder interface {
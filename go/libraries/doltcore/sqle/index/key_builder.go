@@ -49,21 +49,32 @@ func NewSecondaryKeyBuilder(ctx *sql.Context, tableName string, sch schema.Schem
 
 	b.mapping = make(val.OrdinalMapping, len(def.AllTags()))
 	var virtualExpressions []sql.Expression
+	var virtualTypes []sql.Type
 	for i, tag := range def.AllTags() {
 		j, ok := sch.GetPKCols().TagToIdx[tag]
 		if !ok {
-			col := sch.GetNonPKCols().TagToCol[tag]
-			if col.Virtual {
+			col, isExpr := sch.GetNonPKCols().TagToCol[tag], schema.IsExpressionTag(tag)
+			if isExpr || col.Virtual {
 				if len(virtualExpressions) == 0 {
 					virtualExpressions = make([]sql.Expression, len(def.AllTags()))
+					virtualTypes = make([]sql.Type, len(def.AllTags()))
 				}
 
-				expr, err := expranalysis.ResolveDefaultExpression(ctx, tableName, sch, col)
+				var expr sql.Expression
+				var err error
+				if isExpr {
+					// the key part is an expression of the index rather than a column of the table
+					col, _ = def.GetColumn(tag)
+					expr, err = expranalysis.ResolveIndexExpression(ctx, tableName, sch, col.Generated)
+				} else {
+					expr, err = expranalysis.ResolveDefaultExpression(ctx, tableName, sch, col)
+				}
 				if err != nil {
 					return SecondaryKeyBuilder{}, err
 				}
 
 				virtualExpressions[i] = expr
+				virtualTypes[i] = col.TypeInfo.ToSqlType()
 				j = -1
 			} else if keyless {
 				// Skip cardinality column
@@ -76,6 +87,15 @@ func NewSecondaryKeyBuilder(ctx *sql.Context, tableName string, sch schema.Schem
 	}
 
 	b.virtualExpressions = virtualExpressions
+	b.virtualTypes = virtualTypes
+
+	if def.Predicate() != "" {
+		predicate, err := expranalysis.ResolvePredicateExpression(ctx, tableName, sch, def.Predicate())
+		if err != nil {
+			return SecondaryKeyBuilder{}, err
+		}
+		b.predicate = predicate
+	}
 
 	if keyless {
 		// last key in index is hash which is the only column in the key
@@ -91,8 +111,13 @@ type SecondaryKeyBuilder struct {
 	indexDef schema.Index
 	// mapping defines how to map fields from the source table's schema to this index's tuple layout
 	mapping val.OrdinalMapping
-	// virtualExpressions holds the expressions for virtual columns in the index, nil for non-virtual indexes
+	// virtualExpressions holds the expressions for virtual columns and key parts which are expressions in the index,
+	// nil for other indexes
 	virtualExpressions []sql.Expression
+	// virtualTypes holds the types of the values of |virtualExpressions| in the index
+	virtualTypes []sql.Type
+	// predicate holds the expression rows must satisfy to be in a partial index, nil for other indexes
+	predicate sql.Expression
 	// split marks the index in the secondary index's key tuple that splits the main table's
 	// key fields from the main table's value fields.
 	split     int
@@ -118,12 +143,11 @@ func (b SecondaryKeyBuilder) SecondaryKeyFromRow(ctx context.Context, k, v val.T
 				return nil, err
 			}
 
-			value, err := expr.Eval(sqlCtx, sqlRow)
+			value, err := EvalIndexExpression(sqlCtx, expr, b.virtualTypes[to], sqlRow)
 			if err != nil {
 				return nil, err
 			}
 
-			// TODO: type conversion
 			err = tree.PutField(ctx, b.nodeStore, b.builder, to, value)
 			if err != nil {
 				return nil, err
@@ -163,6 +187,44 @@ func (b SecondaryKeyBuilder) SecondaryKeyFromRow(ctx context.Context, k, v val.T
 	return b.builder.Build(b.pool), nil
 }
 
+// IncludesRow returns whether the clustered index row |k|, |v| belongs in the secondary index. This is false for rows
+// which do not satisfy the predicate of a partial index, and true for every row of other indexes.
+func (b SecondaryKeyBuilder) IncludesRow(ctx context.Context, k, v val.Tuple) (bool, error) {
+	if b.predicate == nil {
+		return true, nil
+	}
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+	sqlRow, err := BuildRow(sqlCtx, k, v, b.sch, b.nodeStore)
+	if err != nil {
+		return false, err
+	}
+	return EvalIndexPredicate(sqlCtx, b.predicate, sqlRow)
+}
+
+// EvalIndexPredicate returns whether |row| satisfies |predicate|, the predicate of a partial index. As in a WHERE
+// clause, rows for which the predicate is NULL are not included in the index.
+func EvalIndexPredicate(ctx *sql.Context, predicate sql.Expression, row sql.Row) (bool, error) {
+	res, err := predicate.Eval(ctx, row)
+	if err != nil || res == nil {
+		return false, err
+	}
+	return sql.ConvertToBool(ctx, res)
+}
+
+// EvalIndexExpression returns the value of |expr|, a virtual column or a key part of an index which is an expression,
+// for |row|, converted to |typ|, the type of its values in the index.
+func EvalIndexExpression(ctx *sql.Context, expr sql.Expression, typ sql.Type, row sql.Row) (interface{}, error) {
+	value, err := expr.Eval(ctx, row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	value, _, err = typ.Convert(value)
+	return value, err
+}
+
 // BuildRow returns a sql.Row for the given key/value tuple pair
 func BuildRow(ctx *sql.Context, key, value val.Tuple, sch schema.Schema, ns tree.NodeStore) (sql.Row, error) {
 	prollyIter := prolly.NewPointLookup(key, value)
@@ -17,9 +17,11 @@ package index
 import (
 	"testing"
 
+	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
 	"github.com/dolthub/dolt/go/store/types"
 	"github.com/dolthub/dolt/go/store/val"
 )
@@ -162,3 +164,98 @@ func TestGetIndexKeyMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestSecondaryKeyBuilderPredicate(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	ns := tree.NewTestNodeStore()
+	sch := schema.MustSchemaFromCols(schema.NewColCollection(
+		schema.NewColumn("id", 0, types.IntKind, true),
+		schema.NewColumn("age", 1, types.IntKind, false),
+		schema.NewColumn("deleted", 2, types.IntKind, false),
+	))
+	idx := schema.NewIndex("adults", []uint64{1}, []uint64{1, 0}, sch.Indexes(), schema.IndexProperties{
+		Predicate: "age >= 18 AND deleted = 0",
+	})
+
+	b, err := NewSecondaryKeyBuilder(ctx, "people", sch, idx, idx.Schema().GetKeyDescriptor(), ns.Pool(), ns)
+	require.NoError(t, err)
+
+	row := func(id, age int64, deleted interface{}) (val.Tuple, val.Tuple) {
+		kb := val.NewTupleBuilder(sch.GetKeyDescriptor())
+		require.NoError(t, tree.PutField(ctx, ns, kb, 0, id))
+		vb := val.NewTupleBuilder(sch.GetValueDescriptor())
+		require.NoError(t, tree.PutField(ctx, ns, vb, 0, age))
+		require.NoError(t, tree.PutField(ctx, ns, vb, 1, deleted))
+		return kb.Build(ns.Pool()), vb.Build(ns.Pool())
+	}
+
+	tests := []struct {
+		age      int64
+		deleted  interface{}
+		included bool
+	}{
+		{age: 30, deleted: int64(0), included: true},
+		{age: 18, deleted: int64(0), included: true},
+		{age: 17, deleted: int64(0), included: false},
+		{age: 30, deleted: int64(1), included: false},
+		// rows for which the predicate is NULL are not indexed
+		{age: 30, deleted: nil, included: false},
+	}
+	for i, tt := range tests {
+		k, v := row(int64(i), tt.age, tt.deleted)
+		included, err := b.IncludesRow(ctx, k, v)
+		require.NoError(t, err)
+		require.Equal(t, tt.included, included, "age=%d deleted=%v", tt.age, tt.deleted)
+	}
+
+	// every row is included in an index without a predicate
+	all := schema.NewIndex("ages", []uint64{1}, []uint64{1, 0}, sch.Indexes(), schema.IndexProperties{})
+	b, err = NewSecondaryKeyBuilder(ctx, "people", sch, all, all.Schema().GetKeyDescriptor(), ns.Pool(), ns)
+	require.NoError(t, err)
+	k, v := row(0, 10, int64(1))
+	included, err := b.IncludesRow(ctx, k, v)
+	require.NoError(t, err)
+	require.True(t, included)
+}
+
+func TestSecondaryKeyBuilderExpressions(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	ns := tree.NewTestNodeStore()
+	sch := schema.MustSchemaFromCols(schema.NewColCollection(
+		schema.NewColumn("id", 0, types.IntKind, true),
+		schema.NewColumn("email", 1, types.StringKind, false),
+	))
+	lower := schema.NewExpressionColumn("lower(email)", sch.GetAllCols().TagToCol[1].TypeInfo)
+	idx := schema.NewIndex("lower_email", []uint64{lower.Tag}, []uint64{lower.Tag, 0}, sch.Indexes(), schema.IndexProperties{
+		Expressions: []schema.Column{lower},
+	})
+	desc := idx.Schema().GetKeyDescriptor()
+
+	b, err := NewSecondaryKeyBuilder(ctx, "users", sch, idx, desc, ns.Pool(), ns)
+	require.NoError(t, err)
+	require.Equal(t, val.OrdinalMapping{-1, 0}, b.mapping)
+
+	tests := []struct {
+		email    interface{}
+		expected interface{}
+	}{
+		{email: "Alice@Example.com", expected: "alice@example.com"},
+		{email: "bob@example.com", expected: "bob@example.com"},
+		{email: nil, expected: nil},
+	}
+	for i, tt := range tests {
+		kb := val.NewTupleBuilder(sch.GetKeyDescriptor())
+		require.NoError(t, tree.PutField(ctx, ns, kb, 0, int64(i)))
+		vb := val.NewTupleBuilder(sch.GetValueDescriptor())
+		require.NoError(t, tree.PutField(ctx, ns, vb, 0, tt.email))
+
+		key, err := b.SecondaryKeyFromRow(ctx, kb.Build(ns.Pool()), vb.Build(ns.Pool()))
+		require.NoError(t, err)
+		email, err := tree.GetField(ctx, desc, 0, key, ns)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, email)
+		id, err := tree.GetField(ctx, desc, 1, key, ns)
+		require.NoError(t, err)
+		require.Equal(t, int64(i), id)
+	}
+}
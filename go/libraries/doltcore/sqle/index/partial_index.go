@@ -0,0 +1,378 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/expranalysis"
+)
+
+// partialIndex is the state of a partial index, which only holds the rows of its table that satisfy its predicate.
+//
+// The engine is only offered a partial index for the lookups it can serve, which are those whose ranges are points on
+// the columns of the predicate that satisfy the predicate. The columns of the predicate which are not key parts of the
+// index follow its key parts in the expressions the engine is offered, so that CREATE INDEX ... (email) WHERE
+// deleted_at IS NULL serves the lookups of WHERE email = 'x' AND deleted_at IS NULL. The engine chooses the indexes of
+// lookup joins and foreign key checks before it knows their keys, so the lookups of those which the index cannot serve
+// read a full index of the table with the same leading columns instead, or scan the table if it has none, see
+// LookupIndex.
+type partialIndex struct {
+	// predicate is the resolved predicate of the index, whose fields are the columns of the table, or nil if lookups
+	// cannot be shown to satisfy it.
+	predicate sql.Expression
+	// fields maps the fields of |predicate| to the positions of their columns in the key parts of the index.
+	fields map[int]int
+	// extra are the columns of |predicate| which are not key parts of the index. The predicate fixes their values, so
+	// every row of the index matches the lookups the index serves on them, and they are not stored in the index.
+	extra []schema.Column
+	// keyExprs are the key parts of the index, followed by |extra|, as expressions over the rows of the table. They
+	// match the rows of the lookups which scan the table.
+	keyExprs []sql.Expression
+	// rowLen is the number of columns of the table.
+	rowLen int
+	// full are the indexes of the table which are not partial.
+	full []*doltIndex
+}
+
+// newPartialIndex makes |di| the partial index defined by |def|.
+func newPartialIndex(ctx context.Context, di *doltIndex, def schema.Index) {
+	p := &partialIndex{rowLen: di.tableSch.GetAllCols().Size()}
+	di.partial = p
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+	if predicate, err := expranalysis.ResolvePredicateExpression(sqlCtx, di.tblName, di.tableSch, def.Predicate()); err == nil {
+		if fields, extra, ok := predicateFields(predicate, di); ok && len(di.prefixLengths) == 0 {
+			p.predicate, p.fields, p.extra = predicate, fields, extra
+		}
+	}
+	keyCols := di.keyColumns()
+	keyExprs := make([]sql.Expression, len(keyCols))
+	for i, col := range keyCols {
+		text := col.Generated
+		if !schema.IsExpressionTag(col.Tag) {
+			text = "`" + col.Name + "`"
+		}
+		expr, err := expranalysis.ResolveIndexExpression(sqlCtx, di.tblName, di.tableSch, text)
+		if err != nil {
+			return
+		}
+		keyExprs[i] = expr
+	}
+	p.keyExprs = keyExprs
+}
+
+// setFullIndexes records the indexes of |indexes| which are not partial in each of the partial indexes of |indexes|,
+// which are the indexes of a table.
+func setFullIndexes(indexes []sql.Index) {
+	var full []*doltIndex
+	for _, idx := range indexes {
+		if di, ok := idx.(*doltIndex); ok && !di.isPartial() {
+			full = append(full, di)
+		}
+	}
+	for _, idx := range indexes {
+		if di, ok := idx.(*doltIndex); ok && di.isPartial() {
+			di.partial.full = full
+		}
+	}
+}
+
+// predicateFields returns the positions in the key parts of |di| of the columns of the fields of |predicate|, and the
+// columns of |predicate| which are not columns of |di|. A column which is not a column of |di| must only be compared by
+// conjuncts of |predicate| which fix its value, `col IS NULL` or `col = <literal>`. It returns false otherwise, or if a
+// column of |di| is a string column whose values are compared by a collation which is not binary, as the predicate
+// might then distinguish values which are equal in the index.
+func predicateFields(predicate sql.Expression, di *doltIndex) (map[int]int, []schema.Column, bool) {
+	allCols := di.tableSch.GetAllCols()
+	keyPos := func(field int) (int, bool) {
+		tag := allCols.GetByIndex(field).Tag
+		for i, idxCol := range di.columns {
+			if idxCol.Tag == tag {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	fields := make(map[int]int)
+	var extra []schema.Column
+	ok := true
+	for _, conjunct := range expression.SplitConjunction(predicate) {
+		if field, fixed := fixedField(conjunct); fixed {
+			if _, isKey := keyPos(field); !isKey {
+				if _, seen := fields[field]; !seen {
+					fields[field] = len(di.columns) + len(extra)
+					extra = append(extra, allCols.GetByIndex(field))
+				}
+				continue
+			}
+		}
+		sql.Inspect(conjunct, func(e sql.Expression) bool {
+			gf, isField := e.(*expression.GetField)
+			if !isField || !ok {
+				return ok
+			}
+			col := allCols.GetByIndex(gf.Index())
+			if st, isString := col.TypeInfo.ToSqlType().(sql.StringType); isString {
+				if c := st.Collation(); c != sql.Collation_binary && !strings.HasSuffix(c.String(), "_bin") {
+					ok = false
+					return false
+				}
+			}
+			i, isKey := keyPos(gf.Index())
+			if !isKey {
+				ok = false
+				return false
+			}
+			fields[gf.Index()] = i
+			return true
+		})
+	}
+	if !ok {
+		return nil, nil, false
+	}
+	return fields, extra, true
+}
+
+// fixedField returns the field of the column whose value is fixed by |conjunct|, if it is `col IS NULL` or
+// `col = <literal>`.
+func fixedField(conjunct sql.Expression) (int, bool) {
+	switch e := conjunct.(type) {
+	case *expression.IsNull:
+		if gf, ok := e.Child.(*expression.GetField); ok {
+			return gf.Index(), true
+		}
+	case *expression.Equals:
+		left, right := e.Left(), e.Right()
+		if _, ok := left.(*expression.Literal); ok {
+			left, right = right, left
+		}
+		gf, isField := left.(*expression.GetField)
+		_, isLiteral := right.(*expression.Literal)
+		if isField && isLiteral {
+			return gf.Index(), true
+		}
+	}
+	return 0, false
+}
+
+// isPartial returns whether |di| is a partial index.
+func (di *doltIndex) isPartial() bool {
+	return di.partial != nil
+}
+
+// IsPartial returns whether |idx| is a partial index.
+func IsPartial(idx sql.Index) bool {
+	di, ok := idx.(*doltIndex)
+	return ok && di.isPartial()
+}
+
+// keyColumns returns the columns of the key parts of |di| the engine is offered: the columns of |di|, followed by the
+// columns of the predicate of a partial index which are not columns of |di|.
+func (di *doltIndex) keyColumns() []schema.Column {
+	if !di.isPartial() || len(di.partial.extra) == 0 {
+		return di.columns
+	}
+	cols := make([]schema.Column, 0, len(di.columns)+len(di.partial.extra))
+	cols = append(cols, di.columns...)
+	return append(cols, di.partial.extra...)
+}
+
+// storedRanges returns |ranges| without their parts on the columns of the predicate of a partial index which are not
+// stored in the index. The index only serves lookups whose ranges on those columns match every row of the index.
+func (di *doltIndex) storedRanges(ranges []sql.Range) []sql.Range {
+	if !di.isPartial() || len(di.partial.extra) == 0 {
+		return ranges
+	}
+	stored := make([]sql.Range, len(ranges))
+	for i, rng := range ranges {
+		stored[i] = rng[:min(len(rng), len(di.columns))]
+	}
+	return stored
+}
+
+// LookupIndex returns the index which reads the rows of |lookup| for a table read with |idx|. It is |idx|, unless the
+// index of |lookup| is a partial index. A partial index reads the lookups it can serve. The lookups it cannot serve,
+// which are those of lookup joins and foreign key checks, read a full index of the table whose leading columns are the
+// columns of |lookup| instead. It returns false if the table has none, in which case the rows of |lookup| are read by
+// a scan of the table which keeps the rows that match it, see MatchesLookup.
+func LookupIndex(idx DoltIndex, lookup sql.IndexLookup) (DoltIndex, bool) {
+	di, ok := lookup.Index.(*doltIndex)
+	if !ok || !di.isPartial() {
+		return idx, true
+	}
+	if di.partial.supports(lookup.Ranges) {
+		return di, true
+	}
+	width := 0
+	for _, rng := range lookup.Ranges {
+		width = max(width, len(rng))
+	}
+	if full, ok := di.partial.fullIndex(di, width); ok {
+		return full, true
+	}
+	return nil, false
+}
+
+// fullIndex returns an index which is not partial whose first |width| columns are the first |width| key parts of |di|,
+// or false if there is none.
+func (p *partialIndex) fullIndex(di *doltIndex, width int) (*doltIndex, bool) {
+	keyCols := di.keyColumns()
+	if width > len(keyCols) {
+		return nil, false
+	}
+	for _, full := range p.full {
+		if width > len(full.columns) || len(full.prefixLengths) > 0 {
+			continue
+		}
+		match := true
+		for i := 0; i < width; i++ {
+			match = match && full.columns[i].Tag == keyCols[i].Tag
+		}
+		if match {
+			return full, true
+		}
+	}
+	return nil, false
+}
+
+// MatchesLookup returns whether |row|, a row of all the columns of a table, matches one of the ranges of |lookup|, a
+// lookup of a partial index of the table which is read by a scan of the table, see LookupIndex.
+func MatchesLookup(ctx *sql.Context, lookup sql.IndexLookup, row sql.Row) (bool, error) {
+	di, ok := lookup.Index.(*doltIndex)
+	if !ok || !di.isPartial() {
+		return false, fmt.Errorf("lookup of index %s cannot be read by a scan of its table", lookup.Index.ID())
+	}
+	if di.partial.keyExprs == nil {
+		return false, fmt.Errorf("partial index %s on table %s cannot serve a lookup of rows which might not satisfy "+
+			"its predicate", di.id, di.tblName)
+	}
+	values := make([]interface{}, len(di.partial.keyExprs))
+	evaluated := make([]bool, len(values))
+RangeLoop:
+	for _, rng := range lookup.Ranges {
+		if len(rng) > len(values) {
+			return false, fmt.Errorf("lookup of partial index %s has more columns than the index", di.id)
+		}
+		for i, expr := range rng {
+			if !evaluated[i] {
+				v, err := EvalIndexExpression(ctx, di.partial.keyExprs[i], expr.Typ, row)
+				if err != nil {
+					return false, err
+				}
+				values[i], evaluated[i] = v, true
+			}
+			ok, err := rangeColumnMatches(expr, values[i])
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				continue RangeLoop
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// rangeColumnMatches returns whether |v| is in the range of |expr|. NULL sorts before every other value, as in an index.
+func rangeColumnMatches(expr sql.RangeColumnExpr, v interface{}) (bool, error) {
+	switch cut := expr.LowerBound.(type) {
+	case sql.AboveAll:
+		return false, nil
+	case sql.AboveNull:
+		if v == nil {
+			return false, nil
+		}
+	case sql.Below, sql.Above:
+		if v == nil {
+			return false, nil
+		}
+		cmp, err := expr.Typ.Compare(v, sql.GetRangeCutKey(cut))
+		if err != nil {
+			return false, err
+		}
+		if cmp < 0 || (cmp == 0 && cut.TypeAsLowerBound() == sql.Open) {
+			return false, nil
+		}
+	}
+	switch cut := expr.UpperBound.(type) {
+	case sql.BelowNull:
+		return false, nil
+	case sql.AboveNull:
+		return v == nil, nil
+	case sql.Below, sql.Above:
+		if v == nil {
+			return true, nil
+		}
+		cmp, err := expr.Typ.Compare(v, sql.GetRangeCutKey(cut))
+		if err != nil {
+			return false, err
+		}
+		return cmp < 0 || (cmp == 0 && cut.TypeAsUpperBound() == sql.Closed), nil
+	}
+	return true, nil
+}
+
+// supports returns whether every row matched by |ranges| satisfies the predicate of the index. The values of the
+// columns of the predicate must be fixed by each range, so that the predicate can be evaluated for them.
+func (p *partialIndex) supports(ranges []sql.Range) bool {
+	if p.predicate == nil || len(ranges) == 0 {
+		return false
+	}
+	ctx := sql.NewEmptyContext()
+	for _, rng := range ranges {
+		row := make(sql.Row, p.rowLen)
+		for field, i := range p.fields {
+			if i >= len(rng) {
+				return false
+			}
+			v, ok := pointValue(rng[i])
+			if !ok {
+				return false
+			}
+			row[field] = v
+		}
+		if ok, err := EvalIndexPredicate(ctx, p.predicate, row); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// pointValue returns the single value matched by |expr|, if it matches a single value.
+func pointValue(expr sql.RangeColumnExpr) (interface{}, bool) {
+	if expr.Type() == sql.RangeType_EqualNull {
+		return nil, true
+	}
+	if !expr.HasLowerBound() || !expr.HasUpperBound() ||
+		expr.LowerBound.TypeAsLowerBound() != sql.Closed || expr.UpperBound.TypeAsUpperBound() != sql.Closed {
+		return nil, false
+	}
+	lower, upper := sql.GetRangeCutKey(expr.LowerBound), sql.GetRangeCutKey(expr.UpperBound)
+	if cmp, err := expr.Typ.Compare(lower, upper); err != nil || cmp != 0 {
+		return nil, false
+	}
+	return lower, true
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/dolthub/go-mysql-server/sql"
 
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 	"github.com/dolthub/dolt/go/store/types"
 )
@@ -64,7 +65,7 @@ func (t *IndexedDoltTable) LookupBuilder(ctx *sql.Context) (index.IndexScanBuild
 }
 
 func (idt *IndexedDoltTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLookup) (sql.PartitionIter, error) {
-	return index.NewRangePartitionIter(ctx, idt.DoltTable, lookup, idt.isDoltFormat)
+	return lookupPartitions(ctx, idt.DoltTable, idt.idx, lookup, idt.isDoltFormat)
 }
 
 func (idt *IndexedDoltTable) Partitions(ctx *sql.Context) (sql.PartitionIter, error) {
@@ -72,6 +73,9 @@ func (idt *IndexedDoltTable) Partitions(ctx *sql.Context) (sql.PartitionIter, er
 }
 
 func (idt *IndexedDoltTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	if scan, ok := part.(lookupScanPartition); ok {
+		return idt.DoltTable.lookupScanRows(ctx, scan)
+	}
 	idt.mu.Lock()
 	defer idt.mu.Unlock()
 	key, canCache, err := idt.DoltTable.DataCacheKey(ctx)
@@ -79,31 +83,66 @@ func (idt *IndexedDoltTable) PartitionRows(ctx *sql.Context, part sql.Partition)
 		return nil, err
 	}
 
-	if idt.lb == nil || !canCache || idt.lb.Key() != key {
-		idt.lb, err = index.NewIndexReaderBuilder(ctx, idt.DoltTable, idt.idx, key, idt.DoltTable.projectedCols, idt.DoltTable.sqlSch, idt.isDoltFormat)
+	filter, err := idt.DoltTable.rowPolicyFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	masker, err := idt.DoltTable.columnMasker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		iter, err := idt.DoltTable.rowPolicyIndexRows(ctx, filter, partitionIndex(part, idt.idx), key, idt.isDoltFormat, part)
 		if err != nil {
 			return nil, err
 		}
+		return masker.RowIter(idt.Schema(), iter), nil
+	}
+
+	lb, err := idt.partitionBuilder(ctx, part, key, canCache)
+	if err != nil {
+		return nil, err
 	}
 
-	return idt.lb.NewPartitionRowIter(ctx, part)
+	iter, err := withRowsExamined(ctx, lb.NewPartitionRowIter(ctx, part))
+	if err != nil {
+		return nil, err
+	}
+	return masker.RowIter(idt.Schema(), iter), nil
 }
 
 func (idt *IndexedDoltTable) PartitionRows2(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	if scan, ok := part.(lookupScanPartition); ok {
+		return idt.DoltTable.lookupScanRows(ctx, scan)
+	}
 	idt.mu.Lock()
 	defer idt.mu.Unlock()
 	key, canCache, err := idt.DoltTable.DataCacheKey(ctx)
 	if err != nil {
 		return nil, err
 	}
+	lb, err := idt.partitionBuilder(ctx, part, key, canCache)
+	if err != nil {
+		return nil, err
+	}
+
+	return withRowsExamined(ctx, lb.NewPartitionRowIter(ctx, part))
+}
+
+// partitionBuilder returns the builder of the rows of |part|, a partition of a lookup. It is the cached builder of the
+// index of |idt|, unless |part| reads another index, see index.LookupIndex. |idt.mu| must be held.
+func (idt *IndexedDoltTable) partitionBuilder(ctx *sql.Context, part sql.Partition, key doltdb.DataCacheKey, canCache bool) (index.IndexScanBuilder, error) {
+	if idx := partitionIndex(part, idt.idx); idx != idt.idx {
+		return index.NewIndexReaderBuilder(ctx, idt.DoltTable, idx, key, idt.DoltTable.projectedCols, idt.DoltTable.sqlSch, idt.isDoltFormat)
+	}
 	if idt.lb == nil || !canCache || idt.lb.Key() != key {
-		idt.lb, err = index.NewIndexReaderBuilder(ctx, idt.DoltTable, idt.idx, key, idt.DoltTable.projectedCols, idt.DoltTable.sqlSch, idt.isDoltFormat)
+		lb, err := index.NewIndexReaderBuilder(ctx, idt.DoltTable, idt.idx, key, idt.DoltTable.projectedCols, idt.DoltTable.sqlSch, idt.isDoltFormat)
 		if err != nil {
 			return nil, err
 		}
+		idt.lb = lb
 	}
-
-	return idt.lb.NewPartitionRowIter(ctx, part)
+	return idt.lb, nil
 }
 
 var _ sql.IndexedTable = (*WritableIndexedDoltTable)(nil)
@@ -148,7 +187,7 @@ func (t *WritableIndexedDoltTable) LookupBuilder(ctx *sql.Context) (index.IndexS
 }
 
 func (t *WritableIndexedDoltTable) LookupPartitions(ctx *sql.Context, lookup sql.IndexLookup) (sql.PartitionIter, error) {
-	return index.NewRangePartitionIter(ctx, t.DoltTable, lookup, t.isDoltFormat)
+	return lookupPartitions(ctx, t.DoltTable, t.idx, lookup, t.isDoltFormat)
 }
 
 func (t *WritableIndexedDoltTable) Partitions(ctx *sql.Context) (sql.PartitionIter, error) {
@@ -156,20 +195,56 @@ func (t *WritableIndexedDoltTable) Partitions(ctx *sql.Context) (sql.PartitionIt
 }
 
 func (t *WritableIndexedDoltTable) PartitionRows(ctx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	if scan, ok := part.(lookupScanPartition); ok {
+		return t.DoltTable.lookupScanRows(ctx, scan)
+	}
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	key, canCache, err := t.DataCacheKey(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if t.lb == nil || !canCache || t.lb.Key() != key {
-		t.lb, err = index.NewIndexReaderBuilder(ctx, t.DoltTable, t.idx, key, t.projectedCols, t.sqlSch, t.isDoltFormat)
+	filter, err := t.DoltTable.rowPolicyFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	masker, err := t.DoltTable.columnMasker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		iter, err := t.DoltTable.rowPolicyIndexRows(ctx, filter, partitionIndex(part, t.idx), key, t.isDoltFormat, part)
 		if err != nil {
 			return nil, err
 		}
+		return masker.RowIter(t.Schema(), iter), nil
+	}
+	lb, err := t.partitionBuilder(ctx, part, key, canCache)
+	if err != nil {
+		return nil, err
 	}
 
-	return t.lb.NewPartitionRowIter(ctx, part)
+	iter, err := withRowsExamined(ctx, lb.NewPartitionRowIter(ctx, part))
+	if err != nil {
+		return nil, err
+	}
+	return masker.RowIter(t.Schema(), iter), nil
+}
+
+// partitionBuilder returns the builder of the rows of |part|, a partition of a lookup. It is the cached builder of the
+// index of |t|, unless |part| reads another index, see index.LookupIndex. |t.mu| must be held.
+func (t *WritableIndexedDoltTable) partitionBuilder(ctx *sql.Context, part sql.Partition, key doltdb.DataCacheKey, canCache bool) (index.IndexScanBuilder, error) {
+	if idx := partitionIndex(part, t.idx); idx != t.idx {
+		return index.NewIndexReaderBuilder(ctx, t.DoltTable, idx, key, t.projectedCols, t.sqlSch, t.isDoltFormat)
+	}
+	if t.lb == nil || !canCache || t.lb.Key() != key {
+		lb, err := index.NewIndexReaderBuilder(ctx, t.DoltTable, t.idx, key, t.projectedCols, t.sqlSch, t.isDoltFormat)
+		if err != nil {
+			return nil, err
+		}
+		t.lb = lb
+	}
+	return t.lb, nil
 }
 
 // WithProjections implements sql.ProjectedTable
@@ -194,3 +269,107 @@ func (t *WritableIndexedDoltTable) Projections() []string {
 	}
 	return names
 }
+
+// lookupPartitions returns the partitions of |lookup| on |t|, which is read with |idx|. A partial index reads a full
+// index for the lookups it cannot serve, or a scan of |t| if it has none.
+func lookupPartitions(ctx *sql.Context, t *DoltTable, idx index.DoltIndex, lookup sql.IndexLookup, isDoltFormat bool) (sql.PartitionIter, error) {
+	lookupIdx, ok := index.LookupIndex(idx, lookup)
+	if !ok {
+		parts, err := t.Partitions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &lookupScanPartitionIter{parts: parts, lookup: lookup}, nil
+	}
+	lookup.Index = lookupIdx
+	return index.NewRangePartitionIter(ctx, t, lookup, isDoltFormat)
+}
+
+// partitionIndex returns the index read by |part|, a partition of a lookup of |idx|.
+func partitionIndex(part sql.Partition, idx index.DoltIndex) index.DoltIndex {
+	if partIdx, ok := index.PartitionIndex(part); ok {
+		return partIdx
+	}
+	return idx
+}
+
+// lookupScanPartition is a partition of a table scanned for the rows of |lookup|, a lookup of a partial index which
+// no index of the table can serve.
+type lookupScanPartition struct {
+	sql.Partition
+	lookup sql.IndexLookup
+}
+
+// lookupScanPartitionIter returns the partitions of a table scanned for the rows of |lookup|.
+type lookupScanPartitionIter struct {
+	parts  sql.PartitionIter
+	lookup sql.IndexLookup
+}
+
+var _ sql.PartitionIter = (*lookupScanPartitionIter)(nil)
+
+func (itr *lookupScanPartitionIter) Next(ctx *sql.Context) (sql.Partition, error) {
+	part, err := itr.parts.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return lookupScanPartition{Partition: part, lookup: itr.lookup}, nil
+}
+
+func (itr *lookupScanPartitionIter) Close(ctx *sql.Context) error {
+	return itr.parts.Close(ctx)
+}
+
+// lookupScanRows returns the rows of |part| which match its lookup, projected to the projected columns of |t|.
+func (t *DoltTable) lookupScanRows(ctx *sql.Context, part lookupScanPartition) (sql.RowIter, error) {
+	table, err := t.DoltTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := t.rowPolicyFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	masker, err := t.columnMasker(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := partitionRows(ctx, table, t.allColumnTags(), part.Partition)
+	if err != nil {
+		return nil, err
+	}
+	iter = &lookupScanRowIter{child: iter, lookup: part.lookup}
+	if filter != nil {
+		iter = t.filterRowPolicy(filter, iter, false)
+	}
+	return masker.RowIter(t.Schema(), t.projectRows(iter)), nil
+}
+
+// lookupScanRowIter returns the rows of its child, rows of all the columns of a table, which match |lookup|.
+type lookupScanRowIter struct {
+	child  sql.RowIter
+	lookup sql.IndexLookup
+}
+
+var _ sql.RowIter = (*lookupScanRowIter)(nil)
+
+func (itr *lookupScanRowIter) Next(ctx *sql.Context) (sql.Row, error) {
+	for {
+		row, err := itr.child.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := index.MatchesLookup(ctx, itr.lookup, row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return row, nil
+		}
+	}
+}
+
+func (itr *lookupScanRowIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
@@ -0,0 +1,839 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/sql"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/env/actions"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/matview"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/rowpolicy"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
+	"github.com/dolthub/dolt/go/store/datas"
+	"github.com/dolthub/dolt/go/store/prolly"
+	"github.com/dolthub/dolt/go/store/prolly/tree"
+	"github.com/dolthub/dolt/go/store/types"
+	"github.com/dolthub/dolt/go/store/val"
+)
+
+const (
+	// materializedViewMaxIncrementalKeys is the largest number of changed keys a refresh applies incrementally.
+	// Past it, recomputing the view is usually cheaper than refreshing each key.
+	materializedViewMaxIncrementalKeys = 10_000
+	// materializedViewKeyBatchSize is the number of keys refreshed by each DELETE and INSERT statement.
+	materializedViewKeyBatchSize = 100
+)
+
+// errTooManyMaterializedViewKeys stops diffing a source table once more keys changed than a refresh applies
+// incrementally.
+var errTooManyMaterializedViewKeys = errors.New("too many changed keys")
+
+var materializedViewProcedureSchema = sql.Schema{
+	&sql.Column{Name: "status", Type: gmstypes.Int64, Nullable: false},
+}
+
+// MaterializedViewProcedures are the stored procedures that create, refresh and drop materialized views.
+var MaterializedViewProcedures = []sql.ExternalStoredProcedureDetails{
+	{Name: "dolt_create_materialized_view", Schema: materializedViewProcedureSchema, Function: doltCreateMaterializedView, AdminOnly: true},
+	{Name: "dolt_drop_materialized_view", Schema: materializedViewProcedureSchema, Function: doltDropMaterializedView, AdminOnly: true},
+	{Name: "dolt_refresh_materialized_view", Schema: materializedViewProcedureSchema, Function: doltRefreshMaterializedView},
+}
+
+// materializedViewExtra is the extra column of a materialized view's dolt_schemas row.
+type materializedViewExtra struct {
+	CreatedAt int64
+	// AutoRefresh is true if the view is refreshed by dolt_commit.
+	AutoRefresh bool
+}
+
+// The roots that the tables of materialized views were computed from are recorded under internal refs, so that
+// refreshes can diff the views' source tables in those roots against the current root. The refs are not versioned,
+// so refreshing a view doesn't change the rows of dolt_schemas, and they outlive the server. A ref is named by its view
+// and the hash of the view's table: any root a table was computed from can be diffed to refresh it, so the same ref
+// serves every branch. A view whose root is not known is recomputed by its next refresh.
+const (
+	// materializedViewRefreshRefPrefix is the prefix of the names of the internal refs of the roots.
+	materializedViewRefreshRefPrefix = "materialized-views/"
+	// materializedViewRefreshRootsPerView is the number of the most recent roots kept for each view, so that a view
+	// refreshed on several branches can be refreshed incrementally on each of them.
+	materializedViewRefreshRootsPerView = 8
+)
+
+// materializedView is a materialized view loaded from dolt_schemas. The fragment of a materialized view is its query,
+// and its rows are stored in a table with the view's name.
+type materializedView struct {
+	name  string
+	query string
+	extra materializedViewExtra
+}
+
+// doltCreateMaterializedView is the stored procedure that creates a materialized view: a table holding the results
+// of a query, that can be refreshed with dolt_refresh_materialized_view.
+func doltCreateMaterializedView(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	apr, err := cli.CreateMaterializedViewArgParser().Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if apr.NArg() != 2 {
+		return nil, fmt.Errorf("dolt_create_materialized_view requires a view name and a query")
+	}
+	db, err := currentMaterializedViewDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name, query := apr.Arg(0), apr.Arg(1)
+	sources, err := matview.SourceTables(query)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkMaterializedViewSources(ctx, db, sources); err != nil {
+		return nil, err
+	}
+	if _, exists, err := db.GetTableInsensitive(ctx, name); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, sql.ErrTableAlreadyExists.New(name)
+	}
+	if _, exists, err := loadMaterializedView(ctx, db, name); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, sql.ErrTableAlreadyExists.New(name)
+	}
+
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	engine := sqle.NewDefault(dsess.DSessFromSess(ctx.Session).Provider())
+	err = execMaterializedViewQuery(ctx, engine, fmt.Sprintf("CREATE TABLE %s AS %s", sql.QuoteIdentifier(name), query))
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := analyzeMaterializedView(ctx, root, query)
+	if err != nil {
+		return nil, err
+	}
+	indexed := make(map[string]bool)
+	for _, src := range plan.Sources {
+		cols := make([]string, len(src.ViewColumns))
+		for i, c := range src.ViewColumns {
+			cols[i] = sql.QuoteIdentifier(c)
+		}
+		idx := strings.Join(cols, ",")
+		if indexed[strings.ToLower(idx)] {
+			continue
+		}
+		indexed[strings.ToLower(idx)] = true
+		// Refreshes delete view rows by these columns. Not every column type can be indexed without a prefix
+		// length, in which case refreshes scan the view instead.
+		err = execMaterializedViewQuery(ctx, engine, fmt.Sprintf("ALTER TABLE %s ADD INDEX (%s)", sql.QuoteIdentifier(name), idx))
+		if err != nil {
+			ctx.Warn(1105, "could not index materialized view %s on (%s): %s", name, idx, err.Error())
+		}
+	}
+
+	mv := &materializedView{
+		name:  name,
+		query: query,
+		extra: materializedViewExtra{CreatedAt: time.Now().Unix(), AutoRefresh: apr.Contains(cli.AutoRefreshFlag)},
+	}
+	if err = mv.setRefreshedRoot(ctx, db, root); err != nil {
+		return nil, err
+	}
+	if err = writeMaterializedView(ctx, db, mv); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// doltDropMaterializedView is the stored procedure that drops a materialized view and its table.
+func doltDropMaterializedView(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("dolt_drop_materialized_view requires a view name")
+	}
+	db, err := currentMaterializedViewDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := args[0]
+	err = db.dropFragFromSchemasTable(ctx, materializedViewFragment, name, fmt.Errorf("materialized view not found: %s", name))
+	if err != nil {
+		return nil, err
+	}
+	if err = deleteMaterializedViewRefreshRoots(ctx, db.GetDoltDB(), name, 0); err != nil {
+		return nil, err
+	}
+	engine := sqle.NewDefault(dsess.DSessFromSess(ctx.Session).Provider())
+	err = execMaterializedViewQuery(ctx, engine, fmt.Sprintf("DROP TABLE IF EXISTS %s", sql.QuoteIdentifier(name)))
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// doltRefreshMaterializedView is the stored procedure that refreshes a materialized view, or all of them with --all.
+// Views whose queries are of a supported shape are refreshed from the rows of their source tables that changed since
+// their last refresh, unless --full is given.
+func doltRefreshMaterializedView(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	apr, err := cli.RefreshMaterializedViewArgParser().Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if apr.Contains(cli.AllFlag) == (apr.NArg() == 1) {
+		return nil, fmt.Errorf("dolt_refresh_materialized_view requires either a view name or --all")
+	}
+	db, err := currentMaterializedViewDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var views []*materializedView
+	if apr.Contains(cli.AllFlag) {
+		views, err = loadMaterializedViews(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		mv, ok, err := loadMaterializedView(ctx, db, apr.Arg(0))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("materialized view not found: %s", apr.Arg(0))
+		}
+		views = append(views, mv)
+	}
+
+	engine := sqle.NewDefault(dsess.DSessFromSess(ctx.Session).Provider())
+	for _, mv := range views {
+		if _, err = refreshMaterializedView(ctx, engine, db, mv, apr.Contains(cli.FullFlag)); err != nil {
+			return nil, err
+		}
+	}
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// refreshMaterializedViewsOnCommit refreshes the materialized views of database |dbName| that have auto refresh
+// enabled, and stages them, so that the commit being created includes views that are up-to-date with its data.
+func refreshMaterializedViewsOnCommit(ctx *sql.Context, dbName string) error {
+	db, ok, err := materializedViewDatabase(ctx)
+	if err != nil || !ok {
+		return err
+	}
+	views, err := loadMaterializedViews(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var engine *sqle.Engine
+	var refreshed []doltdb.TableName
+	for _, mv := range views {
+		if !mv.extra.AutoRefresh {
+			continue
+		}
+		if engine == nil {
+			engine = sqle.NewDefault(dsess.DSessFromSess(ctx.Session).Provider())
+		}
+		changed, err := refreshMaterializedView(ctx, engine, db, mv, false)
+		if err != nil {
+			return fmt.Errorf("failed to refresh materialized view %s: %w", mv.name, err)
+		}
+		if changed {
+			refreshed = append(refreshed, doltdb.TableName{Name: mv.name})
+		}
+	}
+	if len(refreshed) == 0 {
+		return nil
+	}
+
+	dSess := dsess.DSessFromSess(ctx.Session)
+	roots, ok := dSess.GetRoots(ctx, dbName)
+	if !ok {
+		return fmt.Errorf("Could not load database %s", dbName)
+	}
+	roots, err = actions.StageTables(ctx, roots, refreshed, false)
+	if err != nil {
+		return err
+	}
+	return dSess.SetRoots(ctx, dbName, roots)
+}
+
+// checkMaterializedViewSources returns an error if the row-level security policies or column masks of any of the
+// tables |sources| restrict what the current user reads from it. Materialized views are refreshed from every row of
+// their source tables, whoever refreshes them, so only users who read every row and column of the source tables may
+// create them.
+func checkMaterializedViewSources(ctx *sql.Context, db Database, sources []string) error {
+	for _, src := range sources {
+		pred, _, err := rowPolicyPredicate(ctx, db.Name(), src, rowpolicy.Select, false)
+		if err != nil {
+			return err
+		}
+		masks, err := columnMasks(ctx, db.Name(), src)
+		if err != nil {
+			return err
+		}
+		if pred != nil || len(masks) > 0 {
+			return fmt.Errorf("cannot create a materialized view of table %s, whose rows or columns are restricted for the current user", src)
+		}
+	}
+	return nil
+}
+
+// refreshMaterializedView brings the table of |mv| up-to-date with the working root of |db|, and returns whether
+// any of its source tables had changed since its last refresh. The view is computed from every row of its source
+// tables, without the row-level security policies and column masks of the user who refreshes it, since its table is
+// shared by all of its readers.
+func refreshMaterializedView(ctx *sql.Context, engine *sqle.Engine, db Database, mv *materializedView, full bool) (bool, error) {
+	if err := branch_control.CheckAccess(ctx, branch_control.Permissions_Write); err != nil {
+		return false, err
+	}
+	ctx = WithoutRowPolicies(ctx)
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if !full {
+		changed, ok, err := refreshMaterializedViewIncrementally(ctx, engine, db, mv, root)
+		if err != nil {
+			return false, err
+		}
+		if ok && !changed {
+			return false, nil
+		}
+		full = !ok
+	}
+
+	if full {
+		tbl := sql.QuoteIdentifier(mv.name)
+		if err = execMaterializedViewQuery(ctx, engine, fmt.Sprintf("DELETE FROM %s", tbl)); err != nil {
+			return false, err
+		}
+		if err = execMaterializedViewQuery(ctx, engine, fmt.Sprintf("INSERT INTO %s %s", tbl, mv.query)); err != nil {
+			return false, err
+		}
+	}
+
+	return true, mv.setRefreshedRoot(ctx, db, root)
+}
+
+// refreshMaterializedViewIncrementally refreshes |mv| from the rows of its source tables that changed between its
+// last refreshed root and |root|. It returns whether any source table changed, and false for |ok| if the view must
+// instead be recomputed, because its query is not of a supported shape, a source table's schema changed, too many
+// rows changed, or its last refreshed root is no longer available.
+func refreshMaterializedViewIncrementally(ctx *sql.Context, engine *sqle.Engine, db Database, mv *materializedView, root doltdb.RootValue) (changed, ok bool, err error) {
+	fromRoot, hok, err := mv.refreshedRoot(ctx, db)
+	if err != nil || !hok {
+		return false, false, err
+	}
+
+	names, err := matview.SourceTables(mv.query)
+	if err != nil {
+		return false, false, err
+	}
+	plan, err := analyzeMaterializedView(ctx, root, mv.query)
+	if err != nil {
+		return false, false, err
+	}
+
+	// The keys of each source table whose rows changed, keyed by the lower case table name.
+	keys := make(map[string][][]string)
+	total := 0
+	for _, name := range names {
+		fromTbl, _, fromOk, err := doltdb.GetTableInsensitive(ctx, fromRoot, doltdb.TableName{Name: name})
+		if err != nil {
+			return false, false, err
+		}
+		toTbl, _, toOk, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: name})
+		if err != nil {
+			return false, false, err
+		}
+		if !fromOk || !toOk {
+			// Views and system tables cannot be diffed, and neither can tables that were created or dropped.
+			return false, false, nil
+		}
+		fromHash, err := fromTbl.HashOf()
+		if err != nil {
+			return false, false, err
+		}
+		toHash, err := toTbl.HashOf()
+		if err != nil {
+			return false, false, err
+		}
+		if fromHash == toHash {
+			continue
+		}
+		changed = true
+
+		src, isSource := materializedViewSource(plan, name)
+		if plan.Kind == matview.Full || !isSource {
+			return true, false, nil
+		}
+		tblKeys, ok, err := changedMaterializedViewKeys(ctx, fromTbl, toTbl, src.KeyColumns, materializedViewMaxIncrementalKeys-total)
+		if err != nil || !ok {
+			return true, false, err
+		}
+		keys[strings.ToLower(name)] = tblKeys
+		total += len(tblKeys)
+	}
+	if !changed {
+		return false, true, nil
+	}
+
+	view := sql.QuoteIdentifier(mv.name)
+	for _, src := range plan.Sources {
+		srcKeys := keys[strings.ToLower(src.Table)]
+		for len(srcKeys) > 0 {
+			batch := srcKeys
+			if len(batch) > materializedViewKeyBatchSize {
+				batch = batch[:materializedViewKeyBatchSize]
+			}
+			srcKeys = srcKeys[len(batch):]
+
+			del := fmt.Sprintf("DELETE FROM %s WHERE %s", view, matview.KeyPredicate("", src.ViewColumns, batch))
+			if err = execMaterializedViewQuery(ctx, engine, del); err != nil {
+				return true, false, err
+			}
+			query, err := plan.RestrictedQuery(src.Alias, batch)
+			if err != nil {
+				return true, false, err
+			}
+			if err = execMaterializedViewQuery(ctx, engine, fmt.Sprintf("INSERT INTO %s %s", view, query)); err != nil {
+				return true, false, err
+			}
+		}
+	}
+	return true, true, nil
+}
+
+// materializedViewSource returns the first source of |plan| that reads table |name|.
+func materializedViewSource(plan *matview.Plan, name string) (matview.Source, bool) {
+	for _, src := range plan.Sources {
+		if strings.EqualFold(src.Table, name) {
+			return src, true
+		}
+	}
+	return matview.Source{}, false
+}
+
+// changedMaterializedViewKeys returns the distinct values of |columns|, as SQL literals, in the rows that differ
+// between |fromTbl| and |toTbl|, taking both the old and the new values of changed rows. It returns false if the
+// tables cannot be diffed by row, or if there are more than |limit| keys.
+func changedMaterializedViewKeys(ctx *sql.Context, fromTbl, toTbl *doltdb.Table, columns []string, limit int) ([][]string, bool, error) {
+	if !types.IsFormat_DOLT(toTbl.Format()) {
+		return nil, false, nil
+	}
+	fromSch, err := fromTbl.GetSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	toSch, err := toTbl.GetSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if !schema.SchemasAreEqual(fromSch, toSch) || schema.IsKeyless(toSch) {
+		return nil, false, nil
+	}
+
+	fields, ok := materializedViewKeyFields(toSch, columns)
+	if !ok {
+		return nil, false, nil
+	}
+
+	fromIdx, err := fromTbl.GetRowData(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	toIdx, err := toTbl.GetRowData(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	from, to := durable.ProllyMapFromIndex(fromIdx), durable.ProllyMapFromIndex(toIdx)
+	kd, vd := to.Descriptors()
+
+	var keys [][]string
+	seen := make(map[string]bool)
+	add := func(k, v val.Tuple, ns tree.NodeStore) error {
+		key := make([]string, len(fields))
+		for i, f := range fields {
+			td, tup := kd, k
+			if !f.isKey {
+				td, tup = vd, v
+			}
+			value, err := tree.GetField(ctx, td, f.idx, tup, ns)
+			if err != nil {
+				return err
+			}
+			key[i], err = sqlfmt.SqlValueAsString(f.col.TypeInfo, value)
+			if err != nil {
+				return err
+			}
+		}
+		s := strings.Join(key, ",")
+		if !seen[s] {
+			seen[s] = true
+			keys = append(keys, key)
+		}
+		if len(keys) > limit {
+			return errTooManyMaterializedViewKeys
+		}
+		return nil
+	}
+
+	err = prolly.DiffMaps(ctx, from, to, false, func(ctx context.Context, d tree.Diff) error {
+		if d.From != nil {
+			if err := add(val.Tuple(d.Key), val.Tuple(d.From), fromTbl.NodeStore()); err != nil {
+				return err
+			}
+		}
+		if d.To != nil {
+			if err := add(val.Tuple(d.Key), val.Tuple(d.To), toTbl.NodeStore()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errTooManyMaterializedViewKeys) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return keys, true, nil
+}
+
+// materializedViewKeyField is the position of a column in the key or value tuples of a table's rows.
+type materializedViewKeyField struct {
+	col   schema.Column
+	isKey bool
+	idx   int
+}
+
+// materializedViewKeyFields returns the positions of |columns| in the rows of a table with schema |sch|. It returns
+// false if a column is virtual, since virtual columns are not stored.
+func materializedViewKeyFields(sch schema.Schema, columns []string) ([]materializedViewKeyField, bool) {
+	fields := make([]materializedViewKeyField, len(columns))
+	for i, name := range columns {
+		found := false
+		for j, col := range sch.GetPKCols().GetColumns() {
+			if strings.EqualFold(col.Name, name) {
+				fields[i], found = materializedViewKeyField{col: col, isKey: true, idx: j}, true
+			}
+		}
+		j := 0
+		for _, col := range sch.GetNonPKCols().GetColumns() {
+			if col.Virtual {
+				if strings.EqualFold(col.Name, name) {
+					return nil, false
+				}
+				continue
+			}
+			if strings.EqualFold(col.Name, name) {
+				fields[i], found = materializedViewKeyField{col: col, idx: j}, true
+			}
+			j++
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return fields, true
+}
+
+// analyzeMaterializedView returns the refresh plan of |query| against the tables of |root|.
+func analyzeMaterializedView(ctx *sql.Context, root doltdb.RootValue, query string) (*matview.Plan, error) {
+	names, err := matview.SourceTables(query)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]matview.Table, len(names))
+	for _, name := range names {
+		tbl, tblName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		sch, err := tbl.GetSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t := matview.Table{Name: tblName}
+		for _, col := range sch.GetAllCols().GetColumns() {
+			t.Columns = append(t.Columns, col.Name)
+		}
+		for _, col := range sch.GetPKCols().GetColumns() {
+			t.PrimaryKey = append(t.PrimaryKey, col.Name)
+		}
+		tables[strings.ToLower(name)] = t
+	}
+	return matview.Analyze(query, tables)
+}
+
+// setRefreshedRoot records |root| as the root the table of |mv| in the working root of |db| was computed from, so
+// that the next refresh can diff against it.
+func (mv *materializedView) setRefreshedRoot(ctx *sql.Context, db Database, root doltdb.RootValue) error {
+	name, ok, err := mv.refreshRefName(ctx, db)
+	if err != nil || !ok {
+		return err
+	}
+	meta, err := datas.NewCommitMeta(env.DefaultName, env.DefaultEmail, "Refresh materialized view "+mv.name)
+	if err != nil {
+		return err
+	}
+	ddb := db.GetDoltDB()
+	if err = ddb.SetInternalRoot(ctx, name, root, meta); err != nil {
+		return err
+	}
+	return deleteMaterializedViewRefreshRoots(ctx, ddb, mv.name, materializedViewRefreshRootsPerView)
+}
+
+// refreshedRoot returns the root the table of |mv| in the working root of |db| was computed from, or false if it is
+// not known.
+func (mv *materializedView) refreshedRoot(ctx *sql.Context, db Database) (doltdb.RootValue, bool, error) {
+	name, ok, err := mv.refreshRefName(ctx, db)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	root, _, ok, err := db.GetDoltDB().GetInternalRoot(ctx, name)
+	return root, ok, err
+}
+
+// refreshRefName returns the name of the internal ref of the root the table of |mv| in the working root of |db| was
+// computed from, or false if the table doesn't exist.
+func (mv *materializedView) refreshRefName(ctx *sql.Context, db Database) (string, bool, error) {
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: mv.name})
+	if err != nil || !ok {
+		return "", false, err
+	}
+	h, err := tbl.HashOf()
+	if err != nil {
+		return "", false, err
+	}
+	return materializedViewRefreshRefs(mv.name) + h.String(), true, nil
+}
+
+// materializedViewRefreshRefs returns the prefix of the names of the internal refs of the roots of the materialized
+// view |name|. View names are hex encoded, since they can hold characters that refs cannot.
+func materializedViewRefreshRefs(name string) string {
+	return materializedViewRefreshRefPrefix + hex.EncodeToString([]byte(strings.ToLower(name))) + "/"
+}
+
+// deleteMaterializedViewRefreshRoots deletes the roots of the materialized view |name| other than the |keep| most
+// recently recorded ones.
+func deleteMaterializedViewRefreshRoots(ctx *sql.Context, ddb *doltdb.DoltDB, name string, keep int) error {
+	names, err := ddb.GetInternalRefNames(ctx, materializedViewRefreshRefs(name))
+	if err != nil || len(names) <= keep {
+		return err
+	}
+	recorded := make(map[string]int64, len(names))
+	for _, n := range names {
+		_, meta, ok, err := ddb.GetInternalRoot(ctx, n)
+		if err != nil {
+			return err
+		}
+		if ok {
+			recorded[n] = meta.UserTimestamp
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return recorded[names[i]] > recorded[names[j]]
+	})
+	for _, n := range names[keep:] {
+		if err = ddb.DeleteInternalRef(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execMaterializedViewQuery executes |query| with |engine| in the session and transaction of |ctx|, discarding its
+// results.
+func execMaterializedViewQuery(ctx *sql.Context, engine *sqle.Engine, query string) error {
+	queryCtx := sql.NewContext(ctx, sql.WithSession(ctx.Session))
+	queryCtx.SetIgnoreAutoCommit(true)
+	_, iter, err := engine.Query(queryCtx, query)
+	if err != nil {
+		return err
+	}
+	_, err = sql.RowIterToRows(queryCtx, iter)
+	return err
+}
+
+// materializedViewDatabase returns the current database. It returns false if the database cannot hold materialized
+// views, such as a read-only database.
+func materializedViewDatabase(ctx *sql.Context) (Database, bool, error) {
+	dbName := ctx.GetCurrentDatabase()
+	if dbName == "" {
+		return Database{}, false, fmt.Errorf("Empty database name.")
+	}
+	sqlDb, err := dsess.DSessFromSess(ctx.Session).Provider().Database(ctx, dbName)
+	if err != nil {
+		return Database{}, false, err
+	}
+	switch db := sqlDb.(type) {
+	case Database:
+		return db, true, nil
+	case ReadReplicaDatabase:
+		return db.Database, true, nil
+	default:
+		return Database{}, false, nil
+	}
+}
+
+// currentMaterializedViewDatabase returns the current database, or an error if it cannot hold materialized views.
+func currentMaterializedViewDatabase(ctx *sql.Context) (Database, error) {
+	db, ok, err := materializedViewDatabase(ctx)
+	if err != nil {
+		return Database{}, err
+	}
+	if !ok {
+		return Database{}, fmt.Errorf("database %s does not support materialized views", ctx.GetCurrentDatabase())
+	}
+	return db, nil
+}
+
+// loadMaterializedViews returns the materialized views of |db|.
+func loadMaterializedViews(ctx *sql.Context, db Database) (views []*materializedView, rerr error) {
+	tbl, _, err := db.GetTableInsensitive(ctx, doltdb.SchemasTableName)
+	if err != nil {
+		return nil, err
+	}
+	wrapper, ok := tbl.(*SchemaTable)
+	if !ok {
+		return nil, fmt.Errorf("expected a SchemaTable, but found %T", tbl)
+	}
+	if wrapper.backingTable == nil {
+		return nil, nil
+	}
+
+	frags, err := getSchemaFragmentsOfType(ctx, wrapper.backingTable, materializedViewFragment)
+	if err != nil {
+		return nil, err
+	}
+	for _, frag := range frags {
+		mv, ok, err := loadMaterializedView(ctx, db, frag.name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			views = append(views, mv)
+		}
+	}
+	return views, nil
+}
+
+// loadMaterializedView returns the materialized view of |db| named |name|, if it exists.
+func loadMaterializedView(ctx *sql.Context, db Database, name string) (*materializedView, bool, error) {
+	tbl, _, err := db.GetTableInsensitive(ctx, doltdb.SchemasTableName)
+	if err != nil {
+		return nil, false, err
+	}
+	wrapper, ok := tbl.(*SchemaTable)
+	if !ok {
+		return nil, false, fmt.Errorf("expected a SchemaTable, but found %T", tbl)
+	}
+	if wrapper.backingTable == nil {
+		return nil, false, nil
+	}
+
+	row, ok, err := fragFromSchemasTable(ctx, wrapper.backingTable, materializedViewFragment, name)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	sch := wrapper.backingTable.sqlSchema()
+	mv := &materializedView{
+		name:  row[sch.IndexOfColName(doltdb.SchemasTablesNameCol)].(string),
+		query: row[sch.IndexOfColName(doltdb.SchemasTablesFragmentCol)].(string),
+	}
+	if extra, ok := row[sch.IndexOfColName(doltdb.SchemasTablesExtraCol)].(sql.JSONWrapper); ok {
+		doc, err := extra.ToInterface()
+		if err != nil {
+			return nil, false, err
+		}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return nil, false, err
+		}
+		if err = json.Unmarshal(b, &mv.extra); err != nil {
+			return nil, false, err
+		}
+	}
+	return mv, true, nil
+}
+
+// writeMaterializedView creates or updates the dolt_schemas row of |mv|.
+func writeMaterializedView(ctx *sql.Context, db Database, mv *materializedView) (err error) {
+	if err := dsess.CheckAccessForDb(ctx, db, branch_control.Permissions_Write); err != nil {
+		return err
+	}
+	tbl, err := getOrCreateDoltSchemasTable(ctx, db)
+	if err != nil {
+		return err
+	}
+	extraJSON, err := json.Marshal(mv.extra)
+	if err != nil {
+		return err
+	}
+	newRow := sql.Row{materializedViewFragment, mv.name, mv.query, extraJSON, sql.LoadSqlMode(ctx).String()}
+
+	oldRow, exists, err := fragFromSchemasTable(ctx, tbl, materializedViewFragment, mv.name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		inserter := tbl.Inserter(ctx)
+		defer func() {
+			cErr := inserter.Close(ctx)
+			if err == nil {
+				err = cErr
+			}
+		}()
+		return inserter.Insert(ctx, newRow)
+	}
+
+	updater := tbl.Updater(ctx)
+	defer func() {
+		cErr := updater.Close(ctx)
+		if err == nil {
+			err = cErr
+		}
+	}()
+	return updater.Update(ctx, oldRow, newRow)
+}
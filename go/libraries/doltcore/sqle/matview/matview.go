@@ -0,0 +1,464 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package matview analyzes the queries of materialized views, to decide
+// whether a view can be refreshed from the rows that changed in the tables it
+// reads, and to build the statements that refresh only those rows.
+package matview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// Kind is the way a materialized view is refreshed.
+type Kind int
+
+const (
+	// Full views are recomputed from scratch on every refresh.
+	Full Kind = iota
+	// Rows views are filters and projections of a table, or inner joins of
+	// tables, whose output includes the primary key of every table read. Each
+	// view row is derived from exactly one row of each source, so a change to
+	// a source row only affects the view rows holding its primary key.
+	Rows
+	// Groups views group a single table by columns that are all included in
+	// the output. A change to a source row only affects the groups of its old
+	// and new values.
+	Groups
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Rows:
+		return "rows"
+	case Groups:
+		return "groups"
+	default:
+		return "full"
+	}
+}
+
+// Table describes a table that a materialized view reads from.
+type Table struct {
+	Name       string
+	Columns    []string
+	PrimaryKey []string
+}
+
+// Source is a table read by a Rows or Groups view.
+type Source struct {
+	// Table is the name of the table.
+	Table string
+	// Alias is the name by which the query refers to the table.
+	Alias string
+	// KeyColumns are the columns of the table whose values identify the view
+	// rows affected by a change to a table row: its primary key for a Rows
+	// view, and the grouping columns for a Groups view.
+	KeyColumns []string
+	// ViewColumns are the view columns holding the values of KeyColumns.
+	ViewColumns []string
+}
+
+// Plan describes how a materialized view is refreshed.
+type Plan struct {
+	Query   string
+	Kind    Kind
+	Sources []Source
+	// Reason explains why the view is refreshed in full.
+	Reason string
+}
+
+// SourceTables returns the names of the tables that |query| reads, including
+// those read by subqueries, in the order they first appear. It returns an
+// error if |query| is not a SELECT statement.
+func SourceTables(query string) ([]string, error) {
+	sel, err := parseSelect(query)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := make(map[string]bool)
+	err = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if ate, ok := node.(*sqlparser.AliasedTableExpr); ok {
+			if tn, ok := ate.Expr.(sqlparser.TableName); ok {
+				name := tn.Name.String()
+				if !seen[strings.ToLower(name)] {
+					seen[strings.ToLower(name)] = true
+					names = append(names, name)
+				}
+			}
+		}
+		return true, nil
+	}, sel)
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// Analyze returns the Plan for a view defined by |query|. |tables| holds the
+// tables the query reads, keyed by their lower case names, and must include
+// every table returned by SourceTables. Queries that are not of a shape that
+// can be refreshed incrementally get a Full plan.
+func Analyze(query string, tables map[string]Table) (*Plan, error) {
+	sel, err := parseSelect(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{Query: query}
+	if reason := unsupported(sel); reason != "" {
+		p.Reason = reason
+		return p, nil
+	}
+
+	sources, reason := fromSources(sel.From, tables)
+	if reason != "" {
+		p.Reason = reason
+		return p, nil
+	}
+
+	outputs, reason := outputColumns(sel.SelectExprs, sources, tables)
+	if reason != "" {
+		p.Reason = reason
+		return p, nil
+	}
+
+	if len(sel.GroupBy) == 0 && !hasAggregate(sel.SelectExprs) {
+		if sel.Having != nil {
+			p.Reason = "HAVING without GROUP BY"
+			return p, nil
+		}
+		for i := range sources {
+			s := &sources[i]
+			s.KeyColumns = tables[strings.ToLower(s.Table)].PrimaryKey
+			for _, c := range s.KeyColumns {
+				out, ok := outputs[columnRef{s.Alias, strings.ToLower(c)}]
+				if !ok {
+					p.Reason = fmt.Sprintf("primary key column %s.%s is not selected", s.Alias, c)
+					return p, nil
+				}
+				s.ViewColumns = append(s.ViewColumns, out)
+			}
+		}
+		p.Kind, p.Sources = Rows, sources
+		return p, nil
+	}
+
+	if len(sel.GroupBy) == 0 {
+		p.Reason = "aggregates without GROUP BY"
+		return p, nil
+	}
+	if len(sources) != 1 {
+		p.Reason = "GROUP BY over a join"
+		return p, nil
+	}
+	s := sources[0]
+	for _, e := range sel.GroupBy {
+		col, ok := e.(*sqlparser.ColName)
+		if !ok {
+			p.Reason = fmt.Sprintf("GROUP BY expression %s is not a column", sqlparser.String(e))
+			return p, nil
+		}
+		ref, ok := resolveColumn(col, sources, tables)
+		if !ok {
+			p.Reason = fmt.Sprintf("unknown GROUP BY column %s", sqlparser.String(col))
+			return p, nil
+		}
+		out, ok := outputs[ref]
+		if !ok {
+			p.Reason = fmt.Sprintf("GROUP BY column %s is not selected", sqlparser.String(col))
+			return p, nil
+		}
+		s.KeyColumns = append(s.KeyColumns, col.Name.String())
+		s.ViewColumns = append(s.ViewColumns, out)
+	}
+	p.Kind, p.Sources = Groups, []Source{s}
+	return p, nil
+}
+
+// RestrictedQuery returns the view's query restricted to the rows of the
+// source with alias |alias| whose key columns hold one of |keys|. Each key is
+// a list of SQL literals, one for each of the source's KeyColumns.
+func (p *Plan) RestrictedQuery(alias string, keys [][]string) (string, error) {
+	var src *Source
+	for i := range p.Sources {
+		if strings.EqualFold(p.Sources[i].Alias, alias) {
+			src = &p.Sources[i]
+		}
+	}
+	if src == nil {
+		return "", fmt.Errorf("materialized view does not read from %s", alias)
+	}
+	sel, err := parseSelect(p.Query)
+	if err != nil {
+		return "", err
+	}
+	pred, err := parseExpr(KeyPredicate(src.Alias, src.KeyColumns, keys))
+	if err != nil {
+		return "", err
+	}
+	if sel.Where == nil {
+		sel.Where = &sqlparser.Where{Type: sqlparser.WhereStr, Expr: pred}
+	} else {
+		sel.Where.Expr = &sqlparser.AndExpr{
+			Left:  &sqlparser.ParenExpr{Expr: sel.Where.Expr},
+			Right: &sqlparser.ParenExpr{Expr: pred},
+		}
+	}
+	return sqlparser.String(sel), nil
+}
+
+// KeyPredicate returns a boolean expression that is true for the rows whose
+// |columns|, qualified by |qualifier| if it is not empty, hold one of |keys|.
+// NULLs compare equal to each other, so that a NULL group matches itself.
+func KeyPredicate(qualifier string, columns []string, keys [][]string) string {
+	if len(keys) == 0 {
+		return "FALSE"
+	}
+	var sb strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteString(" OR ")
+		}
+		sb.WriteByte('(')
+		for j, c := range columns {
+			if j > 0 {
+				sb.WriteString(" AND ")
+			}
+			if qualifier != "" {
+				sb.WriteString(quoteIdentifier(qualifier))
+				sb.WriteByte('.')
+			}
+			sb.WriteString(quoteIdentifier(c))
+			sb.WriteString(" <=> ")
+			sb.WriteString(key[j])
+		}
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
+
+func quoteIdentifier(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+func parseSelect(query string) (*sqlparser.Select, error) {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, fmt.Errorf("materialized view query must be a SELECT statement")
+	}
+	return sel, nil
+}
+
+func parseExpr(expr string) (sqlparser.Expr, error) {
+	sel, err := parseSelect("SELECT 1 FROM dual WHERE " + expr)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Where.Expr, nil
+}
+
+// unsupported returns why the clauses of |sel| prevent an incremental refresh,
+// or the empty string if they do not.
+func unsupported(sel *sqlparser.Select) string {
+	switch {
+	case sel.With != nil:
+		return "common table expressions"
+	case sel.QueryOpts.Distinct:
+		return "SELECT DISTINCT"
+	case sel.Limit != nil:
+		return "LIMIT"
+	case len(sel.Window) > 0:
+		return "window functions"
+	case sel.Into != nil:
+		return "SELECT INTO"
+	}
+	var reason string
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.Subquery:
+			reason = "subqueries"
+		case *sqlparser.FuncExpr:
+			if n.Over != nil {
+				reason = "window functions"
+			}
+		}
+		return reason == "", nil
+	}, sel.SelectExprs, sel.From, sel.Where, sel.GroupBy, sel.Having)
+	return reason
+}
+
+// fromSources returns the tables joined by |from|, or why they cannot be
+// refreshed incrementally.
+func fromSources(from sqlparser.TableExprs, tables map[string]Table) ([]Source, string) {
+	var sources []Source
+	var visit func(te sqlparser.TableExpr) string
+	visit = func(te sqlparser.TableExpr) string {
+		switch te := te.(type) {
+		case *sqlparser.AliasedTableExpr:
+			tn, ok := te.Expr.(sqlparser.TableName)
+			if !ok {
+				return "derived tables"
+			}
+			if te.AsOf != nil {
+				return "AS OF"
+			}
+			if !tn.DbQualifier.IsEmpty() || !tn.SchemaQualifier.IsEmpty() {
+				return fmt.Sprintf("qualified table name %s", sqlparser.String(tn))
+			}
+			t, ok := tables[strings.ToLower(tn.Name.String())]
+			if !ok {
+				return fmt.Sprintf("%s is not a table", tn.Name.String())
+			}
+			if len(t.PrimaryKey) == 0 {
+				return fmt.Sprintf("table %s has no primary key", t.Name)
+			}
+			alias := te.As.String()
+			if alias == "" {
+				alias = tn.Name.String()
+			}
+			for _, s := range sources {
+				if strings.EqualFold(s.Alias, alias) {
+					return fmt.Sprintf("duplicate table alias %s", alias)
+				}
+			}
+			sources = append(sources, Source{Table: t.Name, Alias: alias})
+			return ""
+		case *sqlparser.ParenTableExpr:
+			for _, e := range te.Exprs {
+				if reason := visit(e); reason != "" {
+					return reason
+				}
+			}
+			return ""
+		case *sqlparser.JoinTableExpr:
+			if te.Join != sqlparser.JoinStr && te.Join != sqlparser.StraightJoinStr {
+				return strings.ToUpper(te.Join)
+			}
+			if te.Condition.Using != nil {
+				return "JOIN USING"
+			}
+			if reason := visit(te.LeftExpr); reason != "" {
+				return reason
+			}
+			return visit(te.RightExpr)
+		default:
+			return fmt.Sprintf("table expression %s", sqlparser.String(te))
+		}
+	}
+	for _, te := range from {
+		if reason := visit(te); reason != "" {
+			return nil, reason
+		}
+	}
+	for i := range sources {
+		sources[i].Table = tables[strings.ToLower(sources[i].Table)].Name
+	}
+	return sources, ""
+}
+
+// columnRef is a column of a source, by its alias and lower case name.
+type columnRef struct {
+	alias  string
+	column string
+}
+
+// outputColumns returns the view columns that hold the value of a source
+// column, keyed by the column. A source column that is selected more than once
+// maps to its first view column.
+func outputColumns(exprs sqlparser.SelectExprs, sources []Source, tables map[string]Table) (map[columnRef]string, string) {
+	outputs := make(map[columnRef]string)
+	add := func(ref columnRef, out string) {
+		if _, ok := outputs[ref]; !ok {
+			outputs[ref] = out
+		}
+	}
+	for _, e := range exprs {
+		switch e := e.(type) {
+		case *sqlparser.StarExpr:
+			for _, s := range sources {
+				if !e.TableName.IsEmpty() && !strings.EqualFold(e.TableName.Name.String(), s.Alias) {
+					continue
+				}
+				for _, c := range tables[strings.ToLower(s.Table)].Columns {
+					add(columnRef{s.Alias, strings.ToLower(c)}, c)
+				}
+			}
+		case *sqlparser.AliasedExpr:
+			col, ok := e.Expr.(*sqlparser.ColName)
+			if !ok {
+				continue
+			}
+			ref, ok := resolveColumn(col, sources, tables)
+			if !ok {
+				continue
+			}
+			out := e.As.String()
+			if out == "" {
+				out = col.Name.String()
+			}
+			add(ref, out)
+		default:
+			return nil, fmt.Sprintf("select expression %s", sqlparser.String(e))
+		}
+	}
+	return outputs, ""
+}
+
+// resolveColumn returns the source column referred to by |col|. An unqualified
+// column must belong to exactly one source.
+func resolveColumn(col *sqlparser.ColName, sources []Source, tables map[string]Table) (columnRef, bool) {
+	name := col.Name.Lowered()
+	if !col.Qualifier.IsEmpty() {
+		for _, s := range sources {
+			if strings.EqualFold(s.Alias, col.Qualifier.Name.String()) {
+				return columnRef{s.Alias, name}, true
+			}
+		}
+		return columnRef{}, false
+	}
+	var ref columnRef
+	found := 0
+	for _, s := range sources {
+		for _, c := range tables[strings.ToLower(s.Table)].Columns {
+			if strings.EqualFold(c, name) {
+				ref = columnRef{s.Alias, name}
+				found++
+			}
+		}
+	}
+	return ref, found == 1
+}
+
+// hasAggregate returns whether |exprs| call an aggregate function.
+func hasAggregate(exprs sqlparser.SelectExprs) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.FuncExpr:
+			found = found || n.IsAggregate()
+		case *sqlparser.GroupConcatExpr:
+			found = true
+		}
+		return !found, nil
+	}, exprs)
+	return found
+}
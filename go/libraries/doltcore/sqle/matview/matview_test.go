@@ -0,0 +1,122 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTables = map[string]Table{
+	"orders":    {Name: "orders", Columns: []string{"id", "customer_id", "total", "status"}, PrimaryKey: []string{"id"}},
+	"customers": {Name: "customers", Columns: []string{"id", "name", "region"}, PrimaryKey: []string{"id"}},
+	"events":    {Name: "events", Columns: []string{"id", "payload"}},
+}
+
+func TestSourceTables(t *testing.T) {
+	names, err := SourceTables("select * from orders o join customers c on o.customer_id = c.id where o.id in (select id from Orders)")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders", "customers"}, names)
+
+	_, err = SourceTables("delete from orders")
+	assert.Error(t, err)
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		kind    Kind
+		sources []Source
+	}{
+		{
+			name:  "filter",
+			query: "select id, total from orders where status = 'open'",
+			kind:  Rows,
+			sources: []Source{
+				{Table: "orders", Alias: "orders", KeyColumns: []string{"id"}, ViewColumns: []string{"id"}},
+			},
+		},
+		{
+			name:  "star",
+			query: "select * from orders where total > 10",
+			kind:  Rows,
+			sources: []Source{
+				{Table: "orders", Alias: "orders", KeyColumns: []string{"id"}, ViewColumns: []string{"id"}},
+			},
+		},
+		{
+			name:  "inner join",
+			query: "select o.id as order_id, c.id as customer_id, c.name, o.total from orders o join customers c on o.customer_id = c.id",
+			kind:  Rows,
+			sources: []Source{
+				{Table: "orders", Alias: "o", KeyColumns: []string{"id"}, ViewColumns: []string{"order_id"}},
+				{Table: "customers", Alias: "c", KeyColumns: []string{"id"}, ViewColumns: []string{"customer_id"}},
+			},
+		},
+		{
+			name:  "group by",
+			query: "select status, count(*) as n, sum(total) from orders where total > 0 group by status having n > 1",
+			kind:  Groups,
+			sources: []Source{
+				{Table: "orders", Alias: "orders", KeyColumns: []string{"status"}, ViewColumns: []string{"status"}},
+			},
+		},
+		{name: "missing primary key", query: "select total from orders", kind: Full},
+		{name: "ambiguous primary key", query: "select id from orders join customers on customer_id = customers.id", kind: Full},
+		{name: "left join", query: "select o.id, c.id from orders o left join customers c on o.customer_id = c.id", kind: Full},
+		{name: "keyless table", query: "select id from events", kind: Full},
+		{name: "subquery", query: "select id from orders where customer_id in (select id from customers)", kind: Full},
+		{name: "global aggregate", query: "select count(*) from orders", kind: Full},
+		{name: "group by not selected", query: "select count(*) from orders group by status", kind: Full},
+		{name: "group by join", query: "select c.region, sum(o.total) from orders o join customers c on o.customer_id = c.id group by c.region", kind: Full},
+		{name: "limit", query: "select id from orders limit 10", kind: Full},
+		{name: "distinct", query: "select distinct id, status from orders", kind: Full},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := Analyze(test.query, testTables)
+			require.NoError(t, err)
+			assert.Equal(t, test.kind, p.Kind, p.Reason)
+			assert.Equal(t, test.sources, p.Sources)
+			if test.kind == Full {
+				assert.NotEmpty(t, p.Reason)
+			}
+		})
+	}
+}
+
+func TestRestrictedQuery(t *testing.T) {
+	p, err := Analyze("select o.id, c.id as cid, c.name from orders o join customers c on o.customer_id = c.id where o.status = 'open' or o.total > 10", testTables)
+	require.NoError(t, err)
+	require.Equal(t, Rows, p.Kind)
+
+	q, err := p.RestrictedQuery("c", [][]string{{"1"}, {"2"}})
+	require.NoError(t, err)
+	assert.Equal(t, "select o.id, c.id as cid, c.`name` from orders as o join customers as c on o.customer_id = c.id "+
+		"where (o.`status` = 'open' or o.total > 10) and ((c.id <=> 1) or (c.id <=> 2))", q)
+
+	_, err = p.RestrictedQuery("x", [][]string{{"1"}})
+	assert.Error(t, err)
+}
+
+func TestKeyPredicate(t *testing.T) {
+	assert.Equal(t, "FALSE", KeyPredicate("", []string{"a"}, nil))
+	assert.Equal(t, "(`a` <=> 1 AND `b` <=> NULL) OR (`a` <=> 2 AND `b` <=> 'x')",
+		KeyPredicate("", []string{"a", "b"}, [][]string{{"1", "NULL"}, {"2", "'x'"}}))
+	assert.Equal(t, "(`t`.`a` <=> 1)", KeyPredicate("t", []string{"a"}, [][]string{{"1"}}))
+}
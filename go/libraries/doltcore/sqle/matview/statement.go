@@ -0,0 +1,179 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlscan"
+)
+
+const (
+	// CreateProcedure is the stored procedure which creates a materialized view:
+	//
+	//	CALL dolt_create_materialized_view(['--auto-refresh',] 'view', 'query')
+	CreateProcedure = "dolt_create_materialized_view"
+	// RefreshProcedure is the stored procedure which refreshes a materialized view, or all of them:
+	//
+	//	CALL dolt_refresh_materialized_view(['--full',] {'view' | '--all'})
+	RefreshProcedure = "dolt_refresh_materialized_view"
+	// DropProcedure is the stored procedure which drops a materialized view:
+	//
+	//	CALL dolt_drop_materialized_view('view')
+	DropProcedure = "dolt_drop_materialized_view"
+)
+
+// Verb is what a materialized view statement does.
+type Verb int
+
+const (
+	Create Verb = iota
+	Refresh
+	Drop
+)
+
+// Statement is a statement of materialized views, which the parser doesn't support:
+//
+//	CREATE MATERIALIZED VIEW v [REFRESH ON COMMIT] AS SELECT ...
+//	REFRESH MATERIALIZED VIEW v [FULL]
+//	REFRESH ALL MATERIALIZED VIEWS [FULL]
+//	DROP MATERIALIZED VIEW v
+//
+// The statements are rewritten into calls of the stored procedures which run them. REFRESH ON COMMIT views are
+// refreshed by each dolt_commit, and FULL refreshes recompute views instead of refreshing them from the rows that
+// changed since their last refresh.
+type Statement struct {
+	Verb Verb
+	// Name is the name of the view, which is empty for REFRESH ALL MATERIALIZED VIEWS.
+	Name string
+	// Query is the query of a view created by the statement.
+	Query       string
+	AutoRefresh bool
+	Full        bool
+}
+
+// Parse parses |query| if it is a statement of materialized views, and returns it along with the index of the end of
+// the statement in |query|, past its terminating semicolon. Parse returns nil for other statements.
+func Parse(query string) (*Statement, int, error) {
+	s := &scanner{sqlscan.New(query)}
+	stmt := &Statement{}
+	var err error
+	switch {
+	case s.Keywords("create", "materialized", "view"):
+		stmt.Verb = Create
+		if stmt.Name, err = s.Ident(); err != nil {
+			return nil, 0, err
+		}
+		stmt.AutoRefresh = s.Keywords("refresh", "on", "commit")
+		if !s.Keywords("as") {
+			return nil, 0, s.Errorf("expected AS")
+		}
+		if stmt.Query, err = s.selectQuery(); err != nil {
+			return nil, 0, err
+		}
+	case s.Keywords("refresh", "materialized", "view"):
+		stmt.Verb = Refresh
+		if stmt.Name, err = s.Ident(); err != nil {
+			return nil, 0, err
+		}
+		stmt.Full = s.Keywords("full")
+	case s.Keywords("refresh", "all", "materialized", "views"):
+		stmt.Verb = Refresh
+		stmt.Full = s.Keywords("full")
+	case s.Keywords("drop", "materialized", "view"):
+		stmt.Verb = Drop
+		if stmt.Name, err = s.Ident(); err != nil {
+			return nil, 0, err
+		}
+	default:
+		return nil, 0, nil
+	}
+	if s.Peek() == '.' {
+		return nil, 0, fmt.Errorf("materialized views can only be named in the current database")
+	}
+	end, err := s.End()
+	if err != nil {
+		return nil, 0, err
+	}
+	return stmt, end, nil
+}
+
+// Call returns the CALL statement of the stored procedure which runs |stmt|.
+func (stmt *Statement) Call() *sqlparser.Call {
+	var proc string
+	var args []string
+	switch stmt.Verb {
+	case Create:
+		proc = CreateProcedure
+		if stmt.AutoRefresh {
+			args = append(args, "--auto-refresh")
+		}
+		args = append(args, stmt.Name, stmt.Query)
+	case Refresh:
+		proc = RefreshProcedure
+		if stmt.Full {
+			args = append(args, "--full")
+		}
+		if stmt.Name == "" {
+			args = append(args, "--all")
+		} else {
+			args = append(args, stmt.Name)
+		}
+	case Drop:
+		proc = DropProcedure
+		args = append(args, stmt.Name)
+	}
+	params := make([]sqlparser.Expr, len(args))
+	for i, arg := range args {
+		params[i] = sqlparser.NewStrVal([]byte(arg))
+	}
+	return &sqlparser.Call{
+		ProcName: sqlparser.ProcedureName{Name: sqlparser.NewColIdent(proc)},
+		Params:   params,
+	}
+}
+
+// selectQuery scans the query of a view, which ends with the statement.
+func (s *scanner) selectQuery() (string, error) {
+	s.Skip()
+	start := s.Pos
+	for s.Pos < len(s.Query) && s.Query[s.Pos] != ';' {
+		switch c := s.Query[s.Pos]; {
+		case sqlscan.IsQuote(c):
+			s.Pos = sqlscan.SkipQuoted(s.Query, s.Pos)
+		case c == '#' || c == '-' || c == '/':
+			i := s.Pos
+			s.Skip()
+			if s.Pos == i {
+				s.Pos++
+			}
+		default:
+			s.Pos++
+		}
+	}
+	query := strings.TrimSpace(s.Query[start:s.Pos])
+	if query == "" {
+		return "", s.Errorf("expected the query of the view")
+	}
+	return query, nil
+}
+
+// scanner scans the tokens of a statement.
+type scanner struct {
+	*sqlscan.Scanner
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matview
+
+import (
+	"testing"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *Statement
+		end      int
+		err      bool
+	}{
+		{
+			query: "create view v as select 1",
+		},
+		{
+			query: "refresh table t",
+		},
+		{
+			query:    "CREATE MATERIALIZED VIEW v AS SELECT id FROM t",
+			expected: &Statement{Verb: Create, Name: "v", Query: "SELECT id FROM t"},
+			end:      46,
+		},
+		{
+			query:    "create materialized view `my view` refresh on commit as select a from t where b = ';'; select 1",
+			expected: &Statement{Verb: Create, Name: "my view", Query: "select a from t where b = ';'", AutoRefresh: true},
+			end:      86,
+		},
+		{
+			query:    "refresh materialized view v",
+			expected: &Statement{Verb: Refresh, Name: "v"},
+			end:      27,
+		},
+		{
+			query:    "refresh materialized view v full;",
+			expected: &Statement{Verb: Refresh, Name: "v", Full: true},
+			end:      33,
+		},
+		{
+			query:    "refresh all materialized views full",
+			expected: &Statement{Verb: Refresh, Full: true},
+			end:      35,
+		},
+		{
+			query:    "drop materialized view v",
+			expected: &Statement{Verb: Drop, Name: "v"},
+			end:      24,
+		},
+		{
+			query: "create materialized view v as",
+			err:   true,
+		},
+		{
+			query: "create materialized view v select 1",
+			err:   true,
+		},
+		{
+			query: "refresh materialized view db.v",
+			err:   true,
+		},
+		{
+			query: "drop materialized view v cascade",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			actual, end, err := Parse(tt.query)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	stmt := &Statement{Verb: Create, Name: "v", Query: "select a from t", AutoRefresh: true}
+	assert.Equal(t, "call dolt_create_materialized_view('--auto-refresh', 'v', 'select a from t')", sqlparser.String(stmt.Call()))
+	stmt = &Statement{Verb: Refresh, Name: "v", Full: true}
+	assert.Equal(t, "call dolt_refresh_materialized_view('--full', 'v')", sqlparser.String(stmt.Call()))
+	stmt = &Statement{Verb: Refresh}
+	assert.Equal(t, "call dolt_refresh_materialized_view('--all')", sqlparser.String(stmt.Call()))
+	stmt = &Statement{Verb: Drop, Name: "v"}
+	assert.Equal(t, "call dolt_drop_materialized_view('v')", sqlparser.String(stmt.Call()))
+}
@@ -0,0 +1,250 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	ast "github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dprocedures"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/histogram"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/matview"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/partialindex"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/replicationfilter"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/rowpolicy"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlscan"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/systemtime"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/ttl"
+)
+
+// doltParser is a sql.Parser which extends the parser it wraps with the statements of Dolt that the parser does not
+// support. It rewrites the FOR SYSTEM_TIME clauses of statements into queries of the system time tables of Dolt, the
+// CREATE POLICY and DROP POLICY statements, the CREATE INDEX statements of partial indexes and expression indexes,
+// the ANALYZE TABLE statements of column histograms and the statements of materialized views into calls of the stored
+// procedures which run them, and the TTL options of CREATE TABLE and ALTER TABLE statements into the comments and
+// procedure calls which set them. It also parses the options of CHANGE REPLICATION FILTER statements which the
+// parser does not support, and rewrites SHOW REPLICAS into a call of the stored procedure which lists the replicas.
+type doltParser struct {
+	sql.Parser
+}
+
+var _ sql.Parser = doltParser{}
+
+// NewParser returns a sql.Parser which parses statements with |parser|, and supports:
+//   - the FOR SYSTEM_TIME clauses of SQL:2011 on Dolt tables. The clauses are resolved against the commit times of the
+//     current branch, and the rows they return include the ROW_START and ROW_END columns of their versions. A FOR
+//     SYSTEM_TIME AS OF clause whose time is a string naming a revision, such as 'HEAD~1', is an AS OF clause.
+//   - the CREATE POLICY and DROP POLICY statements of row-level security policies.
+//   - the TTL option of CREATE TABLE, and the ALTER TABLE ... TTL and ALTER TABLE ... REMOVE TTL statements.
+//   - the WHERE clause of CREATE INDEX statements, which create partial indexes, and their key parts which are
+//     expressions, such as (LOWER(email)).
+//   - the ANALYZE TABLE ... UPDATE HISTOGRAM and ANALYZE TABLE ... DROP HISTOGRAM statements of MySQL, which build and
+//     drop the histograms of columns which are not indexed.
+//   - the CREATE MATERIALIZED VIEW, REFRESH MATERIALIZED VIEW, REFRESH ALL MATERIALIZED VIEWS and DROP MATERIALIZED
+//     VIEW statements of materialized views.
+//   - the REPLICATE_DO_DB, REPLICATE_IGNORE_DB, REPLICATE_WILD_DO_TABLE, REPLICATE_WILD_IGNORE_TABLE and
+//     REPLICATE_REWRITE_DB options of CHANGE REPLICATION FILTER statements.
+//   - SHOW REPLICAS on a binlog primary, which lists the replicas streaming from it.
+func NewParser(parser sql.Parser) sql.Parser {
+	return doltParser{Parser: parser}
+}
+
+func (p doltParser) ParseSimple(query string) (ast.Statement, error) {
+	if stmt, _, ok, err := parseCall(query); ok || err != nil {
+		return stmt, err
+	}
+	prepared, t, err := prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.Parser.ParseSimple(prepared)
+	if err != nil {
+		return nil, err
+	}
+	return rewrite(stmt, t)
+}
+
+func (p doltParser) Parse(ctx *sql.Context, query string, multi bool) (ast.Statement, string, string, error) {
+	if stmt, end, ok, err := parseCall(query); ok || err != nil {
+		parsed, remainder := splitCall(query, end, multi)
+		return stmt, parsed, remainder, err
+	}
+	prepared, t, err := prepare(query)
+	if err != nil {
+		return nil, "", "", err
+	}
+	stmt, parsed, remainder, err := p.Parser.Parse(ctx, prepared, multi)
+	if err != nil {
+		return nil, "", "", err
+	}
+	stmt, err = rewrite(stmt, t)
+	return stmt, parsed, remainder, err
+}
+
+func (p doltParser) ParseWithOptions(ctx context.Context, query string, delimiter rune, multi bool, options ast.ParserOptions) (ast.Statement, string, string, error) {
+	if stmt, end, ok, err := parseCall(query); ok || err != nil {
+		parsed, remainder := splitCall(query, end, multi)
+		return stmt, parsed, remainder, err
+	}
+	prepared, t, err := prepare(query)
+	if err != nil {
+		return nil, "", "", err
+	}
+	stmt, parsed, remainder, err := p.Parser.ParseWithOptions(ctx, prepared, delimiter, multi, options)
+	if err != nil {
+		return nil, "", "", err
+	}
+	stmt, err = rewrite(stmt, t)
+	return stmt, parsed, remainder, err
+}
+
+func (p doltParser) ParseOneWithOptions(ctx context.Context, query string, options ast.ParserOptions) (ast.Statement, int, error) {
+	if stmt, end, ok, err := parseCall(query); ok || err != nil {
+		return stmt, end, err
+	}
+	prepared, t, err := prepare(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	stmt, ri, err := p.Parser.ParseOneWithOptions(ctx, prepared, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	stmt, err = rewrite(stmt, t)
+	return stmt, ri, err
+}
+
+// prepare returns |query| prepared to be parsed by the wrapped parser, along with the TTL option of its CREATE TABLE
+// statement, if it has one. The positions in the prepared query are the same as in |query|.
+func prepare(query string) (string, *ttl.TTL, error) {
+	prepared, t, err := ttl.Prepare(query)
+	if err != nil {
+		return "", nil, err
+	}
+	return systemtime.Prepare(prepared), t, nil
+}
+
+// rewrite rewrites |stmt|, parsed from a query prepared by prepare, into a statement the engine supports, which it
+// returns. |t| is the TTL option returned by prepare.
+func rewrite(stmt ast.Statement, t *ttl.TTL) (ast.Statement, error) {
+	if stmt == nil {
+		return nil, nil
+	}
+	if show, ok := stmt.(*ast.Show); ok && strings.EqualFold(show.Type, "replicas") {
+		return &ast.Call{ProcName: ast.ProcedureName{Name: ast.NewColIdent(dprocedures.ShowReplicasProcedure)}}, nil
+	}
+	if t != nil {
+		if err := ttl.Mark(stmt, *t); err != nil {
+			return nil, err
+		}
+	}
+	_, err := systemtime.Rewrite(stmt, doltdb.DoltSystemTimeTablePrefix)
+	return stmt, err
+}
+
+// parseCall parses |query| if it is a statement which is run by a stored procedure: a CREATE POLICY or DROP POLICY
+// statement, an ALTER TABLE statement which sets or removes the TTL of a table, a CREATE INDEX statement which
+// creates a partial index or an index of expressions, an ANALYZE TABLE statement which builds or drops the histograms
+// of columns, or a statement which creates, refreshes or drops materialized views. It returns
+// the call of the stored procedure, along with the end of the statement in |query|. It also parses CHANGE REPLICATION
+// FILTER statements with options the wrapped parser doesn't support, which are returned as they are. It returns false
+// for other statements. Only the parsers of the statements which start with the first keyword of |query| are run.
+func parseCall(query string) (ast.Statement, int, bool, error) {
+	for _, parse := range callParsers[strings.ToLower(sqlscan.New(query).Word())] {
+		stmt, end, err := parse(query)
+		if err != nil {
+			return nil, 0, true, err
+		}
+		if stmt != nil {
+			return stmt, end, true, nil
+		}
+	}
+	return nil, 0, false, nil
+}
+
+// callParser parses a query if it is one of the statements run by parseCall, and returns nil otherwise.
+type callParser func(query string) (ast.Statement, int, error)
+
+// callParsers are the parsers of parseCall, by the first keyword of their statements.
+var callParsers = map[string][]callParser{
+	"create":  {parsePolicy, parsePartialIndex, parseMaterializedView},
+	"drop":    {parsePolicy, parseMaterializedView},
+	"alter":   {parseTTL},
+	"analyze": {parseHistogram},
+	"refresh": {parseMaterializedView},
+	"change":  {parseReplicationFilter},
+}
+
+func parsePolicy(query string) (ast.Statement, int, error) {
+	stmt, end, err := rowpolicy.Parse(query)
+	if stmt == nil || err != nil {
+		return nil, 0, err
+	}
+	return rowpolicy.Call(stmt), end, nil
+}
+
+func parseTTL(query string) (ast.Statement, int, error) {
+	alter, end, err := ttl.ParseAlter(query)
+	if alter == nil || err != nil {
+		return nil, 0, err
+	}
+	return alter.Call(), end, nil
+}
+
+func parsePartialIndex(query string) (ast.Statement, int, error) {
+	create, end, err := partialindex.Parse(query)
+	if create == nil || err != nil {
+		return nil, 0, err
+	}
+	return create.Call(), end, nil
+}
+
+func parseHistogram(query string) (ast.Statement, int, error) {
+	analyze, end, err := histogram.Parse(query)
+	if analyze == nil || err != nil {
+		return nil, 0, err
+	}
+	return analyze.Call(), end, nil
+}
+
+func parseMaterializedView(query string) (ast.Statement, int, error) {
+	view, end, err := matview.Parse(query)
+	if view == nil || err != nil {
+		return nil, 0, err
+	}
+	return view.Call(), end, nil
+}
+
+func parseReplicationFilter(query string) (ast.Statement, int, error) {
+	filter, end, err := replicationfilter.Parse(query)
+	if filter == nil || err != nil {
+		return nil, 0, err
+	}
+	return filter, end, nil
+}
+
+// splitCall returns the statement ending at |end| in |query|, and the remainder of |query| if it is parsed as
+// multiple statements.
+func splitCall(query string, end int, multi bool) (string, string) {
+	parsed := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query[:end]), ";"))
+	if !multi {
+		return parsed, ""
+	}
+	return parsed, query[end:]
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	sqltypes "github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema/typeinfo"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/expranalysis"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/partialindex"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor/creation"
+	"github.com/dolthub/dolt/go/store/types"
+)
+
+// PartialIndexProcedures are the stored procedures that create partial indexes and indexes of expressions. The CREATE
+// INDEX ... WHERE statements, and the CREATE INDEX statements with expression key parts, are run by these procedures.
+var PartialIndexProcedures = []sql.ExternalStoredProcedureDetails{
+	{Name: partialindex.CreateProcedure, Schema: rowPolicyProcedureSchema, Function: doltCreatePartialIndex, AdminOnly: true},
+}
+
+// doltCreatePartialIndex is the stored procedure that creates a partial index, whose rows are the rows of its table
+// which satisfy its predicate, or an index whose key parts include expressions over the columns of its table. The
+// predicate is empty for an index of every row, and the key parts which are expressions are parenthesized:
+//
+//	CALL dolt_create_partial_index(['--unique',] 'table', 'index', 'predicate', 'key_part' [, 'key_part' ...])
+func doltCreatePartialIndex(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	unique := len(args) > 0 && args[0] == partialindex.UniqueFlag
+	if unique {
+		args = args[1:]
+	}
+	if len(args) < 4 {
+		return nil, fmt.Errorf("%s requires a table, an index name, a predicate and the key parts of the index", partialindex.CreateProcedure)
+	}
+	tableName, indexName, predicate, keyParts := args[0], args[1], args[2], args[3:]
+
+	db, ok, err := materializedViewDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("database %s does not support partial indexes", ctx.GetCurrentDatabase())
+	}
+	if err := dsess.CheckAccessForDb(ctx, db, branch_control.Permissions_Write); err != nil {
+		return nil, err
+	}
+
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, tableName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: tableName})
+	if err != nil {
+		return nil, err
+	}
+	if !ok || doltdb.HasDoltPrefix(tableName) {
+		return nil, sql.ErrTableNotFound.New(args[0])
+	}
+	if !types.IsFormat_DOLT(tbl.Format()) {
+		return nil, fmt.Errorf("partial indexes are not supported on storage format %s. Run `dolt migrate` to upgrade to the latest storage format.", tbl.Format().VersionString())
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if predicate != "" {
+		if _, err = expranalysis.ResolvePredicateExpression(ctx, tableName, sch, predicate); err != nil {
+			return nil, fmt.Errorf("invalid predicate for index %s: %w", indexName, err)
+		}
+	}
+
+	columns := make([]string, len(keyParts))
+	var expressions []schema.Column
+	for i, keyPart := range keyParts {
+		expr, ok := partialindex.IsExpression(keyPart)
+		if !ok {
+			columns[i] = keyPart
+			continue
+		}
+		col, err := indexExpressionColumn(ctx, tableName, sch, expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression for index %s: %w", indexName, err)
+		}
+		columns[i] = col.Name
+		expressions = append(expressions, col)
+	}
+
+	ret, err := creation.CreateIndex(ctx, tbl, tableName, indexName, columns, nil, schema.IndexProperties{
+		IsUnique:      unique,
+		IsUserDefined: true,
+		Predicate:     predicate,
+		Expressions:   expressions,
+	}, db.EditOptions())
+	if err != nil {
+		return nil, err
+	}
+	root, err = root.PutTable(ctx, doltdb.TableName{Name: tableName}, ret.NewTable)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.SetRoot(ctx, root); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// indexExpressionColumn returns the column of the key part of an index which is the expression |expr| over the
+// columns of |sch|. As in MySQL, the values of the expression can't be JSON documents or geometries, which have no
+// order as keys; the JSON values of an expression are indexed by casting or unquoting them.
+func indexExpressionColumn(ctx *sql.Context, tableName string, sch schema.Schema, expr string) (schema.Column, error) {
+	resolved, err := expranalysis.ResolveIndexExpression(ctx, tableName, sch, expr)
+	if err != nil {
+		return schema.Column{}, err
+	}
+	typ := resolved.Type()
+	if _, ok := typ.(sql.SpatialColumnType); ok || sqltypes.IsJSON(typ) {
+		return schema.Column{}, fmt.Errorf("cannot index the %s values of expression %s", typ.String(), expr)
+	}
+	typeInfo, err := typeinfo.FromSqlType(typ)
+	if err != nil {
+		return schema.Column{}, err
+	}
+	return schema.NewExpressionColumn(expr, typeInfo), nil
+}
+
+// validateIndexExpressions returns an error if the predicates or expressions of the indexes of |sch| reference columns
+// which are not columns of |sch|, such as the columns dropped or renamed by a schema change. Their text is stored with
+// the index and isn't rewritten by these changes, so the columns they depend on can't be dropped or renamed.
+func validateIndexExpressions(ctx *sql.Context, tableName string, sch schema.Schema) error {
+	for _, idx := range sch.Indexes().AllIndexes() {
+		if idx.Predicate() != "" {
+			if _, err := expranalysis.ResolvePredicateExpression(ctx, tableName, sch, idx.Predicate()); err != nil {
+				return fmt.Errorf("the predicate %s of index %s depends on a column which is not in the table", idx.Predicate(), idx.Name())
+			}
+		}
+		for _, col := range idx.Expressions() {
+			if _, err := expranalysis.ResolveIndexExpression(ctx, tableName, sch, col.Generated); err != nil {
+				return fmt.Errorf("the expression %s of index %s depends on a column which is not in the table", col.Generated, idx.Name())
+			}
+		}
+	}
+	return nil
+}
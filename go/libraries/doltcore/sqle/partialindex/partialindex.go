@@ -0,0 +1,238 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package partialindex parses the CREATE INDEX statements which create
+// partial indexes, whose rows are the rows of their table that satisfy a
+// predicate, and the indexes whose key parts are expressions over the columns
+// of their table rather than columns:
+//
+//	CREATE INDEX active_users ON users (email) WHERE deleted_at IS NULL
+//	CREATE UNIQUE INDEX one_default ON addresses (user_id) WHERE is_default
+//	CREATE UNIQUE INDEX email_ci ON users ((LOWER(email)))
+//
+// A unique partial index only enforces the uniqueness of the rows it holds.
+// The parser supports neither the WHERE clause of CREATE INDEX statements nor
+// their expression key parts, so the statements are rewritten into calls of
+// the stored procedure which runs them.
+package partialindex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlscan"
+)
+
+// CreateProcedure is the stored procedure which creates a partial index or an index of expressions. The predicate is
+// empty for an index of every row, and the key parts which are expressions are parenthesized:
+//
+//	CALL dolt_create_partial_index(['--unique',] 'table', 'index', 'predicate', 'key_part' [, 'key_part' ...])
+const CreateProcedure = "dolt_create_partial_index"
+
+// UniqueFlag is the option of CreateProcedure which creates a unique partial index.
+const UniqueFlag = "--unique"
+
+// Statement is a CREATE INDEX statement which creates a partial index or an index of expressions.
+type Statement struct {
+	Name  string
+	Table string
+	// Columns are the key parts of the index: the names of columns, and the parenthesized expressions, see
+	// IsExpression.
+	Columns []string
+	// Predicate is the predicate of the rows the index holds, or empty if it holds every row.
+	Predicate string
+	Unique    bool
+}
+
+// IsExpression returns whether the key part |keyPart| of a Statement is an expression rather than a column, and the
+// expression without its parentheses.
+func IsExpression(keyPart string) (string, bool) {
+	if len(keyPart) < 2 || keyPart[0] != '(' || keyPart[len(keyPart)-1] != ')' {
+		return "", false
+	}
+	return keyPart[1 : len(keyPart)-1], true
+}
+
+// Parse parses |query| if it is a CREATE INDEX statement with a WHERE clause or with expression key parts, and returns
+// it along with the index of the end of the statement in |query|, past its terminating semicolon. Parse returns nil
+// for other statements, including the CREATE INDEX statements of columns without a WHERE clause, which are left to
+// the parser.
+func Parse(query string) (*Statement, int, error) {
+	s := &scanner{sqlscan.New(query)}
+	if !s.Keywords("create") {
+		return nil, 0, nil
+	}
+	stmt := &Statement{}
+	stmt.Unique = s.Keywords("unique")
+	if !s.Keywords("index") {
+		return nil, 0, nil
+	}
+	var err error
+	if stmt.Name, err = s.Ident(); err != nil {
+		return nil, 0, nil
+	}
+	if !s.Keywords("on") {
+		return nil, 0, nil
+	}
+	if stmt.Table, err = s.Ident(); err != nil || s.Peek() == '.' {
+		return nil, 0, nil
+	}
+	var hasExpressions bool
+	if stmt.Columns, hasExpressions, err = s.keyParts(stmt.Table); err != nil {
+		return nil, 0, err
+	} else if stmt.Columns == nil {
+		return nil, 0, nil
+	}
+	if s.Keywords("where") {
+		if stmt.Predicate, err = s.predicate(stmt.Table); err != nil {
+			return nil, 0, err
+		}
+	} else if !hasExpressions {
+		return nil, 0, nil
+	}
+	end, err := s.End()
+	if err != nil {
+		return nil, 0, err
+	}
+	return stmt, end, nil
+}
+
+// Call returns the CALL statement of the stored procedure which runs |stmt|.
+func (stmt *Statement) Call() *sqlparser.Call {
+	var params []sqlparser.Expr
+	if stmt.Unique {
+		params = append(params, sqlparser.NewStrVal([]byte(UniqueFlag)))
+	}
+	params = append(params,
+		sqlparser.NewStrVal([]byte(stmt.Table)),
+		sqlparser.NewStrVal([]byte(stmt.Name)),
+		sqlparser.NewStrVal([]byte(stmt.Predicate)))
+	for _, col := range stmt.Columns {
+		params = append(params, sqlparser.NewStrVal([]byte(col)))
+	}
+	return &sqlparser.Call{
+		ProcName: sqlparser.ProcedureName{Name: sqlparser.NewColIdent(CreateProcedure)},
+		Params:   params,
+	}
+}
+
+// keyParts scans a parenthesized list of key parts over the rows of |table|, each of which may be followed by ASC or
+// DESC, and returns whether any of them is an expression. Key parts which are columns are identifiers, and those which
+// are expressions are parenthesized. keyParts returns nil without an error if the key parts are not a list of columns
+// and expressions, such as columns with prefix lengths, and an error if one of the expressions is invalid.
+func (s *scanner) keyParts(table string) (keyParts []string, hasExpressions bool, err error) {
+	if s.Peek() != '(' {
+		return nil, false, nil
+	}
+	s.Pos++
+	for {
+		if s.Peek() == '(' {
+			expr, err := s.expression(table)
+			if err != nil {
+				return nil, false, err
+			}
+			keyParts = append(keyParts, "("+expr+")")
+			hasExpressions = true
+		} else if col, err := s.Ident(); err == nil {
+			keyParts = append(keyParts, col)
+		} else {
+			return nil, false, nil
+		}
+		if !s.Keywords("asc") {
+			s.Keywords("desc")
+		}
+		switch s.Peek() {
+		case ',':
+			s.Pos++
+		case ')':
+			s.Pos++
+			return keyParts, hasExpressions, nil
+		default:
+			return nil, false, nil
+		}
+	}
+}
+
+// expression scans a parenthesized expression over the rows of |table|, and returns it without its parentheses, in the
+// canonical form of the parser, so that the same expressions have the same text however they are written.
+func (s *scanner) expression(table string) (string, error) {
+	start := s.Pos
+	depth := 0
+	for s.Pos < len(s.Query) {
+		c := s.Query[s.Pos]
+		if sqlscan.IsQuote(c) {
+			s.Pos = sqlscan.SkipQuoted(s.Query, s.Pos)
+			continue
+		}
+		s.Pos++
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			if depth--; depth == 0 {
+				break
+			}
+		}
+	}
+	if depth != 0 {
+		return "", s.Errorf("expected )")
+	}
+	text := strings.TrimSpace(s.Query[start+1 : s.Pos-1])
+	parsed, err := sqlparser.Parse(fmt.Sprintf("select %s from %s", text, sqlscan.QuoteIdent(table)))
+	if err != nil || text == "" {
+		return "", fmt.Errorf("invalid index expression: %s", text)
+	}
+	sel, ok := parsed.(*sqlparser.Select)
+	if !ok || len(sel.SelectExprs) != 1 {
+		return "", fmt.Errorf("invalid index expression: %s", text)
+	}
+	aliased, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok || !aliased.As.IsEmpty() {
+		return "", fmt.Errorf("invalid index expression: %s", text)
+	}
+	return sqlparser.String(aliased.Expr), nil
+}
+
+// predicate scans the predicate of a WHERE clause over the rows of |table|, which ends with the statement.
+func (s *scanner) predicate(table string) (string, error) {
+	s.Skip()
+	start := s.Pos
+	depth := 0
+	for s.Pos < len(s.Query) {
+		c := s.Query[s.Pos]
+		if sqlscan.IsQuote(c) {
+			s.Pos = sqlscan.SkipQuoted(s.Query, s.Pos)
+			continue
+		}
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			depth--
+		} else if c == ';' && depth == 0 {
+			break
+		}
+		s.Pos++
+	}
+	pred := strings.TrimSpace(s.Query[start:s.Pos])
+	if _, err := sqlparser.Parse(fmt.Sprintf("select * from %s where %s", sqlscan.QuoteIdent(table), pred)); err != nil || pred == "" {
+		return "", fmt.Errorf("invalid partial index predicate: %s", pred)
+	}
+	return pred, nil
+}
+
+// scanner scans the tokens of a statement.
+type scanner struct {
+	*sqlscan.Scanner
+}
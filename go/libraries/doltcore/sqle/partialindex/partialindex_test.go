@@ -0,0 +1,107 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partialindex
+
+import (
+	"testing"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *Statement
+		end      int
+		err      bool
+	}{
+		{
+			query: "create index i on t (a)",
+		},
+		{
+			query: "create table t (a int)",
+		},
+		{
+			query: "create index i on db.t (a) where a > 0",
+		},
+		{
+			query: "create index i on t (a(10)) where a > 0",
+		},
+		{
+			query:    "CREATE INDEX i ON t (a) WHERE a > 0",
+			expected: &Statement{Name: "i", Table: "t", Columns: []string{"a"}, Predicate: "a > 0"},
+			end:      35,
+		},
+		{
+			query:    "create unique index `my idx` on `t` (a desc, `b c`) where (a = 'x;y') and b is null; select 1",
+			expected: &Statement{Name: "my idx", Table: "t", Columns: []string{"a", "b c"}, Predicate: "(a = 'x;y') and b is null", Unique: true},
+			end:      84,
+		},
+		{
+			query:    "create unique index i on t ((LOWER( email )), b)",
+			expected: &Statement{Name: "i", Table: "t", Columns: []string{"(LOWER(email))", "b"}, Unique: true},
+			end:      48,
+		},
+		{
+			query:    "create index i on t ((json_extract(doc, '$.sku')) desc) where b > 0;",
+			expected: &Statement{Name: "i", Table: "t", Columns: []string{"(json_extract(doc, '$.sku'))"}, Predicate: "b > 0"},
+			end:      68,
+		},
+		{
+			query: "create index i on t ((a +))",
+			err:   true,
+		},
+		{
+			query: "create index i on t ((a)",
+		},
+		{
+			query: "create index i on t (a) where",
+			err:   true,
+		},
+		{
+			query: "create index i on t (a) where a >",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			actual, end, err := Parse(tt.query)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	stmt := &Statement{Name: "i", Table: "t", Columns: []string{"a", "b"}, Predicate: "a > 0"}
+	assert.Equal(t, "call dolt_create_partial_index('t', 'i', 'a > 0', 'a', 'b')", sqlparser.String(stmt.Call()))
+	stmt.Unique = true
+	assert.Equal(t, "call dolt_create_partial_index('--unique', 't', 'i', 'a > 0', 'a', 'b')", sqlparser.String(stmt.Call()))
+}
+
+func TestIsExpression(t *testing.T) {
+	expr, ok := IsExpression("(lower(email))")
+	assert.True(t, ok)
+	assert.Equal(t, "lower(email)", expr)
+	_, ok = IsExpression("email")
+	assert.False(t, ok)
+}
@@ -0,0 +1,241 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replicationfilter parses the CHANGE REPLICATION FILTER statements
+// which set the database, wildcard table and rewrite filters of a replica:
+//
+//	CHANGE REPLICATION FILTER REPLICATE_DO_DB = (db1, db2)
+//	CHANGE REPLICATION FILTER REPLICATE_WILD_IGNORE_TABLE = ('db1.tmp%')
+//	CHANGE REPLICATION FILTER REPLICATE_REWRITE_DB = ((db1, db2))
+//
+// The parser only supports the REPLICATE_DO_TABLE and REPLICATE_IGNORE_TABLE
+// options, so statements with any of the other options are parsed here. The
+// values of the other options are passed on as strings in the format of the
+// system variables of the same names, e.g. "db1,db2" and "db1->db2".
+package replicationfilter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlscan"
+)
+
+// The options of CHANGE REPLICATION FILTER statements.
+const (
+	DoTable         = "REPLICATE_DO_TABLE"
+	IgnoreTable     = "REPLICATE_IGNORE_TABLE"
+	DoDb            = "REPLICATE_DO_DB"
+	IgnoreDb        = "REPLICATE_IGNORE_DB"
+	WildDoTable     = "REPLICATE_WILD_DO_TABLE"
+	WildIgnoreTable = "REPLICATE_WILD_IGNORE_TABLE"
+	RewriteDb       = "REPLICATE_REWRITE_DB"
+)
+
+// options are the names of the options of CHANGE REPLICATION FILTER statements.
+var options = map[string]bool{
+	DoTable:         true,
+	IgnoreTable:     true,
+	DoDb:            true,
+	IgnoreDb:        true,
+	WildDoTable:     true,
+	WildIgnoreTable: true,
+	RewriteDb:       true,
+}
+
+// Parse parses |query| if it is a CHANGE REPLICATION FILTER statement with an option the parser doesn't support, and
+// returns it along with the index of the end of the statement in |query|, past its terminating semicolon. Parse
+// returns nil for other statements.
+func Parse(query string) (*sqlparser.ChangeReplicationFilter, int, error) {
+	s := &scanner{sqlscan.New(query)}
+	if !s.Keywords("change", "replication", "filter") {
+		return nil, 0, nil
+	}
+
+	stmt := &sqlparser.ChangeReplicationFilter{}
+	extended := false
+	for {
+		name := strings.ToUpper(s.Word())
+		if !options[name] {
+			return nil, 0, nil
+		}
+		if s.Peek() != '=' {
+			return nil, 0, s.Errorf("expected =")
+		}
+		s.Pos++
+
+		var value interface{}
+		var err error
+		switch name {
+		case DoTable, IgnoreTable:
+			value, err = s.tableNames()
+		case DoDb, IgnoreDb:
+			value, err = s.databases()
+			extended = true
+		case WildDoTable, WildIgnoreTable:
+			value, err = s.patterns()
+			extended = true
+		case RewriteDb:
+			value, err = s.rewrites()
+			extended = true
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		stmt.Options = append(stmt.Options, &sqlparser.ReplicationOption{Name: name, Value: value})
+
+		if s.Peek() != ',' {
+			break
+		}
+		s.Pos++
+	}
+
+	end, err := s.End()
+	if err != nil {
+		return nil, 0, err
+	}
+	if !extended {
+		return nil, 0, nil
+	}
+	return stmt, end, nil
+}
+
+// tableNames scans a parenthesized list of qualified table names.
+func (s *scanner) tableNames() (sqlparser.TableNames, error) {
+	var tables sqlparser.TableNames
+	err := s.list(func() error {
+		database, err := s.Ident()
+		if err != nil {
+			return err
+		}
+		if s.Peek() != '.' {
+			return s.Errorf("expected a table name qualified with its database")
+		}
+		s.Pos++
+		table, err := s.Ident()
+		if err != nil {
+			return err
+		}
+		tables = append(tables, sqlparser.TableName{
+			Name:        sqlparser.NewTableIdent(table),
+			DbQualifier: sqlparser.NewTableIdent(database),
+		})
+		return nil
+	})
+	return tables, err
+}
+
+// databases scans a parenthesized list of database names.
+func (s *scanner) databases() (string, error) {
+	var databases []string
+	err := s.list(func() error {
+		database, err := s.name()
+		if err != nil {
+			return err
+		}
+		databases = append(databases, database)
+		return nil
+	})
+	return strings.Join(databases, ","), err
+}
+
+// patterns scans a parenthesized list of quoted <database pattern>.<table pattern> patterns.
+func (s *scanner) patterns() (string, error) {
+	var patterns []string
+	err := s.list(func() error {
+		pattern, err := s.Str()
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(pattern, ".") {
+			return fmt.Errorf("invalid wildcard table pattern '%s'; expected <database pattern>.<table pattern>", pattern)
+		}
+		if strings.ContainsRune(pattern, ',') {
+			return fmt.Errorf("wildcard table patterns containing a comma are not supported: '%s'", pattern)
+		}
+		patterns = append(patterns, pattern)
+		return nil
+	})
+	return strings.Join(patterns, ","), err
+}
+
+// rewrites scans a parenthesized list of parenthesized (from database, to database) pairs.
+func (s *scanner) rewrites() (string, error) {
+	var rewrites []string
+	err := s.list(func() error {
+		var pair []string
+		err := s.list(func() error {
+			database, err := s.name()
+			if err != nil {
+				return err
+			}
+			pair = append(pair, database)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(pair) != 2 {
+			return fmt.Errorf("invalid database rewrite; expected (<from database>, <to database>)")
+		}
+		rewrites = append(rewrites, pair[0]+"->"+pair[1])
+		return nil
+	})
+	return strings.Join(rewrites, ","), err
+}
+
+// list scans a parenthesized, comma separated list, whose elements are scanned by |elem|. The list may be empty.
+func (s *scanner) list(elem func() error) error {
+	if s.Peek() != '(' {
+		return s.Errorf("expected (")
+	}
+	s.Pos++
+	if s.Peek() == ')' {
+		s.Pos++
+		return nil
+	}
+	for {
+		if err := elem(); err != nil {
+			return err
+		}
+		switch s.Peek() {
+		case ',':
+			s.Pos++
+		case ')':
+			s.Pos++
+			return nil
+		default:
+			return s.Errorf("expected , or )")
+		}
+	}
+}
+
+// name scans a database name, which cannot contain a comma or "->" since the option values are comma separated lists.
+func (s *scanner) name() (string, error) {
+	name, err := s.Ident()
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsRune(name, ',') || strings.Contains(name, "->") {
+		return "", fmt.Errorf("replication filters are not supported on the database %s, whose name contains ',' or '->'", name)
+	}
+	return name, nil
+}
+
+// scanner scans the tokens of a statement.
+type scanner struct {
+	*sqlscan.Scanner
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicationfilter
+
+import (
+	"testing"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []*sqlparser.ReplicationOption
+		end      int
+		err      bool
+	}{
+		{
+			query: "change replication source to source_host='localhost'",
+		},
+		{
+			query: "change replication filter replicate_do_table=(db01.t1)",
+		},
+		{
+			query: "change replication filter replicate_unknown=(db01)",
+		},
+		{
+			query: "CHANGE REPLICATION FILTER REPLICATE_DO_DB = (db01, `db 02`)",
+			expected: []*sqlparser.ReplicationOption{
+				{Name: DoDb, Value: "db01,db 02"},
+			},
+			end: 59,
+		},
+		{
+			query: "change replication filter replicate_ignore_db=(); select 1",
+			expected: []*sqlparser.ReplicationOption{
+				{Name: IgnoreDb, Value: ""},
+			},
+			end: 49,
+		},
+		{
+			query: `change replication filter replicate_wild_do_table=('db%.t\_%', "db02.%"), replicate_do_table=(db03.t)`,
+			expected: []*sqlparser.ReplicationOption{
+				{Name: WildDoTable, Value: `db%.t\_%,db02.%`},
+				{Name: DoTable, Value: sqlparser.TableNames{{
+					Name:        sqlparser.NewTableIdent("t"),
+					DbQualifier: sqlparser.NewTableIdent("db03"),
+				}}},
+			},
+			end: 101,
+		},
+		{
+			query: "change replication filter replicate_rewrite_db=((db01, db02), (db03,db04))",
+			expected: []*sqlparser.ReplicationOption{
+				{Name: RewriteDb, Value: "db01->db02,db03->db04"},
+			},
+			end: 74,
+		},
+		{
+			query: "change replication filter replicate_wild_ignore_table=('db01')",
+			err:   true,
+		},
+		{
+			query: "change replication filter replicate_rewrite_db=((db01))",
+			err:   true,
+		},
+		{
+			query: "change replication filter replicate_do_db=(`a,b`)",
+			err:   true,
+		},
+		{
+			query: "change replication filter replicate_do_db=(db01",
+			err:   true,
+		},
+		{
+			query: "change replication filter replicate_do_db=(db01), replicate_do_table=(t1)",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			actual, end, err := Parse(tt.query)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expected == nil {
+				assert.Nil(t, actual)
+				return
+			}
+			require.NotNil(t, actual)
+			assert.Equal(t, tt.expected, actual.Options)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
@@ -0,0 +1,754 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	gmstypes "github.com/dolthub/go-mysql-server/sql/types"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/dolt/go/cmd/dolt/cli"
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dtables"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/expranalysis"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/rowpolicy"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// ErrRowPolicyCheck is returned when a row written to a table is not allowed by the row-level security policies of
+// the table.
+var ErrRowPolicyCheck = errors.NewKind("new row violates row-level security policy for table %s")
+
+// ErrRowPolicyUsing is returned when a row updated or deleted from a table is not allowed by the row-level security
+// policies of the table.
+var ErrRowPolicyUsing = errors.NewKind("row-level security policy for table %s does not allow the %s of the row")
+
+// ErrRowPolicyTruncate is returned when a table whose rows are restricted by row-level security policies is truncated.
+var ErrRowPolicyTruncate = errors.NewKind("table %s cannot be truncated: its rows are restricted by row-level security policies")
+
+// ErrRowPolicyPatch is returned when the data changes of a table whose rows are restricted by row-level security
+// policies are patched.
+var ErrRowPolicyPatch = errors.NewKind("the data changes of table %s cannot be patched: its rows are restricted by row-level security policies")
+
+// ErrRowPolicyRefChange is returned when a user who is not exempt from row-level security policies runs a stored
+// procedure which could drop the policies or column masks of the default branch.
+var ErrRowPolicyRefChange = errors.NewKind("%s cannot change branch %s: its row-level security policies and column masks may only be changed by the administrators of the database")
+
+var rowPolicyProcedureSchema = sql.Schema{
+	&sql.Column{Name: "status", Type: gmstypes.Int64, Nullable: false},
+}
+
+// RowPolicyProcedures are the stored procedures that create and drop row-level security policies. The CREATE POLICY
+// and DROP POLICY statements are run by these procedures.
+var RowPolicyProcedures = []sql.ExternalStoredProcedureDetails{
+	{Name: "dolt_create_policy", Schema: rowPolicyProcedureSchema, Function: doltCreatePolicy, AdminOnly: true},
+	{Name: "dolt_drop_policy", Schema: rowPolicyProcedureSchema, Function: doltDropPolicy, AdminOnly: true},
+}
+
+func init() {
+	dtables.RowPolicyPredicate = func(ctx *sql.Context, dbName, tableName string) (sql.Expression, sql.Schema, error) {
+		return rowPolicyPredicate(ctx, dbName, tableName, rowpolicy.Select, false)
+	}
+}
+
+// doltCreatePolicy is the stored procedure that creates a row-level security policy from a CREATE POLICY statement.
+// Policies are stored in the dolt_schemas table, so they are versioned with the tables they apply to. The policies
+// which apply to every branch are those of the default branch.
+func doltCreatePolicy(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("dolt_create_policy requires a CREATE POLICY statement")
+	}
+	p, err := rowpolicy.ParsePolicy(args[0])
+	if err != nil {
+		return nil, err
+	}
+	db, err := currentRowPolicyDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, tableName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: p.Table})
+	if err != nil {
+		return nil, err
+	}
+	if !ok || doltdb.HasDoltPrefix(tableName) {
+		return nil, sql.ErrTableNotFound.New(p.Table)
+	}
+	p.Table = tableName
+
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pred := range []string{p.Using, p.Check} {
+		if pred == "" {
+			continue
+		}
+		if _, err = expranalysis.ResolvePredicateExpression(ctx, tableName, sch, pred); err != nil {
+			return nil, fmt.Errorf("invalid predicate for policy %s: %w", p.Name, err)
+		}
+	}
+
+	err = db.addFragToSchemasTable(ctx, policyFragment, p.Name, p.String(), time.Now(), fmt.Errorf("policy %s already exists", p.Name))
+	if err != nil {
+		return nil, err
+	}
+	warnNotDefaultBranch(ctx, db, "policy", p.Name)
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// doltDropPolicy is the stored procedure that drops a row-level security policy.
+func doltDropPolicy(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	apr, err := cli.DropPolicyArgParser().Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if apr.NArg() != 1 {
+		return nil, fmt.Errorf("dolt_drop_policy requires a policy name")
+	}
+	db, err := currentRowPolicyDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := apr.Arg(0)
+	policies, err := loadBranchRowPolicies(ctx, db.Name())
+	if err != nil {
+		return nil, err
+	}
+	var found bool
+	for _, p := range policies {
+		if strings.EqualFold(p.Name, name) {
+			if table, ok := apr.GetValue(cli.TableFlag); ok && !strings.EqualFold(p.Table, table) {
+				return nil, fmt.Errorf("policy %s is not a policy of table %s", name, table)
+			}
+			found = true
+		}
+	}
+	if !found {
+		if apr.Contains(cli.IfExistsFlag) {
+			return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+		}
+		return nil, fmt.Errorf("policy not found: %s", name)
+	}
+
+	err = db.dropFragFromSchemasTable(ctx, policyFragment, name, fmt.Errorf("policy not found: %s", name))
+	if err != nil {
+		return nil, err
+	}
+	warnNotDefaultBranch(ctx, db, "policy", name)
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// warnNotDefaultBranch warns that the change of the |kind| named |name| in |db| doesn't apply until it is merged into
+// the default branch, if |db| is not the default branch.
+func warnNotDefaultBranch(ctx *sql.Context, db Database, kind, name string) {
+	defaultDb, err := rowPolicyDatabaseName(ctx, db.Name())
+	if err != nil {
+		return
+	}
+	if _, head := dsess.SplitRevisionDbName(defaultDb); !strings.EqualFold(db.Revision(), head) {
+		ctx.Warn(1105, "the change of %s %s applies once it is merged into the default branch %s", kind, name, head)
+	}
+}
+
+// currentRowPolicyDatabase returns the current database, or an error if it cannot hold row-level security policies.
+func currentRowPolicyDatabase(ctx *sql.Context) (Database, error) {
+	db, ok, err := materializedViewDatabase(ctx)
+	if err != nil {
+		return Database{}, err
+	}
+	if !ok {
+		return Database{}, fmt.Errorf("database %s does not support row-level security policies", ctx.GetCurrentDatabase())
+	}
+	return db, nil
+}
+
+// rowPolicyCache holds the policies of dolt_schemas tables by the hash of the table, and the resolved predicates of
+// policies by table, schema and predicate.
+var rowPolicyCache = struct {
+	sync.Mutex
+	policies   map[hash.Hash][]rowpolicy.Policy
+	predicates map[string]sql.Expression
+}{
+	policies:   make(map[hash.Hash][]rowpolicy.Policy),
+	predicates: make(map[string]sql.Expression),
+}
+
+// rowPolicyCacheSize is the number of entries of each map of rowPolicyCache past which the map is cleared.
+const rowPolicyCacheSize = 1024
+
+// rowPolicyDatabaseName returns the name of the revision database whose dolt_schemas table holds the row-level
+// security policies and column masks which apply to the database |dbName|, which is the default branch of the
+// database. They are not read from the revision being queried, which could be a branch created from a commit before
+// they were created, or a branch which dropped their fragments.
+func rowPolicyDatabaseName(ctx *sql.Context, dbName string) (string, error) {
+	baseName, _ := dsess.SplitRevisionDbName(dbName)
+	db, ok := dsess.DSessFromSess(ctx.Session).Provider().BaseDatabase(ctx, baseName)
+	if !ok {
+		return "", sql.ErrDatabaseNotFound.New(baseName)
+	}
+	head, err := dsess.DefaultHead(baseName, db)
+	if err != nil {
+		return "", err
+	}
+	return dsess.RevisionDbName(baseName, head), nil
+}
+
+// loadRowPolicies returns the row-level security policies which apply to the database |dbName|, which are those of the
+// working root of its default branch.
+func loadRowPolicies(ctx *sql.Context, dbName string) ([]rowpolicy.Policy, error) {
+	defaultDb, err := rowPolicyDatabaseName(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	return loadBranchRowPolicies(ctx, defaultDb)
+}
+
+// loadBranchRowPolicies returns the row-level security policies of the working root of the database |dbName|.
+func loadBranchRowPolicies(ctx *sql.Context, dbName string) ([]rowpolicy.Policy, error) {
+	h, ok, err := workingSchemasTableHash(ctx, dbName)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	rowPolicyCache.Lock()
+	policies, ok := rowPolicyCache.policies[h]
+	rowPolicyCache.Unlock()
+	if ok {
+		return policies, nil
+	}
+
+	frags, err := workingSchemaFragments(ctx, dbName, policyFragment)
+	if err != nil {
+		return nil, err
+	}
+	for _, frag := range frags {
+		p, err := rowpolicy.ParsePolicy(frag.fragment)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+
+	rowPolicyCache.Lock()
+	defer rowPolicyCache.Unlock()
+	if len(rowPolicyCache.policies) >= rowPolicyCacheSize {
+		rowPolicyCache.policies = make(map[hash.Hash][]rowpolicy.Policy)
+	}
+	rowPolicyCache.policies[h] = policies
+	return policies, nil
+}
+
+// workingSchemasTableHash returns the hash of the dolt_schemas table of the working root of the database |dbName|, which
+// identifies the versions of the fragments of the table, or false if the working root has no dolt_schemas table.
+func workingSchemasTableHash(ctx *sql.Context, dbName string) (hash.Hash, bool, error) {
+	roots, ok := dsess.DSessFromSess(ctx.Session).GetRoots(ctx, dbName)
+	if !ok {
+		return hash.Hash{}, false, fmt.Errorf("unable to get roots for database '%s'", dbName)
+	}
+	tbl, ok, err := roots.Working.GetTable(ctx, doltdb.TableName{Name: doltdb.SchemasTableName})
+	if err != nil || !ok {
+		return hash.Hash{}, false, err
+	}
+	h, err := tbl.HashOf()
+	if err != nil {
+		return hash.Hash{}, false, err
+	}
+	return h, true, nil
+}
+
+// workingSchemaFragments returns the fragments of |fragType| of the dolt_schemas table of the working root of the
+// database |dbName|.
+func workingSchemaFragments(ctx *sql.Context, dbName string, fragType string) ([]schemaFragment, error) {
+	sqlDb, err := dsess.DSessFromSess(ctx.Session).Provider().Database(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	var db Database
+	switch d := sqlDb.(type) {
+	case Database:
+		db = d
+	case ReadReplicaDatabase:
+		db = d.Database
+	default:
+		return nil, nil
+	}
+	schTbl, _, err := db.GetTableInsensitive(ctx, doltdb.SchemasTableName)
+	if err != nil {
+		return nil, err
+	}
+	wrapper, ok := schTbl.(*SchemaTable)
+	if !ok {
+		return nil, fmt.Errorf("expected a SchemaTable, but found %T", schTbl)
+	}
+	if wrapper.backingTable == nil {
+		return nil, nil
+	}
+	return getSchemaFragmentsOfType(ctx, wrapper.backingTable, fragType)
+}
+
+// rowPolicyBypassKey is the key of the value of a context which exempts it from row-level security policies and
+// column masks. See WithoutRowPolicies.
+type rowPolicyBypassKey struct{}
+
+// WithoutRowPolicies returns a copy of |ctx| which reads and writes tables without the row-level security policies
+// and column masks of the current user. It is for the work the server does on behalf of every user of a table, such
+// as building statistics and refreshing materialized views, whose results must not depend on who triggered it.
+func WithoutRowPolicies(ctx *sql.Context) *sql.Context {
+	return ctx.WithContext(context.WithValue(ctx.Context, rowPolicyBypassKey{}, true))
+}
+
+// rowPolicyExempt returns whether the current user is exempt from the row-level security policies of the database
+// |dbName|, which is the case for the users who administer the database, and for contexts returned by
+// WithoutRowPolicies. Users whose privileges have not been checked yet are not exempt.
+func rowPolicyExempt(ctx *sql.Context, dbName string) bool {
+	if bypass, _ := ctx.Value(rowPolicyBypassKey{}).(bool); bypass {
+		return true
+	}
+	bas := branch_control.GetBranchAwareSession(ctx)
+	if bas == nil {
+		return true
+	}
+	baseName, _ := dsess.SplitRevisionDbName(dbName)
+	return branch_control.HasDatabasePrivileges(bas, baseName)
+}
+
+// rowPolicyPredicate returns the predicate of the row-level security policies of the table |tableName| that the rows
+// the current user uses for |cmd| must satisfy, or the rows they write for |cmd| if |check| is true. The predicate is
+// resolved against the schema of the table in the working root of the database |dbName|, which is also returned.
+// The policies are read from the working root of the default branch, so that historical versions of the table and
+// other branches are filtered by the current policies. Policies apply to the current user if they are granted to the
+// user or to one of their roles. rowPolicyPredicate returns a nil predicate if the policies do not restrict the rows.
+func rowPolicyPredicate(ctx *sql.Context, dbName, tableName string, cmd rowpolicy.Command, check bool) (sql.Expression, sql.Schema, error) {
+	if doltdb.HasDoltPrefix(tableName) || rowPolicyExempt(ctx, dbName) {
+		return nil, nil, nil
+	}
+	policies, err := loadRowPolicies(ctx, dbName)
+	if err != nil || !rowpolicy.Restricts(policies, tableName) {
+		return nil, nil, err
+	}
+
+	roles := []string{ctx.Session.Client().User}
+	if grants := maskingGrants(ctx); grants != nil && rowpolicy.HasRoles(policies, tableName) {
+		granted, err := grants.GrantedRoles(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		roles = append(roles, granted...)
+	}
+	var pred string
+	var ok bool
+	if check {
+		pred, ok = rowpolicy.WithCheck(policies, tableName, roles, cmd)
+	} else {
+		pred, ok = rowpolicy.Using(policies, tableName, roles, cmd)
+	}
+	if !ok {
+		return nil, nil, nil
+	}
+
+	roots, _ := dsess.DSessFromSess(ctx.Session).GetRoots(ctx, dbName)
+	tbl, name, ok, err := doltdb.GetTableInsensitive(ctx, roots.Working, doltdb.TableName{Name: tableName})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		// The policies of a table which no longer exists hide all of its history
+		return expression.NewLiteral(false, gmstypes.Boolean), sql.Schema{}, nil
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlSch, err := sqlutil.FromDoltSchema(dbName, name, sch)
+	if err != nil {
+		return nil, nil, err
+	}
+	schHash, err := tbl.GetSchemaHash(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := strings.Join([]string{name, schHash.String(), pred}, "\x00")
+	rowPolicyCache.Lock()
+	expr, ok := rowPolicyCache.predicates[key]
+	rowPolicyCache.Unlock()
+	if !ok {
+		expr, err = expranalysis.ResolvePredicateExpression(ctx, name, sch, pred)
+		if err != nil {
+			return nil, nil, err
+		}
+		rowPolicyCache.Lock()
+		if len(rowPolicyCache.predicates) >= rowPolicyCacheSize {
+			rowPolicyCache.predicates = make(map[string]sql.Expression)
+		}
+		rowPolicyCache.predicates[key] = expr
+		rowPolicyCache.Unlock()
+	}
+	return expr, sqlSch.Schema, nil
+}
+
+// rowPolicyFilter returns the filter of the rows of |t| that the current user may read, or nil if they may read
+// every row.
+func (t *DoltTable) rowPolicyFilter(ctx *sql.Context) (*dtables.RowPolicyFilter, error) {
+	return dtables.NewRowPolicyFilter(ctx, t.db.Name(), t.tableName)
+}
+
+// RowPolicyRestricted returns whether the rows of |t| the current user may read are restricted by row-level security
+// policies, in which case the rows must be read with the row iterators of |t|.
+func (t *DoltTable) RowPolicyRestricted(ctx *sql.Context) (bool, error) {
+	filter, err := t.rowPolicyFilter(ctx)
+	return filter != nil, err
+}
+
+// filterRowPolicy returns |iter|, which returns rows of all the columns of |t|, without the rows |filter| does not
+// allow. If |project| is true, the rows are projected to the projected columns of |t|.
+func (t *DoltTable) filterRowPolicy(filter *dtables.RowPolicyFilter, iter sql.RowIter, project bool) sql.RowIter {
+	iter = filter.RowIter(t.sqlSchema().Schema, iter)
+	if !project {
+		return iter
+	}
+	return t.projectRows(iter)
+}
+
+// projectRows returns the rows of |iter|, which are rows of all the columns of |t|, projected to the projected columns
+// of |t|.
+func (t *DoltTable) projectRows(iter sql.RowIter) sql.RowIter {
+	if t.projectedCols == nil {
+		return iter
+	}
+	cols := t.sch.GetAllCols()
+	ordinals := make([]int, len(t.projectedCols))
+	for i, tag := range t.projectedCols {
+		ordinals[i] = cols.TagToIdx[tag]
+	}
+	return &projectingRowIter{child: iter, ordinals: ordinals}
+}
+
+// rowPolicyIndexRows returns the rows of |part|, a partition of a lookup of |idx| on |t|, without the rows |filter| does
+// not allow, projected to the projected columns of |t|.
+func (t *DoltTable) rowPolicyIndexRows(ctx *sql.Context, filter *dtables.RowPolicyFilter, idx index.DoltIndex, key doltdb.DataCacheKey, isDoltFormat bool, part sql.Partition) (sql.RowIter, error) {
+	lb, err := index.NewIndexReaderBuilder(ctx, t, idx, key, t.allColumnTags(), t.sqlSch, isDoltFormat)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := withRowsExamined(ctx, lb.NewPartitionRowIter(ctx, part))
+	if err != nil {
+		return nil, err
+	}
+	return t.filterRowPolicy(filter, iter, true), nil
+}
+
+// allColumnTags returns the tags of all the columns of |t|, which are read instead of its projected columns when its
+// rows are filtered by row-level security policies.
+func (t *DoltTable) allColumnTags() []uint64 {
+	return t.sch.GetAllCols().Tags
+}
+
+// projectingRowIter returns the columns of the rows of its child at |ordinals|.
+type projectingRowIter struct {
+	child    sql.RowIter
+	ordinals []int
+}
+
+var _ sql.RowIter = (*projectingRowIter)(nil)
+
+func (itr *projectingRowIter) Next(ctx *sql.Context) (sql.Row, error) {
+	row, err := itr.child.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projected := make(sql.Row, len(itr.ordinals))
+	for i, j := range itr.ordinals {
+		projected[i] = row[j]
+	}
+	return projected, nil
+}
+
+func (itr *projectingRowIter) Close(ctx *sql.Context) error {
+	return itr.child.Close(ctx)
+}
+
+// withRowPolicies returns |te|, which writes the rows of |t|, with the checks of the row-level security policies of
+// |t| for the current user.
+func (t *WritableDoltTable) withRowPolicies(ctx *sql.Context, te dsess.TableWriter) (dsess.TableWriter, error) {
+	if doltdb.HasDoltPrefix(t.tableName) {
+		if t.tableName == doltdb.SchemasTableName && !rowPolicyExempt(ctx, t.db.Name()) {
+			return &schemasTableWriter{TableWriter: te, table: t}, nil
+		}
+		return te, nil
+	}
+	if rowPolicyExempt(ctx, t.db.Name()) {
+		return te, nil
+	}
+	policies, err := loadRowPolicies(ctx, t.db.Name())
+	if err != nil || !rowpolicy.Restricts(policies, t.tableName) {
+		return te, err
+	}
+	return &rowPolicyWriter{TableWriter: te, table: t}, nil
+}
+
+// rowPolicyWriter is a dsess.TableWriter which checks the rows written by its wrapped writer against the row-level
+// security policies of its table. The rows updated and deleted must be allowed by the SELECT policies of the table as
+// well as those of the statement, since the statement could otherwise write rows the user cannot read.
+type rowPolicyWriter struct {
+	dsess.TableWriter
+	table *WritableDoltTable
+}
+
+var _ dsess.TableWriter = (*rowPolicyWriter)(nil)
+
+// allows returns whether the row-level security policies of the table allow |row| for |cmd|.
+func (w *rowPolicyWriter) allows(ctx *sql.Context, row sql.Row, cmd rowpolicy.Command, check bool) (bool, error) {
+	pred, _, err := rowPolicyPredicate(ctx, w.table.db.Name(), w.table.tableName, cmd, check)
+	if err != nil || pred == nil {
+		return err == nil, err
+	}
+	res, err := sql.EvaluateCondition(ctx, pred, row)
+	if err != nil {
+		return false, err
+	}
+	return sql.IsTrue(res), nil
+}
+
+// checkUsing returns an error if the policies of the table do not allow the existing row |row| to be used for |cmd|.
+func (w *rowPolicyWriter) checkUsing(ctx *sql.Context, row sql.Row, cmd rowpolicy.Command) error {
+	for _, c := range []rowpolicy.Command{rowpolicy.Select, cmd} {
+		ok, err := w.allows(ctx, row, c, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrRowPolicyUsing.New(w.table.tableName, strings.ToUpper(string(cmd)))
+		}
+	}
+	return nil
+}
+
+// checkNew returns an error if the policies of the table do not allow the new row |row| to be written for |cmd|.
+func (w *rowPolicyWriter) checkNew(ctx *sql.Context, row sql.Row, cmd rowpolicy.Command) error {
+	ok, err := w.allows(ctx, row, cmd, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRowPolicyCheck.New(w.table.tableName)
+	}
+	return nil
+}
+
+func (w *rowPolicyWriter) Insert(ctx *sql.Context, row sql.Row) error {
+	if err := w.checkNew(ctx, row, rowpolicy.Insert); err != nil {
+		return err
+	}
+	return w.TableWriter.Insert(ctx, row)
+}
+
+func (w *rowPolicyWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := w.checkUsing(ctx, old, rowpolicy.Update); err != nil {
+		return err
+	}
+	if err := w.checkNew(ctx, new, rowpolicy.Update); err != nil {
+		return err
+	}
+	return w.TableWriter.Update(ctx, old, new)
+}
+
+func (w *rowPolicyWriter) Delete(ctx *sql.Context, row sql.Row) error {
+	if err := w.checkUsing(ctx, row, rowpolicy.Delete); err != nil {
+		return err
+	}
+	return w.TableWriter.Delete(ctx, row)
+}
+
+// schemasTableWriter is a dsess.TableWriter of the dolt_schemas table which prevents users who are not exempt from
+// row-level security policies from writing the rows of policies and column masks.
+type schemasTableWriter struct {
+	dsess.TableWriter
+	table *WritableDoltTable
+}
+
+var _ dsess.TableWriter = (*schemasTableWriter)(nil)
+
+func (w *schemasTableWriter) check(ctx *sql.Context, rows ...sql.Row) error {
+	typeIdx := w.table.sqlSchema().IndexOfColName(doltdb.SchemasTablesTypeCol)
+	for _, row := range rows {
+		if typ, ok := row[typeIdx].(string); ok && (strings.EqualFold(typ, policyFragment) || strings.EqualFold(typ, maskFragment)) {
+			return sql.ErrPrivilegeCheckFailed.New(ctx.Session.Client().User)
+		}
+	}
+	return nil
+}
+
+func (w *schemasTableWriter) Insert(ctx *sql.Context, row sql.Row) error {
+	if err := w.check(ctx, row); err != nil {
+		return err
+	}
+	return w.TableWriter.Insert(ctx, row)
+}
+
+func (w *schemasTableWriter) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := w.check(ctx, old, new); err != nil {
+		return err
+	}
+	return w.TableWriter.Update(ctx, old, new)
+}
+
+func (w *schemasTableWriter) Delete(ctx *sql.Context, row sql.Row) error {
+	if err := w.check(ctx, row); err != nil {
+		return err
+	}
+	return w.TableWriter.Delete(ctx, row)
+}
+
+// checkRowPolicyTruncate returns an error if the current user may not truncate |t| because of its row-level security
+// policies.
+func (t *WritableDoltTable) checkRowPolicyTruncate(ctx *sql.Context) error {
+	if doltdb.HasDoltPrefix(t.tableName) || rowPolicyExempt(ctx, t.db.Name()) {
+		return nil
+	}
+	policies, err := loadRowPolicies(ctx, t.db.Name())
+	if err != nil {
+		return err
+	}
+	if rowpolicy.Restricts(policies, t.tableName) {
+		return ErrRowPolicyTruncate.New(t.tableName)
+	}
+	return nil
+}
+
+// checkRowPolicyPatch returns an error if the current user may not read every row of the tables of |deltas|, whose
+// data patches would show the rows hidden by the policies of the tables.
+func checkRowPolicyPatch(ctx *sql.Context, dbName string, deltas []diff.TableDelta) error {
+	for _, td := range deltas {
+		name := td.ToName.Name
+		if name == "" {
+			name = td.FromName.Name
+		}
+		filter, err := dtables.NewRowPolicyFilter(ctx, dbName, name)
+		if err != nil {
+			return err
+		}
+		if filter != nil {
+			return ErrRowPolicyPatch.New(name)
+		}
+	}
+	return nil
+}
+
+// rowPolicyRefProcedures are the stored procedures which can change the working set or the head of a branch other
+// than by writing the rows of its tables, and so could drop the row-level security policies and column masks of the
+// default branch.
+var rowPolicyRefProcedures = map[string]bool{
+	"dolt_branch":            true,
+	"dolt_checkout":          true,
+	"dolt_cherry_pick":       true,
+	"dolt_clean":             true,
+	"dolt_conflicts_resolve": true,
+	"dolt_merge":             true,
+	"dolt_pull":              true,
+	"dolt_rebase":            true,
+	"dolt_reset":             true,
+	"dolt_revert":            true,
+}
+
+// withRowPolicyRefCheck returns |esp|, with a check which prevents users who are not exempt from row-level security
+// policies from running it on the default branch of a database with policies or column masks, if it is one of
+// rowPolicyRefProcedures.
+func withRowPolicyRefCheck(esp sql.ExternalStoredProcedureDetails) sql.ExternalStoredProcedureDetails {
+	fn, ok := esp.Function.(func(*sql.Context, ...string) (sql.RowIter, error))
+	if !ok || !rowPolicyRefProcedures[esp.Name] {
+		return esp
+	}
+	name := esp.Name
+	esp.Function = func(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+		if err := checkRowPolicyRefChange(ctx, name, args); err != nil {
+			return nil, err
+		}
+		return fn(ctx, args...)
+	}
+	return esp
+}
+
+// checkRowPolicyRefChange returns an error if the current user may not run the stored procedure |name| with |args|,
+// because it could drop the row-level security policies or column masks of the default branch of the current database.
+func checkRowPolicyRefChange(ctx *sql.Context, name string, args []string) error {
+	db, ok, err := materializedViewDatabase(ctx)
+	if err != nil || !ok || rowPolicyExempt(ctx, db.Name()) {
+		return err
+	}
+	defaultDb, err := rowPolicyDatabaseName(ctx, db.Name())
+	if err != nil {
+		return err
+	}
+	_, head := dsess.SplitRevisionDbName(defaultDb)
+
+	switch name {
+	case "dolt_branch":
+		// Only dolt_branch with options can move, overwrite or delete an existing branch
+		var opts, named bool
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "-") {
+				opts = true
+			} else if strings.EqualFold(arg, head) {
+				named = true
+			}
+		}
+		if !opts || !named {
+			return nil
+		}
+	case "dolt_checkout":
+		// dolt_checkout only changes the working set of the branch when it restores tables
+		var restores bool
+		for _, arg := range args {
+			restores = restores || arg == "." || strings.EqualFold(arg, doltdb.SchemasTableName)
+		}
+		if !restores || !strings.EqualFold(db.Revision(), head) {
+			return nil
+		}
+	default:
+		if !strings.EqualFold(db.Revision(), head) {
+			return nil
+		}
+	}
+
+	for _, fragType := range []string{policyFragment, maskFragment} {
+		frags, err := workingSchemaFragments(ctx, defaultDb, fragType)
+		if err != nil {
+			return err
+		}
+		if len(frags) > 0 {
+			return ErrRowPolicyRefChange.New(name, head)
+		}
+	}
+	return nil
+}
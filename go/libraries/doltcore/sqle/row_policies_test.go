@@ -0,0 +1,46 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/dolthub/dolt/go/libraries/doltcore/table/editor"
+)
+
+func TestRowPolicyExempt(t *testing.T) {
+	dEnv := dtestutils.CreateTestEnv()
+	tmpDir, err := dEnv.TempTableFilesDir()
+	require.NoError(t, err)
+	opts := editor.Options{Deaf: dEnv.DbEaFactory(), Tempdir: tmpDir}
+	db, err := NewDatabase(context.Background(), "dolt", dEnv.DbData(), opts)
+	require.NoError(t, err)
+
+	_, ctx, err := NewTestEngine(dEnv, context.Background(), db)
+	require.NoError(t, err)
+
+	// no statement has checked the privileges of the session yet
+	_, counter := ctx.GetPrivilegeSet()
+	require.Zero(t, counter)
+	assert.False(t, rowPolicyExempt(ctx, "dolt"))
+
+	assert.True(t, rowPolicyExempt(WithoutRowPolicies(ctx), "dolt"))
+	assert.False(t, rowPolicyExempt(ctx, "dolt"))
+}
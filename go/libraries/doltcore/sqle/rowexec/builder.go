@@ -256,6 +256,7 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 	var srcIter prolly.MapIter
 	var dstIter index.SecondaryLookupIterGen
 	var sch schema.Schema
+	var doltTable *sqle.DoltTable
 	switch n := n.(type) {
 	case *plan.TableAlias:
 		return getSourceKv(ctx, n.Child, isSrc)
@@ -267,8 +268,11 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 		return m, mIter, destIter, s, t, n.Expression, nil
 	case *plan.IndexedTableAccess:
 		var lb index.IndexScanBuilder
+		var idx index.DoltIndex
 		switch dt := n.UnderlyingTable().(type) {
 		case *sqle.WritableIndexedDoltTable:
+			doltTable = dt.DoltTable
+			idx = dt.Index()
 			tags = dt.ProjectedTags()
 			table, err = dt.DoltTable.DoltTable(ctx)
 			if err != nil {
@@ -279,6 +283,8 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 				return prolly.Map{}, nil, nil, nil, nil, nil, err
 			}
 		case *sqle.IndexedDoltTable:
+			doltTable = dt.DoltTable
+			idx = dt.Index()
 			tags = dt.ProjectedTags()
 			table, err = dt.DoltTable.DoltTable(ctx)
 			if err != nil {
@@ -293,6 +299,15 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 		default:
 			return prolly.Map{}, nil, nil, nil, nil, nil, nil
 		}
+		// The keys of lookup joins are not known to satisfy the predicates of partial indexes, so lookup joins of
+		// partial indexes read their rows with LookupPartitions
+		if !isSrc && index.IsPartial(idx) {
+			return prolly.Map{}, nil, nil, nil, nil, nil, nil
+		}
+		// Rows filtered by row-level security policies or masked by column masks are read with the row iterators of the table
+		if ok, err := doltTable.ReadRestricted(ctx); err != nil || ok {
+			return prolly.Map{}, nil, nil, nil, nil, nil, err
+		}
 
 		rowData, err := table.GetRowData(ctx)
 		if err != nil {
@@ -306,7 +321,11 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 				return prolly.Map{}, nil, nil, nil, nil, nil, err
 			}
 
-			prollyRanges, err := index.ProllyRangesForIndex(ctx, l.Index, l.Ranges)
+			// a lookup which a partial index cannot serve reads another index or a scan of the table, with LookupPartitions
+			if lookupIdx, ok := index.LookupIndex(idx, l); !ok || lookupIdx != idx {
+				return prolly.Map{}, nil, nil, nil, nil, nil, nil
+			}
+			prollyRanges, err := index.ProllyRangesForIndex(ctx, idx, l.Ranges)
 			if err != nil {
 				return prolly.Map{}, nil, nil, nil, nil, nil, err
 			}
@@ -322,12 +341,15 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 	case *plan.ResolvedTable:
 		switch dt := n.UnderlyingTable().(type) {
 		case *sqle.WritableDoltTable:
+			doltTable = dt.DoltTable
 			tags = dt.ProjectedTags()
 			table, err = dt.DoltTable.DoltTable(ctx)
 		case *sqle.AlterableDoltTable:
+			doltTable = dt.DoltTable
 			tags = dt.ProjectedTags()
 			table, err = dt.DoltTable.DoltTable(ctx)
 		case *sqle.DoltTable:
+			doltTable = dt
 			tags = dt.ProjectedTags()
 			table, err = dt.DoltTable(ctx)
 		default:
@@ -336,6 +358,9 @@ func getSourceKv(ctx *sql.Context, n sql.Node, isSrc bool) (prolly.Map, prolly.M
 		if err != nil {
 			return prolly.Map{}, nil, nil, nil, nil, nil, err
 		}
+		if ok, err := doltTable.ReadRestricted(ctx); err != nil || ok {
+			return prolly.Map{}, nil, nil, nil, nil, nil, err
+		}
 
 		sch, err = table.GetSchema(ctx)
 		if err != nil {
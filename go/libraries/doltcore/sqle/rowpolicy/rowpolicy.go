@@ -0,0 +1,369 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rowpolicy parses the CREATE POLICY and DROP POLICY statements of
+// row-level security, and computes the predicates that the policies of a
+// table impose on the rows a user reads and writes.
+//
+// Policies are permissive: a row may be used by a command if any of the
+// policies of its table which apply to the user and the command allows it. A
+// table with policies of which none applies allows no rows.
+package rowpolicy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlscan"
+)
+
+// Command is the kind of statement a policy applies to.
+type Command string
+
+const (
+	All    Command = "all"
+	Select Command = "select"
+	Insert Command = "insert"
+	Update Command = "update"
+	Delete Command = "delete"
+)
+
+// Public is the role of policies which apply to every user.
+const Public = "public"
+
+// Policy is a row-level security policy of a table.
+type Policy struct {
+	Name  string
+	Table string
+	// Command is the kind of statement the policy applies to.
+	Command Command
+	// Roles are the users and roles the policy applies to. A policy without roles applies to every user.
+	Roles []string
+	// Using is the predicate of the existing rows the policy allows, or empty if it allows every row.
+	Using string
+	// Check is the predicate of the new rows the policy allows. If it is empty, Using is used instead.
+	Check string
+}
+
+// Drop is a DROP POLICY statement.
+type Drop struct {
+	Name     string
+	Table    string
+	IfExists bool
+}
+
+// String returns the CREATE POLICY statement of |p|.
+func (p Policy) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE POLICY %s ON %s FOR %s", sqlscan.QuoteIdent(p.Name), sqlscan.QuoteIdent(p.Table), strings.ToUpper(string(p.Command)))
+	if len(p.Roles) > 0 {
+		roles := make([]string, len(p.Roles))
+		for i, r := range p.Roles {
+			roles[i] = sqlscan.QuoteIdent(r)
+		}
+		fmt.Fprintf(&sb, " TO %s", strings.Join(roles, ", "))
+	}
+	if p.Using != "" {
+		fmt.Fprintf(&sb, " USING (%s)", p.Using)
+	}
+	if p.Check != "" {
+		fmt.Fprintf(&sb, " WITH CHECK (%s)", p.Check)
+	}
+	return sb.String()
+}
+
+// appliesTo returns whether |p| applies to the user with the names |roles| reading or writing the rows of |table| for
+// |cmd|.
+func (p Policy) appliesTo(table string, roles []string, cmd Command) bool {
+	if !strings.EqualFold(p.Table, table) || (p.Command != All && p.Command != cmd) {
+		return false
+	}
+	if len(p.Roles) == 0 {
+		return true
+	}
+	for _, r := range p.Roles {
+		for _, name := range roles {
+			if r == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Restricts returns whether any of |policies| is a policy of |table|.
+func Restricts(policies []Policy, table string) bool {
+	for _, p := range policies {
+		if strings.EqualFold(p.Table, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRoles returns whether any of the |policies| of |table| only applies to some users.
+func HasRoles(policies []Policy, table string) bool {
+	for _, p := range policies {
+		if strings.EqualFold(p.Table, table) && len(p.Roles) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Using returns the predicate that the existing rows of |table| must satisfy for the user whose name, and the names
+// of whose roles, are |roles| to use them for |cmd|, and whether |policies| restrict them at all.
+func Using(policies []Policy, table string, roles []string, cmd Command) (string, bool) {
+	return predicate(policies, table, roles, cmd, func(p Policy) string {
+		return p.Using
+	})
+}
+
+// WithCheck returns the predicate that the rows written to |table| for |cmd| by the user whose name, and the names of
+// whose roles, are |roles| must satisfy, and whether |policies| restrict them at all.
+func WithCheck(policies []Policy, table string, roles []string, cmd Command) (string, bool) {
+	return predicate(policies, table, roles, cmd, func(p Policy) string {
+		if p.Check != "" {
+			return p.Check
+		}
+		return p.Using
+	})
+}
+
+func predicate(policies []Policy, table string, roles []string, cmd Command, pred func(Policy) string) (string, bool) {
+	if !Restricts(policies, table) {
+		return "", false
+	}
+	var preds []string
+	for _, p := range policies {
+		if !p.appliesTo(table, roles, cmd) {
+			continue
+		}
+		e := pred(p)
+		if e == "" {
+			return "", false
+		}
+		preds = append(preds, "("+e+")")
+	}
+	if len(preds) == 0 {
+		return "FALSE", true
+	}
+	return strings.Join(preds, " OR "), true
+}
+
+// Parse parses |query| if it is a CREATE POLICY or DROP POLICY statement, which it returns as a *Policy or a *Drop,
+// along with the index of the end of the statement in |query|, past its terminating semicolon. Parse returns a nil
+// statement for other statements.
+func Parse(query string) (interface{}, int, error) {
+	s := &scanner{sqlscan.New(query)}
+	switch {
+	case s.Keywords("create", "policy"):
+		p, err := s.create()
+		if err != nil {
+			return nil, 0, err
+		}
+		end, err := s.End()
+		if err != nil {
+			return nil, 0, err
+		}
+		return &p, end, nil
+	case s.Keywords("drop", "policy"):
+		d, err := s.drop()
+		if err != nil {
+			return nil, 0, err
+		}
+		end, err := s.End()
+		if err != nil {
+			return nil, 0, err
+		}
+		return d, end, nil
+	default:
+		return nil, 0, nil
+	}
+}
+
+// ParsePolicy parses the CREATE POLICY statement |query|.
+func ParsePolicy(query string) (Policy, error) {
+	stmt, _, err := Parse(query)
+	if err != nil {
+		return Policy{}, err
+	}
+	p, ok := stmt.(*Policy)
+	if !ok {
+		return Policy{}, fmt.Errorf("expected a CREATE POLICY statement: %s", query)
+	}
+	return *p, nil
+}
+
+// Call returns the CALL statement of the stored procedure which runs |stmt|, a statement returned by Parse.
+func Call(stmt interface{}) *sqlparser.Call {
+	switch stmt := stmt.(type) {
+	case *Policy:
+		return call("dolt_create_policy", stmt.String())
+	case *Drop:
+		var args []string
+		if stmt.IfExists {
+			args = append(args, "--if-exists")
+		}
+		args = append(args, stmt.Name)
+		if stmt.Table != "" {
+			args = append(args, "--table", stmt.Table)
+		}
+		return call("dolt_drop_policy", args...)
+	default:
+		return nil
+	}
+}
+
+func call(name string, args ...string) *sqlparser.Call {
+	params := make([]sqlparser.Expr, len(args))
+	for i, a := range args {
+		params[i] = sqlparser.NewStrVal([]byte(a))
+	}
+	return &sqlparser.Call{
+		ProcName: sqlparser.ProcedureName{Name: sqlparser.NewColIdent(name)},
+		Params:   params,
+	}
+}
+
+func (s *scanner) create() (Policy, error) {
+	var p Policy
+	var err error
+	if p.Name, err = s.Ident(); err != nil {
+		return Policy{}, err
+	}
+	if !s.Keywords("on") {
+		return Policy{}, s.Errorf("expected ON")
+	}
+	if p.Table, err = s.Ident(); err != nil {
+		return Policy{}, err
+	}
+	if s.Peek() == '.' {
+		return Policy{}, s.Errorf("the table of a policy cannot be qualified by a database")
+	}
+
+	p.Command = All
+	if s.Keywords("as") {
+		if !s.Keywords("permissive") {
+			return Policy{}, s.Errorf("only PERMISSIVE policies are supported")
+		}
+	}
+	if s.Keywords("for") {
+		w := Command(strings.ToLower(s.Word()))
+		switch w {
+		case All, Select, Insert, Update, Delete:
+			p.Command = w
+		default:
+			return Policy{}, s.Errorf("expected ALL, SELECT, INSERT, UPDATE or DELETE")
+		}
+	}
+	if s.Keywords("to") {
+		public := false
+		for {
+			r, err := s.role()
+			if err != nil {
+				return Policy{}, err
+			}
+			if strings.EqualFold(r, Public) {
+				public = true
+			} else {
+				p.Roles = append(p.Roles, r)
+			}
+			if s.Peek() != ',' {
+				break
+			}
+			s.Pos++
+		}
+		if public {
+			p.Roles = nil
+		}
+	}
+	if s.Keywords("using") {
+		if p.Using, err = s.predicate(p.Table); err != nil {
+			return Policy{}, err
+		}
+	}
+	if s.Keywords("with", "check") {
+		if p.Command == Select || p.Command == Delete {
+			return Policy{}, s.Errorf("WITH CHECK cannot be applied to %s policies", strings.ToUpper(string(p.Command)))
+		}
+		if p.Check, err = s.predicate(p.Table); err != nil {
+			return Policy{}, err
+		}
+	}
+	if p.Using != "" && p.Command == Insert {
+		return Policy{}, s.Errorf("USING cannot be applied to INSERT policies")
+	}
+	return p, nil
+}
+
+func (s *scanner) drop() (*Drop, error) {
+	var d Drop
+	var err error
+	d.IfExists = s.Keywords("if", "exists")
+	if d.Name, err = s.Ident(); err != nil {
+		return nil, err
+	}
+	if s.Keywords("on") {
+		if d.Table, err = s.Ident(); err != nil {
+			return nil, err
+		}
+	}
+	return &d, nil
+}
+
+// predicate scans a parenthesized predicate over the rows of |table|, and returns it without the parentheses.
+func (s *scanner) predicate(table string) (string, error) {
+	if s.Peek() != '(' {
+		return "", s.Errorf("expected (")
+	}
+	start := s.Pos + 1
+	depth := 0
+	for s.Pos < len(s.Query) {
+		switch c := s.Query[s.Pos]; {
+		case sqlscan.IsQuote(c):
+			s.Pos = sqlscan.SkipQuoted(s.Query, s.Pos)
+			continue
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				pred := strings.TrimSpace(s.Query[start:s.Pos])
+				s.Pos++
+				if _, err := sqlparser.Parse(fmt.Sprintf("select * from %s where %s", sqlscan.QuoteIdent(table), pred)); err != nil || pred == "" {
+					return "", fmt.Errorf("invalid policy predicate: %s", pred)
+				}
+				return pred, nil
+			}
+		}
+		s.Pos++
+	}
+	return "", s.Errorf("expected )")
+}
+
+// role scans a user name, which is either an identifier or a quoted string.
+func (s *scanner) role() (string, error) {
+	if c := s.Peek(); c == '\'' || c == '"' {
+		return s.Str()
+	}
+	return s.Ident()
+}
+
+// scanner scans the tokens of a statement.
+type scanner struct {
+	*sqlscan.Scanner
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rowpolicy
+
+import (
+	"testing"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected interface{}
+		end      int
+		err      bool
+	}{
+		{
+			query: "select * from t",
+		},
+		{
+			query:    "CREATE POLICY p ON t USING (owner = current_user())",
+			expected: &Policy{Name: "p", Table: "t", Command: All, Using: "owner = current_user()"},
+			end:      51,
+		},
+		{
+			query: "create policy `my policy` on `t` as permissive for update to alice, 'bob' " +
+				"using (a > (b + 1)) with check (c = ')') ; select 1",
+			expected: &Policy{Name: "my policy", Table: "t", Command: Update, Roles: []string{"alice", "bob"}, Using: "a > (b + 1)", Check: "c = ')'"},
+			end:      116,
+		},
+		{
+			query:    "/* comment */ create policy p on t for select to alice, public using (true)",
+			expected: &Policy{Name: "p", Table: "t", Command: Select, Using: "true"},
+			end:      75,
+		},
+		{
+			query:    "drop policy p",
+			expected: &Drop{Name: "p"},
+			end:      13,
+		},
+		{
+			query:    "DROP POLICY IF EXISTS p ON t;",
+			expected: &Drop{Name: "p", Table: "t", IfExists: true},
+			end:      29,
+		},
+		{
+			query: "create policy p on db.t using (a)",
+			err:   true,
+		},
+		{
+			query: "create policy p on t for truncate using (a)",
+			err:   true,
+		},
+		{
+			query: "create policy p on t using (a",
+			err:   true,
+		},
+		{
+			query: "create policy p on t using (a +)",
+			err:   true,
+		},
+		{
+			query: "create policy p on t for select with check (a)",
+			err:   true,
+		},
+		{
+			query: "create policy p on t for insert using (a)",
+			err:   true,
+		},
+		{
+			query: "create policy p on t using (a) extra",
+			err:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, end, err := Parse(test.query)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, stmt)
+			assert.Equal(t, test.end, end)
+		})
+	}
+}
+
+func TestPolicyString(t *testing.T) {
+	policies := []Policy{
+		{Name: "p", Table: "t", Command: All, Using: "a = 1"},
+		{Name: "p`q", Table: "my table", Command: Update, Roles: []string{"alice", "bob"}, Using: "a = 'x'", Check: "b > 0"},
+		{Name: "p", Table: "t", Command: Insert, Check: "b > 0"},
+	}
+	for _, p := range policies {
+		t.Run(p.String(), func(t *testing.T) {
+			parsed, err := ParsePolicy(p.String())
+			require.NoError(t, err)
+			assert.Equal(t, p, parsed)
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	p := &Policy{Name: "p", Table: "t", Command: Select, Using: "a = 'x'"}
+	assert.Equal(t, "call dolt_create_policy('CREATE POLICY `p` ON `t` FOR SELECT USING (a = \\'x\\')')", sqlparser.String(Call(p)))
+	assert.Equal(t, "call dolt_drop_policy('--if-exists', 'p', '--table', 't')", sqlparser.String(Call(&Drop{Name: "p", Table: "t", IfExists: true})))
+	assert.Equal(t, "call dolt_drop_policy('p')", sqlparser.String(Call(&Drop{Name: "p"})))
+}
+
+func TestPredicates(t *testing.T) {
+	policies := []Policy{
+		{Name: "own", Table: "t", Command: All, Using: "owner = current_user()"},
+		{Name: "public_read", Table: "t", Command: Select, Using: "public = 1"},
+		{Name: "alice_insert", Table: "t", Command: Insert, Roles: []string{"alice"}, Check: "owner = 'alice'"},
+		{Name: "bob_all", Table: "T", Command: Update, Roles: []string{"bob"}},
+		{Name: "u_read", Table: "u", Command: Select, Roles: []string{"alice"}, Using: "a > 0"},
+		{Name: "w_read", Table: "w", Command: Select, Roles: []string{"readers"}, Using: "a > 1"},
+	}
+
+	tests := []struct {
+		table, user string
+		// roles are the roles granted to the user
+		roles      []string
+		cmd        Command
+		using      string
+		check      string
+		restricted bool
+		// checkOnly is set when only the WithCheck predicate is restricted
+		checkOnly bool
+	}{
+		{table: "t", user: "alice", cmd: Select, using: "(owner = current_user()) OR (public = 1)", check: "(owner = current_user()) OR (public = 1)", restricted: true},
+		{table: "t", user: "alice", cmd: Insert, check: "(owner = current_user()) OR (owner = 'alice')", restricted: true, checkOnly: true},
+		{table: "t", user: "bob", cmd: Insert, using: "(owner = current_user())", check: "(owner = current_user())", restricted: true},
+		{table: "t", user: "bob", cmd: Update},
+		{table: "t", user: "bob", cmd: Delete, using: "(owner = current_user())", check: "(owner = current_user())", restricted: true},
+		{table: "u", user: "alice", cmd: Select, using: "(a > 0)", check: "(a > 0)", restricted: true},
+		{table: "u", user: "bob", cmd: Select, using: "FALSE", check: "FALSE", restricted: true},
+		{table: "v", user: "bob", cmd: Select},
+		{table: "w", user: "carol", roles: []string{"writers", "readers"}, cmd: Select, using: "(a > 1)", check: "(a > 1)", restricted: true},
+		{table: "w", user: "readers", cmd: Select, using: "(a > 1)", check: "(a > 1)", restricted: true},
+		{table: "w", user: "dave", roles: []string{"writers"}, cmd: Select, using: "FALSE", check: "FALSE", restricted: true},
+	}
+	for _, test := range tests {
+		t.Run(test.table+" "+test.user+" "+string(test.cmd), func(t *testing.T) {
+			roles := append([]string{test.user}, test.roles...)
+			using, ok := Using(policies, test.table, roles, test.cmd)
+			assert.Equal(t, test.restricted && !test.checkOnly, ok)
+			assert.Equal(t, test.using, using)
+			check, ok := WithCheck(policies, test.table, roles, test.cmd)
+			assert.Equal(t, test.restricted, ok)
+			assert.Equal(t, test.check, check)
+		})
+	}
+}
+
+func TestHasRoles(t *testing.T) {
+	policies := []Policy{
+		{Name: "own", Table: "t", Command: All, Using: "owner = current_user()"},
+		{Name: "u_read", Table: "u", Command: Select, Roles: []string{"readers"}, Using: "a > 0"},
+	}
+	assert.False(t, HasRoles(policies, "t"))
+	assert.True(t, HasRoles(policies, "U"))
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"sync/atomic"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+)
+
+// rowsExaminedIter is a sql.RowIter which counts the rows read from a table
+// for the slow query log.
+type rowsExaminedIter struct {
+	sql.RowIter
+	counter *atomic.Uint64
+}
+
+// withRowsExamined wraps |iter| so that the rows it returns are counted as
+// examined by the session of |ctx|, if the session is tracking them.
+func withRowsExamined(ctx *sql.Context, iter sql.RowIter, err error) (sql.RowIter, error) {
+	if err != nil || iter == nil {
+		return iter, err
+	}
+	sess, ok := ctx.Session.(*dsess.DoltSession)
+	if !ok {
+		return iter, nil
+	}
+	counter := sess.RowsExaminedCounter()
+	if counter == nil {
+		return iter, nil
+	}
+	return rowsExaminedIter{RowIter: iter, counter: counter}, nil
+}
+
+func (i rowsExaminedIter) Next(ctx *sql.Context) (sql.Row, error) {
+	r, err := i.RowIter.Next(ctx)
+	if err == nil {
+		i.counter.Add(1)
+	}
+	return r, err
+}
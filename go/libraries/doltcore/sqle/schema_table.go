@@ -34,9 +34,12 @@ import (
 )
 
 const (
-	viewFragment    = "view"
-	triggerFragment = "trigger"
-	eventFragment   = "event"
+	viewFragment             = "view"
+	triggerFragment          = "trigger"
+	eventFragment            = "event"
+	materializedViewFragment = "materialized_view"
+	policyFragment           = "policy"
+	maskFragment             = "mask"
 )
 
 type Extra struct {
@@ -17,12 +17,16 @@ package sqle
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	sqle "github.com/dolthub/go-mysql-server"
 	"github.com/dolthub/go-mysql-server/sql"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlfmt"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/writer"
 )
 
@@ -41,6 +45,9 @@ func PrepareCreateTableStmt(ctx context.Context, sqlDb dsess.SqlDatabase) (*sql.
 	return sqlCtx, engine, sess
 }
 
+// GetCreateTableStmt returns the CREATE TABLE statement of the table |tableName| in the current database of |ctx|. The
+// partial indexes and indexes of expressions of the table are left out of the statement, as the parser cannot read
+// them in CREATE TABLE statements, and are created by the CREATE INDEX statements which follow it.
 func GetCreateTableStmt(ctx *sql.Context, engine *sqle.Engine, tableName string) (string, error) {
 	_, rowIter, err := engine.Query(ctx, fmt.Sprintf("SHOW CREATE TABLE `%s`;", tableName))
 	if err != nil {
@@ -57,5 +64,75 @@ func GetCreateTableStmt(ctx *sql.Context, engine *sqle.Engine, tableName string)
 	if !ok {
 		return "", fmt.Errorf("expected string statement from SHOW CREATE TABLE")
 	}
-	return stmt + ";", nil
+	sch, ok, err := tableSchema(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return stmt + ";", nil
+	}
+	stmts := []string{removeIndexDefinitions(stmt, sch) + ";"}
+	return strings.Join(append(stmts, sqlfmt.CreateIndexStmts(tableName, sch)...), "\n"), nil
+}
+
+// tableSchema returns the schema of the table |tableName| in the current database of |ctx|, or false if there is no
+// such Dolt table.
+func tableSchema(ctx *sql.Context, tableName string) (schema.Schema, bool, error) {
+	sess := dsess.DSessFromSess(ctx.Session)
+	db, err := sess.Provider().Database(ctx, ctx.GetCurrentDatabase())
+	if err != nil {
+		return nil, false, err
+	}
+	tbl, ok, err := db.GetTableInsensitive(ctx, tableName)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	dt, ok := tbl.(index.DoltTableable)
+	if !ok {
+		return nil, false, nil
+	}
+	table, err := dt.DoltTable(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	sch, err := table.GetSchema(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return sch, true, nil
+}
+
+// removeIndexDefinitions returns |createTable|, the CREATE TABLE statement printed by the engine for a table whose
+// schema is |sch|, without the lines which define the indexes of |sch| that CREATE TABLE statements cannot define,
+// see sqlfmt.IsCreateIndexOnly. The engine prints their key parts which are columns without the predicates of partial
+// indexes, and cannot print their key parts which are expressions.
+func removeIndexDefinitions(createTable string, sch schema.Schema) string {
+	var prefixes []string
+	for _, idx := range sch.Indexes().AllIndexes() {
+		if sqlfmt.IsCreateIndexOnly(idx) {
+			name := sqlfmt.QuoteIdentifier(idx.Name())
+			prefixes = append(prefixes, "KEY "+name+" (", "UNIQUE KEY "+name+" (")
+		}
+	}
+	if len(prefixes) == 0 {
+		return createTable
+	}
+
+	lines := strings.Split(createTable, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		removed := false
+		for _, prefix := range prefixes {
+			removed = removed || strings.HasPrefix(strings.TrimSpace(line), prefix)
+		}
+		if !removed {
+			kept = append(kept, line)
+			continue
+		}
+		// the last definition of the table has no trailing comma, so the definition before it becomes the last
+		if !strings.HasSuffix(line, ",") && len(kept) > 0 {
+			kept[len(kept)-1] = strings.TrimSuffix(kept[len(kept)-1], ",")
+		}
+	}
+	return strings.Join(kept, "\n")
 }
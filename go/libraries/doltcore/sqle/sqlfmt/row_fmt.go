@@ -514,6 +514,11 @@ func ValueAsSqlString(ti typeinfo.TypeInfo, value types.Value) (string, error) {
 	}
 }
 
+// SqlValueAsString returns |value|, a value of a column of type |ti|, as a SQL literal.
+func SqlValueAsString(ti typeinfo.TypeInfo, value interface{}) (string, error) {
+	return interfaceValueAsSqlString(ti, value)
+}
+
 func interfaceValueAsSqlString(ti typeinfo.TypeInfo, value interface{}) (string, error) {
 	if value == nil {
 		return "NULL", nil
@@ -81,6 +81,7 @@ func GenerateSqlPatchSchemaStatements(ctx *sql.Context, toRoot doltdb.RootValue,
 			return nil, errhand.VerboseErrorFromError(err)
 		}
 		ddlStatements = append(ddlStatements, stmt)
+		ddlStatements = append(ddlStatements, CreateIndexStmts(td.ToName.Name, td.ToSch)...)
 	} else {
 		stmts, err := generateNonCreateNonDropTableSqlSchemaDiff(td, toSchemas, fromSch, toSch)
 		if err != nil {
@@ -154,12 +155,12 @@ func generateNonCreateNonDropTableSqlSchemaDiff(td diff.TableDelta, toSchemas ma
 		switch idxDiff.DiffType {
 		case diff.SchDiffNone:
 		case diff.SchDiffAdded:
-			ddlStatements = append(ddlStatements, AlterTableAddIndexStmt(td.ToName.Name, idxDiff.To))
+			ddlStatements = append(ddlStatements, addIndexStmt(td.ToName.Name, idxDiff.To))
 		case diff.SchDiffRemoved:
 			ddlStatements = append(ddlStatements, AlterTableDropIndexStmt(td.FromName.Name, idxDiff.From))
 		case diff.SchDiffModified:
 			ddlStatements = append(ddlStatements, AlterTableDropIndexStmt(td.FromName.Name, idxDiff.From))
-			ddlStatements = append(ddlStatements, AlterTableAddIndexStmt(td.ToName.Name, idxDiff.To))
+			ddlStatements = append(ddlStatements, addIndexStmt(td.ToName.Name, idxDiff.To))
 		}
 	}
 
@@ -227,7 +228,19 @@ func GenerateCreateTableIndentedColumnDefinition(col schema.Column, tableCollati
 // GenerateCreateTableIndexDefinition returns index definition for CREATE TABLE statement with indentation of 2 spaces
 func GenerateCreateTableIndexDefinition(index schema.Index) string {
 	return sql.GenerateCreateTableIndexDefinition(index.IsUnique(), index.IsSpatial(), index.IsFullText(), index.Name(),
-		sql.QuoteIdentifiers(index.ColumnNames()), index.Comment())
+		indexKeyParts(index), index.Comment())
+}
+
+// indexKeyParts returns the key parts of |index|, which are quoted column names, or parenthesized expressions.
+func indexKeyParts(index schema.Index) []string {
+	names := index.ColumnNames()
+	parts := sql.QuoteIdentifiers(names)
+	for i, tag := range index.IndexedColumnTags() {
+		if schema.IsExpressionTag(tag) {
+			parts[i] = "(" + names[i] + ")"
+		}
+	}
+	return parts
 }
 
 // GenerateCreateTableForeignKeyDefinition returns foreign key definition for CREATE TABLE statement with indentation of 2 spaces
@@ -371,14 +384,58 @@ func AlterTableAddIndexStmt(tableName string, idx schema.Index) string {
 	b.WriteString(QuoteIdentifier(tableName))
 	b.WriteString(" ADD INDEX ")
 	b.WriteString(QuoteIdentifier(idx.Name()))
-	var cols []string
-	for _, cn := range idx.ColumnNames() {
-		cols = append(cols, QuoteIdentifier(cn))
+	b.WriteString("(" + strings.Join(indexKeyParts(idx), ",") + ");")
+	return b.String()
+}
+
+// addIndexStmt returns the statement which adds |idx| to the table |tableName|.
+func addIndexStmt(tableName string, idx schema.Index) string {
+	if IsCreateIndexOnly(idx) {
+		return CreateIndexStmt(tableName, idx)
 	}
-	b.WriteString("(" + strings.Join(cols, ",") + ");")
+	return AlterTableAddIndexStmt(tableName, idx)
+}
+
+// IsCreateIndexOnly returns whether |idx| is a partial index or an index of expressions. The parser only reads the
+// predicates and expression key parts of indexes in CREATE INDEX statements, so these indexes are left out of the
+// statements of GenerateCreateTableStatement, and are created by the statements of CreateIndexStmt instead.
+func IsCreateIndexOnly(idx schema.Index) bool {
+	return idx.Predicate() != "" || len(idx.Expressions()) > 0
+}
+
+// CreateIndexStmt returns the CREATE INDEX statement of |idx|, an index of the table |tableName|, including the WHERE
+// clause of its predicate if it is a partial index.
+func CreateIndexStmt(tableName string, idx schema.Index) string {
+	var b strings.Builder
+	b.WriteString("CREATE ")
+	if idx.IsUnique() {
+		b.WriteString("UNIQUE ")
+	}
+	b.WriteString("INDEX ")
+	b.WriteString(QuoteIdentifier(idx.Name()))
+	b.WriteString(" ON ")
+	b.WriteString(QuoteIdentifier(tableName))
+	b.WriteString(" (" + strings.Join(indexKeyParts(idx), ",") + ")")
+	if idx.Predicate() != "" {
+		b.WriteString(" WHERE ")
+		b.WriteString(idx.Predicate())
+	}
+	b.WriteRune(';')
 	return b.String()
 }
 
+// CreateIndexStmts returns the CREATE INDEX statements of the indexes of |sch|, the schema of the table |tableName|,
+// which the statement of GenerateCreateTableStatement leaves out, see IsCreateIndexOnly.
+func CreateIndexStmts(tableName string, sch schema.Schema) []string {
+	var stmts []string
+	for _, idx := range sch.Indexes().AllIndexes() {
+		if IsCreateIndexOnly(idx) {
+			stmts = append(stmts, CreateIndexStmt(tableName, idx))
+		}
+	}
+	return stmts
+}
+
 func AlterTableDropIndexStmt(tableName string, idx schema.Index) string {
 	var b strings.Builder
 	b.WriteString("ALTER TABLE ")
@@ -443,7 +500,8 @@ func AlterTableDropForeignKeyStmt(tableName, fkName string) string {
 
 // GenerateCreateTableStatement returns a CREATE TABLE statement for given table. This is a reasonable approximation of
 // `SHOW CREATE TABLE` in the engine, but may have some differences. Callers are advised to use the engine when
-// possible.
+// possible. Partial indexes and indexes of expressions are left out of the statement, and are created by the
+// statements of CreateIndexStmts.
 // TODO: schema names
 func GenerateCreateTableStatement(
 	tblName string,
@@ -470,6 +528,9 @@ func GenerateCreateTableStatement(
 		if isPrimaryKeyIndex(index, sch) {
 			continue
 		}
+		if IsCreateIndexOnly(index) {
+			continue
+		}
 		colStmts = append(colStmts, GenerateCreateTableIndexDefinition(index))
 	}
 
@@ -0,0 +1,235 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlscan scans the tokens of the statements which Dolt parses itself,
+// or prepares before they are parsed, because the parser doesn't support
+// them. It only scans the words, identifiers, strings and comments those
+// statements are made of, and quotes them as MySQL does: identifiers are
+// quoted with backticks, strings with single or double quotes, a quote is
+// escaped by doubling it, and a backslash escapes the character after it in
+// a string.
+package sqlscan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scanner scans the tokens of a query.
+type Scanner struct {
+	// Query is the query being scanned.
+	Query string
+	// Pos is the position in Query of the next character to scan.
+	Pos int
+}
+
+// New returns a Scanner at the start of |query|.
+func New(query string) *Scanner {
+	return &Scanner{Query: query}
+}
+
+// Skip skips whitespace and comments.
+func (s *Scanner) Skip() {
+	for s.Pos < len(s.Query) {
+		switch {
+		case IsSpace(s.Query[s.Pos]):
+			s.Pos++
+		case s.Query[s.Pos] == '#' || strings.HasPrefix(s.Query[s.Pos:], "-- "):
+			if end := strings.IndexByte(s.Query[s.Pos:], '\n'); end >= 0 {
+				s.Pos += end + 1
+			} else {
+				s.Pos = len(s.Query)
+			}
+		case strings.HasPrefix(s.Query[s.Pos:], "/*"):
+			if end := strings.Index(s.Query[s.Pos+2:], "*/"); end >= 0 {
+				s.Pos += end + 4
+			} else {
+				s.Pos = len(s.Query)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Peek returns the next character after whitespace and comments, or 0 at the end of the query.
+func (s *Scanner) Peek() byte {
+	s.Skip()
+	if s.Pos == len(s.Query) {
+		return 0
+	}
+	return s.Query[s.Pos]
+}
+
+// Word scans the next unquoted word, which is empty if the next token is not a word.
+func (s *Scanner) Word() string {
+	s.Skip()
+	start := s.Pos
+	for s.Pos < len(s.Query) && IsWordChar(s.Query[s.Pos]) {
+		s.Pos++
+	}
+	return s.Query[start:s.Pos]
+}
+
+// Keywords scans |words| if they are next, and returns whether they were. Nothing is scanned if they were not.
+func (s *Scanner) Keywords(words ...string) bool {
+	start := s.Pos
+	for _, w := range words {
+		if !strings.EqualFold(s.Word(), w) {
+			s.Pos = start
+			return false
+		}
+	}
+	return true
+}
+
+// Ident scans an identifier, which may be quoted with backticks.
+func (s *Scanner) Ident() (string, error) {
+	if s.Peek() == '`' {
+		start := s.Pos
+		s.Pos = SkipQuoted(s.Query, s.Pos)
+		if id, ok := Unquote(s.Query[start:s.Pos]); ok && id != "" {
+			return id, nil
+		}
+	} else if w := s.Word(); w != "" {
+		return w, nil
+	}
+	return "", s.Errorf("expected an identifier")
+}
+
+// Str scans a string literal, quoted with single or double quotes, and returns its value.
+func (s *Scanner) Str() (string, error) {
+	if q := s.Peek(); q != '\'' && q != '"' {
+		return "", s.Errorf("expected a quoted string")
+	}
+	start := s.Pos
+	s.Pos = SkipQuoted(s.Query, s.Pos)
+	str, ok := Unquote(s.Query[start:s.Pos])
+	if !ok {
+		return "", s.Errorf("unterminated string")
+	}
+	return str, nil
+}
+
+// End scans the end of the statement, which is either a semicolon or the end of the query, and returns its position.
+func (s *Scanner) End() (int, error) {
+	switch s.Peek() {
+	case ';':
+		s.Pos++
+	case 0:
+	default:
+		return 0, s.Errorf("unexpected input")
+	}
+	return s.Pos, nil
+}
+
+// Errorf returns a syntax error at the next token.
+func (s *Scanner) Errorf(format string, args ...interface{}) error {
+	s.Skip()
+	end := s.Pos
+	for end < len(s.Query) && !IsSpace(s.Query[end]) {
+		end++
+	}
+	return fmt.Errorf("syntax error at position %d near '%s': %s", s.Pos, s.Query[s.Pos:end], fmt.Sprintf(format, args...))
+}
+
+// IsQuote returns whether |c| starts a quoted identifier or string.
+func IsQuote(c byte) bool {
+	return c == '`' || c == '\'' || c == '"'
+}
+
+// SkipQuoted returns the end of the quoted identifier or string starting at |i| in |query|, past its closing quote, or
+// the end of |query| if it is not terminated.
+func SkipQuoted(query string, i int) int {
+	end, _ := quotedEnd(query, i)
+	return end
+}
+
+// quotedEnd returns the end of the quoted identifier or string starting at |i| in |query|, and whether it is
+// terminated.
+func quotedEnd(query string, i int) (int, bool) {
+	q := query[i]
+	for i++; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && q != '`':
+			i++
+		case query[i] == q:
+			if i+1 < len(query) && query[i+1] == q {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+	}
+	return len(query), false
+}
+
+// Unquote returns the value of |quoted|, a quoted identifier or string, and false if it is not a single terminated
+// identifier or string. As in MySQL, \% and \_ keep their backslash in a string, so that they escape the wildcards of
+// a LIKE pattern.
+func Unquote(quoted string) (string, bool) {
+	if len(quoted) < 2 || !IsQuote(quoted[0]) {
+		return "", false
+	}
+	if end, ok := quotedEnd(quoted, 0); !ok || end != len(quoted) {
+		return "", false
+	}
+	q := quoted[0]
+	body := quoted[1 : len(quoted)-1]
+	sb := strings.Builder{}
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == q:
+			// a doubled quote
+			i++
+		case c == '\\' && q != '`':
+			i++
+			c = body[i]
+			switch c {
+			case '0':
+				c = 0
+			case 'b':
+				c = '\b'
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'Z':
+				c = 26
+			case '%', '_':
+				sb.WriteByte('\\')
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), true
+}
+
+// QuoteIdent returns |id| quoted as an identifier.
+func QuoteIdent(id string) string {
+	return "`" + strings.ReplaceAll(id, "`", "``") + "`"
+}
+
+// IsWordChar returns whether |c| can be part of an unquoted word.
+func IsWordChar(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// IsSpace returns whether |c| is whitespace.
+func IsSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
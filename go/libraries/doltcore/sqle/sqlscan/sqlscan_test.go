@@ -0,0 +1,129 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlscan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		quoted   string
+		expected string
+		ok       bool
+	}{
+		{quoted: "`a`", expected: "a", ok: true},
+		{quoted: "`a``b`", expected: "a`b", ok: true},
+		{quoted: "`a\\b`", expected: "a\\b", ok: true},
+		{quoted: "``", expected: "", ok: true},
+		{quoted: "'a'", expected: "a", ok: true},
+		{quoted: `"a"`, expected: "a", ok: true},
+		{quoted: "'it''s'", expected: "it's", ok: true},
+		{quoted: `"say ""hi"""`, expected: `say "hi"`, ok: true},
+		{quoted: `'it\'s'`, expected: "it's", ok: true},
+		{quoted: `"a\"b"`, expected: `a"b`, ok: true},
+		{quoted: `'a"b'`, expected: `a"b`, ok: true},
+		{quoted: `'a\\b'`, expected: `a\b`, ok: true},
+		{quoted: `'a\nb\tc'`, expected: "a\nb\tc", ok: true},
+		{quoted: `'\0\Z'`, expected: "\x00\x1a", ok: true},
+		{quoted: `'t\_%\%'`, expected: `t\_%\%`, ok: true},
+		{quoted: `'\q'`, expected: "q", ok: true},
+		{quoted: `'a\\'`, expected: `a\`, ok: true},
+		{quoted: `'a\'`},
+		{quoted: `'a`},
+		{quoted: `'a'b'`},
+		{quoted: `'a' 'b'`},
+		{quoted: `'`},
+		{quoted: `a`},
+		{quoted: "`a'"},
+	}
+	for _, test := range tests {
+		t.Run(test.quoted, func(t *testing.T) {
+			actual, ok := Unquote(test.quoted)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestSkipQuoted(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{query: "'a' b", expected: 3},
+		{query: "'a''b' c", expected: 6},
+		{query: `'a\'b' c`, expected: 6},
+		{query: "`a\\` b", expected: 4},
+		{query: "`a``b` c", expected: 6},
+		{query: `"a' b`, expected: 5},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			assert.Equal(t, test.expected, SkipQuoted(test.query, 0))
+		})
+	}
+}
+
+func TestScanner(t *testing.T) {
+	s := New("  Create /* a comment */ POLICY -- a comment\n `my ``policy``` # a comment\n ON t USING ('it''s') ;rest")
+	assert.False(t, s.Keywords("create", "table"))
+	assert.True(t, s.Keywords("create", "policy"))
+	id, err := s.Ident()
+	require.NoError(t, err)
+	assert.Equal(t, "my `policy`", id)
+	assert.True(t, s.Keywords("on"))
+	id, err = s.Ident()
+	require.NoError(t, err)
+	assert.Equal(t, "t", id)
+	assert.Equal(t, "USING", s.Word())
+	assert.Equal(t, byte('('), s.Peek())
+	s.Pos++
+	str, err := s.Str()
+	require.NoError(t, err)
+	assert.Equal(t, "it's", str)
+	_, err = s.End()
+	assert.EqualError(t, err, "syntax error at position 94 near ')': unexpected input")
+	s.Pos++
+	end, err := s.End()
+	require.NoError(t, err)
+	assert.Equal(t, "rest", s.Query[end:])
+
+	s = New("``")
+	_, err = s.Ident()
+	assert.Error(t, err)
+
+	s = New("'unterminated")
+	_, err = s.Str()
+	assert.EqualError(t, err, "syntax error at position 13 near '': unterminated string")
+
+	s = New("ident")
+	_, err = s.Str()
+	assert.EqualError(t, err, "syntax error at position 0 near 'ident': expected a quoted string")
+	end, err = New(" -- only a comment").End()
+	require.NoError(t, err)
+	assert.Equal(t, 18, end)
+}
+
+func TestQuoteIdent(t *testing.T) {
+	for _, id := range []string{"a", "a`b", "``", "a b"} {
+		unquoted, ok := Unquote(QuoteIdent(id))
+		assert.True(t, ok)
+		assert.Equal(t, id, unquoted)
+	}
+}
@@ -219,14 +219,28 @@ func (n *NomsStatsDatabase) initMutable(ctx context.Context, i int) error {
 	return nil
 }
 
-func (n *NomsStatsDatabase) DeleteStats(branch string, quals ...sql.StatQualifier) {
+func (n *NomsStatsDatabase) DeleteStats(ctx context.Context, branch string, quals ...sql.StatQualifier) error {
+	if len(quals) == 0 {
+		return nil
+	}
 	for i, b := range n.branches {
 		if strings.EqualFold(b, branch) {
 			for _, qual := range quals {
 				delete(n.stats[i], qual)
 			}
+			if n.dirty[i] == nil {
+				if err := n.initMutable(ctx, i); err != nil {
+					return err
+				}
+			}
+			for _, qual := range quals {
+				if err := deleteIndexRows(ctx, n.dirty[i], qual); err != nil {
+					return err
+				}
+			}
 		}
 	}
+	return nil
 }
 
 func (n *NomsStatsDatabase) DeleteBranchStats(ctx context.Context, branch string, flush bool) error {
@@ -194,6 +194,11 @@ func parseTypeStrings(typs []string) ([]sql.Type, error) {
 }
 
 func loadLowerBound(ctx *sql.Context, qual sql.StatQualifier) (sql.Row, error) {
+	if statspro.IsHistogramIndex(qual.Index()) {
+		// the rows of a table are not ordered by the columns of its histograms
+		return nil, nil
+	}
+
 	dSess := dsess.DSessFromSess(ctx.Session)
 	roots, ok := dSess.GetRoots(ctx, qual.Db())
 	if !ok {
@@ -253,6 +258,14 @@ func loadFuncDeps(ctx *sql.Context, db dsess.SqlDatabase, qual sql.StatQualifier
 		return nil, sql.ColSet{}, fmt.Errorf("%w: table not found: '%s'", statspro.ErrFailedToLoad, qual.Table())
 	}
 
+	if statspro.IsHistogramIndex(qual.Index()) {
+		fds, colSet, err := statspro.HistogramFds(tab.Schema(), statspro.HistogramIndexColumns(qual.Index()))
+		if sql.ErrColumnNotFound.Is(err) {
+			return nil, sql.ColSet{}, fmt.Errorf("%w: %s", statspro.ErrFailedToLoad, err.Error())
+		}
+		return fds, colSet, err
+	}
+
 	iat, ok := tab.(sql.IndexAddressable)
 	if !ok {
 		return nil, sql.ColSet{}, fmt.Errorf("%w: table does not have indexes: '%s'", statspro.ErrFailedToLoad, qual.Table())
@@ -35,19 +35,18 @@ import (
 const maxBucketFanout = 200 * 200
 
 func (n *NomsStatsDatabase) replaceStats(ctx context.Context, statsMap *prolly.MutableMap, dStats *statspro.DoltStats) error {
-	if err := deleteIndexRows(ctx, statsMap, dStats); err != nil {
+	if err := deleteIndexRows(ctx, statsMap, dStats.Qualifier()); err != nil {
 		return err
 	}
 	return putIndexRows(ctx, statsMap, dStats)
 }
 
-func deleteIndexRows(ctx context.Context, statsMap *prolly.MutableMap, dStats *statspro.DoltStats) error {
+func deleteIndexRows(ctx context.Context, statsMap *prolly.MutableMap, qual sql.StatQualifier) error {
 	sch := schema.StatsTableDoltSchema
 	kd, _ := sch.GetMapDescriptors()
 
 	keyBuilder := val.NewTupleBuilder(kd)
 
-	qual := dStats.Qualifier()
 	pool := statsMap.NodeStore().Pool()
 
 	// delete previous entries for this index -> (db, table, index, pos)
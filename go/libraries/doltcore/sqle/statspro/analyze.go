@@ -113,23 +113,9 @@ func (p *Provider) RefreshTableStatsWithBranch(ctx *sql.Context, table sql.Table
 		return err
 	}
 
-	statDb, ok := p.getStatDb(dbName)
-	if !ok {
-		// if the stats database does not exist, initialize one
-		fs, err := p.pro.FileSystemForDatabase(dbName)
-		if err != nil {
-			return err
-		}
-		sourceDb, ok := p.pro.BaseDatabase(ctx, dbName)
-		if !ok {
-			return sql.ErrDatabaseNotFound.New(dbName)
-		}
-		statDb, err = p.sf.Init(ctx, sourceDb, p.pro, fs, env.GetCurrentUserHomeDir)
-		if err != nil {
-			ctx.Warn(0, err.Error())
-			return nil
-		}
-		p.setStatDb(dbName, statDb)
+	statDb, err := p.getOrInitStatDb(ctx, dbName)
+	if err != nil || statDb == nil {
+		return err
 	}
 
 	tablePrefix := fmt.Sprintf("%s.", tableName)
@@ -146,7 +132,8 @@ func (p *Provider) RefreshTableStatsWithBranch(ctx *sql.Context, table sql.Table
 			curStat = NewDoltStats()
 			curStat.Statistic.Qual = qual
 		}
-		idxMeta, err := newIdxMeta(ctx, curStat, dTab, idx, cols)
+		// chunks shared with other branches do not need to be read again
+		idxMeta, err := newSharedIdxMeta(ctx, curStat, dTab, idx, cols, p.sharedBuckets(ctx, statDb, branch, qual))
 		if err != nil {
 			return err
 		}
@@ -157,6 +144,7 @@ func (p *Provider) RefreshTableStatsWithBranch(ctx *sql.Context, table sql.Table
 	if err != nil {
 		return err
 	}
+	p.cacheBuckets(newTableStats)
 
 	// merge new chunks with preexisting chunks
 	for _, idxMeta := range idxMetas {
@@ -172,15 +160,49 @@ func (p *Provider) RefreshTableStatsWithBranch(ctx *sql.Context, table sql.Table
 		stat.Chunks = idxMeta.allAddrs
 		stat.Hist = targetChunks
 		stat.UpdateActive()
+		if len(idxMeta.keepChunks) > 0 {
+			// the counts of the new buckets are only those of the rows which were read
+			stat.updateCounts()
+		}
 		if err := statDb.SetStat(ctx, branch, idxMeta.qual, stat); err != nil {
 			return err
 		}
 	}
 
+	if err := refreshHistograms(ctx, statDb, branch, tableName, sqlTable, dTab); err != nil {
+		return err
+	}
+
 	p.UpdateStatus(dbName, fmt.Sprintf("refreshed %s", dbName))
 	return statDb.Flush(ctx, branch)
 }
 
+// getOrInitStatDb returns the statistics database of |dbName|, and initializes it if it does not exist. It returns
+// nil, after warning the client, if the statistics database cannot be initialized.
+func (p *Provider) getOrInitStatDb(ctx *sql.Context, dbName string) (Database, error) {
+	dbName = strings.ToLower(dbName)
+	if statDb, ok := p.getStatDb(dbName); ok {
+		return statDb, nil
+	}
+
+	// if the stats database does not exist, initialize one
+	fs, err := p.pro.FileSystemForDatabase(dbName)
+	if err != nil {
+		return nil, err
+	}
+	sourceDb, ok := p.pro.BaseDatabase(ctx, dbName)
+	if !ok {
+		return nil, sql.ErrDatabaseNotFound.New(dbName)
+	}
+	statDb, err := p.sf.Init(ctx, sourceDb, p.pro, fs, env.GetCurrentUserHomeDir)
+	if err != nil {
+		ctx.Warn(0, err.Error())
+		return nil, nil
+	}
+	p.setStatDb(dbName, statDb)
+	return statDb, nil
+}
+
 // branchQualifiedDatabase returns a branch qualified database. If the database
 // is already branch suffixed no duplication is applied.
 func (p *Provider) branchQualifiedDatabase(db, branch string) string {
@@ -210,24 +232,34 @@ func GetLatestTable(ctx *sql.Context, tableName string, sqlDb sql.Database) (sql
 		return nil, nil, fmt.Errorf("statistics refresh error: table not found %s", tableName)
 	}
 
-	var dTab *doltdb.Table
+	dTab, err := unwrapDoltTable(ctx, sqlTable)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sqlTable, dTab, nil
+}
+
+// unwrapDoltTable returns the doltdb.Table of |sqlTable|, at the root the table reads from.
+func unwrapDoltTable(ctx *sql.Context, sqlTable sql.Table) (*doltdb.Table, error) {
 	switch t := sqlTable.(type) {
 	case *sqle.AlterableDoltTable:
-		dTab, err = t.DoltTable.DoltTable(ctx)
+		return t.DoltTable.DoltTable(ctx)
 	case *sqle.WritableDoltTable:
-		dTab, err = t.DoltTable.DoltTable(ctx)
+		return t.DoltTable.DoltTable(ctx)
 	case *sqle.DoltTable:
-		dTab, err = t.DoltTable(ctx)
+		return t.DoltTable(ctx)
 	default:
-		err = fmt.Errorf("failed to unwrap dolt table from type: %T", sqlTable)
+		return nil, fmt.Errorf("failed to unwrap dolt table from type: %T", sqlTable)
 	}
-	if err != nil {
-		return nil, nil, err
-	}
-	return sqlTable, dTab, nil
 }
 
 func newIdxMeta(ctx *sql.Context, curStats *DoltStats, doltTable *doltdb.Table, sqlIndex sql.Index, cols []string) (indexMeta, error) {
+	return newSharedIdxMeta(ctx, curStats, doltTable, sqlIndex, cols, nil)
+}
+
+// newSharedIdxMeta is newIdxMeta, but also keeps the buckets of chunks which are not in |curStats| if |shared| finds
+// them, so that only the chunks which have never been built need to be read.
+func newSharedIdxMeta(ctx *sql.Context, curStats *DoltStats, doltTable *doltdb.Table, sqlIndex sql.Index, cols []string, shared *bucketFinder) (indexMeta, error) {
 	var idx durable.Index
 	var err error
 	if strings.EqualFold(sqlIndex.ID(), "PRIMARY") {
@@ -274,12 +306,14 @@ func newIdxMeta(ctx *sql.Context, curStats *DoltStats, doltTable *doltdb.Table,
 		}
 
 		addrs = append(addrs, n.HashOf())
-		if bucketIdx, ok := curStats.Active[n.HashOf()]; !ok {
+		if bucketIdx, ok := curStats.Active[n.HashOf()]; ok {
+			keepChunks = append(keepChunks, curStats.Hist[bucketIdx])
+		} else if b, ok := shared.find(n.HashOf(), len(cols)); ok {
+			keepChunks = append(keepChunks, b)
+		} else {
 			missingChunks = append(missingChunks, n)
 			missingOffsets = append(missingOffsets, updateOrdinal{offset, offset + uint64(treeCnt)})
 			missingAddrs++
-		} else {
-			keepChunks = append(keepChunks, curStats.Hist[bucketIdx])
 		}
 		offset += uint64(treeCnt)
 	}
@@ -223,6 +223,30 @@ func (p *Provider) checkRefresh(ctx *sql.Context, sqlDb sql.Database, dbName, br
 				p.UpdateStatus(dbName, fmt.Sprintf("refreshed %s", dbName))
 			}
 		}
+
+		// The histograms of columns are rebuilt along with the statistics
+		// of the indexes of their table, whose changed chunks are the
+		// estimate of the changed rows of the table. The histograms of
+		// tables without indexes are rebuilt whenever the table changes.
+		var histQuals []sql.StatQualifier
+		for _, q := range statDb.ListStatQuals(branch) {
+			if strings.EqualFold(q.Table(), table) && IsHistogramIndex(q.Index()) {
+				if stat, ok := statDb.GetStat(branch, q); ok {
+					if _, err := histogramColumns(sqlTable.Schema(), stat.Columns()); err == nil {
+						qualExists[q] = true
+						histQuals = append(histQuals, q)
+					}
+				}
+			}
+		}
+		if len(histQuals) > 0 && (len(idxMetas) > 0 || len(indexes) == 0) {
+			ctx.GetLogger().Debugf("statistics updating histograms: %s", table)
+			if err := refreshHistograms(ctx, statDb, branch, table, sqlTable, dTab); err != nil {
+				return err
+			}
+			statDb.SetLatestHash(branch, table, tableHash)
+			p.UpdateStatus(dbName, fmt.Sprintf("refreshed %s", dbName))
+		}
 	}
 
 	for _, q := range statDb.ListStatQuals(branch) {
@@ -234,7 +258,9 @@ func (p *Provider) checkRefresh(ctx *sql.Context, sqlDb sql.Database, dbName, br
 		}
 	}
 
-	statDb.DeleteStats(branch, deletedStats...)
+	if err := statDb.DeleteStats(ctx, branch, deletedStats...); err != nil {
+		return err
+	}
 
 	if err := statDb.Flush(ctx, branch); err != nil {
 		return err
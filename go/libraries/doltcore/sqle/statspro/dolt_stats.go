@@ -203,6 +203,21 @@ func (s *DoltStats) UpdateActive() {
 	s.Active = newActive
 }
 
+// updateCounts sets the row, distinct and null counts of |s| to the sums of
+// those of its buckets. The distinct count is an estimate, because a value
+// can span the boundary of two buckets.
+func (s *DoltStats) updateCounts() {
+	var rows, distinct, nulls uint64
+	for _, b := range s.Hist {
+		rows += b.RowCount()
+		distinct += b.DistinctCount()
+		nulls += b.NullCount()
+	}
+	s.Statistic.RowCnt = rows
+	s.Statistic.DistinctCnt = distinct
+	s.Statistic.NullCnt = nulls
+}
+
 type DoltHistogram []DoltBucket
 
 type DoltBucket struct {
@@ -0,0 +1,462 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statspro
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/stats"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// Histograms are the statistics of columns which are not necessarily
+// indexed, built by ANALYZE TABLE ... UPDATE HISTOGRAM. They are stored with
+// the statistics of the indexes of a table, under a qualifier whose index is
+// named by HistogramIndex. Unlike the histograms of indexes, whose buckets
+// are the chunks of the index, the rows of a table are not ordered by the
+// columns of a histogram, so a histogram is rebuilt from a full scan of the
+// table, with buckets of roughly equal numbers of rows.
+//
+// The planner looks up statistics by the indexes it can use. When an index
+// has no statistics of its own, GetStats returns the histogram of its
+// columns instead, so the estimates of the filters and lookup joins which
+// use the index come from the histogram.
+
+const (
+	// defaultHistogramBuckets is the number of buckets of a histogram
+	// which is built without WITH n BUCKETS.
+	defaultHistogramBuckets = 100
+	histogramIndexPrefix    = "histogram("
+)
+
+// HistogramIndex returns the name of the index of the qualifier of the histogram of the columns |cols|.
+func HistogramIndex(cols []string) string {
+	return histogramIndexPrefix + strings.ToLower(strings.Join(cols, ",")) + ")"
+}
+
+// IsHistogramIndex returns whether |index| is the index of the qualifier of the histogram of columns, rather than
+// the name of an index.
+func IsHistogramIndex(index string) bool {
+	return strings.HasPrefix(index, histogramIndexPrefix) && strings.HasSuffix(index, ")")
+}
+
+// HistogramIndexColumns returns the columns of the histogram whose qualifier has the index |index|.
+func HistogramIndexColumns(index string) []string {
+	return strings.Split(strings.TrimSuffix(strings.TrimPrefix(index, histogramIndexPrefix), ")"), ",")
+}
+
+// histogramStatColumns returns the columns of the histogram of the columns |cols| of |table|, which the planner
+// names by their expressions, such as "t.a".
+func histogramStatColumns(table string, cols []string) []string {
+	tablePrefix := strings.ToLower(table) + "."
+	ret := make([]string, len(cols))
+	for i, c := range cols {
+		ret[i] = strings.TrimPrefix(strings.ToLower(c), tablePrefix)
+	}
+	return ret
+}
+
+// UpdateHistogram builds the histogram of the columns |cols| of |table|, on the current branch of the session, and
+// replaces the previous histogram of the columns. It builds at most |buckets| buckets, or the default number if
+// |buckets| is 0. It returns sql.ErrColumnNotFound if |table| has no such columns.
+func (p *Provider) UpdateHistogram(ctx *sql.Context, db string, table sql.Table, cols []string, buckets int) error {
+	dSess := dsess.DSessFromSess(ctx.Session)
+	branch, err := dSess.GetBranch()
+	if err != nil {
+		return err
+	}
+	sqlDb, err := dSess.Provider().Database(ctx, p.branchQualifiedDatabase(db, branch))
+	if err != nil {
+		return err
+	}
+
+	// lock only after accessing DatabaseProvider
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sqlTable, dTab, err := GetLatestTable(ctx, table.Name(), sqlDb)
+	if err != nil {
+		return err
+	}
+	cols, err = histogramColumns(sqlTable.Schema(), cols)
+	if err != nil {
+		return err
+	}
+	statDb, err := p.getOrInitStatDb(ctx, db)
+	if err != nil || statDb == nil {
+		return err
+	}
+
+	if buckets == 0 {
+		buckets = defaultHistogramBuckets
+	}
+	qual := sql.NewStatQualifier(db, table.Name(), HistogramIndex(cols))
+	stat, err := createHistogram(ctx, sqlTable, dTab, qual, cols, buckets)
+	if err != nil {
+		return err
+	}
+	if err := statDb.SetStat(ctx, branch, qual, stat); err != nil {
+		return err
+	}
+	p.UpdateStatus(db, fmt.Sprintf("refreshed %s", db))
+	return statDb.Flush(ctx, branch)
+}
+
+// DropHistogram drops the histogram of the columns |cols| of |table|, on the current branch of the session, and
+// returns whether it existed.
+func (p *Provider) DropHistogram(ctx *sql.Context, db, table string, cols []string) (bool, error) {
+	dSess := dsess.DSessFromSess(ctx.Session)
+	branch, err := dSess.GetBranch()
+	if err != nil {
+		return false, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statDb, ok := p.getStatDb(db)
+	if !ok {
+		return false, nil
+	}
+	qual := sql.NewStatQualifier(db, table, HistogramIndex(cols))
+	if _, ok := statDb.GetStat(branch, qual); !ok {
+		return false, nil
+	}
+	if err := statDb.DeleteStats(ctx, branch, qual); err != nil {
+		return false, err
+	}
+	p.UpdateStatus(db, fmt.Sprintf("dropped statisic: %s", qual.String()))
+	return true, statDb.Flush(ctx, branch)
+}
+
+// refreshHistograms rebuilds the histograms of |table| in |statDb|, and drops the histograms of columns which no
+// longer exist.
+func refreshHistograms(ctx *sql.Context, statDb Database, branch, table string, sqlTable sql.Table, dTab *doltdb.Table) error {
+	for _, qual := range statDb.ListStatQuals(branch) {
+		if !strings.EqualFold(qual.Table(), table) || !IsHistogramIndex(qual.Index()) {
+			continue
+		}
+		cur, ok := statDb.GetStat(branch, qual)
+		if !ok {
+			continue
+		}
+		stat, err := createHistogram(ctx, sqlTable, dTab, qual, cur.Columns(), histogramBuckets(cur))
+		if sql.ErrColumnNotFound.Is(err) {
+			if err := statDb.DeleteStats(ctx, branch, qual); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := statDb.SetStat(ctx, branch, qual, stat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// histogramBuckets returns the number of buckets of a rebuild of the histogram |stat|. It is the number of buckets
+// of |stat|, unless the columns of |stat| had fewer distinct values than the buckets it was built with.
+func histogramBuckets(stat *DoltStats) int {
+	n := len(stat.Hist)
+	if n == 0 || uint64(n) >= stat.DistinctCount() {
+		return defaultHistogramBuckets
+	}
+	return n
+}
+
+// histogramColumns returns the columns |cols| of a table with the schema |sch|, named as in |sch|. It returns
+// sql.ErrColumnNotFound if the table has no such columns.
+func histogramColumns(sch sql.Schema, cols []string) ([]string, error) {
+	ret := make([]string, len(cols))
+	for i, c := range cols {
+		ord := columnOrdinal(sch, c)
+		if ord < 0 {
+			return nil, sql.ErrColumnNotFound.New(c)
+		}
+		ret[i] = strings.ToLower(sch[ord].Name)
+	}
+	return ret, nil
+}
+
+func columnOrdinal(sch sql.Schema, col string) int {
+	for i, c := range sch {
+		if strings.EqualFold(c.Name, col) {
+			return i
+		}
+	}
+	return -1
+}
+
+// HistogramFds returns the functional dependencies and the column set of the histogram of the columns |cols| of a
+// table with the schema |sch|. A histogram is the statistic of a non-unique index on its columns.
+func HistogramFds(sch sql.Schema, cols []string) (*sql.FuncDepSet, sql.ColSet, error) {
+	var all, notNull, colSet sql.ColSet
+	for i, c := range sch {
+		all.Add(sql.ColumnId(i + 1))
+		if !c.Nullable {
+			notNull.Add(sql.ColumnId(i + 1))
+		}
+	}
+	for _, c := range cols {
+		ord := columnOrdinal(sch, c)
+		if ord < 0 {
+			return nil, sql.ColSet{}, sql.ErrColumnNotFound.New(c)
+		}
+		colSet.Add(sql.ColumnId(ord + 1))
+	}
+	return sql.NewTablescanFDs(all, nil, nil, notNull), colSet, nil
+}
+
+// createHistogram builds the histogram |qual| of the columns |cols| of |sqlTable|, with at most |buckets| buckets.
+func createHistogram(ctx *sql.Context, sqlTable sql.Table, dTab *doltdb.Table, qual sql.StatQualifier, cols []string, buckets int) (*DoltStats, error) {
+	sch := sqlTable.Schema()
+	ords := make([]int, len(cols))
+	types := make([]sql.Type, len(cols))
+	for i, c := range cols {
+		ords[i] = columnOrdinal(sch, c)
+		if ords[i] < 0 {
+			return nil, sql.ErrColumnNotFound.New(c)
+		}
+		types[i] = sch[ords[i]].Type
+	}
+	fds, colSet, err := HistogramFds(sch, cols)
+	if err != nil {
+		return nil, err
+	}
+	tableHash, err := dTab.GetRowDataHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := histogramRows(ctx, sqlTable, ords)
+	if err != nil {
+		return nil, err
+	}
+	if err := sortHistogramRows(rows, types); err != nil {
+		return nil, err
+	}
+	hist, err := buildHistogram(rows, types, buckets, tableHash, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ret := NewDoltStats()
+	ret.Statistic.Created = time.Now()
+	ret.Statistic.Cols = cols
+	ret.Statistic.Typs = types
+	ret.Statistic.Qual = qual
+	ret.Statistic.Fds = fds
+	ret.Statistic.Colset = colSet
+	ret.Statistic.RowCnt = uint64(len(rows))
+	for _, b := range hist {
+		ret.Statistic.DistinctCnt += b.DistinctCount()
+		ret.Statistic.NullCnt += b.NullCount()
+		ret.Hist = append(ret.Hist, b)
+	}
+	if len(rows) > 0 {
+		ret.Statistic.LowerBnd = rows[0]
+	}
+	return ret, nil
+}
+
+// histogramRows returns the values of the columns |ords| of the rows of |sqlTable|. Like the statistics of indexes,
+// histograms are built from every row of the table, whoever builds them.
+func histogramRows(ctx *sql.Context, sqlTable sql.Table, ords []int) ([]sql.Row, error) {
+	ctx = sqle.WithoutRowPolicies(ctx)
+	parts, err := sqlTable.Partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer parts.Close(ctx)
+
+	var rows []sql.Row
+	for {
+		part, err := parts.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		iter, err := sqlTable.PartitionRows(ctx, part)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			row, err := iter.Next(ctx)
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				iter.Close(ctx)
+				return nil, err
+			}
+			vals := make(sql.Row, len(ords))
+			for i, ord := range ords {
+				vals[i] = row[ord]
+			}
+			rows = append(rows, vals)
+		}
+		if err := iter.Close(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// compareHistogramRows compares the rows |a| and |b| of the values of columns of the types |types|. NULLs are
+// ordered first.
+func compareHistogramRows(a, b sql.Row, types []sql.Type) (int, error) {
+	for i, t := range types {
+		switch {
+		case a[i] == nil && b[i] == nil:
+			continue
+		case a[i] == nil:
+			return -1, nil
+		case b[i] == nil:
+			return 1, nil
+		}
+		cmp, err := t.Compare(a[i], b[i])
+		if err != nil {
+			return 0, err
+		}
+		if cmp != 0 {
+			return cmp, nil
+		}
+	}
+	return 0, nil
+}
+
+func sortHistogramRows(rows []sql.Row, types []sql.Type) error {
+	var err error
+	sort.SliceStable(rows, func(i, j int) bool {
+		cmp, cmpErr := compareHistogramRows(rows[i], rows[j], types)
+		if cmpErr != nil && err == nil {
+			err = cmpErr
+		}
+		return cmp < 0
+	})
+	return err
+}
+
+// buildHistogram returns the histogram of the sorted |rows|, with at most |buckets| buckets of roughly equal numbers
+// of rows. The rows of a value are never split across buckets, so the upper bounds of the buckets are distinct.
+// |tableHash| is the hash of the table data the rows are read from.
+func buildHistogram(rows []sql.Row, types []sql.Type, buckets int, tableHash hash.Hash, created time.Time) ([]DoltBucket, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	bucketSize := (len(rows) + buckets - 1) / buckets
+
+	var hist []DoltBucket
+	b := &histogramBucketBuilder{}
+	for i := 0; i < len(rows); {
+		// each run of equal rows is a distinct value
+		j := i + 1
+		for ; j < len(rows); j++ {
+			cmp, err := compareHistogramRows(rows[i], rows[j], types)
+			if err != nil {
+				return nil, err
+			}
+			if cmp != 0 {
+				break
+			}
+		}
+		b.add(rows[i], j-i)
+		if b.rowCnt >= bucketSize || j == len(rows) {
+			hist = append(hist, b.finalize(tableHash, created))
+			b = &histogramBucketBuilder{}
+		}
+		i = j
+	}
+	return hist, nil
+}
+
+// histogramBucketBuilder collects the statistics of a bucket of a histogram from the distinct values of its rows.
+type histogramBucketBuilder struct {
+	rowCnt   int
+	distinct int
+	nulls    int
+	bound    sql.Row
+	boundCnt int
+	values   []histogramValue
+}
+
+type histogramValue struct {
+	row sql.Row
+	cnt int
+}
+
+// add adds the |cnt| rows of the value |row|, which follows the values already added.
+func (b *histogramBucketBuilder) add(row sql.Row, cnt int) {
+	b.rowCnt += cnt
+	b.distinct++
+	for _, v := range row {
+		if v == nil {
+			b.nulls += cnt
+			break
+		}
+	}
+	b.bound = row
+	b.boundCnt = cnt
+	b.values = append(b.values, histogramValue{row: row, cnt: cnt})
+}
+
+// finalize returns the bucket of the values added to |b|. As with the buckets of indexes, the most common values of
+// the bucket are the values which are at least twice as common as the average value of the bucket.
+func (b *histogramBucketBuilder) finalize(tableHash hash.Hash, created time.Time) DoltBucket {
+	sort.SliceStable(b.values, func(i, j int) bool {
+		return b.values[i].cnt > b.values[j].cnt
+	})
+	cutoff := 2 * float64(b.rowCnt) / float64(b.distinct)
+	var mcvs []histogramValue
+	for _, v := range b.values {
+		if len(mcvs) == mcvCnt || float64(v.cnt) < cutoff {
+			break
+		}
+		mcvs = append(mcvs, v)
+	}
+	// the most common values are ordered by increasing count, as they are in the buckets of indexes
+	mcvRows := make([]sql.Row, len(mcvs))
+	mcvCnts := make([]uint64, len(mcvs))
+	for i, v := range mcvs {
+		mcvRows[len(mcvs)-1-i] = v.row
+		mcvCnts[len(mcvs)-1-i] = uint64(v.cnt)
+	}
+
+	return DoltBucket{
+		Chunk:   tableHash,
+		Created: created,
+		Bucket: &stats.Bucket{
+			RowCnt:      uint64(b.rowCnt),
+			DistinctCnt: uint64(b.distinct),
+			NullCnt:     uint64(b.nulls),
+			BoundCnt:    uint64(b.boundCnt),
+			BoundVal:    b.bound,
+			McvVals:     mcvRows,
+			McvsCnt:     mcvCnts,
+		},
+	}
+}
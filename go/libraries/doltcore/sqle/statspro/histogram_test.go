@@ -0,0 +1,86 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statspro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestHistogramIndex(t *testing.T) {
+	idx := HistogramIndex([]string{"A", "b"})
+	assert.Equal(t, "histogram(a,b)", idx)
+	assert.True(t, IsHistogramIndex(idx))
+	assert.False(t, IsHistogramIndex("primary"))
+	assert.Equal(t, []string{"a", "b"}, HistogramIndexColumns(idx))
+}
+
+func TestBuildHistogram(t *testing.T) {
+	tys := []sql.Type{types.Int64}
+	var rows []sql.Row
+	for _, v := range []interface{}{5, 1, nil, 4, 1, 1, 4, 1, 1, 1, 2, 3, 4, 4, 6, 7} {
+		if v != nil {
+			v = int64(v.(int))
+		}
+		rows = append(rows, sql.Row{v})
+	}
+	require.NoError(t, sortHistogramRows(rows, tys))
+	assert.Equal(t, sql.Row{nil}, rows[0])
+	assert.Equal(t, sql.Row{int64(7)}, rows[len(rows)-1])
+
+	h := hash.Of([]byte("table"))
+	hist, err := buildHistogram(rows, tys, 3, h, time.Now())
+	require.NoError(t, err)
+
+	// buckets of at least 6 rows, which never split the rows of a value
+	require.Len(t, hist, 3)
+	expected := []struct {
+		rows, distinct, nulls, boundCnt uint64
+		bound                           sql.Row
+	}{
+		{rows: 7, distinct: 2, nulls: 1, boundCnt: 6, bound: sql.Row{int64(1)}},
+		{rows: 6, distinct: 3, boundCnt: 4, bound: sql.Row{int64(4)}},
+		{rows: 3, distinct: 3, boundCnt: 1, bound: sql.Row{int64(7)}},
+	}
+	var total uint64
+	for i, e := range expected {
+		b := hist[i]
+		assert.Equal(t, h, b.Chunk)
+		assert.Equal(t, e.rows, b.RowCount())
+		assert.Equal(t, e.distinct, b.DistinctCount())
+		assert.Equal(t, e.nulls, b.NullCount())
+		assert.Equal(t, e.boundCnt, b.BoundCount())
+		assert.Equal(t, e.bound, b.UpperBound())
+		total += b.RowCount()
+	}
+	assert.Equal(t, uint64(len(rows)), total)
+
+	// 4 is at least twice as common as the average value of its bucket, but 1 is not
+	assert.Empty(t, hist[0].Mcvs())
+	assert.Equal(t, []sql.Row{{int64(4)}}, hist[1].Mcvs())
+	assert.Equal(t, []uint64{4}, hist[1].McvCounts())
+	assert.Empty(t, hist[2].Mcvs())
+
+	hist, err = buildHistogram(nil, tys, 3, h, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, hist)
+}
@@ -41,8 +41,8 @@ type Database interface {
 	GetStat(branch string, qual sql.StatQualifier) (*DoltStats, bool)
 	//SetStat bulk replaces the statistic, deleting any previous version
 	SetStat(ctx context.Context, branch string, qual sql.StatQualifier, stats *DoltStats) error
-	//DeleteStats deletes a list of index statistics.
-	DeleteStats(branch string, quals ...sql.StatQualifier)
+	// DeleteStats deletes a list of index statistics.
+	DeleteStats(ctx context.Context, branch string, quals ...sql.StatQualifier) error
 	// ReplaceChunks is an update interface that lets a stats implementation
 	// decide how to edit stats for a stats refresh.
 	ReplaceChunks(ctx context.Context, branch string, qual sql.StatQualifier, targetHashes []hash.Hash, dropChunks, newChunks []sql.HistogramBucket) error
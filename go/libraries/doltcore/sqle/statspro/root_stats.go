@@ -0,0 +1,220 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statspro
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// Statistics are collected for the heads of branches, but a query can read
+// a table at any root: AS OF a commit, or in a revision database. Buckets are
+// keyed by the address of the prolly tree chunk they summarize, so the
+// statistics of any root can be assembled from the buckets of the chunks it
+// shares with the statistics of branches, reading only the chunks which have
+// never been summarized. The buckets built for other roots are kept in a
+// cache shared by all branches, as are the statistics of recently queried
+// roots.
+
+const (
+	// maxCachedBuckets is the number of buckets of chunks which are not in the
+	// statistics of a branch that the provider keeps.
+	maxCachedBuckets = 1 << 14
+	// maxCachedRootStats is the number of tables whose statistics at roots
+	// other than the heads of branches the provider keeps.
+	maxCachedRootStats = 128
+)
+
+// bucketKey identifies a bucket by its chunk, and the length of the index prefix it summarizes.
+type bucketKey struct {
+	chunk     hash.Hash
+	prefixLen int
+}
+
+// rootStatsKey identifies the statistics of a table at a root by the hash of the table.
+type rootStatsKey struct {
+	db, table string
+	tableHash hash.Hash
+}
+
+func newBucketCache() *lru.Cache[bucketKey, DoltBucket] {
+	// lru.New only fails for non-positive sizes
+	c, _ := lru.New[bucketKey, DoltBucket](maxCachedBuckets)
+	return c
+}
+
+func newRootStatsCache() *lru.Cache[rootStatsKey, []sql.Statistic] {
+	c, _ := lru.New[rootStatsKey, []sql.Statistic](maxCachedRootStats)
+	return c
+}
+
+// bucketFinder finds the buckets of chunks which have already been built, in
+// the statistics of branches and in the bucket cache of the provider.
+type bucketFinder struct {
+	stats []*DoltStats
+	cache *lru.Cache[bucketKey, DoltBucket]
+}
+
+// find returns the bucket of the chunk |h| of an index, whose statistics are of its first |prefixLen| columns.
+func (f *bucketFinder) find(h hash.Hash, prefixLen int) (sql.HistogramBucket, bool) {
+	if f == nil {
+		return nil, false
+	}
+	for _, s := range f.stats {
+		if len(s.Columns()) != prefixLen {
+			continue
+		}
+		if i, ok := s.Active[h]; ok && i < len(s.Hist) {
+			return s.Hist[i], true
+		}
+	}
+	if f.cache != nil {
+		if b, ok := f.cache.Get(bucketKey{chunk: h, prefixLen: prefixLen}); ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// sharedBuckets returns a bucketFinder for the buckets of the statistics |qual| of the branches with statistics other
+// than |branch|, and of the bucket cache. The provider must be locked.
+func (p *Provider) sharedBuckets(ctx *sql.Context, statDb Database, branch string, qual sql.StatQualifier) *bucketFinder {
+	f := &bucketFinder{cache: p.buckets}
+	for _, b := range p.getStatsBranches(ctx) {
+		if strings.EqualFold(b, branch) {
+			continue
+		}
+		if stat, ok := statDb.GetStat(b, qual); ok {
+			f.stats = append(f.stats, stat)
+		}
+	}
+	return f
+}
+
+// cacheBuckets adds the buckets of |newStats| to the bucket cache.
+func (p *Provider) cacheBuckets(newStats map[sql.StatQualifier]*DoltStats) {
+	for _, stat := range newStats {
+		for _, b := range stat.Hist {
+			if doltB, ok := b.(DoltBucket); ok {
+				p.buckets.Add(bucketKey{chunk: doltB.Chunk, prefixLen: len(stat.Columns())}, doltB)
+			}
+		}
+	}
+}
+
+// getRootTableStats returns the statistics of the indexes of |table| at the
+// root it reads from, which may not be the head of |branch|. The buckets of
+// the chunks |table| shares with the statistics of branches, or with the
+// roots whose statistics were built before, are reused. The histograms of
+// columns are only kept for the heads of branches.
+func (p *Provider) getRootTableStats(ctx *sql.Context, db, branch string, table sql.Table) ([]sql.Statistic, error) {
+	iat, ok := table.(sql.IndexAddressableTable)
+	if !ok {
+		return nil, nil
+	}
+	dTab, err := unwrapDoltTable(ctx, table)
+	if err != nil {
+		// not a table with statistics
+		return nil, nil
+	}
+	tableHash, err := dTab.HashOf()
+	if err != nil {
+		return nil, err
+	}
+	key := rootStatsKey{db: strings.ToLower(db), table: strings.ToLower(table.Name()), tableHash: tableHash}
+	if ret, ok := p.rootStats.Get(key); ok {
+		return ret, nil
+	}
+
+	indexes, err := iat.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, idxMetas, ok, err := p.rootIdxMetas(ctx, db, branch, table, dTab, indexes)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// read the chunks which have no buckets without blocking the provider
+	newTableStats, err := createNewStatsBuckets(ctx, table, dTab, indexes, idxMetas)
+	if err != nil {
+		return nil, err
+	}
+	p.cacheBuckets(newTableStats)
+
+	for _, idxMeta := range idxMetas {
+		stat := newTableStats[idxMeta.qual]
+		targetChunks, err := MergeNewChunks(idxMeta.allAddrs, idxMeta.keepChunks, stat.Hist)
+		if err != nil {
+			return nil, err
+		}
+		stat.Chunks = idxMeta.allAddrs
+		stat.Hist = targetChunks
+		stat.UpdateActive()
+		if len(idxMeta.keepChunks) > 0 {
+			stat.updateCounts()
+		}
+		ret = append(ret, stat)
+	}
+
+	p.rootStats.Add(key, ret)
+	return ret, nil
+}
+
+// rootIdxMetas returns the statistics of |branch| for the indexes of |dTab| whose chunks are those of the head of
+// |branch|, and the indexMetas of the other indexes. It returns false if |db| has no statistics.
+func (p *Provider) rootIdxMetas(ctx *sql.Context, db, branch string, table sql.Table, dTab *doltdb.Table, indexes []sql.Index) ([]sql.Statistic, []indexMeta, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statDb, ok := p.getStatDb(db)
+	if !ok || statDb == nil {
+		return nil, nil, false, nil
+	}
+
+	tablePrefix := strings.ToLower(table.Name()) + "."
+	var ret []sql.Statistic
+	var idxMetas []indexMeta
+	for _, idx := range indexes {
+		cols := make([]string, len(idx.Expressions()))
+		for i, c := range idx.Expressions() {
+			cols[i] = strings.TrimPrefix(strings.ToLower(c), tablePrefix)
+		}
+
+		qual := sql.NewStatQualifier(db, table.Name(), strings.ToLower(idx.ID()))
+		curStat, ok := statDb.GetStat(branch, qual)
+		if !ok {
+			curStat = NewDoltStats()
+			curStat.Statistic.Qual = qual
+		}
+		idxMeta, err := newSharedIdxMeta(ctx, curStat, dTab, idx, cols, p.sharedBuckets(ctx, statDb, branch, qual))
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if ok && len(idxMeta.newNodes) == 0 && len(idxMeta.dropChunks) == 0 && len(idxMeta.keepChunks) == len(curStat.Hist) {
+			// the index is unchanged since the statistics of the branch were built
+			ret = append(ret, curStat)
+			continue
+		}
+		idxMetas = append(idxMetas, idxMeta)
+	}
+	return ret, idxMetas, true, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statspro
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+func TestBucketFinder(t *testing.T) {
+	bucket := func(chunk string, rows uint64) DoltBucket {
+		return DoltBucket{Chunk: hash.Of([]byte(chunk)), Bucket: &stats.Bucket{RowCnt: rows, DistinctCnt: rows, NullCnt: 1}}
+	}
+	branchStat := NewDoltStats()
+	branchStat.Statistic.Cols = []string{"y", "z"}
+	branchStat.Hist = sql.Histogram{bucket("a", 10), bucket("b", 20)}
+	branchStat.Chunks = []hash.Hash{hash.Of([]byte("a")), hash.Of([]byte("b"))}
+	branchStat.UpdateActive()
+
+	cache := newBucketCache()
+	cache.Add(bucketKey{chunk: hash.Of([]byte("c")), prefixLen: 2}, bucket("c", 30))
+
+	f := &bucketFinder{stats: []*DoltStats{branchStat}, cache: cache}
+	b, ok := f.find(hash.Of([]byte("b")), 2)
+	require.True(t, ok)
+	assert.Equal(t, uint64(20), b.RowCount())
+	b, ok = f.find(hash.Of([]byte("c")), 2)
+	require.True(t, ok)
+	assert.Equal(t, uint64(30), b.RowCount())
+
+	// the buckets of a chunk summarize a prefix of its keys
+	_, ok = f.find(hash.Of([]byte("a")), 1)
+	assert.False(t, ok)
+	_, ok = f.find(hash.Of([]byte("d")), 2)
+	assert.False(t, ok)
+
+	var nilFinder *bucketFinder
+	_, ok = nilFinder.find(hash.Of([]byte("a")), 2)
+	assert.False(t, ok)
+
+	merged, err := MergeNewChunks([]hash.Hash{hash.Of([]byte("b")), hash.Of([]byte("c"))}, sql.Histogram{branchStat.Hist[1]}, sql.Histogram{bucket("c", 30)})
+	require.NoError(t, err)
+	stat := NewDoltStats()
+	stat.Hist = merged
+	stat.updateCounts()
+	assert.Equal(t, uint64(50), stat.RowCount())
+	assert.Equal(t, uint64(50), stat.DistinctCount())
+	assert.Equal(t, uint64(2), stat.NullCount())
+}
@@ -22,6 +22,7 @@ import (
 	"sync"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	lru "github.com/hashicorp/golang-lru/v2"
 
 	"github.com/dolthub/dolt/go/libraries/doltcore/env"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle"
@@ -55,6 +56,8 @@ func NewProvider(pro *sqle.DoltDatabaseProvider, sf StatsFactory) *Provider {
 		statDbs:   make(map[string]Database),
 		cancelers: make(map[string]context.CancelFunc),
 		status:    make(map[string]string),
+		buckets:   newBucketCache(),
+		rootStats: newRootStatsCache(),
 	}
 }
 
@@ -69,6 +72,12 @@ type Provider struct {
 	cancelers map[string]context.CancelFunc
 	starter   sqle.InitDatabaseHook
 	status    map[string]string
+	// buckets caches the buckets of chunks which were read for roots other
+	// than the heads of branches
+	buckets *lru.Cache[bucketKey, DoltBucket]
+	// rootStats caches the statistics of tables at roots other than the
+	// heads of branches
+	rootStats *lru.Cache[rootStatsKey, []sql.Statistic]
 }
 
 // each database has one statistics table that is a collection of the
@@ -135,8 +144,21 @@ func (p *Provider) GetTableStats(ctx *sql.Context, db string, table sql.Table) (
 		return nil, nil
 	}
 
+	db, revision := dsess.SplitRevisionDbName(db)
+	if revision != "" {
+		branch = revision
+	}
+
 	// TODO: schema name
-	return p.GetTableDoltStats(ctx, branch, db, table.Name())
+	if _, ok := table.(*sqle.DoltTable); !ok {
+		ret, err := p.GetTableDoltStats(ctx, branch, db, table.Name())
+		if err != nil || len(ret) > 0 || revision == "" {
+			return ret, err
+		}
+	}
+	// read-only tables, as of AS OF queries, and the tables of revisions
+	// without statistics may not be at the head of a branch
+	return p.getRootTableStats(ctx, db, branch, table)
 }
 
 func (p *Provider) GetTableDoltStats(ctx *sql.Context, branch, db, table string) ([]sql.Statistic, error) {
@@ -217,11 +239,18 @@ func (p *Provider) getQualStats(ctx *sql.Context, qual sql.StatQualifier) (*Dolt
 	return statDb.GetStat(branch, qual)
 }
 
-func (p *Provider) GetStats(ctx *sql.Context, qual sql.StatQualifier, _ []string) (sql.Statistic, bool) {
+// GetStats returns the statistics |qual|. If there are none, and |cols| are given, it returns the histogram of |cols|
+// in the table of |qual|, if there is one. The planner looks up the statistics of an index with its columns when it
+// costs the filters and lookup joins which can use the index, so the histogram of the columns of an index which has
+// no statistics of its own is used in their estimates.
+func (p *Provider) GetStats(ctx *sql.Context, qual sql.StatQualifier, cols []string) (sql.Statistic, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	stat, ok := p.getQualStats(ctx, qual)
+	if !ok && len(cols) > 0 && !IsHistogramIndex(qual.Index()) {
+		stat, ok = p.getQualStats(ctx, sql.NewStatQualifier(qual.Db(), qual.Table(), HistogramIndex(histogramStatColumns(qual.Table(), cols))))
+	}
 	if !ok {
 		return nil, false
 	}
@@ -249,6 +278,7 @@ func (p *Provider) DropDbStats(ctx *sql.Context, db string, flush bool) error {
 	}
 
 	p.status[db] = "dropped"
+	p.rootStats.Purge()
 
 	return nil
 }
@@ -269,8 +299,11 @@ func (p *Provider) DropStats(ctx *sql.Context, qual sql.StatQualifier, _ []strin
 	}
 
 	if _, ok := statDb.GetStat(branch, qual); ok {
-		statDb.DeleteStats(branch, qual)
+		if err := statDb.DeleteStats(ctx, branch, qual); err != nil {
+			return err
+		}
 		p.UpdateStatus(qual.Db(), fmt.Sprintf("dropped statisic: %s", qual.String()))
+		return statDb.Flush(ctx, branch)
 	}
 
 	return nil
@@ -0,0 +1,266 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/systemtime"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// systemTimeEnd is the ROW_END of the row versions which are current at the head of the branch.
+var systemTimeEnd = time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.UTC)
+
+var _ sql.Table = (*SystemTimeTable)(nil)
+
+// SystemTimeTable is a system table that shows every version of the rows of a table on the current branch, along
+// with the period of commit time during which each version was current. The versions are read with the machinery of
+// the history table, from the commits on the first parent path of the branch head. It backs the FOR SYSTEM_TIME
+// clauses of queries.
+type SystemTimeTable struct {
+	history *HistoryTable
+	head    *doltdb.Commit
+}
+
+// NewSystemTimeTable creates a system time table
+func NewSystemTimeTable(table *DoltTable, ddb *doltdb.DoltDB, head *doltdb.Commit) sql.Table {
+	return &SystemTimeTable{
+		history: NewHistoryTable(table, ddb, head).(*HistoryTable),
+		head:    head,
+	}
+}
+
+// Name returns the name of the system time table
+func (st *SystemTimeTable) Name() string {
+	return doltdb.DoltSystemTimeTablePrefix + st.history.doltTable.Name()
+}
+
+// String returns the name of the system time table
+func (st *SystemTimeTable) String() string {
+	return st.Name()
+}
+
+// Schema returns the schema of the table with the ROW_START and ROW_END columns appended
+func (st *SystemTimeTable) Schema() sql.Schema {
+	tableName := st.Name()
+	baseSch := st.history.doltTable.Schema().Copy()
+	sch := make(sql.Schema, len(baseSch), len(baseSch)+2)
+	for i, col := range baseSch {
+		col.Source = tableName
+		sch[i] = col
+	}
+	return append(sch,
+		&sql.Column{
+			Name:   systemtime.RowStartCol,
+			Source: tableName,
+			Type:   types.DatetimeMaxPrecision,
+		},
+		&sql.Column{
+			Name:   systemtime.RowEndCol,
+			Source: tableName,
+			Type:   types.DatetimeMaxPrecision,
+		},
+	)
+}
+
+// Collation implements the sql.Table interface.
+func (st *SystemTimeTable) Collation() sql.CollationID {
+	return st.history.Collation()
+}
+
+// Partitions implements the sql.Table interface.
+func (st *SystemTimeTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return sql.PartitionsToPartitionIter(&commitPartition{}), nil
+}
+
+// PartitionRows implements the sql.Table interface. The period of each row version starts at the time of the first
+// commit which has it, and ends at the time of the next commit which changes or deletes it.
+func (st *SystemTimeTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	commits, err := st.branchCommits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	table := st.history.doltTable
+	sch := table.Schema()
+	keyOrdinals := table.PrimaryKeySchema().PkOrdinals
+	keyless := len(keyOrdinals) == 0
+	if keyless {
+		keyOrdinals = make([]int, len(sch))
+		for i := range keyOrdinals {
+			keyOrdinals[i] = i
+		}
+	}
+
+	var rows []sql.Row
+	open := make(map[string]systemTimeVersion)
+	var prevHash hash.Hash
+	for i, cp := range commits {
+		meta, err := cp.cm.GetCommitMeta(ctx)
+		if err != nil {
+			return nil, err
+		}
+		root, err := cp.cm.GetRootValue(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		tbl, _, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: table.Name()})
+		if err != nil {
+			return nil, err
+		}
+		var tblHash hash.Hash
+		if ok {
+			tblHash, err = tbl.HashOf()
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 && tblHash == prevHash {
+				continue
+			}
+		}
+		prevHash = tblHash
+
+		current := make(map[string]sql.Row)
+		if ok {
+			current, err = st.rowsAtCommit(ctx, cp, keyOrdinals, keyless)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		commitTime := meta.Time()
+		for key, v := range open {
+			if row, ok := current[key]; ok {
+				eq, err := systemTimeRowsEqual(sch, row, v.row)
+				if err != nil {
+					return nil, err
+				}
+				if eq {
+					continue
+				}
+			}
+			rows = append(rows, append(v.row, v.start, commitTime))
+			delete(open, key)
+		}
+		for key, row := range current {
+			if _, ok := open[key]; !ok {
+				open[key] = systemTimeVersion{row: row, start: commitTime}
+			}
+		}
+	}
+	for _, v := range open {
+		rows = append(rows, append(v.row, v.start, systemTimeEnd))
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// systemTimeVersion is a row version whose period has not ended yet.
+type systemTimeVersion struct {
+	row   sql.Row
+	start time.Time
+}
+
+// branchCommits returns the commits on the first parent path of the head, oldest first. The path ends at the first
+// ghost commit of a shallow clone.
+func (st *SystemTimeTable) branchCommits(ctx *sql.Context) ([]*commitPartition, error) {
+	var commits []*commitPartition
+	cm := st.head
+	for {
+		h, err := cm.HashOf()
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, &commitPartition{h: h, cm: cm})
+		if cm.NumParents() == 0 {
+			break
+		}
+		optCmt, err := cm.GetParent(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		parent, ok := optCmt.ToCommit()
+		if !ok {
+			break
+		}
+		cm = parent
+	}
+
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+// rowsAtCommit returns the rows of the table at the commit of |cp|, converted to the current schema of the table and
+// keyed by the values of |keyOrdinals|. The rows of keyless tables are also keyed by their number of duplicates.
+func (st *SystemTimeTable) rowsAtCommit(ctx *sql.Context, cp *commitPartition, keyOrdinals []int, keyless bool) (map[string]sql.Row, error) {
+	table := st.history.doltTable
+	iter, err := st.history.newRowItrForTableAtCommit(ctx, table, cp.h, cp.cm, sql.IndexLookup{}, table.ProjectedTags())
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close(ctx)
+
+	rows := make(map[string]sql.Row)
+	duplicates := make(map[string]int)
+	for {
+		row, err := iter.Next(ctx)
+		if err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		var sb strings.Builder
+		for _, i := range keyOrdinals {
+			fmt.Fprintf(&sb, "%v\x00", row[i])
+		}
+		key := sb.String()
+		if keyless {
+			duplicates[key]++
+			key = fmt.Sprintf("%s%d", key, duplicates[key])
+		}
+		rows[key] = row
+	}
+}
+
+func systemTimeRowsEqual(sch sql.Schema, left, right sql.Row) (bool, error) {
+	for i, col := range sch {
+		if left[i] == nil || right[i] == nil {
+			if left[i] == nil && right[i] == nil {
+				continue
+			}
+			return false, nil
+		}
+		cmp, err := col.Type.Compare(left[i], right[i])
+		if err != nil {
+			return false, err
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -39,6 +39,84 @@ func AddDoltSystemVariables() {
 			Type:              types.NewSystemStringType("log_bin_branch"),
 			Default:           "main",
 		},
+		&sql.MysqlSystemVariable{
+			Name:              "log_bin_branch_mappings",
+			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Dynamic:           false,
+			SetVarHintApplies: false,
+			Type:              types.NewSystemStringType("log_bin_branch_mappings"),
+			Default:           "",
+		},
+		&sql.MysqlSystemVariable{
+			Name:              "replicate_do_db",
+			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Dynamic:           true,
+			SetVarHintApplies: false,
+			Type:              types.NewSystemStringType("replicate_do_db"),
+			Default:           "",
+		},
+		&sql.MysqlSystemVariable{
+			Name:              "replicate_ignore_db",
+			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Dynamic:           true,
+			SetVarHintApplies: false,
+			Type:              types.NewSystemStringType("replicate_ignore_db"),
+			Default:           "",
+		},
+		&sql.MysqlSystemVariable{
+			Name:              "replicate_wild_do_table",
+			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Dynamic:           true,
+			SetVarHintApplies: false,
+			Type:              types.NewSystemStringType("replicate_wild_do_table"),
+			Default:           "",
+		},
+		&sql.MysqlSystemVariable{
+			Name:              "replicate_wild_ignore_table",
+			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Dynamic:           true,
+			SetVarHintApplies: false,
+			Type:              types.NewSystemStringType("replicate_wild_ignore_table"),
+			Default:           "",
+		},
+		&sql.MysqlSystemVariable{
+			Name:              "replicate_rewrite_db",
+			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Dynamic:           true,
+			SetVarHintApplies: false,
+			Type:              types.NewSystemStringType("replicate_rewrite_db"),
+			Default:           "",
+		},
+		&sql.MysqlSystemVariable{
+			Name:              "dolt_replica_branch",
+			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Dynamic:           true,
+			SetVarHintApplies: false,
+			Type:              types.NewSystemStringType("dolt_replica_branch"),
+			Default:           "",
+		},
+		&sql.MysqlSystemVariable{
+			Name:    "dolt_replica_commit_transactions",
+			Dynamic: true,
+			Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Type:    types.NewSystemIntType("dolt_replica_commit_transactions", 0, math.MaxInt, false),
+			Default: int64(1),
+		},
+		&sql.MysqlSystemVariable{
+			Name:    "dolt_replica_commit_seconds",
+			Dynamic: true,
+			Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Type:    types.NewSystemIntType("dolt_replica_commit_seconds", 0, math.MaxInt, false),
+			Default: int64(0),
+		},
+		&sql.MysqlSystemVariable{
+			Name:              "dolt_replica_commit_author",
+			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Persist),
+			Dynamic:           true,
+			SetVarHintApplies: false,
+			Type:              types.NewSystemStringType("dolt_replica_commit_author"),
+			Default:           "",
+		},
 		&sql.MysqlSystemVariable{
 			Name:              dsess.DoltOverrideSchema,
 			Scope:             sql.GetMysqlScope(sql.SystemVariableScope_Both),
@@ -198,6 +276,20 @@ func AddDoltSystemVariables() {
 			Type:    types.NewSystemIntType(dsess.DoltClusterAckWritesTimeoutSecs, 0, 60, false),
 			Default: int64(0),
 		},
+		&sql.MysqlSystemVariable{
+			Name:    dsess.DoltMaxReplicaLagMs,
+			Dynamic: true,
+			Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Both),
+			Type:    types.NewSystemIntType(dsess.DoltMaxReplicaLagMs, 0, math.MaxInt64, false),
+			Default: int64(0),
+		},
+		&sql.MysqlSystemVariable{
+			Name:    dsess.DoltSlowQueryTableSize,
+			Dynamic: true,
+			Scope:   sql.GetMysqlScope(sql.SystemVariableScope_Global),
+			Type:    types.NewSystemIntType(dsess.DoltSlowQueryTableSize, 0, 100000, false),
+			Default: int64(0),
+		},
 		&sql.MysqlSystemVariable{
 			Name:    dsess.ShowSystemTables,
 			Dynamic: true,
@@ -0,0 +1,268 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package systemtime implements the SQL:2011 FOR SYSTEM_TIME clauses of
+// table references by rewriting them into queries of the system time tables
+// of Dolt, which hold every version of the rows of a table on the current
+// branch along with the period during which each version was committed.
+//
+// A FOR SYSTEM_TIME AS OF clause whose time is a string that is not a date or
+// time, such as a branch name or 'HEAD~1', names a revision instead, and is
+// left to the AS OF clause of Dolt.
+package systemtime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlscan"
+)
+
+const (
+	// RowStartCol is the column of a system time table holding the commit
+	// time at which a row version was first committed.
+	RowStartCol = "ROW_START"
+	// RowEndCol is the column of a system time table holding the commit time
+	// at which a row version was replaced or deleted.
+	RowEndCol = "ROW_END"
+)
+
+// asOfMarker is the column that Prepare adds to the time of a FOR
+// SYSTEM_TIME AS OF clause, so that Rewrite can tell it apart from a plain AS
+// OF clause, which the parser does not. The marked clause is no longer than
+// the shortest FOR SYSTEM_TIME AS OF.
+const asOfMarker = "dolt_systime"
+
+// timestampLayouts are the layouts of the strings which FOR SYSTEM_TIME AS OF
+// compares with the commit times of row versions. Other strings name
+// revisions.
+var timestampLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"20060102",
+	"20060102150405",
+}
+
+// Prepare returns |query| with its FOR SYSTEM_TIME AS OF <time> clauses
+// replaced by AS OF `dolt_systime`+<time>, which parses into an AS OF clause
+// that Rewrite recognizes. Clauses whose time is a string naming a revision
+// are replaced by AS OF <time>, which Dolt resolves. Prepare must be applied
+// to a query before it is parsed for Rewrite. The replacements are padded
+// with spaces to the length of the clauses they replace, so that the
+// positions in the prepared query are the same as in |query|.
+func Prepare(query string) string {
+	if !strings.Contains(strings.ToLower(query), "system_time") {
+		return query
+	}
+
+	s := sqlscan.New(query)
+	var sb strings.Builder
+	last := 0
+	for s.Skip(); s.Pos < len(query); s.Skip() {
+		c := query[s.Pos]
+		switch {
+		case sqlscan.IsQuote(c):
+			s.Pos = sqlscan.SkipQuoted(query, s.Pos)
+		case sqlscan.IsWordChar(c):
+			start := s.Pos
+			if !s.Keywords("for", "system_time", "as", "of") {
+				s.Word()
+				continue
+			}
+			marked := "AS OF `" + asOfMarker + "`+"
+			if isRevision(query, s.Pos) {
+				marked = "AS OF"
+			}
+			sb.WriteString(query[last:start])
+			sb.WriteString(marked)
+			sb.WriteString(strings.Repeat(" ", s.Pos-start-len(marked)))
+			last = s.Pos
+		default:
+			s.Pos++
+		}
+	}
+	if last == 0 {
+		return query
+	}
+	sb.WriteString(query[last:])
+	return sb.String()
+}
+
+// Rewrite replaces each table reference of |stmt| with a FOR SYSTEM_TIME
+// clause by a derived table of the same name, which selects the row versions
+// of the clause from the system time table of the table. |tablePrefix| is the
+// prefix of the names of system time tables. Rewrite returns whether |stmt|
+// was changed.
+//
+// The parser produces the same clauses for FOR VERSION and VERSIONS as for
+// FOR SYSTEM_TIME, so those are rewritten the same way.
+func Rewrite(stmt sqlparser.Statement, tablePrefix string) (bool, error) {
+	changed := false
+	err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		ate, ok := node.(*sqlparser.AliasedTableExpr)
+		if !ok || ate.AsOf == nil {
+			return true, nil
+		}
+		tn, ok := ate.Expr.(sqlparser.TableName)
+		if !ok {
+			return true, nil
+		}
+
+		var pred sqlparser.Expr
+		switch asOf := ate.AsOf; {
+		case asOf.All:
+		case asOf.Time != nil:
+			t, ok := unmark(asOf.Time)
+			if !ok {
+				// a plain AS OF clause, which is resolved by Dolt
+				return true, nil
+			}
+			if ate.Hints != nil || len(ate.Partitions) > 0 {
+				return false, unsupported(tn)
+			}
+			pred = periodPredicate(sqlparser.LessEqualStr, t, sqlparser.GreaterThanStr, t)
+		case asOf.Start != nil && asOf.End != nil:
+			if asOf.StartInclusive {
+				// CONTAINED IN (start, end)
+				pred = &sqlparser.AndExpr{
+					Left:  compare(RowStartCol, sqlparser.GreaterEqualStr, asOf.Start),
+					Right: compare(RowEndCol, sqlparser.LessEqualStr, asOf.End),
+				}
+			} else if asOf.EndInclusive {
+				// BETWEEN start AND end
+				pred = periodPredicate(sqlparser.LessEqualStr, asOf.End, sqlparser.GreaterThanStr, asOf.Start)
+			} else {
+				// FROM start TO end
+				pred = periodPredicate(sqlparser.LessThanStr, asOf.End, sqlparser.GreaterThanStr, asOf.Start)
+			}
+		default:
+			return true, nil
+		}
+		if ate.Hints != nil || len(ate.Partitions) > 0 {
+			return false, unsupported(tn)
+		}
+
+		alias := ate.As
+		if alias.IsEmpty() {
+			alias = tn.Name
+		}
+		versions := tn
+		versions.Name = sqlparser.NewTableIdent(tablePrefix + tn.Name.String())
+		ate.Expr = &sqlparser.Subquery{Select: &sqlparser.Select{
+			SelectExprs: sqlparser.SelectExprs{&sqlparser.StarExpr{}},
+			From:        sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: versions}},
+			Where:       sqlparser.NewWhere(sqlparser.WhereStr, pred),
+		}}
+		ate.As = alias
+		ate.AsOf = nil
+		changed = true
+		return false, nil
+	}, stmt)
+	return changed, err
+}
+
+// unsupported returns the error of a FOR SYSTEM_TIME clause of table |tn| with
+// index hints or partitions, which system time tables don't have.
+func unsupported(tn sqlparser.TableName) error {
+	return fmt.Errorf("FOR SYSTEM_TIME clause of table %s cannot be combined with index hints or partitions", sqlparser.String(tn))
+}
+
+// periodPredicate returns the predicate that the period of a row version
+// overlaps a time range, which is that the version started before the end of
+// the range and ended after its start.
+func periodPredicate(startOp string, end sqlparser.Expr, endOp string, start sqlparser.Expr) sqlparser.Expr {
+	return &sqlparser.AndExpr{
+		Left:  compare(RowStartCol, startOp, end),
+		Right: compare(RowEndCol, endOp, start),
+	}
+}
+
+func compare(col, op string, expr sqlparser.Expr) sqlparser.Expr {
+	return &sqlparser.ComparisonExpr{
+		Operator: op,
+		Left:     &sqlparser.ColName{Name: sqlparser.NewColIdent(col)},
+		Right:    expr,
+	}
+}
+
+// unmark returns |expr| without the asOfMarker added by Prepare, and whether
+// it had one. Since Prepare adds the marker with +, the marker is the
+// leftmost operand of |expr|, and it is removed by replacing the addition
+// which has it as its left operand with its right operand.
+func unmark(expr sqlparser.Expr) (sqlparser.Expr, bool) {
+	var left *sqlparser.Expr
+	switch e := expr.(type) {
+	case *sqlparser.BinaryExpr:
+		if e.Operator == sqlparser.PlusStr && isMarker(e.Left) {
+			return e.Right, true
+		}
+		left = &e.Left
+	case *sqlparser.ComparisonExpr:
+		left = &e.Left
+	case *sqlparser.AndExpr:
+		left = &e.Left
+	case *sqlparser.OrExpr:
+		left = &e.Left
+	case *sqlparser.XorExpr:
+		left = &e.Left
+	case *sqlparser.IsExpr:
+		left = &e.Expr
+	case *sqlparser.CollateExpr:
+		left = &e.Expr
+	default:
+		return expr, false
+	}
+	unmarked, ok := unmark(*left)
+	if ok {
+		*left = unmarked
+	}
+	return expr, ok
+}
+
+func isMarker(expr sqlparser.Expr) bool {
+	col, ok := expr.(*sqlparser.ColName)
+	return ok && col.Qualifier.IsEmpty() && col.Name.EqualString(asOfMarker)
+}
+
+// isRevision returns whether the time of the FOR SYSTEM_TIME AS OF clause
+// ending at |i| in |query| is a string which names a revision rather than a
+// date or time.
+func isRevision(query string, i int) bool {
+	s := &sqlscan.Scanner{Query: query, Pos: i}
+	if c := s.Peek(); c != '\'' && c != '"' {
+		return false
+	}
+	lit, err := s.Str()
+	if err != nil {
+		return false
+	}
+	if c := s.Peek(); c != 0 && !sqlscan.IsWordChar(c) && !strings.ContainsRune("),;", rune(c)) {
+		// the string is an operand of an expression
+		return false
+	}
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, strings.TrimSpace(lit)); err == nil {
+			return false
+		}
+	}
+	return true
+}
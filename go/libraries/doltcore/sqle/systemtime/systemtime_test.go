@@ -0,0 +1,172 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemtime
+
+import (
+	"testing"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepare(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    "select * from t as of '2024-01-01'",
+			expected: "select * from t as of '2024-01-01'",
+		},
+		{
+			query:    "select * from t for system_time as of '2024-01-01'",
+			expected: "select * from t AS OF `dolt_systime`+ '2024-01-01'",
+		},
+		{
+			query:    "select * from t for system_time as of '2024-01-01 12:00:00' a",
+			expected: "select * from t AS OF `dolt_systime`+ '2024-01-01 12:00:00' a",
+		},
+		{
+			query:    "select * from t for system_time as of 'mybranch'",
+			expected: "select * from t AS OF                 'mybranch'",
+		},
+		{
+			query:    "select * from t for system_time as of \"HEAD~1\" join u",
+			expected: "select * from t AS OF                 \"HEAD~1\" join u",
+		},
+		{
+			query:    "select * from t for system_time as of 'x' + interval 1 day",
+			expected: "select * from t AS OF `dolt_systime`+ 'x' + interval 1 day",
+		},
+		{
+			query:    "select * from t FOR\n  SYSTEM_TIME  As Of now() a",
+			expected: "select * from t AS OF `dolt_systime`+    now() a",
+		},
+		{
+			query:    "select 'for system_time as of', `for system_time as of` from t for system_time all",
+			expected: "select 'for system_time as of', `for system_time as of` from t for system_time all",
+		},
+		{
+			query:    "select * from t /* for system_time as of */ -- for system_time as of\n",
+			expected: "select * from t /* for system_time as of */ -- for system_time as of\n",
+		},
+		{
+			query:    "select 'it''s', \"a\\\"b\" from t for system_time as of x",
+			expected: "select 'it''s', \"a\\\"b\" from t AS OF `dolt_systime`+ x",
+		},
+		{
+			query:    "select * from t for system_time_x as of x",
+			expected: "select * from t for system_time_x as of x",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			prepared := Prepare(test.query)
+			assert.Equal(t, test.expected, prepared)
+			assert.Equal(t, len(test.query), len(prepared))
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    "select * from t",
+			expected: "select * from t",
+		},
+		{
+			query:    "select * from t as of 'main'",
+			expected: "select * from t as of 'main'",
+		},
+		{
+			query:    "select * from t for system_time all",
+			expected: "select * from (select * from dolt_system_time_t) as t",
+		},
+		{
+			query:    "select * from t for system_time as of '2024-01-01'",
+			expected: "select * from (select * from dolt_system_time_t where ROW_START <= '2024-01-01' and ROW_END > '2024-01-01') as t",
+		},
+		{
+			query:    "select * from t for system_time as of now() - interval 1 day",
+			expected: "select * from (select * from dolt_system_time_t where ROW_START <= now() - interval 1 day and ROW_END > now() - interval 1 day) as t",
+		},
+		{
+			query:    "select * from t for system_time as of a + b * c",
+			expected: "select * from (select * from dolt_system_time_t where ROW_START <= a + b * c and ROW_END > a + b * c) as t",
+		},
+		{
+			query:    "select * from t for system_time between 'a' and 'b' x",
+			expected: "select * from (select * from dolt_system_time_t where ROW_START <= 'b' and ROW_END > 'a') as x",
+		},
+		{
+			query:    "select * from t for system_time from 'a' to 'b'",
+			expected: "select * from (select * from dolt_system_time_t where ROW_START < 'b' and ROW_END > 'a') as t",
+		},
+		{
+			query:    "select * from t for system_time contained in ('a', 'b')",
+			expected: "select * from (select * from dolt_system_time_t where ROW_START >= 'a' and ROW_END <= 'b') as t",
+		},
+		{
+			query:    "select * from db.t for system_time all join u on t.id = u.id",
+			expected: "select * from (select * from db.dolt_system_time_t) as t join u on t.id = u.id",
+		},
+		{
+			query:    "select * from u where id in (select id from t for system_time all)",
+			expected: "select * from u where id in (select id from (select * from dolt_system_time_t) as t)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(Prepare(test.query))
+			require.NoError(t, err)
+			changed, err := Rewrite(stmt, "dolt_system_time_")
+			require.NoError(t, err)
+			actual := sqlparser.String(stmt)
+			assert.Equal(t, test.expected, actual)
+			assert.Equal(t, test.query != test.expected, changed)
+
+			_, err = sqlparser.Parse(actual)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRewriteRevision(t *testing.T) {
+	stmt, err := sqlparser.Parse(Prepare("select * from t for system_time as of 'HEAD~1'"))
+	require.NoError(t, err)
+	changed, err := Rewrite(stmt, "dolt_system_time_")
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "select * from t as of 'HEAD~1'", sqlparser.String(stmt))
+}
+
+func TestRewriteUnsupported(t *testing.T) {
+	for _, query := range []string{
+		"select * from t for system_time all use index (i)",
+		"select * from t for system_time as of now() use index (i)",
+		"select * from t partition (p0) for system_time all",
+	} {
+		t.Run(query, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(Prepare(query))
+			require.NoError(t, err)
+			_, err = Rewrite(stmt, "dolt_system_time_")
+			assert.Error(t, err)
+		})
+	}
+}
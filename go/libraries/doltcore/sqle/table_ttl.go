@@ -0,0 +1,136 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/branch_control"
+	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
+	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/ttl"
+)
+
+// TTLProcedures are the stored procedures that set the TTL of tables. The ALTER TABLE ... TTL and ALTER TABLE ...
+// REMOVE TTL statements are run by these procedures.
+var TTLProcedures = []sql.ExternalStoredProcedureDetails{
+	{Name: ttl.SetTTLProcedure, Schema: rowPolicyProcedureSchema, Function: doltSetTTL, AdminOnly: true},
+}
+
+// doltSetTTL is the stored procedure that sets the TTL of a table, or removes it if no TTL is given:
+//
+//	CALL dolt_set_ttl('table' [, 'column + INTERVAL n unit'])
+func doltSetTTL(ctx *sql.Context, args ...string) (sql.RowIter, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("%s requires a table and an optional TTL", ttl.SetTTLProcedure)
+	}
+	db, ok, err := materializedViewDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("database %s does not support TTLs", ctx.GetCurrentDatabase())
+	}
+	if err := dsess.CheckAccessForDb(ctx, db, branch_control.Permissions_Write); err != nil {
+		return nil, err
+	}
+
+	root, err := db.GetRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tbl, tableName, ok, err := doltdb.GetTableInsensitive(ctx, root, doltdb.TableName{Name: args[0]})
+	if err != nil {
+		return nil, err
+	}
+	if !ok || doltdb.HasDoltPrefix(tableName) {
+		return nil, sql.ErrTableNotFound.New(args[0])
+	}
+	sch, err := tbl.GetSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec string
+	if len(args) == 2 {
+		t, err := ttl.Parse(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if t, err = resolveTTL(sch, t); err != nil {
+			return nil, err
+		}
+		spec = t.String()
+	}
+	if spec == sch.GetTTL() {
+		return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+	}
+
+	newSch := sch.Copy()
+	newSch.SetTTL(spec)
+	tbl, err = tbl.UpdateSchema(ctx, newSch)
+	if err != nil {
+		return nil, err
+	}
+	root, err = root.PutTable(ctx, doltdb.TableName{Name: tableName}, tbl)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.SetRoot(ctx, root); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(sql.Row{int64(0)}), nil
+}
+
+// resolveTTL returns |t|, a TTL of a table with the schema |sch|, with its column named as in |sch|. It returns an
+// error if |sch| has no such column, or if the column does not hold times.
+func resolveTTL(sch schema.Schema, t ttl.TTL) (ttl.TTL, error) {
+	col, ok := sch.GetAllCols().GetByNameCaseInsensitive(t.Column)
+	if !ok {
+		return ttl.TTL{}, sql.ErrColumnNotFound.New(t.Column)
+	}
+	if _, ok := col.TypeInfo.ToSqlType().(sql.DatetimeType); !ok {
+		return ttl.TTL{}, fmt.Errorf("the TTL column %s must be a DATE, DATETIME or TIMESTAMP column, but is %s", col.Name, col.TypeInfo.ToSqlType().String())
+	}
+	t.Column = col.Name
+	return t, nil
+}
+
+// ttlForColumnChange returns the TTL of the table with the schema |newSch|, to which the schema |oldSch| is changed
+// by changing the column |oldColumn| into |newColumn|. The TTL follows a renamed column, and the column of a TTL
+// cannot be dropped or changed into a column which doesn't hold times. Either column is "" if it is added or dropped.
+func ttlForColumnChange(oldSch, newSch schema.Schema, oldColumn, newColumn string) (string, error) {
+	if oldSch.GetTTL() == "" {
+		return "", nil
+	}
+	t, err := ttl.Parse(oldSch.GetTTL())
+	if err != nil {
+		return "", err
+	}
+	if oldColumn != "" && strings.EqualFold(oldColumn, t.Column) {
+		if newColumn == "" {
+			return "", fmt.Errorf("the column %s cannot be dropped, because it is the TTL column of its table", oldColumn)
+		}
+		t.Column = newColumn
+	}
+	if t, err = resolveTTL(newSch, t); err != nil {
+		return "", err
+	}
+	return t.String(), nil
+}
@@ -255,8 +255,18 @@ func (t *DoltTable) getRoot(ctx *sql.Context) (doltdb.RootValue, error) {
 	return t.db.GetRoot(ctx)
 }
 
-// GetIndexes implements sql.IndexedTable
+// GetIndexes implements sql.IndexedTable. The indexes of the columns which are masked for the current user are left
+// out, see unmaskedIndexes.
 func (t *DoltTable) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
+	indexes, err := t.getIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.unmaskedIndexes(ctx, indexes)
+}
+
+// getIndexes returns all the indexes of |t|.
+func (t *DoltTable) getIndexes(ctx *sql.Context) ([]sql.Index, error) {
 	// If a schema override is in place, we can't trust that the indexes stored with the data
 	// will match up to the overridden schema, so we disable indexes. We could improve this by
 	// adding schema mapping for the indexes.
@@ -464,7 +474,12 @@ func (t *DoltTable) DataLength(ctx *sql.Context) (uint64, error) {
 // RowCount implements the sql.StatisticsTable interface.
 func (t *DoltTable) RowCount(ctx *sql.Context) (uint64, bool, error) {
 	rows, err := t.numRows(ctx)
-	return rows, true, err
+	if err != nil {
+		return 0, false, err
+	}
+	// The count of a table restricted by row-level security policies is not the count of the rows the user can read
+	restricted, err := t.RowPolicyRestricted(ctx)
+	return rows, !restricted, err
 }
 
 func (t *DoltTable) PrimaryKeySchema() sql.PrimaryKeySchema {
@@ -504,24 +519,45 @@ func (t *DoltTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sq
 		}
 	}
 
+	// Rows restricted by row-level security policies are filtered on all of their columns, and projected afterward.
+	filter, err := t.rowPolicyFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil {
+		projCols = t.allColumnTags()
+	}
+
+	masker, err := t.columnMasker(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	originalRowIter, err := partitionRows(ctx, table, projCols, partition)
 	if err != nil {
 		return originalRowIter, err
 	}
+	if filter != nil {
+		originalRowIter = t.filterRowPolicy(filter, originalRowIter, t.overriddenSchema == nil)
+	}
 
 	if t.overriddenSchema != nil {
-		return newMappingRowIter(ctx, t, originalRowIter)
+		iter, err := newMappingRowIter(ctx, t, originalRowIter)
+		if err != nil {
+			return nil, err
+		}
+		return masker.RowIter(t.Schema(), iter), nil
 	} else {
-		return originalRowIter, err
+		return masker.RowIter(t.Schema(), originalRowIter), err
 	}
 }
 
 func partitionRows(ctx *sql.Context, t *doltdb.Table, projCols []uint64, partition sql.Partition) (sql.RowIter, error) {
 	switch typedPartition := partition.(type) {
 	case doltTablePartition:
-		return newRowIterator(ctx, t, projCols, typedPartition)
+		return withRowsExamined(ctx, newRowIterator(ctx, t, projCols, typedPartition))
 	case index.SinglePartition:
-		return newRowIterator(ctx, t, projCols, doltTablePartition{rowData: typedPartition.RowData, end: NoUpperBound})
+		return withRowsExamined(ctx, newRowIterator(ctx, t, projCols, doltTablePartition{rowData: typedPartition.RowData, end: NoUpperBound}))
 	}
 
 	return nil, errors.New("unsupported partition type")
@@ -585,6 +621,10 @@ func (t *WritableDoltTable) Inserter(ctx *sql.Context) sql.RowInserter {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
+	te, err = t.withRowPolicies(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
 	return te
 }
 
@@ -845,6 +885,14 @@ func (t *WritableDoltTable) Deleter(ctx *sql.Context) sql.RowDeleter {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
+	te, err = t.withRowPolicies(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+	te, err = t.withColumnMasks(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
 	return te
 }
 
@@ -857,6 +905,10 @@ func (t *WritableDoltTable) Replacer(ctx *sql.Context) sql.RowReplacer {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
+	te, err = t.withRowPolicies(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
 	return te
 }
 
@@ -865,6 +917,9 @@ func (t *WritableDoltTable) Truncate(ctx *sql.Context) (int, error) {
 	if err := dsess.CheckAccessForDb(ctx, t.db, branch_control.Permissions_Write); err != nil {
 		return 0, err
 	}
+	if err := t.checkRowPolicyTruncate(ctx); err != nil {
+		return 0, err
+	}
 	table, err := t.DoltTable.DoltTable(ctx)
 	if err != nil {
 		return 0, err
@@ -1011,6 +1066,14 @@ func (t *WritableDoltTable) Updater(ctx *sql.Context) sql.RowUpdater {
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
+	te, err = t.withRowPolicies(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+	te, err = t.withColumnMasks(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
 	return te
 }
 
@@ -1120,6 +1183,14 @@ func (t *WritableDoltTable) GetForeignKeyEditor(ctx *sql.Context) sql.ForeignKey
 	if err != nil {
 		return sqlutil.NewStaticErrorEditor(err)
 	}
+	te, err = t.withRowPolicies(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
+	te, err = t.withColumnMasks(ctx, te)
+	if err != nil {
+		return sqlutil.NewStaticErrorEditor(err)
+	}
 	return te
 }
 
@@ -1681,6 +1752,23 @@ func (t *AlterableDoltTable) RewriteInserter(
 		}
 	}
 
+	if err = validateIndexExpressions(ctx, t.Name(), newSch); err != nil {
+		return nil, err
+	}
+
+	var oldColumnName, newColumnName string
+	if oldColumn != nil {
+		oldColumnName = oldColumn.Name
+	}
+	if newColumn != nil {
+		newColumnName = newColumn.Name
+	}
+	ttlSpec, err := ttlForColumnChange(oldSch, newSch, oldColumnName, newColumnName)
+	if err != nil {
+		return nil, err
+	}
+	newSch.SetTTL(ttlSpec)
+
 	// If we have an auto increment column, we need to set it here before we begin the rewrite process (it may have changed)
 	if schema.HasAutoIncrement(newSch) {
 		newSch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
@@ -1912,6 +2000,7 @@ func modifyFulltextIndexForColumnDrop(index schema.Index, newSch schema.Schema,
 			IsFullText:         true,
 			IsUserDefined:      index.IsUserDefined(),
 			Comment:            index.Comment(),
+			Predicate:          index.Predicate(),
 			FullTextProperties: index.FullTextProperties(),
 		})
 }
@@ -1966,7 +2055,9 @@ func modifyIndexesForTableRewrite(ctx *sql.Context, oldSch schema.Schema, oldCol
 				IsFullText:         index.IsFullText(),
 				IsUserDefined:      index.IsUserDefined(),
 				Comment:            index.Comment(),
+				Predicate:          index.Predicate(),
 				FullTextProperties: index.FullTextProperties(),
+				Expressions:        index.Expressions(),
 			})
 	}
 
@@ -2202,6 +2293,13 @@ func (t *AlterableDoltTable) ModifyColumn(ctx *sql.Context, columnName string, c
 	if err != nil {
 		return err
 	}
+	updatedSch, err := updatedTable.GetSchema(ctx)
+	if err != nil {
+		return err
+	}
+	if err = validateIndexExpressions(ctx, t.Name(), updatedSch); err != nil {
+		return err
+	}
 
 	// For auto columns modified to be auto increment, we have more work to do
 	if !existingCol.AutoIncrement && col.AutoIncrement {
@@ -3193,6 +3291,10 @@ func findIndexWithPrefix(sch schema.Schema, prefixCols []string) (schema.Index,
 	colLen := len(prefixCols)
 	var indexesWithLen []idxWithLen
 	for _, idx := range indexes {
+		if idx.Predicate() != "" {
+			// partial indexes don't hold every row, so they cannot back foreign keys
+			continue
+		}
 		idxCols := lowercaseSlice(idx.ColumnNames())
 		if ok, prefixCount := colsAreIndexSubset(prefixCols, idxCols); ok && prefixCount == colLen {
 			indexesWithLen = append(indexesWithLen, idxWithLen{idx, len(idxCols)})
@@ -0,0 +1,258 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ttl parses the TTL table option, which makes the rows of a table
+// expire a fixed interval after the time held by one of their columns:
+//
+//	CREATE TABLE sessions (id INT PRIMARY KEY, created_at DATETIME) TTL = created_at + INTERVAL 30 DAY
+//	ALTER TABLE sessions TTL = created_at + INTERVAL 7 DAY
+//	ALTER TABLE sessions REMOVE TTL
+//
+// The parser does not support the option, so the TTL of a CREATE TABLE
+// statement is removed from the statement before it is parsed and carried
+// to the table in its comment, and the ALTER TABLE statements are rewritten
+// into calls of the stored procedure which sets the TTL of a table.
+package ttl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlscan"
+)
+
+// Units are the units of the intervals of TTLs.
+var Units = []string{"SECOND", "MINUTE", "HOUR", "DAY", "WEEK", "MONTH", "QUARTER", "YEAR"}
+
+// SetTTLProcedure is the stored procedure which sets the TTL of a table:
+//
+//	CALL dolt_set_ttl('table', 'column + INTERVAL n unit')
+//
+// or removes it when it is called with only the table.
+const SetTTLProcedure = "dolt_set_ttl"
+
+// commentMarker starts the TTL which Mark adds to the comment of a CREATE TABLE statement.
+const commentMarker = "/*dolt_ttl "
+
+// TTL is the time to live of the rows of a table. A row expires once the
+// time in its Column is Interval Units in the past.
+type TTL struct {
+	Column   string
+	Interval int64
+	// Unit is one of Units.
+	Unit string
+}
+
+// String returns |t| as it is written in a TTL table option.
+func (t TTL) String() string {
+	return fmt.Sprintf("%s + INTERVAL %d %s", sqlscan.QuoteIdent(t.Column), t.Interval, t.Unit)
+}
+
+// Expired returns the predicate of the rows of a table with TTL |t| which have expired.
+func (t TTL) Expired() string {
+	return fmt.Sprintf("%s <= DATE_SUB(NOW(), INTERVAL %d %s)", sqlscan.QuoteIdent(t.Column), t.Interval, t.Unit)
+}
+
+// Parse parses the TTL |spec|, written as in a TTL table option without the TTL keyword.
+func Parse(spec string) (TTL, error) {
+	s := &scanner{sqlscan.New(spec)}
+	t, err := s.ttl()
+	if err != nil {
+		return TTL{}, err
+	}
+	if s.Peek() != 0 {
+		return TTL{}, s.Errorf("unexpected input")
+	}
+	return t, nil
+}
+
+// Alter is an ALTER TABLE statement which sets or removes the TTL of a table.
+type Alter struct {
+	Table string
+	// TTL is the new TTL of the table, or nil if the statement removes it.
+	TTL *TTL
+}
+
+// ParseAlter parses |query| if it is an ALTER TABLE statement which sets or removes the TTL of a table, and returns
+// it along with the index of the end of the statement in |query|, past its terminating semicolon. ParseAlter returns
+// nil for other statements, including ALTER TABLE statements which make other changes to a table.
+func ParseAlter(query string) (*Alter, int, error) {
+	s := &scanner{sqlscan.New(query)}
+	if !s.Keywords("alter", "table") {
+		return nil, 0, nil
+	}
+	table, err := s.Ident()
+	if err != nil || s.Peek() == '.' {
+		return nil, 0, nil
+	}
+
+	a := &Alter{Table: table}
+	switch {
+	case s.Keywords("remove", "ttl"):
+	case s.Keywords("ttl"):
+		t, err := s.ttl()
+		if err != nil {
+			return nil, 0, err
+		}
+		a.TTL = &t
+	default:
+		return nil, 0, nil
+	}
+	end, err := s.End()
+	if err != nil {
+		return nil, 0, err
+	}
+	return a, end, nil
+}
+
+// Call returns the CALL statement of the stored procedure which runs |a|.
+func (a *Alter) Call() *sqlparser.Call {
+	params := []sqlparser.Expr{sqlparser.NewStrVal([]byte(a.Table))}
+	if a.TTL != nil {
+		params = append(params, sqlparser.NewStrVal([]byte(a.TTL.String())))
+	}
+	return &sqlparser.Call{
+		ProcName: sqlparser.ProcedureName{Name: sqlparser.NewColIdent(SetTTLProcedure)},
+		Params:   params,
+	}
+}
+
+// Prepare returns |query| with the TTL option of its first statement removed, if it is a CREATE TABLE statement,
+// along with the TTL. The TTL is replaced by spaces, so that the positions in the prepared query are the same as in
+// |query|. After the prepared query is parsed, the TTL must be added to the statement with Mark.
+func Prepare(query string) (string, *TTL, error) {
+	if !strings.Contains(strings.ToLower(query), "ttl") {
+		return query, nil, nil
+	}
+	s := &scanner{sqlscan.New(query)}
+	if !s.Keywords("create", "table") {
+		return query, nil, nil
+	}
+
+	// the TTL option follows the parenthesized definitions of the columns
+	depth, defined := 0, false
+	for s.Pos < len(s.Query) {
+		s.Skip()
+		if s.Pos == len(s.Query) {
+			break
+		}
+		switch c := s.Query[s.Pos]; {
+		case sqlscan.IsQuote(c):
+			s.Pos = sqlscan.SkipQuoted(s.Query, s.Pos)
+		case c == '(':
+			depth++
+			s.Pos++
+		case c == ')':
+			depth--
+			defined = defined || depth == 0
+			s.Pos++
+		case c == ';' && depth == 0:
+			return query, nil, nil
+		case sqlscan.IsWordChar(c):
+			start := s.Pos
+			w := strings.ToLower(s.Word())
+			if depth != 0 || !defined {
+				continue
+			}
+			switch w {
+			case "as", "select", "like":
+				return query, nil, nil
+			case "ttl":
+				t, err := s.ttl()
+				if err != nil {
+					return "", nil, err
+				}
+				prepared := query[:start] + strings.Repeat(" ", s.Pos-start) + query[s.Pos:]
+				return prepared, &t, nil
+			}
+		default:
+			s.Pos++
+		}
+	}
+	return query, nil, nil
+}
+
+// Mark adds |t| to |stmt|, the CREATE TABLE statement parsed from a query prepared by Prepare. The TTL is added to
+// the comment of the table, from which it is removed by SplitComment when the table is created.
+func Mark(stmt sqlparser.Statement, t TTL) error {
+	ddl, ok := stmt.(*sqlparser.DDL)
+	if !ok || ddl.Action != sqlparser.CreateStr || ddl.TableSpec == nil {
+		return fmt.Errorf("TTL can only be applied to a CREATE TABLE statement with column definitions")
+	}
+	marker := commentMarker + t.String() + "*/"
+	for _, opt := range ddl.TableSpec.TableOpts {
+		if strings.EqualFold(opt.Name, "comment") {
+			opt.Value = marker + opt.Value
+			return nil
+		}
+	}
+	ddl.TableSpec.TableOpts = append(ddl.TableSpec.TableOpts, &sqlparser.TableOption{Name: "COMMENT", Value: marker})
+	return nil
+}
+
+// SplitComment returns the comment of a table without the TTL added to it by Mark, along with the TTL, or nil if the
+// comment has none.
+func SplitComment(comment string) (string, *TTL, error) {
+	if !strings.HasPrefix(comment, commentMarker) {
+		return comment, nil, nil
+	}
+	end := strings.Index(comment, "*/")
+	if end < 0 {
+		return comment, nil, nil
+	}
+	t, err := Parse(comment[len(commentMarker):end])
+	if err != nil {
+		return "", nil, err
+	}
+	return comment[end+2:], &t, nil
+}
+
+// ttl scans a TTL, with an optional leading =.
+func (s *scanner) ttl() (TTL, error) {
+	var t TTL
+	var err error
+	if s.Peek() == '=' {
+		s.Pos++
+	}
+	if t.Column, err = s.Ident(); err != nil {
+		return TTL{}, err
+	}
+	if s.Peek() != '+' {
+		return TTL{}, s.Errorf("expected +")
+	}
+	s.Pos++
+	if !s.Keywords("interval") {
+		return TTL{}, s.Errorf("expected INTERVAL")
+	}
+	n := s.Word()
+	if t.Interval, err = strconv.ParseInt(n, 10, 64); err != nil || t.Interval <= 0 {
+		return TTL{}, fmt.Errorf("invalid TTL interval: %s", n)
+	}
+	unit := strings.ToUpper(s.Word())
+	for _, u := range Units {
+		if unit == u {
+			t.Unit = u
+			return t, nil
+		}
+	}
+	return TTL{}, fmt.Errorf("invalid TTL unit: %s", unit)
+}
+
+// scanner scans the tokens of a statement.
+type scanner struct {
+	*sqlscan.Scanner
+}
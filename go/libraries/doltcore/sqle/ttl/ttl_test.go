@@ -0,0 +1,195 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ttl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected TTL
+		err      bool
+	}{
+		{
+			spec:     "created_at + INTERVAL 30 DAY",
+			expected: TTL{Column: "created_at", Interval: 30, Unit: "DAY"},
+		},
+		{
+			spec:     "= `created at` + interval 1 hour",
+			expected: TTL{Column: "created at", Interval: 1, Unit: "HOUR"},
+		},
+		{
+			spec: "created_at + INTERVAL 30 FORTNIGHT",
+			err:  true,
+		},
+		{
+			spec: "created_at + INTERVAL 0 DAY",
+			err:  true,
+		},
+		{
+			spec: "created_at - INTERVAL 1 DAY",
+			err:  true,
+		},
+		{
+			spec: "created_at + INTERVAL 1 DAY AND MORE",
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			actual, err := Parse(tt.spec)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+
+			roundTripped, err := Parse(actual.String())
+			require.NoError(t, err)
+			assert.Equal(t, actual, roundTripped)
+		})
+	}
+}
+
+func TestParseAlter(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *Alter
+		end      int
+		err      bool
+	}{
+		{
+			query: "alter table t add column c int",
+		},
+		{
+			query: "alter table db.t ttl = c + interval 1 day",
+		},
+		{
+			query:    "ALTER TABLE t TTL = c + INTERVAL 1 DAY; select 1",
+			expected: &Alter{Table: "t", TTL: &TTL{Column: "c", Interval: 1, Unit: "DAY"}},
+			end:      39,
+		},
+		{
+			query:    "alter table `t` remove ttl",
+			expected: &Alter{Table: "t"},
+			end:      26,
+		},
+		{
+			query: "alter table t ttl = c + interval 1 day, add column d int",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			actual, end, err := ParseAlter(tt.query)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestAlterCall(t *testing.T) {
+	a := &Alter{Table: "t", TTL: &TTL{Column: "c", Interval: 7, Unit: "DAY"}}
+	assert.Equal(t, "call dolt_set_ttl('t', '`c` + INTERVAL 7 DAY')", sqlparser.String(a.Call()))
+	a = &Alter{Table: "t"}
+	assert.Equal(t, "call dolt_set_ttl('t')", sqlparser.String(a.Call()))
+}
+
+func TestPrepare(t *testing.T) {
+	tests := []struct {
+		query    string
+		prepared string
+		expected *TTL
+		err      bool
+	}{
+		{
+			query:    "select ttl from t",
+			prepared: "select ttl from t",
+		},
+		{
+			query:    "create table t (ttl int) as select ttl from s",
+			prepared: "create table t (ttl int) as select ttl from s",
+		},
+		{
+			query:    "create table t (id int primary key, c datetime) ttl = c + interval 1 day comment 'ttl'",
+			prepared: "create table t (id int primary key, c datetime) " + strings.Repeat(" ", 24) + " comment 'ttl'",
+			expected: &TTL{Column: "c", Interval: 1, Unit: "DAY"},
+		},
+		{
+			query:    "create table t (c datetime); create table s (c datetime) ttl c + interval 1 day",
+			prepared: "create table t (c datetime); create table s (c datetime) ttl c + interval 1 day",
+		},
+		{
+			query: "create table t (c datetime) ttl = c",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			prepared, actual, err := Prepare(tt.query)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.prepared, prepared)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	ttl := TTL{Column: "c", Interval: 1, Unit: "DAY"}
+	for _, comment := range []string{"", "sessions"} {
+		query := "create table t (c datetime)"
+		if comment != "" {
+			query += " comment '" + comment + "'"
+		}
+		stmt, err := sqlparser.Parse(query)
+		require.NoError(t, err)
+		require.NoError(t, Mark(stmt, ttl))
+
+		var marked string
+		for _, opt := range stmt.(*sqlparser.DDL).TableSpec.TableOpts {
+			marked = opt.Value
+		}
+		split, actual, err := SplitComment(marked)
+		require.NoError(t, err)
+		assert.Equal(t, comment, split)
+		assert.Equal(t, &ttl, actual)
+	}
+
+	stmt, err := sqlparser.Parse("create table t like s")
+	require.NoError(t, err)
+	assert.Error(t, Mark(stmt, ttl))
+
+	split, actual, err := SplitComment("/* not a ttl */")
+	require.NoError(t, err)
+	assert.Equal(t, "/* not a ttl */", split)
+	assert.Nil(t, actual)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb/durable"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/index"
 	"github.com/dolthub/dolt/go/store/prolly"
 	"github.com/dolthub/dolt/go/store/prolly/tree"
 	"github.com/dolthub/dolt/go/store/val"
@@ -265,6 +266,15 @@ type prollySecondaryIndexWriter struct {
 	pkMap val.OrdinalMapping
 	// pkBld builds key tuples for primary key index
 	pkBld *val.TupleBuilder
+
+	// predicate is the expression rows must satisfy
+	// to be in a partial index, or nil
+	predicate sql.Expression
+
+	// exprs are the expressions of key fields which are
+	// expressions, and exprTypes their types, see keyField
+	exprs     []sql.Expression
+	exprTypes []sql.Type
 }
 
 var _ indexWriter = prollySecondaryIndexWriter{}
@@ -279,6 +289,9 @@ func (m prollySecondaryIndexWriter) Map(ctx context.Context) (prolly.Map, error)
 
 func (m prollySecondaryIndexWriter) ValidateKeyViolations(ctx context.Context, sqlRow sql.Row) error {
 	if m.unique {
+		if ok, err := includesRow(ctx, m.predicate, sqlRow); err != nil || !ok {
+			return err
+		}
 		if err := m.checkForUniqueKeyErr(ctx, sqlRow); err != nil {
 			return err
 		}
@@ -297,8 +310,11 @@ func (m prollySecondaryIndexWriter) trimKeyPart(to int, keyPart interface{}) int
 
 func (m prollySecondaryIndexWriter) keyFromRow(ctx context.Context, sqlRow sql.Row) (val.Tuple, error) {
 	for to := range m.keyMap {
-		from := m.keyMap.MapOrdinal(to)
-		keyPart := m.trimKeyPart(to, sqlRow[from])
+		value, err := keyField(ctx, m.keyMap, m.exprs, m.exprTypes, to, sqlRow)
+		if err != nil {
+			return nil, err
+		}
+		keyPart := m.trimKeyPart(to, value)
 		if err := tree.PutField(ctx, m.mut.NodeStore(), m.keyBld, to, keyPart); err != nil {
 			return nil, err
 		}
@@ -307,6 +323,9 @@ func (m prollySecondaryIndexWriter) keyFromRow(ctx context.Context, sqlRow sql.R
 }
 
 func (m prollySecondaryIndexWriter) Insert(ctx context.Context, sqlRow sql.Row) error {
+	if ok, err := includesRow(ctx, m.predicate, sqlRow); err != nil || !ok {
+		return err
+	}
 	k, err := m.keyFromRow(ctx, sqlRow)
 	if err != nil {
 		return err
@@ -317,14 +336,17 @@ func (m prollySecondaryIndexWriter) Insert(ctx context.Context, sqlRow sql.Row)
 func (m prollySecondaryIndexWriter) checkForUniqueKeyErr(ctx context.Context, sqlRow sql.Row) error {
 	ns := m.mut.NodeStore()
 	for to := range m.keyMap[:m.idxCols] {
-		from := m.keyMap.MapOrdinal(to)
-		if sqlRow[from] == nil {
+		value, err := keyField(ctx, m.keyMap, m.exprs, m.exprTypes, to, sqlRow)
+		if err != nil {
+			return err
+		}
+		if value == nil {
 			// NULL is incomparable and cannot
 			// trigger a UNIQUE KEY violation
 			m.keyBld.Recycle()
 			return nil
 		}
-		keyPart := m.trimKeyPart(to, sqlRow[from])
+		keyPart := m.trimKeyPart(to, value)
 		if err := tree.PutField(ctx, ns, m.keyBld, to, keyPart); err != nil {
 			return err
 		}
@@ -361,6 +383,9 @@ func (m prollySecondaryIndexWriter) checkForUniqueKeyErr(ctx context.Context, sq
 }
 
 func (m prollySecondaryIndexWriter) Delete(ctx context.Context, sqlRow sql.Row) error {
+	if ok, err := includesRow(ctx, m.predicate, sqlRow); err != nil || !ok {
+		return err
+	}
 	k := m.keyBld.Build(sharePool)
 	k, err := m.keyFromRow(ctx, sqlRow)
 	if err != nil {
@@ -370,6 +395,17 @@ func (m prollySecondaryIndexWriter) Delete(ctx context.Context, sqlRow sql.Row)
 }
 
 func (m prollySecondaryIndexWriter) Update(ctx context.Context, oldRow sql.Row, newRow sql.Row) error {
+	if m.predicate != nil {
+		// a row of a partial index can move in or out of the index
+		if err := m.Delete(ctx, oldRow); err != nil {
+			return err
+		}
+		if err := m.ValidateKeyViolations(ctx, newRow); err != nil {
+			return err
+		}
+		return m.Insert(ctx, newRow)
+	}
+
 	oldKey, err := m.keyFromRow(ctx, oldRow)
 	if err != nil {
 		return err
@@ -411,6 +447,33 @@ func (m prollySecondaryIndexWriter) IterRange(ctx context.Context, rng prolly.Ra
 	return m.mut.IterRange(ctx, rng)
 }
 
+// includesRow returns whether |sqlRow| belongs in a partial index with |predicate|. Every row belongs in an index
+// without a predicate.
+func includesRow(ctx context.Context, predicate sql.Expression, sqlRow sql.Row) (bool, error) {
+	if predicate == nil {
+		return true, nil
+	}
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+	return index.EvalIndexPredicate(sqlCtx, predicate, sqlRow)
+}
+
+// keyField returns the key field |to| of |sqlRow| in a secondary index, which is the value of the column of |sqlRow|
+// mapped to it by |keyMap|, or of the expression |exprs[to]| for the key fields which are expressions. |exprTypes|
+// are the types of the values of |exprs|. |exprs| is nil for indexes without expressions.
+func keyField(ctx context.Context, keyMap val.OrdinalMapping, exprs []sql.Expression, exprTypes []sql.Type, to int, sqlRow sql.Row) (interface{}, error) {
+	if exprs == nil || exprs[to] == nil {
+		return sqlRow[keyMap.MapOrdinal(to)], nil
+	}
+	sqlCtx, ok := ctx.(*sql.Context)
+	if !ok {
+		sqlCtx = sql.NewContext(ctx)
+	}
+	return index.EvalIndexExpression(sqlCtx, exprs[to], exprTypes[to], sqlRow)
+}
+
 // FormatKeyForUniqKeyErr formats the given tuple |key| using |d|. The resulting
 // string is suitable for use in a sql.UniqueKeyError
 func FormatKeyForUniqKeyErr(key val.Tuple, d val.TupleDesc) string {
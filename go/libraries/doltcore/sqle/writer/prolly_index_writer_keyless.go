@@ -192,6 +192,15 @@ type prollyKeylessSecondaryWriter struct {
 	prefixBld *val.TupleBuilder
 	hashBld   *val.TupleBuilder
 	keyMap    val.OrdinalMapping
+
+	// predicate is the expression rows must satisfy
+	// to be in a partial index, or nil
+	predicate sql.Expression
+
+	// exprs are the expressions of key fields which are
+	// expressions, and exprTypes their types, see keyField
+	exprs     []sql.Expression
+	exprTypes []sql.Type
 }
 
 var _ indexWriter = prollyKeylessSecondaryWriter{}
@@ -236,9 +245,15 @@ func (writer prollyKeylessSecondaryWriter) trimKeyPart(to int, keyPart interface
 
 // Insert implements the interface indexWriter.
 func (writer prollyKeylessSecondaryWriter) Insert(ctx context.Context, sqlRow sql.Row) error {
+	if ok, err := includesRow(ctx, writer.predicate, sqlRow); err != nil || !ok {
+		return err
+	}
 	for to := range writer.keyMap {
-		from := writer.keyMap.MapOrdinal(to)
-		keyPart := writer.trimKeyPart(to, sqlRow[from])
+		value, err := keyField(ctx, writer.keyMap, writer.exprs, writer.exprTypes, to, sqlRow)
+		if err != nil {
+			return err
+		}
+		keyPart := writer.trimKeyPart(to, value)
 		if err := tree.PutField(ctx, writer.mut.NodeStore(), writer.keyBld, to, keyPart); err != nil {
 			return err
 		}
@@ -296,6 +311,9 @@ func (writer prollyKeylessSecondaryWriter) checkForUniqueKeyError(ctx context.Co
 
 // Delete implements the interface indexWriter.
 func (writer prollyKeylessSecondaryWriter) Delete(ctx context.Context, sqlRow sql.Row) error {
+	if ok, err := includesRow(ctx, writer.predicate, sqlRow); err != nil || !ok {
+		return err
+	}
 	hashId, cardRow, err := writer.primary.tuplesFromRow(ctx, sqlRow)
 	if err != nil {
 		return err
@@ -311,8 +329,11 @@ func (writer prollyKeylessSecondaryWriter) Delete(ctx context.Context, sqlRow sq
 	}
 
 	for to := range writer.keyMap {
-		from := writer.keyMap.MapOrdinal(to)
-		keyPart := writer.trimKeyPart(to, sqlRow[from])
+		value, err := keyField(ctx, writer.keyMap, writer.exprs, writer.exprTypes, to, sqlRow)
+		if err != nil {
+			return err
+		}
+		keyPart := writer.trimKeyPart(to, value)
 		if err := tree.PutField(ctx, writer.mut.NodeStore(), writer.keyBld, to, keyPart); err != nil {
 			return err
 		}
@@ -92,6 +92,9 @@ func getSecondaryProllyIndexWriters(ctx context.Context, t *doltdb.Table, schSta
 			keyBld:        val.NewTupleBuilder(keyDesc),
 			pkMap:         def.PkMapping,
 			pkBld:         val.NewTupleBuilder(schState.PkKeyDesc),
+			predicate:     def.Predicate,
+			exprs:         def.Expressions,
+			exprTypes:     def.ExpressionTypes,
 		}
 	}
 
@@ -131,6 +134,9 @@ func getSecondaryKeylessProllyWriters(ctx context.Context, t *doltdb.Table, schS
 			prefixBld:     val.NewTupleBuilder(keyDesc.PrefixDesc(def.Count)),
 			hashBld:       val.NewTupleBuilder(val.NewTupleDescriptor(val.Type{Enc: val.Hash128Enc})),
 			keyMap:        def.KeyMapping,
+			predicate:     def.Predicate,
+			exprs:         def.Expressions,
+			exprTypes:     def.ExpressionTypes,
 		}
 	}
 
@@ -139,6 +145,9 @@ func getSecondaryKeylessProllyWriters(ctx context.Context, t *doltdb.Table, schS
 
 // Insert implements TableWriter.
 func (w *prollyTableWriter) Insert(ctx *sql.Context, sqlRow sql.Row) (err error) {
+	if err = dsess.CountRowWritten(ctx); err != nil {
+		return err
+	}
 	if err = w.primary.ValidateKeyViolations(ctx, sqlRow); err != nil {
 		return err
 	}
@@ -170,6 +179,9 @@ func (w *prollyTableWriter) Insert(ctx *sql.Context, sqlRow sql.Row) (err error)
 
 // Delete implements TableWriter.
 func (w *prollyTableWriter) Delete(ctx *sql.Context, sqlRow sql.Row) (err error) {
+	if err = dsess.CountRowWritten(ctx); err != nil {
+		return err
+	}
 	for _, wr := range w.secondary {
 		if err := wr.Delete(ctx, sqlRow); err != nil {
 			return err
@@ -183,6 +195,9 @@ func (w *prollyTableWriter) Delete(ctx *sql.Context, sqlRow sql.Row) (err error)
 
 // Update implements TableWriter.
 func (w *prollyTableWriter) Update(ctx *sql.Context, oldRow sql.Row, newRow sql.Row) (err error) {
+	if err = dsess.CountRowWritten(ctx); err != nil {
+		return err
+	}
 	for _, wr := range w.secondary {
 		if err := wr.Update(ctx, oldRow, newRow); err != nil {
 			if uke, ok := err.(secondaryUniqueKeyError); ok {
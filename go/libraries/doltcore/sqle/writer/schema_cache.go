@@ -22,6 +22,7 @@ import (
 	"github.com/dolthub/dolt/go/libraries/doltcore/doltdb"
 	"github.com/dolthub/dolt/go/libraries/doltcore/schema"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/dsess"
+	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/expranalysis"
 	"github.com/dolthub/dolt/go/libraries/doltcore/sqle/sqlutil"
 	"github.com/dolthub/dolt/go/store/val"
 )
@@ -93,6 +94,27 @@ func newWriterSchema(ctx *sql.Context, t *doltdb.Table, tableName string, dbName
 			IsSpatial:     def.IsSpatial(),
 			PrefixLengths: def.PrefixLengths(),
 		}
+		if def.Predicate() != "" {
+			idxState.Predicate, err = expranalysis.ResolvePredicateExpression(ctx, tableName, schState.DoltSchema, def.Predicate())
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(def.Expressions()) > 0 {
+			idxState.Expressions = make([]sql.Expression, len(def.AllTags()))
+			idxState.ExpressionTypes = make([]sql.Type, len(def.AllTags()))
+			for i, tag := range def.AllTags() {
+				if !schema.IsExpressionTag(tag) {
+					continue
+				}
+				col, _ := def.GetColumn(tag)
+				idxState.Expressions[i], err = expranalysis.ResolveIndexExpression(ctx, tableName, schState.DoltSchema, col.Generated)
+				if err != nil {
+					return nil, err
+				}
+				idxState.ExpressionTypes[i] = col.TypeInfo.ToSqlType()
+			}
+		}
 		schState.SecIndexes = append(schState.SecIndexes, idxState)
 	}
 	return schState, nil
@@ -84,6 +84,12 @@ func BuildProllyIndexExternal(
 			return nil, err
 		}
 
+		if ok, err := secondaryBld.IncludesRow(ctx, k, v); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
 		idxKey, err := secondaryBld.SecondaryKeyFromRow(ctx, k, v)
 		if err != nil {
 			return nil, err
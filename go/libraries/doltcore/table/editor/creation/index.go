@@ -59,7 +59,15 @@ func CreateIndex(
 	// get the real column names as CREATE INDEX columns are case-insensitive
 	var realColNames []string
 	allTableCols := sch.GetAllCols()
+	expressions := make(map[string]struct{}, len(props.Expressions))
+	for _, expr := range props.Expressions {
+		expressions[expr.Name] = struct{}{}
+	}
 	for _, indexCol := range columns {
+		if _, ok := expressions[indexCol]; ok {
+			realColNames = append(realColNames, indexCol)
+			continue
+		}
 		tableCol, ok := allTableCols.GetByNameCaseInsensitive(indexCol)
 		if !ok {
 			return nil, fmt.Errorf("column `%s` does not exist for the table", indexCol)
@@ -81,9 +89,10 @@ func CreateIndex(
 		return nil, fmt.Errorf("invalid index name `%s`", indexName)
 	}
 
-	// if an index was already created for the column set but was not generated by the user then we replace it
+	// if an index was already created for the column set but was not generated by the user then we replace it, unless
+	// the new index is a partial index, which cannot replace it as it doesn't hold every row
 	existingIndex, ok := sch.Indexes().GetIndexByColumnNames(realColNames...)
-	if ok && !existingIndex.IsUserDefined() {
+	if ok && !existingIndex.IsUserDefined() && props.Predicate == "" {
 		_, err = sch.Indexes().RemoveIndex(existingIndex.Name())
 		if err != nil {
 			return nil, err
@@ -244,6 +253,12 @@ func BuildUniqueProllyIndex(
 			return nil, err
 		}
 
+		if ok, err := secondaryBld.IncludesRow(ctx, k, v); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
 		idxKey, err := secondaryBld.SecondaryKeyFromRow(ctx, k, v)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,154 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+)
+
+const (
+	DebeziumConnector = "dolt"
+
+	debeziumOpCreate = "c"
+	debeziumOpUpdate = "u"
+	debeziumOpDelete = "d"
+)
+
+// DebeziumSource is the "source" block of a Debezium change event, describing where a change originated.
+type DebeziumSource struct {
+	Version   string `json:"version"`
+	Connector string `json:"connector"`
+	Name      string `json:"name"`
+	TsMs      int64  `json:"ts_ms"`
+	Snapshot  string `json:"snapshot"`
+	Db        string `json:"db"`
+	Table     string `json:"table"`
+	Commit    string `json:"commit,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	Committer string `json:"committer,omitempty"`
+	Email     string `json:"email,omitempty"`
+}
+
+// debeziumEvent is the payload of a Debezium change event, as emitted by Debezium connectors with schemas disabled.
+type debeziumEvent struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+	Source DebeziumSource  `json:"source"`
+	Op     string          `json:"op"`
+	TsMs   int64           `json:"ts_ms"`
+}
+
+// debeziumRowDiffWriter writes row diffs as newline delimited Debezium change events. Modified rows are emitted as a
+// single update event with both a before and after image.
+type debeziumRowDiffWriter struct {
+	wr      io.WriteCloser
+	enc     *json.Encoder
+	sch     sql.Schema
+	source  DebeziumSource
+	pending json.RawMessage
+}
+
+var _ diff.SqlRowDiffWriter = (*debeziumRowDiffWriter)(nil)
+
+// NewDebeziumRowDiffWriter returns a diff.SqlRowDiffWriter that writes Debezium change events for rows in |sch| to
+// |wr|. Every event carries |source| with its table field set to |tableName|.
+func NewDebeziumRowDiffWriter(wr io.WriteCloser, sch sql.Schema, tableName string, source DebeziumSource) *debeziumRowDiffWriter {
+	enc := json.NewEncoder(wr)
+	enc.SetEscapeHTML(false)
+
+	source.Table = tableName
+	if source.Connector == "" {
+		source.Connector = DebeziumConnector
+	}
+	if source.Snapshot == "" {
+		source.Snapshot = "false"
+	}
+
+	return &debeziumRowDiffWriter{
+		wr:     wr,
+		enc:    enc,
+		sch:    sch,
+		source: source,
+	}
+}
+
+func (d *debeziumRowDiffWriter) WriteRow(ctx context.Context, row sql.Row, rowDiffType diff.ChangeType, colDiffTypes []diff.ChangeType) error {
+	if len(row) != len(d.sch) {
+		return fmt.Errorf("expected %d columns for debezium row, got %d", len(d.sch), len(row))
+	}
+
+	image, err := d.rowImage(row)
+	if err != nil {
+		return err
+	}
+
+	switch rowDiffType {
+	case diff.Added:
+		return d.writeEvent(nil, image, debeziumOpCreate)
+	case diff.Removed:
+		return d.writeEvent(image, nil, debeziumOpDelete)
+	case diff.ModifiedOld:
+		d.pending = image
+		return nil
+	case diff.ModifiedNew:
+		if d.pending == nil {
+			return fmt.Errorf("debezium writer received a modified row without its previous value")
+		}
+		before := d.pending
+		d.pending = nil
+		return d.writeEvent(before, image, debeziumOpUpdate)
+	default:
+		return fmt.Errorf("unexpected diff type for debezium row: %v", rowDiffType)
+	}
+}
+
+func (d *debeziumRowDiffWriter) WriteCombinedRow(ctx context.Context, oldRow, newRow sql.Row, mode diff.Mode) error {
+	return fmt.Errorf("debezium format is unable to output diffs for combined rows")
+}
+
+func (d *debeziumRowDiffWriter) Close(ctx context.Context) error {
+	if d.pending != nil {
+		return fmt.Errorf("debezium writer closed with an unpaired modified row")
+	}
+	return d.wr.Close()
+}
+
+func (d *debeziumRowDiffWriter) rowImage(row sql.Row) (json.RawMessage, error) {
+	vals, err := jsonValuesForSqlSchema(d.sch, row, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.MarshallJsonValue(vals)
+}
+
+func (d *debeziumRowDiffWriter) writeEvent(before, after json.RawMessage, op string) error {
+	return d.enc.Encode(debeziumEvent{
+		Before: before,
+		After:  after,
+		Source: d.source,
+		Op:     op,
+		TsMs:   time.Now().UnixMilli(),
+	})
+}
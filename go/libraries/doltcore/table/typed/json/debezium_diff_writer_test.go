@@ -0,0 +1,91 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/dolt/go/libraries/doltcore/diff"
+	"github.com/dolthub/dolt/go/libraries/utils/iohelp"
+)
+
+func TestDebeziumRowDiffWriter(t *testing.T) {
+	ctx := context.Background()
+	sch := sql.Schema{
+		&sql.Column{Name: "id", Type: types.Int64, PrimaryKey: true},
+		&sql.Column{Name: "name", Type: types.Text, Nullable: true},
+	}
+
+	buf := &bytes.Buffer{}
+	source := DebeziumSource{Name: "mydb", Db: "mydb", Commit: "abc123", TsMs: 1000}
+	wr := NewDebeziumRowDiffWriter(iohelp.NopWrCloser(buf), sch, "people", source)
+
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(1), "bill"}, diff.Added, nil))
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(2), "jane"}, diff.ModifiedOld, nil))
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(2), nil}, diff.ModifiedNew, nil))
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(3), "bob"}, diff.Removed, nil))
+	require.NoError(t, wr.Close(ctx))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var events []map[string]interface{}
+	for _, line := range lines {
+		var ev map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &ev))
+		events = append(events, ev)
+	}
+
+	assert.Equal(t, "c", events[0]["op"])
+	assert.Nil(t, events[0]["before"])
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "name": "bill"}, events[0]["after"])
+
+	assert.Equal(t, "u", events[1]["op"])
+	assert.Equal(t, map[string]interface{}{"id": float64(2), "name": "jane"}, events[1]["before"])
+	assert.Equal(t, map[string]interface{}{"id": float64(2), "name": nil}, events[1]["after"])
+
+	assert.Equal(t, "d", events[2]["op"])
+	assert.Equal(t, map[string]interface{}{"id": float64(3), "name": "bob"}, events[2]["before"])
+	assert.Nil(t, events[2]["after"])
+
+	src := events[0]["source"].(map[string]interface{})
+	assert.Equal(t, "dolt", src["connector"])
+	assert.Equal(t, "people", src["table"])
+	assert.Equal(t, "mydb", src["db"])
+	assert.Equal(t, "abc123", src["commit"])
+	assert.Equal(t, "false", src["snapshot"])
+	assert.Equal(t, float64(1000), src["ts_ms"])
+}
+
+func TestDebeziumRowDiffWriterUnpairedUpdate(t *testing.T) {
+	ctx := context.Background()
+	sch := sql.Schema{&sql.Column{Name: "id", Type: types.Int64, PrimaryKey: true}}
+
+	wr := NewDebeziumRowDiffWriter(iohelp.NopWrCloser(&bytes.Buffer{}), sch, "t", DebeziumSource{})
+	assert.Error(t, wr.WriteRow(ctx, sql.Row{int64(1)}, diff.ModifiedNew, nil))
+
+	wr = NewDebeziumRowDiffWriter(iohelp.NopWrCloser(&bytes.Buffer{}), sch, "t", DebeziumSource{})
+	require.NoError(t, wr.WriteRow(ctx, sql.Row{int64(1)}, diff.ModifiedOld, nil))
+	assert.Error(t, wr.Close(ctx))
+}
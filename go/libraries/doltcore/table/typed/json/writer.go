@@ -192,10 +192,24 @@ func (j *RowWriter) jsonDataForSchema(row sql.Row) ([]byte, error) {
 
 // jsonDataForSqlSchema returns a JSON representation of the given row, using the sql schema for serialization hints
 func (j *RowWriter) jsonDataForSqlSchema(row sql.Row) ([]byte, error) {
-	colValMap := make(map[string]interface{}, len(j.sqlSch))
-	for i, col := range j.sqlSch {
+	colValMap, err := jsonValuesForSqlSchema(j.sqlSch, row, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.MarshallJsonValue(colValMap)
+}
+
+// jsonValuesForSqlSchema returns a map of column name to JSON-serializable value for the given row. NULL values are
+// omitted unless |includeNulls| is true.
+func jsonValuesForSqlSchema(sch sql.Schema, row sql.Row, includeNulls bool) (map[string]interface{}, error) {
+	colValMap := make(map[string]interface{}, len(sch))
+	for i, col := range sch {
 		val := row[i]
 		if val == nil {
+			if includeNulls {
+				colValMap[col.Name] = nil
+			}
 			continue
 		}
 
@@ -231,7 +245,7 @@ func (j *RowWriter) jsonDataForSqlSchema(row sql.Row) ([]byte, error) {
 		colValMap[col.Name] = val
 	}
 
-	return types.MarshallJsonValue(colValMap)
+	return colValMap, nil
 }
 
 func (j *RowWriter) Flush() error {
@@ -40,56 +40,86 @@ const maxSamples = 1000
 const minSamples = 25
 
 func BuildArchive(ctx context.Context, cs chunks.ChunkStore, dagGroups *ChunkRelations, progress chan interface{}) (err error) {
-	// Currently, we don't have any stats to report. Required for calls to the lower layers tho.
-	var stats Stats
-
 	if gs, ok := cs.(*GenerationalNBS); ok {
-		outPath, _ := gs.oldGen.Path()
-		oldgen := gs.oldGen.tables.upstream
+		//NM4 TODO: This code path must only be run on an offline database. We should add a check for that.
+		return archiveTableFiles(ctx, gs.oldGen, dagGroups, progress, false)
+	} else {
+		return errors.New("Modern DB Expected")
+	}
+}
 
-		swapMap := make(map[hash.Hash]hash.Hash)
+// ArchiveNewGen converts the table files of the newest generation of |cs| into archives, and removes the table files
+// it converted. Table files with too few chunks to build a compression dictionary are left as they are. The store must
+// not be in use by anything else.
+func ArchiveNewGen(ctx context.Context, cs chunks.ChunkStore, progress chan interface{}) error {
+	gs, ok := cs.(*GenerationalNBS)
+	if !ok {
+		return errors.New("Modern DB Expected")
+	}
+	relations := NewChunkRelations()
+	err := archiveTableFiles(ctx, gs.newGen, &relations, progress, true)
+	if err != nil {
+		return err
+	}
+	return gs.newGen.PruneTableFiles(ctx)
+}
 
-		for tf, ogcs := range oldgen {
-			// NM4 - We should probably provide a way to pick a particular table file to build an archive for.
+// archiveTableFiles converts the table files of |store| into archives, and swaps the archives into its manifest in
+// place of the table files. If |skipSmall| is set, table files with too few chunks to build a dictionary are skipped.
+func archiveTableFiles(ctx context.Context, store *NomsBlockStore, dagGroups *ChunkRelations, progress chan interface{}, skipSmall bool) error {
+	// Currently, we don't have any stats to report. Required for calls to the lower layers tho.
+	var stats Stats
 
-			idx, err := ogcs.index()
-			if err != nil {
-				return err
-			}
+	outPath, _ := store.Path()
+	tables := store.tables.upstream
 
-			archivePath := ""
-			archiveName := hash.Hash{}
-			archivePath, archiveName, err = convertTableFileToArchive(ctx, ogcs, idx, dagGroups, outPath, progress, &stats)
-			if err != nil {
-				return err
-			}
+	swapMap := make(map[hash.Hash]hash.Hash)
 
-			err = verifyAllChunks(idx, archivePath, progress)
-			if err != nil {
-				return err
-			}
+	for tf, ogcs := range tables {
+		// NM4 - We should probably provide a way to pick a particular table file to build an archive for.
+		if _, isArchive := ogcs.(archiveChunkSource); isArchive {
+			continue
+		}
 
-			swapMap[tf] = archiveName
+		idx, err := ogcs.index()
+		if err != nil {
+			return err
+		}
+		if skipSmall && idx.chunkCount() < minSamples {
+			continue
 		}
 
-		//NM4 TODO: This code path must only be run on an offline database. We should add a check for that.
-		specs, err := gs.oldGen.tables.toSpecs()
-		newSpecs := make([]tableSpec, 0, len(specs))
-		for _, spec := range specs {
-			if newSpec, exists := swapMap[spec.name]; exists {
-				newSpecs = append(newSpecs, tableSpec{newSpec, spec.chunkCount})
-			} else {
-				newSpecs = append(newSpecs, spec)
-			}
+		archivePath := ""
+		archiveName := hash.Hash{}
+		archivePath, archiveName, err = convertTableFileToArchive(ctx, ogcs, idx, dagGroups, outPath, progress, &stats)
+		if err != nil {
+			return err
 		}
-		err = gs.oldGen.swapTables(ctx, newSpecs)
+
+		err = verifyAllChunks(idx, archivePath, progress)
 		if err != nil {
 			return err
 		}
-	} else {
-		return errors.New("Modern DB Expected")
+
+		swapMap[tf] = archiveName
 	}
-	return nil
+	if len(swapMap) == 0 {
+		return nil
+	}
+
+	specs, err := store.tables.toSpecs()
+	if err != nil {
+		return err
+	}
+	newSpecs := make([]tableSpec, 0, len(specs))
+	for _, spec := range specs {
+		if newSpec, exists := swapMap[spec.name]; exists {
+			newSpecs = append(newSpecs, tableSpec{newSpec, spec.chunkCount})
+		} else {
+			newSpecs = append(newSpecs, spec)
+		}
+	}
+	return store.swapTables(ctx, newSpecs)
 }
 
 func convertTableFileToArchive(
@@ -127,6 +127,24 @@ func (acs archiveChunkSource) getRecordRanges(_ context.Context, _ []getRecord)
 	return nil, errors.New("Archive chunk source does not support getRecordRanges")
 }
 
+// getManyCompressed returns the chunks of |reqs| compressed as they are in table files. Archives compress chunks
+// differently, so each chunk is decompressed and compressed again.
 func (acs archiveChunkSource) getManyCompressed(ctx context.Context, eg *errgroup.Group, reqs []getRecord, found func(context.Context, CompressedChunk), stats *Stats) (bool, error) {
-	return false, errors.New("Archive chunk source does not support getManyCompressed")
+	// single threaded first pass.
+	foundAll := true
+	for i, req := range reqs {
+		if req.found {
+			continue
+		}
+		data, err := acs.aRdr.get(*req.a)
+		if err != nil {
+			return true, err
+		} else if data == nil {
+			foundAll = false
+		} else {
+			found(ctx, ChunkToCompressedChunk(chunks.NewChunkWithHash(*req.a, data)))
+			reqs[i].found = true
+		}
+	}
+	return !foundAll, nil
 }
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/dolthub/dolt/go/store/chunks"
 	"github.com/dolthub/dolt/go/store/hash"
@@ -34,6 +35,11 @@ type GenerationalNBS struct {
 	oldGen   *NomsBlockStore
 	newGen   *NomsBlockStore
 	ghostGen *GhostBlockStore
+
+	// lazy, if set, supplies chunks which are absent from every generation because this store is a partial clone.
+	// Chunks read from it are written to newGen and persisted when the store is committed or closed.
+	lazy        atomic.Pointer[LazyChunkSource]
+	lazyFetched atomic.Bool
 }
 
 func (gcs *GenerationalNBS) PersistGhostHashes(ctx context.Context, refs hash.HashSet) error {
@@ -89,6 +95,15 @@ func (gcs *GenerationalNBS) Get(ctx context.Context, h hash.Hash) (chunks.Chunk,
 		}
 	}
 
+	if lazy := gcs.lazy.Load(); c.IsEmpty() && lazy != nil {
+		err = gcs.getLazily(ctx, lazy, hash.NewHashSet(h), func(_ context.Context, chunk *chunks.Chunk) {
+			c = *chunk
+		})
+		if err != nil {
+			return chunks.EmptyChunk, err
+		}
+	}
+
 	return c, nil
 }
 
@@ -131,10 +146,26 @@ func (gcs *GenerationalNBS) GetMany(ctx context.Context, hashes hash.HashSet, fo
 
 	// Last ditch effort to see if the requested objects are commits we've decided to ignore. Note the function spec
 	// considers non-present chunks to be silently ignored, so we don't need to return an error here
-	if gcs.ghostGen == nil {
+	if gcs.ghostGen != nil {
+		err = gcs.ghostGen.GetMany(ctx, notFound, func(ctx context.Context, chunk *chunks.Chunk) {
+			func() {
+				mu.Lock()
+				defer mu.Unlock()
+				delete(notFound, chunk.Hash())
+			}()
+
+			found(ctx, chunk)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	lazy := gcs.lazy.Load()
+	if len(notFound) == 0 || lazy == nil {
 		return nil
 	}
-	return gcs.ghostGen.GetMany(ctx, notFound, found)
+	return gcs.getLazily(ctx, lazy, notFound, found)
 }
 
 func (gcs *GenerationalNBS) GetManyCompressed(ctx context.Context, hashes hash.HashSet, found func(context.Context, CompressedChunk)) error {
@@ -158,7 +189,74 @@ func (gcs *GenerationalNBS) GetManyCompressed(ctx context.Context, hashes hash.H
 		return nil
 	}
 
-	return gcs.newGen.GetManyCompressed(ctx, notInOldGen, found)
+	lazy := gcs.lazy.Load()
+	if lazy == nil {
+		return gcs.newGen.GetManyCompressed(ctx, notInOldGen, found)
+	}
+
+	notFound := notInOldGen.Copy()
+	err = gcs.newGen.GetManyCompressed(ctx, notInOldGen, func(ctx context.Context, chunk CompressedChunk) {
+		func() {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(notFound, chunk.Hash())
+		}()
+
+		found(ctx, chunk)
+	})
+	if err != nil {
+		return err
+	}
+	if len(notFound) == 0 {
+		return nil
+	}
+
+	return gcs.getLazily(ctx, lazy, notFound, func(ctx context.Context, chunk *chunks.Chunk) {
+		found(ctx, ChunkToCompressedChunk(*chunk))
+	})
+}
+
+// SetLazySource makes this store a partial clone which reads the chunks it doesn't have from |lazy|. A nil |lazy|
+// makes it a complete store again, and should only be set once every chunk it references has been fetched. Any lazy
+// source this replaces is closed.
+func (gcs *GenerationalNBS) SetLazySource(lazy *LazyChunkSource) error {
+	if prev := gcs.lazy.Swap(lazy); prev != nil && prev != lazy {
+		return prev.Close()
+	}
+	return nil
+}
+
+// HasLazySource returns whether this store is a partial clone which may be missing chunks it references.
+func (gcs *GenerationalNBS) HasLazySource() bool {
+	return gcs.lazy.Load() != nil
+}
+
+// getLazily reads |hashes| from |lazy|, adding any that are found to the new gen store.
+func (gcs *GenerationalNBS) getLazily(ctx context.Context, lazy *LazyChunkSource, hashes hash.HashSet, found func(context.Context, *chunks.Chunk)) error {
+	mu := &sync.Mutex{}
+	var putErr error
+	err := lazy.getMany(ctx, hashes, func(ctx context.Context, chunk *chunks.Chunk) {
+		func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if putErr == nil {
+				putErr = gcs.newGen.putChunk(ctx, *chunk, func(c chunks.Chunk) chunks.GetAddrsCb {
+					return func(ctx context.Context, addrs hash.HashSet, _ chunks.PendingRefExists) error { return nil }
+				}, gcs.hasManyOrLazy(ctx))
+			}
+		}()
+
+		found(ctx, chunk)
+	})
+	if err != nil {
+		return err
+	}
+	if putErr != nil {
+		return putErr
+	}
+
+	gcs.lazyFetched.Store(true)
+	return nil
 }
 
 // Has returns true iff the value at the address |h| is contained in the store
@@ -214,12 +312,30 @@ func (gcs *GenerationalNBS) hasMany(recs []hasRecord) (absent hash.HashSet, err
 	return gcs.ghostGen.hasMany(absent)
 }
 
+// hasManyOrLazy is the reference check for writes to this store. In a partial clone, a chunk may refer to chunks
+// which were never fetched, so references absent from every generation are checked against the lazy source. HasMany
+// doesn't do the same, so that pulls still fetch the chunks they need. Lazy source reads use |ctx|.
+func (gcs *GenerationalNBS) hasManyOrLazy(ctx context.Context) refCheck {
+	return func(recs []hasRecord) (hash.HashSet, error) {
+		absent, err := gcs.hasMany(recs)
+		if err != nil || len(absent) == 0 {
+			return absent, err
+		}
+
+		lazy := gcs.lazy.Load()
+		if lazy == nil {
+			return absent, nil
+		}
+		return lazy.hasMany(ctx, absent)
+	}
+}
+
 // Put caches c in the ChunkSource. Upon return, c must be visible to
 // subsequent Get and Has calls, but must not be persistent until a call
 // to Flush(). Put may be called concurrently with other calls to Put(),
 // Get(), GetMany(), Has() and HasMany().
 func (gcs *GenerationalNBS) Put(ctx context.Context, c chunks.Chunk, getAddrs chunks.GetAddrsCurry) error {
-	return gcs.newGen.putChunk(ctx, c, getAddrs, gcs.hasMany)
+	return gcs.newGen.putChunk(ctx, c, getAddrs, gcs.hasManyOrLazy(ctx))
 }
 
 // Returns the NomsBinFormat with which this ChunkSource is compatible.
@@ -259,7 +375,7 @@ func (gcs *GenerationalNBS) Root(ctx context.Context) (hash.Hash, error) {
 // persisted root hash from last to current (or keeps it the same).
 // If last doesn't match the root in persistent storage, returns false.
 func (gcs *GenerationalNBS) Commit(ctx context.Context, current, last hash.Hash) (bool, error) {
-	return gcs.newGen.commit(ctx, current, last, gcs.hasMany)
+	return gcs.newGen.commit(ctx, current, last, gcs.hasManyOrLazy(ctx))
 }
 
 // Stats may return some kind of struct that reports statistics about the
@@ -286,6 +402,15 @@ func (gcs *GenerationalNBS) StatsSummary() string {
 // Close() concurrently with any other ChunkStore method; behavior is
 // undefined and probably crashy.
 func (gcs *GenerationalNBS) Close() error {
+	if lazy := gcs.lazy.Load(); lazy != nil {
+		if err := gcs.persistLazyChunks(); err != nil {
+			return err
+		}
+		if err := lazy.Close(); err != nil {
+			return err
+		}
+	}
+
 	oErr := gcs.oldGen.Close()
 	nErr := gcs.newGen.Close()
 
@@ -296,6 +421,22 @@ func (gcs *GenerationalNBS) Close() error {
 	return nErr
 }
 
+// persistLazyChunks writes the chunks read from the lazy source to disk, so they needn't be fetched again
+func (gcs *GenerationalNBS) persistLazyChunks() error {
+	if !gcs.lazyFetched.Load() {
+		return nil
+	}
+
+	ctx := context.Background()
+	root, err := gcs.newGen.Root(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = gcs.newGen.commit(ctx, root, root, gcs.hasManyOrLazy(ctx))
+	return err
+}
+
 func (gcs *GenerationalNBS) copyToOldGen(ctx context.Context, hashes hash.HashSet) error {
 	notInOldGen, err := gcs.oldGen.HasMany(ctx, hashes)
 
@@ -393,7 +534,7 @@ func (gcs *GenerationalNBS) PruneTableFiles(ctx context.Context) error {
 
 // SetRootChunk changes the root chunk hash from the previous value to the new root for the newgen cs
 func (gcs *GenerationalNBS) SetRootChunk(ctx context.Context, root, previous hash.Hash) error {
-	return gcs.newGen.setRootChunk(ctx, root, previous, gcs.hasMany)
+	return gcs.newGen.setRootChunk(ctx, root, previous, gcs.hasManyOrLazy(ctx))
 }
 
 // SupportedOperations returns a description of the support TableFile operations. Some stores only support reading table files, not writing.
@@ -171,3 +171,71 @@ func TestGenerationalCS(t *testing.T) {
 	putChunks(t, ctx, chnks, cs, inNew, 15, 16, 17, 18, 19)
 	requireChunks(t, ctx, chnks, cs, inOld, inNew)
 }
+
+func TestGenerationalCSLazySource(t *testing.T) {
+	ctx := context.Background()
+	origin, _, _ := makeTestLocalStore(t, 64)
+	oldGen, _, _ := makeTestLocalStore(t, 64)
+	newGen, _, _ := makeTestLocalStore(t, 64)
+	inOrigin := make(map[int]bool)
+	chnks := genChunks(t, 10, 1000)
+
+	putChunks(t, ctx, chnks, origin, inOrigin, 0, 1, 2, 3, 4)
+
+	cs := NewGenerationalCS(oldGen, newGen, nil)
+	opened := 0
+	lazy := NewLazyChunkSource(func(ctx context.Context) (chunks.ChunkStore, error) {
+		opened++
+		return origin, nil
+	})
+	require.NoError(t, cs.SetLazySource(lazy))
+	require.True(t, cs.HasLazySource())
+
+	// chunks only the origin has are absent until they're read
+	absent, err := cs.HasMany(ctx, hashesForChunks(chnks, inOrigin))
+	require.NoError(t, err)
+	require.Len(t, absent, 5)
+
+	c, err := cs.Get(ctx, chnks[0].Hash())
+	require.NoError(t, err)
+	require.Equal(t, chnks[0].Hash(), c.Hash())
+	has, err := cs.newGen.Has(ctx, chnks[0].Hash())
+	require.NoError(t, err)
+	require.True(t, has)
+
+	received := foundHashes{}
+	err = cs.GetMany(ctx, hashesForChunks(chnks, map[int]bool{1: true, 2: true, 5: true}), received.found)
+	require.NoError(t, err)
+	require.Equal(t, hashesForChunks(chnks, map[int]bool{1: true, 2: true}), hash.HashSet(received))
+
+	compressed := hash.NewHashSet()
+	err = cs.GetManyCompressed(ctx, hashesForChunks(chnks, map[int]bool{3: true}), func(ctx context.Context, cc CompressedChunk) {
+		compressed.Insert(cc.Hash())
+	})
+	require.NoError(t, err)
+	require.True(t, compressed.Has(chnks[3].Hash()))
+	require.Equal(t, 1, opened)
+
+	// chunks may refer to chunks which only the origin has
+	referrer := chunks.NewChunk([]byte("refers to a chunk which was never fetched"))
+	err = cs.Put(ctx, referrer, func(c chunks.Chunk) chunks.GetAddrsCb {
+		return func(ctx context.Context, addrs hash.HashSet, _ chunks.PendingRefExists) error {
+			addrs.Insert(chnks[4].Hash())
+			return nil
+		}
+	})
+	require.NoError(t, err)
+	root, err := cs.Root(ctx)
+	require.NoError(t, err)
+	ok, err := cs.Commit(ctx, referrer.Hash(), root)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	has, err = cs.Has(ctx, chnks[4].Hash())
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// closing the store closes the origin it opened
+	require.NoError(t, cs.Close())
+	require.Nil(t, lazy.origin)
+}
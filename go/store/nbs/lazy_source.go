@@ -0,0 +1,83 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dolthub/dolt/go/store/chunks"
+	"github.com/dolthub/dolt/go/store/hash"
+)
+
+// LazyChunkSource supplies the chunks that a partial clone left out of its local store. The origin store is opened
+// on first use, so a partial clone only contacts its remote when it reads data it doesn't have.
+type LazyChunkSource struct {
+	open func(ctx context.Context) (chunks.ChunkStore, error)
+
+	mu     sync.Mutex
+	origin chunks.ChunkStore
+}
+
+// NewLazyChunkSource returns a LazyChunkSource that reads missing chunks from the store returned by |open|.
+func NewLazyChunkSource(open func(ctx context.Context) (chunks.ChunkStore, error)) *LazyChunkSource {
+	return &LazyChunkSource{open: open}
+}
+
+func (l *LazyChunkSource) store(ctx context.Context) (chunks.ChunkStore, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.origin == nil {
+		origin, err := l.open(ctx)
+		if err != nil {
+			return nil, err
+		}
+		l.origin = origin
+	}
+
+	return l.origin, nil
+}
+
+func (l *LazyChunkSource) getMany(ctx context.Context, hashes hash.HashSet, found func(context.Context, *chunks.Chunk)) error {
+	origin, err := l.store(ctx)
+	if err != nil {
+		return err
+	}
+
+	return origin.GetMany(ctx, hashes, found)
+}
+
+func (l *LazyChunkSource) hasMany(ctx context.Context, hashes hash.HashSet) (hash.HashSet, error) {
+	origin, err := l.store(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return origin.HasMany(ctx, hashes)
+}
+
+// Close closes the origin store, if it was opened.
+func (l *LazyChunkSource) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.origin == nil {
+		return nil
+	}
+	err := l.origin.Close()
+	l.origin = nil
+	return err
+}
@@ -0,0 +1,163 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE people (
+  id INT PRIMARY KEY,
+  name VARCHAR(32)
+);
+INSERT INTO people VALUES (1, 'bill'), (2, 'jane');
+SQL
+    dolt commit -Am "create people"
+    dolt tag v1
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "bundle: clone from a bundle" {
+    dolt branch other
+    run dolt bundle create "$BATS_TEST_TMPDIR/people.bundle" main other v1
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Wrote 3 refs" ]] || false
+
+    cd "$BATS_TEST_TMPDIR"
+    run dolt clone people.bundle
+    [ "$status" -eq 0 ]
+
+    cd people
+    run dolt sql -q "select * from people order by id" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "1,bill" ]
+    [ "${lines[2]}" = "2,jane" ]
+
+    run dolt branch -a
+    [[ "$output" =~ "remotes/origin/other" ]] || false
+
+    run dolt tag
+    [[ "$output" =~ "v1" ]] || false
+}
+
+@test "bundle: bundles hold archives" {
+    for i in $(seq 3 40); do
+        dolt sql -q "INSERT INTO people VALUES ($i, 'person $i')"
+        dolt commit -qam "add person $i"
+    done
+    dolt bundle create "$BATS_TEST_TMPDIR/people.bundle" main
+
+    run tar -tf "$BATS_TEST_TMPDIR/people.bundle"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ ".darc" ]] || false
+
+    cd "$BATS_TEST_TMPDIR"
+    run dolt clone people.bundle
+    [ "$status" -eq 0 ]
+    cd people
+    run dolt sql -q "select count(*) from people" -r csv
+    [ "${lines[1]}" = "40" ]
+    run dolt log --oneline
+    [[ "$output" =~ "add person 40" ]] || false
+}
+
+@test "bundle: files without the bundle extension need the bundle scheme" {
+    dolt bundle create "$BATS_TEST_TMPDIR/people.bundle" main
+    cp "$BATS_TEST_TMPDIR/people.bundle" "$BATS_TEST_TMPDIR/people.dat"
+
+    cd "$BATS_TEST_TMPDIR"
+    run dolt clone people.dat plain
+    [ "$status" -ne 0 ]
+
+    run dolt clone "bundle://$BATS_TEST_TMPDIR/people.dat" people
+    [ "$status" -eq 0 ]
+    cd people
+    run dolt sql -q "select count(*) from people" -r csv
+    [ "${lines[1]}" = "2" ]
+}
+
+@test "bundle: fetch an incremental bundle" {
+    dolt bundle create "$BATS_TEST_TMPDIR/full.bundle" main
+    dolt clone "$BATS_TEST_TMPDIR/full.bundle" "$BATS_TEST_TMPDIR/copy"
+
+    dolt sql -q "INSERT INTO people VALUES (3, 'bob')"
+    dolt commit -am "add bob"
+    run dolt bundle create "$BATS_TEST_TMPDIR/changes.bundle" main --since v1
+    [ "$status" -eq 0 ]
+
+    # the incremental bundle leaves out the data it was built on
+    full_size=$(wc -c < "$BATS_TEST_TMPDIR/full.bundle")
+    changes_size=$(wc -c < "$BATS_TEST_TMPDIR/changes.bundle")
+    [ "$changes_size" -lt "$full_size" ]
+
+    run dolt clone "$BATS_TEST_TMPDIR/changes.bundle" "$BATS_TEST_TMPDIR/bad"
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "incremental bundle" ]] || false
+
+    cd "$BATS_TEST_TMPDIR/copy"
+    run dolt fetch ../changes.bundle
+    [ "$status" -eq 0 ]
+
+    run dolt log changes/main --oneline
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "add bob" ]] || false
+
+    dolt merge changes/main
+    run dolt sql -q "select name from people where id = 3" -r csv
+    [ "${lines[1]}" = "bob" ]
+}
+
+@test "bundle: incremental bundles leave out the history of their basis" {
+    dolt sql -q "CREATE TABLE notes (id INT PRIMARY KEY, body VARCHAR(64))"
+    for i in $(seq 3 40); do
+        dolt sql -q "INSERT INTO people VALUES ($i, 'person $i'); INSERT INTO notes VALUES ($i, 'note $i')"
+        dolt commit -qAm "add person $i"
+    done
+    dolt tag v2
+    dolt bundle create "$BATS_TEST_TMPDIR/full.bundle" main
+
+    dolt sql -q "UPDATE people SET name = 'william' WHERE id = 1"
+    dolt commit -am "rename bill"
+    run dolt bundle create "$BATS_TEST_TMPDIR/changes.bundle" main --since v2
+    [ "$status" -eq 0 ]
+
+    full_size=$(wc -c < "$BATS_TEST_TMPDIR/full.bundle")
+    changes_size=$(wc -c < "$BATS_TEST_TMPDIR/changes.bundle")
+    [ "$((changes_size * 2))" -lt "$full_size" ]
+
+    dolt clone "$BATS_TEST_TMPDIR/full.bundle" "$BATS_TEST_TMPDIR/fromfull"
+    cd "$BATS_TEST_TMPDIR/fromfull"
+    run dolt fetch ../changes.bundle
+    [ "$status" -eq 0 ]
+    dolt merge changes/main
+    run dolt sql -q "select name from people where id = 1" -r csv
+    [ "${lines[1]}" = "william" ]
+    run dolt sql -q "select count(*) from notes" -r csv
+    [ "${lines[1]}" = "38" ]
+}
+
+@test "bundle: fetch requires the basis commits" {
+    dolt sql -q "INSERT INTO people VALUES (3, 'bob')"
+    dolt commit -am "add bob"
+    dolt bundle create "$BATS_TEST_TMPDIR/changes.bundle" main --since v1
+
+    mkdir "$BATS_TEST_TMPDIR/other" && cd "$BATS_TEST_TMPDIR/other"
+    dolt init
+    run dolt fetch ../changes.bundle
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "lacks the commits this bundle was built on" ]] || false
+}
+
+@test "bundle: create errors" {
+    run dolt bundle create "$BATS_TEST_TMPDIR/x.bundle"
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "at least one ref" ]] || false
+
+    run dolt bundle create "$BATS_TEST_TMPDIR/x.bundle" nosuchbranch
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "'nosuchbranch' is not a branch or tag" ]] || false
+    [ ! -f "$BATS_TEST_TMPDIR/x.bundle" ]
+}
@@ -0,0 +1,115 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE people (
+  id INT PRIMARY KEY,
+  name VARCHAR(32)
+);
+CREATE TABLE other (pk INT PRIMARY KEY);
+SQL
+    dolt commit -Am "create tables"
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "cdc: diff --format debezium" {
+    dolt sql -q "insert into people values (1, 'bill'), (2, 'jane')"
+    dolt commit -am "add people"
+    dolt sql -q "update people set name = 'janet' where id = 2"
+    dolt sql -q "delete from people where id = 1"
+
+    run dolt diff --format debezium
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 2 ]
+    [[ "$output" =~ '{"before":{"id":1,"name":"bill"},"after":null,' ]] || false
+    [[ "$output" =~ '"op":"d"' ]] || false
+    [[ "$output" =~ '{"before":{"id":2,"name":"jane"},"after":{"id":2,"name":"janet"},' ]] || false
+    [[ "$output" =~ '"op":"u"' ]] || false
+    [[ "$output" =~ '"connector":"dolt"' ]] || false
+    [[ "$output" =~ '"table":"people"' ]] || false
+
+    # the working set isn't a commit
+    [[ "$output" =~ '"branch":"main"' ]] || false
+    [[ ! "$output" =~ '"commit":' ]] || false
+
+    head=$(get_head_commit)
+    run dolt diff --format debezium HEAD~1 main
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 2 ]
+    [[ "$output" =~ "\"commit\":\"$head\"" ]] || false
+    [[ "$output" =~ '"branch":"main"' ]] || false
+    [[ "$output" =~ "\"committer\":\"$(current_dolt_user_name)\"" ]] || false
+
+    # the source records the time of the commit, not of the diff
+    source=$(dolt diff --format debezium HEAD~1 main | grep -o '"source":{[^}]*}' | head -n 1)
+    sleep 1
+    [ "$(dolt diff --format debezium HEAD~1 main | grep -o '"source":{[^}]*}' | head -n 1)" = "$source" ]
+
+    run dolt diff --format debezium --stat
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "cannot be combined" ]] || false
+}
+
+@test "cdc: export commits in order" {
+    start=$(get_head_commit)
+    dolt sql -q "insert into people values (1, 'bill')"
+    dolt commit -am "add bill"
+    first=$(get_head_commit)
+    dolt sql -q "update people set name = 'william' where id = 1"
+    dolt sql -q "insert into other values (1)"
+    dolt commit -am "rename bill"
+    second=$(get_head_commit)
+
+    run dolt cdc export --from "$start"
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 3 ]
+    [[ "${lines[0]}" =~ '"op":"c"' ]] || false
+    [[ "${lines[0]}" =~ "\"commit\":\"$first\"" ]] || false
+    [[ "${lines[0]}" =~ '"branch":"main"' ]] || false
+    [[ "$output" =~ '{"before":{"id":1,"name":"bill"},"after":{"id":1,"name":"william"},' ]] || false
+    [[ "$output" =~ "\"commit\":\"$second\"" ]] || false
+
+    run dolt cdc export --from "$start" --to "$first"
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 1 ]
+
+    run dolt cdc export --from "$start" people
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 2 ]
+    [[ ! "$output" =~ '"table":"other"' ]] || false
+
+    run dolt cdc export
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "--from is required" ]] || false
+}
+
+@test "cdc: export resumes from state file" {
+    start=$(get_head_commit)
+    dolt sql -q "insert into people values (1, 'bill')"
+    dolt commit -am "add bill"
+
+    run dolt cdc export --from "$start" --state-file cdc.state
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 1 ]
+    [ "$(cat cdc.state)" = "$(get_head_commit)" ]
+
+    run dolt cdc export --state-file cdc.state
+    [ "$status" -eq 0 ]
+    [ "$output" = "" ]
+
+    dolt sql -q "insert into people values (2, 'jane')"
+    dolt commit -am "add jane"
+
+    run dolt cdc export --from "$start" --state-file cdc.state
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 1 ]
+    [[ "$output" =~ '"after":{"id":2,"name":"jane"}' ]] || false
+    [ "$(cat cdc.state)" = "$(get_head_commit)" ]
+}
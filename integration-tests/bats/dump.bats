@@ -253,6 +253,39 @@ teardown() {
     [[ "$output" =~ 'KEY `idx_v1` (`v1`)' ]] || false
 }
 
+@test "dump: SQL type - with partial indexes and indexes of expressions" {
+    dolt sql -q "CREATE TABLE users (id int primary key, email varchar(100), deleted_at datetime);"
+    dolt sql -q "CREATE UNIQUE INDEX live_email ON users (email) WHERE deleted_at IS NULL;"
+    dolt sql -q "CREATE INDEX email_ci ON users ((LOWER(email)));"
+    dolt sql -q "INSERT INTO users VALUES (1, 'ann@example.com', '2024-01-01'), (2, 'ann@example.com', NULL), (3, 'Bob@example.com', NULL);"
+
+    run dolt dump
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Successfully exported data." ]] || false
+    [ -f doltdump.sql ]
+
+    dolt sql -q "DROP TABLE users;"
+    run dolt sql < doltdump.sql
+    [ "$status" -eq 0 ]
+
+    run dolt schema show users
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ 'CREATE UNIQUE INDEX `live_email` ON `users` (`email`) WHERE deleted_at IS NULL;' ]] || false
+    [[ "$output" =~ 'CREATE INDEX `email_ci` ON `users` ((LOWER(email)));' ]] || false
+    [[ ! "$output" =~ 'KEY `live_email`' ]] || false
+    [[ ! "$output" =~ 'KEY `email_ci`' ]] || false
+
+    run dolt sql -q "SELECT id FROM users WHERE LOWER(email) = 'bob@example.com';" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "3" ]] || false
+
+    # the restored index is still partial: deleted rows may share the email of a live row, live rows may not
+    dolt sql -q "INSERT INTO users VALUES (4, 'ann@example.com', '2024-02-01');"
+    run dolt sql -q "INSERT INTO users VALUES (5, 'ann@example.com', NULL);"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "duplicate unique key" ]] || false
+}
+
 @test "dump: SQL type - with foreign key and import" {
     skip "dolt dump foreign key option for import NOT implemented"
     dolt sql -q "CREATE TABLE new_table(pk int primary key);"
@@ -916,3 +916,77 @@ DELIM
     [ "$status" -eq 1 ]
     [[ "$output" =~ "parameters all-text and schema are mutually exclusive" ]] || false
 }
+
+@test "import-create-tables: --infer infers a primary key and converts extended date formats" {
+    cat <<DELIM >test.csv
+region,day,placed,total
+us,1,01/02/2020,10
+us,2,01/03/2020,10
+eu,1,"Jan 2, 2020",12
+eu,2,01/03/2020,12
+DELIM
+
+    run dolt table import -c --infer test test.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Inferred primary key (region, day)" ]] || false
+    [[ "$output" =~ "Import completed successfully." ]] || false
+
+    run dolt sql -q "show create table test"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "PRIMARY KEY (\`region\`,\`day\`)" ]] || false
+    [[ "$output" =~ "\`placed\` date NOT NULL" ]] || false
+
+    run dolt sql -q "select placed from test where region = 'eu' and day = 1" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2020-01-02" ]] || false
+}
+
+@test "import-create-tables: --infer adds unique keys for unique columns" {
+    echo "id,email,plan" > test.csv
+    for i in $(seq 1 25); do
+        echo "$i,user$i@example.com,plan$((i % 2))" >> test.csv
+    done
+
+    run dolt table import -c --infer test test.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Inferred primary key (id)" ]] || false
+    [[ "$output" =~ "Inferred unique key (email)" ]] || false
+
+    run dolt sql -q "show create table test"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "UNIQUE KEY \`email\` (\`email\`)" ]] || false
+    ! [[ "$output" =~ "UNIQUE KEY \`plan\`" ]] || false
+
+    run dolt sql -q "insert into test values (26, 'user1@example.com', 'plan0')"
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "duplicate unique key" ]] || false
+}
+
+@test "import-create-tables: extended date formats are only inferred with --infer" {
+    cat <<DELIM >test.csv
+id,placed
+1,01/02/2020
+2,01/03/2020
+DELIM
+
+    run dolt table import -c --pk id test test.csv
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "show create table test"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "\`placed\` varchar" ]] || false
+
+    run dolt sql -q "select placed from test where id = 1" -r csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "01/02/2020" ]] || false
+}
+
+@test "import-create-tables: --infer is only supported for create" {
+    run dolt table import -u --infer test `batshelper employees-tbl.json`
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "--infer is only supported for create operations" ]] || false
+
+    run dolt table import -c --infer --all-text test `batshelper employees-tbl.json`
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "parameter infer cannot be used with all-text or schema" ]] || false
+}
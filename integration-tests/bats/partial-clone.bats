@@ -0,0 +1,166 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_no_dolt_init
+
+    TESTDIRS=$(pwd)/testdirs
+    mkdir -p $TESTDIRS/{rem1,repo1}
+
+    cd $TESTDIRS/repo1
+    dolt init
+    dolt sql <<SQL
+CREATE TABLE people (id INT PRIMARY KEY, name VARCHAR(32));
+CREATE TABLE events (id INT PRIMARY KEY, body TEXT);
+INSERT INTO people VALUES (1, 'bill'), (2, 'jane');
+INSERT INTO events VALUES (1, REPEAT('a', 10000)), (2, 'short');
+SQL
+    dolt commit -Am "create tables"
+    dolt remote add origin file://../rem1
+    dolt push origin main
+
+    cd $TESTDIRS
+}
+
+teardown() {
+    teardown_common
+    rm -rf $TESTDIRS
+}
+
+@test "partial-clone: table filter reads other tables on demand" {
+    run dolt clone --filter=tables:people file://rem1 repo2
+    [ "$status" -eq 0 ]
+    [ -f repo2/.dolt/noms/partial_clone.json ]
+
+    cd repo2
+    run dolt sql -q "select name from people order by id" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "bill" ]
+    [ "${lines[2]}" = "jane" ]
+
+    run dolt sql -q "select id, length(body) from events order by id" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "1,10000" ]
+    [ "${lines[2]}" = "2,5" ]
+
+    run dolt schema show events
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "body" ]] || false
+}
+
+@test "partial-clone: data left out by the filter is read from the origin" {
+    dolt clone --filter=tables:people file://rem1 repo2
+    mv rem1 rem1.moved
+
+    cd repo2
+    run dolt sql -q "select name from people where id = 2" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "jane" ]
+
+    run dolt sql -q "select count(*) from events"
+    [ "$status" -ne 0 ]
+
+    mv ../rem1.moved ../rem1
+    run dolt sql -q "select count(*) from events" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "2" ]
+
+    # data read once is kept
+    mv ../rem1 ../rem1.moved
+    run dolt sql -q "select count(*) from events" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "2" ]
+    mv ../rem1.moved ../rem1
+}
+
+@test "partial-clone: blob:none leaves out large values" {
+    dolt clone --filter=blob:none file://rem1 repo2
+    mv rem1 rem1.moved
+
+    cd repo2
+    run dolt sql -q "select name from people where id = 1" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "bill" ]
+
+    run dolt sql -q "select length(body) from events where id = 1"
+    [ "$status" -ne 0 ]
+
+    mv ../rem1.moved ../rem1
+    run dolt sql -q "select length(body) from events where id = 1" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "10000" ]
+}
+
+@test "partial-clone: writes and fetches in a partial clone" {
+    dolt clone --filter=tables:people file://rem1 repo2
+
+    cd repo1
+    dolt sql -q "INSERT INTO events VALUES (3, 'new')"
+    dolt sql -q "INSERT INTO people VALUES (3, 'bob')"
+    dolt commit -am "add rows"
+    dolt push origin main
+
+    cd ../repo2
+    dolt sql -q "INSERT INTO people VALUES (4, 'sue')"
+    dolt commit -am "add sue"
+
+    run dolt pull origin main
+    [ "$status" -eq 0 ]
+
+    run dolt sql -q "select count(*) from people" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "4" ]
+    run dolt sql -q "select count(*) from events" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "3" ]
+}
+
+@test "partial-clone: fetch --backfill makes the clone complete" {
+    dolt clone --filter=tables:people file://rem1 repo2
+
+    cd repo1
+    dolt sql -q "INSERT INTO events VALUES (3, 'new')"
+    dolt commit -am "add event"
+    dolt push origin main
+
+    cd ../repo2
+    run dolt fetch --backfill
+    [ "$status" -eq 0 ]
+    [ ! -f .dolt/noms/partial_clone.json ]
+
+    mv ../rem1 ../rem1.moved
+    run dolt sql -q "select count(*) from events" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "2" ]
+    run dolt sql -q "select count(*) from events as of 'origin/main'" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "3" ]
+
+    run dolt gc
+    [ "$status" -eq 0 ]
+    mv ../rem1.moved ../rem1
+}
+
+@test "partial-clone: errors" {
+    run dolt clone --filter=blob:limit=1k file://rem1 repo2
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "invalid filter" ]] || false
+    [ ! -d repo2 ]
+
+    dolt clone file://rem1 repo3
+    cd repo3
+    run dolt fetch --backfill
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "--backfill can only be used in a partial clone" ]] || false
+
+    cd ..
+    dolt clone --filter=tables:people file://rem1 repo4
+    cd repo4
+    run dolt gc
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "cannot garbage collect a partial clone" ]] || false
+
+    run dolt sql -q "call dolt_clone('--filter', 'blob:none', 'file://../rem1', 'repo5')"
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "--filter is not supported by dolt_clone" ]] || false
+}
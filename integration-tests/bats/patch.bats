@@ -0,0 +1,194 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+
+    dolt sql <<SQL
+CREATE TABLE people (
+  id INT PRIMARY KEY,
+  name VARCHAR(32),
+  age INT
+);
+INSERT INTO people VALUES (1, 'bill', 30), (2, 'jane', 40), (3, 'bob', 50);
+SQL
+    dolt commit -Am "create people"
+    dolt branch base
+}
+
+teardown() {
+    assert_feature_version
+    teardown_common
+}
+
+@test "patch: create and apply cleanly" {
+    dolt checkout -b feature
+    dolt sql <<SQL
+INSERT INTO people VALUES (4, 'sue', 20);
+UPDATE people SET age = 41 WHERE id = 2;
+DELETE FROM people WHERE id = 3;
+CREATE TABLE pets (id INT PRIMARY KEY, owner INT);
+INSERT INTO pets VALUES (1, 4);
+SQL
+    dolt commit -Am "feature changes"
+
+    run dolt patch create base feature -o feature.patch
+    [ "$status" -eq 0 ]
+    run cat feature.patch
+    [[ "$output" =~ '"format": "dolt-patch"' ]] || false
+    [[ "$output" =~ '"op": "update"' ]] || false
+
+    dolt checkout main
+    run dolt patch apply feature.patch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Applied patch to 2 tables, 4 rows changed" ]] || false
+
+    run dolt sql -q "select * from people order by id" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[1]}" = "1,bill,30" ]
+    [ "${lines[2]}" = "2,jane,41" ]
+    [ "${lines[3]}" = "4,sue,20" ]
+    [ "${#lines[@]}" -eq 4 ]
+
+    run dolt sql -q "select * from pets" -r csv
+    [ "${lines[1]}" = "1,4" ]
+
+    # the changes are left in the working set
+    run dolt status
+    [[ "$output" =~ "people" ]] || false
+
+    # applying again fails without changing anything
+    dolt commit -Am "applied"
+    run dolt patch apply feature.patch
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "table pets: already exists" ]] || false
+    [[ "$output" =~ "does not apply cleanly" ]] || false
+}
+
+@test "patch: conflicting patch is rejected" {
+    dolt checkout -b feature
+    dolt sql -q "UPDATE people SET age = 31 WHERE id = 1"
+    dolt commit -am "feature change"
+    dolt patch create base -o feature.patch
+
+    dolt checkout main
+    dolt sql -q "UPDATE people SET age = 99 WHERE id = 1"
+    dolt sql -q "INSERT INTO people VALUES (5, 'ann', 60)"
+
+    run dolt patch apply feature.patch
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "table people: row (1) does not match the patch base" ]] || false
+
+    run dolt sql -q "select age from people where id = 1" -r csv
+    [ "${lines[1]}" = "99" ]
+}
+
+@test "patch: a patch that fails part way is not applied" {
+    dolt checkout -b feature
+    dolt sql <<SQL
+UPDATE people SET age = 41 WHERE id = 2;
+DELETE FROM people WHERE id = 3;
+INSERT INTO people VALUES (4, 'sue', 20);
+SQL
+    dolt commit -am "feature change"
+    dolt patch create base -o feature.patch
+
+    dolt checkout main
+    dolt sql <<SQL
+CREATE TABLE pets (id INT PRIMARY KEY, owner INT, FOREIGN KEY (owner) REFERENCES people (id));
+INSERT INTO pets VALUES (1, 3);
+SQL
+    dolt commit -Am "add pets"
+
+    # the patch applies cleanly, but deleting bob violates the foreign key of pets
+    run dolt patch apply feature.patch
+    [ "$status" -ne 0 ]
+    [[ ! "$output" =~ "does not apply cleanly" ]] || false
+    [[ "$output" =~ "failed to apply patch" ]] || false
+
+    run dolt sql -q "select id, age from people order by id" -r csv
+    [ "${lines[1]}" = "1,30" ]
+    [ "${lines[2]}" = "2,40" ]
+    [ "${lines[3]}" = "3,50" ]
+    [ "${#lines[@]}" -eq 4 ]
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "patch: the schema changes of a patch that fails part way are kept" {
+    dolt checkout -b feature
+    dolt sql <<SQL
+ALTER TABLE people ADD COLUMN email VARCHAR(64);
+UPDATE people SET email = 'jane@example.com' WHERE id = 2;
+DELETE FROM people WHERE id = 3;
+SQL
+    dolt commit -am "feature change"
+    dolt patch create base -o feature.patch
+
+    dolt checkout main
+    dolt sql <<SQL
+CREATE TABLE pets (id INT PRIMARY KEY, owner INT, FOREIGN KEY (owner) REFERENCES people (id));
+INSERT INTO pets VALUES (1, 3);
+SQL
+    dolt commit -Am "add pets"
+
+    run dolt patch apply feature.patch
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "schema changes of the patch were left in the working set" ]] || false
+    [[ "$output" =~ "failed to apply patch" ]] || false
+
+    # the column was added, but no rows were changed
+    run dolt sql -q "select id, email from people order by id" -r csv
+    [ "$status" -eq 0 ]
+    [ "${lines[2]}" = "2," ]
+    [ "${#lines[@]}" -eq 4 ]
+    run dolt status
+    [[ "$output" =~ "people" ]] || false
+}
+
+@test "patch: --3way merges using the base commit" {
+    dolt checkout -b feature
+    dolt sql -q "UPDATE people SET age = 31 WHERE id = 1"
+    dolt commit -am "feature change"
+    dolt patch create base -o feature.patch
+
+    dolt checkout main
+    dolt sql -q "UPDATE people SET name = 'william' WHERE id = 1"
+    dolt commit -am "main change"
+
+    run dolt patch apply --3way feature.patch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "three-way merge" ]] || false
+    [[ "$output" =~ "Merged patch as commit" ]] || false
+
+    run dolt sql -q "select name, age from people where id = 1" -r csv
+    [ "${lines[1]}" = "william,31" ]
+
+    run dolt branch
+    [[ ! "$output" =~ "dolt-patch-apply" ]] || false
+}
+
+@test "patch: --3way records conflicts" {
+    dolt checkout -b feature
+    dolt sql -q "UPDATE people SET age = 31 WHERE id = 1"
+    dolt commit -am "feature change"
+    dolt patch create base -o feature.patch
+
+    dolt checkout main
+    dolt sql -q "UPDATE people SET age = 32 WHERE id = 1"
+    dolt commit -am "main change"
+
+    run dolt patch apply --3way feature.patch
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "1 data conflicts" ]] || false
+
+    run dolt sql -q "select count(*) from dolt_conflicts_people" -r csv
+    [ "${lines[1]}" = "1" ]
+}
+
+@test "patch: apply rejects files that aren't patches" {
+    echo "INSERT INTO people VALUES (10, 'x', 1);" > notapatch.sql
+    run dolt patch apply notapatch.sql
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "not a dolt patch file" ]] || false
+}
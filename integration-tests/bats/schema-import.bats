@@ -410,3 +410,42 @@ CSV
     [[ "$output" =~ "name" ]] || false
     [[ "$output" =~ "invalid schema" ]] || false
 }
+
+@test "schema-import: infer primary key and constraints" {
+    cat <<CSV > infer.csv
+name,order_id,status,placed
+alice,1,open,01/02/2020
+bob,2,shipped,01/03/2020
+carol,3,open,01/04/2020
+CSV
+    run dolt schema import --dry-run -c --infer test infer.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "-- profiled all 3 rows" ]] || false
+    [[ "$output" =~ "-- primary key (order_id)" ]] || false
+    [[ "$output" =~ "\`order_id\` int NOT NULL" ]] || false
+    [[ "$output" =~ "\`name\` varchar(16) NOT NULL" ]] || false
+    [[ "$output" =~ "\`placed\` date NOT NULL" ]] || false
+    [[ "$output" =~ "PRIMARY KEY (\`order_id\`)" ]] || false
+
+    run dolt ls
+    [ "$status" -eq 0 ]
+    ! [[ "$output" =~ "test" ]] || false
+
+    run dolt schema import -c --infer --pks=name test infer.csv
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "PRIMARY KEY (\`name\`)" ]] || false
+}
+
+@test "schema-import: infer fails without a candidate primary key" {
+    cat <<CSV > nokey.csv
+a,b
+1,2
+1,2
+CSV
+    run dolt schema import -c --infer test nokey.csv
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "failed to infer a primary key" ]] || false
+
+    run dolt schema import -u --infer test nokey.csv
+    [ "$status" -eq 1 ]
+}
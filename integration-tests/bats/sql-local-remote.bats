@@ -1386,3 +1386,26 @@ SQL
     [[ "$output" =~ "main commit 2" ]] || false
     [[ "$output" =~ "b1 commit 1" ]] || false
 }
+
+@test "sql-local-remote: verify dolt dump masks columns for the user of a running server" {
+    cd altDB
+    dolt sql <<SQL
+CREATE TABLE customers (id INT PRIMARY KEY, name VARCHAR(10), balance INT);
+INSERT INTO customers VALUES (1, 'annabelle', 987654), (2, 'bart', 123456);
+CALL dolt_create_mask('name', 'customers', 'name', 'hash');
+CALL dolt_create_mask('balance', 'customers', 'balance', 'full');
+CREATE USER reader@'%' IDENTIFIED BY 'pass';
+GRANT SELECT ON altDB.* TO reader@'%';
+SQL
+
+    start_sql_server altDB
+    run dolt --verbose-engine-setup --user=reader --password=pass dump -f
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "starting remote mode" ]] || false
+
+    run grep 'INSERT INTO `customers`' doltdump.sql
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "annabelle" ]] || false
+    [[ ! "$output" =~ "987654" ]] || false
+    [[ "$output" =~ "$(echo -n annabelle | sha256sum | cut -d ' ' -f 1)" ]] || false
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	driver "github.com/dolthub/dolt/go/libraries/doltcore/dtestutils/sql_server_driver"
+)
+
+// TestAuditLog runs a sql-server with an audit log which is written to a
+// file and to a table, and asserts that connection events, authentication
+// attempts and statements are recorded in both.
+func TestAuditLog(t *testing.T) {
+	u, err := driver.NewDoltUser()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		u.Cleanup()
+	})
+	rs, err := u.MakeRepoStore()
+	require.NoError(t, err)
+	_, err = rs.MakeRepo("audited")
+	require.NoError(t, err)
+
+	config := `log_level: trace
+listener:
+  host: 0.0.0.0
+  port: 3309
+audit_log:
+  path: logs/audit.log
+  exclude_users:
+  - auditor
+  statement_classes:
+  - dml
+  - ddl
+  - dcl
+  table:
+    database: audited
+    name: audit_events
+`
+	require.NoError(t, os.WriteFile(filepath.Join(rs.Dir, "server.yaml"), []byte(config), 0550))
+	server, err := driver.StartSqlServer(rs, driver.WithArgs("--config", "server.yaml"), driver.WithPort(3309))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, server.GracefulStop())
+	})
+
+	ctx := context.Background()
+	db, err := server.DB(driver.Connection{User: "root"})
+	require.NoError(t, err)
+	defer db.Close()
+	require.Eventually(t, func() bool {
+		return db.PingContext(ctx) == nil
+	}, 10*time.Second, 100*time.Millisecond)
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, "use audited")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "create table vals (id int primary key)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "insert into vals values (1), (2), (3)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "select * from vals")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "insert into missing values (1)")
+	require.Error(t, err)
+
+	bad, err := server.DB(driver.Connection{User: "mallory", Pass: "wrong"})
+	require.NoError(t, err)
+	require.Error(t, bad.PingContext(ctx))
+	bad.Close()
+
+	type event struct {
+		Event          string   `json:"event"`
+		User           string   `json:"user"`
+		Database       string   `json:"database"`
+		Branch         string   `json:"branch"`
+		StatementType  string   `json:"statement_type"`
+		StatementClass string   `json:"statement_class"`
+		Tables         []string `json:"tables"`
+		RowsAffected   *uint64  `json:"rows_affected"`
+		Error          string   `json:"error"`
+	}
+	readEvents := func() []event {
+		f, err := os.Open(filepath.Join(rs.Dir, "logs", "audit.log"))
+		require.NoError(t, err)
+		defer f.Close()
+		var events []event
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var ev event
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+			events = append(events, ev)
+		}
+		require.NoError(t, scanner.Err())
+		return events
+	}
+
+	var statements []event
+	var authFailures []event
+	for _, ev := range readEvents() {
+		switch ev.Event {
+		case "statement":
+			statements = append(statements, ev)
+		case "auth_failure":
+			authFailures = append(authFailures, ev)
+		}
+	}
+	require.Len(t, authFailures, 1)
+	assert.Equal(t, "mallory", authFailures[0].User)
+
+	// The select and the use are filtered out.
+	require.Len(t, statements, 3)
+	assert.Equal(t, "create", statements[0].StatementType)
+	assert.Equal(t, "ddl", statements[0].StatementClass)
+	assert.Equal(t, "insert", statements[1].StatementType)
+	assert.Equal(t, "root", statements[1].User)
+	assert.Equal(t, "audited", statements[1].Database)
+	assert.Equal(t, "main", statements[1].Branch)
+	assert.Equal(t, []string{"vals"}, statements[1].Tables)
+	require.NotNil(t, statements[1].RowsAffected)
+	assert.Equal(t, uint64(3), *statements[1].RowsAffected)
+	assert.NotEmpty(t, statements[2].Error)
+
+	// The table sink writes its events in the background.
+	require.Eventually(t, func() bool {
+		var cnt int
+		err := db.QueryRowContext(ctx, "select count(*) from audited.audit_events where event = 'statement' and statement_type = 'insert' and user = 'root'").Scan(&cnt)
+		return err == nil && cnt == 2
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,288 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	driver "github.com/dolthub/dolt/go/libraries/doltcore/dtestutils/sql_server_driver"
+)
+
+// TestAutomaticFailover runs a three server cluster with automatic failover
+// enabled. It stops the primary and asserts that one of the standbys becomes
+// the primary at a new epoch with all of the writes which were replicated to
+// it, and that the old primary becomes a standby when it is restarted.
+func TestAutomaticFailover(t *testing.T) {
+	servers, dbs, start := startFailoverCluster(t, 3, nil)
+	ctx := context.Background()
+
+	_, err := dbs[0].ExecContext(ctx, "create database repo1")
+	require.NoError(t, err)
+	_, err = dbs[0].ExecContext(ctx, "create table repo1.vals (id int primary key)")
+	require.NoError(t, err)
+	_, err = dbs[0].ExecContext(ctx, "insert into repo1.vals values (1),(2),(3)")
+	require.NoError(t, err)
+	// Wait for the writes to replicate to both standbys.
+	require.Eventually(t, func() bool {
+		var behind int
+		err := dbs[0].QueryRowContext(ctx, "select count(*) from dolt_cluster.dolt_cluster_status where `database` = 'repo1' and (replication_lag_millis is null or replication_lag_millis > 0)").Scan(&behind)
+		return err == nil && behind == 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// Stop the primary. One of the standbys should take over at epoch 2.
+	require.NoError(t, servers[0].GracefulStop())
+	servers[0] = nil
+
+	newPrimary := -1
+	require.Eventually(t, func() bool {
+		for i := 1; i < len(dbs); i++ {
+			role, epoch, err := clusterRole(ctx, dbs[i])
+			if err == nil && role == "primary" {
+				assert.Equal(t, 2, epoch)
+				newPrimary = i
+				return true
+			}
+		}
+		return false
+	}, 20*time.Second, 250*time.Millisecond)
+
+	var cnt int
+	require.NoError(t, dbs[newPrimary].QueryRowContext(ctx, "select count(*) from repo1.vals").Scan(&cnt))
+	assert.Equal(t, 3, cnt)
+	_, err = dbs[newPrimary].ExecContext(ctx, "insert into repo1.vals values (4)")
+	require.NoError(t, err)
+
+	// The old primary comes back at epoch 1 and should be demoted to a
+	// standby at the new epoch.
+	start(0)
+	dbs[0].Close()
+	dbs[0], err = servers[0].DB(driver.Connection{User: "root"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		role, epoch, err := clusterRole(ctx, dbs[0])
+		return err == nil && role == "standby" && epoch == 2
+	}, 20*time.Second, 250*time.Millisecond)
+
+	// There is still exactly one primary.
+	primaries := 0
+	for i := range dbs {
+		role, _, err := clusterRole(ctx, dbs[i])
+		require.NoError(t, err)
+		if role == "primary" {
+			primaries += 1
+		}
+	}
+	assert.Equal(t, 1, primaries)
+}
+
+// startFailoverCluster starts a cluster of |numServers| servers with automatic
+// failover enabled, in which the first server is the primary. If
+// |partitioned| is non-nil and returns true for two servers, their standby
+// remotes for each other point to a port on which nothing listens, so that
+// they can not reach each other. It returns the servers, connections to them,
+// and a function which restarts a stopped server.
+func startFailoverCluster(t *testing.T, numServers int, partitioned func(i, j int) bool) ([]*driver.SqlServer, []*sql.DB, func(i int)) {
+	u, err := driver.NewDoltUser()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		u.Cleanup()
+	})
+
+	const unreachablePort = 3850
+	ports := make([]int, numServers)
+	remotesapiPorts := make([]int, numServers)
+	for i := range ports {
+		ports[i] = 3309 + i
+		remotesapiPorts[i] = 3851 + i
+	}
+
+	stores := make([]driver.RepoStore, numServers)
+	servers := make([]*driver.SqlServer, numServers)
+	t.Cleanup(func() {
+		for _, s := range servers {
+			if s != nil {
+				assert.NoError(t, s.GracefulStop())
+			}
+		}
+	})
+	start := func(i int) {
+		server, err := driver.StartSqlServer(stores[i], driver.WithArgs("--config", "server.yaml"), driver.WithPort(ports[i]), driver.WithName(fmt.Sprintf("server%d", i+1)))
+		require.NoError(t, err)
+		servers[i] = server
+	}
+	for i := range stores {
+		stores[i], err = u.MakeRepoStore()
+		require.NoError(t, err)
+		role := "standby"
+		if i == 0 {
+			role = "primary"
+		}
+		var remotes []string
+		for j := range remotesapiPorts {
+			if j != i {
+				port := remotesapiPorts[j]
+				if partitioned != nil && (partitioned(i, j) || partitioned(j, i)) {
+					port = unreachablePort
+				}
+				remotes = append(remotes, fmt.Sprintf(`
+  - name: server%d
+    remote_url_template: http://localhost:%d/{database}`, j+1, port))
+			}
+		}
+		config := fmt.Sprintf(`log_level: trace
+listener:
+  host: 0.0.0.0
+  port: %d
+cluster:
+  standby_remotes:%s
+  bootstrap_role: %s
+  bootstrap_epoch: 1
+  remotesapi:
+    port: %d
+  automatic_failover:
+    enabled: true
+    heartbeat_interval_millis: 500
+    failover_timeout_millis: 2000
+`, ports[i], strings.Join(remotes, ""), role, remotesapiPorts[i])
+		require.NoError(t, os.WriteFile(filepath.Join(stores[i].Dir, "server.yaml"), []byte(config), 0550))
+		start(i)
+	}
+
+	ctx := context.Background()
+	dbs := make([]*sql.DB, numServers)
+	for i, s := range servers {
+		dbs[i], err = s.DB(driver.Connection{User: "root"})
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() {
+		for _, db := range dbs {
+			db.Close()
+		}
+	})
+
+	// Wait for the servers to come up.
+	for i := range dbs {
+		require.Eventually(t, func() bool {
+			return dbs[i].PingContext(ctx) == nil
+		}, 10*time.Second, 100*time.Millisecond)
+	}
+	return servers, dbs, start
+}
+
+// TestAutomaticFailoverPrimaryWithoutQuorum stops both standbys of a three
+// server cluster with automatic failover enabled, and asserts that the
+// primary, which can no longer reach a quorum, demotes itself to a standby so
+// that it stops accepting writes.
+func TestAutomaticFailoverPrimaryWithoutQuorum(t *testing.T) {
+	servers, dbs, _ := startFailoverCluster(t, 3, nil)
+	ctx := context.Background()
+
+	_, err := dbs[0].ExecContext(ctx, "create database repo1")
+	require.NoError(t, err)
+
+	for i := 1; i < len(servers); i++ {
+		require.NoError(t, servers[i].GracefulStop())
+		servers[i] = nil
+	}
+
+	require.Eventually(t, func() bool {
+		role, epoch, err := clusterRole(ctx, dbs[0])
+		return err == nil && role == "standby" && epoch == 1
+	}, 10*time.Second, 250*time.Millisecond)
+
+	_, err = dbs[0].ExecContext(ctx, "create table repo1.vals (id int primary key)")
+	require.Error(t, err)
+}
+
+// TestAutomaticFailoverPartitionedStandbys runs a five server cluster with
+// automatic failover enabled, in which server2 and server3 can not reach each
+// other, and the primary can not reach server4 or server5. Only server2 and
+// server3 have the primary's databases. When the primary stops, server2 and
+// server3 each reach a quorum of the cluster, server4 and server5, and each
+// is more caught up than every standby it can reach. It asserts that only
+// one of them is promoted, and that the other is not promoted while the new
+// primary is running.
+func TestAutomaticFailoverPartitionedStandbys(t *testing.T) {
+	servers, dbs, _ := startFailoverCluster(t, 5, func(i, j int) bool {
+		return (i == 1 && j == 2) || (i == 0 && j >= 3)
+	})
+	ctx := context.Background()
+
+	_, err := dbs[0].ExecContext(ctx, "create database repo1")
+	require.NoError(t, err)
+	_, err = dbs[0].ExecContext(ctx, "create table repo1.vals (id int primary key)")
+	require.NoError(t, err)
+	_, err = dbs[0].ExecContext(ctx, "insert into repo1.vals values (1),(2),(3)")
+	require.NoError(t, err)
+	// Wait for the writes to replicate to server2 and server3.
+	require.Eventually(t, func() bool {
+		var behind int
+		err := dbs[0].QueryRowContext(ctx, "select count(*) from dolt_cluster.dolt_cluster_status where `database` = 'repo1' and standby_remote in ('server2', 'server3') and (replication_lag_millis is null or replication_lag_millis > 0)").Scan(&behind)
+		return err == nil && behind == 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	require.NoError(t, servers[0].GracefulStop())
+	servers[0] = nil
+
+	// primaries returns the servers among server2 and server3 which are
+	// primaries.
+	primaries := func() []int {
+		var ret []int
+		for i := 1; i <= 2; i++ {
+			role, _, err := clusterRole(ctx, dbs[i])
+			if err == nil && role == "primary" {
+				ret = append(ret, i)
+			}
+		}
+		return ret
+	}
+	newPrimary := -1
+	require.Eventually(t, func() bool {
+		ps := primaries()
+		assert.LessOrEqual(t, len(ps), 1, "server2 and server3 are both primaries")
+		if len(ps) == 1 {
+			newPrimary = ps[0]
+			return true
+		}
+		return false
+	}, 30*time.Second, 100*time.Millisecond)
+
+	// server4 and server5 can reach the new primary, so they do not vote
+	// for the other standby while it keeps trying to promote itself.
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		require.Equal(t, []int{newPrimary}, primaries())
+	}
+
+	var cnt int
+	require.NoError(t, dbs[newPrimary].QueryRowContext(ctx, "select count(*) from repo1.vals").Scan(&cnt))
+	assert.Equal(t, 3, cnt)
+}
+
+func clusterRole(ctx context.Context, db *sql.DB) (string, int, error) {
+	var role string
+	var epoch int
+	err := db.QueryRowContext(ctx, "select @@GLOBAL.dolt_cluster_role, @@GLOBAL.dolt_cluster_role_epoch").Scan(&role, &epoch)
+	return role, epoch, err
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	driver "github.com/dolthub/dolt/go/libraries/doltcore/dtestutils/sql_server_driver"
+)
+
+// TestResourceLimits runs a sql-server with resource limits for a user and a
+// role, and asserts that statements which exceed them fail, that the limits
+// are shown in dolt_resource_limits and that revoking the role lifts them.
+func TestResourceLimits(t *testing.T) {
+	u, err := driver.NewDoltUser()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		u.Cleanup()
+	})
+	rs, err := u.MakeRepoStore()
+	require.NoError(t, err)
+	_, err = rs.MakeRepo("limited")
+	require.NoError(t, err)
+
+	config := `log_level: trace
+listener:
+  host: 0.0.0.0
+  port: 3309
+resource_limits:
+- user: analyst
+  max_rows_returned: 3
+- role: loaders
+  max_rows_written_per_transaction: 5
+  on_exceed: kill
+`
+	require.NoError(t, os.WriteFile(filepath.Join(rs.Dir, "server.yaml"), []byte(config), 0550))
+	server, err := driver.StartSqlServer(rs, driver.WithArgs("--config", "server.yaml"), driver.WithPort(3309))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, server.GracefulStop())
+	})
+
+	ctx := context.Background()
+	db, err := server.DB(driver.Connection{User: "root"})
+	require.NoError(t, err)
+	defer db.Close()
+	require.Eventually(t, func() bool {
+		return db.PingContext(ctx) == nil
+	}, 10*time.Second, 100*time.Millisecond)
+	for _, q := range []string{
+		"create table limited.vals (id int primary key)",
+		"insert into limited.vals values (1), (2), (3), (4), (5)",
+		"create user analyst@'%' identified by 'pass'",
+		"grant all on *.* to analyst@'%'",
+		"create role loaders",
+		"grant all on *.* to loaders",
+		"create user loader@'%' identified by 'pass'",
+		"grant loaders to loader@'%'",
+		"set default role loaders to loader@'%'",
+	} {
+		_, err = db.ExecContext(ctx, q)
+		require.NoError(t, err, q)
+	}
+
+	analyst, err := server.DB(driver.Connection{User: "analyst", Pass: "pass"})
+	require.NoError(t, err)
+	defer analyst.Close()
+	var cnt int
+	require.NoError(t, analyst.QueryRowContext(ctx, "select count(*) from limited.vals").Scan(&cnt))
+	assert.Equal(t, 5, cnt)
+	rows, err := analyst.QueryContext(ctx, "select * from limited.vals where id <= 3")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	rows, err = analyst.QueryContext(ctx, "select * from limited.vals")
+	if err == nil {
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+	}
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_rows_returned")
+	// Root has no limits.
+	rows, err = db.QueryContext(ctx, "select * from limited.vals")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+
+	loader, err := server.DB(driver.Connection{User: "loader", Pass: "pass"})
+	require.NoError(t, err)
+	defer loader.Close()
+	conn, err := loader.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, "insert into limited.vals values (6), (7), (8)")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "insert into limited.vals values (9), (10), (11), (12), (13), (14)")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_rows_written_per_transaction")
+	// The loader role's limits kill the connection when they are exceeded.
+	require.Error(t, conn.PingContext(ctx))
+
+	require.NoError(t, db.QueryRowContext(ctx, "select count(*) from limited.vals").Scan(&cnt))
+	assert.Equal(t, 8, cnt)
+
+	// The limits of the connections are shown in dolt_resource_limits.
+	conn, err = loader.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.PingContext(ctx))
+	var user string
+	var limit uint64
+	require.NoError(t, analyst.QueryRowContext(ctx, "select user, max_rows_returned from limited.dolt_resource_limits where user = 'analyst'").Scan(&user, &limit))
+	assert.Equal(t, "analyst", user)
+	assert.Equal(t, uint64(3), limit)
+	require.NoError(t, db.QueryRowContext(ctx, "select max_rows_written_per_transaction from limited.dolt_resource_limits where user = 'loader'").Scan(&limit))
+	assert.Equal(t, uint64(5), limit)
+
+	// And with their running statements in SHOW PROCESSLIST.
+	var info string
+	rows, err = conn.QueryContext(ctx, "show processlist")
+	require.NoError(t, err)
+	for rows.Next() {
+		var id, secs int64
+		var user, host, command string
+		var database, state, rowInfo sql.NullString
+		require.NoError(t, rows.Scan(&id, &user, &host, &database, &command, &secs, &state, &rowInfo))
+		if user == "loader" && rowInfo.Valid {
+			info = rowInfo.String
+		}
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	assert.Equal(t, "/* resource limits: max_rows_written_per_transaction=5, on_exceed=kill */ show processlist", info)
+
+	// Once the role is revoked, the open connection of its user is no longer limited by it.
+	_, err = db.ExecContext(ctx, "revoke loaders from loader@'%'")
+	require.NoError(t, err)
+	// The roles of a user are cached for a second.
+	time.Sleep(2 * time.Second)
+	_, err = conn.ExecContext(ctx, "insert into limited.vals values (9), (10), (11), (12), (13), (14)")
+	require.NoError(t, err)
+	require.NoError(t, db.QueryRowContext(ctx, "select count(*) from limited.dolt_resource_limits where user = 'loader'").Scan(&cnt))
+	assert.Equal(t, 0, cnt)
+}
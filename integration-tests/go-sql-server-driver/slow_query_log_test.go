@@ -0,0 +1,92 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	driver "github.com/dolthub/dolt/go/libraries/doltcore/dtestutils/sql_server_driver"
+)
+
+// TestSlowQueryLog runs a sql-server with slow_query_log enabled and asserts
+// that statements slower than long_query_time are written to
+// slow_query_log_file and shown in the dolt_slow_queries table.
+func TestSlowQueryLog(t *testing.T) {
+	u, err := driver.NewDoltUser()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		u.Cleanup()
+	})
+	rs, err := u.MakeRepoStore()
+	require.NoError(t, err)
+	_, err = rs.MakeRepo("slow")
+	require.NoError(t, err)
+
+	server, err := driver.StartSqlServer(rs, driver.WithArgs("--port", "3310"), driver.WithPort(3310))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, server.GracefulStop())
+	})
+
+	ctx := context.Background()
+	db, err := server.DB(driver.Connection{User: "root"})
+	require.NoError(t, err)
+	defer db.Close()
+	require.Eventually(t, func() bool {
+		return db.PingContext(ctx) == nil
+	}, 10*time.Second, 100*time.Millisecond)
+
+	logPath := filepath.Join(rs.Dir, "slow.log")
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	for _, q := range []string{
+		"use slow",
+		"create table vals (id int primary key)",
+		"insert into vals values (1), (2), (3), (4)",
+		"set @@global.slow_query_log_file = '" + logPath + "'",
+		"set @@global.dolt_slow_query_table_size = 10",
+		"set @@global.slow_query_log = 1",
+		"set @@session.long_query_time = 0",
+		"select * from vals where id > 1",
+	} {
+		_, err = conn.ExecContext(ctx, q)
+		require.NoError(t, err, q)
+	}
+
+	var query, database, branch, plan string
+	var rowsExamined, rowsSent uint64
+	err = conn.QueryRowContext(ctx, "select query, database_name, branch, rows_examined, rows_sent, plan from dolt_slow_queries where query like 'select * from vals%'").
+		Scan(&query, &database, &branch, &rowsExamined, &rowsSent, &plan)
+	require.NoError(t, err)
+	assert.Equal(t, "select * from vals where id > 1", query)
+	assert.Equal(t, "slow", database)
+	assert.Equal(t, "main", branch)
+	assert.Equal(t, uint64(3), rowsSent)
+	assert.GreaterOrEqual(t, rowsExamined, uint64(3))
+	assert.Contains(t, plan, "vals")
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Branch: main\n")
+	assert.Contains(t, string(data), "select * from vals where id > 1;\n")
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Dolthub, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	driver "github.com/dolthub/dolt/go/libraries/doltcore/dtestutils/sql_server_driver"
+)
+
+// TestStandbyReads runs a primary and a standby and asserts that the standby
+// serves reads subject to @@dolt_max_replica_lag_ms, and that
+// dolt_cluster_wait_for_hash makes the writes of a commit on the primary
+// visible on the standby.
+func TestStandbyReads(t *testing.T) {
+	u, err := driver.NewDoltUser()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		u.Cleanup()
+	})
+
+	ports := []int{3309, 3310}
+	remotesapiPorts := []int{3851, 3852}
+	roles := []string{"primary", "standby"}
+	var servers [2]*driver.SqlServer
+	t.Cleanup(func() {
+		for _, s := range servers {
+			if s != nil {
+				assert.NoError(t, s.GracefulStop())
+			}
+		}
+	})
+	for i := range servers {
+		rs, err := u.MakeRepoStore()
+		require.NoError(t, err)
+		config := fmt.Sprintf(`log_level: trace
+listener:
+  host: 0.0.0.0
+  port: %d
+cluster:
+  standby_remotes:
+  - name: standby
+    remote_url_template: http://localhost:%d/{database}
+  bootstrap_role: %s
+  bootstrap_epoch: 1
+  remotesapi:
+    port: %d
+  automatic_failover:
+    heartbeat_interval_millis: 250
+`, ports[i], remotesapiPorts[1-i], roles[i], remotesapiPorts[i])
+		require.NoError(t, os.WriteFile(filepath.Join(rs.Dir, "server.yaml"), []byte(config), 0550))
+		servers[i], err = driver.StartSqlServer(rs, driver.WithArgs("--config", "server.yaml"), driver.WithPort(ports[i]), driver.WithName(fmt.Sprintf("server%d", i+1)))
+		require.NoError(t, err)
+	}
+
+	ctx := context.Background()
+	primary, err := servers[0].DB(driver.Connection{User: "root"})
+	require.NoError(t, err)
+	defer primary.Close()
+	standby, err := servers[1].DB(driver.Connection{User: "root"})
+	require.NoError(t, err)
+	defer standby.Close()
+	require.Eventually(t, func() bool {
+		return primary.PingContext(ctx) == nil && standby.PingContext(ctx) == nil
+	}, 10*time.Second, 100*time.Millisecond)
+
+	_, err = primary.ExecContext(ctx, "create database repo1")
+	require.NoError(t, err)
+	_, err = primary.ExecContext(ctx, "create table repo1.vals (id int primary key)")
+	require.NoError(t, err)
+	_, err = primary.ExecContext(ctx, "insert into repo1.vals values (1),(2),(3)")
+	require.NoError(t, err)
+	conn, err := primary.Conn(ctx)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "use repo1")
+	require.NoError(t, err)
+	var commit string
+	require.NoError(t, conn.QueryRowContext(ctx, "call dolt_commit('-Am', 'add vals')").Scan(&commit))
+	require.NoError(t, conn.Close())
+
+	// The standby waits for the commit and then sees its writes.
+	sconn, err := standby.Conn(ctx)
+	require.NoError(t, err)
+	defer sconn.Close()
+	var res int
+	require.NoError(t, sconn.QueryRowContext(ctx, "select dolt_cluster_wait_for_hash('repo1', ?, 10)", commit).Scan(&res))
+	require.Equal(t, 0, res)
+	var cnt int
+	require.NoError(t, sconn.QueryRowContext(ctx, "select count(*) from repo1.vals").Scan(&cnt))
+	assert.Equal(t, 3, cnt)
+
+	// With a staleness bound, the standby serves reads once it knows it
+	// is caught up with the primary.
+	_, err = sconn.ExecContext(ctx, "set @@session.dolt_max_replica_lag_ms = 5000")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return sconn.QueryRowContext(ctx, "select count(*) from repo1.vals").Scan(&cnt) == nil
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, 3, cnt)
+
+	// Once the primary is gone, the lag of the standby grows past the
+	// bound and reads fail.
+	require.NoError(t, servers[0].GracefulStop())
+	servers[0] = nil
+	_, err = sconn.ExecContext(ctx, "set @@session.dolt_max_replica_lag_ms = 1000")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		err := sconn.QueryRowContext(ctx, "select count(*) from repo1.vals").Scan(&cnt)
+		return err != nil && strings.Contains(err.Error(), "behind the primary")
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// Without a bound, the standby still serves the stale data.
+	_, err = sconn.ExecContext(ctx, "set @@session.dolt_max_replica_lag_ms = 0")
+	require.NoError(t, err)
+	require.NoError(t, sconn.QueryRowContext(ctx, "select count(*) from repo1.vals").Scan(&cnt))
+	assert.Equal(t, 3, cnt)
+}